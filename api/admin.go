@@ -4,9 +4,7 @@
 package api
 
 import (
-	"bufio"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
 	"strconv"
@@ -56,7 +54,7 @@ func InitAdmin() {
 }
 
 func getLogs(c *Context, w http.ResponseWriter, r *http.Request) {
-	lines, err := GetLogs()
+	lines, err := app.GetLogs()
 	if err != nil {
 		c.Err = err
 		return
@@ -75,28 +73,6 @@ func getLogs(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(model.ArrayToJson(lines)))
 }
 
-func GetLogs() ([]string, *model.AppError) {
-	var lines []string
-
-	if utils.Cfg.LogSettings.EnableFile {
-		file, err := os.Open(utils.GetLogFileLocation(utils.Cfg.LogSettings.FileLocation))
-		if err != nil {
-			return nil, model.NewLocAppError("getLogs", "api.admin.file_read_error", nil, err.Error())
-		}
-
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			lines = append(lines, scanner.Text())
-		}
-	} else {
-		lines = append(lines, "")
-	}
-
-	return lines, nil
-}
-
 func getClusterStatus(c *Context, w http.ResponseWriter, r *http.Request) {
 	infos := make([]*model.ClusterInfo, 0)
 	if einterfaces.GetClusterInterface() != nil {
@@ -144,6 +120,9 @@ func reloadConfig(c *Context, w http.ResponseWriter, r *http.Request) {
 	// start/restart email batching job if necessary
 	app.InitEmailBatching()
 
+	// start or stop plugins and tell the running ones that the config has changed
+	app.SyncPlugins()
+
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
 	ReturnStatusOK(w)
 }
@@ -186,14 +165,14 @@ func saveConfig(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if *utils.Cfg.ClusterSettings.Enable {
+	if *utils.Cfg.ClusterSettings.Enable && einterfaces.GetClusterInterface() == nil {
 		c.Err = model.NewLocAppError("saveConfig", "ent.cluster.save_config.error", nil, "")
 		return
 	}
 
 	c.LogAudit("")
 
-	//oldCfg := utils.Cfg
+	oldCfg := utils.Cfg
 	utils.SaveConfig(utils.CfgFileName, cfg)
 	utils.LoadConfig(utils.CfgFileName)
 
@@ -205,18 +184,20 @@ func saveConfig(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Future feature is to sync the configuration files
-	// if einterfaces.GetClusterInterface() != nil {
-	// 	err := einterfaces.GetClusterInterface().ConfigChanged(cfg, oldCfg, true)
-	// 	if err != nil {
-	// 		c.Err = err
-	// 		return
-	// 	}
-	// }
+	if einterfaces.GetClusterInterface() != nil {
+		err := einterfaces.GetClusterInterface().ConfigChanged(oldCfg, cfg, true)
+		if err != nil {
+			c.Err = err
+			return
+		}
+	}
 
 	// start/restart email batching job if necessary
 	app.InitEmailBatching()
 
+	// start or stop plugins and tell the running ones that the config has changed
+	app.SyncPlugins()
+
 	rdata := map[string]string{}
 	rdata["status"] = "OK"
 	w.Write([]byte(model.MapToJson(rdata)))
@@ -226,7 +207,7 @@ func recycleDatabaseConnection(c *Context, w http.ResponseWriter, r *http.Reques
 	oldStore := app.Srv.Store
 
 	l4g.Warn(utils.T("api.admin.recycle_db_start.warn"))
-	app.Srv.Store = store.NewSqlStore()
+	app.Srv.Store = app.NewStore()
 
 	time.Sleep(20 * time.Second)
 	oldStore.Close()
@@ -278,8 +259,8 @@ func testEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func getComplianceReports(c *Context, w http.ResponseWriter, r *http.Request) {
-	if !*utils.Cfg.ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance {
-		c.Err = model.NewLocAppError("getComplianceReports", "ent.compliance.licence_disable.app_error", nil, "")
+	if !*utils.Cfg.ComplianceSettings.Enable {
+		c.Err = model.NewLocAppError("getComplianceReports", "api.admin.compliance.disabled.app_error", nil, "")
 		return
 	}
 
@@ -293,8 +274,8 @@ func getComplianceReports(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func saveComplianceReport(c *Context, w http.ResponseWriter, r *http.Request) {
-	if !*utils.Cfg.ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance || einterfaces.GetComplianceInterface() == nil {
-		c.Err = model.NewLocAppError("saveComplianceReport", "ent.compliance.licence_disable.app_error", nil, "")
+	if !*utils.Cfg.ComplianceSettings.Enable || einterfaces.GetComplianceInterface() == nil {
+		c.Err = model.NewLocAppError("saveComplianceReport", "api.admin.compliance.disabled.app_error", nil, "")
 		return
 	}
 
@@ -319,8 +300,8 @@ func saveComplianceReport(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func downloadComplianceReport(c *Context, w http.ResponseWriter, r *http.Request) {
-	if !*utils.Cfg.ComplianceSettings.Enable || !utils.IsLicensed || !*utils.License.Features.Compliance || einterfaces.GetComplianceInterface() == nil {
-		c.Err = model.NewLocAppError("downloadComplianceReport", "ent.compliance.licence_disable.app_error", nil, "")
+	if !*utils.Cfg.ComplianceSettings.Enable || einterfaces.GetComplianceInterface() == nil {
+		c.Err = model.NewLocAppError("downloadComplianceReport", "api.admin.compliance.disabled.app_error", nil, "")
 		return
 	}
 
@@ -339,8 +320,8 @@ func downloadComplianceReport(c *Context, w http.ResponseWriter, r *http.Request
 		job := result.Data.(*model.Compliance)
 		c.LogAudit("downloaded " + job.Desc)
 
-		if f, err := ioutil.ReadFile(*utils.Cfg.ComplianceSettings.Directory + "compliance/" + job.JobName() + ".zip"); err != nil {
-			c.Err = model.NewLocAppError("readFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
+		if f, err := app.ReadFile("compliance/" + job.JobName() + ".zip"); err != nil {
+			c.Err = err
 			return
 		} else {
 			w.Header().Set("Cache-Control", "max-age=2592000, public")
@@ -35,6 +35,14 @@ type Routes struct {
 	Commands *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/commands'
 	Hooks    *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/hooks'
 
+	Threads    *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/threads'
+	NeedThread *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/threads/{thread_id:[A-Za-z0-9]+}'
+
+	ScheduledPosts    *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/scheduled_posts'
+	NeedScheduledPost *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/scheduled_posts/{scheduled_post_id:[A-Za-z0-9]+}'
+
+	Drafts *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/drafts'
+
 	TeamFiles *mux.Router // 'api/v3/teams/{team_id:[A-Za-z0-9]+}/files'
 	Files     *mux.Router // 'api/v3/files'
 	NeedFile  *mux.Router // 'api/v3/files/{file_id:[A-Za-z0-9]+}'
@@ -54,6 +62,12 @@ type Routes struct {
 	Emoji *mux.Router // 'api/v3/emoji'
 
 	Webrtc *mux.Router // 'api/v3/webrtc'
+
+	Bots    *mux.Router // 'api/v3/bots'
+	NeedBot *mux.Router // 'api/v3/bots/{bot_user_id:[A-Za-z0-9]+}'
+
+	Plugins    *mux.Router // 'api/v3/plugins'
+	NeedPlugin *mux.Router // 'api/v3/plugins/{plugin_id:[A-Za-z0-9_\\.-]+}'
 }
 
 var BaseRoutes *Routes
@@ -82,6 +96,11 @@ func InitApi() {
 	BaseRoutes.Files = BaseRoutes.ApiRoot.PathPrefix("/files").Subrouter()
 	BaseRoutes.NeedFile = BaseRoutes.Files.PathPrefix("/{file_id:[A-Za-z0-9]+}").Subrouter()
 	BaseRoutes.Hooks = BaseRoutes.NeedTeam.PathPrefix("/hooks").Subrouter()
+	BaseRoutes.Threads = BaseRoutes.NeedTeam.PathPrefix("/threads").Subrouter()
+	BaseRoutes.NeedThread = BaseRoutes.Threads.PathPrefix("/{thread_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.ScheduledPosts = BaseRoutes.NeedTeam.PathPrefix("/scheduled_posts").Subrouter()
+	BaseRoutes.NeedScheduledPost = BaseRoutes.ScheduledPosts.PathPrefix("/{scheduled_post_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.Drafts = BaseRoutes.NeedTeam.PathPrefix("/drafts").Subrouter()
 	BaseRoutes.OAuth = BaseRoutes.ApiRoot.PathPrefix("/oauth").Subrouter()
 	BaseRoutes.Admin = BaseRoutes.ApiRoot.PathPrefix("/admin").Subrouter()
 	BaseRoutes.General = BaseRoutes.ApiRoot.PathPrefix("/general").Subrouter()
@@ -90,6 +109,10 @@ func InitApi() {
 	BaseRoutes.Public = BaseRoutes.ApiRoot.PathPrefix("/public").Subrouter()
 	BaseRoutes.Emoji = BaseRoutes.ApiRoot.PathPrefix("/emoji").Subrouter()
 	BaseRoutes.Webrtc = BaseRoutes.ApiRoot.PathPrefix("/webrtc").Subrouter()
+	BaseRoutes.Bots = BaseRoutes.ApiRoot.PathPrefix("/bots").Subrouter()
+	BaseRoutes.NeedBot = BaseRoutes.Bots.PathPrefix("/{bot_user_id:[A-Za-z0-9]+}").Subrouter()
+	BaseRoutes.Plugins = BaseRoutes.ApiRoot.PathPrefix("/plugins").Subrouter()
+	BaseRoutes.NeedPlugin = BaseRoutes.Plugins.PathPrefix("/{plugin_id:[A-Za-z0-9_\\.-]+}").Subrouter()
 
 	InitUser()
 	InitTeam()
@@ -108,6 +131,14 @@ func InitApi() {
 	InitStatus()
 	InitWebrtc()
 	InitReaction()
+	InitThread()
+	InitScheduledPost()
+	InitDraft()
+	InitLegalHold()
+	InitPersonalAccessToken()
+	InitBot()
+	InitEventSubscription()
+	InitPlugin()
 	InitDeprecated()
 
 	// 404 on any api route before web.go has a chance to serve it
@@ -14,15 +14,21 @@ import (
 )
 
 func checkPasswordAndAllCriteria(user *model.User, password string, mfaToken string) *model.AppError {
-	if err := checkUserAdditionalAuthenticationCriteria(user, mfaToken); err != nil {
+	if err := checkUserLoginAttempts(user); err != nil {
 		return err
 	}
 
+	// The password is checked before the MFA token so that signing in with the wrong password doesn't use up one of
+	// the user's MFA recovery codes
 	if err := checkUserPassword(user, password); err != nil {
 		return err
 	}
 
-	return nil
+	if err := checkUserAdditionalAuthenticationCriteria(user, mfaToken); err != nil {
+		return err
+	}
+
+	return resetUserLoginAttempts(user)
 }
 
 // This to be used for places we check the users password when they are already logged in
@@ -35,23 +41,21 @@ func doubleCheckPassword(user *model.User, password string) *model.AppError {
 		return err
 	}
 
-	return nil
+	return resetUserLoginAttempts(user)
 }
 
+// checkUserPassword returns an error if the password is wrong and counts it as a failed attempt to sign in. The count
+// isn't reset when the password is right since the user may still need to pass other checks.
 func checkUserPassword(user *model.User, password string) *model.AppError {
 	if !model.ComparePassword(user.Password, password) {
-		if result := <-app.Srv.Store.User().UpdateFailedPasswordAttempts(user.Id, user.FailedAttempts+1); result.Err != nil {
-			return result.Err
+		if err := incrementUserLoginAttempts(user); err != nil {
+			return err
 		}
 
 		return model.NewLocAppError("checkUserPassword", "api.user.check_user_password.invalid.app_error", nil, "user_id="+user.Id)
-	} else {
-		if result := <-app.Srv.Store.User().UpdateFailedPasswordAttempts(user.Id, 0); result.Err != nil {
-			return result.Err
-		}
-
-		return nil
 	}
+
+	return nil
 }
 
 func checkLdapUserPasswordAndAllCriteria(ldapId *string, password string, mfaToken string) (*model.User, *model.AppError) {
@@ -79,6 +83,10 @@ func checkLdapUserPasswordAndAllCriteria(ldapId *string, password string, mfaTok
 		return nil, err
 	}
 
+	if err := resetUserLoginAttempts(user); err != nil {
+		return nil, err
+	}
+
 	// user successfully authenticated
 	return user, nil
 }
@@ -104,7 +112,7 @@ func checkUserAdditionalAuthenticationCriteria(user *model.User, mfaToken string
 }
 
 func checkUserMfa(user *model.User, token string) *model.AppError {
-	if !user.MfaActive || !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+	if !user.MfaActive || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
 		return nil
 	}
 
@@ -113,9 +121,31 @@ func checkUserMfa(user *model.User, token string) *model.AppError {
 		return model.NewLocAppError("checkUserMfa", "api.user.check_user_mfa.not_available.app_error", nil, "")
 	}
 
-	if ok, err := mfaInterface.ValidateToken(user.MfaSecret, token); err != nil {
+	// Tokens are short enough to be guessed, so wrong tokens count towards the same limit as wrong passwords
+	if err := checkUserLoginAttempts(user); err != nil {
 		return err
-	} else if !ok {
+	}
+
+	ok, err := mfaInterface.UseToken(user, token)
+	if err != nil {
+		return err
+	}
+
+	// Users who have lost their device can use one of their recovery codes instead of a token
+	if !ok {
+		if ok, err = mfaInterface.ValidateRecoveryCode(user, token); err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		// Clients may try to sign in without a token to find out whether one is needed
+		if len(token) > 0 {
+			if err := incrementUserLoginAttempts(user); err != nil {
+				return err
+			}
+		}
+
 		return model.NewLocAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, "")
 	}
 
@@ -130,6 +160,26 @@ func checkUserLoginAttempts(user *model.User) *model.AppError {
 	return nil
 }
 
+func incrementUserLoginAttempts(user *model.User) *model.AppError {
+	if result := <-app.Srv.Store.User().IncrementFailedPasswordAttempts(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func resetUserLoginAttempts(user *model.User) *model.AppError {
+	if user.FailedAttempts == 0 {
+		return nil
+	}
+
+	if result := <-app.Srv.Store.User().UpdateFailedPasswordAttempts(user.Id, 0); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
 func checkEmailVerified(user *model.User) *model.AppError {
 	if !user.EmailVerified && utils.Cfg.EmailSettings.RequireEmailVerification {
 		return model.NewLocAppError("Login", "api.user.login.not_verified.app_error", nil, "user_id="+user.Id)
@@ -147,7 +197,12 @@ func checkUserNotDisabled(user *model.User) *model.AppError {
 func authenticateUser(user *model.User, password, mfaToken string) (*model.User, *model.AppError) {
 	ldapAvailable := *utils.Cfg.LdapSettings.Enable && einterfaces.GetLdapInterface() != nil && utils.IsLicensed && *utils.License.Features.LDAP
 
-	if user.AuthService == model.USER_AUTH_SERVICE_LDAP {
+	if user.IsBot {
+		// Bots can only use personal access tokens
+		err := model.NewLocAppError("login", "api.user.login.bot_login_forbidden.app_error", nil, "user_id="+user.Id)
+		err.StatusCode = http.StatusUnauthorized
+		return user, err
+	} else if user.AuthService == model.USER_AUTH_SERVICE_LDAP {
 		if !ldapAvailable {
 			err := model.NewLocAppError("login", "api.user.login_ldap.not_available.app_error", nil, "")
 			err.StatusCode = http.StatusNotImplemented
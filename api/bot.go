@@ -0,0 +1,240 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitBot() {
+	l4g.Debug(utils.T("api.bot.init.debug"))
+
+	BaseRoutes.Bots.Handle("/create", ApiUserRequired(createBot)).Methods("POST")
+	BaseRoutes.Bots.Handle("/list", ApiUserRequired(getBots)).Methods("GET")
+
+	BaseRoutes.NeedBot.Handle("/get", ApiUserRequired(getBot)).Methods("GET")
+	BaseRoutes.NeedBot.Handle("/update", ApiUserRequired(updateBot)).Methods("POST")
+	BaseRoutes.NeedBot.Handle("/disable", ApiUserRequired(disableBot)).Methods("POST")
+	BaseRoutes.NeedBot.Handle("/enable", ApiUserRequired(enableBot)).Methods("POST")
+	BaseRoutes.NeedBot.Handle("/tokens", ApiUserRequired(getBotTokens)).Methods("GET")
+	BaseRoutes.NeedBot.Handle("/tokens/create", ApiUserRequired(createBotToken)).Methods("POST")
+	BaseRoutes.NeedBot.Handle("/tokens/revoke", ApiUserRequired(revokeBotToken)).Methods("POST")
+}
+
+func createBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*utils.Cfg.ServiceSettings.EnableBotAccounts {
+		c.Err = model.NewLocAppError("createBot", "api.bot.create_bot.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	if !HasPermissionToContext(c, model.PERMISSION_MANAGE_BOTS) {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	username := props["username"]
+	if len(username) == 0 {
+		c.SetInvalidParam("createBot", "username")
+		return
+	}
+
+	c.LogAudit("attempt username=" + username)
+
+	bot, err := app.CreateBot(username, props["display_name"], props["description"], c.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success bot_user_id=" + bot.UserId)
+	w.Write([]byte(bot.ToJson()))
+}
+
+// getBots returns the bots owned by the current user, or every bot for users who can manage everyone's bots.
+func getBots(c *Context, w http.ResponseWriter, r *http.Request) {
+	var bots []*model.Bot
+	var err *model.AppError
+
+	if HasPermissionToContext(c, model.PERMISSION_MANAGE_OTHERS_BOTS) {
+		bots, err = app.GetAllBots()
+	} else {
+		c.Err = nil
+		bots, err = app.GetBotsForOwner(c.Session.UserId)
+	}
+
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.BotsToJson(bots)))
+}
+
+// getBotForContext returns the bot that the request is for if the current user is allowed to manage it. Users can
+// manage the bots that they own unless they've been restricted from managing bots at all.
+func getBotForContext(c *Context, r *http.Request) *model.Bot {
+	params := mux.Vars(r)
+
+	bot, err := app.GetBot(params["bot_user_id"])
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if bot.OwnerId == c.Session.UserId && HasPermissionToContext(c, model.PERMISSION_MANAGE_BOTS) {
+		return bot
+	}
+
+	c.Err = nil
+	if !HasPermissionToContext(c, model.PERMISSION_MANAGE_OTHERS_BOTS) {
+		return nil
+	}
+
+	return bot
+}
+
+func getBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := getBotForContext(c, r)
+	if bot == nil {
+		return
+	}
+
+	w.Write([]byte(bot.ToJson()))
+}
+
+func updateBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := getBotForContext(c, r)
+	if bot == nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	c.LogAudit("attempt bot_user_id=" + bot.UserId)
+
+	rbot, err := app.UpdateBot(bot.UserId, props["display_name"], props["description"])
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success bot_user_id=" + rbot.UserId)
+	w.Write([]byte(rbot.ToJson()))
+}
+
+func disableBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	setBotActive(c, w, r, false)
+}
+
+func enableBot(c *Context, w http.ResponseWriter, r *http.Request) {
+	setBotActive(c, w, r, true)
+}
+
+func setBotActive(c *Context, w http.ResponseWriter, r *http.Request, active bool) {
+	bot := getBotForContext(c, r)
+	if bot == nil {
+		return
+	}
+
+	user, err := app.GetUser(bot.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if _, err := UpdateActive(user, active); err != nil {
+		c.Err = err
+		return
+	}
+
+	if !active {
+		app.SetStatusOffline(user.Id, false)
+	}
+
+	c.LogAuditWithUserId(user.Id, fmt.Sprintf("active=%v", active))
+
+	rbot, err := app.GetBot(bot.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(rbot.ToJson()))
+}
+
+func getBotTokens(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := getBotForContext(c, r)
+	if bot == nil {
+		return
+	}
+
+	if tokens, err := app.GetPersonalAccessTokensForUser(bot.UserId); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(model.PersonalAccessTokensToJson(tokens)))
+	}
+}
+
+func createBotToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := getBotForContext(c, r)
+	if bot == nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	c.LogAudit("attempt bot_user_id=" + bot.UserId)
+
+	token, err := app.CreatePersonalAccessToken(bot.UserId, props["description"])
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success bot_user_id=" + bot.UserId + " token_id=" + token.Id)
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	w.Write([]byte(token.ToJson()))
+}
+
+func revokeBotToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	bot := getBotForContext(c, r)
+	if bot == nil {
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	token, err := app.GetPersonalAccessToken(props["token_id"])
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if token.UserId != bot.UserId {
+		c.SetInvalidParam("revokeBotToken", "token_id")
+		return
+	}
+
+	c.LogAudit("attempt bot_user_id=" + bot.UserId + " token_id=" + token.Id)
+
+	if err := app.RevokePersonalAccessToken(token); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success bot_user_id=" + bot.UserId + " token_id=" + token.Id)
+	ReturnStatusOK(w)
+}
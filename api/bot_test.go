@@ -0,0 +1,129 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestBots(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	Client := th.BasicClient
+
+	enableBots := *utils.Cfg.ServiceSettings.EnableBotAccounts
+	enableTokens := *utils.Cfg.ServiceSettings.EnablePersonalAccessTokens
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableBotAccounts = enableBots
+		*utils.Cfg.ServiceSettings.EnablePersonalAccessTokens = enableTokens
+	}()
+	*utils.Cfg.ServiceSettings.EnableBotAccounts = false
+	*utils.Cfg.ServiceSettings.EnablePersonalAccessTokens = true
+
+	username := "bot" + model.NewId()
+
+	if _, err := Client.CreateBot(username, "Bot", "posts things"); err == nil {
+		t.Fatal("should have failed - bot accounts are disabled")
+	}
+
+	*utils.Cfg.ServiceSettings.EnableBotAccounts = true
+
+	result, err := Client.CreateBot(username, "Bot", "posts things")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := result.Data.(*model.Bot)
+
+	if bot.Username != username || bot.DisplayName != "Bot" || bot.OwnerId != th.BasicUser.Id {
+		t.Fatal("should've returned the new bot")
+	}
+
+	if result, err := Client.GetBots(); err != nil {
+		t.Fatal(err)
+	} else if bots := result.Data.([]*model.Bot); len(bots) != 1 || bots[0].UserId != bot.UserId {
+		t.Fatal("should've listed the bot")
+	}
+
+	if _, err := th.SystemAdminClient.GetBot(bot.UserId); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Client.GetBot(th.BasicUser2.Id); err == nil {
+		t.Fatal("shouldn't have found a bot for a regular user")
+	}
+
+	if _, err := Client.Login(username, ""); err == nil {
+		t.Fatal("bots shouldn't be able to sign in")
+	}
+
+	if result, err := Client.UpdateBot(bot.UserId, "Updated", "posts other things"); err != nil {
+		t.Fatal(err)
+	} else if rbot := result.Data.(*model.Bot); rbot.DisplayName != "Updated" || rbot.Description != "posts other things" {
+		t.Fatal("should've updated the bot")
+	}
+
+	result, err = Client.CreateBotToken(bot.UserId, "integration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := result.Data.(*model.PersonalAccessToken)
+
+	botUser, _ := app.GetUser(bot.UserId)
+	if err := app.JoinUserToTeam(th.BasicTeam, botUser); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.AddUserToChannel(botUser, th.BasicChannel); err != nil {
+		t.Fatal(err)
+	}
+
+	BotClient := th.CreateClient()
+	BotClient.AuthToken = token.Token
+	BotClient.AuthType = model.HEADER_BEARER
+	BotClient.SetTeamId(th.BasicTeam.Id)
+
+	if result, err := BotClient.GetMe(""); err != nil {
+		t.Fatal(err)
+	} else if user := result.Data.(*model.User); user.Id != bot.UserId || !user.IsBot {
+		t.Fatal("should've authenticated as the bot")
+	}
+
+	post := &model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"}
+	post.AddProp(model.POST_PROPS_FROM_BOT, "false")
+	if result, err := BotClient.CreatePost(post); err != nil {
+		t.Fatal(err)
+	} else if rpost := result.Data.(*model.Post); rpost.Props[model.POST_PROPS_FROM_BOT] != "true" {
+		t.Fatal("should've marked the post as coming from a bot")
+	}
+
+	post = &model.Post{ChannelId: th.BasicChannel.Id, Message: "hello"}
+	post.AddProp(model.POST_PROPS_FROM_BOT, "true")
+	if result, err := Client.CreatePost(post); err != nil {
+		t.Fatal(err)
+	} else if rpost := result.Data.(*model.Post); rpost.Props[model.POST_PROPS_FROM_BOT] != nil {
+		t.Fatal("users shouldn't be able to pretend to be bots")
+	}
+
+	th.LoginBasic2()
+
+	if _, err := Client.GetBot(bot.UserId); err == nil {
+		t.Fatal("shouldn't have permissions to get another user's bot")
+	}
+
+	if _, err := Client.SetBotActive(bot.UserId, false); err == nil {
+		t.Fatal("shouldn't have permissions to disable another user's bot")
+	}
+
+	if result, err := th.SystemAdminClient.SetBotActive(bot.UserId, false); err != nil {
+		t.Fatal(err)
+	} else if result.Data.(*model.Bot).DeleteAt == 0 {
+		t.Fatal("should've disabled the bot")
+	}
+
+	if _, err := BotClient.GetMe(""); err == nil {
+		t.Fatal("shouldn't have been able to use a disabled bot's token")
+	}
+}
@@ -206,6 +206,7 @@ func updateChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 					l4g.Error(err.Error())
 				}
 			}
+			app.IndexChannel(oldChannel)
 			c.LogAudit("name=" + channel.Name)
 			w.Write([]byte(oldChannel.ToJson()))
 		}
@@ -465,7 +466,7 @@ func leave(c *Context, w http.ResponseWriter, r *http.Request) {
 
 		RemoveUserFromChannel(c.Session.UserId, c.Session.UserId, channel)
 
-		go app.PostUserAddRemoveMessage(c.Session.UserId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.leave.left"), user.Username), model.POST_JOIN_LEAVE)
+		go app.PostUserAddRemoveMessage(c.Session.UserId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.leave.left"), user.Username), model.POST_JOIN_LEAVE, model.POST_MEMBER_EVENT_LEAVE, c.Session.UserId)
 
 		result := make(map[string]string)
 		result["id"] = channel.Id
@@ -563,6 +564,7 @@ func deleteChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		app.InvalidateCacheForChannel(channel.Id)
+		app.DeleteChannelFromIndex(channel.Id)
 
 		c.LogAudit("name=" + channel.Name)
 
@@ -686,7 +688,15 @@ func getChannelStats(c *Context, w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		data := model.ChannelStats{ChannelId: channel.Id, MemberCount: memberCount}
+		var pinnedPostCount int64
+		if result := <-app.Srv.Store.Post().GetPinnedPostCount(channel.Id); result.Err != nil {
+			c.Err = result.Err
+			return
+		} else {
+			pinnedPostCount = result.Data.(int64)
+		}
+
+		data := model.ChannelStats{ChannelId: channel.Id, MemberCount: memberCount, PinnedPostCount: pinnedPostCount}
 		w.Write([]byte(data.ToJson()))
 	}
 }
@@ -766,7 +776,7 @@ func addMember(c *Context, w http.ResponseWriter, r *http.Request) {
 
 			c.LogAudit("name=" + channel.Name + " user_id=" + userId)
 
-			go app.PostUserAddRemoveMessage(c.Session.UserId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.add_member.added"), nUser.Username, oUser.Username), model.POST_ADD_REMOVE)
+			go app.PostUserAddRemoveMessage(c.Session.UserId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.add_member.added"), nUser.Username, oUser.Username), model.POST_ADD_REMOVE, model.POST_MEMBER_EVENT_JOIN, nUser.Id)
 
 			<-app.Srv.Store.Channel().UpdateLastViewedAt([]string{id}, oUser.Id)
 			w.Write([]byte(cm.ToJson()))
@@ -820,7 +830,7 @@ func removeMember(c *Context, w http.ResponseWriter, r *http.Request) {
 
 			c.LogAudit("name=" + channel.Name + " user_id=" + userIdToRemove)
 
-			go app.PostUserAddRemoveMessage(c.Session.UserId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.remove_member.removed"), oUser.Username), model.POST_ADD_REMOVE)
+			go app.PostUserAddRemoveMessage(c.Session.UserId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.remove_member.removed"), oUser.Username), model.POST_ADD_REMOVE, model.POST_MEMBER_EVENT_LEAVE, userIdToRemove)
 
 			result := make(map[string]string)
 			result["channel_id"] = channel.Id
@@ -926,6 +936,15 @@ func searchMoreChannels(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if channels, ok, err := app.SearchMoreChannelsWithEngine(c.Session.UserId, c.TeamId, props.Term); ok {
+		if err != nil {
+			c.Err = err
+		} else {
+			w.Write([]byte(channels.ToJson()))
+		}
+		return
+	}
+
 	if result := <-app.Srv.Store.Channel().SearchMore(c.Session.UserId, c.TeamId, props.Term); result.Err != nil {
 		c.Err = result.Err
 		return
@@ -1358,6 +1358,15 @@ func TestGetChannelStats(t *testing.T) {
 		t.Fatal("couldnt't get extra info")
 	} else if data.MemberCount != 1 {
 		t.Fatal("got incorrect member count")
+	} else if data.PinnedPostCount != 0 {
+		t.Fatal("got incorrect pinned post count")
+	}
+
+	post := th.CreatePost(Client, channel1)
+	Client.Must(Client.PinPost(channel1.Id, post.Id))
+
+	if data := Client.Must(Client.GetChannelStats(channel1.Id, "")).Data.(*model.ChannelStats); data.PinnedPostCount != 1 {
+		t.Fatal("got incorrect pinned post count")
 	}
 }
 
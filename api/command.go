@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 
 	l4g "github.com/alecthomas/log4go"
@@ -44,6 +45,7 @@ func InitCommand() {
 
 	BaseRoutes.Commands.Handle("/execute", ApiUserRequired(executeCommand)).Methods("POST")
 	BaseRoutes.Commands.Handle("/list", ApiUserRequired(listCommands)).Methods("GET")
+	BaseRoutes.Commands.Handle("/suggestions", ApiUserRequired(getCommandSuggestions)).Methods("GET")
 
 	BaseRoutes.Commands.Handle("/create", ApiUserRequired(createCommand)).Methods("POST")
 	BaseRoutes.Commands.Handle("/update", ApiUserRequired(updateCommand)).Methods("POST")
@@ -58,12 +60,27 @@ func InitCommand() {
 }
 
 func listCommands(c *Context, w http.ResponseWriter, r *http.Request) {
+	commands, err := getAutocompleteCommands(c)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	for _, cmd := range commands {
+		cmd.Sanitize()
+	}
+
+	w.Write([]byte(model.CommandListToJson(commands)))
+}
+
+// getAutocompleteCommands returns the built-in and custom commands of the current team that are shown in
+// autocomplete.
+func getAutocompleteCommands(c *Context) ([]*model.Command, *model.AppError) {
 	commands := make([]*model.Command, 0, 32)
 	seen := make(map[string]bool)
 	for _, value := range commandProviders {
 		cpy := *value.GetCommand(c)
 		if cpy.AutoComplete && !seen[cpy.Id] {
-			cpy.Sanitize()
 			seen[cpy.Trigger] = true
 			commands = append(commands, &cpy)
 		}
@@ -71,13 +88,11 @@ func listCommands(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	if *utils.Cfg.ServiceSettings.EnableCommands {
 		if result := <-app.Srv.Store.Command().GetByTeam(c.TeamId); result.Err != nil {
-			c.Err = result.Err
-			return
+			return nil, result.Err
 		} else {
 			teamCmds := result.Data.([]*model.Command)
 			for _, cmd := range teamCmds {
 				if cmd.AutoComplete && !seen[cmd.Id] {
-					cmd.Sanitize()
 					seen[cmd.Trigger] = true
 					commands = append(commands, cmd)
 				}
@@ -85,7 +100,59 @@ func listCommands(c *Context, w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.Write([]byte(model.CommandListToJson(commands)))
+	return commands, nil
+}
+
+func getCommandSuggestions(c *Context, w http.ResponseWriter, r *http.Request) {
+	userInput := r.URL.Query().Get("user_input")
+	if len(userInput) <= 1 || strings.Index(userInput, "/") != 0 {
+		c.SetInvalidParam("getCommandSuggestions", "user_input")
+		return
+	}
+
+	channelId := r.URL.Query().Get("channel_id")
+	if len(channelId) != 26 {
+		c.SetInvalidParam("getCommandSuggestions", "channel_id")
+		return
+	}
+
+	if !HasPermissionToChannelContext(c, channelId, model.PERMISSION_USE_SLASH_COMMANDS) {
+		return
+	}
+
+	commands, err := getAutocompleteCommands(c)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	suggestions := []*model.SuggestCommand{}
+	if index := strings.Index(userInput, " "); index == -1 {
+		// The trigger is still being typed
+		for _, cmd := range commands {
+			if strings.HasPrefix("/"+cmd.Trigger, strings.ToLower(userInput)) {
+				suggestions = append(suggestions, &model.SuggestCommand{
+					Suggestion:  "/" + cmd.Trigger,
+					Hint:        cmd.AutoCompleteHint,
+					Description: cmd.AutoCompleteDesc,
+				})
+			}
+		}
+
+		sort.Slice(suggestions, func(i, j int) bool {
+			return suggestions[i].Suggestion < suggestions[j].Suggestion
+		})
+	} else {
+		trigger := strings.ToLower(userInput[1:index])
+		for _, cmd := range commands {
+			if cmd.Trigger == trigger {
+				suggestions = app.GetCommandSuggestions(cmd, userInput, c.TeamId, channelId, c.Session.UserId)
+				break
+			}
+		}
+	}
+
+	w.Write([]byte(model.SuggestCommandListToJson(suggestions)))
 }
 
 func executeCommand(c *Context, w http.ResponseWriter, r *http.Request) {
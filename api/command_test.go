@@ -4,9 +4,12 @@
 package api
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
 )
@@ -33,6 +36,120 @@ func TestListCommands(t *testing.T) {
 	}
 }
 
+func TestGetCommandSuggestions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	Client := th.SystemAdminClient
+	channel := th.SystemAdminChannel
+
+	enableCommands := *utils.Cfg.ServiceSettings.EnableCommands
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableCommands = &enableCommands
+	}()
+	*utils.Cfg.ServiceSettings.EnableCommands = true
+
+	requests := make(chan *model.AutocompleteRequest, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := model.AutocompleteRequestFromJson(r.Body)
+		requests <- request
+
+		items := []*model.AutocompleteListItem{{Item: "PROJ", HelpText: "Project"}}
+		w.Write([]byte(model.AutocompleteListItemsToJson(items)))
+	}))
+	defer ts.Close()
+
+	cmd := &model.Command{
+		URL:             "http://nowhere.com",
+		Method:          model.COMMAND_METHOD_POST,
+		Trigger:         "jira",
+		AutoComplete:    true,
+		AutoCompleteURL: ts.URL,
+		AutocompleteData: &model.AutocompleteData{
+			Subcommands: []*model.AutocompleteData{
+				{
+					Trigger:  "create",
+					HelpText: "Create an issue",
+					Arguments: []*model.AutocompleteArgument{
+						{Name: "project", Type: model.AUTOCOMPLETE_ARG_TYPE_DYNAMIC},
+						{Name: "priority", Type: model.AUTOCOMPLETE_ARG_TYPE_STATIC_LIST, Options: []*model.AutocompleteListItem{{Item: "high"}, {Item: "low"}}},
+						{Name: "assignee", Type: model.AUTOCOMPLETE_ARG_TYPE_USER},
+					},
+				},
+				{Trigger: "list"},
+			},
+		},
+	}
+	cmd = Client.Must(Client.CreateCommand(cmd)).Data.(*model.Command)
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/ji")).Data.([]*model.SuggestCommand); len(suggestions) != 1 || suggestions[0].Suggestion != "/jira" {
+		t.Fatal("should've suggested the trigger")
+	}
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/jira ")).Data.([]*model.SuggestCommand); len(suggestions) != 2 {
+		t.Fatal("should've suggested both subcommands")
+	}
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/jira cr")).Data.([]*model.SuggestCommand); len(suggestions) != 1 || suggestions[0].Suggestion != "/jira create" {
+		t.Fatal("should've suggested the create subcommand")
+	} else if suggestions[0].Description != "Create an issue" {
+		t.Fatal("should've described the subcommand")
+	}
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/jira create P")).Data.([]*model.SuggestCommand); len(suggestions) != 1 || suggestions[0].Suggestion != "/jira create PROJ" {
+		t.Fatal("should've suggested the project from the integration")
+	}
+
+	if request := <-requests; request.Token != cmd.Token || request.Term != "P" || request.Argument != "project" || request.ChannelId != channel.Id {
+		t.Fatal("integration received the wrong request")
+	}
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/jira create PROJ h")).Data.([]*model.SuggestCommand); len(suggestions) != 1 || suggestions[0].Suggestion != "/jira create PROJ high" {
+		t.Fatal("should've suggested an option from the static list")
+	}
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/jira create PROJ high @"+th.SystemAdminUser.Username)).Data.([]*model.SuggestCommand); len(suggestions) == 0 {
+		t.Fatal("should've suggested a user in the channel")
+	}
+
+	bot, err := app.CreateBot("bot"+model.NewId(), "Bot", "", th.SystemAdminUser.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	botUser, err := app.GetUser(bot.UserId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	LinkUserToTeam(botUser, th.SystemAdminTeam)
+	if _, err := app.AddUserToChannel(botUser, channel); err != nil {
+		t.Fatal(err)
+	}
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/jira create PROJ high @"+bot.Username)).Data.([]*model.SuggestCommand); len(suggestions) != 0 {
+		t.Fatal("shouldn't suggest a bot")
+	}
+
+	if suggestions := Client.Must(Client.GetCommandSuggestions(channel.Id, "/jira junk ")).Data.([]*model.SuggestCommand); len(suggestions) != 0 {
+		t.Fatal("shouldn't suggest anything after an unknown subcommand")
+	}
+
+	if _, err := Client.GetCommandSuggestions(channel.Id, "jira"); err == nil {
+		t.Fatal("should've failed without a slash")
+	}
+
+	if _, err := th.BasicClient.GetCommandSuggestions(channel.Id, "/jira "); err == nil {
+		t.Fatal("should've failed in a channel the user doesn't belong to")
+	}
+
+	if commands := Client.Must(Client.ListCommands()).Data.([]*model.Command); len(commands) == 0 {
+		t.Fatal("should've listed commands")
+	} else {
+		for _, command := range commands {
+			if command.Trigger == "jira" && (len(command.AutoCompleteURL) != 0 || command.AutocompleteData == nil) {
+				t.Fatal("should've sanitized the autocomplete url but kept the autocomplete data")
+			}
+		}
+	}
+}
+
 func TestCreateCommand(t *testing.T) {
 	th := Setup().InitBasic().InitSystemAdmin()
 	Client := th.BasicClient
@@ -308,8 +308,10 @@ func (c *Context) UserRequired() {
 }
 
 func (c *Context) MfaRequired() {
-	// Must be licensed for MFA and have it configured for enforcement
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication || !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication {
+	// Must have MFA available and configured for enforcement
+	if einterfaces.GetMfaInterface() == nil || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+		return
+	} else if !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication && !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthenticationForAdmins {
 		return
 	}
 
@@ -325,6 +327,11 @@ func (c *Context) MfaRequired() {
 	} else {
 		user := result.Data.(*model.User)
 
+		// Bots can't sign in so they can't set up MFA
+		if user.IsBot {
+			return
+		}
+
 		// Only required for email and ldap accounts
 		if user.AuthService != "" &&
 			user.AuthService != model.USER_AUTH_SERVICE_EMAIL &&
@@ -332,6 +339,11 @@ func (c *Context) MfaRequired() {
 			return
 		}
 
+		// Enforcement may only apply to system admins since their accounts are the most valuable
+		if !*utils.Cfg.ServiceSettings.EnforceMultifactorAuthentication && !user.IsInRole(model.ROLE_SYSTEM_ADMIN.Id) {
+			return
+		}
+
 		if !user.MfaActive {
 			c.Err = model.NewLocAppError("", "api.context.mfa_required.app_error", nil, "MfaRequired")
 			c.Err.StatusCode = http.StatusUnauthorized
@@ -0,0 +1,77 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitDraft() {
+	l4g.Debug(utils.T("api.draft.init.debug"))
+
+	BaseRoutes.Drafts.Handle("/", ApiUserRequired(getDrafts)).Methods("GET")
+	BaseRoutes.Drafts.Handle("/save", ApiUserRequired(saveDraft)).Methods("POST")
+	BaseRoutes.Drafts.Handle("/delete", ApiUserRequired(deleteDraft)).Methods("POST")
+}
+
+func getDrafts(c *Context, w http.ResponseWriter, r *http.Request) {
+	if drafts, err := app.GetDraftsForUser(c.TeamId, c.Session.UserId); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(model.DraftsToJson(drafts)))
+	}
+}
+
+func saveDraft(c *Context, w http.ResponseWriter, r *http.Request) {
+	draft := model.DraftFromJson(r.Body)
+	if draft == nil {
+		c.SetInvalidParam("saveDraft", "draft")
+		return
+	}
+
+	if len(draft.ChannelId) != 26 {
+		c.SetInvalidParam("saveDraft", "channel_id")
+		return
+	}
+
+	draft.UserId = c.Session.UserId
+
+	if !HasPermissionToChannelContext(c, draft.ChannelId, model.PERMISSION_CREATE_POST) {
+		return
+	}
+
+	if rdraft, err := app.SaveDraft(draft); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(rdraft.ToJson()))
+	}
+}
+
+func deleteDraft(c *Context, w http.ResponseWriter, r *http.Request) {
+	props := model.MapFromJson(r.Body)
+
+	channelId := props["channel_id"]
+	if len(channelId) != 26 {
+		c.SetInvalidParam("deleteDraft", "channel_id")
+		return
+	}
+
+	rootId := props["root_id"]
+	if len(rootId) != 26 && len(rootId) != 0 {
+		c.SetInvalidParam("deleteDraft", "root_id")
+		return
+	}
+
+	if err := app.DeleteDraft(c.Session.UserId, channelId, rootId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
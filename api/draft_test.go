@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestDrafts(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+	WebSocketClient.Listen()
+
+	time.Sleep(300 * time.Millisecond)
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have responded OK to authentication challenge")
+	}
+
+	draft, err := Client.SaveDraft(&model.Draft{ChannelId: channel.Id, Message: "draft"})
+	if err != nil {
+		t.Fatal(err)
+	} else if draft.UserId != th.BasicUser.Id {
+		t.Fatal("should've set the user")
+	}
+
+	timeout := time.After(2 * time.Second)
+	received := false
+	for !received {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_DRAFT_UPDATED {
+				if rdraft := model.DraftFromJson(strings.NewReader(event.Data["draft"].(string))); rdraft.Message != "draft" {
+					t.Fatal("received the wrong draft")
+				}
+				received = true
+			}
+		case <-timeout:
+			t.Fatal("should've received a draft_updated event")
+		}
+	}
+
+	if _, err := Client.SaveDraft(&model.Draft{ChannelId: channel.Id, Message: "updated"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if drafts, err := Client.GetDrafts(); err != nil {
+		t.Fatal(err)
+	} else if len(drafts) != 1 || drafts[0].Message != "updated" {
+		t.Fatal("should've replaced the draft")
+	}
+
+	if _, err := Client.SaveDraft(&model.Draft{ChannelId: model.NewId(), Message: "junk"}); err == nil {
+		t.Fatal("shouldn't save a draft in a channel the user can't access")
+	}
+
+	if ok, err := Client.DeleteDraft(channel.Id, ""); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("should've deleted the draft")
+	}
+
+	if drafts, err := Client.GetDrafts(); err != nil {
+		t.Fatal(err)
+	} else if len(drafts) != 0 {
+		t.Fatal("should've deleted the draft")
+	}
+
+	if _, err := Client.DeleteDraft("junk", ""); err == nil {
+		t.Fatal("should've failed with an invalid channel id")
+	}
+
+	// sending a post removes the draft that it was written from
+	if _, err := Client.SaveDraft(&model.Draft{ChannelId: channel.Id, Message: "posting"}); err != nil {
+		t.Fatal(err)
+	}
+
+	Client.Must(Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "posting"}))
+	time.Sleep(100 * time.Millisecond)
+
+	if drafts, err := Client.GetDrafts(); err != nil {
+		t.Fatal(err)
+	} else if len(drafts) != 0 {
+		t.Fatal("should've deleted the draft after posting")
+	}
+}
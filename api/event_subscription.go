@@ -0,0 +1,160 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitEventSubscription() {
+	l4g.Debug(utils.T("api.event_subscription.init.debug"))
+
+	BaseRoutes.Hooks.Handle("/subscriptions/create", ApiUserRequired(createEventSubscription)).Methods("POST")
+	BaseRoutes.Hooks.Handle("/subscriptions/list", ApiUserRequired(getEventSubscriptions)).Methods("GET")
+	BaseRoutes.Hooks.Handle("/subscriptions/{subscription_id:[A-Za-z0-9]+}/update", ApiUserRequired(updateEventSubscription)).Methods("POST")
+	BaseRoutes.Hooks.Handle("/subscriptions/{subscription_id:[A-Za-z0-9]+}/regen_secret", ApiUserRequired(regenEventSubscriptionSecret)).Methods("POST")
+	BaseRoutes.Hooks.Handle("/subscriptions/{subscription_id:[A-Za-z0-9]+}/delete", ApiUserRequired(deleteEventSubscription)).Methods("POST")
+}
+
+func checkEventSubscriptionsEnabled(c *Context, where string) bool {
+	if !*utils.Cfg.ServiceSettings.EnableEventSubscriptions {
+		c.Err = model.NewLocAppError(where, "api.event_subscription.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return false
+	}
+
+	if !HasPermissionToCurrentTeamContext(c, model.PERMISSION_MANAGE_WEBHOOKS) {
+		return false
+	}
+
+	return true
+}
+
+// getEventSubscriptionForContext returns the subscription that the request is for if the current user is allowed to
+// manage it.
+func getEventSubscriptionForContext(c *Context, r *http.Request, where string) *model.EventSubscription {
+	if !checkEventSubscriptionsEnabled(c, where) {
+		return nil
+	}
+
+	params := mux.Vars(r)
+
+	subscription, err := app.GetEventSubscription(params["subscription_id"])
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if subscription.TeamId != c.TeamId || (c.Session.UserId != subscription.CreatorId && !HasPermissionToCurrentTeamContext(c, model.PERMISSION_MANAGE_OTHERS_WEBHOOKS)) {
+		c.LogAudit("fail - inappropriate permissions")
+		c.Err = model.NewLocAppError(where, "api.event_subscription.permissions.app_error", nil, "user_id="+c.Session.UserId)
+		c.Err.StatusCode = http.StatusForbidden
+		return nil
+	}
+
+	return subscription
+}
+
+func createEventSubscription(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !checkEventSubscriptionsEnabled(c, "createEventSubscription") {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	subscription := model.EventSubscriptionFromJson(r.Body)
+	if subscription == nil {
+		c.SetInvalidParam("createEventSubscription", "subscription")
+		return
+	}
+
+	subscription.Id = ""
+	subscription.Secret = ""
+	subscription.CreatorId = c.Session.UserId
+	subscription.TeamId = c.TeamId
+
+	if rsubscription, err := app.CreateEventSubscription(subscription); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(rsubscription.ToJson()))
+	}
+}
+
+func getEventSubscriptions(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !checkEventSubscriptionsEnabled(c, "getEventSubscriptions") {
+		return
+	}
+
+	if subscriptions, err := app.GetEventSubscriptionsForTeam(c.TeamId); err != nil {
+		c.Err = err
+		return
+	} else {
+		w.Write([]byte(model.EventSubscriptionListToJson(subscriptions)))
+	}
+}
+
+func updateEventSubscription(c *Context, w http.ResponseWriter, r *http.Request) {
+	subscription := getEventSubscriptionForContext(c, r, "updateEventSubscription")
+	if subscription == nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	updated := model.EventSubscriptionFromJson(r.Body)
+	if updated == nil {
+		c.SetInvalidParam("updateEventSubscription", "subscription")
+		return
+	}
+
+	if rsubscription, err := app.UpdateEventSubscription(subscription, updated); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(rsubscription.ToJson()))
+	}
+}
+
+func regenEventSubscriptionSecret(c *Context, w http.ResponseWriter, r *http.Request) {
+	subscription := getEventSubscriptionForContext(c, r, "regenEventSubscriptionSecret")
+	if subscription == nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if rsubscription, err := app.RegenerateEventSubscriptionSecret(subscription); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("success")
+		w.Write([]byte(rsubscription.ToJson()))
+	}
+}
+
+func deleteEventSubscription(c *Context, w http.ResponseWriter, r *http.Request) {
+	subscription := getEventSubscriptionForContext(c, r, "deleteEventSubscription")
+	if subscription == nil {
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	if err := app.DeleteEventSubscription(subscription.Id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+	ReturnStatusOK(w)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestEventSubscriptions(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	Client := th.SystemAdminClient
+	team := th.SystemAdminTeam
+
+	enableEventSubscriptions := *utils.Cfg.ServiceSettings.EnableEventSubscriptions
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableEventSubscriptions = enableEventSubscriptions
+	}()
+	*utils.Cfg.ServiceSettings.EnableEventSubscriptions = true
+
+	type receivedEvent struct {
+		event     string
+		signature string
+		body      []byte
+	}
+
+	events := make(chan *receivedEvent, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		events <- &receivedEvent{
+			event:     r.Header.Get(model.HEADER_EVENT_SUBSCRIPTION_EVENT),
+			signature: r.Header.Get(model.HEADER_WEBHOOK_SIGNATURE),
+			body:      body,
+		}
+	}))
+	defer ts.Close()
+
+	subscription := &model.EventSubscription{
+		URL:    ts.URL,
+		Events: model.StringArray{model.WEBSOCKET_EVENT_CHANNEL_CREATED},
+	}
+
+	if _, err := th.BasicClient.CreateEventSubscription(subscription); err == nil {
+		t.Fatal("should've failed for a user without permission to manage webhooks")
+	}
+
+	subscription = Client.Must(Client.CreateEventSubscription(subscription)).Data.(*model.EventSubscription)
+	if subscription.TeamId != team.Id || subscription.CreatorId != th.SystemAdminUser.Id || len(subscription.Secret) == 0 {
+		t.Fatal("should've created the subscription for the team")
+	}
+
+	if _, err := Client.CreateEventSubscription(&model.EventSubscription{URL: ts.URL, Events: model.StringArray{model.WEBSOCKET_EVENT_TYPING}}); err == nil {
+		t.Fatal("shouldn't subscribe to typing events")
+	}
+
+	if subscriptions := Client.Must(Client.GetEventSubscriptions()).Data.([]*model.EventSubscription); len(subscriptions) != 1 || subscriptions[0].Id != subscription.Id {
+		t.Fatal("should've listed the subscription")
+	}
+
+	channel := th.CreateChannel(Client, team)
+
+	select {
+	case received := <-events:
+		if received.event != model.WEBSOCKET_EVENT_CHANNEL_CREATED {
+			t.Fatal("should've received the event type in a header")
+		} else if received.signature != subscription.Sign(received.body) {
+			t.Fatal("should've signed the event")
+		} else if event := model.WebSocketEventFromJson(bytes.NewReader(received.body)); event == nil || event.Data["channel_id"] != channel.Id {
+			t.Fatal("should've sent the websocket event")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("should've received the channel created event")
+	}
+
+	// events are delivered like outgoing webhooks so that they can be retried
+	if deliveries, err := app.GetOutgoingWebhookDeliveries(subscription.Id, 0, 10); err != nil {
+		t.Fatal(err)
+	} else if len(deliveries) != 1 || deliveries[0].Event != model.WEBSOCKET_EVENT_CHANNEL_CREATED {
+		t.Fatal("should've queued a delivery for the event")
+	}
+
+	// private channels aren't sent to subscriptions
+	th.CreatePrivateChannel(Client, team)
+
+	subscription.Events = model.StringArray{model.WEBSOCKET_EVENT_POST_EDITED}
+	subscription = Client.Must(Client.UpdateEventSubscription(subscription)).Data.(*model.EventSubscription)
+	if !subscription.HasEvent(model.WEBSOCKET_EVENT_POST_EDITED) || subscription.HasEvent(model.WEBSOCKET_EVENT_CHANNEL_CREATED) {
+		t.Fatal("should've updated the events")
+	}
+
+	select {
+	case received := <-events:
+		t.Fatalf("shouldn't have received %v", received.event)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	oldSecret := subscription.Secret
+	subscription = Client.Must(Client.RegenEventSubscriptionSecret(subscription.Id)).Data.(*model.EventSubscription)
+	if subscription.Secret == oldSecret {
+		t.Fatal("should've changed the secret")
+	}
+
+	Client.Must(Client.DeleteEventSubscription(subscription.Id))
+
+	if subscriptions := Client.Must(Client.GetEventSubscriptions()).Data.([]*model.EventSubscription); len(subscriptions) != 0 {
+		t.Fatal("should've deleted the subscription")
+	}
+
+	*utils.Cfg.ServiceSettings.EnableEventSubscriptions = false
+
+	if _, err := Client.GetEventSubscriptions(); err == nil {
+		t.Fatal("should've failed when event subscriptions are disabled")
+	}
+}
@@ -4,56 +4,36 @@
 package api
 
 import (
-	"bytes"
-	"image"
-	"image/color"
-	"image/draw"
-	_ "image/gif"
-	"image/jpeg"
+	"fmt"
 	"io"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/url"
-	"path/filepath"
+	"os"
 	"strconv"
-	"strings"
 
 	l4g "github.com/alecthomas/log4go"
-	"github.com/disintegration/imaging"
 	"github.com/gorilla/mux"
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
-	"github.com/rwcarlsen/goexif/exif"
-	_ "golang.org/x/image/bmp"
 )
 
 const (
-	/*
-	  EXIF Image Orientations
-	  1        2       3      4         5            6           7          8
-
-	  888888  888888      88  88      8888888888  88                  88  8888888888
-	  88          88      88  88      88  88      88  88          88  88      88  88
-	  8888      8888    8888  8888    88          8888888888  8888888888          88
-	  88          88      88  88
-	  88          88  888888  888888
-	*/
-	Upright            = 1
-	UprightMirrored    = 2
-	UpsideDown         = 3
-	UpsideDownMirrored = 4
-	RotatedCWMirrored  = 5
-	RotatedCCW         = 6
-	RotatedCCWMirrored = 7
-	RotatedCW          = 8
-
-	MaxImageSize = 6048 * 4032 // 24 megapixels, roughly 36MB as a raw image
+	MAX_UPLOAD_FORM_VALUE_SIZE = 1024
 )
 
 func InitFile() {
 	l4g.Debug(utils.T("api.file.init.debug"))
 
 	BaseRoutes.TeamFiles.Handle("/upload", ApiUserRequired(uploadFile)).Methods("POST")
+	BaseRoutes.TeamFiles.Handle("/search", ApiUserRequired(searchFiles)).Methods("POST")
+	BaseRoutes.TeamFiles.Handle("/uploads/create", ApiUserRequired(createUploadSession)).Methods("POST")
+	BaseRoutes.TeamFiles.Handle("/uploads/{upload_id:[A-Za-z0-9]+}", ApiUserRequired(getUploadSession)).Methods("GET")
+	BaseRoutes.TeamFiles.Handle("/uploads/{upload_id:[A-Za-z0-9]+}", ApiUserRequired(uploadChunk)).Methods("PUT")
+	BaseRoutes.TeamFiles.Handle("/uploads/{upload_id:[A-Za-z0-9]+}/finish", ApiUserRequired(finishUploadSession)).Methods("POST")
+	BaseRoutes.TeamFiles.Handle("/uploads/{upload_id:[A-Za-z0-9]+}/delete", ApiUserRequired(deleteUploadSession)).Methods("POST")
 
 	BaseRoutes.NeedFile.Handle("/get", ApiUserRequiredTrustRequester(getFile)).Methods("GET")
 	BaseRoutes.NeedFile.Handle("/get_thumbnail", ApiUserRequiredTrustRequester(getFileThumbnail)).Methods("GET")
@@ -78,231 +58,317 @@ func uploadFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := r.ParseMultipartForm(*utils.Cfg.FileSettings.MaxFileSize); err != nil {
+	reader, err := r.MultipartReader()
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	m := r.MultipartForm
-
-	props := m.Value
-	if len(props["channel_id"]) == 0 {
-		c.SetInvalidParam("uploadFile", "channel_id")
-		return
-	}
-	channelId := props["channel_id"][0]
-	if len(channelId) == 0 {
-		c.SetInvalidParam("uploadFile", "channel_id")
-		return
-	}
-
-	if !HasPermissionToChannelContext(c, channelId, model.PERMISSION_UPLOAD_FILE) {
-		return
-	}
+	// The channel can be provided in the query string so that files can be streamed straight to the file store
+	// instead of waiting for the channel_id field to be read
+	channelId := r.URL.Query().Get("channel_id")
+	hasPermission := false
 
 	resStruct := &model.FileUploadResponse{
 		FileInfos: []*model.FileInfo{},
 		ClientIds: []string{},
 	}
 
-	previewPathList := []string{}
-	thumbnailPathList := []string{}
-	imageDataList := [][]byte{}
-
-	for i, fileHeader := range m.File["files"] {
-		file, fileErr := fileHeader.Open()
-		defer file.Close()
-		if fileErr != nil {
-			http.Error(w, fileErr.Error(), http.StatusInternalServerError)
+	// Files that are sent before the channel_id field are spooled to disk until we know where to store them
+	pendingFiles := []*pendingUploadFile{}
+	defer func() {
+		for _, pending := range pendingFiles {
+			pending.Remove()
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		buf := bytes.NewBuffer(nil)
-		io.Copy(buf, file)
-		data := buf.Bytes()
+		// Each part is handled in its own function so that it's closed however the handling ends
+		ok := func() bool {
+			defer part.Close()
+
+			switch part.FormName() {
+			case "channel_id":
+				if len(channelId) == 0 {
+					channelId = readFormValue(part)
+				}
+			case "client_ids":
+				resStruct.ClientIds = append(resStruct.ClientIds, readFormValue(part))
+			case "files":
+				if len(channelId) == 0 {
+					pending, err := spoolUploadFile(part)
+					if err != nil {
+						c.Err = err
+						return false
+					}
+
+					// Keep a place for the file so that the file infos stay in the same order as the client ids
+					pending.index = len(resStruct.FileInfos)
+					resStruct.FileInfos = append(resStruct.FileInfos, nil)
+
+					pendingFiles = append(pendingFiles, pending)
+					return true
+				}
+
+				if !hasPermission {
+					if !HasPermissionToChannelContext(c, channelId, model.PERMISSION_UPLOAD_FILE) {
+						return false
+					}
+					hasPermission = true
+				}
+
+				info, err := app.UploadFileStream(c.TeamId, channelId, c.Session.UserId, part.FileName(), part)
+				if err != nil {
+					c.Err = err
+					return false
+				}
+
+				resStruct.FileInfos = append(resStruct.FileInfos, info)
+			}
+
+			return true
+		}()
 
-		info, err := doUploadFile(c.TeamId, channelId, c.Session.UserId, fileHeader.Filename, data)
-		if err != nil {
-			c.Err = err
+		if !ok {
 			return
 		}
+	}
 
-		if info.PreviewPath != "" || info.ThumbnailPath != "" {
-			previewPathList = append(previewPathList, info.PreviewPath)
-			thumbnailPathList = append(thumbnailPathList, info.ThumbnailPath)
-			imageDataList = append(imageDataList, data)
+	if len(channelId) == 0 {
+		c.SetInvalidParam("uploadFile", "channel_id")
+		return
+	}
+
+	if len(pendingFiles) > 0 {
+		if !hasPermission && !HasPermissionToChannelContext(c, channelId, model.PERMISSION_UPLOAD_FILE) {
+			return
 		}
 
-		resStruct.FileInfos = append(resStruct.FileInfos, info)
+		for _, pending := range pendingFiles {
+			info, err := pending.Upload(c.TeamId, channelId, c.Session.UserId)
+			if err != nil {
+				c.Err = err
+				return
+			}
 
-		if len(m.Value["client_ids"]) > 0 {
-			resStruct.ClientIds = append(resStruct.ClientIds, m.Value["client_ids"][i])
+			resStruct.FileInfos[pending.index] = info
 		}
 	}
 
-	handleImages(previewPathList, thumbnailPathList, imageDataList)
+	app.HandleImages(resStruct.FileInfos)
 
 	w.Write([]byte(resStruct.ToJson()))
 }
 
-func doUploadFile(teamId string, channelId string, userId string, rawFilename string, data []byte) (*model.FileInfo, *model.AppError) {
-	filename := filepath.Base(rawFilename)
+func readFormValue(part *multipart.Part) string {
+	// Form values are small, so limit how much is read in case the client sends something unexpected
+	value, _ := ioutil.ReadAll(io.LimitReader(part, MAX_UPLOAD_FORM_VALUE_SIZE))
+	return string(value)
+}
 
-	info, err := model.GetInfoForBytes(filename, data)
+type pendingUploadFile struct {
+	filename string
+	file     *os.File
+	index    int
+}
+
+// spoolUploadFile copies a file part into a temporary file on disk so that it doesn't need to be kept in memory.
+func spoolUploadFile(part *multipart.Part) (*pendingUploadFile, *model.AppError) {
+	file, err := ioutil.TempFile("", "mattermost-upload")
 	if err != nil {
-		err.StatusCode = http.StatusBadRequest
+		return nil, model.NewLocAppError("uploadFile", "api.file.upload_file.spool.app_error", nil, err.Error())
+	}
+
+	pending := &pendingUploadFile{
+		filename: part.FileName(),
+		file:     file,
+	}
+
+	// Read one extra byte so that we can tell if the file went over the limit
+	maxFileSize := *utils.Cfg.FileSettings.MaxFileSize
+	if written, err := io.Copy(file, io.LimitReader(part, maxFileSize+1)); err != nil {
+		pending.Remove()
+		return nil, model.NewLocAppError("uploadFile", "api.file.upload_file.spool.app_error", nil, err.Error())
+	} else if written > maxFileSize {
+		pending.Remove()
+
+		err := model.NewLocAppError("uploadFile", "api.file.upload_file.too_large.app_error", nil, "")
+		err.StatusCode = http.StatusRequestEntityTooLarge
 		return nil, err
 	}
 
-	info.Id = model.NewId()
-	info.CreatorId = userId
+	return pending, nil
+}
 
-	pathPrefix := "teams/" + teamId + "/channels/" + channelId + "/users/" + userId + "/" + info.Id + "/"
-	info.Path = pathPrefix + filename
+func (pending *pendingUploadFile) Upload(teamId string, channelId string, userId string) (*model.FileInfo, *model.AppError) {
+	if _, err := pending.file.Seek(0, 0); err != nil {
+		return nil, model.NewLocAppError("uploadFile", "api.file.upload_file.spool.app_error", nil, err.Error())
+	}
 
-	if info.IsImage() {
-		// Check dimensions before loading the whole thing into memory later on
-		if info.Width*info.Height > MaxImageSize {
-			err := model.NewLocAppError("uploadFile", "api.file.upload_file.large_image.app_error", map[string]interface{}{"Filename": filename}, "")
-			err.StatusCode = http.StatusBadRequest
-			return nil, err
-		}
+	return app.UploadFileStream(teamId, channelId, userId, pending.filename, pending.file)
+}
+
+func (pending *pendingUploadFile) Remove() {
+	pending.file.Close()
+	os.Remove(pending.file.Name())
+}
 
-		nameWithoutExtension := filename[:strings.LastIndex(filename, ".")]
-		info.PreviewPath = pathPrefix + nameWithoutExtension + "_preview.jpg"
-		info.ThumbnailPath = pathPrefix + nameWithoutExtension + "_thumb.jpg"
+func createUploadSession(c *Context, w http.ResponseWriter, r *http.Request) {
+	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+		c.Err = model.NewLocAppError("createUploadSession", "api.file.upload_file.storage.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
 	}
 
-	if err := app.WriteFile(data, info.Path); err != nil {
-		return nil, err
+	session := model.UploadSessionFromJson(r.Body)
+	if session == nil {
+		c.SetInvalidParam("createUploadSession", "upload_session")
+		return
 	}
 
-	if result := <-app.Srv.Store.FileInfo().Save(info); result.Err != nil {
-		return nil, result.Err
+	if len(session.ChannelId) != 26 {
+		c.SetInvalidParam("createUploadSession", "channel_id")
+		return
 	}
 
-	return info, nil
+	if !HasPermissionToChannelContext(c, session.ChannelId, model.PERMISSION_UPLOAD_FILE) {
+		return
+	}
+
+	session.Id = ""
+	session.Path = ""
+	session.UserId = c.Session.UserId
+	session.TeamId = c.TeamId
+
+	if rsession, err := app.CreateUploadSession(session); err != nil {
+		c.Err = err
+		return
+	} else {
+		c.LogAudit("id=" + rsession.Id)
+		w.Write([]byte(rsession.ToJson()))
+	}
 }
 
-func handleImages(previewPathList []string, thumbnailPathList []string, fileData [][]byte) {
-	for i, data := range fileData {
-		go func(i int, data []byte) {
-			img, width, height := prepareImage(fileData[i])
-			if img != nil {
-				go generateThumbnailImage(*img, thumbnailPathList[i], width, height)
-				go generatePreviewImage(*img, previewPathList[i], width)
-			}
-		}(i, data)
+func getUploadSessionForRequest(c *Context, r *http.Request) (*model.UploadSession, *model.AppError) {
+	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+		err := model.NewLocAppError("getUploadSessionForRequest", "api.file.upload_file.storage.app_error", nil, "")
+		err.StatusCode = http.StatusNotImplemented
+		return nil, err
+	}
+
+	uploadId := mux.Vars(r)["upload_id"]
+	if len(uploadId) != 26 {
+		return nil, NewInvalidParamError("getUploadSessionForRequest", "upload_id")
 	}
+
+	session, err := app.GetUploadSession(uploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the user that started an upload can see or continue it
+	if session.UserId != c.Session.UserId || session.TeamId != c.TeamId {
+		err := model.NewLocAppError("getUploadSessionForRequest", "api.file.get_upload_session.permissions.app_error", nil, "id="+uploadId)
+		err.StatusCode = http.StatusNotFound
+		return nil, err
+	}
+
+	return session, nil
 }
 
-func prepareImage(fileData []byte) (*image.Image, int, int) {
-	// Decode image bytes into Image object
-	img, imgType, err := image.Decode(bytes.NewReader(fileData))
+func getUploadSession(c *Context, w http.ResponseWriter, r *http.Request) {
+	session, err := getUploadSessionForRequest(c, r)
 	if err != nil {
-		l4g.Error(utils.T("api.file.handle_images_forget.decode.error"), err)
-		return nil, 0, 0
-	}
-
-	width := img.Bounds().Dx()
-	height := img.Bounds().Dy()
-
-	// Fill in the background of a potentially-transparent png file as white
-	if imgType == "png" {
-		dst := image.NewRGBA(img.Bounds())
-		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
-		draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
-		img = dst
-	}
-
-	// Flip the image to be upright
-	orientation, _ := getImageOrientation(fileData)
-
-	switch orientation {
-	case UprightMirrored:
-		img = imaging.FlipH(img)
-	case UpsideDown:
-		img = imaging.Rotate180(img)
-	case UpsideDownMirrored:
-		img = imaging.FlipV(img)
-	case RotatedCWMirrored:
-		img = imaging.Transpose(img)
-	case RotatedCCW:
-		img = imaging.Rotate270(img)
-	case RotatedCCWMirrored:
-		img = imaging.Transverse(img)
-	case RotatedCW:
-		img = imaging.Rotate90(img)
-	}
-
-	return &img, width, height
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(session.ToJson()))
 }
 
-func getImageOrientation(imageData []byte) (int, error) {
-	if exifData, err := exif.Decode(bytes.NewReader(imageData)); err != nil {
-		return Upright, err
-	} else {
-		if tag, err := exifData.Get("Orientation"); err != nil {
-			return Upright, err
-		} else {
-			orientation, err := tag.Int(0)
-			if err != nil {
-				return Upright, err
-			} else {
-				return orientation, nil
-			}
+// parseUploadOffset finds where a chunk belongs in the file, either from a Content-Range header of the form
+// "bytes {start}-{end}/{total}" or from the offset query parameter.
+func parseUploadOffset(r *http.Request) (int64, bool) {
+	if contentRange := r.Header.Get("Content-Range"); len(contentRange) > 0 {
+		var start, end, total int64
+		if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil || start > end {
+			return 0, false
 		}
+
+		return start, true
 	}
-}
 
-func generateThumbnailImage(img image.Image, thumbnailPath string, width int, height int) {
-	thumbWidth := float64(utils.Cfg.FileSettings.ThumbnailWidth)
-	thumbHeight := float64(utils.Cfg.FileSettings.ThumbnailHeight)
-	imgWidth := float64(width)
-	imgHeight := float64(height)
-
-	var thumbnail image.Image
-	if imgHeight < thumbHeight && imgWidth < thumbWidth {
-		thumbnail = img
-	} else if imgHeight/imgWidth < thumbHeight/thumbWidth {
-		thumbnail = imaging.Resize(img, 0, utils.Cfg.FileSettings.ThumbnailHeight, imaging.Lanczos)
+	if offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64); err != nil || offset < 0 {
+		return 0, false
 	} else {
-		thumbnail = imaging.Resize(img, utils.Cfg.FileSettings.ThumbnailWidth, 0, imaging.Lanczos)
+		return offset, true
 	}
+}
 
-	buf := new(bytes.Buffer)
-	if err := jpeg.Encode(buf, thumbnail, &jpeg.Options{Quality: 90}); err != nil {
-		l4g.Error(utils.T("api.file.handle_images_forget.encode_jpeg.error"), thumbnailPath, err)
+func uploadChunk(c *Context, w http.ResponseWriter, r *http.Request) {
+	session, err := getUploadSessionForRequest(c, r)
+	if err != nil {
+		c.Err = err
 		return
 	}
 
-	if err := app.WriteFile(buf.Bytes(), thumbnailPath); err != nil {
-		l4g.Error(utils.T("api.file.handle_images_forget.upload_thumb.error"), thumbnailPath, err)
+	offset, ok := parseUploadOffset(r)
+	if !ok {
+		c.SetInvalidParam("uploadChunk", "offset")
 		return
 	}
-}
 
-func generatePreviewImage(img image.Image, previewPath string, width int) {
-	var preview image.Image
-	if width > int(utils.Cfg.FileSettings.PreviewWidth) {
-		preview = imaging.Resize(img, utils.Cfg.FileSettings.PreviewWidth, utils.Cfg.FileSettings.PreviewHeight, imaging.Lanczos)
+	if rsession, err := app.UploadChunk(session, offset, r.Body); err != nil {
+		c.Err = err
+		return
 	} else {
-		preview = img
+		w.Write([]byte(rsession.ToJson()))
 	}
+}
 
-	buf := new(bytes.Buffer)
+func finishUploadSession(c *Context, w http.ResponseWriter, r *http.Request) {
+	session, err := getUploadSessionForRequest(c, r)
+	if err != nil {
+		c.Err = err
+		return
+	}
 
-	if err := jpeg.Encode(buf, preview, &jpeg.Options{Quality: 90}); err != nil {
-		l4g.Error(utils.T("api.file.handle_images_forget.encode_preview.error"), previewPath, err)
+	if !HasPermissionToChannelContext(c, session.ChannelId, model.PERMISSION_UPLOAD_FILE) {
 		return
 	}
 
-	if err := app.WriteFile(buf.Bytes(), previewPath); err != nil {
-		l4g.Error(utils.T("api.file.handle_images_forget.upload_preview.error"), previewPath, err)
+	if info, err := app.FinishUploadSession(session); err != nil {
+		c.Err = err
 		return
+	} else {
+		w.Write([]byte(info.ToJson()))
 	}
 }
 
+func deleteUploadSession(c *Context, w http.ResponseWriter, r *http.Request) {
+	session, err := getUploadSessionForRequest(c, r)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if err := app.DeleteUploadSession(session); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + session.Id)
+	ReturnStatusOK(w)
+}
+
 func getFile(c *Context, w http.ResponseWriter, r *http.Request) {
 	info, err := getFileInfoForRequest(c, r, true)
 	if err != nil {
@@ -310,12 +376,16 @@ func getFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.Path); err != nil {
+	if reader, err := app.FileReader(info.Path); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, info.MimeType, data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer reader.Close()
+
+		if err := writeFileResponse(info.Name, info.MimeType, info.Size, reader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
@@ -332,12 +402,16 @@ func getFileThumbnail(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.ThumbnailPath); err != nil {
+	if reader, err := app.FileReader(info.ThumbnailPath); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, "", data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer reader.Close()
+
+		if err := writeFileResponse(info.Name, "", 0, reader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
@@ -354,12 +428,16 @@ func getFilePreview(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.PreviewPath); err != nil {
+	if reader, err := app.FileReader(info.PreviewPath); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, "", data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer reader.Close()
+
+		if err := writeFileResponse(info.Name, "", 0, reader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
@@ -375,6 +453,30 @@ func getFileInfo(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(info.ToJson()))
 }
 
+func searchFiles(c *Context, w http.ResponseWriter, r *http.Request) {
+	props := model.StringInterfaceFromJson(r.Body)
+
+	terms, _ := props["terms"].(string)
+	if len(terms) == 0 {
+		c.SetInvalidParam("searchFiles", "terms")
+		return
+	}
+
+	isOrSearch := false
+	if val, ok := props["is_or_search"]; ok && val != nil {
+		isOrSearch, _ = val.(bool)
+	}
+
+	infos, err := app.SearchFilesInTeam(terms, c.Session.UserId, c.TeamId, isOrSearch)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Write([]byte(model.FileInfosToJson(infos)))
+}
+
 func getPublicFile(c *Context, w http.ResponseWriter, r *http.Request) {
 	if !utils.Cfg.FileSettings.EnablePublicLink {
 		c.Err = model.NewLocAppError("getPublicFile", "api.file.get_file.public_disabled.app_error", nil, "")
@@ -404,12 +506,16 @@ func getPublicFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.Path); err != nil {
+	if reader, err := app.FileReader(info.Path); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, info.MimeType, data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer reader.Close()
+
+		if err := writeFileResponse(info.Name, info.MimeType, info.Size, reader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
@@ -502,18 +608,24 @@ func getPublicFileOld(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if data, err := app.ReadFile(info.Path); err != nil {
+	if reader, err := app.FileReader(info.Path); err != nil {
 		c.Err = err
 		c.Err.StatusCode = http.StatusNotFound
-	} else if err := writeFileResponse(info.Name, info.MimeType, data, w, r); err != nil {
-		c.Err = err
-		return
+	} else {
+		defer reader.Close()
+
+		if err := writeFileResponse(info.Name, info.MimeType, info.Size, reader, w, r); err != nil {
+			c.Err = err
+			return
+		}
 	}
 }
 
-func writeFileResponse(filename string, contentType string, bytes []byte, w http.ResponseWriter, r *http.Request) *model.AppError {
+func writeFileResponse(filename string, contentType string, contentSize int64, fileReader io.Reader, w http.ResponseWriter, r *http.Request) *model.AppError {
 	w.Header().Set("Cache-Control", "max-age=2592000, public")
-	w.Header().Set("Content-Length", strconv.Itoa(len(bytes)))
+	if contentSize > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(contentSize, 10))
+	}
 
 	if contentType != "" {
 		w.Header().Set("Content-Type", contentType)
@@ -527,7 +639,10 @@ func writeFileResponse(filename string, contentType string, bytes []byte, w http
 	w.Header().Set("X-Frame-Options", "DENY")
 	w.Header().Set("Content-Security-Policy", "Frame-ancestors 'none'")
 
-	w.Write(bytes)
+	// Stream the file to the client instead of loading it into memory first
+	if _, err := io.Copy(w, fileReader); err != nil {
+		return model.NewLocAppError("writeFileResponse", "api.file.write_file_response.copy.app_error", nil, err.Error())
+	}
 
 	return nil
 }
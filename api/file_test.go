@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"strings"
@@ -78,6 +79,12 @@ func TestUploadFile(t *testing.T) {
 		t.Fatal("file thumbnail path should be set in database")
 	} else if info.PreviewPath == "" {
 		t.Fatal("file preview path should be set in database")
+	} else if info.Size != 279591 {
+		t.Fatal("file size should've been computed while uploading")
+	} else if len(info.Hash) != 64 {
+		t.Fatal("file hash should've been computed while uploading")
+	} else if info.Width != 408 || info.Height != 336 {
+		t.Fatal("image dimensions should've been read from the stored file")
 	}
 
 	// This also makes sure that the relative path provided above is sanitized out
@@ -107,6 +114,344 @@ func TestUploadFile(t *testing.T) {
 	}
 }
 
+func TestUploadFileBeforeChannelId(t *testing.T) {
+	th := Setup().InitBasic()
+
+	if utils.Cfg.FileSettings.DriverName == "" {
+		t.Skip("skipping because no file driver is enabled")
+	}
+
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	data, err := readTestFile("test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first file is sent before the channel, so it has to be spooled and uploaded after the second one
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, field := range []struct{ name, value string }{
+		{"client_ids", "first"},
+		{"files", "first.png"},
+		{"channel_id", channel.Id},
+		{"client_ids", "second"},
+		{"files", "second.png"},
+	} {
+		if field.name == "files" {
+			part, _ := writer.CreateFormFile(field.name, field.value)
+			part.Write(data)
+		} else {
+			writer.WriteField(field.name, field.value)
+		}
+	}
+	writer.Close()
+
+	r, _ := http.NewRequest("POST", Client.ApiUrl+Client.GetTeamRoute()+"/files/upload", body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	r.Header.Set(model.HEADER_AUTH, "BEARER "+Client.AuthToken)
+
+	resp, err := Client.HttpClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("upload failed", resp.Status)
+	}
+
+	res := model.FileUploadResponseFromJson(resp.Body)
+	if len(res.FileInfos) != 2 || len(res.ClientIds) != 2 {
+		t.Fatal("should've uploaded both files")
+	} else if res.FileInfos[0].Name != "first.png" || res.ClientIds[0] != "first" {
+		t.Fatal("the file sent first should be first")
+	} else if res.FileInfos[1].Name != "second.png" || res.ClientIds[1] != "second" {
+		t.Fatal("the file sent second should be second")
+	}
+
+	// Wait a bit for files to ready
+	time.Sleep(2 * time.Second)
+
+	for _, info := range res.FileInfos {
+		if result := <-app.Srv.Store.FileInfo().Get(info.Id); result.Err != nil {
+			t.Fatal(result.Err)
+		} else if err := cleanupTestFile(result.Data.(*model.FileInfo)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUploadSession(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+
+	if utils.Cfg.FileSettings.DriverName == "" {
+		t.Skip("skipping because no file driver is enabled")
+	}
+
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	data, err := readTestFile("test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, appErr := Client.CreateUploadSession(channel.Id, "../test.png", int64(len(data)))
+	if appErr != nil {
+		t.Fatal(appErr)
+	} else if session.Filename != "test.png" {
+		t.Fatal("filename should've been sanitized")
+	} else if session.FileOffset != 0 || session.FileSize != int64(len(data)) {
+		t.Fatal("upload session should start empty")
+	}
+
+	if _, err := Client.CreateUploadSession(channel.Id, "test.png", *utils.Cfg.FileSettings.MaxFileSize+1); err == nil {
+		t.Fatal("shouldn't be able to create an upload session for a file that's too large")
+	}
+
+	half := int64(len(data) / 2)
+
+	if _, err := Client.UploadChunk(session.Id, 0, data[:half]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Client.UploadChunk(session.Id, 0, data[:half]); err == nil {
+		t.Fatal("shouldn't be able to upload a chunk at the wrong offset")
+	}
+
+	if _, err := Client.FinishUploadSession(session.Id); err == nil {
+		t.Fatal("shouldn't be able to finish an incomplete upload")
+	}
+
+	th.SystemAdminClient.SetTeamId(th.BasicTeam.Id)
+	if _, err := th.SystemAdminClient.GetUploadSession(session.Id); err == nil {
+		t.Fatal("shouldn't be able to get another user's upload session")
+	}
+
+	if resumed, err := Client.GetUploadSession(session.Id); err != nil {
+		t.Fatal(err)
+	} else if resumed.FileOffset != half {
+		t.Fatal("upload session should report the number of bytes received so far")
+	}
+
+	if updated, err := Client.UploadChunk(session.Id, half, data[half:]); err != nil {
+		t.Fatal(err)
+	} else if updated.FileOffset != int64(len(data)) {
+		t.Fatal("upload session should be complete")
+	}
+
+	info, appErr := Client.FinishUploadSession(session.Id)
+	if appErr != nil {
+		t.Fatal(appErr)
+	} else if info.Size != int64(len(data)) {
+		t.Fatal("file size should match the uploaded data")
+	} else if info.Name != "test.png" {
+		t.Fatal("file name should match the upload session")
+	}
+
+	if _, err := Client.GetUploadSession(session.Id); err == nil {
+		t.Fatal("upload session should've been removed once finished")
+	}
+
+	if _, err := Client.FinishUploadSession(session.Id); err == nil {
+		t.Fatal("shouldn't be able to finish an upload session twice")
+	}
+
+	if received, err := Client.GetFile(info.Id); err != nil {
+		t.Fatal(err)
+	} else {
+		defer received.Close()
+
+		receivedData, err := ioutil.ReadAll(received)
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(receivedData, data) {
+			t.Fatal("received file didn't match uploaded data")
+		}
+	}
+
+	other, appErr := Client.CreateUploadSession(channel.Id, "test.png", int64(len(data)))
+	if appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	if _, err := Client.UploadChunk(other.Id, 0, data[:half]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Client.DeleteUploadSession(other.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Client.GetUploadSession(other.Id); err == nil {
+		t.Fatal("upload session should've been deleted")
+	}
+
+	// Wait a bit for files to ready
+	time.Sleep(2 * time.Second)
+
+	if result := <-app.Srv.Store.FileInfo().Get(info.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if err := cleanupTestFile(result.Data.(*model.FileInfo)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFinishUploadSessionClaimed(t *testing.T) {
+	th := Setup().InitBasic()
+
+	if utils.Cfg.FileSettings.DriverName == "" {
+		t.Skip("skipping because no file driver is enabled")
+	}
+
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	data, err := readTestFile("test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, appErr := Client.CreateUploadSession(channel.Id, "test.png", int64(len(data)))
+	if appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	if _, err := Client.UploadChunk(session.Id, 0, data); err != nil {
+		t.Fatal(err)
+	}
+
+	claimedAt := model.GetMillis()
+	if result := <-app.Srv.Store.UploadSession().Claim(session.Id, claimedAt); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if _, err := Client.FinishUploadSession(session.Id); err == nil || err.StatusCode != http.StatusConflict {
+		t.Fatal("shouldn't be able to finish an upload session that's already being finished")
+	}
+
+	if _, err := Client.DeleteUploadSession(session.Id); err == nil || err.StatusCode != http.StatusConflict {
+		t.Fatal("shouldn't be able to delete an upload session that's being finished")
+	}
+
+	if _, err := Client.GetUploadSession(session.Id); err != nil {
+		t.Fatal("upload session should still exist while it's being finished")
+	}
+
+	store.Must(app.Srv.Store.UploadSession().ReleaseClaim(session.Id, claimedAt))
+
+	info, appErr := Client.FinishUploadSession(session.Id)
+	if appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	// Wait a bit for files to ready
+	time.Sleep(2 * time.Second)
+
+	if result := <-app.Srv.Store.FileInfo().Get(info.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if err := cleanupTestFile(result.Data.(*model.FileInfo)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFinishUploadSessionSizeMismatch(t *testing.T) {
+	th := Setup().InitBasic()
+
+	if utils.Cfg.FileSettings.DriverName == "" {
+		t.Skip("skipping because no file driver is enabled")
+	}
+
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	data, err := readTestFile("test.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, appErr := Client.CreateUploadSession(channel.Id, "test.png", int64(len(data)))
+	if appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	half := int64(len(data) / 2)
+
+	if _, err := Client.UploadChunk(session.Id, 0, data[:half]); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Client.UploadChunk(session.Id, half, data[half:]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lose one of the stored chunks so that the finished file comes out smaller than expected
+	stored := store.Must(app.Srv.Store.UploadSession().Get(session.Id)).(*model.UploadSession)
+	if err := app.RemoveFile(fmt.Sprintf("%s/chunks/%020d", stored.Path, half)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Client.FinishUploadSession(session.Id); err == nil || err.StatusCode != http.StatusBadRequest {
+		t.Fatal("shouldn't be able to finish an upload session when the stored file is the wrong size")
+	}
+
+	if _, err := Client.GetUploadSession(session.Id); err == nil {
+		t.Fatal("upload session should've been removed")
+	}
+}
+
+func TestSearchFiles(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	if utils.Cfg.FileSettings.DriverName == "" {
+		t.Skip("skipping because no file driver is enabled")
+	}
+
+	upload := func(filename string, data string) string {
+		return Client.MustGeneric(Client.UploadPostAttachment([]byte(data), channel.Id, filename)).(*model.FileUploadResponse).FileInfos[0].Id
+	}
+
+	fileId1 := upload("budget.csv", "department,amount\nmarketing,1000\nengineering,5000\n")
+	fileId2 := upload("minutes.md", "# Minutes\n\nWe agreed on the marketing budget.")
+	fileId3 := upload("unposted.txt", "marketing")
+
+	Client.Must(Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "files", FileIds: []string{fileId1, fileId2}}))
+
+	if infos := Client.Must(Client.SearchFiles("engineering", false)).Data.([]*model.FileInfo); len(infos) != 1 || infos[0].Id != fileId1 {
+		t.Fatal("should've found the file by its content")
+	}
+
+	if infos := Client.Must(Client.SearchFiles("marketing", false)).Data.([]*model.FileInfo); len(infos) != 2 {
+		t.Fatal("should've found both attached files")
+	} else {
+		for _, info := range infos {
+			if info.Id == fileId3 {
+				t.Fatal("shouldn't have found a file that isn't attached to a post")
+			}
+		}
+	}
+
+	if infos := Client.Must(Client.SearchFiles("marketing ext:md", false)).Data.([]*model.FileInfo); len(infos) != 1 || infos[0].Id != fileId2 {
+		t.Fatal("should've only found files with the given extension")
+	}
+
+	if infos := Client.Must(Client.SearchFiles("minutes", false)).Data.([]*model.FileInfo); len(infos) != 1 || infos[0].Id != fileId2 {
+		t.Fatal("should've found the file by its name")
+	}
+
+	if infos := Client.Must(Client.SearchFiles("*", false)).Data.([]*model.FileInfo); len(infos) != 0 {
+		t.Fatal("searching for just * shouldn't return any results")
+	}
+
+	if _, err := Client.SearchFiles("", false); err == nil {
+		t.Fatal("should've failed without any search terms")
+	}
+}
+
 func TestGetFileInfo(t *testing.T) {
 	th := Setup().InitBasic()
 
@@ -4,7 +4,6 @@
 package api
 
 import (
-	"bytes"
 	"io"
 	"regexp"
 	"unicode/utf8"
@@ -37,6 +36,10 @@ func ImportPost(post *model.Post) {
 
 		if result := <-app.Srv.Store.Post().Save(post); result.Err != nil {
 			l4g.Debug(utils.T("api.import.import_post.saving.debug"), post.UserId, post.Message)
+		} else if !post.IsSystemMessage() {
+			// the post is reused for the remainder of the message so index a copy of it
+			indexed := *result.Data.(*model.Post)
+			app.IndexPost(&indexed)
 		}
 
 		for _, fileId := range post.FileIds {
@@ -70,6 +73,8 @@ func ImportUser(team *model.Team, user *model.User) *model.User {
 			l4g.Error(utils.T("api.import.import_user.join_team.error"), err)
 		}
 
+		app.IndexUserById(ruser.Id)
+
 		return ruser
 	}
 }
@@ -80,24 +85,20 @@ func ImportChannel(channel *model.Channel) *model.Channel {
 	} else {
 		sc := result.Data.(*model.Channel)
 
+		app.IndexChannel(sc)
+
 		return sc
 	}
 }
 
 func ImportFile(file io.Reader, teamId string, channelId string, userId string, fileName string) (*model.FileInfo, error) {
-	buf := bytes.NewBuffer(nil)
-	io.Copy(buf, file)
-	data := buf.Bytes()
-
-	fileInfo, err := doUploadFile(teamId, channelId, userId, fileName, data)
+	fileInfo, err := app.UploadFileStream(teamId, channelId, userId, fileName, file)
 	if err != nil {
 		return nil, err
 	}
 
-	img, width, height := prepareImage(data)
-	if img != nil {
-		generateThumbnailImage(*img, fileInfo.ThumbnailPath, width, height)
-		generatePreviewImage(*img, fileInfo.PreviewPath, width)
+	if fileInfo.PreviewPath != "" || fileInfo.ThumbnailPath != "" {
+		app.GenerateImages(fileInfo)
 	}
 
 	return fileInfo, nil
@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitLegalHold() {
+	l4g.Debug(utils.T("api.legal_hold.init.debug"))
+
+	BaseRoutes.Admin.Handle("/legal_holds", ApiAdminSystemRequired(getLegalHolds)).Methods("GET")
+	BaseRoutes.Admin.Handle("/legal_holds/create", ApiAdminSystemRequired(createLegalHold)).Methods("POST")
+	BaseRoutes.Admin.Handle("/legal_holds/{legal_hold_id:[A-Za-z0-9]+}/update", ApiAdminSystemRequired(updateLegalHold)).Methods("POST")
+	BaseRoutes.Admin.Handle("/legal_holds/{legal_hold_id:[A-Za-z0-9]+}/delete", ApiAdminSystemRequired(deleteLegalHold)).Methods("POST")
+}
+
+func getLegalHolds(c *Context, w http.ResponseWriter, r *http.Request) {
+	if holds, err := app.GetAllLegalHolds(); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(model.LegalHoldsToJson(holds)))
+	}
+}
+
+func createLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	hold := model.LegalHoldFromJson(r.Body)
+	if hold == nil {
+		c.SetInvalidParam("createLegalHold", "legal_hold")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	hold.Id = ""
+	hold.CreatorId = c.Session.UserId
+
+	if rhold, err := app.CreateLegalHold(hold); err != nil {
+		c.Err = err
+	} else {
+		c.LogAudit("success id=" + rhold.Id)
+		w.Write([]byte(rhold.ToJson()))
+	}
+}
+
+func updateLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	hold := model.LegalHoldFromJson(r.Body)
+	if hold == nil {
+		c.SetInvalidParam("updateLegalHold", "legal_hold")
+		return
+	}
+
+	if hold.Id != params["legal_hold_id"] {
+		c.SetInvalidParam("updateLegalHold", "id")
+		return
+	}
+
+	c.LogAudit("attempt id=" + hold.Id)
+
+	if rhold, err := app.UpdateLegalHold(hold); err != nil {
+		c.Err = err
+	} else {
+		c.LogAudit("success id=" + rhold.Id)
+		w.Write([]byte(rhold.ToJson()))
+	}
+}
+
+func deleteLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id := params["legal_hold_id"]
+
+	c.LogAudit("attempt id=" + id)
+
+	if err := app.DeleteLegalHold(id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success id=" + id)
+	ReturnStatusOK(w)
+}
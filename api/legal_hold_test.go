@@ -0,0 +1,92 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+)
+
+func TestLegalHolds(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	Client := th.SystemAdminClient
+
+	hold := &model.LegalHold{Name: "hold", UserIds: []string{th.BasicUser.Id}}
+
+	if _, err := th.BasicClient.CreateLegalHold(hold); err == nil {
+		t.Fatal("shouldn't have permissions")
+	}
+
+	rhold, err := Client.CreateLegalHold(hold)
+	if err != nil {
+		t.Fatal(err)
+	} else if rhold.CreatorId != th.SystemAdminUser.Id {
+		t.Fatal("should've set the creator")
+	}
+	defer app.DeleteLegalHold(rhold.Id)
+
+	if _, err := Client.CreateLegalHold(&model.LegalHold{Name: "empty"}); err == nil {
+		t.Fatal("shouldn't have created a legal hold without any users or channels")
+	}
+
+	if holds, err := Client.GetLegalHolds(); err != nil {
+		t.Fatal(err)
+	} else {
+		found := false
+		for _, h := range holds {
+			if h.Id == rhold.Id {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should've returned the legal hold")
+		}
+	}
+
+	rhold.ChannelIds = []string{th.BasicChannel.Id}
+	if updated, err := Client.UpdateLegalHold(rhold); err != nil {
+		t.Fatal(err)
+	} else if len(updated.ChannelIds) != 1 || updated.CreatorId != th.SystemAdminUser.Id {
+		t.Fatal("should've updated the legal hold")
+	}
+
+	if err := PermanentDeleteUser(th.BasicUser); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("shouldn't have deleted a user under a legal hold")
+	}
+
+	if err := PermanentDeleteTeam(th.BasicTeam); err == nil || err.StatusCode != http.StatusForbidden {
+		t.Fatal("shouldn't have deleted a team with a channel under a legal hold")
+	}
+
+	if audits, err := app.GetAudits(th.BasicUser.Id, 100); err != nil {
+		t.Fatal(err)
+	} else {
+		found := false
+		for _, audit := range audits {
+			if audit.Action == app.LEGAL_HOLD_AUDIT_BLOCKED_DELETION {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("should've audited the blocked deletion")
+		}
+	}
+
+	if ok, err := Client.DeleteLegalHold(rhold.Id); err != nil || !ok {
+		t.Fatal("should've deleted the legal hold", err)
+	}
+
+	if _, err := Client.DeleteLegalHold(rhold.Id); err == nil {
+		t.Fatal("should've failed to delete a legal hold that doesn't exist")
+	}
+
+	if err := PermanentDeleteUser(th.BasicUser2); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -289,6 +289,7 @@ func completeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 				c.Err = err
 			} else {
 				doLogin(c, w, r, user, "")
+				saveOpenIdRefreshToken(c, service, props)
 			}
 			if c.Err == nil {
 				http.Redirect(w, r, GetProtocol(r)+"://"+r.Host, http.StatusTemporaryRedirect)
@@ -296,6 +297,7 @@ func completeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 			break
 		case model.OAUTH_ACTION_LOGIN:
 			user := LoginByOAuth(c, w, r, service, body)
+			saveOpenIdRefreshToken(c, service, props)
 			if len(teamId) > 0 {
 				c.Err = app.JoinUserToTeamById(teamId, user)
 			}
@@ -321,6 +323,7 @@ func completeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 			break
 		default:
 			LoginByOAuth(c, w, r, service, body)
+			saveOpenIdRefreshToken(c, service, props)
 			if c.Err == nil {
 				http.Redirect(w, r, GetProtocol(r)+"://"+r.Host, http.StatusTemporaryRedirect)
 			}
@@ -329,6 +332,20 @@ func completeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// saveOpenIdRefreshToken keeps the refresh token issued by an OpenID Connect provider with the session that was just
+// created so that the provider can be asked later on whether the user is still allowed to sign in.
+func saveOpenIdRefreshToken(c *Context, service string, props map[string]string) {
+	if c.Err != nil || service != model.USER_AUTH_SERVICE_OPENID || len(c.Session.Id) == 0 {
+		return
+	}
+
+	if refreshToken := props[model.SESSION_PROP_OPENID_REFRESH_TOKEN]; len(refreshToken) > 0 {
+		if err := app.SetOpenIdRefreshToken(&c.Session, refreshToken); err != nil {
+			c.LogError(err)
+		}
+	}
+}
+
 func authorizeOAuth(c *Context, w http.ResponseWriter, r *http.Request) {
 	if !utils.Cfg.ServiceSettings.EnableOAuthServiceProvider {
 		c.Err = model.NewLocAppError("authorizeOAuth", "api.oauth.authorize_oauth.disabled.app_error", nil, "")
@@ -699,6 +716,14 @@ func GetAuthorizationCode(c *Context, service string, props map[string]string, l
 	endpoint := sso.AuthEndpoint
 	scope := sso.Scope
 
+	if provider, ok := einterfaces.GetOauthProvider(service).(einterfaces.OpenIdProvider); ok {
+		if authEndpoint, err := provider.GetAuthEndpoint(sso); err != nil {
+			return "", err
+		} else {
+			endpoint = authEndpoint
+		}
+	}
+
 	props["hash"] = model.HashPassword(clientId)
 	state := b64.StdEncoding.EncodeToString([]byte(model.MapToJson(props)))
 
@@ -738,6 +763,19 @@ func AuthorizeOAuthUser(service, code, state, redirectUri string) (io.ReadCloser
 
 	teamId := stateProps["team_id"]
 
+	if provider, ok := einterfaces.GetOauthProvider(service).(einterfaces.OpenIdProvider); ok {
+		tokens, err := provider.RedeemCode(sso, code, redirectUri)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		// The refresh token is returned with the state so that it can be saved with the session once the user has
+		// signed in
+		stateProps[model.SESSION_PROP_OPENID_REFRESH_TOKEN] = tokens.RefreshToken
+
+		return ioutil.NopCloser(strings.NewReader(tokens.Claims)), teamId, stateProps, nil
+	}
+
 	p := url.Values{}
 	p.Set("client_id", sso.Id)
 	p.Set("client_secret", sso.Secret)
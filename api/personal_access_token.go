@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitPersonalAccessToken() {
+	l4g.Debug(utils.T("api.personal_access_token.init.debug"))
+
+	BaseRoutes.NeedUser.Handle("/tokens", ApiUserRequired(getPersonalAccessTokens)).Methods("GET")
+	BaseRoutes.NeedUser.Handle("/tokens/create", ApiUserRequired(createPersonalAccessToken)).Methods("POST")
+	BaseRoutes.Users.Handle("/tokens/revoke", ApiUserRequired(revokePersonalAccessToken)).Methods("POST")
+}
+
+func getPersonalAccessTokens(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userId := params["user_id"]
+
+	if !HasPermissionToUser(c, userId) {
+		return
+	}
+
+	if tokens, err := app.GetPersonalAccessTokensForUser(userId); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(model.PersonalAccessTokensToJson(tokens)))
+	}
+}
+
+func createPersonalAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	userId := params["user_id"]
+
+	props := model.MapFromJson(r.Body)
+	if props == nil {
+		c.SetInvalidParam("createPersonalAccessToken", "description")
+		return
+	}
+
+	if !HasPermissionToUser(c, userId) {
+		return
+	}
+
+	c.LogAudit("attempt user_id=" + userId)
+
+	token, err := app.CreatePersonalAccessToken(userId, props["description"])
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success user_id=" + userId + " token_id=" + token.Id)
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	w.Write([]byte(token.ToJson()))
+}
+
+func revokePersonalAccessToken(c *Context, w http.ResponseWriter, r *http.Request) {
+	props := model.MapFromJson(r.Body)
+	tokenId := props["token_id"]
+	if len(tokenId) != 26 {
+		c.SetInvalidParam("revokePersonalAccessToken", "token_id")
+		return
+	}
+
+	token, err := app.GetPersonalAccessToken(tokenId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !HasPermissionToUser(c, token.UserId) {
+		return
+	}
+
+	c.LogAudit("attempt user_id=" + token.UserId + " token_id=" + token.Id)
+
+	if err := app.RevokePersonalAccessToken(token); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success user_id=" + token.UserId + " token_id=" + token.Id)
+
+	ReturnStatusOK(w)
+}
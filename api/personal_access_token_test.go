@@ -0,0 +1,88 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func TestPersonalAccessTokens(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	Client := th.BasicClient
+
+	enableTokens := *utils.Cfg.ServiceSettings.EnablePersonalAccessTokens
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnablePersonalAccessTokens = enableTokens
+	}()
+	*utils.Cfg.ServiceSettings.EnablePersonalAccessTokens = false
+
+	if _, err := Client.CreatePersonalAccessToken(th.BasicUser.Id, "script"); err == nil {
+		t.Fatal("should have failed - personal access tokens are disabled")
+	}
+
+	*utils.Cfg.ServiceSettings.EnablePersonalAccessTokens = true
+
+	if _, err := Client.CreatePersonalAccessToken(th.BasicUser2.Id, "script"); err == nil {
+		t.Fatal("shouldn't have permissions to create a token for another user")
+	}
+
+	result, err := Client.CreatePersonalAccessToken(th.BasicUser.Id, "script")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := result.Data.(*model.PersonalAccessToken)
+
+	if len(token.Token) != 26 || token.UserId != th.BasicUser.Id || token.Description != "script" {
+		t.Fatal("should've returned the new token")
+	}
+
+	if result, err := Client.GetPersonalAccessTokens(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	} else if tokens := result.Data.([]*model.PersonalAccessToken); len(tokens) != 1 || tokens[0].Id != token.Id {
+		t.Fatal("should've listed the token")
+	} else if tokens[0].Token != "" {
+		t.Fatal("shouldn't have returned the token again")
+	}
+
+	if _, err := Client.GetPersonalAccessTokens(th.BasicUser2.Id); err == nil {
+		t.Fatal("shouldn't have permissions to list another user's tokens")
+	}
+
+	if _, err := th.SystemAdminClient.GetPersonalAccessTokens(th.BasicUser.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	TokenClient := th.CreateClient()
+	TokenClient.AuthToken = token.Token
+	TokenClient.AuthType = model.HEADER_BEARER
+
+	if result, err := TokenClient.GetMe(""); err != nil {
+		t.Fatal(err)
+	} else if result.Data.(*model.User).Id != th.BasicUser.Id {
+		t.Fatal("should've authenticated as the owner of the token")
+	}
+
+	if _, err := th.CreateClient().RevokePersonalAccessToken(token.Id); err == nil {
+		t.Fatal("should have failed - not logged in")
+	}
+
+	th.LoginBasic2()
+
+	if _, err := Client.RevokePersonalAccessToken(token.Id); err == nil {
+		t.Fatal("shouldn't have permissions to revoke another user's token")
+	}
+
+	th.LoginBasic()
+
+	if _, err := Client.RevokePersonalAccessToken(token.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := TokenClient.GetMe(""); err == nil {
+		t.Fatal("shouldn't have been able to use a revoked token")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/plugin"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitPlugin() {
+	l4g.Debug(utils.T("api.plugin.init.debug"))
+
+	BaseRoutes.Plugins.Handle("/upload", ApiAdminSystemRequired(uploadPlugin)).Methods("POST")
+	BaseRoutes.Plugins.Handle("/list", ApiAdminSystemRequired(getPlugins)).Methods("GET")
+
+	BaseRoutes.NeedPlugin.Handle("/remove", ApiAdminSystemRequired(removePlugin)).Methods("POST")
+	BaseRoutes.NeedPlugin.Handle("/enable", ApiAdminSystemRequired(enablePlugin)).Methods("POST")
+	BaseRoutes.NeedPlugin.Handle("/disable", ApiAdminSystemRequired(disablePlugin)).Methods("POST")
+
+	// Plugins handle their own requests, so these aren't wrapped in an api handler
+	BaseRoutes.Root.HandleFunc("/plugins/{plugin_id:[A-Za-z0-9_\\.-]+}", servePluginRequest)
+	BaseRoutes.Root.PathPrefix("/plugins/{plugin_id:[A-Za-z0-9_\\.-]+}/").HandlerFunc(servePluginRequest)
+}
+
+func uploadPlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !*utils.Cfg.PluginSettings.Enable || !*utils.Cfg.PluginSettings.EnableUploads {
+		c.Err = model.NewLocAppError("uploadPlugin", "api.plugin.upload.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return
+	}
+
+	if r.ContentLength > *utils.Cfg.FileSettings.MaxFileSize {
+		c.Err = model.NewLocAppError("uploadPlugin", "api.plugin.upload.too_large.app_error", nil, "")
+		c.Err.StatusCode = http.StatusRequestEntityTooLarge
+		return
+	}
+
+	if err := r.ParseMultipartForm(*utils.Cfg.FileSettings.MaxFileSize); err != nil {
+		c.Err = model.NewLocAppError("uploadPlugin", "api.plugin.upload.parse.app_error", nil, err.Error())
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	files, ok := r.MultipartForm.File["plugin"]
+	if !ok || len(files) != 1 {
+		c.Err = model.NewLocAppError("uploadPlugin", "api.plugin.upload.no_file.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	file, err := files[0].Open()
+	if err != nil {
+		c.Err = model.NewLocAppError("uploadPlugin", "api.plugin.upload.open.app_error", nil, err.Error())
+		return
+	}
+	defer file.Close()
+
+	manifest, appErr := app.InstallPlugin(file)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	c.LogAudit("plugin_id=" + manifest.Id)
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(manifest.ToJson()))
+}
+
+func getPlugins(c *Context, w http.ResponseWriter, r *http.Request) {
+	plugins, err := app.GetPlugins()
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PluginInfoListToJson(plugins)))
+}
+
+func removePlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["plugin_id"]
+
+	if err := app.RemovePlugin(id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("plugin_id=" + id)
+	ReturnStatusOK(w)
+}
+
+func enablePlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["plugin_id"]
+
+	if err := app.EnablePlugin(id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("plugin_id=" + id)
+	ReturnStatusOK(w)
+}
+
+func disablePlugin(c *Context, w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["plugin_id"]
+
+	if err := app.DisablePlugin(id); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("plugin_id=" + id)
+	ReturnStatusOK(w)
+}
+
+// servePluginRequest passes a request under /plugins/{plugin_id} to the plugin with the part of the path after the
+// plugin's id. The user's token is replaced by their id so that plugins never see it.
+func servePluginRequest(w http.ResponseWriter, r *http.Request) {
+	if !*utils.Cfg.PluginSettings.Enable {
+		http.NotFound(w, r)
+		return
+	}
+
+	id := mux.Vars(r)["plugin_id"]
+
+	token := ""
+	authHeader := r.Header.Get(model.HEADER_AUTH)
+	if len(authHeader) > 6 && strings.ToUpper(authHeader[0:6]) == model.HEADER_BEARER {
+		token = authHeader[7:]
+	} else if len(authHeader) > 5 && strings.ToLower(authHeader[0:5]) == model.HEADER_TOKEN {
+		token = authHeader[6:]
+	} else if cookie, err := r.Cookie(model.SESSION_COOKIE_TOKEN); err == nil {
+		// Only requests that can't be forged by another site are allowed to change anything using the cookie
+		if r.Method == "GET" || r.Method == "HEAD" || r.Header.Get(model.HEADER_REQUESTED_WITH) == model.HEADER_REQUESTED_WITH_XML {
+			token = cookie.Value
+		}
+	}
+
+	r.Header.Del(model.HEADER_AUTH)
+	r.Header.Del(plugin.HEADER_USER_ID)
+	removeCookie(r, model.SESSION_COOKIE_TOKEN)
+
+	if len(token) > 0 {
+		if session, err := app.GetSession(token); err == nil {
+			r.Header.Set(plugin.HEADER_USER_ID, session.UserId)
+		}
+	}
+
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, "/plugins/"+id)
+	if len(r.URL.Path) == 0 {
+		r.URL.Path = "/"
+	}
+	r.URL.RawPath = ""
+
+	app.ServePluginRequest(id, w, r)
+}
+
+func removeCookie(r *http.Request, name string) {
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		if cookie.Name != name {
+			r.AddCookie(cookie)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func makePluginTarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for name, contents := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tarWriter.Close()
+	gzipWriter.Close()
+
+	return buf
+}
+
+func TestPlugins(t *testing.T) {
+	th := Setup().InitBasic().InitSystemAdmin()
+	Client := th.SystemAdminClient
+
+	pluginDir, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(pluginDir)
+
+	enable := *utils.Cfg.PluginSettings.Enable
+	enableUploads := *utils.Cfg.PluginSettings.EnableUploads
+	directory := *utils.Cfg.PluginSettings.Directory
+	pluginStates := utils.Cfg.PluginSettings.PluginStates
+	defer func() {
+		*utils.Cfg.PluginSettings.Enable = enable
+		*utils.Cfg.PluginSettings.EnableUploads = enableUploads
+		*utils.Cfg.PluginSettings.Directory = directory
+		utils.Cfg.PluginSettings.PluginStates = pluginStates
+		utils.SaveConfig(utils.CfgFileName, utils.Cfg)
+	}()
+	*utils.Cfg.PluginSettings.Enable = true
+	*utils.Cfg.PluginSettings.EnableUploads = false
+	*utils.Cfg.PluginSettings.Directory = pluginDir
+
+	manifest := &model.Manifest{Id: "com.example.test", Name: "Test", Version: "0.1.0"}
+	tarball := makePluginTarball(t, map[string]string{"test/" + model.PLUGIN_MANIFEST_FILENAME: manifest.ToJson()}).Bytes()
+
+	if _, err := Client.UploadPlugin(bytes.NewReader(tarball)); err == nil {
+		t.Fatal("should've failed with uploads disabled")
+	}
+
+	*utils.Cfg.PluginSettings.EnableUploads = true
+
+	if _, err := th.BasicClient.UploadPlugin(bytes.NewReader(tarball)); err == nil {
+		t.Fatal("should've failed for a user who isn't a system admin")
+	}
+
+	if installed := Client.Must(Client.UploadPlugin(bytes.NewReader(tarball))).Data.(*model.Manifest); installed.Id != manifest.Id {
+		t.Fatal("should've installed the plugin")
+	}
+
+	if _, err := Client.UploadPlugin(bytes.NewReader(tarball)); err == nil {
+		t.Fatal("should've failed for a plugin that's already installed")
+	}
+
+	if _, err := Client.UploadPlugin(bytes.NewReader([]byte("junk"))); err == nil {
+		t.Fatal("should've failed for a file that isn't a tarball")
+	}
+
+	if _, err := Client.UploadPlugin(makePluginTarball(t, map[string]string{"README": "no manifest"})); err == nil {
+		t.Fatal("should've failed without a manifest")
+	}
+
+	evil := &model.Manifest{Id: "com.example.evil", Name: "Evil"}
+	if _, err := Client.UploadPlugin(makePluginTarball(t, map[string]string{
+		model.PLUGIN_MANIFEST_FILENAME: evil.ToJson(),
+		"../evil":                      "outside",
+	})); err == nil {
+		t.Fatal("should've failed for a file outside of the plugin's directory")
+	}
+
+	if _, err := th.BasicClient.GetPlugins(); err == nil {
+		t.Fatal("should've failed for a user who isn't a system admin")
+	}
+
+	plugins := Client.Must(Client.GetPlugins()).Data.([]*model.PluginInfo)
+	if len(plugins) != 1 || plugins[0].Id != manifest.Id || plugins[0].Enabled || plugins[0].Active {
+		t.Fatal("should've listed the disabled plugin", plugins)
+	}
+
+	if _, err := th.BasicClient.SetPluginEnabled(manifest.Id, true); err == nil {
+		t.Fatal("should've failed for a user who isn't a system admin")
+	}
+
+	Client.Must(Client.SetPluginEnabled(manifest.Id, true))
+
+	plugins = Client.Must(Client.GetPlugins()).Data.([]*model.PluginInfo)
+	if len(plugins) != 1 || !plugins[0].Enabled || !plugins[0].Active {
+		t.Fatal("should've started the plugin", plugins)
+	}
+
+	// The plugin doesn't have a backend to handle requests
+	if resp, err := http.Get(Client.Url + "/plugins/" + manifest.Id + "/path"); err != nil {
+		t.Fatal(err)
+	} else {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatal("should've been not found", resp.StatusCode)
+		}
+	}
+
+	Client.Must(Client.SetPluginEnabled(manifest.Id, false))
+
+	plugins = Client.Must(Client.GetPlugins()).Data.([]*model.PluginInfo)
+	if len(plugins) != 1 || plugins[0].Enabled || plugins[0].Active {
+		t.Fatal("should've stopped the plugin", plugins)
+	}
+
+	if _, err := Client.SetPluginEnabled("com.example.missing", true); err == nil {
+		t.Fatal("should've failed for a plugin that isn't installed")
+	}
+
+	if _, err := th.BasicClient.RemovePlugin(manifest.Id); err == nil {
+		t.Fatal("should've failed for a user who isn't a system admin")
+	}
+
+	Client.Must(Client.RemovePlugin(manifest.Id))
+
+	if plugins := Client.Must(Client.GetPlugins()).Data.([]*model.PluginInfo); len(plugins) != 0 {
+		t.Fatal("should've removed the plugin", plugins)
+	}
+
+	if _, err := Client.RemovePlugin(manifest.Id); err == nil {
+		t.Fatal("should've failed for a plugin that isn't installed")
+	}
+}
@@ -27,12 +27,16 @@ func InitPost() {
 	BaseRoutes.Posts.Handle("/update", ApiUserRequiredActivity(updatePost, true)).Methods("POST")
 	BaseRoutes.Posts.Handle("/page/{offset:[0-9]+}/{limit:[0-9]+}", ApiUserRequired(getPosts)).Methods("GET")
 	BaseRoutes.Posts.Handle("/since/{time:[0-9]+}", ApiUserRequired(getPostsSince)).Methods("GET")
+	BaseRoutes.Posts.Handle("/pinned", ApiUserRequired(getPinnedPosts)).Methods("GET")
 
 	BaseRoutes.NeedPost.Handle("/get", ApiUserRequired(getPost)).Methods("GET")
 	BaseRoutes.NeedPost.Handle("/delete", ApiUserRequiredActivity(deletePost, true)).Methods("POST")
 	BaseRoutes.NeedPost.Handle("/before/{offset:[0-9]+}/{num_posts:[0-9]+}", ApiUserRequired(getPostsBefore)).Methods("GET")
 	BaseRoutes.NeedPost.Handle("/after/{offset:[0-9]+}/{num_posts:[0-9]+}", ApiUserRequired(getPostsAfter)).Methods("GET")
 	BaseRoutes.NeedPost.Handle("/get_file_infos", ApiUserRequired(getFileInfosForPost)).Methods("GET")
+	BaseRoutes.NeedPost.Handle("/pin", ApiUserRequired(pinPost)).Methods("POST")
+	BaseRoutes.NeedPost.Handle("/unpin", ApiUserRequired(unpinPost)).Methods("POST")
+	BaseRoutes.NeedPost.Handle("/actions/{action_id:[A-Za-z0-9]+}", ApiUserRequired(doPostAction)).Methods("POST")
 }
 
 func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -68,6 +72,8 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		post.CreateAt = 0
 	}
 
+	app.StripUntrustedPostActions(post)
+
 	if rp, err := app.CreatePost(post, c.TeamId, true); err != nil {
 		c.Err = err
 
@@ -156,7 +162,121 @@ func updatePost(c *Context, w http.ResponseWriter, r *http.Request) {
 		go app.Publish(message)
 
 		app.InvalidateCacheForChannelPosts(rpost.ChannelId)
+		app.IndexPost(rpost)
+
+		w.Write([]byte(rpost.ToJson()))
+	}
+}
+
+func getPinnedPosts(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	channelId := params["channel_id"]
+	if len(channelId) != 26 {
+		c.SetInvalidParam("getPinnedPosts", "channelId")
+		return
+	}
+
+	if !HasPermissionToChannelContext(c, channelId, model.PERMISSION_READ_CHANNEL) {
+		return
+	}
+
+	if result := <-app.Srv.Store.Post().GetPinnedPosts(channelId); result.Err != nil {
+		c.Err = result.Err
+	} else {
+		w.Write([]byte(result.Data.(*model.PostList).ToJson()))
+	}
+}
+
+func doPostAction(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	channelId := params["channel_id"]
+	if len(channelId) != 26 {
+		c.SetInvalidParam("doPostAction", "channelId")
+		return
+	}
+
+	postId := params["post_id"]
+	if len(postId) != 26 {
+		c.SetInvalidParam("doPostAction", "postId")
+		return
+	}
+
+	if !HasPermissionToChannelContext(c, channelId, model.PERMISSION_READ_CHANNEL) {
+		return
+	}
+
+	var post *model.Post
+	if result := <-app.Srv.Store.Post().Get(postId); result.Err != nil {
+		c.Err = result.Err
+		return
+	} else if post = result.Data.(*model.PostList).Posts[postId]; post == nil || post.ChannelId != channelId {
+		c.SetInvalidParam("doPostAction", "postId")
+		return
+	}
+
+	props := model.MapFromJson(r.Body)
+
+	if err := app.DoPostAction(post, params["action_id"], c.TeamId, c.Session.UserId, props["selected_option"]); err != nil {
+		c.Err = err
+		return
+	}
 
+	ReturnStatusOK(w)
+}
+
+func pinPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	setPostPinned(c, w, r, true)
+}
+
+func unpinPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	setPostPinned(c, w, r, false)
+}
+
+func setPostPinned(c *Context, w http.ResponseWriter, r *http.Request, isPinned bool) {
+	params := mux.Vars(r)
+
+	channelId := params["channel_id"]
+	if len(channelId) != 26 {
+		c.SetInvalidParam("setPostPinned", "channelId")
+		return
+	}
+
+	postId := params["post_id"]
+	if len(postId) != 26 {
+		c.SetInvalidParam("setPostPinned", "postId")
+		return
+	}
+
+	if !HasPermissionToChannelContext(c, channelId, model.PERMISSION_PIN_POST) {
+		return
+	}
+
+	var post *model.Post
+	if result := <-app.Srv.Store.Post().Get(postId); result.Err != nil {
+		c.Err = result.Err
+		return
+	} else if post = result.Data.(*model.PostList).Posts[postId]; post == nil {
+		c.SetInvalidParam("setPostPinned", "postId")
+		return
+	}
+
+	if post.ChannelId != channelId {
+		c.Err = model.NewLocAppError("setPostPinned", "api.post.set_post_pinned.permissions.app_error", nil, "")
+		c.Err.StatusCode = http.StatusForbidden
+		return
+	}
+
+	if post.IsSystemMessage() {
+		c.Err = model.NewLocAppError("setPostPinned", "api.post.set_post_pinned.system_message.app_error", nil, "")
+		c.Err.StatusCode = http.StatusBadRequest
+		return
+	}
+
+	if rpost, err := app.SetPostPinned(post, isPinned, c.Session.UserId, c.TeamId); err != nil {
+		c.Err = err
+	} else {
 		w.Write([]byte(rpost.ToJson()))
 	}
 }
@@ -445,6 +565,8 @@ func deletePost(c *Context, w http.ResponseWriter, r *http.Request) {
 		go DeleteFlaggedPost(c.Session.UserId, post)
 
 		app.InvalidateCacheForChannelPosts(post.ChannelId)
+		app.DeletePostFromIndex(post.Id)
+		go app.UpdateThreadForDeletedPost(c.TeamId, post)
 
 		result := make(map[string]string)
 		result["id"] = postId
@@ -548,26 +670,10 @@ func searchPosts(c *Context, w http.ResponseWriter, r *http.Request) {
 		isOrSearch = val.(bool)
 	}
 
-	paramsList := model.ParseSearchParams(terms)
-	channels := []store.StoreChannel{}
-
-	for _, params := range paramsList {
-		params.OrTerms = isOrSearch
-		// don't allow users to search for everything
-		if params.Terms != "*" {
-			channels = append(channels, app.Srv.Store.Post().Search(c.TeamId, c.Session.UserId, params))
-		}
-	}
-
-	posts := &model.PostList{}
-	for _, channel := range channels {
-		if result := <-channel; result.Err != nil {
-			c.Err = result.Err
-			return
-		} else {
-			data := result.Data.(*model.PostList)
-			posts.Extend(data)
-		}
+	posts, err := app.SearchPostsInTeam(terms, c.Session.UserId, c.TeamId, isOrSearch)
+	if err != nil {
+		c.Err = err
+		return
 	}
 
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
@@ -4,7 +4,9 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -951,6 +953,65 @@ func TestGetFlaggedPosts(t *testing.T) {
 	}
 }
 
+func TestPinPost(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+	post := th.BasicPost
+
+	if rpost := Client.Must(Client.PinPost(channel.Id, post.Id)).Data.(*model.Post); !rpost.IsPinned {
+		t.Fatal("should've pinned the post")
+	} else if rpost.EditAt != 0 {
+		t.Fatal("pinning a post shouldn't mark it as edited")
+	}
+
+	if list := Client.Must(Client.GetPinnedPosts(channel.Id)).Data.(*model.PostList); len(list.Order) != 1 || list.Order[0] != post.Id {
+		t.Fatal("should've returned the pinned post")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if list := Client.Must(Client.GetPosts(channel.Id, 0, 10, "")).Data.(*model.PostList); list.Posts[list.Order[0]].Type != model.POST_PINNED {
+		t.Fatal("should've announced that the post was pinned")
+	} else if list.Posts[list.Order[0]].Props["pinned_post_id"] != post.Id {
+		t.Fatal("announcement should refer to the pinned post")
+	}
+
+	if rpost := Client.Must(Client.UnpinPost(channel.Id, post.Id)).Data.(*model.Post); rpost.IsPinned {
+		t.Fatal("should've unpinned the post")
+	}
+
+	if list := Client.Must(Client.GetPinnedPosts(channel.Id)).Data.(*model.PostList); len(list.Order) != 0 {
+		t.Fatal("shouldn't return unpinned posts")
+	}
+
+	pinned := &model.Post{ChannelId: channel.Id, Message: "a" + model.NewId() + "a", IsPinned: true}
+	if rpost := Client.Must(Client.CreatePost(pinned)).Data.(*model.Post); rpost.IsPinned {
+		t.Fatal("shouldn't be able to create a post that's already pinned")
+	} else if list := Client.Must(Client.GetPinnedPosts(channel.Id)).Data.(*model.PostList); len(list.Order) != 0 {
+		t.Fatal("shouldn't have stored the new post as pinned")
+	}
+
+	if _, err := Client.PinPost(channel.Id, "junk"); err == nil {
+		t.Fatal("should've failed with an invalid post id")
+	}
+
+	otherChannel := th.CreateChannel(Client, th.BasicTeam)
+	if _, err := Client.PinPost(otherChannel.Id, post.Id); err == nil {
+		t.Fatal("shouldn't pin a post through another channel")
+	}
+
+	th.LoginBasic2()
+
+	if _, err := Client.PinPost(channel.Id, post.Id); err == nil {
+		t.Fatal("shouldn't pin a post in a channel the user doesn't belong to")
+	}
+
+	if _, err := Client.GetPinnedPosts(channel.Id); err == nil {
+		t.Fatal("shouldn't get pinned posts in a channel the user doesn't belong to")
+	}
+}
+
 func TestGetMessageForNotification(t *testing.T) {
 	Setup().InitBasic()
 
@@ -1121,3 +1182,187 @@ func TestGetPermalinkTmp(t *testing.T) {
 		t.Fatal("should not be empty")
 	}
 }
+
+func TestDoPostAction(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	allowedInternalConnections := *utils.Cfg.ServiceSettings.AllowedUntrustedInternalConnections
+	defer func() {
+		*utils.Cfg.ServiceSettings.AllowedUntrustedInternalConnections = allowedInternalConnections
+	}()
+	*utils.Cfg.ServiceSettings.AllowedUntrustedInternalConnections = "127.0.0.1"
+
+	var requests = make(chan *model.PostActionIntegrationRequest, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(model.HEADER_WEBHOOK_SIGNATURE) != model.SignPostActionRequest(*utils.Cfg.ServiceSettings.PostActionSigningSecret, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		request := model.PostActionIntegrationRequestFromJson(bytes.NewReader(body))
+		requests <- request
+
+		if request.Type == model.POST_ACTION_TYPE_SELECT {
+			w.Write([]byte((&model.PostActionIntegrationResponse{EphemeralText: "selected"}).ToJson()))
+		} else {
+			w.Write([]byte((&model.PostActionIntegrationResponse{Update: &model.Post{Message: "clicked"}}).ToJson()))
+		}
+	}))
+	defer ts.Close()
+
+	newPost := func(message string) *model.Post {
+		return &model.Post{
+			ChannelId: channel.Id,
+			UserId:    th.BasicUser.Id,
+			Message:   message,
+			Props: model.StringInterface{
+				"from_webhook": "true",
+				"attachments": []interface{}{
+					map[string]interface{}{
+						"text": "attachment",
+						"actions": []interface{}{
+							map[string]interface{}{
+								"name":        "Button",
+								"integration": map[string]interface{}{"url": ts.URL, "context": map[string]interface{}{"a": "b"}},
+							},
+							map[string]interface{}{
+								"name":        "Menu",
+								"type":        model.POST_ACTION_TYPE_SELECT,
+								"options":     []interface{}{map[string]interface{}{"text": "One", "value": "1"}},
+								"integration": map[string]interface{}{"url": ts.URL},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// actions are only kept on posts made by integrations
+	post, err := app.CreatePost(newPost("test"), th.BasicTeam.Id, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actions := post.Props["attachments"].([]interface{})[0].(map[string]interface{})["actions"].([]interface{})
+	buttonId, _ := actions[0].(map[string]interface{})["id"].(string)
+	menuId, _ := actions[1].(map[string]interface{})["id"].(string)
+	if len(buttonId) != 26 || len(menuId) != 26 {
+		t.Fatal("should've given the actions ids")
+	}
+
+	Client.Must(Client.DoPostAction(channel.Id, post.Id, buttonId, ""))
+
+	if request := <-requests; request.UserId != th.BasicUser.Id || request.PostId != post.Id || request.Context["a"] != "b" {
+		t.Fatal("integration received the wrong request")
+	}
+
+	if rpost := Client.Must(Client.GetPost(channel.Id, post.Id, "")).Data.(*model.PostList).Posts[post.Id]; rpost.Message != "clicked" {
+		t.Fatal("should've updated the post")
+	} else if rpost.EditAt == 0 {
+		t.Fatal("should've marked the post as edited")
+	} else if _, ok := rpost.Props["attachments"]; ok {
+		t.Fatal("should've removed the attachments")
+	}
+
+	// the original post's actions are gone once it's been updated
+	if _, err := Client.DoPostAction(channel.Id, post.Id, menuId, "1"); err == nil {
+		t.Fatal("should've failed with an action that no longer exists")
+	}
+
+	if post, err = app.CreatePost(newPost("menu"), th.BasicTeam.Id, false); err != nil {
+		t.Fatal(err)
+	}
+	actions = post.Props["attachments"].([]interface{})[0].(map[string]interface{})["actions"].([]interface{})
+	menuId, _ = actions[1].(map[string]interface{})["id"].(string)
+
+	Client.Must(Client.DoPostAction(channel.Id, post.Id, menuId, "1"))
+
+	if request := <-requests; request.Type != model.POST_ACTION_TYPE_SELECT || request.Context[model.POST_ACTION_CONTEXT_SELECTED_OPTION] != "1" {
+		t.Fatal("integration should've received the selected option")
+	}
+
+	if _, err := Client.DoPostAction(channel.Id, post.Id, "junk", ""); err == nil {
+		t.Fatal("should've failed with an invalid action id")
+	}
+
+	otherChannel := th.CreateChannel(Client, th.BasicTeam)
+	if _, err := Client.DoPostAction(otherChannel.Id, post.Id, menuId, "1"); err == nil {
+		t.Fatal("shouldn't use an action through another channel")
+	}
+
+	th.LoginBasic2()
+
+	if _, err := Client.DoPostAction(channel.Id, post.Id, menuId, "1"); err == nil {
+		t.Fatal("shouldn't use an action in a channel the user doesn't belong to")
+	}
+}
+
+func TestDoPostActionInternalConnection(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	allowedInternalConnections := *utils.Cfg.ServiceSettings.AllowedUntrustedInternalConnections
+	defer func() {
+		*utils.Cfg.ServiceSettings.AllowedUntrustedInternalConnections = allowedInternalConnections
+	}()
+	*utils.Cfg.ServiceSettings.AllowedUntrustedInternalConnections = ""
+
+	var requests = make(chan bool, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- true
+	}))
+	defer ts.Close()
+
+	newPost := func() *model.Post {
+		return &model.Post{
+			ChannelId: channel.Id,
+			Message:   "test",
+			Props: model.StringInterface{
+				"from_webhook": "true",
+				"attachments": []interface{}{
+					map[string]interface{}{
+						"actions": []interface{}{
+							map[string]interface{}{
+								"id":          "internal",
+								"name":        "Button",
+								"integration": map[string]interface{}{"url": ts.URL},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// a user's post can't have actions even if it claims to be from a webhook
+	post := Client.Must(Client.CreatePost(newPost())).Data.(*model.Post)
+	if post.GetAction("internal") != nil {
+		t.Fatal("should've removed the actions from a user's post")
+	}
+
+	if _, err := Client.DoPostAction(channel.Id, post.Id, "internal", ""); err == nil {
+		t.Fatal("shouldn't have been able to use an action on a user's post")
+	}
+
+	// integrations can't make the server connect to internal addresses unless they're allowed
+	integrationPost := newPost()
+	integrationPost.UserId = th.BasicUser.Id
+	if post, err := app.CreatePost(integrationPost, th.BasicTeam.Id, false); err != nil {
+		t.Fatal(err)
+	} else if _, err := Client.DoPostAction(channel.Id, post.Id, "internal", ""); err == nil {
+		t.Fatal("shouldn't have been able to connect to an internal address")
+	} else if err.StatusCode != http.StatusForbidden {
+		t.Fatal("should've been forbidden")
+	}
+
+	select {
+	case <-requests:
+		t.Fatal("server shouldn't have sent a request")
+	default:
+	}
+}
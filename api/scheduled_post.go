@@ -0,0 +1,144 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitScheduledPost() {
+	l4g.Debug(utils.T("api.scheduled_post.init.debug"))
+
+	BaseRoutes.ScheduledPosts.Handle("/", ApiUserRequired(getScheduledPosts)).Methods("GET")
+	BaseRoutes.ScheduledPosts.Handle("/create", ApiUserRequired(createScheduledPost)).Methods("POST")
+
+	BaseRoutes.NeedScheduledPost.Handle("/update", ApiUserRequired(updateScheduledPost)).Methods("POST")
+	BaseRoutes.NeedScheduledPost.Handle("/delete", ApiUserRequired(deleteScheduledPost)).Methods("POST")
+}
+
+func getScheduledPosts(c *Context, w http.ResponseWriter, r *http.Request) {
+	if posts, err := app.GetScheduledPostsForUser(c.TeamId, c.Session.UserId); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(model.ScheduledPostsToJson(posts)))
+	}
+}
+
+func createScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := model.ScheduledPostFromJson(r.Body)
+	if scheduledPost == nil {
+		c.SetInvalidParam("createScheduledPost", "scheduled_post")
+		return
+	}
+
+	scheduledPost.UserId = c.Session.UserId
+	scheduledPost.TeamId = c.TeamId
+
+	if !canScheduleInChannel(c, scheduledPost.ChannelId, "createScheduledPost") {
+		return
+	}
+
+	if rscheduledPost, err := app.CreateScheduledPost(scheduledPost); err != nil {
+		c.Err = err
+	} else {
+		c.LogAudit("id=" + rscheduledPost.Id)
+		w.Write([]byte(rscheduledPost.ToJson()))
+	}
+}
+
+func updateScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := model.ScheduledPostFromJson(r.Body)
+	if scheduledPost == nil {
+		c.SetInvalidParam("updateScheduledPost", "scheduled_post")
+		return
+	}
+
+	oldScheduledPost := getScheduledPostForSession(c, r, "updateScheduledPost")
+	if oldScheduledPost == nil {
+		return
+	}
+
+	if !canScheduleInChannel(c, oldScheduledPost.ChannelId, "updateScheduledPost") {
+		return
+	}
+
+	if rscheduledPost, err := app.UpdateScheduledPost(oldScheduledPost, scheduledPost); err != nil {
+		c.Err = err
+	} else {
+		c.LogAudit("id=" + rscheduledPost.Id)
+		w.Write([]byte(rscheduledPost.ToJson()))
+	}
+}
+
+func deleteScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := getScheduledPostForSession(c, r, "deleteScheduledPost")
+	if scheduledPost == nil {
+		return
+	}
+
+	if err := app.DeleteScheduledPost(scheduledPost); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("id=" + scheduledPost.Id)
+	ReturnStatusOK(w)
+}
+
+// getScheduledPostForSession returns the scheduled post from the request's path if it belongs to the current user.
+func getScheduledPostForSession(c *Context, r *http.Request, where string) *model.ScheduledPost {
+	id := mux.Vars(r)["scheduled_post_id"]
+	if len(id) != 26 {
+		c.SetInvalidParam(where, "scheduled_post_id")
+		return nil
+	}
+
+	scheduledPost, err := app.GetScheduledPost(id)
+	if err != nil {
+		c.Err = err
+		return nil
+	}
+
+	if scheduledPost.UserId != c.Session.UserId || scheduledPost.TeamId != c.TeamId {
+		c.Err = model.NewLocAppError(where, "api.scheduled_post.permissions.app_error", nil, "id="+id)
+		c.Err.StatusCode = http.StatusForbidden
+		return nil
+	}
+
+	return scheduledPost
+}
+
+// canScheduleInChannel checks that the current user can post in a channel on the current team.
+func canScheduleInChannel(c *Context, channelId string, where string) bool {
+	if len(channelId) != 26 {
+		c.SetInvalidParam(where, "channel_id")
+		return false
+	}
+
+	cchan := app.Srv.Store.Channel().Get(channelId, true)
+
+	if !HasPermissionToChannelContext(c, channelId, model.PERMISSION_CREATE_POST) {
+		return false
+	}
+
+	if result := <-cchan; result.Err != nil {
+		c.SetInvalidParam(where, "channel_id")
+		return false
+	} else if channel := result.Data.(*model.Channel); channel.DeleteAt != 0 {
+		c.Err = model.NewLocAppError(where, "api.post.create_post.can_not_post_to_deleted.error", nil, "channel_id="+channelId)
+		c.Err.StatusCode = http.StatusBadRequest
+		return false
+	} else if channel.TeamId != "" && channel.TeamId != c.TeamId {
+		c.SetInvalidParam(where, "channel_id")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+)
+
+func TestScheduledPosts(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+
+	scheduledPost := &model.ScheduledPost{
+		ChannelId:   channel.Id,
+		Message:     "scheduled " + model.NewId(),
+		ScheduledAt: model.GetMillis() + 60*60*1000,
+	}
+
+	rscheduledPost, err := Client.CreateScheduledPost(scheduledPost)
+	if err != nil {
+		t.Fatal(err)
+	} else if rscheduledPost.UserId != th.BasicUser.Id || rscheduledPost.TeamId != th.BasicTeam.Id {
+		t.Fatal("should've set the user and team")
+	}
+
+	if _, err := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: channel.Id, Message: "past", ScheduledAt: model.GetMillis() - 1000}); err == nil {
+		t.Fatal("shouldn't schedule a post in the past")
+	}
+
+	if _, err := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: model.NewId(), Message: "junk", ScheduledAt: scheduledPost.ScheduledAt}); err == nil {
+		t.Fatal("shouldn't schedule a post in a channel the user can't access")
+	}
+
+	if posts, err := Client.GetScheduledPosts(); err != nil {
+		t.Fatal(err)
+	} else if len(posts) != 1 || posts[0].Id != rscheduledPost.Id {
+		t.Fatal("should've returned the scheduled post")
+	}
+
+	rscheduledPost.Message = "updated " + model.NewId()
+	if updated, err := Client.UpdateScheduledPost(rscheduledPost); err != nil {
+		t.Fatal(err)
+	} else if updated.Message != rscheduledPost.Message {
+		t.Fatal("should've updated the message")
+	}
+
+	th.LoginBasic2()
+
+	if _, err := Client.UpdateScheduledPost(rscheduledPost); err == nil {
+		t.Fatal("shouldn't be able to update another user's scheduled post")
+	}
+
+	if _, err := Client.DeleteScheduledPost(rscheduledPost.Id); err == nil {
+		t.Fatal("shouldn't be able to delete another user's scheduled post")
+	}
+
+	th.LoginBasic()
+
+	// move the post into the past to simulate it coming due
+	rscheduledPost.ScheduledAt = model.GetMillis() - 1000
+	store.Must(app.Srv.Store.ScheduledPost().Update(rscheduledPost, 0))
+
+	app.PublishDueScheduledPosts()
+
+	if posts, err := Client.GetScheduledPosts(); err != nil {
+		t.Fatal(err)
+	} else if len(posts) != 0 {
+		t.Fatal("should've removed the published post")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if r, err := Client.GetPosts(channel.Id, 0, 10, ""); err != nil {
+		t.Fatal(err)
+	} else if list := r.Data.(*model.PostList); list.Posts[list.Order[0]].Message != rscheduledPost.Message {
+		t.Fatal("should've published the scheduled post")
+	}
+
+	second, err := Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: channel.Id, Message: "second", ScheduledAt: model.GetMillis() + 60*60*1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := Client.DeleteScheduledPost(second.Id); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("should've deleted the scheduled post")
+	}
+
+	if posts, err := Client.GetScheduledPosts(); err != nil {
+		t.Fatal(err)
+	} else if len(posts) != 0 {
+		t.Fatal("should've deleted the scheduled post")
+	}
+}
+
+func TestScheduledPostFailure(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	channel := th.CreateChannel(Client, th.BasicTeam)
+
+	rscheduledPost, err := Client.CreateScheduledPost(&model.ScheduledPost{
+		ChannelId:   channel.Id,
+		Message:     "scheduled",
+		ScheduledAt: model.GetMillis() + 60*60*1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Client.Must(Client.LeaveChannel(channel.Id))
+
+	rscheduledPost.ScheduledAt = model.GetMillis() - 1000
+	store.Must(app.Srv.Store.ScheduledPost().Update(rscheduledPost, 0))
+
+	app.PublishDueScheduledPosts()
+
+	if posts, err := Client.GetScheduledPosts(); err != nil {
+		t.Fatal(err)
+	} else if len(posts) != 1 || !posts[0].HasFailed() {
+		t.Fatal("should've recorded that the post couldn't be published")
+	}
+}
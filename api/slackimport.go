@@ -199,25 +199,27 @@ func SlackAddBotUser(teamId string, log *bytes.Buffer) *model.User {
 		team = result.Data.(*model.Team)
 	}
 
-	password := model.NewId()
 	username := "slackimportuser_" + model.NewId()
-	email := username + "@localhost"
-
-	botUser := model.User{
-		Username:  username,
-		FirstName: "",
-		LastName:  "",
-		Email:     email,
-		Password:  password,
+
+	bot, err := app.CreateBot(username, "Slack Import", "Posts by Slack bots and integrations", "")
+	if err != nil {
+		l4g.Error(utils.T("api.import.import_user.saving.error"), err)
+		log.WriteString(utils.T("api.slackimport.slack_add_bot_user.unable_import", map[string]interface{}{"Username": username}))
+		return nil
 	}
 
-	if mUser := ImportUser(team, &botUser); mUser != nil {
-		log.WriteString(utils.T("api.slackimport.slack_add_bot_user.email_pwd", map[string]interface{}{"Email": botUser.Email, "Password": password}))
-		return mUser
-	} else {
+	botUser, err := app.GetUser(bot.UserId)
+	if err != nil {
 		log.WriteString(utils.T("api.slackimport.slack_add_bot_user.unable_import", map[string]interface{}{"Username": username}))
 		return nil
 	}
+
+	if err := app.JoinUserToTeam(team, botUser); err != nil {
+		l4g.Error(utils.T("api.import.import_user.join_team.error"), err)
+	}
+
+	log.WriteString(utils.T("api.slackimport.slack_add_bot_user.created", map[string]interface{}{"Username": botUser.Username}))
+	return botUser
 }
 
 func SlackAddPosts(teamId string, channel *model.Channel, posts []SlackPost, users map[string]*model.User, uploads map[string]*zip.File, botUser *model.User) {
@@ -150,6 +150,7 @@ func LeaveTeam(team *model.Team, user *model.User) *model.AppError {
 
 	app.RemoveAllSessionsForUserId(user.Id)
 	app.InvalidateCacheForUser(user.Id)
+	app.IndexUserById(user.Id)
 
 	return nil
 }
@@ -714,6 +715,10 @@ func updateMemberRoles(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func PermanentDeleteTeam(team *model.Team) *model.AppError {
+	if err := app.CheckLegalHoldsForTeamDeletion(team.Id); err != nil {
+		return err
+	}
+
 	team.DeleteAt = model.GetMillis()
 	if result := <-app.Srv.Store.Team().Update(team); result.Err != nil {
 		return result.Err
@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/utils"
+)
+
+func InitThread() {
+	l4g.Debug(utils.T("api.thread.init.debug"))
+
+	BaseRoutes.Threads.Handle("/{offset:[0-9]+}/{limit:[0-9]+}", ApiUserRequired(getThreads)).Methods("GET")
+	BaseRoutes.Threads.Handle("/read_all", ApiUserRequired(markAllThreadsAsRead)).Methods("POST")
+
+	BaseRoutes.NeedThread.Handle("/get", ApiUserRequired(getThread)).Methods("GET")
+	BaseRoutes.NeedThread.Handle("/follow", ApiUserRequired(followThread)).Methods("POST")
+	BaseRoutes.NeedThread.Handle("/unfollow", ApiUserRequired(unfollowThread)).Methods("POST")
+	BaseRoutes.NeedThread.Handle("/read", ApiUserRequired(markThreadAsRead)).Methods("POST")
+}
+
+func getThreads(c *Context, w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	offset, err := strconv.Atoi(params["offset"])
+	if err != nil {
+		c.SetInvalidParam("getThreads", "offset")
+		return
+	}
+
+	limit, err := strconv.Atoi(params["limit"])
+	if err != nil {
+		c.SetInvalidParam("getThreads", "limit")
+		return
+	}
+
+	if list, err := app.GetThreadsForUser(c.TeamId, c.Session.UserId, offset, limit); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(list.ToJson()))
+	}
+}
+
+func getThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	threadId := getThreadIdParam(c, r, "getThread")
+	if threadId == "" {
+		return
+	}
+
+	if thread, err := app.GetThreadForUser(c.TeamId, c.Session.UserId, threadId); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(thread.ToJson()))
+	}
+}
+
+func followThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	setThreadFollowing(c, w, r, "followThread", true)
+}
+
+func unfollowThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	setThreadFollowing(c, w, r, "unfollowThread", false)
+}
+
+func setThreadFollowing(c *Context, w http.ResponseWriter, r *http.Request, where string, following bool) {
+	threadId := getThreadIdParam(c, r, where)
+	if threadId == "" {
+		return
+	}
+
+	// make sure that the thread exists and that the user can see it
+	if _, err := app.GetThreadForUser(c.TeamId, c.Session.UserId, threadId); err != nil {
+		c.Err = err
+		return
+	}
+
+	if thread, err := app.SetThreadFollowing(c.TeamId, c.Session.UserId, threadId, following); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(thread.ToJson()))
+	}
+}
+
+func markThreadAsRead(c *Context, w http.ResponseWriter, r *http.Request) {
+	threadId := getThreadIdParam(c, r, "markThreadAsRead")
+	if threadId == "" {
+		return
+	}
+
+	if _, err := app.GetThreadForUser(c.TeamId, c.Session.UserId, threadId); err != nil {
+		c.Err = err
+		return
+	}
+
+	if thread, err := app.MarkThreadAsRead(c.TeamId, c.Session.UserId, threadId); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(thread.ToJson()))
+	}
+}
+
+func markAllThreadsAsRead(c *Context, w http.ResponseWriter, r *http.Request) {
+	if err := app.MarkAllThreadsAsRead(c.TeamId, c.Session.UserId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
+func getThreadIdParam(c *Context, r *http.Request, where string) string {
+	threadId := mux.Vars(r)["thread_id"]
+	if len(threadId) != 26 {
+		c.SetInvalidParam(where, "threadId")
+		return ""
+	}
+
+	return threadId
+}
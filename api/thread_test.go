@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestThreads(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+	channel := th.BasicChannel
+	root := th.BasicPost
+
+	th.LoginBasic2()
+	Client.Must(Client.JoinChannel(channel.Id))
+	Client.Must(Client.CreatePost(&model.Post{ChannelId: channel.Id, RootId: root.Id, Message: "reply"}))
+
+	if list, err := Client.GetThreads(0, 10); err != nil {
+		t.Fatal(err)
+	} else if len(list.Threads) != 1 || list.Threads[0].PostId != root.Id {
+		t.Fatal("replying should follow the thread")
+	} else if list.Threads[0].UnreadReplies != 0 {
+		t.Fatal("a user's own replies shouldn't be unread")
+	}
+
+	th.LoginBasic()
+
+	if list, err := Client.GetThreads(0, 10); err != nil {
+		t.Fatal(err)
+	} else if len(list.Threads) != 1 || list.Threads[0].PostId != root.Id {
+		t.Fatal("the author of the root post should follow the thread")
+	} else if list.Threads[0].ReplyCount != 1 || list.Threads[0].UnreadReplies != 1 || list.TotalUnreadThreads != 1 {
+		t.Fatal("returned the wrong counts")
+	} else if list.Threads[0].Post == nil || list.Threads[0].Post.Id != root.Id {
+		t.Fatal("should've included the root post")
+	}
+
+	if thread, err := Client.MarkThreadAsRead(root.Id); err != nil {
+		t.Fatal(err)
+	} else if thread.UnreadReplies != 0 {
+		t.Fatal("should've marked the thread as read")
+	}
+
+	if thread, err := Client.UnfollowThread(root.Id); err != nil {
+		t.Fatal(err)
+	} else if thread.Following {
+		t.Fatal("should've unfollowed the thread")
+	}
+
+	if list, err := Client.GetThreads(0, 10); err != nil {
+		t.Fatal(err)
+	} else if len(list.Threads) != 0 {
+		t.Fatal("shouldn't list threads that aren't followed")
+	}
+
+	if thread, err := Client.FollowThread(root.Id); err != nil {
+		t.Fatal(err)
+	} else if !thread.Following {
+		t.Fatal("should've followed the thread")
+	}
+
+	if thread, err := Client.GetThread(root.Id); err != nil {
+		t.Fatal(err)
+	} else if thread.PostId != root.Id || thread.ReplyCount != 1 {
+		t.Fatal("returned the wrong thread")
+	}
+
+	if _, err := Client.GetThread(model.NewId()); err == nil {
+		t.Fatal("shouldn't get a thread that doesn't exist")
+	}
+
+	if _, err := Client.FollowThread("junk"); err == nil {
+		t.Fatal("should've failed with an invalid thread id")
+	}
+
+	if ok, err := Client.MarkAllThreadsAsRead(); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("should've returned OK")
+	}
+
+	otherClient := th.CreateClient()
+	otherUser := th.CreateUser(otherClient)
+	LinkUserToTeam(otherUser, th.BasicTeam)
+	otherClient.Must(otherClient.Login(otherUser.Email, otherUser.Password))
+	otherClient.SetTeamId(th.BasicTeam.Id)
+
+	if _, err := otherClient.FollowThread(root.Id); err == nil {
+		t.Fatal("shouldn't be able to follow a thread in a channel that the user doesn't belong to")
+	}
+}
@@ -63,6 +63,7 @@ func InitUser() {
 	BaseRoutes.Users.Handle("/mfa", ApiAppHandler(checkMfa)).Methods("POST")
 	BaseRoutes.Users.Handle("/generate_mfa_secret", ApiUserRequiredMfa(generateMfaSecret)).Methods("GET")
 	BaseRoutes.Users.Handle("/update_mfa", ApiUserRequiredMfa(updateMfa)).Methods("POST")
+	BaseRoutes.Users.Handle("/generate_mfa_recovery_codes", ApiUserRequiredMfa(generateMfaRecoveryCodes)).Methods("POST")
 
 	BaseRoutes.Users.Handle("/claim/email_to_oauth", ApiAppHandler(emailToOAuth)).Methods("POST")
 	BaseRoutes.Users.Handle("/claim/oauth_to_email", ApiUserRequired(oauthToEmail)).Methods("POST")
@@ -998,7 +999,7 @@ func uploadProfileImage(c *Context, w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		c.Err = model.NewLocAppError("uploadProfileFile", "api.user.upload_profile_user.decode_config.app_error", nil, err.Error())
 		return
-	} else if config.Width*config.Height > MaxImageSize {
+	} else if config.Width*config.Height > app.MaxImageSize {
 		c.Err = model.NewLocAppError("uploadProfileFile", "api.user.upload_profile_user.too_large.app_error", nil, err.Error())
 		return
 	}
@@ -1074,6 +1075,7 @@ func updateUser(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.LogAudit("")
 
 		rusers := result.Data.([2]*model.User)
+		app.IndexUserById(rusers[0].Id)
 
 		if rusers[0].Email != rusers[1].Email {
 			go sendEmailChangeEmail(c, rusers[1].Email, rusers[0].Email, c.GetSiteURL())
@@ -1320,6 +1322,10 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		l4g.Warn(utils.T("api.user.permanent_delete_user.system_admin.warn"), user.Email)
 	}
 
+	if err := app.CheckLegalHoldsForUserDeletion(user.Id); err != nil {
+		return err
+	}
+
 	if _, err := UpdateActive(user, false); err != nil {
 		return err
 	}
@@ -1356,6 +1362,14 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-app.Srv.Store.ScheduledPost().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-app.Srv.Store.Draft().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
 	if result := <-app.Srv.Store.User().PermanentDelete(user.Id); result.Err != nil {
 		return result.Err
 	}
@@ -1372,6 +1386,24 @@ func PermanentDeleteUser(user *model.User) *model.AppError {
 		return result.Err
 	}
 
+	if result := <-app.Srv.Store.MfaRecoveryCode().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-app.Srv.Store.PersonalAccessToken().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-app.Srv.Store.Bot().PermanentDelete(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-app.Srv.Store.EventSubscription().PermanentDeleteByUser(user.Id); result.Err != nil {
+		return result.Err
+	}
+
+	app.DeleteUserFromIndex(user.Id)
+
 	l4g.Warn(utils.T("api.user.permanent_delete_user.deleted.warn"), user.Email, user.Id)
 
 	return nil
@@ -1412,6 +1444,12 @@ func sendPasswordReset(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Bots can't sign in so they don't have a password to reset
+	if user.IsBot {
+		w.Write([]byte(model.MapToJson(props)))
+		return
+	}
+
 	recovery := &model.PasswordRecovery{}
 	recovery.UserId = user.Id
 
@@ -2087,8 +2125,25 @@ func updateMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(model.MapToJson(rdata)))
 }
 
+func generateMfaRecoveryCodes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.LogAudit("attempt")
+
+	codes, err := app.GenerateMfaRecoveryCodes(c.Session.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success")
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	w.Write([]byte(model.ArrayToJson(codes)))
+}
+
 func checkMfa(c *Context, w http.ResponseWriter, r *http.Request) {
-	if !utils.IsLicensed || !*utils.License.Features.MFA || !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
+	if !*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication {
 		rdata := map[string]string{}
 		rdata["mfa_required"] = "false"
 		w.Write([]byte(model.MapToJson(rdata)))
@@ -2303,6 +2358,23 @@ func searchUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Err = nil
 	}
 
+	if props.InChannelId == "" && props.NotInChannelId == "" && len(searchOptions) == 1 {
+		// the search engine only supports searching by every field, so it can't be used when some are hidden
+		if profiles, ok, err := app.SearchUsersInTeamWithEngine(props.TeamId, props.Term, props.AllowInactive); ok {
+			if err != nil {
+				c.Err = err
+				return
+			}
+
+			for _, p := range profiles {
+				sanitizeProfile(c, p)
+			}
+
+			w.Write([]byte(model.UserListToJson(profiles)))
+			return
+		}
+	}
+
 	var uchan store.StoreChannel
 	if props.InChannelId != "" {
 		uchan = app.Srv.Store.User().SearchInChannel(props.InChannelId, props.Term, searchOptions)
@@ -2366,6 +2438,7 @@ func autocompleteUsersInChannel(c *Context, w http.ResponseWriter, r *http.Reque
 	}
 
 	searchOptions := map[string]bool{}
+	searchOptions[store.USER_SEARCH_OPTION_EXCLUDE_BOTS] = true
 
 	hideFullName := !utils.Cfg.PrivacySettings.ShowFullName
 	if hideFullName && !HasPermissionToContext(c, model.PERMISSION_MANAGE_SYSTEM) {
@@ -2422,6 +2495,7 @@ func autocompleteUsersInTeam(c *Context, w http.ResponseWriter, r *http.Request)
 	}
 
 	searchOptions := map[string]bool{}
+	searchOptions[store.USER_SEARCH_OPTION_EXCLUDE_BOTS] = true
 
 	hideFullName := !utils.Cfg.PrivacySettings.ShowFullName
 	if hideFullName && !HasPermissionToContext(c, model.PERMISSION_MANAGE_SYSTEM) {
@@ -2455,6 +2529,7 @@ func autocompleteUsers(c *Context, w http.ResponseWriter, r *http.Request) {
 	term := r.URL.Query().Get("term")
 
 	searchOptions := map[string]bool{}
+	searchOptions[store.USER_SEARCH_OPTION_EXCLUDE_BOTS] = true
 
 	hideFullName := !utils.Cfg.PrivacySettings.ShowFullName
 	if hideFullName && !HasPermissionToContext(c, model.PERMISSION_MANAGE_SYSTEM) {
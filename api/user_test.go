@@ -1817,6 +1817,29 @@ func TestUpdateMfa(t *testing.T) {
 	// need to add more test cases when enterprise bits can be loaded into tests
 }
 
+func TestGenerateMfaRecoveryCodes(t *testing.T) {
+	th := Setup().InitBasic()
+	Client := th.BasicClient
+
+	enableMfa := *utils.Cfg.ServiceSettings.EnableMultifactorAuthentication
+	defer func() {
+		*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = enableMfa
+	}()
+	*utils.Cfg.ServiceSettings.EnableMultifactorAuthentication = true
+
+	Client.Logout()
+
+	if _, err := Client.GenerateMfaRecoveryCodes(); err == nil {
+		t.Fatal("should have failed - not logged in")
+	}
+
+	th.LoginBasic()
+
+	if _, err := Client.GenerateMfaRecoveryCodes(); err == nil {
+		t.Fatal("should have failed - mfa not active")
+	}
+}
+
 func TestCheckMfa(t *testing.T) {
 	th := Setup()
 	Client := th.CreateClient()
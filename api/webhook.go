@@ -6,6 +6,7 @@ package api
 import (
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -28,6 +29,8 @@ func InitWebhook() {
 	BaseRoutes.Hooks.Handle("/outgoing/regen_token", ApiUserRequired(regenOutgoingHookToken)).Methods("POST")
 	BaseRoutes.Hooks.Handle("/outgoing/delete", ApiUserRequired(deleteOutgoingHook)).Methods("POST")
 	BaseRoutes.Hooks.Handle("/outgoing/list", ApiUserRequired(getOutgoingHooks)).Methods("GET")
+	BaseRoutes.Hooks.Handle("/outgoing/{hook_id:[A-Za-z0-9]+}/deliveries/{offset:[0-9]+}/{limit:[0-9]+}", ApiUserRequired(getOutgoingHookDeliveries)).Methods("GET")
+	BaseRoutes.Hooks.Handle("/outgoing/{hook_id:[A-Za-z0-9]+}/deliveries/{delivery_id:[A-Za-z0-9]+}/redeliver", ApiUserRequired(redeliverOutgoingHook)).Methods("POST")
 
 	BaseRoutes.Hooks.Handle("/{id:[A-Za-z0-9]+}", ApiAppHandler(incomingWebhook)).Methods("POST")
 
@@ -340,6 +343,99 @@ func regenOutgoingHookToken(c *Context, w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// getOutgoingHookForContext returns the outgoing webhook that the request is for if the current user is allowed to
+// manage it.
+func getOutgoingHookForContext(c *Context, r *http.Request, where string) *model.OutgoingWebhook {
+	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+		c.Err = model.NewLocAppError(where, "api.webhook.get_outgoing.disabled.app_error", nil, "")
+		c.Err.StatusCode = http.StatusNotImplemented
+		return nil
+	}
+
+	if !HasPermissionToCurrentTeamContext(c, model.PERMISSION_MANAGE_WEBHOOKS) {
+		c.Err = model.NewLocAppError(where, "api.command.admin_only.app_error", nil, "")
+		c.Err.StatusCode = http.StatusForbidden
+		return nil
+	}
+
+	params := mux.Vars(r)
+
+	var hook *model.OutgoingWebhook
+	if result := <-app.Srv.Store.Webhook().GetOutgoing(params["hook_id"]); result.Err != nil {
+		c.Err = result.Err
+		return nil
+	} else {
+		hook = result.Data.(*model.OutgoingWebhook)
+	}
+
+	if hook.TeamId != c.TeamId || (c.Session.UserId != hook.CreatorId && !HasPermissionToCurrentTeamContext(c, model.PERMISSION_MANAGE_OTHERS_WEBHOOKS)) {
+		c.LogAudit("fail - inappropriate permissions")
+		c.Err = model.NewLocAppError(where, "api.webhook.outgoing_deliveries.permissions.app_error", nil, "user_id="+c.Session.UserId)
+		c.Err.StatusCode = http.StatusForbidden
+		return nil
+	}
+
+	return hook
+}
+
+func getOutgoingHookDeliveries(c *Context, w http.ResponseWriter, r *http.Request) {
+	hook := getOutgoingHookForContext(c, r, "getOutgoingHookDeliveries")
+	if hook == nil {
+		return
+	}
+
+	params := mux.Vars(r)
+
+	offset, err := strconv.Atoi(params["offset"])
+	if err != nil {
+		c.SetInvalidParam("getOutgoingHookDeliveries", "offset")
+		return
+	}
+
+	limit, err := strconv.Atoi(params["limit"])
+	if err != nil {
+		c.SetInvalidParam("getOutgoingHookDeliveries", "limit")
+		return
+	}
+
+	if deliveries, err := app.GetOutgoingWebhookDeliveries(hook.Id, offset, limit); err != nil {
+		c.Err = err
+	} else {
+		w.Write([]byte(model.OutgoingWebhookDeliveriesToJson(deliveries)))
+	}
+}
+
+func redeliverOutgoingHook(c *Context, w http.ResponseWriter, r *http.Request) {
+	hook := getOutgoingHookForContext(c, r, "redeliverOutgoingHook")
+	if hook == nil {
+		return
+	}
+
+	params := mux.Vars(r)
+
+	delivery, err := app.GetOutgoingWebhookDelivery(params["delivery_id"])
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if delivery.HookId != hook.Id {
+		c.SetInvalidParam("redeliverOutgoingHook", "delivery_id")
+		return
+	}
+
+	c.LogAudit("attempt hook_id=" + hook.Id + " delivery_id=" + delivery.Id)
+
+	redelivery, err := app.RedeliverOutgoingWebhook(delivery)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("success hook_id=" + hook.Id + " delivery_id=" + redelivery.Id)
+	w.Write([]byte(redelivery.ToJson()))
+}
+
 func incomingWebhook(c *Context, w http.ResponseWriter, r *http.Request) {
 	if !utils.Cfg.ServiceSettings.EnableIncomingWebhooks {
 		c.Err = model.NewLocAppError("incomingWebhook", "web.incoming_webhook.disabled.app_error", nil, "")
@@ -7,8 +7,12 @@ import (
 	"fmt"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/utils"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestCreateIncomingHook(t *testing.T) {
@@ -487,6 +491,116 @@ func TestDeleteOutgoingHook(t *testing.T) {
 	}
 }
 
+func TestOutgoingHookDeliveries(t *testing.T) {
+	th := Setup().InitSystemAdmin()
+	Client := th.SystemAdminClient
+	team := th.SystemAdminTeam
+	channel1 := th.CreateChannel(Client, team)
+	user2 := th.CreateUser(Client)
+	LinkUserToTeam(user2, team)
+
+	enableOutgoingHooks := utils.Cfg.ServiceSettings.EnableOutgoingWebhooks
+	enableAdminOnlyHooks := utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations
+	defer func() {
+		utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = enableOutgoingHooks
+		utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = enableAdminOnlyHooks
+		utils.SetDefaultRolesBasedOnConfig()
+	}()
+	utils.Cfg.ServiceSettings.EnableOutgoingWebhooks = true
+	*utils.Cfg.ServiceSettings.EnableOnlyAdminIntegrations = false
+	utils.SetDefaultRolesBasedOnConfig()
+
+	var hook *model.OutgoingWebhook
+
+	var mutex sync.Mutex
+	requests := 0
+	failures := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		requests += 1
+
+		if len(body) == 0 || r.Header.Get(model.HEADER_WEBHOOK_SIGNATURE) != model.SignOutgoingWebhookPayload(hook.Token, body) {
+			failures += 1
+		}
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	hook = &model.OutgoingWebhook{ChannelId: channel1.Id, CallbackURLs: []string{ts.URL + "/fail", ts.URL + "/succeed"}}
+	hook = Client.Must(Client.CreateOutgoingWebhook(hook)).Data.(*model.OutgoingWebhook)
+
+	Client.Must(Client.CreatePost(&model.Post{ChannelId: channel1.Id, Message: "hello"}))
+
+	var deliveries []*model.OutgoingWebhookDelivery
+	for i := 0; i < 20; i++ {
+		deliveries = Client.Must(Client.GetOutgoingWebhookDeliveries(hook.Id, 0, 10)).Data.([]*model.OutgoingWebhookDelivery)
+
+		attempted := 0
+		for _, delivery := range deliveries {
+			if delivery.Attempts > 0 {
+				attempted += 1
+			}
+		}
+
+		if attempted == 2 {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var failed *model.OutgoingWebhookDelivery
+	for _, delivery := range deliveries {
+		if delivery.URL == ts.URL+"/fail" {
+			if delivery.StatusCode != http.StatusInternalServerError || delivery.Status != model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING || delivery.NextAttemptAt <= delivery.LastAttemptAt {
+				t.Fatal("should've scheduled the failed delivery to be retried")
+			}
+			failed = delivery
+		} else if delivery.StatusCode != http.StatusOK || delivery.Status != model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS {
+			t.Fatal("should've recorded the successful delivery")
+		}
+	}
+
+	if len(deliveries) != 2 || failed == nil {
+		t.Fatal("should've created a delivery for each callback URL")
+	}
+
+	mutex.Lock()
+	if requests != 2 || failures != 0 {
+		t.Fatal("every callback URL should've been sent a signed request")
+	}
+	mutex.Unlock()
+
+	if _, err := Client.RedeliverOutgoingWebhook(hook.Id, model.NewId()); err == nil {
+		t.Fatal("should have failed - bad delivery id")
+	}
+
+	if result, err := Client.RedeliverOutgoingWebhook(hook.Id, failed.Id); err != nil {
+		t.Fatal(err)
+	} else if redelivery := result.Data.(*model.OutgoingWebhookDelivery); redelivery.Id == failed.Id || redelivery.Payload != failed.Payload {
+		t.Fatal("should've created a new delivery with the same request")
+	}
+
+	Client.Logout()
+	Client.Must(Client.LoginById(user2.Id, user2.Password))
+	Client.SetTeamId(team.Id)
+
+	if _, err := Client.GetOutgoingWebhookDeliveries(hook.Id, 0, 10); err == nil {
+		t.Fatal("should have failed - not creator or team admin")
+	}
+
+	if _, err := Client.RedeliverOutgoingWebhook(hook.Id, failed.Id); err == nil {
+		t.Fatal("should have failed - not creator or team admin")
+	}
+}
+
 func TestRegenOutgoingHookToken(t *testing.T) {
 	th := Setup().InitSystemAdmin()
 	Client := th.SystemAdminClient
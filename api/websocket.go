@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 
 	l4g "github.com/alecthomas/log4go"
 	"github.com/gorilla/websocket"
@@ -35,8 +36,18 @@ func connect(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wc := app.NewWebConn(ws, c.Session, c.T, c.Locale)
-	app.HubRegister(wc)
+	// A client that's reconnecting can resume its previous connection to receive any events that it missed
+	connectionId := r.URL.Query().Get("connection_id")
+	sequence, parseErr := strconv.ParseInt(r.URL.Query().Get("sequence_number"), 10, 64)
+	if len(connectionId) != 26 || parseErr != nil {
+		connectionId = ""
+		sequence = 0
+	}
+
+	wc := app.NewWebConn(ws, c.Session, c.T, c.Locale, connectionId, sequence)
+	if len(c.Session.UserId) > 0 {
+		app.HubRegister(wc)
+	}
 	go wc.WritePump()
 	wc.ReadPump()
 }
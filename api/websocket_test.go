@@ -249,6 +249,92 @@ func TestWebSocketEvent(t *testing.T) {
 	}
 }
 
+func TestWebSocketResume(t *testing.T) {
+	th := Setup().InitBasic()
+	WebSocketClient, err := th.CreateWebSocketClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer WebSocketClient.Close()
+
+	WebSocketClient.Listen()
+
+	time.Sleep(300 * time.Millisecond)
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have responded OK to authentication challenge")
+	}
+
+	if hello := <-WebSocketClient.EventChannel; hello.Event != model.WEBSOCKET_EVENT_HELLO {
+		t.Fatal("should have received hello")
+	} else if WebSocketClient.ConnectionId == "" {
+		t.Fatal("should have been given a connection id")
+	}
+
+	// Skip over any other events, like status changes, sent after connecting
+	time.Sleep(300 * time.Millisecond)
+	for len(WebSocketClient.EventChannel) > 0 {
+		<-WebSocketClient.EventChannel
+	}
+
+	connectionId := WebSocketClient.ConnectionId
+	lastSequence := WebSocketClient.EventSequence
+
+	WebSocketClient.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	missed := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_TYPING, "", th.BasicChannel.Id, "", nil)
+	missed.Add("user_id", "missed")
+	app.Publish(missed)
+	time.Sleep(300 * time.Millisecond)
+
+	if err := WebSocketClient.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	WebSocketClient.Listen()
+
+	time.Sleep(300 * time.Millisecond)
+	if resp := <-WebSocketClient.ResponseChannel; resp.Status != model.STATUS_OK {
+		t.Fatal("should have responded OK to authentication challenge")
+	}
+
+	replayed := false
+	for event := range WebSocketClient.EventChannel {
+		if event.Sequence != lastSequence+1 {
+			t.Fatal("should have continued the sequence from the previous connection")
+		}
+		lastSequence = event.Sequence
+
+		if event.Event == model.WEBSOCKET_EVENT_HELLO {
+			break
+		} else if event.Event == model.WEBSOCKET_EVENT_TYPING && event.Data["user_id"] == "missed" {
+			replayed = true
+		}
+	}
+
+	if !replayed {
+		t.Fatal("should have replayed the missed event before hello")
+	} else if WebSocketClient.ConnectionId != connectionId {
+		t.Fatal("should have resumed the previous connection")
+	}
+
+	WebSocketClient.Close()
+	time.Sleep(300 * time.Millisecond)
+
+	// An unknown connection can't be resumed, so the client has to reload everything
+	WebSocketClient.ConnectionId = model.NewId()
+	if err := WebSocketClient.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	WebSocketClient.Listen()
+
+	time.Sleep(300 * time.Millisecond)
+	<-WebSocketClient.ResponseChannel
+
+	if event := <-WebSocketClient.EventChannel; event.Event != model.WEBSOCKET_EVENT_RESYNC_REQUIRED {
+		t.Fatal("should have required a resync", event.Event)
+	}
+}
+
 func TestZZWebSocketTearDown(t *testing.T) {
 	// *IMPORTANT* - Kind of hacky
 	// This should be the last function in any test file
@@ -0,0 +1,34 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bufio"
+	"os"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func GetLogs() ([]string, *model.AppError) {
+	var lines []string
+
+	if utils.Cfg.LogSettings.EnableFile {
+		file, err := os.Open(utils.GetLogFileLocation(utils.Cfg.LogSettings.FileLocation))
+		if err != nil {
+			return nil, model.NewLocAppError("getLogs", "api.admin.file_read_error", nil, err.Error())
+		}
+
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	} else {
+		lines = append(lines, "")
+	}
+
+	return lines, nil
+}
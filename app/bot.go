@@ -0,0 +1,139 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// CreateBot creates a bot along with the user account that it posts as. An empty ownerId means that the bot can only
+// be managed by system admins.
+func CreateBot(username string, displayName string, description string, ownerId string) (*model.Bot, *model.AppError) {
+	user := model.NewBotUser(username, displayName)
+	user.Roles = model.ROLE_SYSTEM_USER.Id
+	user.MakeNonNil()
+	user.Locale = *utils.Cfg.LocalizationSettings.DefaultClientLocale
+
+	ruser, err := createUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	bot := &model.Bot{
+		UserId:      ruser.Id,
+		OwnerId:     ownerId,
+		Description: description,
+	}
+
+	if result := <-Srv.Store.Bot().Save(bot); result.Err != nil {
+		// Don't leave behind a user that can't be managed as a bot
+		if dresult := <-Srv.Store.User().PermanentDelete(ruser.Id); dresult.Err != nil {
+			l4g.Error(utils.T("app.bot.create_bot.cleanup.error"), ruser.Id, dresult.Err.Error())
+		}
+
+		return nil, result.Err
+	}
+
+	bot.SetUser(ruser)
+
+	return bot, nil
+}
+
+func GetBot(userId string) (*model.Bot, *model.AppError) {
+	bchan := Srv.Store.Bot().Get(userId)
+	uchan := Srv.Store.User().Get(userId)
+
+	var bot *model.Bot
+	if result := <-bchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		bot = result.Data.(*model.Bot)
+	}
+
+	if result := <-uchan; result.Err != nil {
+		return nil, result.Err
+	} else {
+		bot.SetUser(result.Data.(*model.User))
+	}
+
+	return bot, nil
+}
+
+func GetBotsForOwner(ownerId string) ([]*model.Bot, *model.AppError) {
+	if result := <-Srv.Store.Bot().GetByOwner(ownerId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return setBotUsers(result.Data.([]*model.Bot))
+	}
+}
+
+func GetAllBots() ([]*model.Bot, *model.AppError) {
+	if result := <-Srv.Store.Bot().GetAll(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return setBotUsers(result.Data.([]*model.Bot))
+	}
+}
+
+func setBotUsers(bots []*model.Bot) ([]*model.Bot, *model.AppError) {
+	if len(bots) == 0 {
+		return bots, nil
+	}
+
+	userIds := make([]string, len(bots))
+	for i, bot := range bots {
+		userIds[i] = bot.UserId
+	}
+
+	if result := <-Srv.Store.User().GetProfileByIds(userIds, true); result.Err != nil {
+		return nil, result.Err
+	} else {
+		users := result.Data.(map[string]*model.User)
+		for _, bot := range bots {
+			if user, ok := users[bot.UserId]; ok {
+				bot.SetUser(user)
+			}
+		}
+	}
+
+	return bots, nil
+}
+
+// UpdateBot changes the description of a bot and the display name of its user account.
+func UpdateBot(userId string, displayName string, description string) (*model.Bot, *model.AppError) {
+	bot, err := GetBot(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.FirstName != displayName {
+		user.FirstName = displayName
+
+		if result := <-Srv.Store.User().Update(user, false); result.Err != nil {
+			return nil, result.Err
+		} else {
+			user = result.Data.([2]*model.User)[0]
+		}
+
+		InvalidateCacheForUser(user.Id)
+		IndexUserById(user.Id)
+	}
+
+	bot.Description = description
+
+	if result := <-Srv.Store.Bot().Update(bot); result.Err != nil {
+		return nil, result.Err
+	}
+
+	bot.SetUser(user)
+
+	return bot, nil
+}
@@ -102,6 +102,10 @@ func JoinDefaultChannels(teamId string, user *model.User, channelRole string) *m
 			Message:   fmt.Sprintf(utils.T("api.channel.join_channel.post_and_forget"), user.Username),
 			Type:      model.POST_JOIN_LEAVE,
 			UserId:    user.Id,
+			Props: model.StringInterface{
+				model.POST_PROPS_MEMBER_EVENT:   model.POST_MEMBER_EVENT_JOIN,
+				model.POST_PROPS_MEMBER_USER_ID: user.Id,
+			},
 		}
 
 		InvalidateCacheForChannel(result.Data.(*model.Channel).Id)
@@ -126,6 +130,10 @@ func JoinDefaultChannels(teamId string, user *model.User, channelRole string) *m
 			Message:   fmt.Sprintf(utils.T("api.channel.join_channel.post_and_forget"), user.Username),
 			Type:      model.POST_JOIN_LEAVE,
 			UserId:    user.Id,
+			Props: model.StringInterface{
+				model.POST_PROPS_MEMBER_EVENT:   model.POST_MEMBER_EVENT_JOIN,
+				model.POST_PROPS_MEMBER_USER_ID: user.Id,
+			},
 		}
 
 		InvalidateCacheForChannel(result.Data.(*model.Channel).Id)
@@ -159,6 +167,16 @@ func CreateChannel(channel *model.Channel, addMember bool) (*model.Channel, *mod
 			InvalidateCacheForUser(channel.CreatorId)
 		}
 
+		IndexChannel(sc)
+
+		// Only public channels are announced since anyone on the team could see them
+		if sc.Type == model.CHANNEL_OPEN {
+			message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_CREATED, sc.TeamId, "", "", nil)
+			message.Add("channel_id", sc.Id)
+			message.Add("team_id", sc.TeamId)
+			Publish(message)
+		}
+
 		return sc, nil
 	}
 }
@@ -377,7 +395,7 @@ func JoinChannel(channel *model.Channel, userId string) *model.AppError {
 			if _, err := AddUserToChannel(user, channel); err != nil {
 				return err
 			}
-			PostUserAddRemoveMessage(userId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.join_channel.post_and_forget"), user.Username), model.POST_JOIN_LEAVE)
+			PostUserAddRemoveMessage(userId, channel.Id, channel.TeamId, fmt.Sprintf(utils.T("api.channel.join_channel.post_and_forget"), user.Username), model.POST_JOIN_LEAVE, model.POST_MEMBER_EVENT_JOIN, userId)
 		} else {
 			return model.NewLocAppError("JoinChannel", "api.channel.join_channel.permissions.app_error", nil, "")
 		}
@@ -386,12 +404,18 @@ func JoinChannel(channel *model.Channel, userId string) *model.AppError {
 	return nil
 }
 
-func PostUserAddRemoveMessage(userId, channelId, teamId, message, postType string) *model.AppError {
+// PostUserAddRemoveMessage posts a system message about a change in a channel's membership. The member event and user
+// id describe whose membership changed and how so that it can be tracked without parsing the message.
+func PostUserAddRemoveMessage(userId, channelId, teamId, message, postType, memberEvent, memberUserId string) *model.AppError {
 	post := &model.Post{
 		ChannelId: channelId,
 		Message:   message,
 		Type:      postType,
 		UserId:    userId,
+		Props: model.StringInterface{
+			model.POST_PROPS_MEMBER_EVENT:   memberEvent,
+			model.POST_PROPS_MEMBER_USER_ID: memberUserId,
+		},
 	}
 	if _, err := CreatePost(post, teamId, false); err != nil {
 		return model.NewLocAppError("PostUserAddRemoveMessage", "api.channel.post_user_add_remove_message_and_forget.error", nil, err.Error())
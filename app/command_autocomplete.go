@@ -0,0 +1,199 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	COMMAND_AUTOCOMPLETE_TIMEOUT         = 5 * time.Second
+	COMMAND_AUTOCOMPLETE_MAX_SUGGESTIONS = 25
+)
+
+// GetCommandSuggestions returns suggestions for the subcommand or argument that's being typed at the end of the user's
+// input for a command. Each suggestion is the whole input with the last word completed.
+func GetCommandSuggestions(command *model.Command, userInput string, teamId string, channelId string, userId string) []*model.SuggestCommand {
+	suggestions := []*model.SuggestCommand{}
+
+	data := command.AutocompleteData
+	if data == nil {
+		return suggestions
+	}
+
+	words := strings.Split(userInput, " ")
+	prefix := words[0] + " "
+	words = words[1:]
+
+	// Every word but the last one has already been typed, so follow the subcommands that they name
+	for len(words) > 1 && len(data.Subcommands) > 0 {
+		if data = data.GetSubcommand(words[0]); data == nil {
+			return suggestions
+		}
+
+		prefix += words[0] + " "
+		words = words[1:]
+	}
+
+	if len(data.Subcommands) > 0 {
+		for _, subcommand := range data.Subcommands {
+			if strings.HasPrefix(strings.ToLower(subcommand.Trigger), strings.ToLower(words[0])) {
+				suggestions = append(suggestions, &model.SuggestCommand{
+					Suggestion:  prefix + subcommand.Trigger,
+					Hint:        subcommand.Hint,
+					Description: subcommand.HelpText,
+				})
+			}
+		}
+
+		return suggestions
+	}
+
+	argumentIndex := len(words) - 1
+	if argumentIndex >= len(data.Arguments) {
+		return suggestions
+	}
+
+	prefix += strings.Join(words[:argumentIndex], " ")
+	if argumentIndex > 0 {
+		prefix += " "
+	}
+
+	argument := data.Arguments[argumentIndex]
+	term := words[argumentIndex]
+
+	var items []*model.AutocompleteListItem
+	switch argument.Type {
+	case model.AUTOCOMPLETE_ARG_TYPE_USER:
+		items = getUserAutocompleteItems(channelId, strings.TrimPrefix(term, "@"))
+	case model.AUTOCOMPLETE_ARG_TYPE_CHANNEL:
+		items = getChannelAutocompleteItems(teamId, strings.TrimPrefix(term, "~"))
+	case model.AUTOCOMPLETE_ARG_TYPE_STATIC_LIST:
+		for _, option := range argument.Options {
+			if strings.HasPrefix(strings.ToLower(option.Item), strings.ToLower(term)) {
+				items = append(items, option)
+			}
+		}
+	case model.AUTOCOMPLETE_ARG_TYPE_DYNAMIC:
+		request := &model.AutocompleteRequest{
+			Token:     command.Token,
+			TeamId:    teamId,
+			ChannelId: channelId,
+			UserId:    userId,
+			Command:   "/" + command.Trigger,
+			UserInput: userInput,
+			Argument:  argument.Name,
+			Term:      term,
+		}
+
+		var err *model.AppError
+		if items, err = getDynamicAutocompleteItems(command.AutoCompleteURL, request); err != nil {
+			l4g.Warn(utils.T("app.command_autocomplete.dynamic.warn"), command.Trigger, err.Error())
+		}
+	default:
+		// Free text can't be completed, so just describe what should be typed
+		items = []*model.AutocompleteListItem{{Item: term, Hint: argument.Name, HelpText: argument.HelpText}}
+	}
+
+	for _, item := range items {
+		if len(suggestions) >= COMMAND_AUTOCOMPLETE_MAX_SUGGESTIONS {
+			break
+		}
+
+		suggestions = append(suggestions, &model.SuggestCommand{
+			Suggestion:  prefix + item.Item,
+			Hint:        item.Hint,
+			Description: item.HelpText,
+		})
+	}
+
+	return suggestions
+}
+
+func getUserAutocompleteItems(channelId string, term string) []*model.AutocompleteListItem {
+	searchOptions := map[string]bool{}
+	searchOptions[store.USER_SEARCH_OPTION_EXCLUDE_BOTS] = true
+
+	if utils.Cfg.PrivacySettings.ShowFullName {
+		searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY] = true
+	} else {
+		searchOptions[store.USER_SEARCH_OPTION_NAMES_ONLY_NO_FULL_NAME] = true
+	}
+
+	items := []*model.AutocompleteListItem{}
+	if result := <-Srv.Store.User().SearchInChannel(channelId, term, searchOptions); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	} else {
+		for _, user := range result.Data.([]*model.User) {
+			item := &model.AutocompleteListItem{Item: "@" + user.Username}
+			if utils.Cfg.PrivacySettings.ShowFullName {
+				item.HelpText = user.GetFullName()
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func getChannelAutocompleteItems(teamId string, term string) []*model.AutocompleteListItem {
+	items := []*model.AutocompleteListItem{}
+	if result := <-Srv.Store.Channel().SearchInTeam(teamId, term); result.Err != nil {
+		l4g.Error(result.Err.Error())
+	} else {
+		for _, channel := range *result.Data.(*model.ChannelList) {
+			items = append(items, &model.AutocompleteListItem{Item: "~" + channel.Name, HelpText: channel.DisplayName})
+		}
+	}
+
+	return items
+}
+
+// getDynamicAutocompleteItems asks a command's integration for suggestions. The request includes the command's token
+// so that the integration can check that it came from this server.
+func getDynamicAutocompleteItems(url string, request *model.AutocompleteRequest) ([]*model.AutocompleteListItem, *model.AppError) {
+	req, err := http.NewRequest("POST", url, strings.NewReader(request.ToJson()))
+	if err != nil {
+		return nil, model.NewLocAppError("getDynamicAutocompleteItems", "app.command_autocomplete.dynamic.app_error", nil, err.Error())
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+	}
+	client := &http.Client{Transport: tr, Timeout: COMMAND_AUTOCOMPLETE_TIMEOUT}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, model.NewLocAppError("getDynamicAutocompleteItems", "app.command_autocomplete.dynamic.app_error", nil, err.Error())
+	}
+
+	defer func() {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, model.NewLocAppError("getDynamicAutocompleteItems", "app.command_autocomplete.dynamic.app_error", nil, "status="+resp.Status)
+	}
+
+	items := model.AutocompleteListItemsFromJson(resp.Body)
+	if items == nil {
+		return nil, model.NewLocAppError("getDynamicAutocompleteItems", "app.command_autocomplete.dynamic.app_error", nil, "invalid response")
+	}
+
+	return items, nil
+}
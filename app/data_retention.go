@@ -0,0 +1,246 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"strconv"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	DATA_RETENTION_TASK_NAME  = "Data Retention"
+	DATA_RETENTION_BATCH_SIZE = 1000
+
+	// Servers in a cluster each schedule the job, so skip it if another server has run it recently
+	DATA_RETENTION_MIN_INTERVAL = 12 * time.Hour
+)
+
+// DataRetentionProgress describes how much data has been deleted by a run of the data retention job so far.
+type DataRetentionProgress struct {
+	TeamId       string
+	PostsDeleted int64
+	FilesDeleted int64
+}
+
+type DataRetentionProgressFunc func(progress *DataRetentionProgress)
+
+func StartDataRetentionJob() {
+	if task := model.GetTaskByName(DATA_RETENTION_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateTask(DATA_RETENTION_TASK_NAME, runScheduledDataRetention, timeUntilDataRetentionJob(time.Now()))
+}
+
+// timeUntilDataRetentionJob returns how long to wait until the next time of day that the job is configured to start.
+func timeUntilDataRetentionJob(now time.Time) time.Duration {
+	startTime, err := time.Parse("15:04", *utils.Cfg.DataRetentionSettings.DeletionJobStartTime)
+	if err != nil {
+		startTime, _ = time.Parse("15:04", model.DATA_RETENTION_SETTINGS_DEFAULT_DELETION_JOB_START_TIME)
+	}
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), startTime.Hour(), startTime.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next.Sub(now)
+}
+
+func runScheduledDataRetention() {
+	defer StartDataRetentionJob()
+
+	if !*utils.Cfg.DataRetentionSettings.EnableMessageDeletion && !*utils.Cfg.DataRetentionSettings.EnableFileDeletion {
+		return
+	}
+
+	if result := <-Srv.Store.System().GetByName(model.SYSTEM_LAST_DATA_RETENTION_TIME); result.Err == nil {
+		lastRun, _ := strconv.ParseInt(result.Data.(*model.System).Value, 10, 64)
+		if model.GetMillis()-lastRun < int64(DATA_RETENTION_MIN_INTERVAL/time.Millisecond) {
+			l4g.Info(utils.T("api.data_retention.run.skipped.info"))
+			return
+		}
+	}
+
+	l4g.Info(utils.T("api.data_retention.run.start.info"))
+
+	if err := RunDataRetention(logDataRetentionProgress); err != nil {
+		l4g.Error(utils.T("api.data_retention.run.error"), err.Error())
+	}
+}
+
+func logDataRetentionProgress(progress *DataRetentionProgress) {
+	l4g.Info(utils.T("api.data_retention.run.progress.info"), progress.TeamId, progress.PostsDeleted, progress.FilesDeleted)
+}
+
+// RunDataRetention permanently deletes every post and file that's older than its team's retention policy, or the
+// global policy for teams without one. A team policy with a period of 0 days uses the global period instead. Anything
+// preserved by a legal hold is kept. The given function is called after each batch is deleted.
+//
+// Deleted posts are removed from the search index of every server in the cluster. If this is run from outside of a
+// running server, the server removes them from its index once it finds that they no longer exist.
+func RunDataRetention(progressFunc DataRetentionProgressFunc) *model.AppError {
+	settings := utils.Cfg.DataRetentionSettings
+	now := time.Now()
+
+	holds, err := GetAllLegalHolds()
+	if err != nil {
+		return err
+	}
+
+	excludeTeamIds := []string{}
+	for _, policy := range settings.TeamPolicies {
+		excludeTeamIds = append(excludeTeamIds, policy.TeamId)
+
+		progress := &DataRetentionProgress{TeamId: policy.TeamId}
+
+		if *settings.EnableFileDeletion {
+			days := getTeamRetentionDays(policy.FileRetentionDays, *settings.FileRetentionDays)
+			if err := deleteFilesForRetention(retentionEndTime(now, days), policy.TeamId, nil, holds, progress, progressFunc); err != nil {
+				return err
+			}
+		}
+
+		if *settings.EnableMessageDeletion {
+			days := getTeamRetentionDays(policy.MessageRetentionDays, *settings.MessageRetentionDays)
+			if err := deletePostsForRetention(retentionEndTime(now, days), policy.TeamId, nil, holds, progress, progressFunc); err != nil {
+				return err
+			}
+		}
+	}
+
+	progress := &DataRetentionProgress{}
+
+	if *settings.EnableFileDeletion {
+		if err := deleteFilesForRetention(retentionEndTime(now, *settings.FileRetentionDays), "", excludeTeamIds, holds, progress, progressFunc); err != nil {
+			return err
+		}
+	}
+
+	if *settings.EnableMessageDeletion {
+		if err := deletePostsForRetention(retentionEndTime(now, *settings.MessageRetentionDays), "", excludeTeamIds, holds, progress, progressFunc); err != nil {
+			return err
+		}
+	}
+
+	system := &model.System{Name: model.SYSTEM_LAST_DATA_RETENTION_TIME, Value: strconv.FormatInt(model.GetMillis(), 10)}
+	if result := <-Srv.Store.System().SaveOrUpdate(system); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// getTeamRetentionDays returns the retention period from a team policy, or the global period if the team's is 0.
+func getTeamRetentionDays(teamDays int, globalDays int) int {
+	if teamDays == 0 {
+		return globalDays
+	}
+
+	return teamDays
+}
+
+func retentionEndTime(now time.Time, days int) int64 {
+	return now.AddDate(0, 0, -days).UnixNano() / int64(time.Millisecond)
+}
+
+func deletePostsForRetention(endTime int64, teamId string, excludeTeamIds []string, holds []*model.LegalHold, progress *DataRetentionProgress, progressFunc DataRetentionProgressFunc) *model.AppError {
+	if err := auditLegalHoldsForPostRetention(holds, endTime, teamId, excludeTeamIds); err != nil {
+		return err
+	}
+
+	for {
+		var postIds []string
+		if result := <-Srv.Store.Post().GetPostIdsForRetention(endTime, teamId, excludeTeamIds, holds, DATA_RETENTION_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			postIds = result.Data.([]string)
+		}
+
+		if len(postIds) == 0 {
+			return nil
+		}
+
+		if result := <-Srv.Store.Reaction().PermanentDeleteByPostIds(postIds); result.Err != nil {
+			return result.Err
+		}
+
+		if result := <-Srv.Store.Thread().PermanentDeleteByPostIds(postIds); result.Err != nil {
+			return result.Err
+		}
+
+		if result := <-Srv.Store.OutgoingWebhookDelivery().PermanentDeleteByPostIds(postIds); result.Err != nil {
+			return result.Err
+		}
+
+		if result := <-Srv.Store.Post().PermanentDeleteByIds(postIds); result.Err != nil {
+			return result.Err
+		}
+
+		for _, postId := range postIds {
+			DeletePostFromIndex(postId)
+		}
+
+		progress.PostsDeleted += int64(len(postIds))
+		if progressFunc != nil {
+			progressFunc(progress)
+		}
+
+		if len(postIds) < DATA_RETENTION_BATCH_SIZE {
+			return nil
+		}
+	}
+}
+
+func deleteFilesForRetention(endTime int64, teamId string, excludeTeamIds []string, holds []*model.LegalHold, progress *DataRetentionProgress, progressFunc DataRetentionProgressFunc) *model.AppError {
+	if err := auditLegalHoldsForFileRetention(holds, endTime, teamId, excludeTeamIds); err != nil {
+		return err
+	}
+
+	for {
+		var infos []*model.FileInfo
+		if result := <-Srv.Store.FileInfo().GetForRetention(endTime, teamId, excludeTeamIds, holds, DATA_RETENTION_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			infos = result.Data.([]*model.FileInfo)
+		}
+
+		if len(infos) == 0 {
+			return nil
+		}
+
+		fileIds := make([]string, len(infos))
+		for i, info := range infos {
+			fileIds[i] = info.Id
+
+			for _, path := range []string{info.Path, info.ThumbnailPath, info.PreviewPath} {
+				if path == "" {
+					continue
+				}
+
+				// Keep going if a file is already missing so that its info can still be removed from the database
+				if err := RemoveFile(path); err != nil {
+					l4g.Warn(utils.T("api.data_retention.remove_file.warn"), path, err.Error())
+				}
+			}
+		}
+
+		if result := <-Srv.Store.FileInfo().PermanentDeleteByIds(fileIds); result.Err != nil {
+			return result.Err
+		}
+
+		progress.FilesDeleted += int64(len(infos))
+		if progressFunc != nil {
+			progressFunc(progress)
+		}
+
+		if len(infos) < DATA_RETENTION_BATCH_SIZE {
+			return nil
+		}
+	}
+}
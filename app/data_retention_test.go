@@ -0,0 +1,47 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/utils"
+)
+
+func TestTimeUntilDataRetentionJob(t *testing.T) {
+	utils.LoadTestConfig()
+
+	startTime := *utils.Cfg.DataRetentionSettings.DeletionJobStartTime
+	defer func() {
+		*utils.Cfg.DataRetentionSettings.DeletionJobStartTime = startTime
+	}()
+
+	*utils.Cfg.DataRetentionSettings.DeletionJobStartTime = "02:00"
+
+	now := time.Date(2017, 5, 1, 1, 30, 0, 0, time.UTC)
+	if duration := timeUntilDataRetentionJob(now); duration != 30*time.Minute {
+		t.Fatal("should've run later the same day", duration)
+	}
+
+	now = time.Date(2017, 5, 1, 2, 0, 0, 0, time.UTC)
+	if duration := timeUntilDataRetentionJob(now); duration != 24*time.Hour {
+		t.Fatal("should've run the next day", duration)
+	}
+
+	now = time.Date(2017, 5, 1, 23, 15, 0, 0, time.UTC)
+	if duration := timeUntilDataRetentionJob(now); duration != 2*time.Hour+45*time.Minute {
+		t.Fatal("should've run the next day", duration)
+	}
+}
+
+func TestGetTeamRetentionDays(t *testing.T) {
+	if days := getTeamRetentionDays(30, 365); days != 30 {
+		t.Fatal("should've used the team's retention period", days)
+	}
+
+	if days := getTeamRetentionDays(0, 365); days != 365 {
+		t.Fatal("should've used the global retention period", days)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// SaveDraft creates or replaces a user's draft and sends it to each of their sessions so that every device shows the
+// same draft.
+func SaveDraft(draft *model.Draft) (*model.Draft, *model.AppError) {
+	if result := <-Srv.Store.Draft().Save(draft); result.Err != nil {
+		return nil, result.Err
+	} else {
+		draft = result.Data.(*model.Draft)
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_DRAFT_UPDATED, "", "", draft.UserId, nil)
+	message.Add("draft", draft.ToJson())
+
+	go Publish(message)
+
+	return draft, nil
+}
+
+func GetDraftsForUser(teamId string, userId string) ([]*model.Draft, *model.AppError) {
+	if result := <-Srv.Store.Draft().GetForUser(teamId, userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.Draft), nil
+	}
+}
+
+// DeleteDraft removes a user's draft and lets each of their sessions know that it's gone.
+func DeleteDraft(userId string, channelId string, rootId string) *model.AppError {
+	if result := <-Srv.Store.Draft().Delete(userId, channelId, rootId); result.Err != nil {
+		return result.Err
+	} else if !result.Data.(bool) {
+		// there was no draft to delete
+		return nil
+	}
+
+	draft := &model.Draft{
+		UserId:    userId,
+		ChannelId: channelId,
+		RootId:    rootId,
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_DRAFT_DELETED, "", "", userId, nil)
+	message.Add("draft", draft.ToJson())
+
+	go Publish(message)
+
+	return nil
+}
+
+// deleteDraftForPost removes the draft that a post was written from now that it's been sent.
+func deleteDraftForPost(post *model.Post) {
+	if err := DeleteDraft(post.UserId, post.ChannelId, post.RootId); err != nil {
+		l4g.Error(utils.T("api.draft.delete_for_post.error"), post.Id, err.Error())
+	}
+}
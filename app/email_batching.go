@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"html/template"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/mattermost/platform/model"
@@ -48,6 +49,15 @@ func AddNotificationEmailToBatch(user *model.User, post *model.Post, team *model
 	return nil
 }
 
+// EmailBatchingQueueSize returns the number of notifications that are waiting to be sent in a batched email.
+func EmailBatchingQueueSize() int {
+	if emailBatchingJob == nil {
+		return 0
+	}
+
+	return len(emailBatchingJob.newNotifications) + int(atomic.LoadInt64(&emailBatchingJob.pendingCount))
+}
+
 type batchedNotification struct {
 	userId   string
 	post     *model.Post
@@ -57,6 +67,7 @@ type batchedNotification struct {
 type EmailBatchingJob struct {
 	newNotifications     chan *batchedNotification
 	pendingNotifications map[string][]*batchedNotification
+	pendingCount         int64
 }
 
 func MakeEmailBatchingJob(bufferSize int) *EmailBatchingJob {
@@ -98,6 +109,12 @@ func (job *EmailBatchingJob) CheckPendingEmails() {
 	// without actually sending emails
 	job.checkPendingNotifications(time.Now(), sendBatchedEmailNotification)
 
+	pendingCount := 0
+	for _, notifications := range job.pendingNotifications {
+		pendingCount += len(notifications)
+	}
+	atomic.StoreInt64(&job.pendingCount, int64(pendingCount))
+
 	l4g.Debug(utils.T("api.email_batching.check_pending_emails.finished_running"), len(job.pendingNotifications))
 }
 
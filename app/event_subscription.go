@@ -0,0 +1,143 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func CreateEventSubscription(subscription *model.EventSubscription) (*model.EventSubscription, *model.AppError) {
+	if result := <-Srv.Store.EventSubscription().Save(subscription); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.EventSubscription), nil
+	}
+}
+
+func GetEventSubscription(id string) (*model.EventSubscription, *model.AppError) {
+	if result := <-Srv.Store.EventSubscription().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.EventSubscription), nil
+	}
+}
+
+func GetEventSubscriptionsForTeam(teamId string) ([]*model.EventSubscription, *model.AppError) {
+	if result := <-Srv.Store.EventSubscription().GetByTeam(teamId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.EventSubscription), nil
+	}
+}
+
+// UpdateEventSubscription changes the URL, events and description of a subscription. Everything else is kept from the
+// old subscription.
+func UpdateEventSubscription(oldSubscription *model.EventSubscription, updated *model.EventSubscription) (*model.EventSubscription, *model.AppError) {
+	subscription := &model.EventSubscription{}
+	*subscription = *oldSubscription
+
+	subscription.URL = updated.URL
+	subscription.Events = updated.Events
+	subscription.DisplayName = updated.DisplayName
+	subscription.Description = updated.Description
+
+	if result := <-Srv.Store.EventSubscription().Update(subscription); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.EventSubscription), nil
+	}
+}
+
+func RegenerateEventSubscriptionSecret(subscription *model.EventSubscription) (*model.EventSubscription, *model.AppError) {
+	updated := &model.EventSubscription{}
+	*updated = *subscription
+	updated.Secret = model.NewRandomString(model.EVENT_SUBSCRIPTION_SECRET_LENGTH)
+
+	if result := <-Srv.Store.EventSubscription().Update(updated); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.EventSubscription), nil
+	}
+}
+
+func DeleteEventSubscription(id string) *model.AppError {
+	if result := <-Srv.Store.EventSubscription().Delete(id, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// HandleEventSubscriptions queues an event to be sent to the subscriptions of the team that it happened in. Only events
+// that anyone on the team could see are sent, so events in private channels and direct messages are skipped. Events are
+// delivered the same way as outgoing webhooks so that they're retried if the subscription's URL can't be reached.
+func HandleEventSubscriptions(event *model.WebSocketEvent) {
+	if !*utils.Cfg.ServiceSettings.EnableEventSubscriptions || !model.IsSubscribableEvent(event.Event) {
+		return
+	}
+
+	channelId := getEventSubscriptionChannelId(event)
+
+	teamId := getEventSubscriptionTeamId(event, channelId)
+	if len(teamId) == 0 {
+		return
+	}
+
+	subscriptions, err := GetEventSubscriptionsForTeam(teamId)
+	if err != nil {
+		l4g.Error(utils.T("app.event_subscription.get.error"), teamId, err.Error())
+		return
+	}
+
+	payload := event.GetPreComputeJson()
+	if payload == nil {
+		payload = []byte(event.ToJson())
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.HasEvent(event.Event) {
+			continue
+		}
+
+		delivery := &model.OutgoingWebhookDelivery{
+			HookId:      subscription.Id,
+			Event:       event.Event,
+			ChannelId:   channelId,
+			URL:         subscription.URL,
+			ContentType: "application/json",
+			Payload:     string(payload),
+		}
+
+		if err := EnqueueOutgoingWebhookDelivery(delivery); err != nil {
+			l4g.Error(utils.T("app.event_subscription.enqueue.error"), subscription.Id, err.Error())
+		}
+	}
+}
+
+func getEventSubscriptionChannelId(event *model.WebSocketEvent) string {
+	if len(event.Broadcast.ChannelId) > 0 {
+		return event.Broadcast.ChannelId
+	}
+
+	channelId, _ := event.Data["channel_id"].(string)
+	return channelId
+}
+
+// getEventSubscriptionTeamId returns the team that an event happened in or an empty string if the event shouldn't be
+// sent to subscriptions.
+func getEventSubscriptionTeamId(event *model.WebSocketEvent, channelId string) string {
+	if len(channelId) == 0 {
+		// Team events are broadcast to the team that they happened in
+		return event.Broadcast.TeamId
+	}
+
+	// The channel's members were just sent the event, so the channel is almost always in the cache
+	if channel, err := GetChannel(channelId); err != nil || channel.Type != model.CHANNEL_OPEN {
+		return ""
+	} else {
+		return channel.TeamId
+	}
+}
@@ -7,155 +7,129 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
 	"io"
-	"io/ioutil"
+	"net/http"
 	"net/url"
-	"os"
-	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 
 	l4g "github.com/alecthomas/log4go"
+	"github.com/disintegration/imaging"
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
-
-	s3 "github.com/minio/minio-go"
+	"github.com/rwcarlsen/goexif/exif"
+	_ "golang.org/x/image/bmp"
 )
 
-func ReadFile(path string) ([]byte, *model.AppError) {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
-		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
-		if err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
-		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
-		minioObject, err := s3Clnt.GetObject(bucket, path)
-		defer minioObject.Close()
+var fileBackend utils.FileBackend
+var fileBackendCfgHash string
+var fileBackendLock sync.Mutex
+
+// FileBackend returns the file store for the configured driver. It's only created once and then reused until the
+// config is reloaded.
+func FileBackend() (utils.FileBackend, *model.AppError) {
+	fileBackendLock.Lock()
+	defer fileBackendLock.Unlock()
+
+	if fileBackend == nil || fileBackendCfgHash != utils.CfgHash {
+		backend, err := utils.NewFileBackend(&utils.Cfg.FileSettings)
 		if err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
+			return nil, err
 		}
-		if f, err := ioutil.ReadAll(minioObject); err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
-		} else {
-			return f, nil
-		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if f, err := ioutil.ReadFile(utils.Cfg.FileSettings.Directory + path); err != nil {
-			return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
-		} else {
-			return f, nil
-		}
-	} else {
-		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.configured.app_error", nil, "")
+
+		fileBackend = backend
+		fileBackendCfgHash = utils.CfgHash
 	}
+
+	return fileBackend, nil
 }
 
-func MoveFile(oldPath, newPath string) *model.AppError {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
-		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
-		if err != nil {
-			return model.NewLocAppError("moveFile", "api.file.write_file.s3.app_error", nil, err.Error())
-		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
+func InitFileBackend() {
+	if len(utils.Cfg.FileSettings.DriverName) == 0 {
+		return
+	}
 
-		var copyConds = s3.NewCopyConditions()
-		if err = s3Clnt.CopyObject(bucket, newPath, "/"+path.Join(bucket, oldPath), copyConds); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
-		}
-		if err = s3Clnt.RemoveObject(bucket, oldPath); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
-		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := os.MkdirAll(filepath.Dir(utils.Cfg.FileSettings.Directory+newPath), 0774); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
-		}
+	if backend, err := FileBackend(); err != nil {
+		l4g.Error(utils.T("api.file.init_file_backend.error"), err.Error())
+	} else if err := backend.TestConnection(); err != nil {
+		l4g.Error(utils.T("api.file.init_file_backend.error"), err.Error())
+	}
+}
 
-		if err := os.Rename(utils.Cfg.FileSettings.Directory+oldPath, utils.Cfg.FileSettings.Directory+newPath); err != nil {
-			return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
-		}
-	} else {
-		return model.NewLocAppError("moveFile", "api.file.move_file.configured.app_error", nil, "")
+func ReadFile(path string) ([]byte, *model.AppError) {
+	backend, err := FileBackend()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return backend.ReadFile(path)
 }
 
-func WriteFile(f []byte, path string) *model.AppError {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		endpoint := utils.Cfg.FileSettings.AmazonS3Endpoint
-		accessKey := utils.Cfg.FileSettings.AmazonS3AccessKeyId
-		secretKey := utils.Cfg.FileSettings.AmazonS3SecretAccessKey
-		secure := *utils.Cfg.FileSettings.AmazonS3SSL
-		s3Clnt, err := s3.New(endpoint, accessKey, secretKey, secure)
-		if err != nil {
-			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
-		}
-		bucket := utils.Cfg.FileSettings.AmazonS3Bucket
-		ext := filepath.Ext(path)
-
-		if model.IsFileExtImage(ext) {
-			_, err = s3Clnt.PutObject(bucket, path, bytes.NewReader(f), model.GetImageMimeType(ext))
-		} else {
-			_, err = s3Clnt.PutObject(bucket, path, bytes.NewReader(f), "binary/octet-stream")
-		}
-		if err != nil {
-			return model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
-		}
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := writeFileLocally(f, utils.Cfg.FileSettings.Directory+path); err != nil {
-			return err
-		}
-	} else {
-		return model.NewLocAppError("WriteFile", "api.file.write_file.configured.app_error", nil, "")
+// FileReader opens a stream to the stored file at the given path. The caller is responsible for closing it.
+func FileReader(path string) (io.ReadCloser, *model.AppError) {
+	backend, err := FileBackend()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return backend.Reader(path)
 }
 
-func writeFileLocally(f []byte, path string) *model.AppError {
-	if err := os.MkdirAll(filepath.Dir(path), 0774); err != nil {
-		directory, _ := filepath.Abs(filepath.Dir(path))
-		return model.NewLocAppError("WriteFile", "api.file.write_file_locally.create_dir.app_error", nil, "directory="+directory+", err="+err.Error())
+func FileExists(path string) (bool, *model.AppError) {
+	backend, err := FileBackend()
+	if err != nil {
+		return false, err
 	}
 
-	if err := ioutil.WriteFile(path, f, 0644); err != nil {
-		return model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, err.Error())
+	return backend.FileExists(path)
+}
+
+func MoveFile(oldPath, newPath string) *model.AppError {
+	backend, err := FileBackend()
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return backend.MoveFile(oldPath, newPath)
 }
 
-func openFileWriteStream(path string) (io.Writer, *model.AppError) {
-	if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_S3 {
-		return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.s3.app_error", nil, "")
-	} else if utils.Cfg.FileSettings.DriverName == model.IMAGE_DRIVER_LOCAL {
-		if err := os.MkdirAll(filepath.Dir(utils.Cfg.FileSettings.Directory+path), 0774); err != nil {
-			return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.creating_dir.app_error", nil, err.Error())
-		}
+func WriteFile(f []byte, path string) *model.AppError {
+	backend, err := FileBackend()
+	if err != nil {
+		return err
+	}
 
-		if fileHandle, err := os.Create(utils.Cfg.FileSettings.Directory + path); err != nil {
-			return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.local_server.app_error", nil, err.Error())
-		} else {
-			fileHandle.Chmod(0644)
-			return fileHandle, nil
-		}
+	_, err = backend.WriteFile(bytes.NewReader(f), path)
+	return err
+}
+
+// WriteFileStream copies everything from the given reader into a new file without holding it in memory.
+func WriteFileStream(fr io.Reader, path string) (int64, *model.AppError) {
+	backend, err := FileBackend()
+	if err != nil {
+		return 0, err
 	}
 
-	return nil, model.NewLocAppError("openFileWriteStream", "api.file.open_file_write_stream.configured.app_error", nil, "")
+	return backend.WriteFile(fr, path)
 }
 
-func closeFileWriteStream(file io.Writer) {
-	file.(*os.File).Close()
+func RemoveFile(path string) *model.AppError {
+	backend, err := FileBackend()
+	if err != nil {
+		return err
+	}
+
+	return backend.RemoveFile(path)
 }
 
 func GetInfoForFilename(post *model.Post, teamId string, filename string) *model.FileInfo {
@@ -222,7 +196,7 @@ func FindTeamIdForFilename(post *model.Post, filename string) string {
 	} else {
 		for _, team := range teams {
 			path := fmt.Sprintf("teams/%s/channels/%s/users/%s/%s/%s", team.Id, post.ChannelId, post.UserId, id, name)
-			if _, err := ReadFile(path); err == nil {
+			if exists, err := FileExists(path); err == nil && exists {
 				// Found the team that this file was posted from
 				return team.Id
 			}
@@ -338,3 +312,336 @@ func GeneratePublicLinkHash(fileId, salt string) string {
 
 	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
 }
+
+const (
+	/*
+	  EXIF Image Orientations
+	  1        2       3      4         5            6           7          8
+
+	  888888  888888      88  88      8888888888  88                  88  8888888888
+	  88          88      88  88      88  88      88  88          88  88      88  88
+	  8888      8888    8888  8888    88          8888888888  8888888888          88
+	  88          88      88  88
+	  88          88  888888  888888
+	*/
+	Upright            = 1
+	UprightMirrored    = 2
+	UpsideDown         = 3
+	UpsideDownMirrored = 4
+	RotatedCWMirrored  = 5
+	RotatedCCW         = 6
+	RotatedCCWMirrored = 7
+	RotatedCW          = 8
+
+	MaxImageSize = 6048 * 4032 // 24 megapixels, roughly 36MB as a raw image
+
+	IMAGE_PROCESSING_QUEUE_SIZE = 1000
+)
+
+// UploadFileStream copies a file from the given reader into the file store, computing its size and hash along the way
+// so that the file is never held in memory in full. The FileInfo for the new file is saved before being returned.
+func UploadFileStream(teamId string, channelId string, userId string, rawFilename string, fr io.Reader) (*model.FileInfo, *model.AppError) {
+	filename := filepath.Base(rawFilename)
+
+	info := model.NewInfo(filename)
+	info.Id = model.NewId()
+	info.CreatorId = userId
+
+	pathPrefix := "teams/" + teamId + "/channels/" + channelId + "/users/" + userId + "/" + info.Id + "/"
+	info.Path = pathPrefix + filename
+
+	hash := sha256.New()
+
+	// Read one extra byte so that we can tell if the file went over the limit
+	maxFileSize := *utils.Cfg.FileSettings.MaxFileSize
+	limitedReader := &io.LimitedReader{R: io.TeeReader(fr, hash), N: maxFileSize + 1}
+
+	if written, err := WriteFileStream(limitedReader, info.Path); err != nil {
+		return nil, err
+	} else if written > maxFileSize {
+		RemoveFile(info.Path)
+
+		err := model.NewLocAppError("UploadFileStream", "api.file.upload_file.too_large.app_error", nil, "")
+		err.StatusCode = http.StatusRequestEntityTooLarge
+		return nil, err
+	} else {
+		info.Size = written
+		info.Hash = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	if info.IsImage() {
+		if err := setImageInfo(info); err != nil {
+			RemoveFile(info.Path)
+			return nil, err
+		}
+
+		if info.Width > 0 && info.Height > 0 {
+			nameWithoutExtension := filename[:strings.LastIndex(filename, ".")]
+			info.PreviewPath = pathPrefix + nameWithoutExtension + "_preview.jpg"
+			info.ThumbnailPath = pathPrefix + nameWithoutExtension + "_thumb.jpg"
+		}
+	}
+
+	if utils.CanExtractText(info.Extension) && info.Size <= utils.TEXT_EXTRACTION_MAX_FILE_SIZE {
+		setFileContent(info)
+	}
+
+	if result := <-Srv.Store.FileInfo().Save(info); result.Err != nil {
+		RemoveFile(info.Path)
+		return nil, result.Err
+	}
+
+	return info, nil
+}
+
+// SearchFilesInTeam returns the files attached to posts that the user can see which match the given search terms.
+func SearchFilesInTeam(terms string, userId string, teamId string, isOrSearch bool) ([]*model.FileInfo, *model.AppError) {
+	paramsList := model.ParseSearchParams(terms)
+	channels := []store.StoreChannel{}
+
+	for _, params := range paramsList {
+		params.OrTerms = isOrSearch
+		// don't allow users to search for everything
+		if params.Terms != "*" {
+			channels = append(channels, Srv.Store.FileInfo().Search(teamId, userId, params))
+		}
+	}
+
+	infos := []*model.FileInfo{}
+	found := map[string]bool{}
+
+	for _, channel := range channels {
+		if result := <-channel; result.Err != nil {
+			return nil, result.Err
+		} else {
+			for _, info := range result.Data.([]*model.FileInfo) {
+				if !found[info.Id] {
+					infos = append(infos, info)
+					found[info.Id] = true
+				}
+			}
+		}
+	}
+
+	return infos, nil
+}
+
+// setFileContent reads a file back from the file store to extract the text that it contains so that it can be found by
+// searches. Files that can't be read are still uploaded, just without any content.
+func setFileContent(info *model.FileInfo) {
+	data, err := ReadFile(info.Path)
+	if err != nil {
+		l4g.Warn(utils.T("api.file.extract_content.read.warn"), info.Path, err.Error())
+		return
+	}
+
+	if content, err := utils.ExtractText(info.Extension, data); err != nil {
+		l4g.Warn(utils.T("api.file.extract_content.extract.warn"), info.Path, err.Error())
+	} else {
+		info.Content = content
+	}
+}
+
+// setImageInfo reads the dimensions of an image back from the file store. Only the header of the image is read unless
+// it's a gif, in which case the whole thing is decoded to tell if it's animated.
+func setImageInfo(info *model.FileInfo) *model.AppError {
+	reader, err := FileReader(info.Path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	config, _, decodeErr := image.DecodeConfig(reader)
+	if decodeErr != nil {
+		// Still upload the file even if we don't understand the image
+		return nil
+	}
+
+	// Check dimensions before loading the whole thing into memory later on
+	if config.Width*config.Height > MaxImageSize {
+		err := model.NewLocAppError("uploadFile", "api.file.upload_file.large_image.app_error", map[string]interface{}{"Filename": info.Name}, "")
+		err.StatusCode = http.StatusBadRequest
+		return err
+	}
+
+	info.Width = config.Width
+	info.Height = config.Height
+	info.HasPreviewImage = true
+
+	if info.MimeType == "image/gif" {
+		// Just show the gif itself instead of a preview image for animated gifs
+		if gifReader, err := FileReader(info.Path); err == nil {
+			defer gifReader.Close()
+
+			if gifConfig, err := gif.DecodeAll(gifReader); err == nil {
+				info.HasPreviewImage = len(gifConfig.Image) == 1
+			}
+		}
+	}
+
+	return nil
+}
+
+var imageProcessingQueue chan *model.FileInfo
+var imageProcessingOnce sync.Once
+
+// HandleImages queues the thumbnail and preview images for the given files to be generated in the background. A fixed
+// number of workers read the images back from the file store so that uploading many images at once doesn't cause
+// every one of them to be decoded at the same time.
+func HandleImages(infos []*model.FileInfo) {
+	imageProcessingOnce.Do(startImageProcessingWorkers)
+
+	for _, info := range infos {
+		if info.PreviewPath != "" || info.ThumbnailPath != "" {
+			imageProcessingQueue <- info
+		}
+	}
+}
+
+func startImageProcessingWorkers() {
+	imageProcessingQueue = make(chan *model.FileInfo, IMAGE_PROCESSING_QUEUE_SIZE)
+
+	for i := 0; i < runtime.NumCPU(); i++ {
+		go func() {
+			for info := range imageProcessingQueue {
+				GenerateImages(info)
+			}
+		}()
+	}
+}
+
+// GenerateImages creates the thumbnail and preview images for an uploaded image file.
+func GenerateImages(info *model.FileInfo) {
+	img, width, height := prepareImage(info.Path)
+	if img == nil {
+		return
+	}
+
+	if info.ThumbnailPath != "" {
+		generateThumbnailImage(*img, info.ThumbnailPath, width, height)
+	}
+
+	if info.PreviewPath != "" {
+		generatePreviewImage(*img, info.PreviewPath, width)
+	}
+}
+
+func prepareImage(path string) (*image.Image, int, int) {
+	reader, appErr := FileReader(path)
+	if appErr != nil {
+		l4g.Error(utils.T("api.file.handle_images_forget.decode.error"), appErr)
+		return nil, 0, 0
+	}
+	defer reader.Close()
+
+	// Decode image bytes into Image object
+	img, imgType, err := image.Decode(reader)
+	if err != nil {
+		l4g.Error(utils.T("api.file.handle_images_forget.decode.error"), err)
+		return nil, 0, 0
+	}
+
+	width := img.Bounds().Dx()
+	height := img.Bounds().Dy()
+
+	// Fill in the background of a potentially-transparent png file as white
+	if imgType == "png" {
+		dst := image.NewRGBA(img.Bounds())
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+		img = dst
+	}
+
+	// Flip the image to be upright
+	orientation, _ := getImageOrientation(path)
+
+	switch orientation {
+	case UprightMirrored:
+		img = imaging.FlipH(img)
+	case UpsideDown:
+		img = imaging.Rotate180(img)
+	case UpsideDownMirrored:
+		img = imaging.FlipV(img)
+	case RotatedCWMirrored:
+		img = imaging.Transpose(img)
+	case RotatedCCW:
+		img = imaging.Rotate270(img)
+	case RotatedCCWMirrored:
+		img = imaging.Transverse(img)
+	case RotatedCW:
+		img = imaging.Rotate90(img)
+	}
+
+	return &img, width, height
+}
+
+func getImageOrientation(path string) (int, error) {
+	reader, appErr := FileReader(path)
+	if appErr != nil {
+		return Upright, appErr
+	}
+	defer reader.Close()
+
+	if exifData, err := exif.Decode(reader); err != nil {
+		return Upright, err
+	} else {
+		if tag, err := exifData.Get("Orientation"); err != nil {
+			return Upright, err
+		} else {
+			orientation, err := tag.Int(0)
+			if err != nil {
+				return Upright, err
+			} else {
+				return orientation, nil
+			}
+		}
+	}
+}
+
+func generateThumbnailImage(img image.Image, thumbnailPath string, width int, height int) {
+	thumbWidth := float64(utils.Cfg.FileSettings.ThumbnailWidth)
+	thumbHeight := float64(utils.Cfg.FileSettings.ThumbnailHeight)
+	imgWidth := float64(width)
+	imgHeight := float64(height)
+
+	var thumbnail image.Image
+	if imgHeight < thumbHeight && imgWidth < thumbWidth {
+		thumbnail = img
+	} else if imgHeight/imgWidth < thumbHeight/thumbWidth {
+		thumbnail = imaging.Resize(img, 0, utils.Cfg.FileSettings.ThumbnailHeight, imaging.Lanczos)
+	} else {
+		thumbnail = imaging.Resize(img, utils.Cfg.FileSettings.ThumbnailWidth, 0, imaging.Lanczos)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jpeg.Encode(buf, thumbnail, &jpeg.Options{Quality: 90}); err != nil {
+		l4g.Error(utils.T("api.file.handle_images_forget.encode_jpeg.error"), thumbnailPath, err)
+		return
+	}
+
+	if err := WriteFile(buf.Bytes(), thumbnailPath); err != nil {
+		l4g.Error(utils.T("api.file.handle_images_forget.upload_thumb.error"), thumbnailPath, err)
+		return
+	}
+}
+
+func generatePreviewImage(img image.Image, previewPath string, width int) {
+	var preview image.Image
+	if width > int(utils.Cfg.FileSettings.PreviewWidth) {
+		preview = imaging.Resize(img, utils.Cfg.FileSettings.PreviewWidth, utils.Cfg.FileSettings.PreviewHeight, imaging.Lanczos)
+	} else {
+		preview = img
+	}
+
+	buf := new(bytes.Buffer)
+
+	if err := jpeg.Encode(buf, preview, &jpeg.Options{Quality: 90}); err != nil {
+		l4g.Error(utils.T("api.file.handle_images_forget.encode_preview.error"), previewPath, err)
+		return
+	}
+
+	if err := WriteFile(buf.Bytes(), previewPath); err != nil {
+		l4g.Error(utils.T("api.file.handle_images_forget.upload_preview.error"), previewPath, err)
+		return
+	}
+}
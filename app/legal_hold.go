@@ -0,0 +1,215 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	LEGAL_HOLD_AUDIT_BLOCKED_DELETION = "legal_hold_blocked_deletion"
+)
+
+func CreateLegalHold(hold *model.LegalHold) (*model.LegalHold, *model.AppError) {
+	if result := <-Srv.Store.LegalHold().Save(hold); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.LegalHold), nil
+	}
+}
+
+func GetLegalHold(id string) (*model.LegalHold, *model.AppError) {
+	if result := <-Srv.Store.LegalHold().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.LegalHold), nil
+	}
+}
+
+func GetAllLegalHolds() ([]*model.LegalHold, *model.AppError) {
+	if result := <-Srv.Store.LegalHold().GetAll(); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.LegalHold), nil
+	}
+}
+
+// UpdateLegalHold replaces the users, channels and time range covered by an existing hold.
+func UpdateLegalHold(hold *model.LegalHold) (*model.LegalHold, *model.AppError) {
+	oldHold, err := GetLegalHold(hold.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	hold.CreateAt = oldHold.CreateAt
+	hold.CreatorId = oldHold.CreatorId
+
+	if result := <-Srv.Store.LegalHold().Update(hold); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.LegalHold), nil
+	}
+}
+
+func DeleteLegalHold(id string) *model.AppError {
+	if _, err := GetLegalHold(id); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.LegalHold().Delete(id); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// GetLegalHoldsBlockingUserDeletion returns the holds that would be violated by permanently deleting a user, either
+// because they name the user or because the user has posted in a held channel during the hold.
+func GetLegalHoldsBlockingUserDeletion(userId string) ([]*model.LegalHold, *model.AppError) {
+	holds, err := GetAllLegalHolds()
+	if err != nil {
+		return nil, err
+	}
+
+	blocking := []*model.LegalHold{}
+	for _, hold := range holds {
+		if hold.HasUser(userId) {
+			blocking = append(blocking, hold)
+			continue
+		}
+
+		if result := <-Srv.Store.Post().CountPostsForLegalHold(hold, userId, ""); result.Err != nil {
+			return nil, result.Err
+		} else if result.Data.(int64) > 0 {
+			blocking = append(blocking, hold)
+		}
+	}
+
+	return blocking, nil
+}
+
+// GetLegalHoldsBlockingTeamDeletion returns the holds that would be violated by permanently deleting a team, either
+// because they name one of its channels or because a held user has posted in the team during the hold.
+func GetLegalHoldsBlockingTeamDeletion(teamId string) ([]*model.LegalHold, *model.AppError) {
+	holds, err := GetAllLegalHolds()
+	if err != nil {
+		return nil, err
+	}
+
+	blocking := []*model.LegalHold{}
+	for _, hold := range holds {
+		inTeam := false
+		for _, channelId := range hold.ChannelIds {
+			// Channels that no longer exist can't be in the team
+			if result := <-Srv.Store.Channel().Get(channelId, true); result.Err == nil && result.Data.(*model.Channel).TeamId == teamId {
+				inTeam = true
+				break
+			}
+		}
+
+		if inTeam {
+			blocking = append(blocking, hold)
+			continue
+		}
+
+		if result := <-Srv.Store.Post().CountPostsForLegalHold(hold, "", teamId); result.Err != nil {
+			return nil, result.Err
+		} else if result.Data.(int64) > 0 {
+			blocking = append(blocking, hold)
+		}
+	}
+
+	return blocking, nil
+}
+
+// CheckLegalHoldsForUserDeletion returns an error if a user can't be permanently deleted because of a legal hold.
+// An audit entry is written for each hold that blocks the deletion.
+func CheckLegalHoldsForUserDeletion(userId string) *model.AppError {
+	holds, err := GetLegalHoldsBlockingUserDeletion(userId)
+	if err != nil {
+		return err
+	}
+
+	return blockDeletionForLegalHolds(holds, userId, "user_id="+userId)
+}
+
+// CheckLegalHoldsForTeamDeletion returns an error if a team can't be permanently deleted because of a legal hold.
+// An audit entry is written for each hold that blocks the deletion.
+func CheckLegalHoldsForTeamDeletion(teamId string) *model.AppError {
+	holds, err := GetLegalHoldsBlockingTeamDeletion(teamId)
+	if err != nil {
+		return err
+	}
+
+	return blockDeletionForLegalHolds(holds, "", "team_id="+teamId)
+}
+
+func blockDeletionForLegalHolds(holds []*model.LegalHold, userId string, target string) *model.AppError {
+	if len(holds) == 0 {
+		return nil
+	}
+
+	for _, hold := range holds {
+		AuditLegalHoldBlockedDeletion(hold, userId, target)
+	}
+
+	err := model.NewLocAppError("CheckLegalHolds", "api.legal_hold.blocked_deletion.app_error", map[string]interface{}{"Name": holds[0].Name}, target)
+	err.StatusCode = http.StatusForbidden
+	return err
+}
+
+// AuditLegalHoldBlockedDeletion records that a hold prevented something from being permanently deleted.
+func AuditLegalHoldBlockedDeletion(hold *model.LegalHold, userId string, target string) {
+	l4g.Warn(utils.T("api.legal_hold.blocked_deletion.warn"), hold.Name, hold.Id, target)
+
+	audit := &model.Audit{
+		UserId:    userId,
+		Action:    LEGAL_HOLD_AUDIT_BLOCKED_DELETION,
+		ExtraInfo: "legal_hold_id=" + hold.Id + " " + target,
+	}
+
+	if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+		l4g.Error(utils.T("api.legal_hold.audit.error"), hold.Id, result.Err.Error())
+	}
+}
+
+// auditLegalHoldsForPostRetention records each hold that's preventing posts older than endTime from being deleted by
+// a data retention policy.
+func auditLegalHoldsForPostRetention(holds []*model.LegalHold, endTime int64, teamId string, excludeTeamIds []string) *model.AppError {
+	return auditLegalHoldsForRetention(holds, endTime, teamId, "posts", func(hold *model.LegalHold) store.StoreChannel {
+		return Srv.Store.Post().CountPostsHeldForRetention(endTime, teamId, excludeTeamIds, hold)
+	})
+}
+
+// auditLegalHoldsForFileRetention records each hold that's preventing files older than endTime from being deleted by
+// a data retention policy.
+func auditLegalHoldsForFileRetention(holds []*model.LegalHold, endTime int64, teamId string, excludeTeamIds []string) *model.AppError {
+	return auditLegalHoldsForRetention(holds, endTime, teamId, "files", func(hold *model.LegalHold) store.StoreChannel {
+		return Srv.Store.FileInfo().CountHeldForRetention(endTime, teamId, excludeTeamIds, hold)
+	})
+}
+
+// auditLegalHoldsForRetention writes an audit entry for each hold that's actually keeping something that would
+// otherwise have been deleted, as counted by countHeld.
+func auditLegalHoldsForRetention(holds []*model.LegalHold, endTime int64, teamId string, kind string, countHeld func(*model.LegalHold) store.StoreChannel) *model.AppError {
+	for _, hold := range holds {
+		if hold.StartAt >= endTime {
+			continue
+		}
+
+		if result := <-countHeld(hold); result.Err != nil {
+			return result.Err
+		} else if count := result.Data.(int64); count > 0 {
+			AuditLegalHoldBlockedDeletion(hold, "", "data_retention_team_id="+teamId+" end_time="+strconv.FormatInt(endTime, 10)+" "+kind+"="+strconv.FormatInt(count, 10))
+		}
+	}
+
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	l4g "github.com/alecthomas/log4go"
@@ -448,7 +449,18 @@ func GetMessageForNotification(post *model.Post, translateFunc i18n.TranslateFun
 	}
 }
 
+// pushNotificationsInProgress is the number of push notifications that are being sent to the push proxy
+var pushNotificationsInProgress int64
+
+// PushNotificationQueueSize returns the number of push notifications that are still being sent.
+func PushNotificationQueueSize() int {
+	return int(atomic.LoadInt64(&pushNotificationsInProgress))
+}
+
 func sendPushNotification(post *model.Post, user *model.User, channel *model.Channel, senderName string, wasMentioned bool) *model.AppError {
+	atomic.AddInt64(&pushNotificationsInProgress, 1)
+	defer atomic.AddInt64(&pushNotificationsInProgress, -1)
+
 	sessions, err := getMobileAppSessions(user.Id)
 	if err != nil {
 		return err
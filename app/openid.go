@@ -0,0 +1,106 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// The ids of the sessions that are being refreshed. Providers may only accept each refresh token once, so a session
+// is never refreshed twice at the same time.
+var openIdRefreshesInProgress = make(map[string]bool)
+var openIdRefreshesMutex sync.Mutex
+
+// SetOpenIdRefreshToken saves the refresh token issued when a user signed in with an OpenID Connect provider so that
+// the provider can be asked later on whether the user is still allowed to sign in.
+func SetOpenIdRefreshToken(session *model.Session, refreshToken string) *model.AppError {
+	// Copy the props since the cached session shares them
+	props := model.StringMap{}
+	for key, value := range session.Props {
+		props[key] = value
+	}
+
+	props[model.SESSION_PROP_OPENID_REFRESH_TOKEN] = refreshToken
+	props[model.SESSION_PROP_OPENID_REFRESHED_AT] = strconv.FormatInt(model.GetMillis(), 10)
+
+	updated := *session
+	updated.Props = props
+
+	if result := <-Srv.Store.Session().UpdateProps(&updated); result.Err != nil {
+		return result.Err
+	}
+
+	AddSessionToCache(&updated)
+
+	return nil
+}
+
+// RefreshOpenIdSession uses a session's refresh token to check that the user who created it can still sign in with the
+// OpenID Connect provider, and revokes the session if they can't. This means that users who are deactivated by the
+// provider don't stay signed in for the full length of their session.
+func RefreshOpenIdSession(session *model.Session) {
+	openIdRefreshesMutex.Lock()
+	if openIdRefreshesInProgress[session.Id] {
+		openIdRefreshesMutex.Unlock()
+		return
+	}
+	openIdRefreshesInProgress[session.Id] = true
+	openIdRefreshesMutex.Unlock()
+
+	defer func() {
+		openIdRefreshesMutex.Lock()
+		delete(openIdRefreshesInProgress, session.Id)
+		openIdRefreshesMutex.Unlock()
+	}()
+
+	provider, ok := einterfaces.GetOauthProvider(model.USER_AUTH_SERVICE_OPENID).(einterfaces.OpenIdProvider)
+	if !ok || !utils.Cfg.OpenIdSettings.Enable {
+		return
+	}
+
+	tokens, err := provider.RefreshTokens(&utils.Cfg.OpenIdSettings, session.Props[model.SESSION_PROP_OPENID_REFRESH_TOKEN])
+	if err != nil {
+		if err.StatusCode == http.StatusUnauthorized {
+			l4g.Info(utils.T("api.openid.refresh_session.revoked.info"), session.Id, session.UserId)
+
+			if err := RevokeSession(session); err != nil {
+				l4g.Error(err.Error())
+			}
+		} else {
+			l4g.Error(utils.T("api.openid.refresh_session.error"), session.Id, err.Error())
+		}
+
+		return
+	}
+
+	// A new ID token has to identify the same user as the one that was used to sign in
+	if len(tokens.Claims) > 0 {
+		authData := provider.GetAuthDataFromJson(strings.NewReader(tokens.Claims))
+
+		if user, err := GetUser(session.UserId); err != nil {
+			l4g.Error(utils.T("api.openid.refresh_session.error"), session.Id, err.Error())
+			return
+		} else if user.AuthService != model.USER_AUTH_SERVICE_OPENID || user.AuthData == nil || *user.AuthData != authData {
+			l4g.Info(utils.T("api.openid.refresh_session.revoked.info"), session.Id, session.UserId)
+
+			if err := RevokeSession(session); err != nil {
+				l4g.Error(err.Error())
+			}
+
+			return
+		}
+	}
+
+	if err := SetOpenIdRefreshToken(session, tokens.RefreshToken); err != nil {
+		l4g.Error(utils.T("api.openid.refresh_session.error"), session.Id, err.Error())
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	OUTGOING_WEBHOOK_DELIVERY_TASK_NAME         = "Retry Outgoing Webhook Deliveries"
+	OUTGOING_WEBHOOK_DELIVERY_CLEANUP_TASK_NAME = "Delete Old Outgoing Webhook Deliveries"
+	OUTGOING_WEBHOOK_DELIVERY_CHECK_INTERVAL    = 10 * time.Second
+	OUTGOING_WEBHOOK_DELIVERY_CLEANUP_INTERVAL  = time.Hour
+	OUTGOING_WEBHOOK_DELIVERY_BATCH             = 100
+
+	OUTGOING_WEBHOOK_DELIVERY_TIMEOUT       = 10 * time.Second
+	OUTGOING_WEBHOOK_DELIVERY_MAX_ATTEMPTS  = 5
+	OUTGOING_WEBHOOK_DELIVERY_RETRY_BACKOFF = 30 * time.Second
+	OUTGOING_WEBHOOK_DELIVERY_RETENTION     = 7 * 24 * time.Hour
+
+	// A server that claims a delivery has this long to record the result before another server will try it again
+	OUTGOING_WEBHOOK_DELIVERY_LEASE = OUTGOING_WEBHOOK_DELIVERY_TIMEOUT + time.Minute
+)
+
+func StartOutgoingWebhookDeliveryTasks() {
+	if task := model.GetTaskByName(OUTGOING_WEBHOOK_DELIVERY_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	if task := model.GetTaskByName(OUTGOING_WEBHOOK_DELIVERY_CLEANUP_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(OUTGOING_WEBHOOK_DELIVERY_TASK_NAME, RetryDueOutgoingWebhookDeliveries, OUTGOING_WEBHOOK_DELIVERY_CHECK_INTERVAL)
+	model.CreateRecurringTask(OUTGOING_WEBHOOK_DELIVERY_CLEANUP_TASK_NAME, DeleteOldOutgoingWebhookDeliveries, OUTGOING_WEBHOOK_DELIVERY_CLEANUP_INTERVAL)
+}
+
+// EnqueueOutgoingWebhookDelivery saves a delivery and starts sending it. If it can't be sent, it'll be retried with
+// exponential backoff by RetryDueOutgoingWebhookDeliveries.
+func EnqueueOutgoingWebhookDelivery(delivery *model.OutgoingWebhookDelivery) *model.AppError {
+	if result := <-Srv.Store.OutgoingWebhookDelivery().Save(delivery); result.Err != nil {
+		return result.Err
+	}
+
+	// Send a copy so that the caller can keep using the delivery that was saved
+	attempt := *delivery
+	go attemptOutgoingWebhookDelivery(&attempt)
+
+	return nil
+}
+
+// RetryDueOutgoingWebhookDeliveries sends every pending delivery that's due. Every server in a cluster runs this, but
+// each delivery is claimed in the database before it's sent so only one of them will send it.
+func RetryDueOutgoingWebhookDeliveries() {
+	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks && !*utils.Cfg.ServiceSettings.EnableEventSubscriptions {
+		return
+	}
+
+	for {
+		var deliveries []*model.OutgoingWebhookDelivery
+		if result := <-Srv.Store.OutgoingWebhookDelivery().GetDue(model.GetMillis(), OUTGOING_WEBHOOK_DELIVERY_BATCH); result.Err != nil {
+			l4g.Error(utils.T("app.outgoing_webhook_delivery.get_due.error"), result.Err.Error())
+			return
+		} else {
+			deliveries = result.Data.([]*model.OutgoingWebhookDelivery)
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery *model.OutgoingWebhookDelivery) {
+				defer wg.Done()
+				attemptOutgoingWebhookDelivery(delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < OUTGOING_WEBHOOK_DELIVERY_BATCH {
+			return
+		}
+	}
+}
+
+func DeleteOldOutgoingWebhookDeliveries() {
+	if result := <-Srv.Store.OutgoingWebhookDelivery().PermanentDeleteBefore(model.GetMillis() - int64(OUTGOING_WEBHOOK_DELIVERY_RETENTION/time.Millisecond)); result.Err != nil {
+		l4g.Error(utils.T("app.outgoing_webhook_delivery.delete_old.error"), result.Err.Error())
+	}
+}
+
+func attemptOutgoingWebhookDelivery(delivery *model.OutgoingWebhookDelivery) {
+	now := model.GetMillis()
+	if result := <-Srv.Store.OutgoingWebhookDelivery().Claim(delivery.Id, delivery.NextAttemptAt, now+int64(OUTGOING_WEBHOOK_DELIVERY_LEASE/time.Millisecond)); result.Err != nil {
+		l4g.Error(utils.T("app.outgoing_webhook_delivery.claim.error"), delivery.Id, result.Err.Error())
+		return
+	} else if !result.Data.(bool) {
+		// another server is already sending this delivery
+		return
+	}
+
+	var hook *model.OutgoingWebhook
+	var signature string
+	if len(delivery.Event) > 0 {
+		if result := <-Srv.Store.EventSubscription().Get(delivery.HookId); result.Err != nil {
+			// The subscription has been deleted since the event was queued
+			delivery.SetError(result.Err.Message)
+			finishOutgoingWebhookDelivery(delivery, model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED)
+			return
+		} else {
+			signature = result.Data.(*model.EventSubscription).Sign([]byte(delivery.Payload))
+		}
+	} else if result := <-Srv.Store.Webhook().GetOutgoing(delivery.HookId); result.Err != nil {
+		// The hook has been deleted since the delivery was queued
+		delivery.SetError(result.Err.Message)
+		finishOutgoingWebhookDelivery(delivery, model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED)
+		return
+	} else {
+		hook = result.Data.(*model.OutgoingWebhook)
+		signature = model.SignOutgoingWebhookPayload(hook.Token, []byte(delivery.Payload))
+	}
+
+	resp, latency, err := sendOutgoingWebhookDelivery(delivery, signature)
+
+	delivery.Attempts += 1
+	delivery.LastAttemptAt = now
+	delivery.Latency = latency
+
+	if err != nil {
+		l4g.Warn(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
+
+		delivery.StatusCode = 0
+		delivery.SetError(err.Error())
+		retryOutgoingWebhookDelivery(delivery)
+		return
+	}
+
+	defer func() {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	delivery.StatusCode = resp.StatusCode
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		delivery.SetError(resp.Status)
+		retryOutgoingWebhookDelivery(delivery)
+		return
+	}
+
+	delivery.ErrorMessage = ""
+	finishOutgoingWebhookDelivery(delivery, model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS)
+
+	// Only outgoing webhooks can reply with a post
+	if hook == nil {
+		return
+	}
+
+	respProps := model.MapFromJson(resp.Body)
+	if text, ok := respProps["text"]; ok {
+		createOutgoingWebhookResponsePost(hook, delivery, text, respProps["username"], respProps["icon_url"])
+	}
+}
+
+// sendOutgoingWebhookDelivery makes the request for a delivery and returns the response along with how long it took
+// in milliseconds.
+func sendOutgoingWebhookDelivery(delivery *model.OutgoingWebhookDelivery, signature string) (*http.Response, int64, error) {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+	}
+	client := &http.Client{Transport: tr, Timeout: OUTGOING_WEBHOOK_DELIVERY_TIMEOUT}
+
+	req, err := http.NewRequest("POST", delivery.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req.Header.Set("Content-Type", delivery.ContentType)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(model.HEADER_WEBHOOK_DELIVERY, delivery.Id)
+	req.Header.Set(model.HEADER_WEBHOOK_SIGNATURE, signature)
+	if len(delivery.Event) > 0 {
+		req.Header.Set(model.HEADER_EVENT_SUBSCRIPTION_EVENT, delivery.Event)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	latency := int64(time.Since(start) / time.Millisecond)
+
+	return resp, latency, err
+}
+
+// retryOutgoingWebhookDelivery schedules the next attempt of a delivery that failed, waiting twice as long after each
+// attempt, or gives up on it once it's been attempted too many times.
+func retryOutgoingWebhookDelivery(delivery *model.OutgoingWebhookDelivery) {
+	if delivery.Attempts >= OUTGOING_WEBHOOK_DELIVERY_MAX_ATTEMPTS {
+		finishOutgoingWebhookDelivery(delivery, model.OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED)
+		return
+	}
+
+	backoff := OUTGOING_WEBHOOK_DELIVERY_RETRY_BACKOFF * time.Duration(1<<uint(delivery.Attempts-1))
+	delivery.NextAttemptAt = delivery.LastAttemptAt + int64(backoff/time.Millisecond)
+
+	if result := <-Srv.Store.OutgoingWebhookDelivery().Update(delivery); result.Err != nil {
+		l4g.Error(utils.T("app.outgoing_webhook_delivery.update.error"), delivery.Id, result.Err.Error())
+	}
+}
+
+func finishOutgoingWebhookDelivery(delivery *model.OutgoingWebhookDelivery, status string) {
+	delivery.Status = status
+	delivery.NextAttemptAt = 0
+
+	if result := <-Srv.Store.OutgoingWebhookDelivery().Update(delivery); result.Err != nil {
+		l4g.Error(utils.T("app.outgoing_webhook_delivery.update.error"), delivery.Id, result.Err.Error())
+	}
+}
+
+func createOutgoingWebhookResponsePost(hook *model.OutgoingWebhook, delivery *model.OutgoingWebhookDelivery, text string, overrideUsername string, overrideIconUrl string) {
+	var props model.StringInterface
+	var postType string
+	if result := <-Srv.Store.Post().Get(delivery.PostId); result.Err == nil {
+		if post, ok := result.Data.(*model.PostList).Posts[delivery.PostId]; ok {
+			props = post.Props
+			postType = post.Type
+		}
+	}
+
+	if _, err := CreateWebhookPost(hook.CreatorId, hook.TeamId, delivery.ChannelId, text, overrideUsername, overrideIconUrl, props, postType); err != nil {
+		l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.create_post.error"), err)
+	}
+}
+
+func GetOutgoingWebhookDelivery(id string) (*model.OutgoingWebhookDelivery, *model.AppError) {
+	if result := <-Srv.Store.OutgoingWebhookDelivery().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.OutgoingWebhookDelivery), nil
+	}
+}
+
+func GetOutgoingWebhookDeliveries(hookId string, offset int, limit int) ([]*model.OutgoingWebhookDelivery, *model.AppError) {
+	if result := <-Srv.Store.OutgoingWebhookDelivery().GetByHook(hookId, offset, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.OutgoingWebhookDelivery), nil
+	}
+}
+
+// RedeliverOutgoingWebhook sends the request of an earlier delivery again as a new delivery.
+func RedeliverOutgoingWebhook(delivery *model.OutgoingWebhookDelivery) (*model.OutgoingWebhookDelivery, *model.AppError) {
+	if !utils.Cfg.ServiceSettings.EnableOutgoingWebhooks {
+		err := model.NewLocAppError("RedeliverOutgoingWebhook", "api.webhook.redeliver_outgoing.disabled.app_error", nil, "")
+		err.StatusCode = http.StatusNotImplemented
+		return nil, err
+	}
+
+	redelivery := delivery.Redelivery()
+	if err := EnqueueOutgoingWebhookDelivery(redelivery); err != nil {
+		return nil, err
+	}
+
+	return redelivery, nil
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	PERSONAL_ACCESS_TOKEN_AUDIT_CREATED = "personal_access_token_created"
+	PERSONAL_ACCESS_TOKEN_AUDIT_REVOKED = "personal_access_token_revoked"
+)
+
+// CreatePersonalAccessToken creates a new token for a user. The returned token is the only copy of it that isn't
+// hashed, so it needs to be given to the user before it's sanitized.
+func CreatePersonalAccessToken(userId string, description string) (*model.PersonalAccessToken, *model.AppError) {
+	if !*utils.Cfg.ServiceSettings.EnablePersonalAccessTokens {
+		err := model.NewLocAppError("CreatePersonalAccessToken", "api.personal_access_token.disabled.app_error", nil, "")
+		err.StatusCode = http.StatusNotImplemented
+		return nil, err
+	}
+
+	user, err := GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeleteAt != 0 {
+		err := model.NewLocAppError("CreatePersonalAccessToken", "api.personal_access_token.create.inactive.app_error", nil, "user_id="+userId)
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	token := &model.PersonalAccessToken{
+		UserId:      userId,
+		Description: description,
+	}
+
+	if result := <-Srv.Store.PersonalAccessToken().Save(token); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.PersonalAccessToken), nil
+	}
+}
+
+func GetPersonalAccessToken(tokenId string) (*model.PersonalAccessToken, *model.AppError) {
+	if result := <-Srv.Store.PersonalAccessToken().Get(tokenId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		token := result.Data.(*model.PersonalAccessToken)
+		token.Sanitize()
+		return token, nil
+	}
+}
+
+func GetPersonalAccessTokensForUser(userId string) ([]*model.PersonalAccessToken, *model.AppError) {
+	if result := <-Srv.Store.PersonalAccessToken().GetByUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		tokens := result.Data.([]*model.PersonalAccessToken)
+		for _, token := range tokens {
+			token.Sanitize()
+		}
+		return tokens, nil
+	}
+}
+
+// RevokePersonalAccessToken deletes a token and removes any sessions created from it from the caches of every server
+// so that it stops working immediately.
+func RevokePersonalAccessToken(token *model.PersonalAccessToken) *model.AppError {
+	if result := <-Srv.Store.PersonalAccessToken().Delete(token.Id); result.Err != nil {
+		return result.Err
+	}
+
+	RemoveAllSessionsForUserId(token.UserId)
+
+	return nil
+}
+
+// AuditPersonalAccessToken records that a token was created or revoked by something other than an API request, such
+// as the command line tool. Requests to the API are audited by their handlers.
+func AuditPersonalAccessToken(token *model.PersonalAccessToken, action string) {
+	audit := &model.Audit{
+		UserId:    token.UserId,
+		Action:    action,
+		ExtraInfo: "token_id=" + token.Id,
+	}
+
+	if result := <-Srv.Store.Audit().Save(audit); result.Err != nil {
+		l4g.Error(utils.T("api.personal_access_token.audit.error"), token.Id, result.Err.Error())
+	}
+}
+
+// getSessionForPersonalAccessToken creates a session for a request that's authenticated with a personal access
+// token. The session is only cached and never saved since it lasts for as long as the token does.
+func getSessionForPersonalAccessToken(tokenString string) (*model.Session, *model.AppError) {
+	if !*utils.Cfg.ServiceSettings.EnablePersonalAccessTokens {
+		return nil, model.NewLocAppError("getSessionForPersonalAccessToken", "api.context.invalid_token.error", map[string]interface{}{"Token": tokenString, "Error": ""}, "personal access tokens are disabled")
+	}
+
+	var token *model.PersonalAccessToken
+	if result := <-Srv.Store.PersonalAccessToken().GetByTokenHash(model.HashPersonalAccessToken(tokenString)); result.Err != nil {
+		return nil, model.NewLocAppError("getSessionForPersonalAccessToken", "api.context.invalid_token.error", map[string]interface{}{"Token": tokenString, "Error": result.Err.DetailedError}, "")
+	} else {
+		token = result.Data.(*model.PersonalAccessToken)
+	}
+
+	user, err := GetUser(token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeleteAt != 0 {
+		return nil, model.NewLocAppError("getSessionForPersonalAccessToken", "api.context.invalid_token.error", map[string]interface{}{"Token": tokenString, "Error": ""}, "user_id="+user.Id+" is inactive")
+	}
+
+	session := &model.Session{
+		Id:       token.Id,
+		Token:    tokenString,
+		CreateAt: token.CreateAt,
+		UserId:   user.Id,
+		Roles:    user.GetRawRoles(),
+		Props: model.StringMap{
+			model.SESSION_PROP_TYPE:                     model.SESSION_TYPE_PERSONAL_ACCESS_TOKEN,
+			model.SESSION_PROP_PERSONAL_ACCESS_TOKEN_ID: token.Id,
+		},
+	}
+
+	if result := <-Srv.Store.Team().GetTeamsForUser(user.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		members := result.Data.([]*model.TeamMember)
+		session.TeamMembers = make([]*model.TeamMember, 0, len(members))
+		for _, member := range members {
+			if member.DeleteAt == 0 {
+				session.TeamMembers = append(session.TeamMembers, member)
+			}
+		}
+	}
+
+	AddSessionToCache(session)
+
+	return session, nil
+}
@@ -0,0 +1,576 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/plugin/rpcplugin"
+	"github.com/mattermost/platform/utils"
+)
+
+// pluginBundle is a plugin that's been installed in the plugin directory.
+type pluginBundle struct {
+	Manifest *model.Manifest
+	Path     string
+}
+
+type activePlugin struct {
+	Manifest   *model.Manifest
+	Supervisor *rpcplugin.Supervisor
+}
+
+var (
+	// pluginsSyncLock is held while plugins are being installed, removed, started or stopped, which can call back into
+	// the app, so only pluginsLock is held while hooks are running
+	pluginsSyncLock sync.Mutex
+
+	pluginsLock   sync.RWMutex
+	activePlugins = map[string]*activePlugin{}
+)
+
+// SyncPlugins starts the plugins that are enabled in the config and stops the rest. Plugins that keep running are
+// told that the config has changed.
+func SyncPlugins() {
+	pluginsSyncLock.Lock()
+	defer pluginsSyncLock.Unlock()
+
+	bundles := map[string]*pluginBundle{}
+	if *utils.Cfg.PluginSettings.Enable {
+		if err := os.MkdirAll(*utils.Cfg.PluginSettings.Directory, 0755); err != nil {
+			l4g.Error(utils.T("app.plugin.directory.error"), err.Error())
+		}
+
+		if list, err := getPluginBundles(); err != nil {
+			l4g.Error(utils.T("app.plugin.directory.error"), err.Error())
+		} else {
+			for _, bundle := range list {
+				bundles[bundle.Manifest.Id] = bundle
+			}
+		}
+	}
+
+	var running []*activePlugin
+	for _, plugin := range getActivePlugins() {
+		if bundle, ok := bundles[plugin.Manifest.Id]; !ok || !isPluginEnabled(bundle.Manifest.Id) {
+			deactivatePlugin(plugin.Manifest.Id)
+		} else {
+			running = append(running, plugin)
+		}
+	}
+
+	for _, plugin := range running {
+		if plugin.Supervisor != nil {
+			if err := plugin.Supervisor.Hooks().OnConfigurationChange(); err != nil {
+				l4g.Error(utils.T("app.plugin.hook.error"), plugin.Manifest.Id, "OnConfigurationChange", err.Error())
+			}
+		}
+	}
+
+	for id, bundle := range bundles {
+		if isPluginEnabled(id) && getActivePlugin(id) == nil {
+			if err := activatePlugin(bundle); err != nil {
+				l4g.Error(err.Error())
+			}
+		}
+	}
+}
+
+// StopPlugins stops every running plugin.
+func StopPlugins() {
+	pluginsSyncLock.Lock()
+	defer pluginsSyncLock.Unlock()
+
+	for _, plugin := range getActivePlugins() {
+		deactivatePlugin(plugin.Manifest.Id)
+	}
+}
+
+func isPluginEnabled(id string) bool {
+	state, ok := utils.Cfg.PluginSettings.PluginStates[id]
+	return ok && state != nil && state.Enable
+}
+
+func getActivePlugin(id string) *activePlugin {
+	pluginsLock.RLock()
+	defer pluginsLock.RUnlock()
+
+	return activePlugins[id]
+}
+
+// getActivePlugins returns the running plugins ordered by id so that their hooks are always run in the same order.
+func getActivePlugins() []*activePlugin {
+	pluginsLock.RLock()
+	defer pluginsLock.RUnlock()
+
+	plugins := make([]*activePlugin, 0, len(activePlugins))
+	for _, plugin := range activePlugins {
+		plugins = append(plugins, plugin)
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Manifest.Id < plugins[j].Manifest.Id
+	})
+
+	return plugins
+}
+
+func activatePlugin(bundle *pluginBundle) *model.AppError {
+	plugin := &activePlugin{Manifest: bundle.Manifest}
+
+	if backend := bundle.Manifest.Backend; backend != nil {
+		executable := filepath.Join(bundle.Path, filepath.FromSlash(backend.Executable))
+
+		supervisor, err := rpcplugin.StartSupervisor(executable, bundle.Path, &PluginAPI{id: bundle.Manifest.Id}, &pluginLogWriter{id: bundle.Manifest.Id})
+		if err != nil {
+			return model.NewLocAppError("activatePlugin", "app.plugin.activate.app_error", nil, "id="+bundle.Manifest.Id+", "+err.Error())
+		}
+
+		if err := supervisor.Hooks().OnActivate(); err != nil {
+			supervisor.Stop()
+			return model.NewLocAppError("activatePlugin", "app.plugin.activate.app_error", nil, "id="+bundle.Manifest.Id+", "+err.Error())
+		}
+
+		plugin.Supervisor = supervisor
+		go watchPlugin(plugin)
+	}
+
+	pluginsLock.Lock()
+	activePlugins[bundle.Manifest.Id] = plugin
+	pluginsLock.Unlock()
+
+	l4g.Info(utils.T("app.plugin.activated.info"), bundle.Manifest.Id)
+
+	return nil
+}
+
+// watchPlugin forgets about a plugin if its process exits while it's meant to be running. It stays stopped until it's
+// enabled again or the config changes.
+func watchPlugin(plugin *activePlugin) {
+	<-plugin.Supervisor.Exited()
+
+	pluginsLock.Lock()
+	defer pluginsLock.Unlock()
+
+	if activePlugins[plugin.Manifest.Id] == plugin {
+		delete(activePlugins, plugin.Manifest.Id)
+		l4g.Error(utils.T("app.plugin.exited.error"), plugin.Manifest.Id)
+	}
+}
+
+func deactivatePlugin(id string) {
+	pluginsLock.Lock()
+	plugin := activePlugins[id]
+	delete(activePlugins, id)
+	pluginsLock.Unlock()
+
+	if plugin == nil {
+		return
+	}
+
+	if plugin.Supervisor != nil {
+		if err := plugin.Supervisor.Hooks().OnDeactivate(); err != nil {
+			l4g.Error(utils.T("app.plugin.hook.error"), id, "OnDeactivate", err.Error())
+		}
+
+		plugin.Supervisor.Stop()
+	}
+
+	l4g.Info(utils.T("app.plugin.deactivated.info"), id)
+}
+
+func getPluginBundles() ([]*pluginBundle, *model.AppError) {
+	dir, err := filepath.Abs(*utils.Cfg.PluginSettings.Directory)
+	if err != nil {
+		return nil, model.NewLocAppError("getPluginBundles", "app.plugin.directory.app_error", nil, err.Error())
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*pluginBundle{}, nil
+		}
+		return nil, model.NewLocAppError("getPluginBundles", "app.plugin.directory.app_error", nil, err.Error())
+	}
+
+	bundles := []*pluginBundle{}
+	for _, entry := range entries {
+		// Plugins that are still being installed are extracted to hidden directories
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if manifest, err := readPluginManifest(path); err != nil {
+			l4g.Warn(utils.T("app.plugin.manifest.warn"), path, err.Error())
+		} else if manifest.Id != entry.Name() {
+			l4g.Warn(utils.T("app.plugin.manifest.warn"), path, "id="+manifest.Id)
+		} else {
+			bundles = append(bundles, &pluginBundle{Manifest: manifest, Path: path})
+		}
+	}
+
+	return bundles, nil
+}
+
+func getPluginBundle(id string) (*pluginBundle, *model.AppError) {
+	bundles, err := getPluginBundles()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bundle := range bundles {
+		if bundle.Manifest.Id == id {
+			return bundle, nil
+		}
+	}
+
+	appErr := model.NewLocAppError("getPluginBundle", "app.plugin.not_found.app_error", nil, "id="+id)
+	appErr.StatusCode = http.StatusNotFound
+	return nil, appErr
+}
+
+func readPluginManifest(dir string) (*model.Manifest, *model.AppError) {
+	file, err := os.Open(filepath.Join(dir, model.PLUGIN_MANIFEST_FILENAME))
+	if err != nil {
+		return nil, model.NewLocAppError("readPluginManifest", "app.plugin.manifest.app_error", nil, err.Error())
+	}
+	defer file.Close()
+
+	manifest := model.ManifestFromJson(file)
+	if manifest == nil {
+		return nil, model.NewLocAppError("readPluginManifest", "app.plugin.manifest.app_error", nil, "invalid json")
+	}
+
+	if err := manifest.IsValid(); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// GetPlugins returns every installed plugin and whether it's running.
+func GetPlugins() ([]*model.PluginInfo, *model.AppError) {
+	bundles, err := getPluginBundles()
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := []*model.PluginInfo{}
+	for _, bundle := range bundles {
+		plugins = append(plugins, &model.PluginInfo{
+			Manifest: *bundle.Manifest,
+			Enabled:  isPluginEnabled(bundle.Manifest.Id),
+			Active:   getActivePlugin(bundle.Manifest.Id) != nil,
+		})
+	}
+
+	return plugins, nil
+}
+
+// InstallPlugin unpacks a gzipped tarball into the plugin directory. The manifest has to be at the root of the
+// tarball or inside a single top level directory. Plugins are installed disabled.
+func InstallPlugin(file io.Reader) (*model.Manifest, *model.AppError) {
+	pluginsSyncLock.Lock()
+	defer pluginsSyncLock.Unlock()
+
+	dir, err := filepath.Abs(*utils.Cfg.PluginSettings.Directory)
+	if err != nil {
+		return nil, model.NewLocAppError("InstallPlugin", "app.plugin.directory.app_error", nil, err.Error())
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, model.NewLocAppError("InstallPlugin", "app.plugin.directory.app_error", nil, err.Error())
+	}
+
+	tmpDir, err := ioutil.TempDir(dir, ".install-")
+	if err != nil {
+		return nil, model.NewLocAppError("InstallPlugin", "app.plugin.directory.app_error", nil, err.Error())
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := extractTarGz(file, tmpDir); err != nil {
+		return nil, model.NewLocAppError("InstallPlugin", "app.plugin.install.extract.app_error", nil, err.Error())
+	}
+
+	bundleDir := tmpDir
+	if _, err := os.Stat(filepath.Join(bundleDir, model.PLUGIN_MANIFEST_FILENAME)); os.IsNotExist(err) {
+		if entries, err := ioutil.ReadDir(tmpDir); err == nil && len(entries) == 1 && entries[0].IsDir() {
+			bundleDir = filepath.Join(tmpDir, entries[0].Name())
+		}
+	}
+
+	manifest, appErr := readPluginManifest(bundleDir)
+	if appErr != nil {
+		appErr.StatusCode = http.StatusBadRequest
+		return nil, appErr
+	}
+
+	dest := filepath.Join(dir, manifest.Id)
+	if _, err := os.Stat(dest); err == nil {
+		appErr := model.NewLocAppError("InstallPlugin", "app.plugin.install.exists.app_error", nil, "id="+manifest.Id)
+		appErr.StatusCode = http.StatusBadRequest
+		return nil, appErr
+	}
+
+	if err := os.Rename(bundleDir, dest); err != nil {
+		return nil, model.NewLocAppError("InstallPlugin", "app.plugin.install.app_error", nil, err.Error())
+	}
+
+	return manifest, nil
+}
+
+// extractTarGz unpacks the directories and regular files of a gzipped tarball. Anything that would end up outside of
+// dest is rejected.
+func extractTarGz(file io.Reader, dest string) error {
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return model.NewLocAppError("extractTarGz", "app.plugin.install.path.app_error", nil, "name="+header.Name)
+		}
+		path := filepath.Join(dest, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+
+			out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode)&0755)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(out, tarReader)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RemovePlugin stops a plugin and deletes it from the plugin directory.
+func RemovePlugin(id string) *model.AppError {
+	pluginsSyncLock.Lock()
+	defer pluginsSyncLock.Unlock()
+
+	bundle, err := getPluginBundle(id)
+	if err != nil {
+		return err
+	}
+
+	deactivatePlugin(id)
+
+	if err := os.RemoveAll(bundle.Path); err != nil {
+		return model.NewLocAppError("RemovePlugin", "app.plugin.remove.app_error", nil, err.Error())
+	}
+
+	if _, ok := utils.Cfg.PluginSettings.PluginStates[id]; ok {
+		return savePluginState(id, nil)
+	}
+
+	return nil
+}
+
+// SetPluginEnabled marks a plugin as enabled or disabled in the config without starting or stopping it, so that a
+// running server picks up the change the next time its config is reloaded.
+func SetPluginEnabled(id string, enabled bool) *model.AppError {
+	pluginsSyncLock.Lock()
+	defer pluginsSyncLock.Unlock()
+
+	_, err := setPluginEnabled(id, enabled)
+	return err
+}
+
+// EnablePlugin marks a plugin as enabled in the config and starts it if plugins are turned on.
+func EnablePlugin(id string) *model.AppError {
+	pluginsSyncLock.Lock()
+	defer pluginsSyncLock.Unlock()
+
+	bundle, err := setPluginEnabled(id, true)
+	if err != nil {
+		return err
+	}
+
+	if *utils.Cfg.PluginSettings.Enable && getActivePlugin(id) == nil {
+		return activatePlugin(bundle)
+	}
+
+	return nil
+}
+
+// DisablePlugin marks a plugin as disabled in the config and stops it.
+func DisablePlugin(id string) *model.AppError {
+	pluginsSyncLock.Lock()
+	defer pluginsSyncLock.Unlock()
+
+	if _, err := setPluginEnabled(id, false); err != nil {
+		return err
+	}
+
+	deactivatePlugin(id)
+
+	return nil
+}
+
+func setPluginEnabled(id string, enabled bool) (*pluginBundle, *model.AppError) {
+	bundle, err := getPluginBundle(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := savePluginState(id, &model.PluginState{Enable: enabled}); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// savePluginState changes the state of one plugin in the config file, or removes it if state is nil, and tells the
+// other servers in the cluster to do the same.
+func savePluginState(id string, state *model.PluginState) *model.AppError {
+	if err := savePluginStateSkipClusterSend(id, state); err != nil {
+		return err
+	}
+
+	if einterfaces.GetClusterInterface() != nil {
+		einterfaces.GetClusterInterface().PluginStateChanged(id, state)
+	}
+
+	return nil
+}
+
+// SavePluginStateSkipClusterSend applies a change to a plugin's state that was made on another server in the cluster
+// and starts or stops the plugin to match.
+func SavePluginStateSkipClusterSend(id string, state *model.PluginState) {
+	if len(id) == 0 {
+		return
+	}
+
+	pluginsSyncLock.Lock()
+	err := savePluginStateSkipClusterSend(id, state)
+	pluginsSyncLock.Unlock()
+
+	if err != nil {
+		l4g.Error(utils.T("app.plugin.save_state.error"), id, err.Error())
+		return
+	}
+
+	SyncPlugins()
+}
+
+func savePluginStateSkipClusterSend(id string, state *model.PluginState) *model.AppError {
+	cfg := *utils.Cfg
+
+	states := map[string]*model.PluginState{}
+	for key, value := range cfg.PluginSettings.PluginStates {
+		states[key] = value
+	}
+
+	if state == nil {
+		delete(states, id)
+	} else {
+		states[id] = state
+	}
+	cfg.PluginSettings.PluginStates = states
+
+	if err := utils.SaveConfig(utils.CfgFileName, &cfg); err != nil {
+		return err
+	}
+	utils.LoadConfig(utils.CfgFileName)
+
+	return nil
+}
+
+// RunMessageWillBePostedHooks passes a post through the MessageWillBePosted hook of each plugin in turn. An error is
+// returned if any of them rejects the post.
+func RunMessageWillBePostedHooks(post *model.Post) (*model.Post, *model.AppError) {
+	for _, plugin := range getActivePlugins() {
+		if plugin.Supervisor == nil {
+			continue
+		}
+
+		newPost, rejectionReason, err := plugin.Supervisor.Hooks().MessageWillBePosted(post)
+		if err != nil {
+			l4g.Error(utils.T("app.plugin.hook.error"), plugin.Manifest.Id, "MessageWillBePosted", err.Error())
+			continue
+		}
+
+		if newPost == nil {
+			appErr := model.NewLocAppError("RunMessageWillBePostedHooks", "app.plugin.message_will_be_posted.rejected.app_error", map[string]interface{}{"Reason": rejectionReason}, "plugin_id="+plugin.Manifest.Id)
+			appErr.StatusCode = http.StatusBadRequest
+			return nil, appErr
+		}
+
+		post = newPost
+	}
+
+	return post, nil
+}
+
+func RunMessageHasBeenPostedHooks(post *model.Post) {
+	for _, plugin := range getActivePlugins() {
+		if plugin.Supervisor == nil {
+			continue
+		}
+
+		if err := plugin.Supervisor.Hooks().MessageHasBeenPosted(post); err != nil {
+			l4g.Error(utils.T("app.plugin.hook.error"), plugin.Manifest.Id, "MessageHasBeenPosted", err.Error())
+		}
+	}
+}
+
+// ServePluginRequest passes a request to the ServeHTTP hook of a running plugin.
+func ServePluginRequest(id string, w http.ResponseWriter, r *http.Request) {
+	plugin := getActivePlugin(id)
+	if plugin == nil || plugin.Supervisor == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	plugin.Supervisor.Hooks().ServeHTTP(w, r)
+}
+
+// pluginLogWriter writes what a plugin logs to stderr to the server's log.
+type pluginLogWriter struct {
+	id string
+}
+
+func (w *pluginLogWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		l4g.Info(utils.T("app.plugin.log.info"), w.id, line)
+	}
+	return len(p), nil
+}
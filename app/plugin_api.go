@@ -0,0 +1,97 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/json"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/plugin"
+	"github.com/mattermost/platform/utils"
+)
+
+// PluginAPI is the API that's given to a plugin. Errors are translated before they're returned because plugins can't
+// translate them themselves.
+type PluginAPI struct {
+	id string
+}
+
+var _ plugin.API = (*PluginAPI)(nil)
+
+func (api *PluginAPI) LoadPluginConfiguration(dest interface{}) error {
+	if b, err := json.Marshal(utils.Cfg.PluginSettings.Plugins[api.id]); err != nil {
+		return err
+	} else {
+		return json.Unmarshal(b, dest)
+	}
+}
+
+func (api *PluginAPI) GetUser(userId string) (*model.User, *model.AppError) {
+	user, err := GetUser(userId)
+	return sanitizePluginUser(user), translatePluginError(err)
+}
+
+func (api *PluginAPI) GetUserByUsername(username string) (*model.User, *model.AppError) {
+	user, err := GetUserByUsername(username)
+	return sanitizePluginUser(user), translatePluginError(err)
+}
+
+func (api *PluginAPI) GetTeam(teamId string) (*model.Team, *model.AppError) {
+	team, err := GetTeam(teamId)
+	return team, translatePluginError(err)
+}
+
+func (api *PluginAPI) GetTeamByName(name string) (*model.Team, *model.AppError) {
+	team, err := GetTeamByName(name)
+	return team, translatePluginError(err)
+}
+
+func (api *PluginAPI) GetChannel(channelId string) (*model.Channel, *model.AppError) {
+	channel, err := GetChannel(channelId)
+	return channel, translatePluginError(err)
+}
+
+func (api *PluginAPI) GetChannelByName(name, teamId string) (*model.Channel, *model.AppError) {
+	channel, err := GetChannelByName(name, teamId)
+	return channel, translatePluginError(err)
+}
+
+func (api *PluginAPI) CreatePost(post *model.Post) (*model.Post, *model.AppError) {
+	channel, err := GetChannel(post.ChannelId)
+	if err != nil {
+		return nil, translatePluginError(err)
+	}
+
+	// Direct and group messages don't belong to a team, so use one of the poster's teams for notifications
+	teamId := channel.TeamId
+	if len(teamId) == 0 {
+		if teams, err := GetTeamsForUser(post.UserId); err != nil {
+			return nil, translatePluginError(err)
+		} else if len(teams) > 0 {
+			teamId = teams[0].Id
+		}
+	}
+
+	rpost, err := CreatePost(post, teamId, true)
+	return rpost, translatePluginError(err)
+}
+
+func sanitizePluginUser(user *model.User) *model.User {
+	if user == nil {
+		return nil
+	}
+
+	sanitized := &model.User{}
+	*sanitized = *user
+	sanitized.Sanitize(map[string]bool{})
+
+	return sanitized
+}
+
+func translatePluginError(err *model.AppError) *model.AppError {
+	if err != nil {
+		err.Translate(utils.T)
+	}
+	return err
+}
@@ -4,6 +4,7 @@
 package app
 
 import (
+	"fmt"
 	"regexp"
 
 	l4g "github.com/alecthomas/log4go"
@@ -15,10 +16,14 @@ import (
 
 func CreatePost(post *model.Post, teamId string, triggerWebhooks bool) (*model.Post, *model.AppError) {
 	var pchan store.StoreChannel
+	var rootPost *model.Post
 	if len(post.RootId) > 0 {
 		pchan = Srv.Store.Post().Get(post.RootId)
 	}
 
+	// Whether a user is a bot never changes, so their cached profile can be used
+	uchan := Srv.Store.User().GetProfileByIds([]string{post.UserId}, true)
+
 	// Verify the parent/child relationships are correct
 	if pchan != nil {
 		if presult := <-pchan; presult.Err != nil {
@@ -33,6 +38,8 @@ func CreatePost(post *model.Post, teamId string, triggerWebhooks bool) (*model.P
 				post.ParentId = post.RootId
 			}
 
+			rootPost = list.Posts[post.RootId]
+
 			if post.RootId != post.ParentId {
 				parent := list.Posts[post.ParentId]
 				if parent == nil {
@@ -42,7 +49,38 @@ func CreatePost(post *model.Post, teamId string, triggerWebhooks bool) (*model.P
 		}
 	}
 
+	// Plugins can change a post or reject it before it's saved
+	if newPost, err := RunMessageWillBePostedHooks(post); err != nil {
+		return nil, err
+	} else {
+		post = newPost
+	}
+
+	// Posts by bots are marked so that clients can tell them apart from posts by people, and the mark can't be added
+	// to anyone else's posts
+	isBot := false
+	if result := <-uchan; result.Err == nil {
+		if user, ok := result.Data.(map[string]*model.User)[post.UserId]; ok {
+			isBot = user.IsBot
+		}
+	}
+
+	if isBot {
+		post.AddProp(model.POST_PROPS_FROM_BOT, "true")
+	} else {
+		delete(post.Props, model.POST_PROPS_FROM_BOT)
+	}
+
+	// Actions make the server send requests to their integrations, so people can't add them to their own posts
+	if !isBot && post.Props["from_webhook"] != "true" {
+		post.StripActions()
+	}
+
+	// Posts can only be pinned after they've been created by someone allowed to pin them
+	post.IsPinned = false
+
 	post.Hashtags, _ = model.ParseHashtags(post.Message)
+	post.GenerateActionIds()
 
 	var rpost *model.Post
 	if result := <-Srv.Store.Post().Save(post); result.Err != nil {
@@ -73,10 +111,22 @@ func CreatePost(post *model.Post, teamId string, triggerWebhooks bool) (*model.P
 	InvalidateCacheForChannel(rpost.ChannelId)
 	InvalidateCacheForChannelPosts(rpost.ChannelId)
 
+	if !rpost.IsSystemMessage() {
+		IndexPost(rpost)
+
+		go deleteDraftForPost(rpost)
+
+		if rootPost != nil {
+			UpdateThreadForReply(teamId, rpost, rootPost)
+		}
+	}
+
 	if err := handlePostEvents(rpost, teamId, triggerWebhooks); err != nil {
 		return nil, err
 	}
 
+	go RunMessageHasBeenPostedHooks(rpost)
+
 	return rpost, nil
 }
 
@@ -173,6 +223,63 @@ func parseSlackLinksToMarkdown(text string) string {
 	return linkWithTextRegex.ReplaceAllString(text, "[${2}](${1})")
 }
 
+// SetPostPinned pins or unpins a post and lets the channel know that it changed. Pinning a post is also announced in
+// the channel with a system message.
+func SetPostPinned(post *model.Post, isPinned bool, userId string, teamId string) (*model.Post, *model.AppError) {
+	if result := <-Srv.Store.Post().SetPinned(post.Id, isPinned); result.Err != nil {
+		return nil, result.Err
+	}
+
+	InvalidateCacheForChannelPosts(post.ChannelId)
+
+	var rpost *model.Post
+	if result := <-Srv.Store.Post().Get(post.Id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		rpost = result.Data.(*model.PostList).Posts[post.Id]
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_EDITED, "", rpost.ChannelId, "", nil)
+	message.Add("post", rpost.ToJson())
+
+	go Publish(message)
+
+	if isPinned && !post.IsPinned {
+		go func() {
+			if err := postPinnedMessage(userId, rpost, teamId); err != nil {
+				l4g.Error(err.Error())
+			}
+		}()
+	}
+
+	return rpost, nil
+}
+
+func postPinnedMessage(userId string, pinnedPost *model.Post, teamId string) *model.AppError {
+	var user *model.User
+	if result := <-Srv.Store.User().Get(userId); result.Err != nil {
+		return model.NewLocAppError("postPinnedMessage", "api.post.post_pinned_message.retrieve_user.error", nil, result.Err.Error())
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	post := &model.Post{
+		ChannelId: pinnedPost.ChannelId,
+		Message:   fmt.Sprintf(utils.T("api.post.post_pinned_message.pinned"), user.Username),
+		Type:      model.POST_PINNED,
+		UserId:    userId,
+		Props: model.StringInterface{
+			"pinned_post_id": pinnedPost.Id,
+		},
+	}
+
+	if _, err := CreatePost(post, teamId, false); err != nil {
+		return model.NewLocAppError("postPinnedMessage", "api.post.post_pinned_message.post.error", nil, err.Error())
+	}
+
+	return nil
+}
+
 func SendEphemeralPost(teamId, userId string, post *model.Post) *model.Post {
 	post.Type = model.POST_EPHEMERAL
 
@@ -0,0 +1,264 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	POST_ACTION_TIMEOUT = 10 * time.Second
+)
+
+var errPostActionAddressForbidden = errors.New("address is on this machine or the local network")
+
+// reservedIPRanges are the private, loopback and link-local networks that the server won't connect to on behalf of
+// users unless they're allowed by ServiceSettings.AllowedUntrustedInternalConnections.
+var reservedIPRanges []*net.IPNet
+
+func init() {
+	for _, cidr := range []string{
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"127.0.0.0/8",
+		"169.254.0.0/16",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"::/128",
+		"::1/128",
+		"fc00::/7",
+		"fe80::/10",
+	} {
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		reservedIPRanges = append(reservedIPRanges, ipNet)
+	}
+}
+
+// DoPostAction sends a request to the integration behind one of a post's actions on behalf of a user and applies the
+// integration's response. Integrations can check that the request came from this server using its signature.
+func DoPostAction(post *model.Post, actionId string, teamId string, userId string, selectedOption string) *model.AppError {
+	action := post.GetAction(actionId)
+	if action == nil || action.Integration == nil || len(action.Integration.URL) == 0 {
+		err := model.NewLocAppError("DoPostAction", "api.post.do_action.action_id.app_error", nil, "action_id="+actionId)
+		err.StatusCode = http.StatusNotFound
+		return err
+	}
+
+	request := &model.PostActionIntegrationRequest{
+		UserId:    userId,
+		TeamId:    teamId,
+		ChannelId: post.ChannelId,
+		PostId:    post.Id,
+		Type:      action.Type,
+		Context:   action.Integration.Context,
+	}
+
+	if action.Type == model.POST_ACTION_TYPE_SELECT {
+		if request.Context == nil {
+			request.Context = model.StringInterface{}
+		}
+		request.Context[model.POST_ACTION_CONTEXT_SELECTED_OPTION] = selectedOption
+	}
+
+	response, err := sendPostActionRequest(action.Integration.URL, request)
+	if err != nil {
+		return err
+	}
+
+	if response.Update != nil {
+		if err := applyPostActionUpdate(post, response.Update); err != nil {
+			return err
+		}
+	}
+
+	if len(response.EphemeralText) > 0 {
+		ephemeralPost := &model.Post{
+			ChannelId: post.ChannelId,
+			RootId:    post.RootId,
+			Message:   parseSlackLinksToMarkdown(response.EphemeralText),
+			UserId:    post.UserId,
+		}
+
+		SendEphemeralPost(teamId, userId, ephemeralPost)
+	}
+
+	return nil
+}
+
+// StripUntrustedPostActions removes the actions from a post that was sent through a user's session. Clients can mark
+// their posts as coming from a webhook, so only posts by bot accounts are trusted to have actions there.
+func StripUntrustedPostActions(post *model.Post) {
+	if result := <-Srv.Store.User().GetProfileByIds([]string{post.UserId}, true); result.Err == nil {
+		if user, ok := result.Data.(map[string]*model.User)[post.UserId]; ok && user.IsBot {
+			return
+		}
+	}
+
+	post.StripActions()
+}
+
+func sendPostActionRequest(actionUrl string, request *model.PostActionIntegrationRequest) (*model.PostActionIntegrationResponse, *model.AppError) {
+	body := request.ToJson()
+
+	req, err := http.NewRequest("POST", actionUrl, strings.NewReader(body))
+	if err != nil {
+		return nil, model.NewLocAppError("DoPostAction", "api.post.do_action.request.app_error", nil, err.Error())
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(model.HEADER_WEBHOOK_SIGNATURE, model.SignPostActionRequest(*utils.Cfg.ServiceSettings.PostActionSigningSecret, []byte(body)))
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+		DialContext:     dialPostActionIntegration,
+	}
+	client := &http.Client{Transport: tr, Timeout: POST_ACTION_TIMEOUT}
+
+	resp, err := client.Do(req)
+	if urlErr, ok := err.(*url.Error); ok && urlErr.Err == errPostActionAddressForbidden {
+		appErr := model.NewLocAppError("DoPostAction", "api.post.do_action.address_forbidden.app_error", nil, err.Error())
+		appErr.StatusCode = http.StatusForbidden
+		return nil, appErr
+	} else if err != nil {
+		return nil, model.NewLocAppError("DoPostAction", "api.post.do_action.request.app_error", nil, err.Error())
+	}
+
+	defer func() {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		appErr := model.NewLocAppError("DoPostAction", "api.post.do_action.request.app_error", nil, "status="+resp.Status)
+		appErr.StatusCode = http.StatusBadGateway
+		return nil, appErr
+	}
+
+	// Integrations don't have to reply with anything
+	response := model.PostActionIntegrationResponseFromJson(resp.Body)
+	if response == nil {
+		response = &model.PostActionIntegrationResponse{}
+	}
+
+	return response, nil
+}
+
+// dialPostActionIntegration connects to an integration for a post action. The address is resolved and checked here
+// instead of before the request is made so that redirects and DNS changes can't be used to reach internal services.
+func dialPostActionIntegration(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: POST_ACTION_TIMEOUT}
+
+	err = errPostActionAddressForbidden
+	for _, ip := range ips {
+		if isReservedIP(ip.IP) && !isAllowedInternalConnection(host, ip.IP) {
+			continue
+		}
+
+		var conn net.Conn
+		if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port)); err == nil {
+			return conn, nil
+		}
+	}
+
+	return nil, err
+}
+
+func isReservedIP(ip net.IP) bool {
+	for _, ipNet := range reservedIPRanges {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return ip.IsLoopback() || ip.IsLinkLocalMulticast()
+}
+
+// isAllowedInternalConnection returns true if the host or the address it resolved to is one of the hostnames, IP
+// addresses or CIDR ranges that the system admin has allowed the server to connect to on behalf of users.
+func isAllowedInternalConnection(host string, ip net.IP) bool {
+	for _, allowed := range strings.Fields(*utils.Cfg.ServiceSettings.AllowedUntrustedInternalConnections) {
+		if strings.EqualFold(allowed, host) {
+			return true
+		} else if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		} else if _, ipNet, err := net.ParseCIDR(allowed); err == nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyPostActionUpdate changes the message and props of a post to the ones in an integration's response. The author
+// of the post and anything that marks it as being from an integration can't be changed.
+func applyPostActionUpdate(oldPost *model.Post, update *model.Post) *model.AppError {
+	newPost := &model.Post{}
+	*newPost = *oldPost
+
+	newPost.Message = parseSlackLinksToMarkdown(update.Message)
+	newPost.Hashtags, _ = model.ParseHashtags(newPost.Message)
+	newPost.EditAt = model.GetMillis()
+
+	newPost.Props = model.StringInterface{}
+	for key, value := range oldPost.Props {
+		if key == "from_webhook" || key == "override_username" || key == "override_icon_url" || key == model.POST_PROPS_FROM_BOT {
+			newPost.Props[key] = value
+		}
+	}
+
+	if update.Props != nil {
+		for key, value := range update.Props {
+			if key == "attachments" {
+				parseSlackAttachment(newPost, value)
+			} else if _, ok := newPost.Props[key]; !ok {
+				newPost.Props[key] = value
+			}
+		}
+	}
+
+	if _, ok := newPost.Props["attachments"]; !ok && oldPost.Type == model.POST_SLACK_ATTACHMENT {
+		newPost.Type = ""
+	}
+
+	newPost.GenerateActionIds()
+
+	if result := <-Srv.Store.Post().Update(newPost, oldPost); result.Err != nil {
+		return result.Err
+	} else {
+		rpost := result.Data.(*model.Post)
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_EDITED, "", rpost.ChannelId, "", nil)
+		message.Add("post", rpost.ToJson())
+
+		go Publish(message)
+
+		InvalidateCacheForChannelPosts(rpost.ChannelId)
+		IndexPost(rpost)
+	}
+
+	return nil
+}
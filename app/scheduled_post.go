@@ -0,0 +1,249 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	SCHEDULED_POST_TASK_NAME      = "Publish Scheduled Posts"
+	SCHEDULED_POST_CHECK_INTERVAL = 30 * time.Second
+	SCHEDULED_POST_BATCH          = 100
+
+	// a server has this long to publish a post that it's claimed before another server can claim it instead
+	SCHEDULED_POST_CLAIM_TIMEOUT = 5 * time.Minute
+
+	// how long to wait before trying again to remove a scheduled post that's been published
+	SCHEDULED_POST_DELETE_RETRY_DELAY = 5 * time.Second
+)
+
+func StartScheduledPostTask() {
+	if task := model.GetTaskByName(SCHEDULED_POST_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(SCHEDULED_POST_TASK_NAME, PublishDueScheduledPosts, SCHEDULED_POST_CHECK_INTERVAL)
+}
+
+// PublishDueScheduledPosts publishes every scheduled post that's due. Every server in a cluster runs this, but each
+// post is claimed in the database before it's published so only one of them will publish it. If the server that claimed
+// a post stops before publishing it, the claim expires so that the post is retried.
+func PublishDueScheduledPosts() {
+	for {
+		now := model.GetMillis()
+
+		var posts []*model.ScheduledPost
+		if result := <-Srv.Store.ScheduledPost().GetDue(now, getExpiredScheduledPostClaimTime(now), SCHEDULED_POST_BATCH); result.Err != nil {
+			l4g.Error(utils.T("api.scheduled_post.publish.get_due.error"), result.Err.Error())
+			return
+		} else {
+			posts = result.Data.([]*model.ScheduledPost)
+		}
+
+		for _, scheduledPost := range posts {
+			publishScheduledPost(scheduledPost)
+		}
+
+		if len(posts) < SCHEDULED_POST_BATCH {
+			return
+		}
+	}
+}
+
+func getExpiredScheduledPostClaimTime(now int64) int64 {
+	return now - int64(SCHEDULED_POST_CLAIM_TIMEOUT/time.Millisecond)
+}
+
+func publishScheduledPost(scheduledPost *model.ScheduledPost) {
+	claimedAt := model.GetMillis()
+	if result := <-Srv.Store.ScheduledPost().Claim(scheduledPost.Id, claimedAt, getExpiredScheduledPostClaimTime(claimedAt)); result.Err != nil {
+		l4g.Error(utils.T("api.scheduled_post.publish.claim.error"), scheduledPost.Id, result.Err.Error())
+		return
+	} else if !result.Data.(bool) {
+		// another server is already publishing this post
+		return
+	}
+
+	if err := canPublishScheduledPost(scheduledPost); err != nil {
+		failScheduledPost(scheduledPost, claimedAt, err)
+		return
+	}
+
+	post := scheduledPost.ToPost()
+	StripUntrustedPostActions(post)
+
+	if _, err := CreatePost(post, scheduledPost.TeamId, true); err != nil {
+		failScheduledPost(scheduledPost, claimedAt, err)
+		return
+	}
+
+	deletePublishedScheduledPost(scheduledPost, claimedAt)
+}
+
+// deletePublishedScheduledPost removes a scheduled post once it's been published. Another server would publish the
+// post again if it's still there once the claim made at claimedAt expires, so removing it is retried until half of the
+// claim's time has passed.
+func deletePublishedScheduledPost(scheduledPost *model.ScheduledPost, claimedAt int64) {
+	deadline := claimedAt + int64(SCHEDULED_POST_CLAIM_TIMEOUT/time.Millisecond)/2
+
+	for {
+		result := <-Srv.Store.ScheduledPost().Delete(scheduledPost.Id)
+		if result.Err == nil {
+			return
+		}
+
+		if model.GetMillis()+int64(SCHEDULED_POST_DELETE_RETRY_DELAY/time.Millisecond) > deadline {
+			l4g.Error(utils.T("api.scheduled_post.publish.delete.error"), scheduledPost.Id, result.Err.Error())
+			return
+		}
+
+		l4g.Warn(utils.T("api.scheduled_post.publish.delete_retry.warn"), scheduledPost.Id, result.Err.Error())
+		time.Sleep(SCHEDULED_POST_DELETE_RETRY_DELAY)
+	}
+}
+
+// canPublishScheduledPost checks that the author of a scheduled post is still allowed to post it since their
+// permissions may have changed after it was scheduled.
+func canPublishScheduledPost(scheduledPost *model.ScheduledPost) *model.AppError {
+	if result := <-Srv.Store.User().Get(scheduledPost.UserId); result.Err != nil {
+		return result.Err
+	} else if user := result.Data.(*model.User); user.DeleteAt != 0 {
+		return model.NewLocAppError("publishScheduledPost", "api.scheduled_post.publish.user_inactive.app_error", nil, "user_id="+user.Id)
+	}
+
+	if result := <-Srv.Store.Channel().Get(scheduledPost.ChannelId, true); result.Err != nil {
+		return result.Err
+	} else if channel := result.Data.(*model.Channel); channel.DeleteAt != 0 {
+		return model.NewLocAppError("publishScheduledPost", "api.post.create_post.can_not_post_to_deleted.error", nil, "channel_id="+channel.Id)
+	}
+
+	if result := <-Srv.Store.Channel().GetMember(scheduledPost.ChannelId, scheduledPost.UserId); result.Err != nil {
+		return model.NewLocAppError("publishScheduledPost", "api.scheduled_post.publish.not_member.app_error", nil, "channel_id="+scheduledPost.ChannelId)
+	}
+
+	return nil
+}
+
+// failScheduledPost records why a scheduled post couldn't be published so that the user can see it and reschedule it.
+// Nothing is recorded if the claim made at claimedAt has since expired and been taken over by another server.
+func failScheduledPost(scheduledPost *model.ScheduledPost, claimedAt int64, err *model.AppError) {
+	l4g.Warn(utils.T("api.scheduled_post.publish.failed.warn"), scheduledPost.Id, err.Error())
+
+	if result := <-Srv.Store.ScheduledPost().Get(scheduledPost.Id); result.Err != nil {
+		l4g.Error(utils.T("api.scheduled_post.publish.save_error.error"), scheduledPost.Id, result.Err.Error())
+		return
+	} else {
+		scheduledPost = result.Data.(*model.ScheduledPost)
+	}
+
+	scheduledPost.ErrorMessage = err.Message
+	if len(scheduledPost.ErrorMessage) > model.SCHEDULED_POST_ERROR_MESSAGE_MAX_LENGTH {
+		scheduledPost.ErrorMessage = scheduledPost.ErrorMessage[:model.SCHEDULED_POST_ERROR_MESSAGE_MAX_LENGTH]
+	}
+
+	if result := <-Srv.Store.ScheduledPost().Update(scheduledPost, claimedAt); result.Err != nil {
+		l4g.Error(utils.T("api.scheduled_post.publish.save_error.error"), scheduledPost.Id, result.Err.Error())
+		return
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_SCHEDULED_POST_FAILED, scheduledPost.TeamId, "", scheduledPost.UserId, nil)
+	message.Add("scheduled_post", scheduledPost.ToJson())
+
+	Publish(message)
+}
+
+func CreateScheduledPost(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	if err := validateScheduledPost(scheduledPost); err != nil {
+		return nil, err
+	}
+
+	if result := <-Srv.Store.ScheduledPost().Save(scheduledPost); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPost(id string) (*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func GetScheduledPostsForUser(teamId string, userId string) ([]*model.ScheduledPost, *model.AppError) {
+	if result := <-Srv.Store.ScheduledPost().GetForUser(teamId, userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ScheduledPost), nil
+	}
+}
+
+// UpdateScheduledPost changes the contents or time of a scheduled post. A post that failed to publish is scheduled
+// again, but one that's already being published can't be changed.
+func UpdateScheduledPost(oldScheduledPost *model.ScheduledPost, updatedScheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	if !oldScheduledPost.IsPending() && !oldScheduledPost.HasFailed() {
+		err := model.NewLocAppError("UpdateScheduledPost", "api.scheduled_post.update.published.app_error", nil, "id="+oldScheduledPost.Id)
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	scheduledPost := *oldScheduledPost
+	scheduledPost.Message = updatedScheduledPost.Message
+	scheduledPost.Props = updatedScheduledPost.Props
+	scheduledPost.FileIds = updatedScheduledPost.FileIds
+	scheduledPost.ScheduledAt = updatedScheduledPost.ScheduledAt
+	scheduledPost.ProcessedAt = 0
+	scheduledPost.ErrorMessage = ""
+
+	if err := validateScheduledPost(&scheduledPost); err != nil {
+		return nil, err
+	}
+
+	// this fails if a server started publishing the post after it was read
+	if result := <-Srv.Store.ScheduledPost().Update(&scheduledPost, oldScheduledPost.ProcessedAt); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.ScheduledPost), nil
+	}
+}
+
+func DeleteScheduledPost(scheduledPost *model.ScheduledPost) *model.AppError {
+	if !scheduledPost.IsPending() && !scheduledPost.HasFailed() {
+		err := model.NewLocAppError("DeleteScheduledPost", "api.scheduled_post.delete.published.app_error", nil, "id="+scheduledPost.Id)
+		err.StatusCode = http.StatusBadRequest
+		return err
+	}
+
+	if result := <-Srv.Store.ScheduledPost().Delete(scheduledPost.Id); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func validateScheduledPost(scheduledPost *model.ScheduledPost) *model.AppError {
+	if scheduledPost.ScheduledAt <= model.GetMillis() {
+		err := model.NewLocAppError("validateScheduledPost", "api.scheduled_post.scheduled_at.app_error", nil, "")
+		err.StatusCode = http.StatusBadRequest
+		return err
+	}
+
+	if len(scheduledPost.RootId) > 0 {
+		if result := <-Srv.Store.Post().Get(scheduledPost.RootId); result.Err != nil {
+			return model.NewLocAppError("validateScheduledPost", "api.post.create_post.root_id.app_error", nil, "")
+		} else if list := result.Data.(*model.PostList); len(list.Posts) == 0 || !list.IsChannelId(scheduledPost.ChannelId) {
+			return model.NewLocAppError("validateScheduledPost", "api.post.create_post.channel_root_id.app_error", nil, "")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,468 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	SEARCH_ENGINE_INDEXING_BATCH_SIZE = 1000
+)
+
+func StartSearchEngine() {
+	if searchEngine := einterfaces.GetSearchEngineInterface(); searchEngine != nil {
+		if err := searchEngine.Start(); err != nil {
+			l4g.Error(utils.T("api.search_engine.start.error"), err.Error())
+		}
+	}
+}
+
+func StopSearchEngine() {
+	if searchEngine := einterfaces.GetSearchEngineInterface(); searchEngine != nil {
+		if err := searchEngine.Stop(); err != nil {
+			l4g.Error(utils.T("api.search_engine.stop.error"), err.Error())
+		}
+	}
+}
+
+func getIndexingSearchEngine() einterfaces.SearchEngineInterface {
+	if searchEngine := einterfaces.GetSearchEngineInterface(); searchEngine != nil && searchEngine.IsIndexingEnabled() {
+		return searchEngine
+	}
+
+	return nil
+}
+
+func getSearchingSearchEngine() einterfaces.SearchEngineInterface {
+	if searchEngine := einterfaces.GetSearchEngineInterface(); searchEngine != nil && searchEngine.IsSearchEnabled() {
+		return searchEngine
+	}
+
+	return nil
+}
+
+// IndexPost adds a post to the search index on this server and on the other servers in the cluster.
+func IndexPost(post *model.Post) {
+	IndexPostSkipClusterSend(post)
+
+	if cluster := einterfaces.GetClusterInterface(); cluster != nil && getIndexingSearchEngine() != nil {
+		cluster.IndexPost(post)
+	}
+}
+
+func IndexPostSkipClusterSend(post *model.Post) {
+	if searchEngine := getIndexingSearchEngine(); searchEngine != nil {
+		go func() {
+			if err := searchEngine.IndexPost(post); err != nil {
+				l4g.Error(utils.T("api.search_engine.index_post.error"), post.Id, err.Error())
+			}
+		}()
+	}
+}
+
+func DeletePostFromIndex(postId string) {
+	DeletePostFromIndexSkipClusterSend(postId)
+
+	if cluster := einterfaces.GetClusterInterface(); cluster != nil && getIndexingSearchEngine() != nil {
+		cluster.DeletePostFromIndex(postId)
+	}
+}
+
+func DeletePostFromIndexSkipClusterSend(postId string) {
+	if searchEngine := getIndexingSearchEngine(); searchEngine != nil {
+		go func() {
+			if err := searchEngine.DeletePost(postId); err != nil {
+				l4g.Error(utils.T("api.search_engine.delete_post.error"), postId, err.Error())
+			}
+		}()
+	}
+}
+
+func IndexChannel(channel *model.Channel) {
+	IndexChannelSkipClusterSend(channel)
+
+	if cluster := einterfaces.GetClusterInterface(); cluster != nil && getIndexingSearchEngine() != nil {
+		cluster.IndexChannel(channel)
+	}
+}
+
+func IndexChannelSkipClusterSend(channel *model.Channel) {
+	if searchEngine := getIndexingSearchEngine(); searchEngine != nil {
+		go func() {
+			if err := searchEngine.IndexChannel(channel); err != nil {
+				l4g.Error(utils.T("api.search_engine.index_channel.error"), channel.Id, err.Error())
+			}
+		}()
+	}
+}
+
+func DeleteChannelFromIndex(channelId string) {
+	DeleteChannelFromIndexSkipClusterSend(channelId)
+
+	if cluster := einterfaces.GetClusterInterface(); cluster != nil && getIndexingSearchEngine() != nil {
+		cluster.DeleteChannelFromIndex(channelId)
+	}
+}
+
+func DeleteChannelFromIndexSkipClusterSend(channelId string) {
+	if searchEngine := getIndexingSearchEngine(); searchEngine != nil {
+		go func() {
+			if err := searchEngine.DeleteChannel(channelId); err != nil {
+				l4g.Error(utils.T("api.search_engine.delete_channel.error"), channelId, err.Error())
+			}
+		}()
+	}
+}
+
+// IndexUserById updates the search index for a user along with the teams that they belong to.
+func IndexUserById(userId string) {
+	IndexUserByIdSkipClusterSend(userId)
+
+	if cluster := einterfaces.GetClusterInterface(); cluster != nil && getIndexingSearchEngine() != nil {
+		cluster.IndexUser(userId)
+	}
+}
+
+func IndexUserByIdSkipClusterSend(userId string) {
+	if searchEngine := getIndexingSearchEngine(); searchEngine != nil {
+		go func() {
+			if result := <-Srv.Store.User().Get(userId); result.Err != nil {
+				l4g.Error(utils.T("api.search_engine.index_user.error"), userId, result.Err.Error())
+			} else if err := indexUser(searchEngine, result.Data.(*model.User)); err != nil {
+				l4g.Error(utils.T("api.search_engine.index_user.error"), userId, err.Error())
+			}
+		}()
+	}
+}
+
+func indexUser(searchEngine einterfaces.SearchEngineInterface, user *model.User) *model.AppError {
+	if result := <-Srv.Store.Team().GetTeamsForUser(user.Id); result.Err != nil {
+		return result.Err
+	} else {
+		teamIds := []string{}
+		for _, member := range result.Data.([]*model.TeamMember) {
+			if member.DeleteAt == 0 {
+				teamIds = append(teamIds, member.TeamId)
+			}
+		}
+
+		return searchEngine.IndexUser(user, teamIds)
+	}
+}
+
+func DeleteUserFromIndex(userId string) {
+	DeleteUserFromIndexSkipClusterSend(userId)
+
+	if cluster := einterfaces.GetClusterInterface(); cluster != nil && getIndexingSearchEngine() != nil {
+		cluster.DeleteUserFromIndex(userId)
+	}
+}
+
+func DeleteUserFromIndexSkipClusterSend(userId string) {
+	if searchEngine := getIndexingSearchEngine(); searchEngine != nil {
+		go func() {
+			if err := searchEngine.DeleteUser(userId); err != nil {
+				l4g.Error(utils.T("api.search_engine.delete_user.error"), userId, err.Error())
+			}
+		}()
+	}
+}
+
+// SearchPostsInTeam runs each of the searches given by the search terms using the search engine, if one is enabled,
+// or the database otherwise and combines the results.
+func SearchPostsInTeam(terms string, userId string, teamId string, isOrSearch bool) (*model.PostList, *model.AppError) {
+	paramsList := model.ParseSearchParams(terms)
+
+	posts := &model.PostList{}
+
+	if searchEngine := getSearchingSearchEngine(); searchEngine != nil {
+		for _, params := range paramsList {
+			params.OrTerms = isOrSearch
+			// don't allow users to search for everything
+			if params.Terms == "*" || !hasPostSearchTermsOrFilters(params) {
+				continue
+			}
+
+			if list, err := searchPostsWithEngine(searchEngine, userId, teamId, params); err != nil {
+				return nil, err
+			} else {
+				posts.Extend(list)
+			}
+		}
+	} else {
+		channels := []store.StoreChannel{}
+
+		for _, params := range paramsList {
+			params.OrTerms = isOrSearch
+			// don't allow users to search for everything
+			if params.Terms != "*" && hasPostSearchTermsOrFilters(params) {
+				channels = append(channels, Srv.Store.Post().Search(teamId, userId, params))
+			}
+		}
+
+		for _, channel := range channels {
+			if result := <-channel; result.Err != nil {
+				return nil, result.Err
+			} else {
+				posts.Extend(result.Data.(*model.PostList))
+			}
+		}
+	}
+
+	posts.MakeNonNil()
+
+	return posts, nil
+}
+
+// hasPostSearchTermsOrFilters returns false if the search params only contain filters that don't apply to posts, such
+// as file extensions.
+func hasPostSearchTermsOrFilters(params *model.SearchParams) bool {
+	return params.Terms != "" || len(params.InChannels) != 0 || len(params.FromUsers) != 0 || params.HasDateFilter()
+}
+
+func searchPostsWithEngine(searchEngine einterfaces.SearchEngineInterface, userId string, teamId string, params *model.SearchParams) (*model.PostList, *model.AppError) {
+	var channels *model.ChannelList
+	if result := <-Srv.Store.Channel().GetChannels(teamId, userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		channels = result.Data.(*model.ChannelList)
+	}
+
+	inChannels := make(map[string]bool, len(params.InChannels))
+	for _, name := range params.InChannels {
+		inChannels[name] = true
+	}
+
+	channelIds := []string{}
+	for _, channel := range *channels {
+		if len(inChannels) == 0 || inChannels[channel.Name] {
+			channelIds = append(channelIds, channel.Id)
+		}
+	}
+
+	fromUserIds := []string{}
+	for _, username := range params.FromUsers {
+		if result := <-Srv.Store.User().GetByUsername(username); result.Err == nil {
+			fromUserIds = append(fromUserIds, result.Data.(*model.User).Id)
+		}
+	}
+
+	list := &model.PostList{}
+
+	if len(channelIds) == 0 || (len(params.FromUsers) > 0 && len(fromUserIds) == 0) {
+		return list, nil
+	}
+
+	postIds, err := searchEngine.SearchPosts(channelIds, fromUserIds, params)
+	if err != nil {
+		return nil, err
+	} else if len(postIds) == 0 {
+		return list, nil
+	}
+
+	postsById := make(map[string]*model.Post, len(postIds))
+	if result := <-Srv.Store.Post().GetPostsByIds(postIds); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, post := range result.Data.([]*model.Post) {
+			postsById[post.Id] = post
+		}
+	}
+
+	// keep the order returned by the search engine since it's sorted by relevance
+	missingPostIds := []string{}
+	for _, postId := range postIds {
+		post, ok := postsById[postId]
+		if !ok {
+			missingPostIds = append(missingPostIds, postId)
+			continue
+		} else if post.IsSystemMessage() {
+			continue
+		}
+
+		list.AddPost(post)
+		list.AddOrder(post.Id)
+
+		if params.Terms != "" {
+			if snippet := params.Snippet(post.Message); snippet != "" {
+				list.AddSnippet(post.Id, snippet)
+			}
+		}
+	}
+
+	if len(missingPostIds) > 0 {
+		go removeDeletedPostsFromIndex(missingPostIds)
+	}
+
+	return list, nil
+}
+
+// removeDeletedPostsFromIndex removes any of the given posts that no longer exist from the search index. This cleans
+// up after posts that were deleted without going through a running server, such as by the data retention command.
+func removeDeletedPostsFromIndex(postIds []string) {
+	if result := <-Srv.Store.Post().GetDeletedPostIds(postIds); result.Err != nil {
+		l4g.Error(utils.T("api.search_engine.remove_deleted_posts.error"), result.Err.Error())
+	} else {
+		for _, postId := range result.Data.([]string) {
+			DeletePostFromIndex(postId)
+		}
+	}
+}
+
+// SearchUsersInTeamWithEngine returns the users matching the given term using the search engine. The returned bool
+// is false if the search engine isn't enabled, in which case the database should be searched instead.
+func SearchUsersInTeamWithEngine(teamId string, term string, allowInactive bool) ([]*model.User, bool, *model.AppError) {
+	searchEngine := getSearchingSearchEngine()
+	if searchEngine == nil {
+		return nil, false, nil
+	}
+
+	userIds, err := searchEngine.SearchUsersInTeam(teamId, term)
+	if err != nil {
+		return nil, true, err
+	}
+
+	users := []*model.User{}
+	if len(userIds) == 0 {
+		return users, true, nil
+	}
+
+	if result := <-Srv.Store.User().GetProfileByIds(userIds, false); result.Err != nil {
+		return nil, true, result.Err
+	} else {
+		profiles := result.Data.(map[string]*model.User)
+
+		for _, userId := range userIds {
+			if user, ok := profiles[userId]; ok && (allowInactive || user.DeleteAt == 0) {
+				users = append(users, user)
+			}
+		}
+	}
+
+	return users, true, nil
+}
+
+// SearchMoreChannelsWithEngine returns the open channels matching the given term that the user doesn't belong to using
+// the search engine. The returned bool is false if the search engine isn't enabled, in which case the database should
+// be searched instead.
+func SearchMoreChannelsWithEngine(userId string, teamId string, term string) (*model.ChannelList, bool, *model.AppError) {
+	searchEngine := getSearchingSearchEngine()
+	if searchEngine == nil {
+		return nil, false, nil
+	}
+
+	channelIds, err := searchEngine.SearchChannels(teamId, term)
+	if err != nil {
+		return nil, true, err
+	}
+
+	channels := model.ChannelList{}
+	if len(channelIds) == 0 {
+		return &channels, true, nil
+	}
+
+	memberOf := map[string]bool{}
+	if result := <-Srv.Store.Channel().GetChannels(teamId, userId); result.Err != nil {
+		return nil, true, result.Err
+	} else {
+		for _, channel := range *result.Data.(*model.ChannelList) {
+			memberOf[channel.Id] = true
+		}
+	}
+
+	if result := <-Srv.Store.Channel().GetChannelsByIds(channelIds); result.Err != nil {
+		return nil, true, result.Err
+	} else {
+		for _, channel := range result.Data.([]*model.Channel) {
+			if !memberOf[channel.Id] {
+				channels = append(channels, channel)
+			}
+		}
+	}
+
+	return &channels, true, nil
+}
+
+// ReindexSearchEngine removes everything from the search engine's indexes and adds every post, open channel and user
+// stored in the database back to them.
+func ReindexSearchEngine() *model.AppError {
+	searchEngine := getIndexingSearchEngine()
+	if searchEngine == nil {
+		return model.NewLocAppError("ReindexSearchEngine", "api.search_engine.reindex.disabled.app_error", nil, "")
+	}
+
+	if err := searchEngine.PurgeIndexes(); err != nil {
+		return err
+	}
+
+	if result := <-Srv.Store.Team().GetAll(); result.Err != nil {
+		return result.Err
+	} else {
+		for _, team := range result.Data.([]*model.Team) {
+			if cresult := <-Srv.Store.Channel().GetAll(team.Id); cresult.Err != nil {
+				return cresult.Err
+			} else {
+				for _, channel := range cresult.Data.([]*model.Channel) {
+					if err := searchEngine.IndexChannel(channel); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	for offset := 0; ; offset += SEARCH_ENGINE_INDEXING_BATCH_SIZE {
+		var users map[string]*model.User
+		if result := <-Srv.Store.User().GetAllProfiles(offset, SEARCH_ENGINE_INDEXING_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			users = result.Data.(map[string]*model.User)
+		}
+
+		for _, user := range users {
+			if err := indexUser(searchEngine, user); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < SEARCH_ENGINE_INDEXING_BATCH_SIZE {
+			break
+		}
+	}
+
+	var startTime int64
+	startPostId := ""
+	for {
+		var posts []*model.Post
+		if result := <-Srv.Store.Post().GetPostsBatchForIndexing(startTime, startPostId, SEARCH_ENGINE_INDEXING_BATCH_SIZE); result.Err != nil {
+			return result.Err
+		} else {
+			posts = result.Data.([]*model.Post)
+		}
+
+		for _, post := range posts {
+			if post.IsSystemMessage() {
+				continue
+			}
+
+			if err := searchEngine.IndexPost(post); err != nil {
+				return err
+			}
+		}
+
+		if len(posts) < SEARCH_ENGINE_INDEXING_BATCH_SIZE {
+			break
+		}
+
+		startTime = posts[len(posts)-1].CreateAt
+		startPostId = posts[len(posts)-1].Id
+	}
+
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	l4g "github.com/alecthomas/log4go"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
 	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
@@ -78,7 +79,19 @@ func NewServer() {
 }
 
 func InitStores() {
-	Srv.Store = store.NewSqlStore()
+	Srv.Store = NewStore()
+}
+
+// NewStore connects to the database. When metrics are available, the store is wrapped so that the time taken by each
+// call to it is recorded.
+func NewStore() store.Store {
+	sqlStore := store.NewSqlStore()
+
+	if einterfaces.GetMetricsInterface() != nil {
+		return store.NewTimerLayer(sqlStore)
+	}
+
+	return sqlStore
 }
 
 type VaryBy struct{}
@@ -31,6 +31,10 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 
 	if session == nil {
 		if sessionResult := <-Srv.Store.Session().Get(token); sessionResult.Err != nil {
+			if tokenSession, err := getSessionForPersonalAccessToken(token); err == nil {
+				return tokenSession, nil
+			}
+
 			return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token, "Error": sessionResult.Err.DetailedError}, "")
 		} else {
 			session = sessionResult.Data.(*model.Session)
@@ -39,6 +43,11 @@ func GetSession(token string) (*model.Session, *model.AppError) {
 				return nil, model.NewLocAppError("GetSession", "api.context.invalid_token.error", map[string]interface{}{"Token": token, "Error": sessionResult.Err.DetailedError}, "")
 			} else {
 				AddSessionToCache(session)
+
+				if session.IsOpenIdRefreshDue() {
+					go RefreshOpenIdSession(session)
+				}
+
 				return session, nil
 			}
 		}
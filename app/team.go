@@ -77,6 +77,12 @@ func JoinUserToTeam(team *model.Team, user *model.User) *model.AppError {
 
 	RemoveAllSessionsForUserId(user.Id)
 	InvalidateCacheForUser(user.Id)
+	IndexUserById(user.Id)
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_ADDED_TO_TEAM, team.Id, "", "", nil)
+	message.Add("team_id", team.Id)
+	message.Add("user_id", user.Id)
+	Publish(message)
 
 	return nil
 }
@@ -88,3 +94,19 @@ func GetTeamsForUser(userId string) ([]*model.Team, *model.AppError) {
 		return result.Data.([]*model.Team), nil
 	}
 }
+
+func GetTeam(teamId string) (*model.Team, *model.AppError) {
+	if result := <-Srv.Store.Team().Get(teamId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Team), nil
+	}
+}
+
+func GetTeamByName(name string) (*model.Team, *model.AppError) {
+	if result := <-Srv.Store.Team().GetByName(name); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.Team), nil
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// UpdateThreadForReply recounts the replies to the root post of a new reply, makes the authors of both posts follow the
+// thread and lets everyone following it know that it changed.
+func UpdateThreadForReply(teamId string, reply *model.Post, rootPost *model.Post) {
+	if result := <-Srv.Store.Thread().UpdateFromReplies(rootPost.Id, rootPost.ChannelId); result.Err != nil {
+		l4g.Error(utils.T("api.thread.update_thread.error"), rootPost.Id, result.Err.Error())
+		return
+	}
+
+	// the author of the reply has read everything up to and including it
+	membership := &model.ThreadMembership{
+		PostId:     rootPost.Id,
+		UserId:     reply.UserId,
+		Following:  true,
+		LastViewed: reply.CreateAt,
+	}
+	if result := <-Srv.Store.Thread().SaveMembership(membership); result.Err != nil {
+		l4g.Error(utils.T("api.thread.follow_thread.error"), rootPost.Id, reply.UserId, result.Err.Error())
+	}
+
+	// the author of the root post follows the thread unless they've already chosen not to
+	if rootPost.UserId != reply.UserId {
+		if result := <-Srv.Store.Thread().GetMembership(rootPost.Id, rootPost.UserId); result.Err != nil {
+			membership := &model.ThreadMembership{
+				PostId:     rootPost.Id,
+				UserId:     rootPost.UserId,
+				Following:  true,
+				LastViewed: rootPost.CreateAt,
+			}
+			if result := <-Srv.Store.Thread().SaveMembership(membership); result.Err != nil {
+				l4g.Error(utils.T("api.thread.follow_thread.error"), rootPost.Id, rootPost.UserId, result.Err.Error())
+			}
+		}
+	}
+
+	go publishThreadUpdatedToFollowers(teamId, rootPost.Id)
+}
+
+// UpdateThreadForDeletedPost recounts the replies to a thread after one of them is deleted or removes the thread
+// entirely if its root post is deleted.
+func UpdateThreadForDeletedPost(teamId string, post *model.Post) {
+	if post.RootId == "" {
+		if result := <-Srv.Store.Thread().Delete(post.Id); result.Err != nil {
+			l4g.Error(utils.T("api.thread.delete_thread.error"), post.Id, result.Err.Error())
+		}
+
+		return
+	}
+
+	if result := <-Srv.Store.Thread().UpdateFromReplies(post.RootId, post.ChannelId); result.Err != nil {
+		l4g.Error(utils.T("api.thread.update_thread.error"), post.RootId, result.Err.Error())
+		return
+	}
+
+	go publishThreadUpdatedToFollowers(teamId, post.RootId)
+}
+
+func publishThreadUpdatedToFollowers(teamId string, postId string) {
+	if result := <-Srv.Store.Thread().GetFollowers(postId); result.Err != nil {
+		l4g.Error(utils.T("api.thread.publish_thread_updated.error"), postId, result.Err.Error())
+	} else {
+		for _, userId := range result.Data.([]string) {
+			publishThreadUpdated(teamId, userId, postId)
+		}
+	}
+}
+
+// publishThreadUpdated sends a user the current state of a thread since the number of unread replies differs between
+// each user following it.
+func publishThreadUpdated(teamId string, userId string, postId string) {
+	if result := <-Srv.Store.Thread().GetThreadForUser(teamId, userId, postId); result.Err != nil {
+		l4g.Error(utils.T("api.thread.publish_thread_updated.error"), postId, result.Err.Error())
+	} else {
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_THREAD_UPDATED, teamId, "", userId, nil)
+		message.Add("thread", result.Data.(*model.ThreadResponse).ToJson())
+
+		Publish(message)
+	}
+}
+
+func GetThreadForUser(teamId string, userId string, postId string) (*model.ThreadResponse, *model.AppError) {
+	var thread *model.ThreadResponse
+	if result := <-Srv.Store.Thread().GetThreadForUser(teamId, userId, postId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		thread = result.Data.(*model.ThreadResponse)
+	}
+
+	if result := <-Srv.Store.Post().GetPostsByIds([]string{postId}); result.Err != nil {
+		return nil, result.Err
+	} else if posts := result.Data.([]*model.Post); len(posts) > 0 {
+		thread.Post = posts[0]
+	}
+
+	return thread, nil
+}
+
+// GetThreadsForUser returns a page of the threads that a user follows on a team along with the root post of each one.
+func GetThreadsForUser(teamId string, userId string, offset int, limit int) (*model.ThreadList, *model.AppError) {
+	var list *model.ThreadList
+	if result := <-Srv.Store.Thread().GetThreadsForUser(teamId, userId, offset, limit); result.Err != nil {
+		return nil, result.Err
+	} else {
+		list = result.Data.(*model.ThreadList)
+	}
+
+	if len(list.Threads) == 0 {
+		return list, nil
+	}
+
+	postIds := make([]string, len(list.Threads))
+	for i, thread := range list.Threads {
+		postIds[i] = thread.PostId
+	}
+
+	if result := <-Srv.Store.Post().GetPostsByIds(postIds); result.Err != nil {
+		return nil, result.Err
+	} else {
+		posts := map[string]*model.Post{}
+		for _, post := range result.Data.([]*model.Post) {
+			posts[post.Id] = post
+		}
+
+		for _, thread := range list.Threads {
+			thread.Post = posts[thread.PostId]
+		}
+	}
+
+	return list, nil
+}
+
+// SetThreadFollowing makes a user start or stop following a thread, keeping track of how much of it they've read.
+func SetThreadFollowing(teamId string, userId string, postId string, following bool) (*model.ThreadResponse, *model.AppError) {
+	membership := &model.ThreadMembership{
+		PostId: postId,
+		UserId: userId,
+	}
+
+	if result := <-Srv.Store.Thread().GetMembership(postId, userId); result.Err == nil {
+		membership = result.Data.(*model.ThreadMembership)
+	}
+
+	membership.Following = following
+
+	if result := <-Srv.Store.Thread().SaveMembership(membership); result.Err != nil {
+		return nil, result.Err
+	}
+
+	publishThreadUpdated(teamId, userId, postId)
+
+	return GetThreadForUser(teamId, userId, postId)
+}
+
+// MarkThreadAsRead marks every reply to a thread as read by the user without changing whether or not they follow it.
+func MarkThreadAsRead(teamId string, userId string, postId string) (*model.ThreadResponse, *model.AppError) {
+	membership := &model.ThreadMembership{
+		PostId: postId,
+		UserId: userId,
+	}
+
+	if result := <-Srv.Store.Thread().GetMembership(postId, userId); result.Err == nil {
+		membership = result.Data.(*model.ThreadMembership)
+	}
+
+	membership.LastViewed = model.GetMillis()
+
+	if result := <-Srv.Store.Thread().SaveMembership(membership); result.Err != nil {
+		return nil, result.Err
+	}
+
+	publishThreadUpdated(teamId, userId, postId)
+
+	return GetThreadForUser(teamId, userId, postId)
+}
+
+func MarkAllThreadsAsRead(teamId string, userId string) *model.AppError {
+	if result := <-Srv.Store.Thread().MarkAllAsRead(teamId, userId, model.GetMillis()); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
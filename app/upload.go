@@ -0,0 +1,321 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	UPLOAD_SESSION_CLEANUP_TASK_NAME = "Upload Session Cleanup"
+	UPLOAD_SESSION_CLEANUP_BATCH     = 100
+)
+
+func CreateUploadSession(session *model.UploadSession) (*model.UploadSession, *model.AppError) {
+	if session.FileSize > *utils.Cfg.FileSettings.MaxFileSize {
+		err := model.NewLocAppError("CreateUploadSession", "api.file.upload_file.too_large.app_error", nil, "")
+		err.StatusCode = http.StatusRequestEntityTooLarge
+		return nil, err
+	}
+
+	session.FileOffset = 0
+
+	if result := <-Srv.Store.UploadSession().Save(session); result.Err != nil {
+		result.Err.StatusCode = http.StatusBadRequest
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.UploadSession), nil
+	}
+}
+
+func GetUploadSession(id string) (*model.UploadSession, *model.AppError) {
+	if result := <-Srv.Store.UploadSession().Get(id); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.(*model.UploadSession), nil
+	}
+}
+
+func GetUploadSessionsForUser(userId string) ([]*model.UploadSession, *model.AppError) {
+	if result := <-Srv.Store.UploadSession().GetForUser(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.UploadSession), nil
+	}
+}
+
+// Each chunk is stored as a separate file named after its offset so that the chunks sort into the order in which they
+// need to be put back together.
+func getUploadChunkPath(session *model.UploadSession, offset int64) string {
+	return fmt.Sprintf("%s/chunks/%020d", session.Path, offset)
+}
+
+func getUploadChunkDirectory(session *model.UploadSession) string {
+	return session.Path + "/chunks"
+}
+
+// Chunks are written somewhere else first and only moved into place once the upload's offset has been claimed for them
+// so that two requests for the same offset can't overwrite each other's data.
+func getIncomingUploadChunkPath(session *model.UploadSession) string {
+	return fmt.Sprintf("%s/incoming/%s", session.Path, model.NewId())
+}
+
+// UploadChunk appends the data from the given reader to the upload. The offset must match the number of bytes that
+// have been received so far so that a client resuming an upload can't leave a gap or overlap in the file.
+func UploadChunk(session *model.UploadSession, offset int64, fr io.Reader) (*model.UploadSession, *model.AppError) {
+	if offset != session.FileOffset {
+		err := model.NewLocAppError("UploadChunk", "api.file.upload_chunk.offset.app_error", map[string]interface{}{"Expected": session.FileOffset, "Actual": offset}, "id="+session.Id)
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	remaining := session.FileSize - session.FileOffset
+	incomingPath := getIncomingUploadChunkPath(session)
+
+	// Read one extra byte so that we can tell if the client sent more than it said the file contained
+	written, err := WriteFileStream(&io.LimitedReader{R: fr, N: remaining + 1}, incomingPath)
+	if err != nil {
+		RemoveFile(incomingPath)
+		return nil, err
+	} else if written > remaining {
+		RemoveFile(incomingPath)
+
+		err := model.NewLocAppError("UploadChunk", "api.file.upload_chunk.too_large.app_error", nil, "id="+session.Id)
+		err.StatusCode = http.StatusRequestEntityTooLarge
+		return nil, err
+	} else if written == 0 {
+		RemoveFile(incomingPath)
+
+		err := model.NewLocAppError("UploadChunk", "api.file.upload_chunk.empty.app_error", nil, "id="+session.Id)
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	// Only the request that manages to move the offset forward gets to keep its chunk
+	if result := <-Srv.Store.UploadSession().UpdateFileOffset(session.Id, offset, offset+written); result.Err != nil {
+		RemoveFile(incomingPath)
+		return nil, result.Err
+	}
+
+	if err := MoveFile(incomingPath, getUploadChunkPath(session, offset)); err != nil {
+		RemoveFile(incomingPath)
+
+		// Give the offset back so that the client can send the chunk again
+		if result := <-Srv.Store.UploadSession().UpdateFileOffset(session.Id, offset+written, offset); result.Err != nil {
+			l4g.Error(utils.T("api.file.upload_chunk.reset_offset.error"), session.Id, result.Err.Error())
+		}
+
+		return nil, err
+	}
+
+	session.FileOffset = offset + written
+	return session, nil
+}
+
+// FinishUploadSession puts the chunks of a completed upload back together into a regular file. The returned FileInfo
+// can then be attached to a post in the same way as a file uploaded in a single request.
+func FinishUploadSession(session *model.UploadSession) (*model.FileInfo, *model.AppError) {
+	if !session.IsComplete() {
+		err := model.NewLocAppError("FinishUploadSession", "api.file.finish_upload_session.incomplete.app_error",
+			map[string]interface{}{"Received": session.FileOffset, "Expected": session.FileSize}, "id="+session.Id)
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	backend, err := FileBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	// Claim the session so that finishing the same upload twice can't create two copies of the file
+	claimedAt, err := claimUploadSession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := backend.ListDirectory(getUploadChunkDirectory(session))
+	if err != nil {
+		releaseUploadSession(session, claimedAt)
+		return nil, err
+	}
+	sort.Strings(chunks)
+
+	reader := &chunkReader{backend: backend, paths: chunks}
+	defer reader.Close()
+
+	// The chunks are kept if this fails so that the client can try to finish the upload again
+	info, err := UploadFileStream(session.TeamId, session.ChannelId, session.UserId, session.Filename, reader)
+	if err != nil {
+		releaseUploadSession(session, claimedAt)
+		return nil, err
+	}
+
+	if info.Size != session.FileSize {
+		RemoveFile(info.Path)
+		if result := <-Srv.Store.FileInfo().PermanentDeleteByIds([]string{info.Id}); result.Err != nil {
+			l4g.Error(utils.T("api.file.finish_upload_session.remove_file.error"), info.Id, result.Err.Error())
+		}
+
+		// The stored chunks don't make up the file that the client described, so there's nothing to retry
+		if err := removeUploadSession(backend, session); err != nil {
+			l4g.Error(utils.T("api.file.finish_upload_session.delete.error"), session.Id, err.Error())
+		}
+
+		err := model.NewLocAppError("FinishUploadSession", "api.file.finish_upload_session.size_mismatch.app_error",
+			map[string]interface{}{"Received": info.Size, "Expected": session.FileSize}, "id="+session.Id)
+		err.StatusCode = http.StatusBadRequest
+		return nil, err
+	}
+
+	// The file has been stored at this point, so the session is left claimed for the cleanup task to remove if this fails
+	if err := removeUploadSession(backend, session); err != nil {
+		l4g.Error(utils.T("api.file.finish_upload_session.delete.error"), session.Id, err.Error())
+	}
+
+	HandleImages([]*model.FileInfo{info})
+
+	return info, nil
+}
+
+// DeleteUploadSession removes an upload and any chunks that have been stored for it. An upload can't be removed while
+// another request is finishing it.
+func DeleteUploadSession(session *model.UploadSession) *model.AppError {
+	backend, err := FileBackend()
+	if err != nil {
+		return err
+	}
+
+	if _, err := claimUploadSession(session); err != nil {
+		return err
+	}
+
+	return removeUploadSession(backend, session)
+}
+
+func claimUploadSession(session *model.UploadSession) (int64, *model.AppError) {
+	claimedAt := model.GetMillis()
+
+	if result := <-Srv.Store.UploadSession().Claim(session.Id, claimedAt); result.Err != nil {
+		return 0, result.Err
+	} else if !result.Data.(bool) {
+		err := model.NewLocAppError("claimUploadSession", "api.file.upload_session.claimed.app_error", nil, "id="+session.Id)
+		err.StatusCode = http.StatusConflict
+		return 0, err
+	}
+
+	return claimedAt, nil
+}
+
+func releaseUploadSession(session *model.UploadSession, claimedAt int64) {
+	if result := <-Srv.Store.UploadSession().ReleaseClaim(session.Id, claimedAt); result.Err != nil {
+		l4g.Error(utils.T("api.file.finish_upload_session.release.error"), session.Id, result.Err.Error())
+	}
+}
+
+func removeUploadSession(backend utils.FileBackend, session *model.UploadSession) *model.AppError {
+	if result := <-Srv.Store.UploadSession().Delete(session.Id); result.Err != nil {
+		return result.Err
+	}
+
+	return backend.RemoveDirectory(session.Path)
+}
+
+// chunkReader reads each of the stored chunks of an upload in turn without opening them all at once.
+type chunkReader struct {
+	backend utils.FileBackend
+	paths   []string
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+
+			reader, err := r.backend.Reader(r.paths[0])
+			if err != nil {
+				return 0, err
+			}
+
+			r.current = reader
+			r.paths = r.paths[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+
+			if n == 0 {
+				continue
+			}
+
+			err = nil
+		}
+
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+
+	return nil
+}
+
+func StartUploadSessionCleanupTask() {
+	if task := model.GetTaskByName(UPLOAD_SESSION_CLEANUP_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	model.CreateRecurringTask(UPLOAD_SESSION_CLEANUP_TASK_NAME, CleanupStaleUploadSessions, time.Hour)
+}
+
+// CleanupStaleUploadSessions removes any uploads that haven't received a chunk in a day.
+func CleanupStaleUploadSessions() {
+	updatedBefore := model.GetMillis() - model.UPLOAD_SESSION_EXPIRY_MILLIS
+
+	backend, err := FileBackend()
+	if err != nil {
+		l4g.Error(utils.T("api.file.cleanup_upload_sessions.file_backend.error"), err.Error())
+		return
+	}
+
+	for {
+		var sessions []*model.UploadSession
+		if result := <-Srv.Store.UploadSession().GetStale(updatedBefore, UPLOAD_SESSION_CLEANUP_BATCH); result.Err != nil {
+			l4g.Error(utils.T("api.file.cleanup_upload_sessions.get.error"), result.Err.Error())
+			return
+		} else {
+			sessions = result.Data.([]*model.UploadSession)
+		}
+
+		// Stale sessions are removed even if they're claimed since the request that claimed them must have stopped
+		for _, session := range sessions {
+			if err := removeUploadSession(backend, session); err != nil {
+				l4g.Error(utils.T("api.file.cleanup_upload_sessions.delete.error"), session.Id, err.Error())
+				return
+			}
+		}
+
+		if len(sessions) < UPLOAD_SESSION_CLEANUP_BATCH {
+			break
+		}
+	}
+
+	l4g.Debug(utils.T("api.file.cleanup_upload_sessions.debug"), strconv.FormatInt(updatedBefore, 10))
+}
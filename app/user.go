@@ -104,6 +104,8 @@ func IsFirstUserAccount() bool {
 }
 
 func CreateUser(user *model.User) (*model.User, *model.AppError) {
+	// Bots can only be created by CreateBot
+	user.IsBot = false
 
 	user.Roles = model.ROLE_SYSTEM_USER.Id
 
@@ -125,6 +127,10 @@ func CreateUser(user *model.User) (*model.User, *model.AppError) {
 		return nil, err
 	}
 
+	return createUser(user)
+}
+
+func createUser(user *model.User) (*model.User, *model.AppError) {
 	if result := <-Srv.Store.User().Save(user); result.Err != nil {
 		l4g.Error(utils.T("api.user.create_user.save.error"), result.Err)
 		return nil, result.Err
@@ -142,6 +148,8 @@ func CreateUser(user *model.User) (*model.User, *model.AppError) {
 			l4g.Error(utils.T("api.user.create_user.tutorial.error"), presult.Err.Message)
 		}
 
+		IndexUserById(ruser.Id)
+
 		ruser.Sanitize(map[string]bool{})
 
 		// This message goes to everyone, so the teamId, channelId and userId are irrelevant
@@ -375,6 +383,24 @@ func DeactivateMfa(userId string) *model.AppError {
 	return nil
 }
 
+func GenerateMfaRecoveryCodes(userId string) ([]string, *model.AppError) {
+	mfaInterface := einterfaces.GetMfaInterface()
+	if mfaInterface == nil {
+		err := model.NewLocAppError("GenerateMfaRecoveryCodes", "api.user.update_mfa.not_available.app_error", nil, "")
+		err.StatusCode = http.StatusNotImplemented
+		return nil, err
+	}
+
+	var user *model.User
+	if result := <-Srv.Store.User().Get(userId); result.Err != nil {
+		return nil, result.Err
+	} else {
+		user = result.Data.(*model.User)
+	}
+
+	return mfaInterface.GenerateRecoveryCodes(user)
+}
+
 func CreateProfileImage(username string, userId string) ([]byte, *model.AppError) {
 	colors := []color.NRGBA{
 		{197, 8, 126, 255},
@@ -33,21 +33,31 @@ type WebConn struct {
 	Locale                    string
 	AllChannelMembers         map[string]string
 	LastAllChannelMembersTime int64
+	ConnectionId              string
+	Sequence                  int64 // the sequence number of the next event sent over the connection
+	resumeConnectionId        string
+	resumeSequence            int64
 }
 
-func NewWebConn(ws *websocket.Conn, session model.Session, t goi18n.TranslateFunc, locale string) *WebConn {
+// NewWebConn creates a connection for the given session. If the client was connected before, it can pass the id of
+// its previous connection and the sequence number of the last event it received so that it's sent any events that
+// it missed in between.
+func NewWebConn(ws *websocket.Conn, session model.Session, t goi18n.TranslateFunc, locale string, resumeConnectionId string, resumeSequence int64) *WebConn {
 	if len(session.UserId) > 0 {
 		go SetStatusOnline(session.UserId, session.Id, false)
 	}
 
 	return &WebConn{
-		Send:             make(chan model.WebSocketMessage, 256),
-		WebSocket:        ws,
-		UserId:           session.UserId,
-		SessionToken:     session.Token,
-		SessionExpiresAt: session.ExpiresAt,
-		T:                t,
-		Locale:           locale,
+		Send:               make(chan model.WebSocketMessage, 256),
+		WebSocket:          ws,
+		UserId:             session.UserId,
+		SessionToken:       session.Token,
+		SessionExpiresAt:   session.ExpiresAt,
+		T:                  t,
+		Locale:             locale,
+		ConnectionId:       model.NewId(),
+		resumeConnectionId: resumeConnectionId,
+		resumeSequence:     resumeSequence,
 	}
 }
 
@@ -171,11 +181,11 @@ func (webCon *WebConn) IsAuthenticated() bool {
 	return true
 }
 
-func (webCon *WebConn) SendHello() {
+func (webCon *WebConn) NewHelloEvent() *model.WebSocketEvent {
 	msg := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_HELLO, "", "", webCon.UserId, nil)
 	msg.Add("server_version", fmt.Sprintf("%v.%v.%v", model.CurrentVersion, model.BuildNumber, utils.CfgHash))
-	msg.DoPreComputeJson()
-	webCon.Send <- msg
+	msg.Add("connection_id", webCon.ConnectionId)
+	return msg
 }
 
 func (webCon *WebConn) ShouldSendEvent(msg *model.WebSocketEvent) bool {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"runtime"
+	"time"
 
 	l4g "github.com/alecthomas/log4go"
 
@@ -22,6 +23,7 @@ type Hub struct {
 	broadcast      chan *model.WebSocketEvent
 	stop           chan string
 	invalidateUser chan string
+	replayBuffers  map[string]*replayBuffer
 }
 
 var hubs []*Hub = make([]*Hub, 0)
@@ -34,6 +36,7 @@ func NewWebHub() *Hub {
 		broadcast:      make(chan *model.WebSocketEvent, 4096),
 		stop:           make(chan string),
 		invalidateUser: make(chan string),
+		replayBuffers:  make(map[string]*replayBuffer),
 	}
 }
 
@@ -48,6 +51,17 @@ func TotalWebsocketConnections() int {
 	return count
 }
 
+// WebsocketConnectionsPerHub returns the number of connections held by each hub. Like TotalWebsocketConnections,
+// this is racy and should only be used for reporting.
+func WebsocketConnectionsPerHub() []int {
+	counts := make([]int, len(hubs))
+	for i, hub := range hubs {
+		counts[i] = len(hub.connections)
+	}
+
+	return counts
+}
+
 func HubStart() {
 	l4g.Info(utils.T("api.web_hub.start.starting.debug"), runtime.NumCPU()*2)
 
@@ -94,6 +108,9 @@ func Publish(message *model.WebSocketEvent) {
 	if einterfaces.GetClusterInterface() != nil {
 		einterfaces.GetClusterInterface().Publish(message)
 	}
+
+	// Only the server that the event happened on sends it to subscriptions
+	go HandleEventSubscriptions(message)
 }
 
 func PublishSkipClusterSend(message *model.WebSocketEvent) {
@@ -155,10 +172,6 @@ func InvalidateWebConnSessionCacheForUser(userId string) {
 
 func (h *Hub) Register(webConn *WebConn) {
 	h.register <- webConn
-
-	if webConn.IsAuthenticated() {
-		webConn.SendHello()
-	}
 }
 
 func (h *Hub) Unregister(webConn *WebConn) {
@@ -179,18 +192,129 @@ func (h *Hub) Stop() {
 	h.stop <- "all"
 }
 
+func (h *Hub) getReplayBuffer(userId string) *replayBuffer {
+	buffer, ok := h.replayBuffers[userId]
+	if !ok {
+		buffer = newReplayBuffer()
+		h.replayBuffers[userId] = buffer
+	}
+
+	return buffer
+}
+
+// sendEvent stamps a copy of the event with the connection's next sequence number and queues it to be sent. The event
+// is kept so that it can be replayed even if it couldn't be queued because the connection has fallen behind.
+func (h *Hub) sendEvent(webCon *WebConn, msg *model.WebSocketEvent) bool {
+	sequenced := h.sequenceEvent(webCon, msg)
+
+	select {
+	case webCon.Send <- sequenced:
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *Hub) sequenceEvent(webCon *WebConn, msg *model.WebSocketEvent) *model.WebSocketEvent {
+	sequenced := msg.CopyWithSequence(webCon.Sequence)
+	webCon.Sequence++
+
+	h.getReplayBuffer(webCon.UserId).add(webCon.ConnectionId, sequenced)
+
+	return sequenced
+}
+
+// detach keeps a closed connection around so that the client can resume it by reconnecting.
+func (h *Hub) detach(webCon *WebConn) {
+	if len(webCon.UserId) == 0 {
+		return
+	}
+
+	h.getReplayBuffer(webCon.UserId).detached[webCon.ConnectionId] = &detachedWebConn{
+		webConn:    webCon,
+		sequence:   webCon.Sequence,
+		detachedAt: model.GetMillis(),
+	}
+}
+
+// resume continues a connection that the client had open before reconnecting by sending it any events that it missed.
+// If they can't all be sent, the client is told to reload its data instead.
+func (h *Hub) resume(webCon *WebConn) {
+	connectionId := webCon.resumeConnectionId
+	if len(connectionId) == 0 {
+		return
+	}
+
+	// The client may have given up on a connection before the server noticed that it was gone
+	for other := range h.connections {
+		if other.UserId == webCon.UserId && other.ConnectionId == connectionId {
+			delete(h.connections, other)
+			close(other.Send)
+			h.detach(other)
+		}
+	}
+
+	buffer := h.getReplayBuffer(webCon.UserId)
+
+	var missed []*model.WebSocketEvent
+	detached, ok := buffer.detached[connectionId]
+	if ok {
+		missed, ok = buffer.getMissedEvents(connectionId, webCon.resumeSequence, detached.webConn.Sequence)
+	}
+
+	if !ok {
+		l4g.Debug(fmt.Sprintf("webhub.resume: unable to resume connection, resync required userId=%v", webCon.UserId))
+		h.sendEvent(webCon, model.NewWebSocketEvent(model.WEBSOCKET_EVENT_RESYNC_REQUIRED, "", "", webCon.UserId, nil))
+		return
+	}
+
+	delete(buffer.detached, connectionId)
+
+	webCon.ConnectionId = connectionId
+	webCon.Sequence = detached.webConn.Sequence
+
+	for _, msg := range missed {
+		webCon.Send <- msg
+	}
+}
+
+func (h *Hub) removeExpiredReplayBuffers() {
+	activeUsers := make(map[string]bool)
+	for webCon := range h.connections {
+		activeUsers[webCon.UserId] = true
+	}
+
+	now := model.GetMillis()
+	for userId, buffer := range h.replayBuffers {
+		buffer.removeExpired(now)
+
+		if len(buffer.detached) == 0 && !activeUsers[userId] {
+			delete(h.replayBuffers, userId)
+		}
+	}
+}
+
 func (h *Hub) Start() {
 	go func() {
+		replayTicker := time.NewTicker(time.Minute)
+		defer replayTicker.Stop()
+
 		for {
 			select {
 			case webCon := <-h.register:
+				h.resume(webCon)
 				h.connections[webCon] = true
 
+				if webCon.IsAuthenticated() {
+					h.sendEvent(webCon, webCon.NewHelloEvent())
+				}
+
 			case webCon := <-h.unregister:
 				userId := webCon.UserId
 				if _, ok := h.connections[webCon]; ok {
 					delete(h.connections, webCon)
 					close(webCon.Send)
+					h.detach(webCon)
 				}
 
 				if len(userId) == 0 {
@@ -216,19 +340,38 @@ func (h *Hub) Start() {
 					}
 				}
 
+				if buffer, ok := h.replayBuffers[userId]; ok {
+					for _, detached := range buffer.detached {
+						detached.webConn.InvalidateCache()
+					}
+				}
+
 			case msg := <-h.broadcast:
 				for webCon := range h.connections {
 					if webCon.ShouldSendEvent(msg) {
-						select {
-						case webCon.Send <- msg:
-						default:
+						if !h.sendEvent(webCon, msg) {
 							l4g.Error(fmt.Sprintf("webhub.broadcast: cannot send, closing websocket for userId=%v", webCon.UserId))
 							close(webCon.Send)
 							delete(h.connections, webCon)
+							h.detach(webCon)
 						}
 					}
 				}
 
+				for _, buffer := range h.replayBuffers {
+					for id, detached := range buffer.detached {
+						if !detached.webConn.IsAuthenticated() {
+							// Events can't be tracked for a connection without a session, so it can't be resumed
+							delete(buffer.detached, id)
+						} else if detached.webConn.ShouldSendEvent(msg) {
+							h.sequenceEvent(detached.webConn, msg)
+						}
+					}
+				}
+
+			case <-replayTicker.C:
+				h.removeExpiredReplayBuffers()
+
 			case <-h.stop:
 				for webCon := range h.connections {
 					webCon.WebSocket.Close()
@@ -0,0 +1,84 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	// Kept below the size of a WebConn's send channel so that every missed event can be queued at once when a
+	// connection is resumed
+	WEBSOCKET_REPLAY_BUFFER_SIZE   = 128
+	WEBSOCKET_REPLAY_BUFFER_EXPIRY = 10 * time.Minute
+)
+
+type replayEvent struct {
+	connectionId string
+	event        *model.WebSocketEvent
+}
+
+// detachedWebConn is a connection that has been closed but that the client may still resume. Events keep being
+// sequenced for it while it's detached so that they can be replayed once the client reconnects.
+type detachedWebConn struct {
+	webConn    *WebConn
+	sequence   int64 // the connection's next sequence number when it was closed
+	detachedAt int64
+}
+
+// replayBuffer holds the most recent events sent to each of a user's connections along with the connections that
+// have been closed but can still be resumed.
+type replayBuffer struct {
+	events   []*replayEvent
+	detached map[string]*detachedWebConn
+}
+
+func newReplayBuffer() *replayBuffer {
+	return &replayBuffer{
+		events:   make([]*replayEvent, 0, WEBSOCKET_REPLAY_BUFFER_SIZE),
+		detached: make(map[string]*detachedWebConn),
+	}
+}
+
+func (rb *replayBuffer) add(connectionId string, event *model.WebSocketEvent) {
+	if len(rb.events) >= WEBSOCKET_REPLAY_BUFFER_SIZE {
+		rb.events = append(rb.events[:0], rb.events[1:]...)
+	}
+
+	rb.events = append(rb.events, &replayEvent{connectionId: connectionId, event: event})
+}
+
+// getMissedEvents returns the events sent to a connection after the given sequence number up to, but not including,
+// nextSequence. It returns false if some of those events have already been dropped from the buffer.
+func (rb *replayBuffer) getMissedEvents(connectionId string, lastSequence int64, nextSequence int64) ([]*model.WebSocketEvent, bool) {
+	if lastSequence >= nextSequence {
+		return nil, false
+	}
+
+	missed := []*model.WebSocketEvent{}
+	for _, e := range rb.events {
+		if e.connectionId == connectionId && e.event.Sequence > lastSequence {
+			missed = append(missed, e.event)
+		}
+	}
+
+	if int64(len(missed)) != nextSequence-lastSequence-1 {
+		return nil, false
+	}
+
+	return missed, true
+}
+
+// removeExpired forgets about detached connections that can no longer be resumed, either because they were closed too
+// long ago or because more events have been sent to them than can be replayed.
+func (rb *replayBuffer) removeExpired(now int64) {
+	for id, detached := range rb.detached {
+		if now-detached.detachedAt > int64(WEBSOCKET_REPLAY_BUFFER_EXPIRY/time.Millisecond) ||
+			detached.webConn.Sequence-detached.sequence > WEBSOCKET_REPLAY_BUFFER_SIZE {
+			delete(rb.detached, id)
+		}
+	}
+}
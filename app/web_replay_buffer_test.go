@@ -0,0 +1,99 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestReplayBufferGetMissedEvents(t *testing.T) {
+	buffer := newReplayBuffer()
+
+	connectionId := model.NewId()
+	for i := int64(0); i < 5; i++ {
+		buffer.add(connectionId, &model.WebSocketEvent{Event: "event", Sequence: i})
+		buffer.add(model.NewId(), &model.WebSocketEvent{Event: "other", Sequence: i})
+	}
+
+	if missed, ok := buffer.getMissedEvents(connectionId, 2, 5); !ok {
+		t.Fatal("should've been able to replay the events")
+	} else if len(missed) != 2 || missed[0].Sequence != 3 || missed[1].Sequence != 4 {
+		t.Fatal("returned the wrong events")
+	}
+
+	if missed, ok := buffer.getMissedEvents(connectionId, 4, 5); !ok || len(missed) != 0 {
+		t.Fatal("shouldn't have missed anything")
+	}
+
+	if _, ok := buffer.getMissedEvents(connectionId, 5, 5); ok {
+		t.Fatal("shouldn't accept a sequence number that hasn't been sent yet")
+	}
+
+	for i := int64(5); i < WEBSOCKET_REPLAY_BUFFER_SIZE+5; i++ {
+		buffer.add(connectionId, &model.WebSocketEvent{Event: "event", Sequence: i})
+	}
+
+	if len(buffer.events) != WEBSOCKET_REPLAY_BUFFER_SIZE {
+		t.Fatal("should've limited the size of the buffer")
+	}
+
+	if _, ok := buffer.getMissedEvents(connectionId, 2, WEBSOCKET_REPLAY_BUFFER_SIZE+5); ok {
+		t.Fatal("shouldn't be able to replay events that have been dropped")
+	}
+}
+
+func TestHubResume(t *testing.T) {
+	h := NewWebHub()
+	userId := model.NewId()
+
+	webCon := &WebConn{Send: make(chan model.WebSocketMessage, 256), UserId: userId, ConnectionId: model.NewId()}
+	h.connections[webCon] = true
+
+	h.sendEvent(webCon, model.NewWebSocketEvent("first", "", "", userId, nil))
+	<-webCon.Send
+
+	delete(h.connections, webCon)
+	h.detach(webCon)
+
+	// Events keep being sequenced for the connection while it's detached
+	h.sequenceEvent(webCon, model.NewWebSocketEvent("second", "", "", userId, nil))
+
+	resumed := &WebConn{
+		Send:               make(chan model.WebSocketMessage, 256),
+		UserId:             userId,
+		ConnectionId:       model.NewId(),
+		resumeConnectionId: webCon.ConnectionId,
+		resumeSequence:     0,
+	}
+	h.resume(resumed)
+
+	if resumed.ConnectionId != webCon.ConnectionId || resumed.Sequence != 2 {
+		t.Fatal("should've continued the previous connection")
+	}
+
+	if msg := (<-resumed.Send).(*model.WebSocketEvent); msg.Event != "second" || msg.Sequence != 1 {
+		t.Fatal("should've replayed the missed event")
+	}
+
+	if _, ok := h.replayBuffers[userId].detached[webCon.ConnectionId]; ok {
+		t.Fatal("shouldn't be able to resume the same connection twice")
+	}
+
+	other := &WebConn{
+		Send:               make(chan model.WebSocketMessage, 256),
+		UserId:             model.NewId(),
+		ConnectionId:       model.NewId(),
+		resumeConnectionId: resumed.ConnectionId,
+		resumeSequence:     1,
+	}
+	h.resume(other)
+
+	if other.ConnectionId == resumed.ConnectionId {
+		t.Fatal("shouldn't be able to resume another user's connection")
+	} else if msg := (<-other.Send).(*model.WebSocketEvent); msg.Event != model.WEBSOCKET_EVENT_RESYNC_REQUIRED {
+		t.Fatal("should've required a resync")
+	}
+}
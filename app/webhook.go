@@ -4,10 +4,6 @@
 package app
 
 import (
-	"crypto/tls"
-	"io"
-	"io/ioutil"
-	"net/http"
 	"regexp"
 	"strings"
 
@@ -61,58 +57,44 @@ func handleWebhookEvents(post *model.Post, team *model.Team, channel *model.Chan
 	}
 
 	for _, hook := range relevantHooks {
-		go func(hook *model.OutgoingWebhook) {
-			payload := &model.OutgoingWebhookPayload{
-				Token:       hook.Token,
-				TeamId:      hook.TeamId,
-				TeamDomain:  team.Name,
-				ChannelId:   post.ChannelId,
-				ChannelName: channel.Name,
-				Timestamp:   post.CreateAt,
-				UserId:      post.UserId,
-				UserName:    user.Username,
+		payload := &model.OutgoingWebhookPayload{
+			Token:       hook.Token,
+			TeamId:      hook.TeamId,
+			TeamDomain:  team.Name,
+			ChannelId:   post.ChannelId,
+			ChannelName: channel.Name,
+			Timestamp:   post.CreateAt,
+			UserId:      post.UserId,
+			UserName:    user.Username,
+			PostId:      post.Id,
+			Text:        post.Message,
+			TriggerWord: firstWord,
+		}
+
+		var body string
+		var contentType string
+		if hook.ContentType == "application/json" {
+			body = payload.ToJSON()
+			contentType = "application/json"
+		} else {
+			body = payload.ToFormValues()
+			contentType = "application/x-www-form-urlencoded"
+		}
+
+		for _, url := range hook.CallbackURLs {
+			delivery := &model.OutgoingWebhookDelivery{
+				HookId:      hook.Id,
 				PostId:      post.Id,
-				Text:        post.Message,
-				TriggerWord: firstWord,
-			}
-			var body io.Reader
-			var contentType string
-			if hook.ContentType == "application/json" {
-				body = strings.NewReader(payload.ToJSON())
-				contentType = "application/json"
-			} else {
-				body = strings.NewReader(payload.ToFormValues())
-				contentType = "application/x-www-form-urlencoded"
-			}
-			tr := &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
-			}
-			client := &http.Client{Transport: tr}
-
-			for _, url := range hook.CallbackURLs {
-				go func(url string) {
-					req, _ := http.NewRequest("POST", url, body)
-					req.Header.Set("Content-Type", contentType)
-					req.Header.Set("Accept", "application/json")
-					if resp, err := client.Do(req); err != nil {
-						l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.event_post.error"), err.Error())
-					} else {
-						defer func() {
-							ioutil.ReadAll(resp.Body)
-							resp.Body.Close()
-						}()
-						respProps := model.MapFromJson(resp.Body)
-
-						if text, ok := respProps["text"]; ok {
-							if _, err := CreateWebhookPost(hook.CreatorId, hook.TeamId, post.ChannelId, text, respProps["username"], respProps["icon_url"], post.Props, post.Type); err != nil {
-								l4g.Error(utils.T("api.post.handle_webhook_events_and_forget.create_post.error"), err)
-							}
-						}
-					}
-				}(url)
+				ChannelId:   post.ChannelId,
+				URL:         url,
+				ContentType: contentType,
+				Payload:     body,
 			}
 
-		}(hook)
+			if err := EnqueueOutgoingWebhookDelivery(delivery); err != nil {
+				l4g.Error(utils.T("app.outgoing_webhook_delivery.enqueue.error"), err.Error())
+			}
+		}
 	}
 
 	return nil
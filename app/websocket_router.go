@@ -61,7 +61,9 @@ func (wr *WebSocketRouter) ServeWebSocket(conn *WebConn, r *model.WebSocketReque
 			resp := model.NewWebSocketResponse(model.STATUS_OK, r.Seq, nil)
 			resp.DoPreComputeJson()
 			conn.Send <- resp
-			conn.SendHello()
+
+			// Connections aren't registered until they're authenticated since they're grouped by user
+			HubRegister(conn)
 		}
 
 		return
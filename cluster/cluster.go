@@ -0,0 +1,417 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	// Nodes that stopped without removing themselves are forgotten after this long
+	DISCOVERY_CLEANUP_AGE = 24 * 60 * 60 * 1000
+)
+
+// HttpCluster keeps the servers that share a database in sync by sending messages directly between them over HTTP.
+// The servers find each other through the ClusterDiscovery table, and each server only communicates with the others
+// while ClusterSettings.Enable is true.
+type HttpCluster struct {
+	node      *Node
+	nodeMutex sync.RWMutex
+}
+
+func init() {
+	einterfaces.RegisterClusterInterface(&HttpCluster{})
+}
+
+func (c *HttpCluster) getNode() *Node {
+	c.nodeMutex.RLock()
+	defer c.nodeMutex.RUnlock()
+
+	return c.node
+}
+
+func (c *HttpCluster) StartInterNodeCommunication() {
+	if !*utils.Cfg.ClusterSettings.Enable {
+		return
+	}
+
+	discovery := &SqlDiscovery{Store: app.Srv.Store}
+	if err := discovery.Cleanup(model.GetMillis() - DISCOVERY_CLEANUP_AGE); err != nil {
+		l4g.Error(utils.T("ent.cluster.cleanup.error"), err.Error())
+	}
+
+	secret, err := getClusterSecret()
+	if err != nil {
+		l4g.Critical(utils.T("ent.cluster.start.critical"), err.Error())
+		return
+	}
+
+	tlsConfig, tlsErr := getClusterTLSConfig()
+	if tlsErr != nil {
+		l4g.Critical(utils.T("ent.cluster.start.critical"), tlsErr.Error())
+		return
+	}
+
+	node := NewNode(*utils.Cfg.ClusterSettings.InterNodeListenAddress, *utils.Cfg.ClusterSettings.AdvertiseAddress, discovery, secret)
+	node.TLSConfig = tlsConfig
+	RegisterHandlers(node)
+
+	if err := node.Start(); err != nil {
+		l4g.Critical(utils.T("ent.cluster.start.critical"), err.Error())
+		return
+	}
+
+	c.nodeMutex.Lock()
+	c.node = node
+	c.nodeMutex.Unlock()
+}
+
+func (c *HttpCluster) StopInterNodeCommunication() {
+	c.nodeMutex.Lock()
+	node := c.node
+	c.node = nil
+	c.nodeMutex.Unlock()
+
+	if node != nil {
+		node.Stop()
+	}
+}
+
+// getClusterSecret returns the key that every node uses to sign its messages, creating it if this is the first node
+// to start.
+func getClusterSecret() (string, *model.AppError) {
+	if result := <-app.Srv.Store.System().GetByName(model.SYSTEM_CLUSTER_SECRET); result.Err == nil {
+		return result.Data.(*model.System).Value, nil
+	}
+
+	secret := &model.System{Name: model.SYSTEM_CLUSTER_SECRET, Value: model.NewRandomString(64)}
+	if result := <-app.Srv.Store.System().Save(secret); result.Err == nil {
+		return secret.Value, nil
+	}
+
+	// Another node may have created the secret at the same time
+	if result := <-app.Srv.Store.System().GetByName(model.SYSTEM_CLUSTER_SECRET); result.Err != nil {
+		return "", result.Err
+	} else {
+		return result.Data.(*model.System).Value, nil
+	}
+}
+
+// getClusterTLSConfig returns the TLS configuration used between nodes, or nil if ClusterSettings.UseTLS is false. The
+// other nodes' certificates are verified using TLSCAFile if it's set or the system's certificate authorities if not.
+func getClusterTLSConfig() (*tls.Config, error) {
+	settings := utils.Cfg.ClusterSettings
+	if !*settings.UseTLS {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(*settings.TLSCertFile, *settings.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if *settings.TLSCAFile != "" {
+		data, err := ioutil.ReadFile(*settings.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.New("no certificates found in " + *settings.TLSCAFile)
+		}
+	}
+
+	return config, nil
+}
+
+// RegisterHandlers sets up a node to apply the messages sent by the other nodes to this server.
+func RegisterHandlers(node *Node) {
+	node.RegisterHandler(model.CLUSTER_EVENT_PUBLISH, func(msg *model.ClusterMessage) string {
+		if event := model.WebSocketEventFromJson(strings.NewReader(msg.Data)); event != nil {
+			app.PublishSkipClusterSend(event)
+		}
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_UPDATE_STATUS, func(msg *model.ClusterMessage) string {
+		if status := model.StatusFromJson(strings.NewReader(msg.Data)); status != nil {
+			app.AddStatusCacheSkipClusterSend(status)
+		}
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_INVALIDATE_ALL_CACHES, func(msg *model.ClusterMessage) string {
+		app.InvalidateAllCaches()
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER, func(msg *model.ClusterMessage) string {
+		app.InvalidateCacheForUserSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL, func(msg *model.ClusterMessage) string {
+		app.InvalidateCacheForChannelSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL_POSTS, func(msg *model.ClusterMessage) string {
+		app.InvalidateCacheForChannelPostsSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_CLEAR_SESSION_CACHE_FOR_USER, func(msg *model.ClusterMessage) string {
+		app.RemoveAllSessionsForUserIdSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_CONFIG_CHANGED, handleConfigChanged)
+
+	node.RegisterHandler(model.CLUSTER_EVENT_INDEX_POST, func(msg *model.ClusterMessage) string {
+		if post := model.PostFromJson(strings.NewReader(msg.Data)); post != nil {
+			app.IndexPostSkipClusterSend(post)
+		}
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_DELETE_POST_FROM_INDEX, func(msg *model.ClusterMessage) string {
+		app.DeletePostFromIndexSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_INDEX_CHANNEL, func(msg *model.ClusterMessage) string {
+		if channel := model.ChannelFromJson(strings.NewReader(msg.Data)); channel != nil {
+			app.IndexChannelSkipClusterSend(channel)
+		}
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_DELETE_CHANNEL_FROM_INDEX, func(msg *model.ClusterMessage) string {
+		app.DeleteChannelFromIndexSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_INDEX_USER, func(msg *model.ClusterMessage) string {
+		app.IndexUserByIdSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_DELETE_USER_FROM_INDEX, func(msg *model.ClusterMessage) string {
+		app.DeleteUserFromIndexSkipClusterSend(msg.Data)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_PLUGIN_STATE_CHANGED, func(msg *model.ClusterMessage) string {
+		data := model.MapFromJson(strings.NewReader(msg.Data))
+
+		var state *model.PluginState
+		if enable, ok := data["enable"]; ok {
+			state = &model.PluginState{Enable: enable == "true"}
+		}
+
+		app.SavePluginStateSkipClusterSend(data["plugin_id"], state)
+		return ""
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_GET_CLUSTER_STATS, func(msg *model.ClusterMessage) string {
+		stats := &model.ClusterStats{
+			Id:                        node.Id,
+			TotalWebsocketConnections: app.TotalWebsocketConnections(),
+			TotalReadDbConnections:    app.Srv.Store.TotalReadDbConnections(),
+			TotalMasterDbConnections:  app.Srv.Store.TotalMasterDbConnections(),
+		}
+		return stats.ToJson()
+	})
+
+	node.RegisterHandler(model.CLUSTER_EVENT_GET_LOGS, func(msg *model.ClusterMessage) string {
+		lines, err := app.GetLogs()
+		if err != nil {
+			l4g.Error(err.Error())
+			return model.ArrayToJson([]string{})
+		}
+		return model.ArrayToJson(lines)
+	})
+}
+
+// handleConfigChanged saves the configuration that was changed on another node and restarts the same things as saving
+// it on this node would. Secrets that weren't sent are kept from this node's configuration. Each node keeps its own
+// cluster settings since those are what it uses to find and communicate with the rest of the cluster.
+func handleConfigChanged(msg *model.ClusterMessage) string {
+	cfg := model.ConfigFromJson(strings.NewReader(msg.Data))
+	if cfg == nil {
+		return ""
+	}
+
+	cfg.SetDefaults()
+	utils.Desanitize(cfg)
+	cfg.ClusterSettings = utils.Cfg.ClusterSettings
+
+	l4g.Info(utils.T("ent.cluster.config_received.info"))
+
+	if err := utils.SaveConfig(utils.CfgFileName, cfg); err != nil {
+		l4g.Error(utils.T("ent.cluster.config_received.error"), err.Error())
+		return ""
+	}
+
+	utils.LoadConfig(utils.CfgFileName)
+
+	if einterfaces.GetMetricsInterface() != nil {
+		if *utils.Cfg.MetricsSettings.Enable {
+			einterfaces.GetMetricsInterface().StartServer()
+		} else {
+			einterfaces.GetMetricsInterface().StopServer()
+		}
+	}
+
+	// start/restart email batching job if necessary
+	app.InitEmailBatching()
+
+	// start or stop plugins and tell the running ones that the config has changed
+	app.SyncPlugins()
+
+	return ""
+}
+
+func (c *HttpCluster) GetClusterInfos() []*model.ClusterInfo {
+	if node := c.getNode(); node != nil {
+		return node.GetClusterInfos()
+	}
+
+	return []*model.ClusterInfo{}
+}
+
+func (c *HttpCluster) GetClusterStats() ([]*model.ClusterStats, *model.AppError) {
+	stats := []*model.ClusterStats{}
+
+	if node := c.getNode(); node != nil {
+		for _, response := range node.Request(&model.ClusterMessage{Event: model.CLUSTER_EVENT_GET_CLUSTER_STATS}) {
+			if stat := model.ClusterStatsFromJson(strings.NewReader(response)); stat != nil {
+				stats = append(stats, stat)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+func (c *HttpCluster) GetLogs() ([]string, *model.AppError) {
+	lines := []string{}
+
+	if node := c.getNode(); node != nil {
+		for _, response := range node.Request(&model.ClusterMessage{Event: model.CLUSTER_EVENT_GET_LOGS}) {
+			lines = append(lines, model.ArrayFromJson(strings.NewReader(response))...)
+		}
+	}
+
+	return lines, nil
+}
+
+func (c *HttpCluster) GetClusterId() string {
+	if node := c.getNode(); node != nil {
+		return node.Id
+	}
+
+	return ""
+}
+
+func (c *HttpCluster) broadcast(event string, data string) {
+	if node := c.getNode(); node != nil {
+		node.Broadcast(&model.ClusterMessage{Event: event, Data: data})
+	}
+}
+
+func (c *HttpCluster) RemoveAllSessionsForUserId(userId string) {
+	c.broadcast(model.CLUSTER_EVENT_CLEAR_SESSION_CACHE_FOR_USER, userId)
+}
+
+func (c *HttpCluster) InvalidateCacheForUser(userId string) {
+	c.broadcast(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER, userId)
+}
+
+func (c *HttpCluster) InvalidateCacheForChannel(channelId string) {
+	c.broadcast(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL, channelId)
+}
+
+func (c *HttpCluster) InvalidateCacheForChannelPosts(channelId string) {
+	c.broadcast(model.CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL_POSTS, channelId)
+}
+
+func (c *HttpCluster) Publish(event *model.WebSocketEvent) {
+	c.broadcast(model.CLUSTER_EVENT_PUBLISH, event.ToJson())
+}
+
+func (c *HttpCluster) UpdateStatus(status *model.Status) {
+	c.broadcast(model.CLUSTER_EVENT_UPDATE_STATUS, status.ToJson())
+}
+
+func (c *HttpCluster) ConfigChanged(previousConfig *model.Config, newConfig *model.Config, sendToOtherServer bool) *model.AppError {
+	if sendToOtherServer {
+		cfg := newConfig
+
+		// Secrets are only sent to the other nodes when the connection to them is encrypted
+		if node := c.getNode(); node == nil || node.TLSConfig == nil {
+			cfg = model.ConfigFromJson(strings.NewReader(newConfig.ToJson()))
+			cfg.Sanitize()
+		}
+
+		c.broadcast(model.CLUSTER_EVENT_CONFIG_CHANGED, cfg.ToJson())
+	}
+
+	return nil
+}
+
+func (c *HttpCluster) InvalidateAllCaches() *model.AppError {
+	c.broadcast(model.CLUSTER_EVENT_INVALIDATE_ALL_CACHES, "")
+	return nil
+}
+
+func (c *HttpCluster) IndexPost(post *model.Post) {
+	c.broadcast(model.CLUSTER_EVENT_INDEX_POST, post.ToJson())
+}
+
+func (c *HttpCluster) DeletePostFromIndex(postId string) {
+	c.broadcast(model.CLUSTER_EVENT_DELETE_POST_FROM_INDEX, postId)
+}
+
+func (c *HttpCluster) IndexChannel(channel *model.Channel) {
+	c.broadcast(model.CLUSTER_EVENT_INDEX_CHANNEL, channel.ToJson())
+}
+
+func (c *HttpCluster) DeleteChannelFromIndex(channelId string) {
+	c.broadcast(model.CLUSTER_EVENT_DELETE_CHANNEL_FROM_INDEX, channelId)
+}
+
+func (c *HttpCluster) IndexUser(userId string) {
+	c.broadcast(model.CLUSTER_EVENT_INDEX_USER, userId)
+}
+
+func (c *HttpCluster) DeleteUserFromIndex(userId string) {
+	c.broadcast(model.CLUSTER_EVENT_DELETE_USER_FROM_INDEX, userId)
+}
+
+// PluginStateChanged tells the other nodes that a plugin was enabled or disabled, or that its state was removed if
+// state is nil, so that they start or stop it too.
+func (c *HttpCluster) PluginStateChanged(pluginId string, state *model.PluginState) {
+	data := map[string]string{"plugin_id": pluginId}
+	if state != nil {
+		data["enable"] = strconv.FormatBool(state.Enable)
+	}
+
+	c.broadcast(model.CLUSTER_EVENT_PLUGIN_STATE_CHANGED, model.MapToJson(data))
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/store"
+)
+
+// SqlDiscovery finds the other nodes in the cluster using the database that they all share.
+type SqlDiscovery struct {
+	Store store.Store
+}
+
+func (d *SqlDiscovery) Heartbeat(node *model.ClusterDiscovery) *model.AppError {
+	if result := <-d.Store.ClusterDiscovery().SaveOrUpdate(node); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (d *SqlDiscovery) GetAliveNodes(aliveSince int64) ([]*model.ClusterDiscovery, *model.AppError) {
+	if result := <-d.Store.ClusterDiscovery().GetAll(aliveSince); result.Err != nil {
+		return nil, result.Err
+	} else {
+		return result.Data.([]*model.ClusterDiscovery), nil
+	}
+}
+
+func (d *SqlDiscovery) Remove(id string) *model.AppError {
+	if result := <-d.Store.ClusterDiscovery().Delete(id); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+func (d *SqlDiscovery) Cleanup(before int64) *model.AppError {
+	if result := <-d.Store.ClusterDiscovery().Cleanup(before); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
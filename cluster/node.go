@@ -0,0 +1,487 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	MESSAGE_PATH               = "/cluster/v1/message"
+	HEADER_SENDER_ID           = "X-Cluster-Sender-Id"
+	HEADER_SIGNATURE           = "X-Cluster-Signature"
+	HEADER_TIMESTAMP           = "X-Cluster-Timestamp"
+	HEADER_MESSAGE_ID          = "X-Cluster-Message-Id"
+	DEFAULT_HEARTBEAT_INTERVAL = 5 * time.Second
+	MISSED_HEARTBEATS_TIMEOUT  = 3 // a node is considered dead after missing this many heartbeats
+	PEER_QUEUE_SIZE            = 4096
+	REQUEST_TIMEOUT            = 10 * time.Second
+	MAX_MESSAGE_SIZE           = 10 * 1024 * 1024
+	MAX_MESSAGE_AGE            = 60 * 1000 // messages sent more than this many milliseconds away from now are rejected
+)
+
+// MessageHandler handles a message sent by another node. The returned string is sent back to nodes that are waiting
+// for a response to the message.
+type MessageHandler func(msg *model.ClusterMessage) string
+
+// Discovery is how a node advertises itself and finds the other nodes in the cluster.
+type Discovery interface {
+	Heartbeat(node *model.ClusterDiscovery) *model.AppError
+	GetAliveNodes(aliveSince int64) ([]*model.ClusterDiscovery, *model.AppError)
+	Remove(id string) *model.AppError
+}
+
+type peer struct {
+	info     *model.ClusterDiscovery
+	lastPing int64 // the last time the peer's heartbeat was seen or a message was sent to it successfully
+	queue    chan *model.ClusterMessage
+	stop     chan bool
+}
+
+// Node is a single server in a cluster. It listens for messages from the other nodes over HTTP and keeps track of
+// them through a Discovery. Every message is signed with a secret shared by all of the nodes along with when it was
+// sent and a unique id so that it can't be received more than once.
+type Node struct {
+	Id                string
+	ListenAddress     string
+	Url               string
+	HeartbeatInterval time.Duration
+
+	// TLSConfig, if set, is used to encrypt the messages between nodes. Every node in the cluster must use it.
+	TLSConfig *tls.Config
+
+	discovery Discovery
+	secret    []byte
+	handlers  map[string]MessageHandler
+	client    *http.Client
+
+	server   *http.Server
+	listener net.Listener
+
+	peers      map[string]*peer
+	peersMutex sync.RWMutex
+
+	receivedIds      map[string]int64 // the ids of recently received messages mapped to when they were sent
+	receivedIdsMutex sync.Mutex
+	lastPrunedAt     int64
+
+	stop    chan bool
+	stopped chan bool
+}
+
+// NewNode creates a node that will listen on the given address. If url is empty, the node advertises itself using
+// the machine's hostname and the port that it's listening on.
+func NewNode(listenAddress string, url string, discovery Discovery, secret string) *Node {
+	return &Node{
+		Id:                model.NewId(),
+		ListenAddress:     listenAddress,
+		Url:               url,
+		HeartbeatInterval: DEFAULT_HEARTBEAT_INTERVAL,
+		discovery:         discovery,
+		secret:            []byte(secret),
+		handlers:          make(map[string]MessageHandler),
+		client:            &http.Client{Timeout: REQUEST_TIMEOUT},
+		peers:             make(map[string]*peer),
+		receivedIds:       make(map[string]int64),
+	}
+}
+
+// RegisterHandler sets the function called when a message for the given event is received. Handlers must be
+// registered before the node is started.
+func (n *Node) RegisterHandler(event string, handler MessageHandler) {
+	n.handlers[event] = handler
+}
+
+func (n *Node) Start() error {
+	listener, err := net.Listen("tcp", n.ListenAddress)
+	if err != nil {
+		return err
+	}
+
+	scheme := "http"
+	if n.TLSConfig != nil {
+		scheme = "https"
+		listener = tls.NewListener(listener, n.TLSConfig)
+		n.client = &http.Client{Timeout: REQUEST_TIMEOUT, Transport: &http.Transport{TLSClientConfig: n.TLSConfig}}
+	}
+
+	n.listener = listener
+
+	if n.Url == "" {
+		n.Url = getDefaultUrl(listener.Addr(), scheme)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(MESSAGE_PATH, n.handleMessage)
+	n.server = &http.Server{Handler: mux}
+
+	go func() {
+		if err := n.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			l4g.Error(utils.T("ent.cluster.serve.error"), err.Error())
+		}
+	}()
+
+	n.stop = make(chan bool)
+	n.stopped = make(chan bool)
+
+	n.heartbeat()
+	go n.runHeartbeats()
+
+	hostname, _ := os.Hostname()
+	l4g.Info(utils.T("ent.cluster.starting.info"), n.ListenAddress, hostname, n.Id)
+
+	return nil
+}
+
+func (n *Node) Stop() {
+	hostname, _ := os.Hostname()
+	l4g.Info(utils.T("ent.cluster.stopping.info"), n.ListenAddress, hostname, n.Id)
+
+	close(n.stop)
+	<-n.stopped
+
+	if err := n.discovery.Remove(n.Id); err != nil {
+		l4g.Error(utils.T("ent.cluster.remove.error"), err.Error())
+	}
+
+	n.server.Close()
+
+	n.peersMutex.Lock()
+	for id, p := range n.peers {
+		close(p.stop)
+		delete(n.peers, id)
+	}
+	n.peersMutex.Unlock()
+}
+
+func getDefaultUrl(addr net.Addr, scheme string) string {
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return scheme + "://" + addr.String()
+	}
+
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		if hostname, err := os.Hostname(); err == nil {
+			host = hostname
+		}
+	}
+
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+func (n *Node) runHeartbeats() {
+	ticker := time.NewTicker(n.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n.heartbeat()
+		case <-n.stop:
+			close(n.stopped)
+			return
+		}
+	}
+}
+
+// heartbeat tells the other nodes that this one is still alive and updates the list of nodes that messages are sent
+// to.
+func (n *Node) heartbeat() {
+	hostname, _ := os.Hostname()
+
+	if err := n.discovery.Heartbeat(&model.ClusterDiscovery{
+		Id:           n.Id,
+		Hostname:     hostname,
+		InterNodeUrl: n.Url,
+		Version:      model.CurrentVersion,
+		ConfigHash:   utils.CfgHash,
+	}); err != nil {
+		l4g.Error(utils.T("ent.cluster.heartbeat.error"), err.Error())
+		return
+	}
+
+	aliveSince := model.GetMillis() - int64(MISSED_HEARTBEATS_TIMEOUT*n.HeartbeatInterval/time.Millisecond)
+
+	nodes, err := n.discovery.GetAliveNodes(aliveSince)
+	if err != nil {
+		l4g.Error(utils.T("ent.cluster.heartbeat.error"), err.Error())
+		return
+	}
+
+	n.updatePeers(nodes)
+}
+
+func (n *Node) updatePeers(nodes []*model.ClusterDiscovery) {
+	n.peersMutex.Lock()
+	defer n.peersMutex.Unlock()
+
+	alive := make(map[string]bool)
+
+	for _, node := range nodes {
+		if node.Id == n.Id {
+			continue
+		}
+
+		alive[node.Id] = true
+
+		if p, ok := n.peers[node.Id]; ok {
+			p.updateLastPing(node.LastPingAt)
+			continue
+		}
+
+		p := &peer{
+			info:     node,
+			lastPing: node.LastPingAt,
+			queue:    make(chan *model.ClusterMessage, PEER_QUEUE_SIZE),
+			stop:     make(chan bool),
+		}
+		n.peers[node.Id] = p
+		go n.runPeer(p)
+
+		l4g.Info(utils.T("ent.cluster.peer_added.info"), node.Id, node.InterNodeUrl)
+	}
+
+	for id, p := range n.peers {
+		if !alive[id] {
+			close(p.stop)
+			delete(n.peers, id)
+
+			l4g.Info(utils.T("ent.cluster.peer_removed.info"), id, p.info.InterNodeUrl)
+		}
+	}
+}
+
+// runPeer sends the messages queued for a peer one at a time so that they're received in the order they were sent.
+func (n *Node) runPeer(p *peer) {
+	for {
+		select {
+		case msg := <-p.queue:
+			if _, err := n.send(p, msg); err != nil {
+				l4g.Warn(utils.T("ent.cluster.send.warn"), msg.Event, p.info.Id, err.Error())
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *peer) updateLastPing(lastPing int64) {
+	for {
+		old := atomic.LoadInt64(&p.lastPing)
+		if old >= lastPing || atomic.CompareAndSwapInt64(&p.lastPing, old, lastPing) {
+			return
+		}
+	}
+}
+
+func (n *Node) getPeers() []*peer {
+	n.peersMutex.RLock()
+	defer n.peersMutex.RUnlock()
+
+	peers := make([]*peer, 0, len(n.peers))
+	for _, p := range n.peers {
+		peers = append(peers, p)
+	}
+
+	return peers
+}
+
+// Broadcast queues a message to be sent to every other node without waiting for it to be received.
+func (n *Node) Broadcast(msg *model.ClusterMessage) {
+	for _, p := range n.getPeers() {
+		select {
+		case p.queue <- msg:
+		default:
+			l4g.Warn(utils.T("ent.cluster.queue_full.warn"), msg.Event, p.info.Id)
+		}
+	}
+}
+
+// Request sends a message to every other node and waits for their responses, which are returned by node id. Nodes
+// that fail to respond are left out.
+func (n *Node) Request(msg *model.ClusterMessage) map[string]string {
+	responses := make(map[string]string)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, p := range n.getPeers() {
+		wg.Add(1)
+
+		go func(p *peer) {
+			defer wg.Done()
+
+			if response, err := n.send(p, msg); err != nil {
+				l4g.Warn(utils.T("ent.cluster.send.warn"), msg.Event, p.info.Id, err.Error())
+			} else {
+				mutex.Lock()
+				responses[p.info.Id] = response
+				mutex.Unlock()
+			}
+		}(p)
+	}
+
+	wg.Wait()
+
+	return responses
+}
+
+// GetClusterInfos returns the other nodes in the cluster.
+func (n *Node) GetClusterInfos() []*model.ClusterInfo {
+	infos := []*model.ClusterInfo{}
+
+	for _, p := range n.getPeers() {
+		info := p.info.ToClusterInfo()
+		info.LastSuccessfulPing = atomic.LoadInt64(&p.lastPing)
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+func (n *Node) sign(senderId string, timestamp string, messageId string, body []byte) string {
+	mac := hmac.New(sha256.New, n.secret)
+	for _, part := range []string{senderId, timestamp, messageId} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newRequest creates a signed request that sends a message to the node at the given url.
+func (n *Node) newRequest(url string, msg *model.ClusterMessage) (*http.Request, error) {
+	body := []byte(msg.ToJson())
+
+	req, err := http.NewRequest("POST", url+MESSAGE_PATH, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := strconv.FormatInt(model.GetMillis(), 10)
+	messageId := model.NewId()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HEADER_SENDER_ID, n.Id)
+	req.Header.Set(HEADER_TIMESTAMP, timestamp)
+	req.Header.Set(HEADER_MESSAGE_ID, messageId)
+	req.Header.Set(HEADER_SIGNATURE, n.sign(n.Id, timestamp, messageId, body))
+
+	return req, nil
+}
+
+func (n *Node) send(p *peer, msg *model.ClusterMessage) (string, error) {
+	req, err := n.newRequest(p.info.InterNodeUrl, msg)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MAX_MESSAGE_SIZE))
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status_code=%v, body=%v", resp.StatusCode, string(data))
+	}
+
+	p.updateLastPing(model.GetMillis())
+
+	return string(data), nil
+}
+
+func (n *Node) handleMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, MAX_MESSAGE_SIZE))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	senderId := r.Header.Get(HEADER_SENDER_ID)
+	timestamp := r.Header.Get(HEADER_TIMESTAMP)
+	messageId := r.Header.Get(HEADER_MESSAGE_ID)
+	if !hmac.Equal([]byte(r.Header.Get(HEADER_SIGNATURE)), []byte(n.sign(senderId, timestamp, messageId, body))) {
+		l4g.Warn(utils.T("ent.cluster.invalid_signature.warn"), senderId, r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if sentAt, err := strconv.ParseInt(timestamp, 10, 64); err != nil || !n.markReceived(messageId, sentAt) {
+		l4g.Warn(utils.T("ent.cluster.replayed_message.warn"), senderId, r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	msg := model.ClusterMessageFromJson(bytes.NewReader(body))
+	if msg == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	handler, ok := n.handlers[msg.Event]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Write([]byte(handler(msg)))
+}
+
+// markReceived records that the message with the given id has been received. It returns false if the message was
+// already received or if it was sent too long ago for that to be known.
+func (n *Node) markReceived(messageId string, sentAt int64) bool {
+	now := model.GetMillis()
+	if len(messageId) == 0 || sentAt < now-MAX_MESSAGE_AGE || sentAt > now+MAX_MESSAGE_AGE {
+		return false
+	}
+
+	n.receivedIdsMutex.Lock()
+	defer n.receivedIdsMutex.Unlock()
+
+	// Messages old enough to be forgotten are rejected by their timestamps instead
+	if n.lastPrunedAt < now-MAX_MESSAGE_AGE {
+		for id, receivedSentAt := range n.receivedIds {
+			if receivedSentAt < now-MAX_MESSAGE_AGE {
+				delete(n.receivedIds, id)
+			}
+		}
+
+		n.lastPrunedAt = now
+	}
+
+	if _, ok := n.receivedIds[messageId]; ok {
+		return false
+	}
+
+	n.receivedIds[messageId] = sentAt
+	return true
+}
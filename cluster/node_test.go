@@ -0,0 +1,396 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+// memoryDiscovery lets nodes running in the same process find each other without a database.
+type memoryDiscovery struct {
+	nodes map[string]*model.ClusterDiscovery
+	mutex sync.Mutex
+}
+
+func newMemoryDiscovery() *memoryDiscovery {
+	return &memoryDiscovery{nodes: make(map[string]*model.ClusterDiscovery)}
+}
+
+func (d *memoryDiscovery) Heartbeat(node *model.ClusterDiscovery) *model.AppError {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	node.PreSave()
+	d.nodes[node.Id] = node
+	return nil
+}
+
+func (d *memoryDiscovery) GetAliveNodes(aliveSince int64) ([]*model.ClusterDiscovery, *model.AppError) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	nodes := []*model.ClusterDiscovery{}
+	for _, node := range d.nodes {
+		if node.LastPingAt >= aliveSince {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+func (d *memoryDiscovery) Remove(id string) *model.AppError {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.nodes, id)
+	return nil
+}
+
+func startTestNode(t *testing.T, discovery Discovery, secret string, setup func(node *Node)) *Node {
+	node := NewNode("127.0.0.1:0", "", discovery, secret)
+	node.HeartbeatInterval = 50 * time.Millisecond
+
+	if setup != nil {
+		setup(node)
+	}
+
+	if err := node.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	return node
+}
+
+func waitForPeers(t *testing.T, node *Node, count int) {
+	for i := 0; i < 100; i++ {
+		if len(node.GetClusterInfos()) == count {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("node should've had %v peers but had %v", count, len(node.GetClusterInfos()))
+}
+
+func setupClusterTest() {
+	utils.TranslationsPreInit()
+	utils.LoadTestConfig()
+	utils.InitTranslations(utils.Cfg.LocalizationSettings)
+}
+
+func TestNodeDiscovery(t *testing.T) {
+	setupClusterTest()
+
+	discovery := newMemoryDiscovery()
+	secret := model.NewRandomString(64)
+
+	nodes := []*Node{}
+	for i := 0; i < 3; i++ {
+		nodes = append(nodes, startTestNode(t, discovery, secret, nil))
+	}
+
+	for _, node := range nodes {
+		waitForPeers(t, node, 2)
+	}
+
+	for _, info := range nodes[0].GetClusterInfos() {
+		if info.Id == nodes[0].Id {
+			t.Fatal("shouldn't have included itself")
+		} else if info.InterNodeUrl == "" || !info.IsAlive || info.LastSuccessfulPing == 0 {
+			t.Fatal("returned the wrong cluster info")
+		}
+	}
+
+	nodes[2].Stop()
+
+	waitForPeers(t, nodes[0], 1)
+	waitForPeers(t, nodes[1], 1)
+
+	nodes[0].Stop()
+	nodes[1].Stop()
+}
+
+func TestNodeBroadcast(t *testing.T) {
+	setupClusterTest()
+
+	discovery := newMemoryDiscovery()
+	secret := model.NewRandomString(64)
+
+	type receivedMessage struct {
+		nodeId string
+		data   string
+	}
+
+	received := make(chan receivedMessage, 100)
+	receive := func(node *Node) {
+		node.RegisterHandler("test", func(msg *model.ClusterMessage) string {
+			received <- receivedMessage{nodeId: node.Id, data: msg.Data}
+			return ""
+		})
+	}
+
+	sender := startTestNode(t, discovery, secret, nil)
+	defer sender.Stop()
+
+	for i := 0; i < 2; i++ {
+		receiver := startTestNode(t, discovery, secret, receive)
+		defer receiver.Stop()
+	}
+
+	waitForPeers(t, sender, 2)
+
+	for i := 0; i < 10; i++ {
+		sender.Broadcast(&model.ClusterMessage{Event: "test", Data: strconv.Itoa(i)})
+	}
+
+	// Each receiver should get every message in the order that they were sent
+	next := map[string]int{}
+	for i := 0; i < 20; i++ {
+		select {
+		case msg := <-received:
+			if msg.data != strconv.Itoa(next[msg.nodeId]) {
+				t.Fatal("received a message out of order")
+			}
+
+			next[msg.nodeId]++
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for messages")
+		}
+	}
+
+	if len(next) != 2 {
+		t.Fatal("each receiver should've received the messages")
+	}
+}
+
+func TestNodeRequest(t *testing.T) {
+	setupClusterTest()
+
+	discovery := newMemoryDiscovery()
+	secret := model.NewRandomString(64)
+
+	respond := func(node *Node) {
+		node.RegisterHandler("test", func(msg *model.ClusterMessage) string {
+			return node.Id + ":" + msg.Data
+		})
+	}
+
+	nodes := []*Node{}
+	for i := 0; i < 3; i++ {
+		node := startTestNode(t, discovery, secret, respond)
+		defer node.Stop()
+
+		nodes = append(nodes, node)
+	}
+
+	waitForPeers(t, nodes[0], 2)
+
+	responses := nodes[0].Request(&model.ClusterMessage{Event: "test", Data: "data"})
+	if len(responses) != 2 {
+		t.Fatal("should've received a response from each other node")
+	}
+
+	for _, node := range nodes[1:] {
+		if responses[node.Id] != node.Id+":data" {
+			t.Fatal("received the wrong response")
+		}
+	}
+
+	if responses := nodes[0].Request(&model.ClusterMessage{Event: "unknown"}); len(responses) != 0 {
+		t.Fatal("shouldn't have received responses for an unknown event")
+	}
+}
+
+func TestNodeRejectsInvalidSignature(t *testing.T) {
+	setupClusterTest()
+
+	discovery := newMemoryDiscovery()
+
+	respond := func(node *Node) {
+		node.RegisterHandler("test", func(msg *model.ClusterMessage) string {
+			return "response"
+		})
+	}
+
+	node := startTestNode(t, discovery, model.NewRandomString(64), respond)
+	defer node.Stop()
+
+	other := startTestNode(t, discovery, model.NewRandomString(64), respond)
+	defer other.Stop()
+
+	waitForPeers(t, node, 1)
+
+	if responses := node.Request(&model.ClusterMessage{Event: "test"}); len(responses) != 0 {
+		t.Fatal("should've rejected a message signed with a different secret")
+	}
+}
+
+func TestNodeRejectsReplayedMessage(t *testing.T) {
+	setupClusterTest()
+
+	discovery := newMemoryDiscovery()
+	secret := model.NewRandomString(64)
+
+	received := make(chan bool, 10)
+	node := startTestNode(t, discovery, secret, func(node *Node) {
+		node.RegisterHandler("test", func(msg *model.ClusterMessage) string {
+			received <- true
+			return ""
+		})
+	})
+	defer node.Stop()
+
+	sender := NewNode("127.0.0.1:0", "", discovery, secret)
+
+	req, err := sender.newRequest(node.Url, &model.ClusterMessage{Event: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte((&model.ClusterMessage{Event: "test"}).ToJson())
+
+	if resp, err := http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusOK {
+		t.Fatal("should've accepted the message", resp.StatusCode)
+	}
+
+	replayed, _ := http.NewRequest("POST", node.Url+MESSAGE_PATH, bytes.NewReader(body))
+	replayed.Header = req.Header
+	if resp, err := http.DefaultClient.Do(replayed); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatal("should've rejected a message that was already received", resp.StatusCode)
+	}
+
+	stale, _ := sender.newRequest(node.Url, &model.ClusterMessage{Event: "test"})
+	timestamp := strconv.FormatInt(model.GetMillis()-2*MAX_MESSAGE_AGE, 10)
+	stale.Header.Set(HEADER_TIMESTAMP, timestamp)
+	stale.Header.Set(HEADER_SIGNATURE, sender.sign(sender.Id, timestamp, stale.Header.Get(HEADER_MESSAGE_ID), body))
+	if resp, err := http.DefaultClient.Do(stale); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatal("should've rejected a message that was sent too long ago", resp.StatusCode)
+	}
+
+	if len(received) != 1 {
+		t.Fatal("should've only handled the first message", len(received))
+	}
+}
+
+func TestNodeTLS(t *testing.T) {
+	setupClusterTest()
+
+	discovery := newMemoryDiscovery()
+	secret := model.NewRandomString(64)
+	tlsConfig := newTestTLSConfig(t)
+
+	respond := func(node *Node) {
+		node.TLSConfig = tlsConfig
+		node.RegisterHandler("test", func(msg *model.ClusterMessage) string {
+			return "response"
+		})
+	}
+
+	node := startTestNode(t, discovery, secret, respond)
+	defer node.Stop()
+
+	other := startTestNode(t, discovery, secret, respond)
+	defer other.Stop()
+
+	if !strings.HasPrefix(node.Url, "https://") {
+		t.Fatal("should've advertised an https url", node.Url)
+	}
+
+	waitForPeers(t, node, 1)
+
+	if responses := node.Request(&model.ClusterMessage{Event: "test"}); responses[other.Id] != "response" {
+		t.Fatal("should've received a response over TLS")
+	}
+}
+
+// newTestTLSConfig creates a self-signed certificate for 127.0.0.1 that's trusted by the returned configuration.
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cluster"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		RootCAs:      pool,
+	}
+}
+
+func TestHandlersUpdateStatus(t *testing.T) {
+	setupClusterTest()
+
+	discovery := newMemoryDiscovery()
+	secret := model.NewRandomString(64)
+
+	sender := startTestNode(t, discovery, secret, nil)
+	defer sender.Stop()
+
+	receiver := startTestNode(t, discovery, secret, RegisterHandlers)
+	defer receiver.Stop()
+
+	waitForPeers(t, sender, 1)
+
+	status := &model.Status{UserId: model.NewId(), Status: model.STATUS_ONLINE, LastActivityAt: model.GetMillis()}
+	sender.Broadcast(&model.ClusterMessage{Event: model.CLUSTER_EVENT_UPDATE_STATUS, Data: status.ToJson()})
+
+	for i := 0; i < 100; i++ {
+		if cached := app.GetStatusFromCache(status.UserId); cached != nil {
+			if cached.Status != model.STATUS_ONLINE {
+				t.Fatal("cached the wrong status")
+			}
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("should've cached the status sent by the other node")
+}
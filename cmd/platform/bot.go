@@ -0,0 +1,146 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattermost/platform/api"
+	"github.com/mattermost/platform/app"
+	"github.com/spf13/cobra"
+)
+
+var botCmd = &cobra.Command{
+	Use:   "bot",
+	Short: "Management of bot accounts",
+}
+
+var createBotCmd = &cobra.Command{
+	Use:   "create [username]",
+	Short: "Create a bot",
+	Long: `Create a bot account for an integration to post as. Bots can't sign in, so use
+"token create" to give the bot a token once it has been created.`,
+	Example: `  bot create deploybot --owner user@example.com --display-name "Deploy Bot"`,
+	RunE:    createBotCmdF,
+}
+
+var listBotsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List all bots",
+	Example: "  bot list",
+	RunE:    listBotsCmdF,
+}
+
+var disableBotsCmd = &cobra.Command{
+	Use:     "disable [bots]",
+	Short:   "Disable bots",
+	Long:    "Disable some bots so that their tokens can no longer be used.",
+	Example: "  bot disable deploybot",
+	RunE:    disableBotsCmdF,
+}
+
+var enableBotsCmd = &cobra.Command{
+	Use:     "enable [bots]",
+	Short:   "Enable bots",
+	Long:    "Enable some bots that were previously disabled.",
+	Example: "  bot enable deploybot",
+	RunE:    enableBotsCmdF,
+}
+
+func init() {
+	createBotCmd.Flags().String("owner", "", "The user who can manage the bot. Only system admins can manage bots without an owner.")
+	createBotCmd.Flags().String("display-name", "", "Display Name")
+	createBotCmd.Flags().String("description", "", "Description")
+
+	botCmd.AddCommand(
+		createBotCmd,
+		listBotsCmd,
+		disableBotsCmd,
+		enableBotsCmd,
+	)
+}
+
+func createBotCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) != 1 {
+		return errors.New("Expected one username.")
+	}
+
+	ownerId := ""
+	if ownerArg, _ := cmd.Flags().GetString("owner"); ownerArg != "" {
+		owner := getUserFromUserArg(ownerArg)
+		if owner == nil {
+			return errors.New("Unable to find user '" + ownerArg + "'")
+		}
+		ownerId = owner.Id
+	}
+
+	displayName, _ := cmd.Flags().GetString("display-name")
+	description, _ := cmd.Flags().GetString("description")
+
+	bot, err := app.CreateBot(args[0], displayName, description, ownerId)
+	if err != nil {
+		return errors.New("Bot creation failed: " + err.Error())
+	}
+
+	CommandPrettyPrintln("Created bot " + bot.Username + " (" + bot.UserId + ")")
+
+	return nil
+}
+
+func listBotsCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	bots, err := app.GetAllBots()
+	if err != nil {
+		return err
+	}
+
+	for _, bot := range bots {
+		status := ""
+		if bot.DeleteAt != 0 {
+			status = " (disabled)"
+		}
+		CommandPrettyPrintln(fmt.Sprintf("%v: %v%v", bot.UserId, bot.Username, status))
+	}
+
+	return nil
+}
+
+func disableBotsCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter bot(s) to disable.")
+	}
+
+	changeBotsActiveStatus(args, false)
+	return nil
+}
+
+func enableBotsCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter bot(s) to enable.")
+	}
+
+	changeBotsActiveStatus(args, true)
+	return nil
+}
+
+func changeBotsActiveStatus(botArgs []string, active bool) {
+	users := getUsersFromUserArgs(botArgs)
+	for i, user := range users {
+		if user == nil || !user.IsBot {
+			CommandPrintErrorln("Can't find bot '" + botArgs[i] + "'")
+			continue
+		}
+
+		if _, err := api.UpdateActive(user, active); err != nil {
+			CommandPrintErrorln("Unable to change activation status of bot: " + botArgs[i])
+		}
+	}
+}
@@ -220,6 +220,8 @@ func deleteChannelsCmdF(cmd *cobra.Command, args []string) error {
 		}
 		if result := <-app.Srv.Store.Channel().Delete(channel.Id, model.GetMillis()); result.Err != nil {
 			CommandPrintErrorln("Unable to delete channel '" + channel.Name + "' error: " + result.Err.Error())
+		} else {
+			app.DeleteChannelFromIndex(channel.Id)
 		}
 	}
 
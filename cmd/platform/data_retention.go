@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/utils"
+	"github.com/spf13/cobra"
+)
+
+var dataRetentionCmd = &cobra.Command{
+	Use:   "data_retention",
+	Short: "Management of data retention",
+}
+
+var runDataRetentionCmd = &cobra.Command{
+	Use:     "run",
+	Short:   "Delete old posts and files now",
+	Long:    "Permanently deletes every post and file that's older than allowed by the policies in DataRetentionSettings instead of waiting for the nightly job. A running server removes the deleted posts from its search index as it comes across them.",
+	Example: "  data_retention run",
+	RunE:    runDataRetentionCmdF,
+}
+
+func init() {
+	dataRetentionCmd.AddCommand(runDataRetentionCmd)
+}
+
+func runDataRetentionCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if !*utils.Cfg.DataRetentionSettings.EnableMessageDeletion && !*utils.Cfg.DataRetentionSettings.EnableFileDeletion {
+		return errors.New("Message or file deletion must be enabled in DataRetentionSettings before the data retention job can be run")
+	}
+
+	CommandPrettyPrintln("Deleting old posts and files. This may take a while.")
+
+	if err := app.RunDataRetention(printDataRetentionProgress); err != nil {
+		return err
+	}
+
+	CommandPrettyPrintln("Finished deleting old posts and files")
+
+	return nil
+}
+
+func printDataRetentionProgress(progress *app.DataRetentionProgress) {
+	if progress.TeamId == "" {
+		CommandPrettyPrintln(fmt.Sprintf("Deleted %v posts and %v files", progress.PostsDeleted, progress.FilesDeleted))
+	} else {
+		CommandPrettyPrintln(fmt.Sprintf("Deleted %v posts and %v files from team %v", progress.PostsDeleted, progress.FilesDeleted, progress.TeamId))
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/model"
+	"github.com/spf13/cobra"
+)
+
+const LEGAL_HOLD_DATE_FORMAT = "2006-01-02"
+
+var legalHoldCmd = &cobra.Command{
+	Use:   "legal_hold",
+	Short: "Management of legal holds",
+}
+
+var createLegalHoldCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a legal hold",
+	Long: `Create a legal hold that prevents the posts and files of some users and channels from being permanently deleted.
+Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.
+Dates are given as YYYY-MM-DD in UTC. If no end date is given, the hold covers everything created after the start date.`,
+	Example: `  legal_hold create --name "Case 123" --users user@example.com,username --channels myteam:mychannel --start 2017-01-01 --end 2017-06-30`,
+	RunE:    createLegalHoldCmdF,
+}
+
+var listLegalHoldsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List all legal holds",
+	Example: "  legal_hold list",
+	RunE:    listLegalHoldsCmdF,
+}
+
+var updateLegalHoldCmd = &cobra.Command{
+	Use:   "update [legal_hold_id]",
+	Short: "Update a legal hold",
+	Long: `Update the name, description, users, channels or time range of a legal hold. Only the given values are changed.
+Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.
+Dates are given as YYYY-MM-DD in UTC. Pass an empty end date to make the hold open-ended.`,
+	Example: `  legal_hold update 9f4uqfwwmbgo7pmbznmu7c6t9o --users user@example.com,username --end 2017-12-31`,
+	RunE:    updateLegalHoldCmdF,
+}
+
+var deleteLegalHoldsCmd = &cobra.Command{
+	Use:     "delete [legal_hold_ids]",
+	Short:   "Delete legal holds",
+	Long:    "Delete some legal holds so that the data they covered can be deleted again.",
+	Example: "  legal_hold delete 9f4uqfwwmbgo7pmbznmu7c6t9o",
+	RunE:    deleteLegalHoldsCmdF,
+}
+
+func init() {
+	createLegalHoldCmd.Flags().String("name", "", "Legal Hold Name")
+	createLegalHoldCmd.Flags().String("description", "", "Legal Hold Description")
+	createLegalHoldCmd.Flags().StringSlice("users", []string{}, "Usernames, emails or IDs of the users to hold")
+	createLegalHoldCmd.Flags().StringSlice("channels", []string{}, "Channels to hold")
+	createLegalHoldCmd.Flags().String("start", "", "Start of the hold (YYYY-MM-DD)")
+	createLegalHoldCmd.Flags().String("end", "", "End of the hold (YYYY-MM-DD)")
+
+	updateLegalHoldCmd.Flags().String("name", "", "Legal Hold Name")
+	updateLegalHoldCmd.Flags().String("description", "", "Legal Hold Description")
+	updateLegalHoldCmd.Flags().StringSlice("users", []string{}, "Usernames, emails or IDs of the users to hold")
+	updateLegalHoldCmd.Flags().StringSlice("channels", []string{}, "Channels to hold")
+	updateLegalHoldCmd.Flags().String("start", "", "Start of the hold (YYYY-MM-DD)")
+	updateLegalHoldCmd.Flags().String("end", "", "End of the hold (YYYY-MM-DD)")
+
+	legalHoldCmd.AddCommand(
+		createLegalHoldCmd,
+		listLegalHoldsCmd,
+		updateLegalHoldCmd,
+		deleteLegalHoldsCmd,
+	)
+}
+
+func createLegalHoldCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	name, errn := cmd.Flags().GetString("name")
+	if errn != nil || name == "" {
+		return errors.New("Name is required")
+	}
+	description, _ := cmd.Flags().GetString("description")
+	userArgs, _ := cmd.Flags().GetStringSlice("users")
+	channelArgs, _ := cmd.Flags().GetStringSlice("channels")
+
+	if len(userArgs) == 0 && len(channelArgs) == 0 {
+		return errors.New("At least one user or channel is required")
+	}
+
+	hold := &model.LegalHold{
+		Name:        name,
+		Description: description,
+	}
+
+	var err error
+	if hold.UserIds, err = getLegalHoldUserIds(userArgs); err != nil {
+		return err
+	}
+
+	if hold.ChannelIds, err = getLegalHoldChannelIds(channelArgs); err != nil {
+		return err
+	}
+
+	if start, _ := cmd.Flags().GetString("start"); start != "" {
+		if hold.StartAt, err = parseLegalHoldStart(start); err != nil {
+			return err
+		}
+	}
+
+	if end, _ := cmd.Flags().GetString("end"); end != "" {
+		if hold.EndAt, err = parseLegalHoldEnd(end); err != nil {
+			return err
+		}
+	}
+
+	if rhold, err := app.CreateLegalHold(hold); err != nil {
+		return errors.New("Legal hold creation failed: " + err.Error())
+	} else {
+		CommandPrettyPrintln("Created legal hold " + rhold.Id)
+	}
+
+	return nil
+}
+
+func updateLegalHoldCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) != 1 {
+		return errors.New("Enter one legal hold to update.")
+	}
+
+	hold, appErr := app.GetLegalHold(args[0])
+	if appErr != nil {
+		return errors.New("Unable to find legal hold '" + args[0] + "'")
+	}
+
+	flags := cmd.Flags()
+	var err error
+
+	if flags.Changed("name") {
+		if hold.Name, _ = flags.GetString("name"); hold.Name == "" {
+			return errors.New("Name is required")
+		}
+	}
+
+	if flags.Changed("description") {
+		hold.Description, _ = flags.GetString("description")
+	}
+
+	if flags.Changed("users") {
+		userArgs, _ := flags.GetStringSlice("users")
+		if hold.UserIds, err = getLegalHoldUserIds(userArgs); err != nil {
+			return err
+		}
+	}
+
+	if flags.Changed("channels") {
+		channelArgs, _ := flags.GetStringSlice("channels")
+		if hold.ChannelIds, err = getLegalHoldChannelIds(channelArgs); err != nil {
+			return err
+		}
+	}
+
+	if len(hold.UserIds) == 0 && len(hold.ChannelIds) == 0 {
+		return errors.New("At least one user or channel is required")
+	}
+
+	if flags.Changed("start") {
+		hold.StartAt = 0
+		if start, _ := flags.GetString("start"); start != "" {
+			if hold.StartAt, err = parseLegalHoldStart(start); err != nil {
+				return err
+			}
+		}
+	}
+
+	if flags.Changed("end") {
+		hold.EndAt = 0
+		if end, _ := flags.GetString("end"); end != "" {
+			if hold.EndAt, err = parseLegalHoldEnd(end); err != nil {
+				return err
+			}
+		}
+	}
+
+	if rhold, err := app.UpdateLegalHold(hold); err != nil {
+		return errors.New("Legal hold update failed: " + err.Error())
+	} else {
+		CommandPrettyPrintln("Updated legal hold " + rhold.Id)
+	}
+
+	return nil
+}
+
+func getLegalHoldUserIds(userArgs []string) ([]string, error) {
+	userIds := []string{}
+	for i, user := range getUsersFromUserArgs(userArgs) {
+		if user == nil {
+			return nil, errors.New("Unable to find user '" + userArgs[i] + "'")
+		}
+		userIds = append(userIds, user.Id)
+	}
+
+	return userIds, nil
+}
+
+func getLegalHoldChannelIds(channelArgs []string) ([]string, error) {
+	channelIds := []string{}
+	for i, channel := range getChannelsFromChannelArgs(channelArgs) {
+		if channel == nil {
+			return nil, errors.New("Unable to find channel '" + channelArgs[i] + "'")
+		}
+		channelIds = append(channelIds, channel.Id)
+	}
+
+	return channelIds, nil
+}
+
+func parseLegalHoldStart(start string) (int64, error) {
+	if startTime, err := time.Parse(LEGAL_HOLD_DATE_FORMAT, start); err != nil {
+		return 0, errors.New("Invalid start date '" + start + "'")
+	} else {
+		return model.GetMillisForTime(startTime), nil
+	}
+}
+
+func parseLegalHoldEnd(end string) (int64, error) {
+	if endTime, err := time.Parse(LEGAL_HOLD_DATE_FORMAT, end); err != nil {
+		return 0, errors.New("Invalid end date '" + end + "'")
+	} else {
+		// Include the whole of the last day
+		return model.GetMillisForTime(endTime.AddDate(0, 0, 1)) - 1, nil
+	}
+}
+
+func listLegalHoldsCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	holds, err := app.GetAllLegalHolds()
+	if err != nil {
+		return err
+	}
+
+	for _, hold := range holds {
+		end := "open-ended"
+		if hold.EndAt != 0 {
+			end = formatLegalHoldTime(hold.EndAt)
+		}
+
+		CommandPrettyPrintln(fmt.Sprintf("%v: %v (%v to %v) users=%v channels=%v", hold.Id, hold.Name, formatLegalHoldTime(hold.StartAt), end, strings.Join(hold.UserIds, ","), strings.Join(hold.ChannelIds, ",")))
+	}
+
+	return nil
+}
+
+func formatLegalHoldTime(millis int64) string {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC().Format(LEGAL_HOLD_DATE_FORMAT)
+}
+
+func deleteLegalHoldsCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter at least one legal hold to delete.")
+	}
+
+	for _, id := range args {
+		if err := app.DeleteLegalHold(id); err != nil {
+			CommandPrintErrorln("Unable to delete legal hold '" + id + "' error: " + err.Error())
+		}
+	}
+
+	return nil
+}
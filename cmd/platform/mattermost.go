@@ -13,7 +13,13 @@ import (
 	"github.com/spf13/cobra"
 
 	// Plugins
+	_ "github.com/mattermost/platform/cluster"
+	_ "github.com/mattermost/platform/compliance"
+	_ "github.com/mattermost/platform/metrics"
+	_ "github.com/mattermost/platform/mfa"
 	_ "github.com/mattermost/platform/model/gitlab"
+	_ "github.com/mattermost/platform/model/openid"
+	_ "github.com/mattermost/platform/searchengine"
 
 	// Enterprise Deps
 	_ "github.com/dgryski/dgoogauth"
@@ -34,7 +40,7 @@ func main() {
 
 	resetCmd.Flags().Bool("confirm", false, "Confirm you really want to delete everything and a DB backup has been performed.")
 
-	rootCmd.AddCommand(serverCmd, versionCmd, userCmd, teamCmd, licenseCmd, importCmd, resetCmd, channelCmd, rolesCmd, testCmd, ldapCmd)
+	rootCmd.AddCommand(serverCmd, versionCmd, userCmd, teamCmd, licenseCmd, importCmd, resetCmd, channelCmd, rolesCmd, testCmd, ldapCmd, searchCmd, dataRetentionCmd, legalHoldCmd, tokenCmd, botCmd, pluginCmd)
 
 	flag.Usage = func() {
 		rootCmd.Usage()
@@ -16,7 +16,6 @@ import (
 	"github.com/mattermost/platform/app"
 	"github.com/mattermost/platform/einterfaces"
 	"github.com/mattermost/platform/model"
-	"github.com/mattermost/platform/store"
 	"github.com/mattermost/platform/utils"
 	"github.com/mattermost/platform/web"
 )
@@ -333,7 +332,7 @@ func cmdVersion() {
 		fmt.Fprintln(os.Stderr, "Build Date: "+model.BuildDate)
 		fmt.Fprintln(os.Stderr, "Build Hash: "+model.BuildHash)
 		fmt.Fprintln(os.Stderr, "Build Enterprise Ready: "+model.BuildEnterpriseReady)
-		fmt.Fprintln(os.Stderr, "DB Version: "+app.Srv.Store.(*store.SqlStore).SchemaVersion)
+		fmt.Fprintln(os.Stderr, "DB Version: "+app.Srv.Store.GetCurrentSchemaVersion())
 
 		os.Exit(0)
 	}
@@ -0,0 +1,160 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mattermost/platform/app"
+	"github.com/spf13/cobra"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "Management of plugins",
+}
+
+var addPluginCmd = &cobra.Command{
+	Use:   "add [plugins]",
+	Short: "Install plugins",
+	Long: `Install plugins from gzipped tarballs. Plugins are installed disabled, so use
+"plugin enable" to start them.`,
+	Example: "  plugin add myplugin.tar.gz",
+	RunE:    addPluginCmdF,
+}
+
+var deletePluginCmd = &cobra.Command{
+	Use:     "delete [plugins]",
+	Short:   "Delete plugins",
+	Long:    "Delete some installed plugins by their ids.",
+	Example: "  plugin delete com.example.myplugin",
+	RunE:    deletePluginCmdF,
+}
+
+var enablePluginCmd = &cobra.Command{
+	Use:   "enable [plugins]",
+	Short: "Enable plugins",
+	Long: `Enable some installed plugins by their ids. A running server starts them the
+next time its config is reloaded.`,
+	Example: "  plugin enable com.example.myplugin",
+	RunE:    enablePluginCmdF,
+}
+
+var disablePluginCmd = &cobra.Command{
+	Use:   "disable [plugins]",
+	Short: "Disable plugins",
+	Long: `Disable some installed plugins by their ids. A running server stops them the
+next time its config is reloaded.`,
+	Example: "  plugin disable com.example.myplugin",
+	RunE:    disablePluginCmdF,
+}
+
+var listPluginsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List all installed plugins",
+	Example: "  plugin list",
+	RunE:    listPluginsCmdF,
+}
+
+func init() {
+	pluginCmd.AddCommand(
+		addPluginCmd,
+		deletePluginCmd,
+		enablePluginCmd,
+		disablePluginCmd,
+		listPluginsCmd,
+	)
+}
+
+func addPluginCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter plugin(s) to install.")
+	}
+
+	for _, path := range args {
+		file, err := os.Open(path)
+		if err != nil {
+			CommandPrintErrorln("Unable to open plugin '" + path + "': " + err.Error())
+			continue
+		}
+
+		if manifest, appErr := app.InstallPlugin(file); appErr != nil {
+			CommandPrintErrorln("Unable to install plugin '" + path + "': " + appErr.Error())
+		} else {
+			CommandPrettyPrintln("Installed plugin " + manifest.Id)
+		}
+
+		file.Close()
+	}
+
+	return nil
+}
+
+func deletePluginCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter plugin(s) to delete.")
+	}
+
+	for _, id := range args {
+		if err := app.RemovePlugin(id); err != nil {
+			CommandPrintErrorln("Unable to delete plugin '" + id + "': " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func enablePluginCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter plugin(s) to enable.")
+	}
+
+	changePluginsEnabled(args, true)
+	return nil
+}
+
+func disablePluginCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter plugin(s) to disable.")
+	}
+
+	changePluginsEnabled(args, false)
+	return nil
+}
+
+func changePluginsEnabled(ids []string, enabled bool) {
+	for _, id := range ids {
+		if err := app.SetPluginEnabled(id, enabled); err != nil {
+			CommandPrintErrorln("Unable to change whether plugin '" + id + "' is enabled: " + err.Error())
+		}
+	}
+}
+
+func listPluginsCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	plugins, err := app.GetPlugins()
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range plugins {
+		status := ""
+		if !plugin.Enabled {
+			status = " (disabled)"
+		}
+		CommandPrettyPrintln(fmt.Sprintf("%v: %v %v%v", plugin.Id, plugin.Name, plugin.Version, status))
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"errors"
+
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/utils"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Management of the search engine",
+}
+
+var reindexSearchCmd = &cobra.Command{
+	Use:     "reindex",
+	Short:   "Rebuild the search index",
+	Long:    "Removes everything from the search engine's index and adds every post, open channel and user in the database back to it. The server should be stopped while this runs. Each server in a cluster keeps its own index, so this needs to be run on every server.",
+	Example: "  search reindex",
+	RunE:    reindexSearchCmdF,
+}
+
+func init() {
+	searchCmd.AddCommand(reindexSearchCmd)
+}
+
+func reindexSearchCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if !*utils.Cfg.SearchEngineSettings.EnableIndexing {
+		return errors.New("Indexing must be enabled in SearchEngineSettings before the index can be rebuilt")
+	}
+
+	app.StartSearchEngine()
+	defer app.StopSearchEngine()
+
+	CommandPrettyPrintln("Rebuilding the search index. This may take a while.")
+
+	if err := app.ReindexSearchEngine(); err != nil {
+		return err
+	}
+
+	CommandPrettyPrintln("Finished rebuilding the search index")
+
+	return nil
+}
@@ -66,6 +66,8 @@ func runServer(configFileLocation string) {
 
 	app.NewServer()
 	app.InitStores()
+	app.InitFileBackend()
+	app.StartSearchEngine()
 	api.InitRouter()
 	api.InitApi()
 	web.InitWeb()
@@ -95,6 +97,12 @@ func runServer(configFileLocation string) {
 	setDiagnosticId()
 	go runSecurityAndDiagnosticsJob()
 
+	app.StartUploadSessionCleanupTask()
+	app.StartScheduledPostTask()
+	app.StartOutgoingWebhookDeliveryTasks()
+	app.StartDataRetentionJob()
+	app.SyncPlugins()
+
 	if complianceI := einterfaces.GetComplianceInterface(); complianceI != nil {
 		complianceI.StartComplianceDailyJob()
 	}
@@ -121,7 +129,9 @@ func runServer(configFileLocation string) {
 		einterfaces.GetMetricsInterface().StopServer()
 	}
 
+	app.StopPlugins()
 	app.StopServer()
+	app.StopSearchEngine()
 }
 
 func runSecurityAndDiagnosticsJob() {
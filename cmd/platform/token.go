@@ -0,0 +1,127 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/platform/app"
+	"github.com/spf13/cobra"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Management of personal access tokens",
+}
+
+var createTokenCmd = &cobra.Command{
+	Use:   "create [user]",
+	Short: "Create a personal access token",
+	Long: `Create a personal access token that lets scripts and integrations use the API as a user.
+The token is only shown once, so it needs to be saved somewhere safe.`,
+	Example: `  token create user@example.com --description "Deployment script"`,
+	RunE:    createTokenCmdF,
+}
+
+var listTokensCmd = &cobra.Command{
+	Use:     "list [user]",
+	Short:   "List the personal access tokens of a user",
+	Example: "  token list user@example.com",
+	RunE:    listTokensCmdF,
+}
+
+var revokeTokensCmd = &cobra.Command{
+	Use:     "revoke [token_ids]",
+	Short:   "Revoke personal access tokens",
+	Long:    "Revoke some personal access tokens so that they can no longer be used.",
+	Example: "  token revoke 9f4uqfwwmbgo7pmbznmu7c6t9o",
+	RunE:    revokeTokensCmdF,
+}
+
+func init() {
+	createTokenCmd.Flags().String("description", "", "Token Description")
+
+	tokenCmd.AddCommand(
+		createTokenCmd,
+		listTokensCmd,
+		revokeTokensCmd,
+	)
+}
+
+func createTokenCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) != 1 {
+		return errors.New("Expected one user.")
+	}
+
+	user := getUserFromUserArg(args[0])
+	if user == nil {
+		return errors.New("Unable to find user '" + args[0] + "'")
+	}
+
+	description, _ := cmd.Flags().GetString("description")
+
+	token, err := app.CreatePersonalAccessToken(user.Id, description)
+	if err != nil {
+		return errors.New("Token creation failed: " + err.Error())
+	}
+
+	app.AuditPersonalAccessToken(token, app.PERSONAL_ACCESS_TOKEN_AUDIT_CREATED)
+
+	CommandPrettyPrintln("Created token " + token.Id + " for " + user.Username + ": " + token.Token)
+
+	return nil
+}
+
+func listTokensCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) != 1 {
+		return errors.New("Expected one user.")
+	}
+
+	user := getUserFromUserArg(args[0])
+	if user == nil {
+		return errors.New("Unable to find user '" + args[0] + "'")
+	}
+
+	tokens, err := app.GetPersonalAccessTokensForUser(user.Id)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		created := time.Unix(0, token.CreateAt*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+		CommandPrettyPrintln(fmt.Sprintf("%v: %v (created %v)", token.Id, token.Description, created))
+	}
+
+	return nil
+}
+
+func revokeTokensCmdF(cmd *cobra.Command, args []string) error {
+	initDBCommandContextCobra(cmd)
+
+	if len(args) < 1 {
+		return errors.New("Enter at least one token to revoke.")
+	}
+
+	for _, id := range args {
+		token, err := app.GetPersonalAccessToken(id)
+		if err != nil {
+			CommandPrintErrorln("Unable to find token '" + id + "'")
+			continue
+		}
+
+		if err := app.RevokePersonalAccessToken(token); err != nil {
+			CommandPrintErrorln("Unable to revoke token '" + id + "' error: " + err.Error())
+			continue
+		}
+
+		app.AuditPersonalAccessToken(token, app.PERSONAL_ACCESS_TOKEN_AUDIT_REVOKED)
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package compliance
+
+import (
+	"archive/zip"
+	"encoding/xml"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	ACTIANCE_EXPORT_FILE = "actiance_export.xml"
+	ACTIANCE_USER_TYPE   = "user"
+)
+
+type actianceEvent struct {
+	XMLName          xml.Name
+	LoginName        string `xml:"LoginName"`
+	UserType         string `xml:"UserType"`
+	DateTimeUTC      int64  `xml:"DateTimeUTC"`
+	CorporateEmailID string `xml:"CorporateEmailID"`
+}
+
+type actianceMessage struct {
+	XMLName          xml.Name `xml:"Message"`
+	LoginName        string   `xml:"LoginName"`
+	UserType         string   `xml:"UserType"`
+	DateTimeUTC      int64    `xml:"DateTimeUTC"`
+	CorporateEmailID string   `xml:"CorporateEmailID"`
+	Content          string   `xml:"Content"`
+}
+
+type actianceFileTransfer struct {
+	XMLName          xml.Name
+	LoginName        string `xml:"LoginName"`
+	UserType         string `xml:"UserType"`
+	DateTimeUTC      int64  `xml:"DateTimeUTC"`
+	CorporateEmailID string `xml:"CorporateEmailID"`
+	FileName         string `xml:"FileName"`
+	FileSize         int64  `xml:"FileSize"`
+	Status           string `xml:"Status,omitempty"`
+}
+
+// actianceWriter writes an XML file that can be imported by Actiance Vantage with a Channel element for each channel
+// containing its messages, file transfers and join/leave events.
+type actianceWriter struct {
+	zipWriter *zip.Writer
+	encoder   *xml.Encoder
+	lastPost  *exportPost
+}
+
+func newActianceWriter(w *zip.Writer) exportWriter {
+	return &actianceWriter{
+		zipWriter: w,
+	}
+}
+
+// toActianceTime converts a time in milliseconds to the seconds used by Actiance.
+func toActianceTime(millis int64) int64 {
+	return millis / 1000
+}
+
+func getActianceRoomId(post *exportPost) string {
+	return getChannelTypeName(post.ChannelType) + " - " + post.ChannelName + " - " + post.ChannelId
+}
+
+// getChannelTypeName returns a readable name for a type of channel.
+func getChannelTypeName(channelType string) string {
+	switch channelType {
+	case model.CHANNEL_OPEN:
+		return "public"
+	case model.CHANNEL_PRIVATE:
+		return "private"
+	case model.CHANNEL_DIRECT:
+		return "direct"
+	default:
+		return channelType
+	}
+}
+
+func (aw *actianceWriter) start() error {
+	if aw.encoder != nil {
+		return nil
+	}
+
+	f, err := aw.zipWriter.Create(ACTIANCE_EXPORT_FILE)
+	if err != nil {
+		return err
+	}
+
+	aw.encoder = xml.NewEncoder(f)
+	aw.encoder.Indent("", "  ")
+
+	if err := aw.encoder.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}); err != nil {
+		return err
+	}
+
+	return aw.encoder.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "FileDump"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns:xsi"}, Value: "http://www.w3.org/2001/XMLSchema-instance"}},
+	})
+}
+
+func (aw *actianceWriter) StartChannel(post *exportPost) error {
+	if err := aw.start(); err != nil {
+		return err
+	}
+
+	if err := aw.encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "Channel"}}); err != nil {
+		return err
+	}
+
+	if err := aw.encoder.EncodeElement(getActianceRoomId(post), xml.StartElement{Name: xml.Name{Local: "RoomID"}}); err != nil {
+		return err
+	}
+
+	return aw.encoder.EncodeElement(toActianceTime(post.PostCreateAt), xml.StartElement{Name: xml.Name{Local: "StartTimeUTC"}})
+}
+
+func (aw *actianceWriter) WritePost(post *exportPost) error {
+	aw.lastPost = post
+
+	if post.MemberEvent != "" {
+		name := "JoinEvent"
+		if post.MemberEvent == model.POST_MEMBER_EVENT_LEAVE {
+			name = "LeaveEvent"
+		}
+
+		return aw.encoder.Encode(&actianceEvent{
+			XMLName:          xml.Name{Local: name},
+			LoginName:        post.Member.Email,
+			UserType:         ACTIANCE_USER_TYPE,
+			DateTimeUTC:      toActianceTime(post.PostCreateAt),
+			CorporateEmailID: post.Member.Email,
+		})
+	}
+
+	for _, info := range post.Files {
+		if err := aw.writeFileTransfer("FileTransferStarted", "", post, info); err != nil {
+			return err
+		}
+
+		if err := aw.writeFileTransfer("FileTransferEnded", "Completed", post, info); err != nil {
+			return err
+		}
+	}
+
+	if post.PostMessage == "" && len(post.Files) > 0 {
+		return nil
+	}
+
+	return aw.encoder.Encode(&actianceMessage{
+		LoginName:        post.UserEmail,
+		UserType:         ACTIANCE_USER_TYPE,
+		DateTimeUTC:      toActianceTime(post.PostCreateAt),
+		CorporateEmailID: post.UserEmail,
+		Content:          post.PostMessage,
+	})
+}
+
+func (aw *actianceWriter) writeFileTransfer(name string, status string, post *exportPost, info *model.FileInfo) error {
+	return aw.encoder.Encode(&actianceFileTransfer{
+		XMLName:          xml.Name{Local: name},
+		LoginName:        post.UserEmail,
+		UserType:         ACTIANCE_USER_TYPE,
+		DateTimeUTC:      toActianceTime(post.PostCreateAt),
+		CorporateEmailID: post.UserEmail,
+		FileName:         info.Name,
+		FileSize:         info.Size,
+		Status:           status,
+	})
+}
+
+func (aw *actianceWriter) EndChannel() error {
+	if err := aw.encoder.EncodeElement(toActianceTime(aw.lastPost.PostCreateAt), xml.StartElement{Name: xml.Name{Local: "EndTimeUTC"}}); err != nil {
+		return err
+	}
+
+	return aw.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "Channel"}})
+}
+
+func (aw *actianceWriter) Close() error {
+	if err := aw.start(); err != nil {
+		return err
+	}
+
+	if err := aw.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: "FileDump"}}); err != nil {
+		return err
+	}
+
+	return aw.encoder.Flush()
+}
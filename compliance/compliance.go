@@ -0,0 +1,344 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package compliance
+
+import (
+	"archive/zip"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	DAILY_EXPORT_TASK_NAME = "Daily Compliance Export"
+	EXPORT_BATCH_SIZE      = 1000
+	EXPORT_DIRECTORY       = "compliance/"
+)
+
+// ComplianceExporter runs compliance jobs by writing every matching post to a zip file in the file store using the
+// format given by ComplianceSettings.ExportFormat.
+type ComplianceExporter struct {
+}
+
+func init() {
+	einterfaces.RegisterComplianceInterface(&ComplianceExporter{})
+}
+
+// exportPost is a post being exported along with the files attached to it and, for posts about a change in a
+// channel's membership, the user that joined or left.
+type exportPost struct {
+	*model.CompliancePost
+	Files       []*model.FileInfo
+	MemberEvent string
+	Member      *model.User
+}
+
+// exportWriter writes the posts from each channel in a compliance export. The posts from a channel are all written
+// between a call to StartChannel and a call to EndChannel in the order that they were created.
+type exportWriter interface {
+	StartChannel(post *exportPost) error
+	WritePost(post *exportPost) error
+	EndChannel() error
+	Close() error
+}
+
+func newExportWriter(format string, w *zip.Writer, job *model.Compliance) exportWriter {
+	switch format {
+	case model.COMPLIANCE_EXPORT_FORMAT_ACTIANCE:
+		return newActianceWriter(w)
+	case model.COMPLIANCE_EXPORT_FORMAT_GLOBALRELAY:
+		return newGlobalRelayWriter(w, job)
+	default:
+		return newCsvWriter(w)
+	}
+}
+
+func getExportPath(job *model.Compliance) string {
+	return EXPORT_DIRECTORY + job.JobName() + ".zip"
+}
+
+func (ce *ComplianceExporter) StartComplianceDailyJob() {
+	if task := model.GetTaskByName(DAILY_EXPORT_TASK_NAME); task != nil {
+		task.Cancel()
+	}
+
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+
+	model.CreateTask(DAILY_EXPORT_TASK_NAME, ce.runDailyJob, midnight.Sub(now))
+}
+
+// runDailyJob exports every post created since the last post exported by the previous daily job.
+func (ce *ComplianceExporter) runDailyJob() {
+	defer ce.StartComplianceDailyJob()
+
+	if !*utils.Cfg.ComplianceSettings.Enable || !*utils.Cfg.ComplianceSettings.EnableDaily {
+		return
+	}
+
+	now := time.Now()
+	desc := now.Format("2006-01-02")
+
+	// Servers in a cluster each schedule the daily job, so only the first one to start should run it
+	if result := <-app.Srv.Store.Compliance().GetAll(); result.Err != nil {
+		l4g.Error(result.Err.Error())
+		return
+	} else {
+		for _, job := range result.Data.(model.Compliances) {
+			if job.Type == model.COMPLIANCE_TYPE_DAILY && job.Desc == desc {
+				return
+			}
+		}
+	}
+
+	job := &model.Compliance{
+		Desc:    desc,
+		Type:    model.COMPLIANCE_TYPE_DAILY,
+		StartAt: now.AddDate(0, 0, -1).UnixNano() / int64(time.Millisecond),
+		EndAt:   now.UnixNano() / int64(time.Millisecond),
+	}
+
+	if result := <-app.Srv.Store.System().GetByName(model.SYSTEM_LAST_COMPLIANCE_TIME); result.Err == nil {
+		if lastTime, err := strconv.ParseInt(result.Data.(*model.System).Value, 10, 64); err == nil && lastTime > 0 && lastTime < job.EndAt {
+			job.StartAt = lastTime
+		}
+	}
+
+	if result := <-app.Srv.Store.Compliance().Save(job); result.Err != nil {
+		l4g.Error(result.Err.Error())
+		return
+	} else {
+		job = result.Data.(*model.Compliance)
+	}
+
+	lastPostTime, err := ce.runJob(job)
+	if err != nil {
+		return
+	}
+
+	if lastPostTime > 0 {
+		system := &model.System{Name: model.SYSTEM_LAST_COMPLIANCE_TIME, Value: strconv.FormatInt(lastPostTime, 10)}
+		if result := <-app.Srv.Store.System().SaveOrUpdate(system); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+	}
+}
+
+func (ce *ComplianceExporter) RunComplianceJob(job *model.Compliance) *model.AppError {
+	_, err := ce.runJob(job)
+	return err
+}
+
+// runJob writes a job's export to the file store and returns the time of the last post that was exported.
+func (ce *ComplianceExporter) runJob(job *model.Compliance) (int64, *model.AppError) {
+	path := getExportPath(job)
+	logParams := map[string]interface{}{"JobName": job.JobName(), "FilePath": path}
+
+	l4g.Info(utils.T("ent.compliance.run_started.info", logParams))
+
+	job.Status = model.COMPLIANCE_STATUS_RUNNING
+	if result := <-app.Srv.Store.Compliance().Update(job); result.Err != nil {
+		l4g.Error(utils.T("ent.compliance.run_failed.error", logParams) + " err=" + result.Err.Error())
+		return 0, result.Err
+	}
+
+	// Stream the export into the file store as it's generated so that it never has to be held in memory
+	reader, writer := io.Pipe()
+	exportDone := make(chan bool)
+
+	var count int
+	var lastPostTime int64
+	var exportErr *model.AppError
+
+	go func() {
+		defer close(exportDone)
+
+		count, lastPostTime, exportErr = exportJob(job, writer)
+		if exportErr != nil {
+			writer.CloseWithError(exportErr)
+		} else {
+			writer.Close()
+		}
+	}()
+
+	_, writeErr := app.WriteFileStream(reader, path)
+
+	// Unblock the export if the file store stopped reading early
+	reader.Close()
+	<-exportDone
+
+	if exportErr == nil {
+		exportErr = writeErr
+	}
+
+	if exportErr != nil {
+		l4g.Error(utils.T("ent.compliance.run_failed.error", logParams) + " err=" + exportErr.Error())
+
+		// Don't leave behind a partial export that looks like it could be downloaded
+		app.RemoveFile(path)
+
+		job.Status = model.COMPLIANCE_STATUS_FAILED
+		if result := <-app.Srv.Store.Compliance().Update(job); result.Err != nil {
+			l4g.Error(result.Err.Error())
+		}
+
+		return 0, exportErr
+	}
+
+	job.Status = model.COMPLIANCE_STATUS_FINISHED
+	job.Count = count
+	if result := <-app.Srv.Store.Compliance().Update(job); result.Err != nil {
+		l4g.Error(result.Err.Error())
+		return 0, result.Err
+	}
+
+	logParams["Count"] = count
+	l4g.Info(utils.T("ent.compliance.run_finished.info", logParams))
+
+	return lastPostTime, nil
+}
+
+// exportJob writes a zip file containing every post matching the job to w. It returns the number of posts exported
+// and the time of the last one.
+func exportJob(job *model.Compliance, w io.Writer) (int, int64, *model.AppError) {
+	var channelIds []string
+	if result := <-app.Srv.Store.Compliance().GetChannelIdsForExport(job); result.Err != nil {
+		return 0, 0, result.Err
+	} else {
+		channelIds = result.Data.([]string)
+	}
+
+	zipWriter := zip.NewWriter(w)
+	exporter := newExportWriter(*utils.Cfg.ComplianceSettings.ExportFormat, zipWriter, job)
+	members := map[string]*model.User{}
+
+	count := 0
+	var lastPostTime int64
+
+	for _, channelId := range channelIds {
+		afterTime := job.StartAt
+		afterPostId := ""
+		started := false
+
+		for {
+			var cposts []*model.CompliancePost
+			if result := <-app.Srv.Store.Compliance().ComplianceExportBatch(job, channelId, afterTime, afterPostId, EXPORT_BATCH_SIZE); result.Err != nil {
+				return 0, 0, result.Err
+			} else {
+				cposts = result.Data.([]*model.CompliancePost)
+			}
+
+			posts, err := getExportPosts(cposts, members)
+			if err != nil {
+				return 0, 0, err
+			}
+
+			for _, post := range posts {
+				if !started {
+					if err := exporter.StartChannel(post); err != nil {
+						return 0, 0, newExportError(job, err)
+					}
+					started = true
+				}
+
+				if err := exporter.WritePost(post); err != nil {
+					return 0, 0, newExportError(job, err)
+				}
+
+				if post.PostCreateAt > lastPostTime {
+					lastPostTime = post.PostCreateAt
+				}
+			}
+
+			count += len(posts)
+
+			if len(cposts) < EXPORT_BATCH_SIZE {
+				break
+			}
+
+			afterTime = cposts[len(cposts)-1].PostCreateAt
+			afterPostId = cposts[len(cposts)-1].PostId
+		}
+
+		if started {
+			if err := exporter.EndChannel(); err != nil {
+				return 0, 0, newExportError(job, err)
+			}
+		}
+	}
+
+	if err := exporter.Close(); err != nil {
+		return 0, 0, newExportError(job, err)
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return 0, 0, newExportError(job, err)
+	}
+
+	return count, lastPostTime, nil
+}
+
+// getExportPosts looks up the files attached to a batch of posts and the users whose membership changed in any
+// join/leave messages. Members are cached in the given map since they're likely to appear again.
+func getExportPosts(cposts []*model.CompliancePost, members map[string]*model.User) ([]*exportPost, *model.AppError) {
+	postIds := []string{}
+	for _, cpost := range cposts {
+		if cpost.PostFileIds != "" && cpost.PostFileIds != "[]" {
+			postIds = append(postIds, cpost.PostId)
+		}
+	}
+
+	files := map[string][]*model.FileInfo{}
+	if result := <-app.Srv.Store.FileInfo().GetForPostIds(postIds); result.Err != nil {
+		return nil, result.Err
+	} else {
+		for _, info := range result.Data.([]*model.FileInfo) {
+			files[info.PostId] = append(files[info.PostId], info)
+		}
+	}
+
+	posts := make([]*exportPost, len(cposts))
+	for i, cpost := range cposts {
+		post := &exportPost{
+			CompliancePost: cpost,
+			Files:          files[cpost.PostId],
+		}
+
+		if cpost.PostType == model.POST_JOIN_LEAVE || cpost.PostType == model.POST_ADD_REMOVE {
+			props := model.StringInterfaceFromJson(strings.NewReader(cpost.PostProps))
+			memberEvent, _ := props[model.POST_PROPS_MEMBER_EVENT].(string)
+			memberUserId, _ := props[model.POST_PROPS_MEMBER_USER_ID].(string)
+
+			if memberEvent != "" && memberUserId != "" {
+				member, ok := members[memberUserId]
+				if !ok {
+					if result := <-app.Srv.Store.User().Get(memberUserId); result.Err == nil {
+						member = result.Data.(*model.User)
+					}
+					members[memberUserId] = member
+				}
+
+				if member != nil {
+					post.MemberEvent = memberEvent
+					post.Member = member
+				}
+			}
+		}
+
+		posts[i] = post
+	}
+
+	return posts, nil
+}
+
+func newExportError(job *model.Compliance, err error) *model.AppError {
+	return model.NewLocAppError("ComplianceExporter.RunComplianceJob", "compliance.export.write.app_error", nil, "job_id="+job.Id+", err="+err.Error())
+}
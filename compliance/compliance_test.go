@@ -0,0 +1,208 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io/ioutil"
+	"mime/quotedprintable"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func getTestExportPosts() []*exportPost {
+	channelId := model.NewId()
+	member := &model.User{Id: model.NewId(), Username: "member", Email: "member@example.com"}
+
+	return []*exportPost{
+		{
+			CompliancePost: &model.CompliancePost{
+				TeamName:           "team",
+				ChannelId:          channelId,
+				ChannelName:        "channel",
+				ChannelDisplayName: "Channel",
+				ChannelType:        model.CHANNEL_OPEN,
+				UserUsername:       "poster",
+				UserEmail:          "poster@example.com",
+				PostId:             model.NewId(),
+				PostCreateAt:       1000,
+				PostUpdateAt:       1000,
+				PostType:           model.POST_ADD_REMOVE,
+				PostMessage:        "member added to the channel by poster",
+			},
+			MemberEvent: model.POST_MEMBER_EVENT_JOIN,
+			Member:      member,
+		},
+		{
+			CompliancePost: &model.CompliancePost{
+				TeamName:           "team",
+				ChannelId:          channelId,
+				ChannelName:        "channel",
+				ChannelDisplayName: "Channel",
+				ChannelType:        model.CHANNEL_OPEN,
+				UserUsername:       "member",
+				UserEmail:          "member@example.com",
+				PostId:             model.NewId(),
+				PostCreateAt:       2000,
+				PostUpdateAt:       2000,
+				PostMessage:        "hello <world>",
+				PostFileIds:        `["file"]`,
+			},
+			Files: []*model.FileInfo{
+				{Id: model.NewId(), Name: "file.txt", Size: 123, MimeType: "text/plain", CreateAt: 2000},
+			},
+		},
+	}
+}
+
+// writeTestExport writes the test posts using the given format and returns the contents of each file in the zip.
+func writeTestExport(t *testing.T, format string) map[string]string {
+	buffer := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buffer)
+	exporter := newExportWriter(format, zipWriter, &model.Compliance{Id: model.NewId()})
+
+	posts := getTestExportPosts()
+
+	if err := exporter.StartChannel(posts[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, post := range posts {
+		if err := exporter.WritePost(post); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := exporter.EndChannel(); err != nil {
+		t.Fatal(err)
+	} else if err := exporter.Close(); err != nil {
+		t.Fatal(err)
+	} else if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	for _, f := range zipReader.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[f.Name] = string(data)
+	}
+
+	return files
+}
+
+func TestCsvExport(t *testing.T) {
+	files := writeTestExport(t, model.COMPLIANCE_EXPORT_FORMAT_CSV)
+
+	if rows, err := csv.NewReader(strings.NewReader(files[CSV_POSTS_FILE])).ReadAll(); err != nil {
+		t.Fatal(err)
+	} else if len(rows) != 3 {
+		t.Fatal("should've had a header and a row for each post")
+	} else if rows[2][len(model.CompliancePostHeader())-5] != "hello <world>" {
+		t.Fatal("should've written the message")
+	}
+
+	if rows, err := csv.NewReader(strings.NewReader(files[CSV_ATTACHMENTS_FILE])).ReadAll(); err != nil {
+		t.Fatal(err)
+	} else if len(rows) != 2 {
+		t.Fatal("should've had a header and a row for the attachment")
+	} else if rows[1][2] != "file.txt" || rows[1][4] != "123" {
+		t.Fatal("should've written the attachment's details")
+	}
+}
+
+func TestActianceExport(t *testing.T) {
+	files := writeTestExport(t, model.COMPLIANCE_EXPORT_FORMAT_ACTIANCE)
+
+	var export struct {
+		Channels []struct {
+			RoomID       string
+			StartTimeUTC int64
+			EndTimeUTC   int64
+			JoinEvent    []actianceEvent
+			Message      []actianceMessage
+			Transfers    []actianceFileTransfer `xml:"FileTransferEnded"`
+		} `xml:"Channel"`
+	}
+
+	if err := xml.Unmarshal([]byte(files[ACTIANCE_EXPORT_FILE]), &export); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(export.Channels) != 1 {
+		t.Fatal("should've written one channel")
+	}
+
+	channel := export.Channels[0]
+	if !strings.HasPrefix(channel.RoomID, "public - channel - ") || channel.StartTimeUTC != 1 || channel.EndTimeUTC != 2 {
+		t.Fatal("wrote the wrong channel details")
+	}
+
+	if len(channel.JoinEvent) != 1 || channel.JoinEvent[0].LoginName != "member@example.com" {
+		t.Fatal("should've written the join event for the added user")
+	}
+
+	if len(channel.Message) != 1 || channel.Message[0].Content != "hello <world>" {
+		t.Fatal("should've written the message")
+	}
+
+	if len(channel.Transfers) != 1 || channel.Transfers[0].FileName != "file.txt" || channel.Transfers[0].FileSize != 123 {
+		t.Fatal("should've written the file transfer")
+	}
+}
+
+func TestGlobalRelayExport(t *testing.T) {
+	files := writeTestExport(t, model.COMPLIANCE_EXPORT_FORMAT_GLOBALRELAY)
+
+	if len(files) != 1 {
+		t.Fatal("should've written one file for the channel")
+	}
+
+	for name, eml := range files {
+		if !strings.HasSuffix(name, ".eml") {
+			t.Fatal("should've written an eml file")
+		}
+
+		if !strings.Contains(eml, "From: \"poster\" <poster@example.com>\r\n") {
+			t.Fatal("should've been from the first user in the conversation")
+		}
+
+		if !strings.Contains(eml, "To: \"poster\" <poster@example.com>, \"member\" <member@example.com>\r\n") {
+			t.Fatal("should've been to everyone in the conversation")
+		}
+
+		parts := strings.SplitN(eml, "\r\n\r\n", 2)
+		body, err := ioutil.ReadAll(quotedprintable.NewReader(strings.NewReader(parts[1])))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(body), "@member <member@example.com> joined the channel") {
+			t.Fatal("should've included the join event")
+		}
+
+		if !strings.Contains(string(body), "Attachment: file.txt (123 bytes, text/plain)") {
+			t.Fatal("should've included the attachment")
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	CSV_POSTS_FILE       = "posts.csv"
+	CSV_ATTACHMENTS_FILE = "attachments.csv"
+)
+
+// csvWriter writes one row for each post to posts.csv in the same format as model.CompliancePost.Row and one row for
+// each file attached to them to attachments.csv.
+type csvWriter struct {
+	zipWriter         *zip.Writer
+	posts             *csv.Writer
+	attachments       *bytes.Buffer
+	attachmentsWriter *csv.Writer
+}
+
+func csvAttachmentHeader() []string {
+	return []string{
+		"PostId",
+		"FileId",
+		"FileName",
+		"FileExtension",
+		"FileSize",
+		"FileMimeType",
+		"FilePath",
+		"FileCreateAt",
+		"FileDeleteAt",
+	}
+}
+
+func csvAttachmentRow(info *model.FileInfo) []string {
+	deleteAt := ""
+	if info.DeleteAt > 0 {
+		deleteAt = formatExportTime(info.DeleteAt)
+	}
+
+	return []string{
+		info.PostId,
+		info.Id,
+		info.Name,
+		info.Extension,
+		strconv.FormatInt(info.Size, 10),
+		info.MimeType,
+		info.Path,
+		formatExportTime(info.CreateAt),
+		deleteAt,
+	}
+}
+
+func formatExportTime(millis int64) string {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+}
+
+func newCsvWriter(w *zip.Writer) exportWriter {
+	return &csvWriter{
+		zipWriter: w,
+	}
+}
+
+func (cw *csvWriter) start() error {
+	if cw.posts != nil {
+		return nil
+	}
+
+	if f, err := cw.zipWriter.Create(CSV_POSTS_FILE); err != nil {
+		return err
+	} else {
+		cw.posts = csv.NewWriter(f)
+	}
+
+	// Only one file in a zip can be written at a time, so the attachments are saved until all of the posts are written
+	cw.attachments = &bytes.Buffer{}
+	cw.attachmentsWriter = csv.NewWriter(cw.attachments)
+
+	if err := cw.posts.Write(model.CompliancePostHeader()); err != nil {
+		return err
+	}
+
+	return cw.attachmentsWriter.Write(csvAttachmentHeader())
+}
+
+func (cw *csvWriter) StartChannel(post *exportPost) error {
+	return cw.start()
+}
+
+func (cw *csvWriter) WritePost(post *exportPost) error {
+	if err := cw.posts.Write(post.Row()); err != nil {
+		return err
+	}
+
+	for _, info := range post.Files {
+		if err := cw.attachmentsWriter.Write(csvAttachmentRow(info)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cw *csvWriter) EndChannel() error {
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	// Always include the headers even if there was nothing to export
+	if err := cw.start(); err != nil {
+		return err
+	}
+
+	cw.posts.Flush()
+	if err := cw.posts.Error(); err != nil {
+		return err
+	}
+
+	cw.attachmentsWriter.Flush()
+	if err := cw.attachmentsWriter.Error(); err != nil {
+		return err
+	}
+
+	if f, err := cw.zipWriter.Create(CSV_ATTACHMENTS_FILE); err != nil {
+		return err
+	} else if _, err := cw.attachments.WriteTo(f); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package compliance
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+// globalRelayWriter writes each channel as a separate EML file containing the whole conversation so that it can be
+// archived by GlobalRelay. The conversation is kept in memory until the channel ends since its headers list everyone
+// that took part in it.
+type globalRelayWriter struct {
+	zipWriter    *zip.Writer
+	job          *model.Compliance
+	firstPost    *exportPost
+	lastPost     *exportPost
+	participants []*mail.Address
+	emails       map[string]bool
+	body         *bytes.Buffer
+	bodyWriter   *quotedprintable.Writer
+}
+
+func newGlobalRelayWriter(w *zip.Writer, job *model.Compliance) exportWriter {
+	return &globalRelayWriter{
+		zipWriter: w,
+		job:       job,
+	}
+}
+
+func formatEmlTime(millis int64) string {
+	return time.Unix(0, millis*int64(time.Millisecond)).UTC().Format(time.RFC1123Z)
+}
+
+func (gw *globalRelayWriter) addParticipant(username string, email string) {
+	if gw.emails[email] {
+		return
+	}
+
+	gw.emails[email] = true
+	gw.participants = append(gw.participants, &mail.Address{Name: username, Address: email})
+}
+
+func (gw *globalRelayWriter) StartChannel(post *exportPost) error {
+	gw.firstPost = post
+	gw.participants = []*mail.Address{}
+	gw.emails = map[string]bool{}
+	gw.body = &bytes.Buffer{}
+	gw.bodyWriter = quotedprintable.NewWriter(gw.body)
+
+	return nil
+}
+
+func (gw *globalRelayWriter) WritePost(post *exportPost) error {
+	gw.lastPost = post
+	gw.addParticipant(post.UserUsername, post.UserEmail)
+
+	line := fmt.Sprintf("[%s] @%s <%s>", formatExportTime(post.PostCreateAt), post.UserUsername, post.UserEmail)
+
+	if post.MemberEvent != "" {
+		gw.addParticipant(post.Member.Username, post.Member.Email)
+
+		if post.MemberEvent == model.POST_MEMBER_EVENT_LEAVE {
+			line += fmt.Sprintf(" (@%s <%s> left the channel)", post.Member.Username, post.Member.Email)
+		} else {
+			line += fmt.Sprintf(" (@%s <%s> joined the channel)", post.Member.Username, post.Member.Email)
+		}
+	}
+
+	if post.PostDeleteAt > 0 {
+		line += " (deleted " + formatExportTime(post.PostDeleteAt) + ")"
+	}
+
+	line += ": " + post.PostMessage + "\r\n"
+
+	for _, info := range post.Files {
+		line += fmt.Sprintf("    Attachment: %s (%d bytes, %s)\r\n", info.Name, info.Size, info.MimeType)
+	}
+
+	_, err := gw.bodyWriter.Write([]byte(line))
+	return err
+}
+
+func (gw *globalRelayWriter) EndChannel() error {
+	if err := gw.bodyWriter.Close(); err != nil {
+		return err
+	}
+
+	f, err := gw.zipWriter.Create(gw.firstPost.ChannelId + ".eml")
+	if err != nil {
+		return err
+	}
+
+	to := make([]string, len(gw.participants))
+	for i, participant := range gw.participants {
+		to[i] = participant.String()
+	}
+
+	channelName := gw.firstPost.ChannelDisplayName
+	if channelName == "" {
+		channelName = gw.firstPost.ChannelName
+	}
+
+	headers := []string{
+		"From: " + gw.participants[0].String(),
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + mime.QEncoding.Encode("utf-8", "Mattermost Compliance Export: "+channelName),
+		"Date: " + formatEmlTime(gw.firstPost.PostCreateAt),
+		"Message-ID: <" + gw.firstPost.ChannelId + "." + gw.job.Id + "@mattermost>",
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"UTF-8\"",
+		"Content-Transfer-Encoding: quoted-printable",
+		"X-Mattermost-ChannelId: " + gw.firstPost.ChannelId,
+		"X-Mattermost-ChannelType: " + getChannelTypeName(gw.firstPost.ChannelType),
+		"X-Mattermost-TeamName: " + gw.firstPost.TeamName,
+		"X-Mattermost-ConversationEnd: " + formatEmlTime(gw.lastPost.PostCreateAt),
+	}
+
+	if _, err := f.Write([]byte(strings.Join(headers, "\r\n") + "\r\n\r\n")); err != nil {
+		return err
+	}
+
+	_, err = gw.body.WriteTo(f)
+	return err
+}
+
+func (gw *globalRelayWriter) Close() error {
+	return nil
+}
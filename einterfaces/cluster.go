@@ -22,6 +22,13 @@ type ClusterInterface interface {
 	GetClusterId() string
 	ConfigChanged(previousConfig *model.Config, newConfig *model.Config, sendToOtherServer bool) *model.AppError
 	InvalidateAllCaches() *model.AppError
+	IndexPost(post *model.Post)
+	DeletePostFromIndex(postId string)
+	IndexChannel(channel *model.Channel)
+	DeleteChannelFromIndex(channelId string)
+	IndexUser(userId string)
+	DeleteUserFromIndex(userId string)
+	PluginStateChanged(pluginId string, state *model.PluginState)
 }
 
 var theClusterInterface ClusterInterface
@@ -28,6 +28,8 @@ type MetricsInterface interface {
 
 	AddMemCacheHitCounter(cacheName string, amount float64)
 	AddMemCacheMissCounter(cacheName string, amount float64)
+
+	ObserveStoreMethodDuration(method string, success bool, elapsed float64)
 }
 
 var theMetricsInterface MetricsInterface
@@ -12,6 +12,9 @@ type MfaInterface interface {
 	Activate(user *model.User, token string) *model.AppError
 	Deactivate(userId string) *model.AppError
 	ValidateToken(secret, token string) (bool, *model.AppError)
+	UseToken(user *model.User, token string) (bool, *model.AppError)
+	GenerateRecoveryCodes(user *model.User) ([]string, *model.AppError)
+	ValidateRecoveryCode(user *model.User, code string) (bool, *model.AppError)
 }
 
 var theMfaInterface MfaInterface
@@ -14,6 +14,15 @@ type OauthProvider interface {
 	GetAuthDataFromJson(data io.Reader) string
 }
 
+// OpenIdProvider is implemented by the providers that find their endpoints using OpenID Connect discovery and identify
+// users with a signed ID token instead of a user API endpoint.
+type OpenIdProvider interface {
+	OauthProvider
+	GetAuthEndpoint(settings *model.SSOSettings) (string, *model.AppError)
+	RedeemCode(settings *model.SSOSettings, code string, redirectUri string) (*model.OpenIdTokens, *model.AppError)
+	RefreshTokens(settings *model.SSOSettings, refreshToken string) (*model.OpenIdTokens, *model.AppError)
+}
+
 var oauthProviders = make(map[string]OauthProvider)
 
 func RegisterOauthProvider(name string, newProvider OauthProvider) {
@@ -0,0 +1,40 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package einterfaces
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+type SearchEngineInterface interface {
+	Start() *model.AppError
+	Stop() *model.AppError
+
+	IsIndexingEnabled() bool
+	IsSearchEnabled() bool
+
+	IndexPost(post *model.Post) *model.AppError
+	SearchPosts(channelIds []string, fromUserIds []string, params *model.SearchParams) ([]string, *model.AppError)
+	DeletePost(postId string) *model.AppError
+
+	IndexChannel(channel *model.Channel) *model.AppError
+	SearchChannels(teamId string, term string) ([]string, *model.AppError)
+	DeleteChannel(channelId string) *model.AppError
+
+	IndexUser(user *model.User, teamIds []string) *model.AppError
+	SearchUsersInTeam(teamId string, term string) ([]string, *model.AppError)
+	DeleteUser(userId string) *model.AppError
+
+	PurgeIndexes() *model.AppError
+}
+
+var theSearchEngineInterface SearchEngineInterface
+
+func RegisterSearchEngineInterface(newInterface SearchEngineInterface) {
+	theSearchEngineInterface = newInterface
+}
+
+func GetSearchEngineInterface() SearchEngineInterface {
+	return theSearchEngineInterface
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	METRICS_NAMESPACE = "mattermost"
+	METRICS_PATH      = "/metrics"
+)
+
+// PrometheusMetrics records the server's metrics and serves them in the Prometheus format on
+// MetricsSettings.ListenAddress while MetricsSettings.Enable is true.
+type PrometheusMetrics struct {
+	registry *prometheus.Registry
+
+	server      *http.Server
+	listener    net.Listener
+	serverMutex sync.Mutex
+
+	postCreate         prometheus.Counter
+	postSentEmail      prometheus.Counter
+	postSentPush       prometheus.Counter
+	postBroadcast      prometheus.Counter
+	postFileAttachment prometheus.Counter
+
+	httpRequest         prometheus.Counter
+	httpError           prometheus.Counter
+	httpRequestDuration prometheus.Histogram
+
+	login     prometheus.Counter
+	loginFail prometheus.Counter
+
+	etagHit  *prometheus.CounterVec
+	etagMiss *prometheus.CounterVec
+
+	memCacheHit  *prometheus.CounterVec
+	memCacheMiss *prometheus.CounterVec
+
+	storeMethodDuration *prometheus.HistogramVec
+}
+
+func init() {
+	einterfaces.RegisterMetricsInterface(NewPrometheusMetrics())
+}
+
+func newCounter(subsystem string, name string, help string) prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	})
+}
+
+func newCounterVec(subsystem string, name string, help string, labels ...string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, labels)
+}
+
+func newGaugeFunc(subsystem string, name string, help string, function func() int) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: METRICS_NAMESPACE,
+		Subsystem: subsystem,
+		Name:      name,
+		Help:      help,
+	}, func() float64 {
+		return float64(function())
+	})
+}
+
+func NewPrometheusMetrics() *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		registry: prometheus.NewRegistry(),
+
+		postCreate:         newCounter("post", "total", "The total number of posts created."),
+		postSentEmail:      newCounter("post", "emails_sent_total", "The total number of notification emails sent."),
+		postSentPush:       newCounter("post", "pushes_sent_total", "The total number of push notifications sent."),
+		postBroadcast:      newCounter("post", "broadcasts_total", "The total number of websocket broadcasts sent because a post was created."),
+		postFileAttachment: newCounter("post", "file_attachments_total", "The total number of files attached to posts."),
+
+		httpRequest: newCounter("http", "requests_total", "The total number of API requests."),
+		httpError:   newCounter("http", "errors_total", "The total number of API requests that returned an error."),
+		httpRequestDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: METRICS_NAMESPACE,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "The time taken to handle each API request.",
+		}),
+
+		login:     newCounter("login", "logins_total", "The total number of successful logins."),
+		loginFail: newCounter("login", "logins_fail_total", "The total number of failed logins."),
+
+		etagHit:  newCounterVec("cache", "etag_hit_total", "The total number of requests whose etag matched, by route.", "route"),
+		etagMiss: newCounterVec("cache", "etag_miss_total", "The total number of requests whose etag didn't match, by route.", "route"),
+
+		memCacheHit:  newCounterVec("cache", "mem_hit_total", "The total number of memory cache hits, by cache.", "name"),
+		memCacheMiss: newCounterVec("cache", "mem_miss_total", "The total number of memory cache misses, by cache.", "name"),
+
+		storeMethodDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: METRICS_NAMESPACE,
+			Subsystem: "db",
+			Name:      "store_time",
+			Help:      "The time taken by each call to the store, in seconds.",
+		}, []string{"method", "success"}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewProcessCollector(os.Getpid(), METRICS_NAMESPACE),
+		prometheus.NewGoCollector(),
+
+		m.postCreate,
+		m.postSentEmail,
+		m.postSentPush,
+		m.postBroadcast,
+		m.postFileAttachment,
+
+		m.httpRequest,
+		m.httpError,
+		m.httpRequestDuration,
+
+		m.login,
+		m.loginFail,
+
+		m.etagHit,
+		m.etagMiss,
+
+		m.memCacheHit,
+		m.memCacheMiss,
+
+		m.storeMethodDuration,
+
+		&websocketCollector{
+			connections: prometheus.NewDesc(
+				prometheus.BuildFQName(METRICS_NAMESPACE, "websocket", "connections"),
+				"The number of websocket connections held by each hub.",
+				[]string{"hub"},
+				nil,
+			),
+		},
+
+		newGaugeFunc("email", "queue_size", "The number of notifications waiting to be sent in a batched email.", app.EmailBatchingQueueSize),
+		newGaugeFunc("push", "queue_size", "The number of push notifications that are being sent.", app.PushNotificationQueueSize),
+	)
+
+	return m
+}
+
+// websocketCollector reports the number of connections held by each hub when the metrics are scraped.
+type websocketCollector struct {
+	connections *prometheus.Desc
+}
+
+func (c *websocketCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+}
+
+func (c *websocketCollector) Collect(ch chan<- prometheus.Metric) {
+	for i, count := range app.WebsocketConnectionsPerHub() {
+		ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(count), strconv.Itoa(i))
+	}
+}
+
+func (m *PrometheusMetrics) StartServer() {
+	m.serverMutex.Lock()
+	defer m.serverMutex.Unlock()
+
+	if !*utils.Cfg.MetricsSettings.Enable {
+		return
+	}
+
+	if m.server != nil {
+		// The server may be restarted on a different address after the config changes
+		if m.listener.Addr().String() == *utils.Cfg.MetricsSettings.ListenAddress {
+			return
+		}
+
+		m.stopServer()
+	}
+
+	runtime.SetBlockProfileRate(*utils.Cfg.MetricsSettings.BlockProfileRate)
+
+	listener, err := net.Listen("tcp", *utils.Cfg.MetricsSettings.ListenAddress)
+	if err != nil {
+		l4g.Error(utils.T("ent.metrics.starting.error"), *utils.Cfg.MetricsSettings.ListenAddress, err.Error())
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(METRICS_PATH, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	m.listener = listener
+	m.server = &http.Server{Handler: mux}
+
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			l4g.Error(utils.T("ent.metrics.starting.error"), listener.Addr().String(), err.Error())
+		}
+	}(m.server)
+
+	l4g.Info(utils.T("ent.metrics.starting.info"), *utils.Cfg.MetricsSettings.ListenAddress)
+}
+
+func (m *PrometheusMetrics) StopServer() {
+	m.serverMutex.Lock()
+	defer m.serverMutex.Unlock()
+
+	m.stopServer()
+}
+
+func (m *PrometheusMetrics) stopServer() {
+	if m.server == nil {
+		return
+	}
+
+	l4g.Info(utils.T("ent.metrics.stopping.info"), m.listener.Addr().String())
+
+	m.server.Close()
+	m.server = nil
+	m.listener = nil
+}
+
+func (m *PrometheusMetrics) IncrementPostCreate() {
+	m.postCreate.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementPostSentEmail() {
+	m.postSentEmail.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementPostSentPush() {
+	m.postSentPush.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementPostBroadcast() {
+	m.postBroadcast.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementPostFileAttachment(count int) {
+	m.postFileAttachment.Add(float64(count))
+}
+
+func (m *PrometheusMetrics) IncrementHttpRequest() {
+	m.httpRequest.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementHttpError() {
+	m.httpError.Inc()
+}
+
+func (m *PrometheusMetrics) ObserveHttpRequestDuration(elapsed float64) {
+	m.httpRequestDuration.Observe(elapsed)
+}
+
+func (m *PrometheusMetrics) IncrementLogin() {
+	m.login.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementLoginFail() {
+	m.loginFail.Inc()
+}
+
+func (m *PrometheusMetrics) IncrementEtagHitCounter(route string) {
+	m.etagHit.WithLabelValues(route).Inc()
+}
+
+func (m *PrometheusMetrics) IncrementEtagMissCounter(route string) {
+	m.etagMiss.WithLabelValues(route).Inc()
+}
+
+func (m *PrometheusMetrics) IncrementMemCacheHitCounter(cacheName string) {
+	m.memCacheHit.WithLabelValues(cacheName).Inc()
+}
+
+func (m *PrometheusMetrics) IncrementMemCacheMissCounter(cacheName string) {
+	m.memCacheMiss.WithLabelValues(cacheName).Inc()
+}
+
+func (m *PrometheusMetrics) AddMemCacheHitCounter(cacheName string, amount float64) {
+	m.memCacheHit.WithLabelValues(cacheName).Add(amount)
+}
+
+func (m *PrometheusMetrics) AddMemCacheMissCounter(cacheName string, amount float64) {
+	m.memCacheMiss.WithLabelValues(cacheName).Add(amount)
+}
+
+func (m *PrometheusMetrics) ObserveStoreMethodDuration(method string, success bool, elapsed float64) {
+	m.storeMethodDuration.WithLabelValues(method, strconv.FormatBool(success)).Observe(elapsed)
+}
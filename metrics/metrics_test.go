@@ -0,0 +1,155 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/utils"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func setupMetricsTest() {
+	utils.TranslationsPreInit()
+	utils.LoadTestConfig()
+	utils.InitTranslations(utils.Cfg.LocalizationSettings)
+}
+
+func findMetric(t *testing.T, m *PrometheusMetrics, name string) *dto.MetricFamily {
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() == name {
+			return family
+		}
+	}
+
+	return nil
+}
+
+func TestCounters(t *testing.T) {
+	setupMetricsTest()
+
+	m := NewPrometheusMetrics()
+
+	m.IncrementPostCreate()
+	m.IncrementPostCreate()
+	m.IncrementPostFileAttachment(3)
+	m.IncrementEtagHitCounter("/api/v3/users/me")
+
+	if family := findMetric(t, m, "mattermost_post_total"); family == nil || family.Metric[0].Counter.GetValue() != 2 {
+		t.Fatal("should've counted the created posts")
+	}
+
+	if family := findMetric(t, m, "mattermost_post_file_attachments_total"); family == nil || family.Metric[0].Counter.GetValue() != 3 {
+		t.Fatal("should've counted the file attachments")
+	}
+
+	if family := findMetric(t, m, "mattermost_cache_etag_hit_total"); family == nil {
+		t.Fatal("should've counted the etag hit")
+	} else if label := family.Metric[0].Label[0]; label.GetName() != "route" || label.GetValue() != "/api/v3/users/me" {
+		t.Fatal("should've labelled the etag hit with its route")
+	}
+}
+
+func TestObserveStoreMethodDuration(t *testing.T) {
+	setupMetricsTest()
+
+	m := NewPrometheusMetrics()
+
+	m.ObserveStoreMethodDuration("UserStore.Get", true, 0.01)
+	m.ObserveStoreMethodDuration("UserStore.Get", true, 0.02)
+	m.ObserveStoreMethodDuration("UserStore.Get", false, 0.5)
+
+	family := findMetric(t, m, "mattermost_db_store_time")
+	if family == nil {
+		t.Fatal("should've recorded the store method durations")
+	}
+
+	counts := map[string]uint64{}
+	for _, metric := range family.Metric {
+		labels := map[string]string{}
+		for _, label := range metric.Label {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["method"] != "UserStore.Get" {
+			t.Fatal("should've labelled the durations with the method")
+		}
+
+		counts[labels["success"]] = metric.Histogram.GetSampleCount()
+	}
+
+	if counts["true"] != 2 || counts["false"] != 1 {
+		t.Fatal("should've recorded successful and failed calls separately")
+	}
+}
+
+func TestQueueGauges(t *testing.T) {
+	setupMetricsTest()
+
+	m := NewPrometheusMetrics()
+
+	if family := findMetric(t, m, "mattermost_email_queue_size"); family == nil || family.Metric[0].Gauge.GetValue() != 0 {
+		t.Fatal("should've reported an empty email queue")
+	}
+
+	if family := findMetric(t, m, "mattermost_push_queue_size"); family == nil || family.Metric[0].Gauge.GetValue() != 0 {
+		t.Fatal("should've reported an empty push notification queue")
+	}
+}
+
+func TestServer(t *testing.T) {
+	setupMetricsTest()
+
+	enable := *utils.Cfg.MetricsSettings.Enable
+	listenAddress := *utils.Cfg.MetricsSettings.ListenAddress
+	defer func() {
+		*utils.Cfg.MetricsSettings.Enable = enable
+		*utils.Cfg.MetricsSettings.ListenAddress = listenAddress
+	}()
+
+	m := NewPrometheusMetrics()
+
+	*utils.Cfg.MetricsSettings.Enable = false
+	m.StartServer()
+	if m.server != nil {
+		t.Fatal("shouldn't have started the server while metrics are disabled")
+	}
+
+	*utils.Cfg.MetricsSettings.Enable = true
+	*utils.Cfg.MetricsSettings.ListenAddress = "127.0.0.1:0"
+	m.StartServer()
+	defer m.StopServer()
+
+	if m.server == nil {
+		t.Fatal("should've started the server")
+	}
+
+	m.IncrementLogin()
+
+	resp, err := http.Get("http://" + m.listener.Addr().String() + METRICS_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("should've returned the metrics")
+	} else if !strings.Contains(string(body), "mattermost_login_logins_total 1") {
+		t.Fatal("should've included the login counter")
+	}
+
+	m.StopServer()
+	if m.server != nil {
+		t.Fatal("should've stopped the server")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dgryski/dgoogauth"
+	"github.com/mattermost/platform/app"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+	"github.com/mattermost/rsc/qr"
+)
+
+const (
+	// The length of the secrets shared with the user's authenticator app, which RFC 4226 recommends be at least
+	// 160 bits
+	MFA_SECRET_SIZE = 20
+
+	// The length of time that each token is generated for
+	MFA_TIME_STEP_SECONDS = 30
+
+	// The number of time steps around the current one that a token is accepted for, which allows for the clock of
+	// the user's device being slightly off
+	MFA_WINDOW_SIZE = 3
+)
+
+// TotpMfa implements multi-factor authentication with the time-based one-time passwords of RFC 6238, which are
+// generated by authenticator apps like Google Authenticator from a secret shared through a QR code. Users can also
+// sign in with single-use recovery codes if they lose their device.
+type TotpMfa struct {
+}
+
+func init() {
+	einterfaces.RegisterMfaInterface(&TotpMfa{})
+}
+
+// GenerateSecret generates and saves a new secret for a user who is setting up MFA, and returns it along with a QR
+// code of its provisioning URI as a PNG image.
+func (m *TotpMfa) GenerateSecret(user *model.User) (string, []byte, *model.AppError) {
+	if user.MfaActive {
+		return "", nil, model.NewLocAppError("TotpMfa.GenerateSecret", "ent.mfa.generate_secret.active.app_error", nil, "user_id="+user.Id)
+	}
+
+	bytes := make([]byte, MFA_SECRET_SIZE)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", nil, model.NewLocAppError("TotpMfa.GenerateSecret", "ent.mfa.generate_secret.app_error", nil, err.Error())
+	}
+	secret := base32.StdEncoding.EncodeToString(bytes)
+
+	code, err := qr.Encode(getProvisioningUri(user, secret), qr.M)
+	if err != nil {
+		return "", nil, model.NewLocAppError("TotpMfa.GenerateSecret", "ent.mfa.generate_qr_code.create_code.app_error", nil, err.Error())
+	}
+
+	if result := <-app.Srv.Store.User().UpdateMfaSecret(user.Id, secret); result.Err != nil {
+		return "", nil, result.Err
+	}
+
+	return secret, code.PNG(), nil
+}
+
+// getProvisioningUri returns the URI that an authenticator app reads from the QR code. The site name is used as the
+// issuer so that the user can tell which account the tokens are for.
+func getProvisioningUri(user *model.User, secret string) string {
+	issuer := utils.Cfg.TeamSettings.SiteName
+	if len(issuer) == 0 {
+		issuer = "Mattermost"
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+
+	return "otpauth://totp/" + url.PathEscape(issuer) + ":" + url.PathEscape(user.Email) + "?" + query.Encode()
+}
+
+// Activate turns on MFA for a user once they've proven that their authenticator app has the secret generated by
+// GenerateSecret.
+func (m *TotpMfa) Activate(user *model.User, token string) *model.AppError {
+	if len(user.MfaSecret) == 0 {
+		return model.NewLocAppError("TotpMfa.Activate", "ent.mfa.activate.no_secret.app_error", nil, "user_id="+user.Id)
+	}
+
+	if ok, err := m.UseToken(user, token); err != nil {
+		return err
+	} else if !ok {
+		err := model.NewLocAppError("TotpMfa.Activate", "ent.mfa.activate.bad_token.app_error", nil, "user_id="+user.Id)
+		err.StatusCode = http.StatusUnauthorized
+		return err
+	}
+
+	if result := <-app.Srv.Store.User().UpdateMfaActive(user.Id, true); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// Deactivate turns off MFA for a user and forgets their secret and recovery codes.
+func (m *TotpMfa) Deactivate(userId string) *model.AppError {
+	if result := <-app.Srv.Store.User().UpdateMfaActive(userId, false); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-app.Srv.Store.User().UpdateMfaSecret(userId, ""); result.Err != nil {
+		return result.Err
+	}
+
+	if result := <-app.Srv.Store.MfaRecoveryCode().PermanentDeleteByUser(userId); result.Err != nil {
+		return result.Err
+	}
+
+	return nil
+}
+
+// ValidateToken checks whether a token was generated from the secret within the last or next few time steps.
+func (m *TotpMfa) ValidateToken(secret, token string) (bool, *model.AppError) {
+	_, ok, err := getTokenTimeStep(secret, token)
+	return ok, err
+}
+
+// UseToken checks whether a token was generated from the user's secret like ValidateToken does and, if it was, makes
+// sure that it can't be used again. Tokens from time steps before the last one used are rejected too.
+func (m *TotpMfa) UseToken(user *model.User, token string) (bool, *model.AppError) {
+	timeStep, ok, err := getTokenTimeStep(user.MfaSecret, token)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if result := <-app.Srv.Store.User().UseMfaTimeStep(user.Id, timeStep); result.Err != nil {
+		return false, result.Err
+	} else {
+		return result.Data.(bool), nil
+	}
+}
+
+// getTokenTimeStep returns the time step that a token was generated for if it's one of the few around the current one.
+func getTokenTimeStep(secret, token string) (int64, bool, *model.AppError) {
+	if len(secret) == 0 {
+		return 0, false, model.NewLocAppError("TotpMfa.ValidateToken", "ent.mfa.validate_token.no_secret.app_error", nil, "")
+	}
+
+	if _, err := base32.StdEncoding.DecodeString(secret); err != nil {
+		return 0, false, model.NewLocAppError("TotpMfa.ValidateToken", "ent.mfa.validate_token.authenticate.app_error", nil, err.Error())
+	}
+
+	// Only accept the 6 digit tokens generated by authenticator apps
+	if len(token) != 6 {
+		return 0, false, nil
+	}
+
+	now := time.Now().Unix() / MFA_TIME_STEP_SECONDS
+	for timeStep := now - MFA_WINDOW_SIZE/2; timeStep <= now+MFA_WINDOW_SIZE/2; timeStep++ {
+		code := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret, timeStep))
+		if subtle.ConstantTimeCompare([]byte(code), []byte(token)) == 1 {
+			return timeStep, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// GenerateRecoveryCodes replaces a user's recovery codes with new ones and returns them. Only hashes of the codes are
+// saved, so they can't be shown to the user again.
+func (m *TotpMfa) GenerateRecoveryCodes(user *model.User) ([]string, *model.AppError) {
+	if !user.MfaActive {
+		return nil, model.NewLocAppError("TotpMfa.GenerateRecoveryCodes", "ent.mfa.generate_recovery_codes.not_active.app_error", nil, "user_id="+user.Id)
+	}
+
+	codes := make([]string, model.MFA_RECOVERY_CODE_COUNT)
+	hashes := make([]string, model.MFA_RECOVERY_CODE_COUNT)
+	for i := range codes {
+		codes[i] = model.NewMfaRecoveryCode()
+		hashes[i] = model.HashMfaRecoveryCode(codes[i])
+	}
+
+	if result := <-app.Srv.Store.MfaRecoveryCode().Save(user.Id, hashes); result.Err != nil {
+		return nil, result.Err
+	}
+
+	return codes, nil
+}
+
+// ValidateRecoveryCode checks whether the code is one of the user's recovery codes and, if it is, uses it up so that
+// it can't be used again.
+func (m *TotpMfa) ValidateRecoveryCode(user *model.User, code string) (bool, *model.AppError) {
+	if len(code) == 0 {
+		return false, nil
+	}
+
+	if result := <-app.Srv.Store.MfaRecoveryCode().Use(user.Id, model.HashMfaRecoveryCode(code)); result.Err != nil {
+		return false, result.Err
+	} else {
+		return result.Data.(bool), nil
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package mfa
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgryski/dgoogauth"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const TEST_SECRET = "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+
+func setupMfaTest() {
+	utils.TranslationsPreInit()
+	utils.LoadTestConfig()
+	utils.InitTranslations(utils.Cfg.LocalizationSettings)
+}
+
+func tokenAt(t time.Time) string {
+	return fmt.Sprintf("%06d", dgoogauth.ComputeCode(TEST_SECRET, t.Unix()/30))
+}
+
+func TestValidateToken(t *testing.T) {
+	setupMfaTest()
+
+	m := &TotpMfa{}
+	now := time.Now()
+
+	if ok, err := m.ValidateToken(TEST_SECRET, tokenAt(now)); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("should've accepted the current token")
+	}
+
+	if ok, _ := m.ValidateToken(TEST_SECRET, tokenAt(now.Add(-30*time.Second))); !ok {
+		t.Fatal("should've accepted the previous token")
+	}
+
+	if ok, _ := m.ValidateToken(TEST_SECRET, tokenAt(now.Add(-5*time.Minute))); ok {
+		t.Fatal("shouldn't have accepted an old token")
+	}
+
+	for _, token := range []string{"", "abcdef", "12345", "12345678"} {
+		if ok, err := m.ValidateToken(TEST_SECRET, token); err != nil {
+			t.Fatal(err)
+		} else if ok {
+			t.Fatalf("shouldn't have accepted %v", token)
+		}
+	}
+
+	if _, err := m.ValidateToken("", tokenAt(now)); err == nil {
+		t.Fatal("should've failed without a secret")
+	}
+}
+
+func TestGetTokenTimeStep(t *testing.T) {
+	setupMfaTest()
+
+	now := time.Now()
+
+	if timeStep, ok, err := getTokenTimeStep(TEST_SECRET, tokenAt(now.Add(-30*time.Second))); err != nil {
+		t.Fatal(err)
+	} else if !ok || timeStep != now.Unix()/30-1 {
+		t.Fatal("should've returned the time step that the token was generated for", timeStep)
+	}
+
+	if _, ok, _ := getTokenTimeStep(TEST_SECRET, tokenAt(now.Add(-5*time.Minute))); ok {
+		t.Fatal("shouldn't have accepted an old token")
+	}
+
+	if _, _, err := getTokenTimeStep("not base32!", tokenAt(now)); err == nil {
+		t.Fatal("should've failed with an invalid secret")
+	}
+}
+
+func TestGetProvisioningUri(t *testing.T) {
+	setupMfaTest()
+
+	siteName := utils.Cfg.TeamSettings.SiteName
+	defer func() {
+		utils.Cfg.TeamSettings.SiteName = siteName
+	}()
+
+	utils.Cfg.TeamSettings.SiteName = "Example Chat"
+	user := &model.User{Email: "jdoe@example.com"}
+
+	uri, err := url.Parse(getProvisioningUri(user, TEST_SECRET))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Example Chat:jdoe@example.com" {
+		t.Fatal("should've labelled the account with the site name and email", uri.String())
+	}
+
+	if uri.Query().Get("secret") != TEST_SECRET || uri.Query().Get("issuer") != "Example Chat" {
+		t.Fatal("should've included the secret and issuer", uri.String())
+	}
+}
+
+func TestGenerateSecretWhileActive(t *testing.T) {
+	setupMfaTest()
+
+	if _, _, err := (&TotpMfa{}).GenerateSecret(&model.User{Id: model.NewId(), MfaActive: true}); err == nil {
+		t.Fatal("shouldn't have replaced the secret of a user with MFA active")
+	}
+}
+
+func TestGenerateRecoveryCodesWhileInactive(t *testing.T) {
+	setupMfaTest()
+
+	if _, err := (&TotpMfa{}).GenerateRecoveryCodes(&model.User{Id: model.NewId()}); err == nil {
+		t.Fatal("shouldn't have generated recovery codes for a user without MFA")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	BOT_DESCRIPTION_MAX_RUNES = 1024
+
+	POST_PROPS_FROM_BOT = "from_bot"
+)
+
+// Bot describes a user account that's used by an integration instead of a person. Bots can't sign in and instead use
+// personal access tokens, and they're managed by the user who owns them. Bots created by imports don't have an owner
+// and can only be managed by system admins.
+//
+// The username and display name are stored on the bot's user account and are only filled in when a bot is returned
+// by the API.
+type Bot struct {
+	UserId      string `json:"user_id"`
+	Username    string `json:"username" db:"-"`
+	DisplayName string `json:"display_name" db:"-"`
+	Description string `json:"description"`
+	OwnerId     string `json:"owner_id"`
+	CreateAt    int64  `json:"create_at"`
+	UpdateAt    int64  `json:"update_at"`
+	DeleteAt    int64  `json:"delete_at" db:"-"`
+}
+
+func (o *Bot) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func BotFromJson(data io.Reader) *Bot {
+	decoder := json.NewDecoder(data)
+	var o Bot
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func BotsToJson(bots []*Bot) string {
+	b, err := json.Marshal(bots)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func BotsFromJson(data io.Reader) []*Bot {
+	decoder := json.NewDecoder(data)
+	var o []*Bot
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *Bot) PreSave() {
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *Bot) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+func (o *Bot) IsValid() *AppError {
+	if len(o.UserId) != 26 {
+		return NewLocAppError("Bot.IsValid", "model.bot.is_valid.user_id.app_error", nil, "")
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("Bot.IsValid", "model.bot.is_valid.create_at.app_error", nil, "user_id="+o.UserId)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewLocAppError("Bot.IsValid", "model.bot.is_valid.update_at.app_error", nil, "user_id="+o.UserId)
+	}
+
+	if len(o.OwnerId) != 0 && len(o.OwnerId) != 26 {
+		return NewLocAppError("Bot.IsValid", "model.bot.is_valid.owner_id.app_error", nil, "user_id="+o.UserId)
+	}
+
+	if utf8.RuneCountInString(o.Description) > BOT_DESCRIPTION_MAX_RUNES {
+		return NewLocAppError("Bot.IsValid", "model.bot.is_valid.description.app_error", nil, "user_id="+o.UserId)
+	}
+
+	return nil
+}
+
+// SetUser fills in the fields of the bot that are stored on its user account.
+func (o *Bot) SetUser(user *User) {
+	o.Username = user.Username
+	o.DisplayName = user.FirstName
+	o.DeleteAt = user.DeleteAt
+}
+
+// NewBotUser returns the user account for a new bot. Bots don't have a password and their email address is never
+// used, but one is needed since it's required for every user.
+func NewBotUser(username string, displayName string) *User {
+	return &User{
+		Username:      username,
+		Email:         username + "@localhost",
+		EmailVerified: true,
+		FirstName:     displayName,
+		IsBot:         true,
+	}
+}
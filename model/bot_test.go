@@ -0,0 +1,70 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBotJson(t *testing.T) {
+	o := Bot{UserId: NewId(), Username: "bot", OwnerId: NewId(), Description: "posts things"}
+	json := o.ToJson()
+
+	ro := BotFromJson(strings.NewReader(json))
+	if ro.UserId != o.UserId || ro.Username != o.Username || ro.OwnerId != o.OwnerId || ro.Description != o.Description {
+		t.Fatal("ids do not match")
+	}
+
+	bots := BotsFromJson(strings.NewReader(BotsToJson([]*Bot{&o})))
+	if len(bots) != 1 || bots[0].UserId != o.UserId {
+		t.Fatal("ids do not match")
+	}
+}
+
+func TestBotIsValid(t *testing.T) {
+	o := Bot{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.OwnerId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.OwnerId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Description = strings.Repeat("0", BOT_DESCRIPTION_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestNewBotUser(t *testing.T) {
+	user := NewBotUser("bot"+NewId(), "Bot")
+	user.PreSave()
+
+	if !user.IsBot || user.FirstName != "Bot" {
+		t.Fatal("should've created a bot user")
+	}
+
+	if err := user.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
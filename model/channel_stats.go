@@ -9,8 +9,9 @@ import (
 )
 
 type ChannelStats struct {
-	ChannelId   string `json:"channel_id"`
-	MemberCount int64  `json:"member_count"`
+	ChannelId       string `json:"channel_id"`
+	MemberCount     int64  `json:"member_count"`
+	PinnedPostCount int64  `json:"pinnedpost_count"`
 }
 
 func (o *ChannelStats) ToJson() string {
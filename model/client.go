@@ -745,6 +745,19 @@ func (c *Client) UpdateMfa(activate bool, token string) (*Result, *AppError) {
 	}
 }
 
+// GenerateMfaRecoveryCodes replaces the current user's multi-factor authentication
+// recovery codes with new ones. The result's data is the list of new codes, which
+// can each be used once in place of a token.
+func (c *Client) GenerateMfaRecoveryCodes() (*Result, *AppError) {
+	if r, err := c.DoApiPost("/users/generate_mfa_recovery_codes", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), ArrayFromJson(r.Body)}, nil
+	}
+}
+
 func (c *Client) AdminResetMfa(userId string) (*Result, *AppError) {
 	m := make(map[string]string)
 	m["user_id"] = userId
@@ -781,6 +794,150 @@ func (c *Client) GetSessions(id string) (*Result, *AppError) {
 	}
 }
 
+// CreatePersonalAccessToken creates a token that lets scripts use the API as the given user. The token is only
+// returned by this call, so it needs to be saved by the caller.
+func (c *Client) CreatePersonalAccessToken(userId string, description string) (*Result, *AppError) {
+	m := make(map[string]string)
+	m["description"] = description
+
+	if r, err := c.DoApiPost("/users/"+userId+"/tokens/create", MapToJson(m)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PersonalAccessTokenFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) GetPersonalAccessTokens(userId string) (*Result, *AppError) {
+	if r, err := c.DoApiGet("/users/"+userId+"/tokens", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PersonalAccessTokensFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) RevokePersonalAccessToken(tokenId string) (*Result, *AppError) {
+	m := make(map[string]string)
+	m["token_id"] = tokenId
+
+	if r, err := c.DoApiPost("/users/tokens/revoke", MapToJson(m)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) GetBotRoute(botUserId string) string {
+	return fmt.Sprintf("/bots/%v", botUserId)
+}
+
+// CreateBot creates a bot that's owned by the current user.
+func (c *Client) CreateBot(username string, displayName string, description string) (*Result, *AppError) {
+	m := make(map[string]string)
+	m["username"] = username
+	m["display_name"] = displayName
+	m["description"] = description
+
+	if r, err := c.DoApiPost("/bots/create", MapToJson(m)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), BotFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) GetBots() (*Result, *AppError) {
+	if r, err := c.DoApiGet("/bots/list", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), BotsFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) GetBot(botUserId string) (*Result, *AppError) {
+	if r, err := c.DoApiGet(c.GetBotRoute(botUserId)+"/get", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), BotFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) UpdateBot(botUserId string, displayName string, description string) (*Result, *AppError) {
+	m := make(map[string]string)
+	m["display_name"] = displayName
+	m["description"] = description
+
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+"/update", MapToJson(m)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), BotFromJson(r.Body)}, nil
+	}
+}
+
+// SetBotActive disables or re-enables a bot. A disabled bot's tokens stop working until it's enabled again.
+func (c *Client) SetBotActive(botUserId string, active bool) (*Result, *AppError) {
+	action := "/disable"
+	if active {
+		action = "/enable"
+	}
+
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+action, ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), BotFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) GetBotTokens(botUserId string) (*Result, *AppError) {
+	if r, err := c.DoApiGet(c.GetBotRoute(botUserId)+"/tokens", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PersonalAccessTokensFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) CreateBotToken(botUserId string, description string) (*Result, *AppError) {
+	m := make(map[string]string)
+	m["description"] = description
+
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+"/tokens/create", MapToJson(m)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PersonalAccessTokenFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) RevokeBotToken(botUserId string, tokenId string) (*Result, *AppError) {
+	m := make(map[string]string)
+	m["token_id"] = tokenId
+
+	if r, err := c.DoApiPost(c.GetBotRoute(botUserId)+"/tokens/revoke", MapToJson(m)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
 func (c *Client) EmailToOAuth(m map[string]string) (*Result, *AppError) {
 	if r, err := c.DoApiPost("/users/claim/email_to_sso", MapToJson(m)); err != nil {
 		return nil, err
@@ -842,6 +999,18 @@ func (c *Client) ListCommands() (*Result, *AppError) {
 	}
 }
 
+// GetCommandSuggestions returns suggestions for completing a slash command that's being typed in a channel.
+func (c *Client) GetCommandSuggestions(channelId string, userInput string) (*Result, *AppError) {
+	query := fmt.Sprintf("?channel_id=%v&user_input=%v", url.QueryEscape(channelId), url.QueryEscape(userInput))
+	if r, err := c.DoApiGet(c.GetTeamRoute()+"/commands/suggestions"+query, "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), SuggestCommandListFromJson(r.Body)}, nil
+	}
+}
+
 func (c *Client) ListTeamCommands() (*Result, *AppError) {
 	if r, err := c.DoApiGet(c.GetTeamRoute()+"/commands/list_team_commands", "", ""); err != nil {
 		return nil, err
@@ -1509,6 +1678,21 @@ func (c *Client) SearchPosts(terms string, isOrSearch bool) (*Result, *AppError)
 	}
 }
 
+// SearchFiles returns the files attached to posts that match the given terms. The terms support the same flags as
+// post searches along with "ext:" to only return files with a given extension.
+func (c *Client) SearchFiles(terms string, isOrSearch bool) (*Result, *AppError) {
+	data := map[string]interface{}{}
+	data["terms"] = terms
+	data["is_or_search"] = isOrSearch
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/files/search", StringInterfaceToJson(data)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), FileInfosFromJson(r.Body)}, nil
+	}
+}
+
 // GetFlaggedPosts will return a post list of posts that have been flagged by the user.
 // The page is set by the integer parameters offset and limit.
 func (c *Client) GetFlaggedPosts(offset int, limit int) (*Result, *AppError) {
@@ -1521,6 +1705,204 @@ func (c *Client) GetFlaggedPosts(offset int, limit int) (*Result, *AppError) {
 	}
 }
 
+// GetThreads returns a page of the threads on the current team that the user is following, ordered by the time of
+// their most recent reply.
+func (c *Client) GetThreads(offset int, limit int) (*ThreadList, *AppError) {
+	if r, err := c.DoApiGet(c.GetTeamRoute()+fmt.Sprintf("/threads/%v/%v", offset, limit), "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ThreadListFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) GetThread(threadId string) (*ThreadResponse, *AppError) {
+	if r, err := c.DoApiGet(c.GetTeamRoute()+fmt.Sprintf("/threads/%v/get", threadId), "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ThreadResponseFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) FollowThread(threadId string) (*ThreadResponse, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/threads/%v/follow", threadId), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ThreadResponseFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) UnfollowThread(threadId string) (*ThreadResponse, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/threads/%v/unfollow", threadId), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ThreadResponseFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) MarkThreadAsRead(threadId string) (*ThreadResponse, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/threads/%v/read", threadId), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ThreadResponseFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) MarkAllThreadsAsRead() (bool, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/threads/read_all", ""); err != nil {
+		return false, err
+	} else {
+		return c.CheckStatusOK(r), nil
+	}
+}
+
+// CreateScheduledPost saves a post on the current team to be published at the time given by its ScheduledAt field.
+func (c *Client) CreateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/scheduled_posts/create", scheduledPost.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) GetScheduledPosts() ([]*ScheduledPost, *AppError) {
+	if r, err := c.DoApiGet(c.GetTeamRoute()+"/scheduled_posts/", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ScheduledPostsFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) UpdateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/scheduled_posts/%v/update", scheduledPost.Id), scheduledPost.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return ScheduledPostFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) DeleteScheduledPost(scheduledPostId string) (bool, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/scheduled_posts/%v/delete", scheduledPostId), ""); err != nil {
+		return false, err
+	} else {
+		return c.CheckStatusOK(r), nil
+	}
+}
+
+// SaveDraft creates or replaces the current user's draft for the channel and thread given by the draft.
+func (c *Client) SaveDraft(draft *Draft) (*Draft, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/drafts/save", draft.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return DraftFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) GetDrafts() ([]*Draft, *AppError) {
+	if r, err := c.DoApiGet(c.GetTeamRoute()+"/drafts/", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return DraftsFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) DeleteDraft(channelId string, rootId string) (bool, *AppError) {
+	data := map[string]string{"channel_id": channelId, "root_id": rootId}
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/drafts/delete", MapToJson(data)); err != nil {
+		return false, err
+	} else {
+		return c.CheckStatusOK(r), nil
+	}
+}
+
+func (c *Client) GetPinnedPosts(channelId string) (*Result, *AppError) {
+	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+"/posts/pinned", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PostListFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) PinPost(channelId string, postId string) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+fmt.Sprintf("/posts/%v/pin", postId), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PostFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) UnpinPost(channelId string, postId string) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+fmt.Sprintf("/posts/%v/unpin", postId), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PostFromJson(r.Body)}, nil
+	}
+}
+
+// DoPostAction uses one of the actions on a post's attachments. The selected option is only used by menus.
+func (c *Client) DoPostAction(channelId string, postId string, actionId string, selectedOption string) (*Result, *AppError) {
+	m := make(map[string]string)
+	m["selected_option"] = selectedOption
+
+	if r, err := c.DoApiPost(c.GetChannelRoute(channelId)+fmt.Sprintf("/posts/%v/actions/%v", postId, actionId), MapToJson(m)); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) GetLegalHolds() ([]*LegalHold, *AppError) {
+	if r, err := c.DoApiGet("/admin/legal_holds", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return LegalHoldsFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) CreateLegalHold(hold *LegalHold) (*LegalHold, *AppError) {
+	if r, err := c.DoApiPost("/admin/legal_holds/create", hold.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return LegalHoldFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) UpdateLegalHold(hold *LegalHold) (*LegalHold, *AppError) {
+	if r, err := c.DoApiPost("/admin/legal_holds/"+hold.Id+"/update", hold.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return LegalHoldFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) DeleteLegalHold(id string) (bool, *AppError) {
+	if r, err := c.DoApiPost("/admin/legal_holds/"+id+"/delete", ""); err != nil {
+		return false, err
+	} else {
+		return c.CheckStatusOK(r), nil
+	}
+}
+
 func (c *Client) UploadProfileFile(data []byte, contentType string) (*Result, *AppError) {
 	return c.uploadFile(c.ApiUrl+"/users/newimage", data, contentType)
 }
@@ -1531,18 +1913,19 @@ func (c *Client) UploadPostAttachment(data []byte, channelId string, filename st
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	if part, err := writer.CreateFormFile("files", filename); err != nil {
-		return nil, NewLocAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error())
-	} else if _, err = io.Copy(part, bytes.NewBuffer(data)); err != nil {
-		return nil, NewLocAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error())
-	}
-
+	// Send the channel first so that the server can stream the file straight to storage
 	if part, err := writer.CreateFormField("channel_id"); err != nil {
 		return nil, NewLocAppError("UploadPostAttachment", "model.client.upload_post_attachment.channel_id.app_error", nil, err.Error())
 	} else if _, err = io.Copy(part, strings.NewReader(channelId)); err != nil {
 		return nil, NewLocAppError("UploadPostAttachment", "model.client.upload_post_attachment.channel_id.app_error", nil, err.Error())
 	}
 
+	if part, err := writer.CreateFormFile("files", filename); err != nil {
+		return nil, NewLocAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error())
+	} else if _, err = io.Copy(part, bytes.NewBuffer(data)); err != nil {
+		return nil, NewLocAppError("UploadPostAttachment", "model.client.upload_post_attachment.file.app_error", nil, err.Error())
+	}
+
 	if err := writer.Close(); err != nil {
 		return nil, NewLocAppError("UploadPostAttachment", "model.client.upload_post_attachment.writer.app_error", nil, err.Error())
 	}
@@ -1574,6 +1957,73 @@ func (c *Client) uploadFile(url string, data []byte, contentType string) (*Resul
 	}
 }
 
+// CreateUploadSession starts an upload that can be sent in several chunks using UploadChunk.
+func (c *Client) CreateUploadSession(channelId string, filename string, fileSize int64) (*UploadSession, *AppError) {
+	session := &UploadSession{ChannelId: channelId, Filename: filename, FileSize: fileSize}
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/files/uploads/create", session.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		c.fillInExtraProperties(r)
+		return UploadSessionFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) GetUploadSession(uploadId string) (*UploadSession, *AppError) {
+	if r, err := c.DoApiGet(c.GetTeamRoute()+"/files/uploads/"+uploadId, "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		c.fillInExtraProperties(r)
+		return UploadSessionFromJson(r.Body), nil
+	}
+}
+
+// UploadChunk sends part of a file starting at the given offset, which must match the number of bytes that the
+// server has already received.
+func (c *Client) UploadChunk(uploadId string, offset int64, data []byte) (*UploadSession, *AppError) {
+	url := c.ApiUrl + c.GetTeamRoute() + "/files/uploads/" + uploadId
+	rq, _ := http.NewRequest("PUT", url, bytes.NewReader(data))
+	rq.Header.Set("Content-Type", "application/octet-stream")
+	rq.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/*", offset, offset+int64(len(data))-1))
+	rq.Close = true
+
+	if len(c.AuthToken) > 0 {
+		rq.Header.Set(HEADER_AUTH, c.AuthType+" "+c.AuthToken)
+	}
+
+	if rp, err := c.HttpClient.Do(rq); err != nil {
+		return nil, NewLocAppError(url, "model.client.connecting.app_error", nil, err.Error())
+	} else if rp.StatusCode >= 300 {
+		defer closeBody(rp)
+		return nil, AppErrorFromJson(rp.Body)
+	} else {
+		defer closeBody(rp)
+		c.fillInExtraProperties(rp)
+		return UploadSessionFromJson(rp.Body), nil
+	}
+}
+
+func (c *Client) FinishUploadSession(uploadId string) (*FileInfo, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/files/uploads/"+uploadId+"/finish", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		c.fillInExtraProperties(r)
+		return FileInfoFromJson(r.Body), nil
+	}
+}
+
+func (c *Client) DeleteUploadSession(uploadId string) (map[string]string, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/files/uploads/"+uploadId+"/delete", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		c.fillInExtraProperties(r)
+		return MapFromJson(r.Body), nil
+	}
+}
+
 func (c *Client) GetFile(fileId string) (io.ReadCloser, *AppError) {
 	if r, err := c.DoApiGet(c.GetFileRoute(fileId)+"/get", "", ""); err != nil {
 		return nil, err
@@ -2119,6 +2569,79 @@ func (c *Client) ListOutgoingWebhooks() (*Result, *AppError) {
 	}
 }
 
+// GetOutgoingWebhookDeliveries returns a page of the requests that have been sent for an outgoing webhook with the
+// newest ones first.
+func (c *Client) GetOutgoingWebhookDeliveries(hookId string, offset int, limit int) (*Result, *AppError) {
+	if r, err := c.DoApiGet(c.GetTeamRoute()+fmt.Sprintf("/hooks/outgoing/%v/deliveries/%v/%v", hookId, offset, limit), "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), OutgoingWebhookDeliveriesFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) CreateEventSubscription(subscription *EventSubscription) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+"/hooks/subscriptions/create", subscription.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), EventSubscriptionFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) GetEventSubscriptions() (*Result, *AppError) {
+	if r, err := c.DoApiGet(c.GetTeamRoute()+"/hooks/subscriptions/list", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), EventSubscriptionListFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) UpdateEventSubscription(subscription *EventSubscription) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/hooks/subscriptions/%v/update", subscription.Id), subscription.ToJson()); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), EventSubscriptionFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) RegenEventSubscriptionSecret(id string) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/hooks/subscriptions/%v/regen_secret", id), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), EventSubscriptionFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) DeleteEventSubscription(id string) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/hooks/subscriptions/%v/delete", id), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
+// RedeliverOutgoingWebhook sends the request of an earlier delivery again and returns the new delivery.
+func (c *Client) RedeliverOutgoingWebhook(hookId string, deliveryId string) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetTeamRoute()+fmt.Sprintf("/hooks/outgoing/%v/deliveries/%v/redeliver", hookId, deliveryId), ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), OutgoingWebhookDeliveryFromJson(r.Body)}, nil
+	}
+}
+
 func (c *Client) RegenOutgoingWebhookToken(id string) (*Result, *AppError) {
 	data := make(map[string]string)
 	data["id"] = id
@@ -2324,6 +2847,81 @@ func (c *Client) DeleteReaction(channelId string, reaction *Reaction) *AppError
 	}
 }
 
+func (c *Client) GetPluginRoute(pluginId string) string {
+	return fmt.Sprintf("/plugins/%v", pluginId)
+}
+
+// UploadPlugin installs a plugin from a gzipped tarball. The plugin is disabled until EnablePlugin is called.
+func (c *Client) UploadPlugin(file io.Reader) (*Result, *AppError) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	if part, err := writer.CreateFormFile("plugin", "plugin.tar.gz"); err != nil {
+		return nil, NewLocAppError("UploadPlugin", "model.client.upload_plugin.file.app_error", nil, err.Error())
+	} else if _, err = io.Copy(part, file); err != nil {
+		return nil, NewLocAppError("UploadPlugin", "model.client.upload_plugin.file.app_error", nil, err.Error())
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, NewLocAppError("UploadPlugin", "model.client.upload_plugin.writer.app_error", nil, err.Error())
+	}
+
+	rq, _ := http.NewRequest("POST", c.ApiUrl+"/plugins/upload", body)
+	rq.Header.Set("Content-Type", writer.FormDataContentType())
+	rq.Close = true
+
+	if len(c.AuthToken) > 0 {
+		rq.Header.Set(HEADER_AUTH, "BEARER "+c.AuthToken)
+	}
+
+	if rp, err := c.HttpClient.Do(rq); err != nil {
+		return nil, NewLocAppError("UploadPlugin", "model.client.connecting.app_error", nil, err.Error())
+	} else if rp.StatusCode >= 300 {
+		defer closeBody(rp)
+		return nil, AppErrorFromJson(rp.Body)
+	} else {
+		defer closeBody(rp)
+		return &Result{rp.Header.Get(HEADER_REQUEST_ID),
+			rp.Header.Get(HEADER_ETAG_SERVER), ManifestFromJson(rp.Body)}, nil
+	}
+}
+
+func (c *Client) GetPlugins() (*Result, *AppError) {
+	if r, err := c.DoApiGet("/plugins/list", "", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), PluginInfoListFromJson(r.Body)}, nil
+	}
+}
+
+func (c *Client) RemovePlugin(pluginId string) (*Result, *AppError) {
+	if r, err := c.DoApiPost(c.GetPluginRoute(pluginId)+"/remove", ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
+// SetPluginEnabled enables or disables a plugin. Enabled plugins are started straight away if plugins are turned on.
+func (c *Client) SetPluginEnabled(pluginId string, enabled bool) (*Result, *AppError) {
+	action := "/disable"
+	if enabled {
+		action = "/enable"
+	}
+
+	if r, err := c.DoApiPost(c.GetPluginRoute(pluginId)+action, ""); err != nil {
+		return nil, err
+	} else {
+		defer closeBody(r)
+		return &Result{r.Header.Get(HEADER_REQUEST_ID),
+			r.Header.Get(HEADER_ETAG_SERVER), MapFromJson(r.Body)}, nil
+	}
+}
+
 // Lists all emoji reactions made for the given post in the given channel. Returns a list of Reactions if successful, otherwise returns an AppError.
 func (c *Client) ListReactions(channelId string, postId string) ([]*Reaction, *AppError) {
 	if r, err := c.DoApiGet(c.GetChannelRoute(channelId)+fmt.Sprintf("/posts/%v/reactions", postId), "", ""); err != nil {
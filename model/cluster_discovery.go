@@ -0,0 +1,83 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ClusterDiscovery is a row in the shared database that a server in a cluster uses to advertise itself to the other
+// servers. Each server updates LastPingAt periodically while it's running so that servers that have stopped without
+// removing themselves can be ignored.
+type ClusterDiscovery struct {
+	Id           string `json:"id"`
+	Hostname     string `json:"hostname"`
+	InterNodeUrl string `json:"internode_url"`
+	Version      string `json:"version"`
+	ConfigHash   string `json:"config_hash"`
+	CreateAt     int64  `json:"create_at"`
+	LastPingAt   int64  `json:"last_ping_at"`
+}
+
+func (o *ClusterDiscovery) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ClusterDiscoveryFromJson(data io.Reader) *ClusterDiscovery {
+	decoder := json.NewDecoder(data)
+	var o ClusterDiscovery
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *ClusterDiscovery) PreSave() {
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+
+	o.LastPingAt = GetMillis()
+}
+
+func (o *ClusterDiscovery) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewLocAppError("ClusterDiscovery.IsValid", "model.cluster_discovery.is_valid.id.app_error", nil, "")
+	}
+
+	if len(o.InterNodeUrl) == 0 || len(o.InterNodeUrl) > 512 {
+		return NewLocAppError("ClusterDiscovery.IsValid", "model.cluster_discovery.is_valid.internode_url.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Hostname) > 512 {
+		return NewLocAppError("ClusterDiscovery.IsValid", "model.cluster_discovery.is_valid.hostname.app_error", nil, "id="+o.Id)
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("ClusterDiscovery.IsValid", "model.cluster_discovery.is_valid.create_at.app_error", nil, "id="+o.Id)
+	}
+
+	return nil
+}
+
+// ToClusterInfo returns the details of the server shown in the System Console.
+func (o *ClusterDiscovery) ToClusterInfo() *ClusterInfo {
+	return &ClusterInfo{
+		Id:                 o.Id,
+		Version:            o.Version,
+		ConfigHash:         o.ConfigHash,
+		InterNodeUrl:       o.InterNodeUrl,
+		Hostname:           o.Hostname,
+		LastSuccessfulPing: o.LastPingAt,
+		IsAlive:            true,
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClusterDiscoveryJson(t *testing.T) {
+	o := ClusterDiscovery{Id: NewId(), Hostname: "host", InterNodeUrl: "http://localhost:8075"}
+	json := o.ToJson()
+	ro := ClusterDiscoveryFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.InterNodeUrl != ro.InterNodeUrl {
+		t.Fatal("Ids do not match")
+	}
+}
+
+func TestClusterDiscoveryIsValid(t *testing.T) {
+	o := ClusterDiscovery{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.InterNodeUrl = "http://localhost:8075"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if o.LastPingAt == 0 {
+		t.Fatal("should've set the last ping time")
+	}
+
+	if info := o.ToClusterInfo(); info.Id != o.Id || info.LastSuccessfulPing != o.LastPingAt || !info.IsAlive {
+		t.Fatal("returned the wrong cluster info")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	CLUSTER_EVENT_PUBLISH                            = "publish"
+	CLUSTER_EVENT_UPDATE_STATUS                      = "update_status"
+	CLUSTER_EVENT_INVALIDATE_ALL_CACHES              = "invalidate_all_caches"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER          = "invalidate_cache_for_user"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL       = "invalidate_cache_for_channel"
+	CLUSTER_EVENT_INVALIDATE_CACHE_FOR_CHANNEL_POSTS = "invalidate_cache_for_channel_posts"
+	CLUSTER_EVENT_CLEAR_SESSION_CACHE_FOR_USER       = "clear_session_cache_for_user"
+	CLUSTER_EVENT_CONFIG_CHANGED                     = "config_changed"
+	CLUSTER_EVENT_GET_CLUSTER_STATS                  = "get_cluster_stats"
+	CLUSTER_EVENT_GET_LOGS                           = "get_logs"
+	CLUSTER_EVENT_INDEX_POST                         = "index_post"
+	CLUSTER_EVENT_DELETE_POST_FROM_INDEX             = "delete_post_from_index"
+	CLUSTER_EVENT_INDEX_CHANNEL                      = "index_channel"
+	CLUSTER_EVENT_DELETE_CHANNEL_FROM_INDEX          = "delete_channel_from_index"
+	CLUSTER_EVENT_INDEX_USER                         = "index_user"
+	CLUSTER_EVENT_DELETE_USER_FROM_INDEX             = "delete_user_from_index"
+	CLUSTER_EVENT_PLUGIN_STATE_CHANGED               = "plugin_state_changed"
+)
+
+// ClusterMessage is sent from one server in a cluster to the others. Data holds the body of the message, usually
+// as JSON, and its format depends on the event.
+type ClusterMessage struct {
+	Event string            `json:"event"`
+	Data  string            `json:"data"`
+	Props map[string]string `json:"props,omitempty"`
+}
+
+func (o *ClusterMessage) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ClusterMessageFromJson(data io.Reader) *ClusterMessage {
+	decoder := json.NewDecoder(data)
+	var o ClusterMessage
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClusterMessageJson(t *testing.T) {
+	o := ClusterMessage{Event: CLUSTER_EVENT_INVALIDATE_CACHE_FOR_USER, Data: NewId(), Props: map[string]string{"key": "value"}}
+	json := o.ToJson()
+	ro := ClusterMessageFromJson(strings.NewReader(json))
+
+	if o.Event != ro.Event || o.Data != ro.Data || ro.Props["key"] != "value" {
+		t.Fatal("messages do not match")
+	}
+}
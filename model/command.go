@@ -34,6 +34,11 @@ type Command struct {
 	DisplayName      string `json:"display_name"`
 	Description      string `json:"description"`
 	URL              string `json:"url"`
+
+	// AutocompleteData describes the command's subcommands and arguments, and AutoCompleteURL is queried for
+	// suggestions for its dynamic arguments.
+	AutocompleteData *AutocompleteData `json:"autocomplete_data,omitempty"`
+	AutoCompleteURL  string            `json:"auto_complete_url"`
 }
 
 func (o *Command) ToJson() string {
@@ -126,6 +131,16 @@ func (o *Command) IsValid() *AppError {
 		return NewLocAppError("Command.IsValid", "model.command.is_valid.description.app_error", nil, "")
 	}
 
+	if len(o.AutoCompleteURL) > 1024 || (len(o.AutoCompleteURL) > 0 && !IsValidHttpUrl(o.AutoCompleteURL)) {
+		return NewLocAppError("Command.IsValid", "model.command.is_valid.auto_complete_url.app_error", nil, "")
+	}
+
+	if o.AutocompleteData != nil {
+		if err := o.AutocompleteData.IsValid(len(o.AutoCompleteURL) > 0); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -151,6 +166,7 @@ func (o *Command) Sanitize() {
 	o.CreatorId = ""
 	o.Method = ""
 	o.URL = ""
+	o.AutoCompleteURL = ""
 	o.Username = ""
 	o.IconURL = ""
 }
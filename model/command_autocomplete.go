@@ -0,0 +1,186 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+const (
+	AUTOCOMPLETE_ARG_TYPE_TEXT        = "text"
+	AUTOCOMPLETE_ARG_TYPE_USER        = "user"
+	AUTOCOMPLETE_ARG_TYPE_CHANNEL     = "channel"
+	AUTOCOMPLETE_ARG_TYPE_STATIC_LIST = "static_list"
+	AUTOCOMPLETE_ARG_TYPE_DYNAMIC     = "dynamic"
+
+	AUTOCOMPLETE_DATA_MAX_SIZE = 16000
+	AUTOCOMPLETE_MAX_DEPTH     = 8
+)
+
+// AutocompleteData describes the subcommands and arguments of a slash command, or of one of its subcommands, so that
+// they can be suggested while a user is typing the command.
+type AutocompleteData struct {
+	Trigger     string                  `json:"trigger"`
+	HelpText    string                  `json:"help_text"`
+	Hint        string                  `json:"hint"`
+	Subcommands []*AutocompleteData     `json:"subcommands,omitempty"`
+	Arguments   []*AutocompleteArgument `json:"arguments,omitempty"`
+}
+
+// AutocompleteArgument is a value that's typed after a command. Users, channels and static lists are suggested by the
+// server, and dynamic arguments are suggested by querying the command's autocomplete URL.
+type AutocompleteArgument struct {
+	Name     string                  `json:"name"`
+	HelpText string                  `json:"help_text"`
+	Type     string                  `json:"type"`
+	Options  []*AutocompleteListItem `json:"options,omitempty"`
+}
+
+type AutocompleteListItem struct {
+	Item     string `json:"item"`
+	Hint     string `json:"hint"`
+	HelpText string `json:"help_text"`
+}
+
+// AutocompleteRequest is sent to a command's autocomplete URL to get suggestions for a dynamic argument.
+type AutocompleteRequest struct {
+	Token     string `json:"token"`
+	TeamId    string `json:"team_id"`
+	ChannelId string `json:"channel_id"`
+	UserId    string `json:"user_id"`
+	Command   string `json:"command"`
+	UserInput string `json:"user_input"`
+	Argument  string `json:"argument"`
+	Term      string `json:"term"`
+}
+
+func (o *AutocompleteData) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AutocompleteDataFromJson(data io.Reader) *AutocompleteData {
+	decoder := json.NewDecoder(data)
+	var o AutocompleteData
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *AutocompleteRequest) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AutocompleteRequestFromJson(data io.Reader) *AutocompleteRequest {
+	decoder := json.NewDecoder(data)
+	var o AutocompleteRequest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func AutocompleteListItemsToJson(items []*AutocompleteListItem) string {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func AutocompleteListItemsFromJson(data io.Reader) []*AutocompleteListItem {
+	decoder := json.NewDecoder(data)
+	var o []*AutocompleteListItem
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+// IsValid checks the autocomplete data of a command. Dynamic arguments are only allowed if the command has somewhere
+// to get their suggestions from.
+func (o *AutocompleteData) IsValid(hasURL bool) *AppError {
+	if len(o.ToJson()) > AUTOCOMPLETE_DATA_MAX_SIZE {
+		return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.size.app_error", nil, "")
+	}
+
+	return o.isValid(hasURL, 0)
+}
+
+func (o *AutocompleteData) isValid(hasURL bool, depth int) *AppError {
+	if depth > AUTOCOMPLETE_MAX_DEPTH {
+		return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.depth.app_error", nil, "")
+	}
+
+	// Only subcommands are typed by name
+	if depth > 0 && (len(o.Trigger) == 0 || strings.Contains(o.Trigger, " ")) {
+		return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.trigger.app_error", nil, "trigger="+o.Trigger)
+	}
+
+	if len(o.Subcommands) > 0 && len(o.Arguments) > 0 {
+		return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.subcommands_and_arguments.app_error", nil, "trigger="+o.Trigger)
+	}
+
+	for _, subcommand := range o.Subcommands {
+		if subcommand == nil {
+			return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.trigger.app_error", nil, "")
+		}
+
+		if err := subcommand.isValid(hasURL, depth+1); err != nil {
+			return err
+		}
+	}
+
+	for _, argument := range o.Arguments {
+		if argument == nil {
+			return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.argument_type.app_error", nil, "")
+		}
+
+		switch argument.Type {
+		case AUTOCOMPLETE_ARG_TYPE_TEXT, AUTOCOMPLETE_ARG_TYPE_USER, AUTOCOMPLETE_ARG_TYPE_CHANNEL:
+		case AUTOCOMPLETE_ARG_TYPE_STATIC_LIST:
+			if len(argument.Options) == 0 {
+				return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.options.app_error", nil, "name="+argument.Name)
+			}
+		case AUTOCOMPLETE_ARG_TYPE_DYNAMIC:
+			if !hasURL {
+				return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.dynamic_url.app_error", nil, "name="+argument.Name)
+			}
+		default:
+			return NewLocAppError("AutocompleteData.IsValid", "model.autocomplete_data.is_valid.argument_type.app_error", nil, "name="+argument.Name)
+		}
+	}
+
+	return nil
+}
+
+// GetSubcommand returns the subcommand with the given trigger or nil if there isn't one.
+func (o *AutocompleteData) GetSubcommand(trigger string) *AutocompleteData {
+	for _, subcommand := range o.Subcommands {
+		if strings.ToLower(subcommand.Trigger) == strings.ToLower(trigger) {
+			return subcommand
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAutocompleteDataJson(t *testing.T) {
+	o := AutocompleteData{
+		Subcommands: []*AutocompleteData{
+			{Trigger: "create", Arguments: []*AutocompleteArgument{{Name: "project", Type: AUTOCOMPLETE_ARG_TYPE_DYNAMIC}}},
+		},
+	}
+	ro := AutocompleteDataFromJson(strings.NewReader(o.ToJson()))
+
+	if len(ro.Subcommands) != 1 || ro.Subcommands[0].Trigger != "create" || ro.Subcommands[0].Arguments[0].Name != "project" {
+		t.Fatal("autocomplete data doesn't match")
+	}
+
+	items := []*AutocompleteListItem{{Item: "PROJ", HelpText: "Project"}}
+	if ritems := AutocompleteListItemsFromJson(strings.NewReader(AutocompleteListItemsToJson(items))); len(ritems) != 1 || ritems[0].Item != "PROJ" {
+		t.Fatal("list items don't match")
+	}
+}
+
+func TestAutocompleteDataIsValid(t *testing.T) {
+	o := &AutocompleteData{
+		Subcommands: []*AutocompleteData{
+			{
+				Trigger: "create",
+				Arguments: []*AutocompleteArgument{
+					{Name: "user", Type: AUTOCOMPLETE_ARG_TYPE_USER},
+					{Name: "priority", Type: AUTOCOMPLETE_ARG_TYPE_STATIC_LIST, Options: []*AutocompleteListItem{{Item: "high"}}},
+					{Name: "summary", Type: AUTOCOMPLETE_ARG_TYPE_TEXT},
+				},
+			},
+		},
+	}
+
+	if err := o.IsValid(false); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Subcommands[0].Trigger = "two words"
+	if err := o.IsValid(false); err == nil {
+		t.Fatal("subcommand triggers can't contain spaces")
+	}
+
+	o.Subcommands[0].Trigger = ""
+	if err := o.IsValid(false); err == nil {
+		t.Fatal("subcommands need a trigger")
+	}
+
+	o.Subcommands[0].Trigger = "create"
+	o.Subcommands[0].Arguments[1].Options = nil
+	if err := o.IsValid(false); err == nil {
+		t.Fatal("static lists need options")
+	}
+
+	o.Subcommands[0].Arguments[1].Options = []*AutocompleteListItem{{Item: "high"}}
+	o.Subcommands[0].Arguments[0].Type = "junk"
+	if err := o.IsValid(false); err == nil {
+		t.Fatal("argument types should be checked")
+	}
+
+	o.Subcommands[0].Arguments[0].Type = AUTOCOMPLETE_ARG_TYPE_DYNAMIC
+	if err := o.IsValid(false); err == nil {
+		t.Fatal("dynamic arguments need an autocomplete url")
+	}
+
+	if err := o.IsValid(true); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Arguments = []*AutocompleteArgument{{Name: "text", Type: AUTOCOMPLETE_ARG_TYPE_TEXT}}
+	if err := o.IsValid(true); err == nil {
+		t.Fatal("can't have both subcommands and arguments")
+	}
+
+	o = &AutocompleteData{}
+	data := o
+	for i := 0; i <= AUTOCOMPLETE_MAX_DEPTH; i++ {
+		data.Subcommands = []*AutocompleteData{{Trigger: "sub"}}
+		data = data.Subcommands[0]
+	}
+
+	if err := o.IsValid(false); err == nil {
+		t.Fatal("subcommands shouldn't be nested too deeply")
+	}
+}
+
+func TestAutocompleteDataGetSubcommand(t *testing.T) {
+	o := &AutocompleteData{Subcommands: []*AutocompleteData{{Trigger: "create"}, {Trigger: "list"}}}
+
+	if subcommand := o.GetSubcommand("LIST"); subcommand == nil || subcommand.Trigger != "list" {
+		t.Fatal("should've found the subcommand")
+	}
+
+	if subcommand := o.GetSubcommand("junk"); subcommand != nil {
+		t.Fatal("shouldn't have found a subcommand")
+	}
+}
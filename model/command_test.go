@@ -161,6 +161,22 @@ func TestCommandIsValid(t *testing.T) {
 	if err := o.IsValid(); err != nil {
 		t.Fatal(err)
 	}
+
+	o.AutoCompleteURL = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.AutoCompleteURL = ""
+	o.AutocompleteData = &AutocompleteData{Arguments: []*AutocompleteArgument{{Name: "project", Type: AUTOCOMPLETE_ARG_TYPE_DYNAMIC}}}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without an autocomplete url")
+	}
+
+	o.AutoCompleteURL = "http://nowhere.com/autocomplete"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 func TestCommandPreSave(t *testing.T) {
@@ -14,10 +14,13 @@ type CompliancePost struct {
 	TeamDisplayName string
 
 	// From Channel
+	ChannelId          string
 	ChannelName        string
 	ChannelDisplayName string
+	ChannelType        string
 
 	// From User
+	UserId       string
 	UserUsername string
 	UserEmail    string
 	UserNickname string
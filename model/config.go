@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/url"
+	"time"
 )
 
 const (
@@ -27,6 +28,9 @@ const (
 	SERVICE_GITLAB    = "gitlab"
 	SERVICE_GOOGLE    = "google"
 	SERVICE_OFFICE365 = "office365"
+	SERVICE_OPENID    = "openid"
+
+	OPENID_SETTINGS_DEFAULT_SCOPE = "openid profile email"
 
 	WEBSERVER_MODE_REGULAR  = "regular"
 	WEBSERVER_MODE_GZIP     = "gzip"
@@ -53,51 +57,71 @@ const (
 	EMAIL_BATCHING_INTERVAL    = 30
 
 	SITENAME_MAX_LENGTH = 30
+
+	COMPLIANCE_EXPORT_FORMAT_CSV         = "csv"
+	COMPLIANCE_EXPORT_FORMAT_ACTIANCE    = "actiance"
+	COMPLIANCE_EXPORT_FORMAT_GLOBALRELAY = "globalrelay"
+
+	DATA_RETENTION_SETTINGS_DEFAULT_RETENTION_DAYS          = 365
+	DATA_RETENTION_SETTINGS_DEFAULT_DELETION_JOB_START_TIME = "02:00"
+
+	PLUGIN_SETTINGS_DEFAULT_DIRECTORY = "./plugins"
 )
 
 type ServiceSettings struct {
-	SiteURL                           *string
-	ListenAddress                     string
-	ConnectionSecurity                *string
-	TLSCertFile                       *string
-	TLSKeyFile                        *string
-	UseLetsEncrypt                    *bool
-	LetsEncryptCertificateCacheFile   *string
-	Forward80To443                    *bool
-	ReadTimeout                       *int
-	WriteTimeout                      *int
-	MaximumLoginAttempts              int
-	SegmentDeveloperKey               string
-	GoogleDeveloperKey                string
-	EnableOAuthServiceProvider        bool
-	EnableIncomingWebhooks            bool
-	EnableOutgoingWebhooks            bool
-	EnableCommands                    *bool
-	EnableOnlyAdminIntegrations       *bool
-	EnablePostUsernameOverride        bool
-	EnablePostIconOverride            bool
-	EnableTesting                     bool
-	EnableDeveloper                   *bool
-	EnableSecurityFixAlert            *bool
-	EnableInsecureOutgoingConnections *bool
-	EnableMultifactorAuthentication   *bool
-	EnforceMultifactorAuthentication  *bool
-	AllowCorsFrom                     *string
-	SessionLengthWebInDays            *int
-	SessionLengthMobileInDays         *int
-	SessionLengthSSOInDays            *int
-	SessionCacheInMinutes             *int
-	WebsocketSecurePort               *int
-	WebsocketPort                     *int
-	WebserverMode                     *string
-	EnableCustomEmoji                 *bool
-	RestrictCustomEmojiCreation       *string
+	SiteURL                                   *string
+	ListenAddress                             string
+	ConnectionSecurity                        *string
+	TLSCertFile                               *string
+	TLSKeyFile                                *string
+	UseLetsEncrypt                            *bool
+	LetsEncryptCertificateCacheFile           *string
+	Forward80To443                            *bool
+	ReadTimeout                               *int
+	WriteTimeout                              *int
+	MaximumLoginAttempts                      int
+	SegmentDeveloperKey                       string
+	GoogleDeveloperKey                        string
+	EnableOAuthServiceProvider                bool
+	EnableIncomingWebhooks                    bool
+	EnableOutgoingWebhooks                    bool
+	EnableCommands                            *bool
+	EnableOnlyAdminIntegrations               *bool
+	EnablePostUsernameOverride                bool
+	EnablePostIconOverride                    bool
+	EnableTesting                             bool
+	EnableDeveloper                           *bool
+	EnableSecurityFixAlert                    *bool
+	EnableInsecureOutgoingConnections         *bool
+	AllowedUntrustedInternalConnections       *string
+	EnableMultifactorAuthentication           *bool
+	EnforceMultifactorAuthentication          *bool
+	EnforceMultifactorAuthenticationForAdmins *bool
+	EnablePersonalAccessTokens                *bool
+	EnableBotAccounts                         *bool
+	EnableEventSubscriptions                  *bool
+	PostActionSigningSecret                   *string
+	AllowCorsFrom                             *string
+	SessionLengthWebInDays                    *int
+	SessionLengthMobileInDays                 *int
+	SessionLengthSSOInDays                    *int
+	SessionCacheInMinutes                     *int
+	WebsocketSecurePort                       *int
+	WebsocketPort                             *int
+	WebserverMode                             *string
+	EnableCustomEmoji                         *bool
+	RestrictCustomEmojiCreation               *string
 }
 
 type ClusterSettings struct {
 	Enable                 *bool
 	InterNodeListenAddress *string
+	AdvertiseAddress       *string
 	InterNodeUrls          []string
+	UseTLS                 *bool
+	TLSCertFile            *string
+	TLSKeyFile             *string
+	TLSCAFile              *string
 }
 
 type MetricsSettings struct {
@@ -118,6 +142,7 @@ type SSOSettings struct {
 	AuthEndpoint    string
 	TokenEndpoint   string
 	UserApiEndpoint string
+	Issuer          string
 }
 
 type SqlSettings struct {
@@ -275,9 +300,10 @@ type LdapSettings struct {
 }
 
 type ComplianceSettings struct {
-	Enable      *bool
-	Directory   *string
-	EnableDaily *bool
+	Enable       *bool
+	Directory    *string
+	EnableDaily  *bool
+	ExportFormat *string
 }
 
 type LocalizationSettings struct {
@@ -329,29 +355,69 @@ type WebrtcSettings struct {
 	TurnSharedKey       *string
 }
 
+type SearchEngineSettings struct {
+	EnableIndexing  *bool
+	EnableSearching *bool
+	IndexDir        *string
+}
+
+// DataRetentionTeamPolicy overrides the global retention periods for a single team. A period of 0 days means that the
+// team uses the global period for its messages or files.
+type DataRetentionTeamPolicy struct {
+	TeamId               string
+	MessageRetentionDays int
+	FileRetentionDays    int
+}
+
+type DataRetentionSettings struct {
+	EnableMessageDeletion *bool
+	EnableFileDeletion    *bool
+	MessageRetentionDays  *int
+	FileRetentionDays     *int
+	DeletionJobStartTime  *string
+	TeamPolicies          []DataRetentionTeamPolicy
+}
+
+// PluginState is whether an installed plugin should be running.
+type PluginState struct {
+	Enable bool
+}
+
+type PluginSettings struct {
+	Enable        *bool
+	EnableUploads *bool
+	Directory     *string
+	Plugins       map[string]map[string]interface{}
+	PluginStates  map[string]*PluginState
+}
+
 type Config struct {
-	ServiceSettings      ServiceSettings
-	TeamSettings         TeamSettings
-	SqlSettings          SqlSettings
-	LogSettings          LogSettings
-	PasswordSettings     PasswordSettings
-	FileSettings         FileSettings
-	EmailSettings        EmailSettings
-	RateLimitSettings    RateLimitSettings
-	PrivacySettings      PrivacySettings
-	SupportSettings      SupportSettings
-	GitLabSettings       SSOSettings
-	GoogleSettings       SSOSettings
-	Office365Settings    SSOSettings
-	LdapSettings         LdapSettings
-	ComplianceSettings   ComplianceSettings
-	LocalizationSettings LocalizationSettings
-	SamlSettings         SamlSettings
-	NativeAppSettings    NativeAppSettings
-	ClusterSettings      ClusterSettings
-	MetricsSettings      MetricsSettings
-	AnalyticsSettings    AnalyticsSettings
-	WebrtcSettings       WebrtcSettings
+	ServiceSettings       ServiceSettings
+	TeamSettings          TeamSettings
+	SqlSettings           SqlSettings
+	LogSettings           LogSettings
+	PasswordSettings      PasswordSettings
+	FileSettings          FileSettings
+	EmailSettings         EmailSettings
+	RateLimitSettings     RateLimitSettings
+	PrivacySettings       PrivacySettings
+	SupportSettings       SupportSettings
+	GitLabSettings        SSOSettings
+	GoogleSettings        SSOSettings
+	Office365Settings     SSOSettings
+	OpenIdSettings        SSOSettings
+	LdapSettings          LdapSettings
+	ComplianceSettings    ComplianceSettings
+	LocalizationSettings  LocalizationSettings
+	SamlSettings          SamlSettings
+	NativeAppSettings     NativeAppSettings
+	ClusterSettings       ClusterSettings
+	MetricsSettings       MetricsSettings
+	AnalyticsSettings     AnalyticsSettings
+	WebrtcSettings        WebrtcSettings
+	SearchEngineSettings  SearchEngineSettings
+	DataRetentionSettings DataRetentionSettings
+	PluginSettings        PluginSettings
 }
 
 func (o *Config) ToJson() string {
@@ -371,6 +437,8 @@ func (o *Config) GetSSOService(service string) *SSOSettings {
 		return &o.GoogleSettings
 	case SERVICE_OFFICE365:
 		return &o.Office365Settings
+	case SERVICE_OPENID:
+		return &o.OpenIdSettings
 	}
 
 	return nil
@@ -451,6 +519,10 @@ func (o *Config) SetDefaults() {
 		*o.ServiceSettings.EnableInsecureOutgoingConnections = false
 	}
 
+	if o.ServiceSettings.AllowedUntrustedInternalConnections == nil {
+		o.ServiceSettings.AllowedUntrustedInternalConnections = new(string)
+	}
+
 	if o.ServiceSettings.EnableMultifactorAuthentication == nil {
 		o.ServiceSettings.EnableMultifactorAuthentication = new(bool)
 		*o.ServiceSettings.EnableMultifactorAuthentication = false
@@ -461,6 +533,31 @@ func (o *Config) SetDefaults() {
 		*o.ServiceSettings.EnforceMultifactorAuthentication = false
 	}
 
+	if o.ServiceSettings.EnforceMultifactorAuthenticationForAdmins == nil {
+		o.ServiceSettings.EnforceMultifactorAuthenticationForAdmins = new(bool)
+		*o.ServiceSettings.EnforceMultifactorAuthenticationForAdmins = false
+	}
+
+	if o.ServiceSettings.EnablePersonalAccessTokens == nil {
+		o.ServiceSettings.EnablePersonalAccessTokens = new(bool)
+		*o.ServiceSettings.EnablePersonalAccessTokens = false
+	}
+
+	if o.ServiceSettings.EnableBotAccounts == nil {
+		o.ServiceSettings.EnableBotAccounts = new(bool)
+		*o.ServiceSettings.EnableBotAccounts = false
+	}
+
+	if o.ServiceSettings.EnableEventSubscriptions == nil {
+		o.ServiceSettings.EnableEventSubscriptions = new(bool)
+		*o.ServiceSettings.EnableEventSubscriptions = false
+	}
+
+	if o.ServiceSettings.PostActionSigningSecret == nil || len(*o.ServiceSettings.PostActionSigningSecret) == 0 {
+		o.ServiceSettings.PostActionSigningSecret = new(string)
+		*o.ServiceSettings.PostActionSigningSecret = NewRandomString(32)
+	}
+
 	if o.PasswordSettings.MinimumLength == nil {
 		o.PasswordSettings.MinimumLength = new(int)
 		*o.PasswordSettings.MinimumLength = PASSWORD_MINIMUM_LENGTH
@@ -832,6 +929,11 @@ func (o *Config) SetDefaults() {
 		*o.ClusterSettings.InterNodeListenAddress = ":8075"
 	}
 
+	if o.ClusterSettings.AdvertiseAddress == nil {
+		o.ClusterSettings.AdvertiseAddress = new(string)
+		*o.ClusterSettings.AdvertiseAddress = ""
+	}
+
 	if o.ClusterSettings.Enable == nil {
 		o.ClusterSettings.Enable = new(bool)
 		*o.ClusterSettings.Enable = false
@@ -841,6 +943,26 @@ func (o *Config) SetDefaults() {
 		o.ClusterSettings.InterNodeUrls = []string{}
 	}
 
+	if o.ClusterSettings.UseTLS == nil {
+		o.ClusterSettings.UseTLS = new(bool)
+		*o.ClusterSettings.UseTLS = false
+	}
+
+	if o.ClusterSettings.TLSCertFile == nil {
+		o.ClusterSettings.TLSCertFile = new(string)
+		*o.ClusterSettings.TLSCertFile = ""
+	}
+
+	if o.ClusterSettings.TLSKeyFile == nil {
+		o.ClusterSettings.TLSKeyFile = new(string)
+		*o.ClusterSettings.TLSKeyFile = ""
+	}
+
+	if o.ClusterSettings.TLSCAFile == nil {
+		o.ClusterSettings.TLSCAFile = new(string)
+		*o.ClusterSettings.TLSCAFile = ""
+	}
+
 	if o.MetricsSettings.ListenAddress == nil {
 		o.MetricsSettings.ListenAddress = new(string)
 		*o.MetricsSettings.ListenAddress = ":8067"
@@ -871,6 +993,11 @@ func (o *Config) SetDefaults() {
 		*o.ComplianceSettings.EnableDaily = false
 	}
 
+	if o.ComplianceSettings.ExportFormat == nil {
+		o.ComplianceSettings.ExportFormat = new(string)
+		*o.ComplianceSettings.ExportFormat = COMPLIANCE_EXPORT_FORMAT_CSV
+	}
+
 	if o.LocalizationSettings.DefaultServerLocale == nil {
 		o.LocalizationSettings.DefaultServerLocale = new(string)
 		*o.LocalizationSettings.DefaultServerLocale = DEFAULT_LOCALE
@@ -1047,6 +1174,13 @@ func (o *Config) SetDefaults() {
 	}
 
 	o.defaultWebrtcSettings()
+	o.defaultSearchEngineSettings()
+	o.defaultDataRetentionSettings()
+	o.defaultPluginSettings()
+
+	if len(o.OpenIdSettings.Scope) == 0 {
+		o.OpenIdSettings.Scope = OPENID_SETTINGS_DEFAULT_SCOPE
+	}
 }
 
 func (o *Config) IsValid() *AppError {
@@ -1069,6 +1203,10 @@ func (o *Config) IsValid() *AppError {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.cluster_email_batching.app_error", nil, "")
 	}
 
+	if *o.ClusterSettings.UseTLS && (len(*o.ClusterSettings.TLSCertFile) == 0 || len(*o.ClusterSettings.TLSKeyFile) == 0) {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.cluster_tls.app_error", nil, "")
+	}
+
 	if len(*o.ServiceSettings.SiteURL) == 0 && *o.EmailSettings.EnableEmailBatching {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.site_url_email_batching.app_error", nil, "")
 	}
@@ -1265,6 +1403,28 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.isValidSearchEngineSettings(); err != nil {
+		return err
+	}
+
+	if err := o.isValidComplianceSettings(); err != nil {
+		return err
+	}
+
+	if err := o.isValidDataRetentionSettings(); err != nil {
+		return err
+	}
+
+	if len(*o.PluginSettings.Directory) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.plugin_directory.app_error", nil, "")
+	}
+
+	if o.OpenIdSettings.Enable {
+		if _, err := url.ParseRequestURI(o.OpenIdSettings.Issuer); err != nil {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.openid_issuer.app_error", nil, "")
+		}
+	}
+
 	if !(*o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_NONE || *o.ServiceSettings.ConnectionSecurity == CONN_SECURITY_TLS) {
 		return NewLocAppError("Config.IsValid", "model.config.is_valid.webserver_security.app_error", nil, "")
 	}
@@ -1300,6 +1460,7 @@ func (o *Config) Sanitize() {
 
 	o.EmailSettings.InviteSalt = FAKE_SETTING
 	o.EmailSettings.PasswordResetSalt = FAKE_SETTING
+	*o.ServiceSettings.PostActionSigningSecret = FAKE_SETTING
 	if len(o.EmailSettings.SMTPPassword) > 0 {
 		o.EmailSettings.SMTPPassword = FAKE_SETTING
 	}
@@ -1308,6 +1469,10 @@ func (o *Config) Sanitize() {
 		o.GitLabSettings.Secret = FAKE_SETTING
 	}
 
+	if len(o.OpenIdSettings.Secret) > 0 {
+		o.OpenIdSettings.Secret = FAKE_SETTING
+	}
+
 	o.SqlSettings.DataSource = FAKE_SETTING
 	o.SqlSettings.AtRestEncryptKey = FAKE_SETTING
 
@@ -1383,3 +1548,123 @@ func (o *Config) isValidWebrtcSettings() *AppError {
 
 	return nil
 }
+
+func (o *Config) defaultSearchEngineSettings() {
+	if o.SearchEngineSettings.EnableIndexing == nil {
+		o.SearchEngineSettings.EnableIndexing = new(bool)
+		*o.SearchEngineSettings.EnableIndexing = false
+	}
+
+	if o.SearchEngineSettings.EnableSearching == nil {
+		o.SearchEngineSettings.EnableSearching = new(bool)
+		*o.SearchEngineSettings.EnableSearching = false
+	}
+
+	if o.SearchEngineSettings.IndexDir == nil {
+		o.SearchEngineSettings.IndexDir = new(string)
+		*o.SearchEngineSettings.IndexDir = "./data/searchindex/"
+	}
+}
+
+func (o *Config) isValidSearchEngineSettings() *AppError {
+	if *o.SearchEngineSettings.EnableSearching && !*o.SearchEngineSettings.EnableIndexing {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.search_engine_searching.app_error", nil, "")
+	}
+
+	if *o.SearchEngineSettings.EnableIndexing && len(*o.SearchEngineSettings.IndexDir) == 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.search_engine_index_dir.app_error", nil, "")
+	}
+
+	return nil
+}
+
+func (o *Config) isValidComplianceSettings() *AppError {
+	switch *o.ComplianceSettings.ExportFormat {
+	case COMPLIANCE_EXPORT_FORMAT_CSV, COMPLIANCE_EXPORT_FORMAT_ACTIANCE, COMPLIANCE_EXPORT_FORMAT_GLOBALRELAY:
+		return nil
+	default:
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.compliance_export_format.app_error", nil, "")
+	}
+}
+
+func (o *Config) defaultDataRetentionSettings() {
+	if o.DataRetentionSettings.EnableMessageDeletion == nil {
+		o.DataRetentionSettings.EnableMessageDeletion = new(bool)
+		*o.DataRetentionSettings.EnableMessageDeletion = false
+	}
+
+	if o.DataRetentionSettings.EnableFileDeletion == nil {
+		o.DataRetentionSettings.EnableFileDeletion = new(bool)
+		*o.DataRetentionSettings.EnableFileDeletion = false
+	}
+
+	if o.DataRetentionSettings.MessageRetentionDays == nil {
+		o.DataRetentionSettings.MessageRetentionDays = new(int)
+		*o.DataRetentionSettings.MessageRetentionDays = DATA_RETENTION_SETTINGS_DEFAULT_RETENTION_DAYS
+	}
+
+	if o.DataRetentionSettings.FileRetentionDays == nil {
+		o.DataRetentionSettings.FileRetentionDays = new(int)
+		*o.DataRetentionSettings.FileRetentionDays = DATA_RETENTION_SETTINGS_DEFAULT_RETENTION_DAYS
+	}
+
+	if o.DataRetentionSettings.DeletionJobStartTime == nil {
+		o.DataRetentionSettings.DeletionJobStartTime = new(string)
+		*o.DataRetentionSettings.DeletionJobStartTime = DATA_RETENTION_SETTINGS_DEFAULT_DELETION_JOB_START_TIME
+	}
+
+	if o.DataRetentionSettings.TeamPolicies == nil {
+		o.DataRetentionSettings.TeamPolicies = []DataRetentionTeamPolicy{}
+	}
+}
+
+func (o *Config) isValidDataRetentionSettings() *AppError {
+	if *o.DataRetentionSettings.MessageRetentionDays <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention.message_retention_days_too_low.app_error", nil, "")
+	}
+
+	if *o.DataRetentionSettings.FileRetentionDays <= 0 {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention.file_retention_days_too_low.app_error", nil, "")
+	}
+
+	if _, err := time.Parse("15:04", *o.DataRetentionSettings.DeletionJobStartTime); err != nil {
+		return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention.deletion_job_start_time.app_error", nil, err.Error())
+	}
+
+	for _, policy := range o.DataRetentionSettings.TeamPolicies {
+		if len(policy.TeamId) != 26 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention.team_id.app_error", nil, "team_id="+policy.TeamId)
+		}
+
+		if policy.MessageRetentionDays < 0 || policy.FileRetentionDays < 0 {
+			return NewLocAppError("Config.IsValid", "model.config.is_valid.data_retention.team_retention_days.app_error", nil, "team_id="+policy.TeamId)
+		}
+	}
+
+	return nil
+}
+
+func (o *Config) defaultPluginSettings() {
+	if o.PluginSettings.Enable == nil {
+		o.PluginSettings.Enable = new(bool)
+		*o.PluginSettings.Enable = false
+	}
+
+	if o.PluginSettings.EnableUploads == nil {
+		o.PluginSettings.EnableUploads = new(bool)
+		*o.PluginSettings.EnableUploads = false
+	}
+
+	if o.PluginSettings.Directory == nil {
+		o.PluginSettings.Directory = new(string)
+		*o.PluginSettings.Directory = PLUGIN_SETTINGS_DEFAULT_DIRECTORY
+	}
+
+	if o.PluginSettings.Plugins == nil {
+		o.PluginSettings.Plugins = map[string]map[string]interface{}{}
+	}
+
+	if o.PluginSettings.PluginStates == nil {
+		o.PluginSettings.PluginStates = map[string]*PluginState{}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+// Draft is a message that a user has started writing in a channel or thread but hasn't posted yet. It's saved on the
+// server so that it follows the user between devices.
+type Draft struct {
+	CreateAt  int64           `json:"create_at"`
+	UpdateAt  int64           `json:"update_at"`
+	UserId    string          `json:"user_id"`
+	ChannelId string          `json:"channel_id"`
+	RootId    string          `json:"root_id"`
+	Message   string          `json:"message"`
+	Props     StringInterface `json:"props"`
+	FileIds   StringArray     `json:"file_ids,omitempty"`
+}
+
+func (o *Draft) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DraftFromJson(data io.Reader) *Draft {
+	decoder := json.NewDecoder(data)
+	var o Draft
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func DraftsToJson(drafts []*Draft) string {
+	b, err := json.Marshal(drafts)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func DraftsFromJson(data io.Reader) []*Draft {
+	decoder := json.NewDecoder(data)
+	var o []*Draft
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *Draft) PreSave() {
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+
+	if o.Props == nil {
+		o.Props = make(map[string]interface{})
+	}
+
+	if o.FileIds == nil {
+		o.FileIds = []string{}
+	}
+
+	o.UpdateAt = GetMillis()
+}
+
+func (o *Draft) IsValid() *AppError {
+	if o.CreateAt == 0 {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.create_at.app_error", nil, "channel_id="+o.ChannelId)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.update_at.app_error", nil, "channel_id="+o.ChannelId)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.user_id.app_error", nil, "")
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.channel_id.app_error", nil, "")
+	}
+
+	if !(len(o.RootId) == 26 || len(o.RootId) == 0) {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.root_id.app_error", nil, "channel_id="+o.ChannelId)
+	}
+
+	if utf8.RuneCountInString(o.Message) > POST_MESSAGE_MAX_RUNES {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.msg.app_error", nil, "channel_id="+o.ChannelId)
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.FileIds)) > POST_FILEIDS_MAX_RUNES {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.file_ids.app_error", nil, "channel_id="+o.ChannelId)
+	}
+
+	if utf8.RuneCountInString(StringInterfaceToJson(o.Props)) > POST_PROPS_MAX_RUNES {
+		return NewLocAppError("Draft.IsValid", "model.draft.is_valid.props.app_error", nil, "channel_id="+o.ChannelId)
+	}
+
+	return nil
+}
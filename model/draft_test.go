@@ -0,0 +1,72 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDraftJson(t *testing.T) {
+	o := Draft{UserId: NewId(), ChannelId: NewId(), Message: NewId()}
+	ro := DraftFromJson(strings.NewReader(o.ToJson()))
+
+	if o.UserId != ro.UserId || o.ChannelId != ro.ChannelId || o.Message != ro.Message {
+		t.Fatal("drafts do not match")
+	}
+
+	drafts := DraftsFromJson(strings.NewReader(DraftsToJson([]*Draft{&o})))
+	if len(drafts) != 1 || drafts[0].Message != o.Message {
+		t.Fatal("drafts do not match")
+	}
+}
+
+func TestDraftIsValid(t *testing.T) {
+	o := Draft{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	o.ChannelId = NewId()
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RootId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RootId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Message = strings.Repeat("0", POST_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = ""
+	o.ChannelId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestDraftPreSave(t *testing.T) {
+	o := Draft{CreateAt: 1000}
+	o.PreSave()
+
+	if o.CreateAt != 1000 {
+		t.Fatal("shouldn't have changed the creation time")
+	} else if o.UpdateAt == 0 {
+		t.Fatal("should have set the update time")
+	} else if o.Props == nil || o.FileIds == nil {
+		t.Fatal("should have initialized props and file ids")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	HEADER_EVENT_SUBSCRIPTION_EVENT = "X-Mattermost-Event"
+
+	EVENT_SUBSCRIPTION_SECRET_LENGTH = 32
+)
+
+// EventSubscription sends the events of a team to a URL. Each event is POSTed with the same payload that's sent to
+// WebSocket clients and is signed with the subscription's secret.
+type EventSubscription struct {
+	Id          string      `json:"id"`
+	Secret      string      `json:"secret"`
+	CreateAt    int64       `json:"create_at"`
+	UpdateAt    int64       `json:"update_at"`
+	DeleteAt    int64       `json:"delete_at"`
+	CreatorId   string      `json:"creator_id"`
+	TeamId      string      `json:"team_id"`
+	URL         string      `json:"url"`
+	Events      StringArray `json:"events"`
+	DisplayName string      `json:"display_name"`
+	Description string      `json:"description"`
+}
+
+// GetSubscribableEvents returns the WebSocket events that event subscriptions can be made for.
+func GetSubscribableEvents() []string {
+	return []string{
+		WEBSOCKET_EVENT_CHANNEL_CREATED,
+		WEBSOCKET_EVENT_CHANNEL_DELETED,
+		WEBSOCKET_EVENT_ADDED_TO_TEAM,
+		WEBSOCKET_EVENT_LEAVE_TEAM,
+		WEBSOCKET_EVENT_USER_ADDED,
+		WEBSOCKET_EVENT_USER_REMOVED,
+		WEBSOCKET_EVENT_REACTION_ADDED,
+		WEBSOCKET_EVENT_REACTION_REMOVED,
+		WEBSOCKET_EVENT_POST_EDITED,
+		WEBSOCKET_EVENT_POST_DELETED,
+	}
+}
+
+func IsSubscribableEvent(event string) bool {
+	for _, subscribable := range GetSubscribableEvents() {
+		if event == subscribable {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *EventSubscription) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func EventSubscriptionFromJson(data io.Reader) *EventSubscription {
+	decoder := json.NewDecoder(data)
+	var o EventSubscription
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func EventSubscriptionListToJson(l []*EventSubscription) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func EventSubscriptionListFromJson(data io.Reader) []*EventSubscription {
+	decoder := json.NewDecoder(data)
+	var o []*EventSubscription
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *EventSubscription) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.id.app_error", nil, "")
+	}
+
+	if len(o.Secret) != EVENT_SUBSCRIPTION_SECRET_LENGTH {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.secret.app_error", nil, "id="+o.Id)
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.create_at.app_error", nil, "id="+o.Id)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.update_at.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.CreatorId) != 26 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.user_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.team_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.URL) == 0 || len(o.URL) > 1024 || !IsValidHttpUrl(o.URL) {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.url.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Events) == 0 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.events.app_error", nil, "id="+o.Id)
+	}
+
+	for _, event := range o.Events {
+		if !IsSubscribableEvent(event) {
+			return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.events.app_error", nil, "id="+o.Id+", event="+event)
+		}
+	}
+
+	if len(o.DisplayName) > 64 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.display_name.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Description) > 128 {
+		return NewLocAppError("EventSubscription.IsValid", "model.event_subscription.is_valid.description.app_error", nil, "id="+o.Id)
+	}
+
+	return nil
+}
+
+func (o *EventSubscription) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Secret == "" {
+		o.Secret = NewRandomString(EVENT_SUBSCRIPTION_SECRET_LENGTH)
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *EventSubscription) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+// HasEvent returns true if the subscription is for the given event.
+func (o *EventSubscription) HasEvent(event string) bool {
+	for _, subscribed := range o.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the value of the signature header that's sent with an event. It uses the same scheme as outgoing
+// webhooks with the subscription's secret as the key.
+func (o *EventSubscription) Sign(payload []byte) string {
+	return SignOutgoingWebhookPayload(o.Secret, payload)
+}
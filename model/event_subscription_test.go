@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEventSubscriptionJson(t *testing.T) {
+	o := EventSubscription{Id: NewId(), Events: StringArray{WEBSOCKET_EVENT_POST_EDITED}}
+	ro := EventSubscriptionFromJson(strings.NewReader(o.ToJson()))
+
+	if o.Id != ro.Id || !ro.HasEvent(WEBSOCKET_EVENT_POST_EDITED) {
+		t.Fatal("subscriptions don't match")
+	}
+
+	if l := EventSubscriptionListFromJson(strings.NewReader(EventSubscriptionListToJson([]*EventSubscription{&o}))); len(l) != 1 || l[0].Id != o.Id {
+		t.Fatal("subscription lists don't match")
+	}
+}
+
+func TestEventSubscriptionIsValid(t *testing.T) {
+	o := EventSubscription{
+		CreatorId: NewId(),
+		TeamId:    NewId(),
+		URL:       "http://nowhere.com/events",
+		Events:    StringArray{WEBSOCKET_EVENT_CHANNEL_CREATED, WEBSOCKET_EVENT_REACTION_ADDED},
+	}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid before it's saved")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(o.Secret) != EVENT_SUBSCRIPTION_SECRET_LENGTH {
+		t.Fatal("should've generated a secret")
+	}
+
+	o.URL = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.URL = "http://nowhere.com/events"
+	o.Events = StringArray{}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should need at least one event")
+	}
+
+	o.Events = StringArray{WEBSOCKET_EVENT_TYPING}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("shouldn't allow subscribing to typing events")
+	}
+
+	o.Events = StringArray{WEBSOCKET_EVENT_POST_DELETED}
+	o.DisplayName = strings.Repeat("1", 65)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.DisplayName = ""
+	o.Description = strings.Repeat("1", 129)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestEventSubscriptionSign(t *testing.T) {
+	o := EventSubscription{Secret: NewRandomString(EVENT_SUBSCRIPTION_SECRET_LENGTH)}
+
+	if o.Sign([]byte("payload")) != SignOutgoingWebhookPayload(o.Secret, []byte("payload")) {
+		t.Fatal("should sign with the subscription's secret")
+	}
+}
@@ -14,6 +14,10 @@ import (
 	"strings"
 )
 
+const (
+	FILE_INFO_CONTENT_MAX_RUNES = 16000
+)
+
 type FileInfo struct {
 	Id              string `json:"id"`
 	CreatorId       string `json:"user_id"`
@@ -31,6 +35,8 @@ type FileInfo struct {
 	Width           int    `json:"width,omitempty"`
 	Height          int    `json:"height,omitempty"`
 	HasPreviewImage bool   `json:"has_preview_image,omitempty"`
+	Hash            string `json:"hash,omitempty"`
+	Content         string `json:"-"` // text extracted from the file for searching, not sent back to the client
 }
 
 func (info *FileInfo) ToJson() string {
@@ -116,12 +122,11 @@ func (o *FileInfo) IsImage() bool {
 	return strings.HasPrefix(o.MimeType, "image")
 }
 
-func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
+// NewInfo creates a FileInfo with the fields that can be determined from the file name alone.
+func NewInfo(name string) *FileInfo {
 	info := &FileInfo{
 		Name: name,
-		Size: int64(len(data)),
 	}
-	var err *AppError
 
 	extension := strings.ToLower(filepath.Ext(name))
 	info.MimeType = mime.TypeByExtension(extension)
@@ -133,6 +138,14 @@ func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
 		info.Extension = extension
 	}
 
+	return info
+}
+
+func GetInfoForBytes(name string, data []byte) (*FileInfo, *AppError) {
+	info := NewInfo(name)
+	info.Size = int64(len(data))
+	var err *AppError
+
 	if info.IsImage() {
 		// Only set the width and height if it's actually an image that we can understand
 		if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
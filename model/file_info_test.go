@@ -75,6 +75,28 @@ func TestFileInfoIsImage(t *testing.T) {
 	}
 }
 
+func TestNewInfo(t *testing.T) {
+	if info := NewInfo("file.txt"); info.Name != "file.txt" {
+		t.Fatalf("Got incorrect filename: %v", info.Name)
+	} else if info.Extension != "txt" {
+		t.Fatalf("Got incorrect extension: %v", info.Extension)
+	} else if !strings.HasPrefix(info.MimeType, "text/plain") {
+		t.Fatalf("Got incorrect mime type: %v", info.MimeType)
+	} else if info.IsImage() {
+		t.Fatal("shouldn't be an image")
+	}
+
+	if info := NewInfo("test.PNG"); info.Extension != "png" {
+		t.Fatalf("Got incorrect extension: %v", info.Extension)
+	} else if !info.IsImage() {
+		t.Fatal("should be an image")
+	}
+
+	if info := NewInfo("noextension"); info.Extension != "" {
+		t.Fatalf("Got incorrect extension: %v", info.Extension)
+	}
+}
+
 func TestGetInfoForFile(t *testing.T) {
 	fakeFile := make([]byte, 1000)
 
@@ -0,0 +1,184 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	LEGAL_HOLD_NAME_MAX_RUNES        = 64
+	LEGAL_HOLD_DESCRIPTION_MAX_RUNES = 1024
+	LEGAL_HOLD_IDS_MAX_RUNES         = 8000
+)
+
+// LegalHold preserves the posts and files of the given users and channels that were created during a range of time.
+// While a hold is in place, none of that data can be permanently deleted either by data retention or by deleting
+// the users, channels or teams that it belongs to. An EndAt of 0 means that the hold covers everything created after
+// StartAt.
+type LegalHold struct {
+	Id          string      `json:"id"`
+	CreateAt    int64       `json:"create_at"`
+	UpdateAt    int64       `json:"update_at"`
+	CreatorId   string      `json:"creator_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	UserIds     StringArray `json:"user_ids"`
+	ChannelIds  StringArray `json:"channel_ids"`
+	StartAt     int64       `json:"start_at"`
+	EndAt       int64       `json:"end_at"`
+}
+
+func (o *LegalHold) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LegalHoldFromJson(data io.Reader) *LegalHold {
+	decoder := json.NewDecoder(data)
+	var o LegalHold
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func LegalHoldsToJson(holds []*LegalHold) string {
+	b, err := json.Marshal(holds)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func LegalHoldsFromJson(data io.Reader) []*LegalHold {
+	decoder := json.NewDecoder(data)
+	var o []*LegalHold
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *LegalHold) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.UserIds == nil {
+		o.UserIds = []string{}
+	}
+
+	if o.ChannelIds == nil {
+		o.ChannelIds = []string{}
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+}
+
+func (o *LegalHold) PreUpdate() {
+	if o.UserIds == nil {
+		o.UserIds = []string{}
+	}
+
+	if o.ChannelIds == nil {
+		o.ChannelIds = []string{}
+	}
+
+	o.UpdateAt = GetMillis()
+}
+
+func (o *LegalHold) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.id.app_error", nil, "")
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.create_at.app_error", nil, "id="+o.Id)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.update_at.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.CreatorId) > 26 {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.creator_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Name) == 0 || utf8.RuneCountInString(o.Name) > LEGAL_HOLD_NAME_MAX_RUNES {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.name.app_error", nil, "id="+o.Id)
+	}
+
+	if utf8.RuneCountInString(o.Description) > LEGAL_HOLD_DESCRIPTION_MAX_RUNES {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.description.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.UserIds) == 0 && len(o.ChannelIds) == 0 {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.empty.app_error", nil, "id="+o.Id)
+	}
+
+	for _, userId := range o.UserIds {
+		if len(userId) != 26 {
+			return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.user_ids.app_error", nil, "id="+o.Id)
+		}
+	}
+
+	for _, channelId := range o.ChannelIds {
+		if len(channelId) != 26 {
+			return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.channel_ids.app_error", nil, "id="+o.Id)
+		}
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.UserIds)) > LEGAL_HOLD_IDS_MAX_RUNES || utf8.RuneCountInString(ArrayToJson(o.ChannelIds)) > LEGAL_HOLD_IDS_MAX_RUNES {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.too_many_ids.app_error", nil, "id="+o.Id)
+	}
+
+	if o.StartAt < 0 || o.EndAt < 0 || (o.EndAt != 0 && o.EndAt < o.StartAt) {
+		return NewLocAppError("LegalHold.IsValid", "model.legal_hold.is_valid.time_range.app_error", nil, "id="+o.Id)
+	}
+
+	return nil
+}
+
+func (o *LegalHold) HasUser(userId string) bool {
+	for _, id := range o.UserIds {
+		if id == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o *LegalHold) HasChannel(channelId string) bool {
+	for _, id := range o.ChannelIds {
+		if id == channelId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CoversTime returns true if something created at the given time falls within the hold's range.
+func (o *LegalHold) CoversTime(time int64) bool {
+	return time >= o.StartAt && (o.EndAt == 0 || time <= o.EndAt)
+}
+
+// CoversPost returns true if the hold preserves a post made by the given user in the given channel at the given time.
+func (o *LegalHold) CoversPost(userId string, channelId string, createAt int64) bool {
+	return (o.HasUser(userId) || o.HasChannel(channelId)) && o.CoversTime(createAt)
+}
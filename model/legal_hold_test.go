@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLegalHoldJson(t *testing.T) {
+	o := LegalHold{Id: NewId(), Name: NewId(), UserIds: []string{NewId()}, StartAt: 1000}
+	ro := LegalHoldFromJson(strings.NewReader(o.ToJson()))
+
+	if o.Id != ro.Id || o.Name != ro.Name || len(ro.UserIds) != 1 || o.UserIds[0] != ro.UserIds[0] || o.StartAt != ro.StartAt {
+		t.Fatal("legal holds do not match")
+	}
+
+	holds := LegalHoldsFromJson(strings.NewReader(LegalHoldsToJson([]*LegalHold{&o})))
+	if len(holds) != 1 || holds[0].Id != o.Id {
+		t.Fatal("legal holds do not match")
+	}
+}
+
+func TestLegalHoldIsValid(t *testing.T) {
+	o := LegalHold{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	o.Name = "hold"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid without any users or channels")
+	}
+
+	o.ChannelIds = []string{NewId()}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.UserIds = []string{"junk"}
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserIds = []string{NewId()}
+	o.StartAt = 2000
+	o.EndAt = 1000
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid when ending before it starts")
+	}
+
+	o.EndAt = 0
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Name = strings.Repeat("0", LEGAL_HOLD_NAME_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestLegalHoldCoversPost(t *testing.T) {
+	userId := NewId()
+	channelId := NewId()
+
+	o := LegalHold{UserIds: []string{userId}, StartAt: 1000, EndAt: 2000}
+
+	if !o.CoversPost(userId, NewId(), 1500) {
+		t.Fatal("should cover the user's posts during the hold")
+	}
+
+	if o.CoversPost(userId, NewId(), 2500) || o.CoversPost(userId, NewId(), 500) {
+		t.Fatal("shouldn't cover posts outside of the hold")
+	}
+
+	if o.CoversPost(NewId(), channelId, 1500) {
+		t.Fatal("shouldn't cover other users' posts")
+	}
+
+	o.ChannelIds = []string{channelId}
+	o.EndAt = 0
+
+	if !o.CoversPost(NewId(), channelId, 5000) {
+		t.Fatal("should cover any post in the channel after an open-ended hold starts")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	MFA_RECOVERY_CODE_COUNT  = 10
+	MFA_RECOVERY_CODE_LENGTH = 10
+)
+
+// MfaRecoveryCode is a single-use code that lets a user sign in without their MFA token. Only a hash of the code is
+// stored, so the codes can't be recovered from the database.
+type MfaRecoveryCode struct {
+	UserId   string
+	Hash     string
+	CreateAt int64
+}
+
+// NewMfaRecoveryCode generates a random recovery code, formatted in two groups so that it's easier to copy down.
+func NewMfaRecoveryCode() string {
+	code := NewRandomString(MFA_RECOVERY_CODE_LENGTH)
+	return code[:MFA_RECOVERY_CODE_LENGTH/2] + "-" + code[MFA_RECOVERY_CODE_LENGTH/2:]
+}
+
+// HashMfaRecoveryCode returns the hash stored for a recovery code. The case of the code and any whitespace or dashes
+// that the user typed are ignored. The codes are random enough that they don't need a salted password hash.
+func HashMfaRecoveryCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' || r == '\t' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
+func (o *MfaRecoveryCode) IsValid() *AppError {
+	if len(o.UserId) != 26 {
+		return NewLocAppError("MfaRecoveryCode.IsValid", "model.mfa_recovery_code.is_valid.user_id.app_error", nil, "")
+	}
+
+	if len(o.Hash) != sha256.Size*2 {
+		return NewLocAppError("MfaRecoveryCode.IsValid", "model.mfa_recovery_code.is_valid.hash.app_error", nil, "user_id="+o.UserId)
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("MfaRecoveryCode.IsValid", "model.mfa_recovery_code.is_valid.create_at.app_error", nil, "user_id="+o.UserId)
+	}
+
+	return nil
+}
+
+func (o *MfaRecoveryCode) PreSave() {
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
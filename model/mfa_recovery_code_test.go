@@ -0,0 +1,60 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewMfaRecoveryCode(t *testing.T) {
+	code := NewMfaRecoveryCode()
+	if len(code) != MFA_RECOVERY_CODE_LENGTH+1 || code[MFA_RECOVERY_CODE_LENGTH/2] != '-' {
+		t.Fatal("should've formatted the code in two groups", code)
+	}
+
+	if NewMfaRecoveryCode() == code {
+		t.Fatal("should've generated a different code")
+	}
+}
+
+func TestHashMfaRecoveryCode(t *testing.T) {
+	code := NewMfaRecoveryCode()
+	hash := HashMfaRecoveryCode(code)
+
+	if hash == code || len(hash) != 64 {
+		t.Fatal("should've hashed the code")
+	}
+
+	if HashMfaRecoveryCode(strings.ToUpper(strings.Replace(code, "-", " ", 1))) != hash {
+		t.Fatal("should've ignored the case and separators")
+	}
+
+	if HashMfaRecoveryCode(NewMfaRecoveryCode()) == hash {
+		t.Fatal("should've hashed a different code differently")
+	}
+}
+
+func TestMfaRecoveryCodeIsValid(t *testing.T) {
+	o := MfaRecoveryCode{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Hash = HashMfaRecoveryCode(NewMfaRecoveryCode())
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+const (
+	USER_AUTH_SERVICE_OPENID = "openid"
+)
+
+// OpenIdTokens are the tokens issued to the server when a user signs in with an OpenID Connect provider. Claims holds
+// the verified claims of the ID token as JSON, which takes the place of the response from a user API endpoint.
+type OpenIdTokens struct {
+	AccessToken  string
+	RefreshToken string
+	Claims       string
+}
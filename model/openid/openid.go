@@ -0,0 +1,486 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package oauthopenid
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+	"gopkg.in/square/go-jose.v1"
+)
+
+const (
+	DISCOVERY_PATH = "/.well-known/openid-configuration"
+
+	// How long the discovery document and signing keys are used before they're requested from the provider again
+	DISCOVERY_CACHE_TIME = 60 * 60 * 1000 // 1 hour
+
+	// How far the clocks of the provider and this server can differ when checking the times in an ID token
+	CLOCK_SKEW = 5 * 60 // 5 minutes
+
+	REQUEST_TIMEOUT = 30 * time.Second
+)
+
+// The algorithms that ID tokens can be signed with. Symmetric algorithms aren't supported since they'd use the client
+// secret as the key.
+var allowedAlgorithms = map[string]bool{
+	"RS256": true,
+	"RS384": true,
+	"RS512": true,
+	"ES256": true,
+	"ES384": true,
+	"ES512": true,
+}
+
+// OpenIdProvider signs users in with any identity provider that supports OpenID Connect. Its endpoints are found
+// using the discovery document published by the issuer set in OpenIdSettings.Issuer, and users are identified by the
+// claims of the ID token after its signature has been checked against the issuer's published keys.
+type OpenIdProvider struct {
+	documents map[string]*discoveryDocument
+	keySets   map[string]*keySet
+	mutex     sync.Mutex
+}
+
+type discoveryDocument struct {
+	Issuer                   string   `json:"issuer"`
+	AuthorizationEndpoint    string   `json:"authorization_endpoint"`
+	TokenEndpoint            string   `json:"token_endpoint"`
+	JwksUri                  string   `json:"jwks_uri"`
+	TokenEndpointAuthMethods []string `json:"token_endpoint_auth_methods_supported"`
+
+	fetchedAt int64
+}
+
+type keySet struct {
+	jose.JsonWebKeySet
+
+	fetchedAt int64
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	IdToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type OpenIdClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Email             string   `json:"email"`
+	EmailVerified     *bool    `json:"email_verified"`
+	PreferredUsername string   `json:"preferred_username"`
+	Name              string   `json:"name"`
+	GivenName         string   `json:"given_name"`
+	FamilyName        string   `json:"family_name"`
+}
+
+// audience is the aud claim of an ID token, which is either a single client id or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*a = audience(multiple)
+	return nil
+}
+
+func (a audience) contains(clientId string) bool {
+	for _, aud := range a {
+		if aud == clientId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	einterfaces.RegisterOauthProvider(model.USER_AUTH_SERVICE_OPENID, NewOpenIdProvider())
+}
+
+func NewOpenIdProvider() *OpenIdProvider {
+	return &OpenIdProvider{
+		documents: make(map[string]*discoveryDocument),
+		keySets:   make(map[string]*keySet),
+	}
+}
+
+func openIdClaimsFromJson(data io.Reader) *OpenIdClaims {
+	decoder := json.NewDecoder(data)
+	var claims OpenIdClaims
+	err := decoder.Decode(&claims)
+	if err == nil {
+		return &claims
+	} else {
+		return nil
+	}
+}
+
+func (claims *OpenIdClaims) IsValid() bool {
+	if len(claims.Subject) == 0 {
+		return false
+	}
+
+	if len(claims.Email) == 0 {
+		return false
+	}
+
+	// Providers that allow users to set their own email address tell us when it hasn't been verified
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return false
+	}
+
+	return true
+}
+
+func userFromClaims(claims *OpenIdClaims) *model.User {
+	user := &model.User{}
+
+	username := claims.PreferredUsername
+	if username == "" {
+		username = strings.Split(claims.Email, "@")[0]
+	}
+	user.Username = model.CleanUsername(username)
+
+	if claims.GivenName != "" || claims.FamilyName != "" {
+		user.FirstName = claims.GivenName
+		user.LastName = claims.FamilyName
+	} else {
+		splitName := strings.Split(claims.Name, " ")
+		if len(splitName) >= 2 {
+			user.FirstName = splitName[0]
+			user.LastName = strings.Join(splitName[1:], " ")
+		} else {
+			user.FirstName = claims.Name
+		}
+	}
+
+	user.Email = strings.TrimSpace(claims.Email)
+	subject := claims.Subject
+	user.AuthData = &subject
+	user.AuthService = model.USER_AUTH_SERVICE_OPENID
+
+	return user
+}
+
+func (p *OpenIdProvider) GetIdentifier() string {
+	return model.USER_AUTH_SERVICE_OPENID
+}
+
+func (p *OpenIdProvider) GetUserFromJson(data io.Reader) *model.User {
+	if claims := openIdClaimsFromJson(data); claims != nil && claims.IsValid() {
+		return userFromClaims(claims)
+	}
+
+	return &model.User{}
+}
+
+func (p *OpenIdProvider) GetAuthDataFromJson(data io.Reader) string {
+	if claims := openIdClaimsFromJson(data); claims != nil && claims.IsValid() {
+		return claims.Subject
+	}
+
+	return ""
+}
+
+func (p *OpenIdProvider) GetAuthEndpoint(settings *model.SSOSettings) (string, *model.AppError) {
+	if doc, err := p.getDiscoveryDocument(settings.Issuer); err != nil {
+		return "", err
+	} else {
+		return doc.AuthorizationEndpoint, nil
+	}
+}
+
+func (p *OpenIdProvider) RedeemCode(settings *model.SSOSettings, code string, redirectUri string) (*model.OpenIdTokens, *model.AppError) {
+	params := url.Values{}
+	params.Set("grant_type", model.ACCESS_TOKEN_GRANT_TYPE)
+	params.Set("code", code)
+	params.Set("redirect_uri", redirectUri)
+
+	tokens, err := p.requestTokens(settings, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens.Claims) == 0 {
+		return nil, model.NewLocAppError("OpenIdProvider.RedeemCode", "ent.openid.missing_id_token.app_error", nil, "")
+	}
+
+	return tokens, nil
+}
+
+// RefreshTokens asks the provider for new tokens using a refresh token that it issued earlier. The returned error has
+// a status code of http.StatusUnauthorized if the provider no longer accepts the refresh token, such as when the user
+// has been deactivated or has signed out of the provider. Claims is only set if the provider issued a new ID token.
+func (p *OpenIdProvider) RefreshTokens(settings *model.SSOSettings, refreshToken string) (*model.OpenIdTokens, *model.AppError) {
+	params := url.Values{}
+	params.Set("grant_type", model.REFRESH_TOKEN_GRANT_TYPE)
+	params.Set("refresh_token", refreshToken)
+
+	tokens, err := p.requestTokens(settings, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider doesn't have to issue a new refresh token, in which case the current one can be used again
+	if len(tokens.RefreshToken) == 0 {
+		tokens.RefreshToken = refreshToken
+	}
+
+	return tokens, nil
+}
+
+func (p *OpenIdProvider) requestTokens(settings *model.SSOSettings, params url.Values) (*model.OpenIdTokens, *model.AppError) {
+	doc, err := p.getDiscoveryDocument(settings.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Client credentials are sent using HTTP basic authentication unless the provider only supports sending them
+	// with the rest of the parameters
+	useBasicAuth := true
+	if len(doc.TokenEndpointAuthMethods) > 0 {
+		useBasicAuth = false
+		for _, method := range doc.TokenEndpointAuthMethods {
+			if method == "client_secret_basic" {
+				useBasicAuth = true
+			}
+		}
+	}
+
+	if !useBasicAuth {
+		params.Set("client_id", settings.Id)
+		params.Set("client_secret", settings.Secret)
+	}
+
+	req, _ := http.NewRequest("POST", doc.TokenEndpoint, strings.NewReader(params.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if useBasicAuth {
+		req.SetBasicAuth(url.QueryEscape(settings.Id), url.QueryEscape(settings.Secret))
+	}
+
+	resp, httpErr := httpClient().Do(req)
+	if httpErr != nil {
+		return nil, model.NewLocAppError("OpenIdProvider.requestTokens", "ent.openid.token_request.app_error", nil, httpErr.Error())
+	}
+	defer func() {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	var response tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, model.NewLocAppError("OpenIdProvider.requestTokens", "ent.openid.bad_response.app_error", nil, "status="+resp.Status+", "+err.Error())
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		appErr := model.NewLocAppError("OpenIdProvider.requestTokens", "ent.openid.token_rejected.app_error", nil, "error="+response.Error+", description="+response.ErrorDescription)
+		appErr.StatusCode = http.StatusInternalServerError
+
+		if response.Error == "invalid_grant" {
+			appErr.StatusCode = http.StatusUnauthorized
+		}
+
+		return nil, appErr
+	}
+
+	if strings.ToLower(response.TokenType) != model.ACCESS_TOKEN_TYPE || len(response.AccessToken) == 0 {
+		return nil, model.NewLocAppError("OpenIdProvider.requestTokens", "ent.openid.bad_response.app_error", nil, "token_type="+response.TokenType)
+	}
+
+	tokens := &model.OpenIdTokens{
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+	}
+
+	if len(response.IdToken) > 0 {
+		if claims, err := p.verifyIdToken(settings, doc, response.IdToken); err != nil {
+			return nil, err
+		} else {
+			tokens.Claims = string(claims)
+		}
+	}
+
+	return tokens, nil
+}
+
+// verifyIdToken checks that an ID token was signed by the provider for this server and returns its claims.
+func (p *OpenIdProvider) verifyIdToken(settings *model.SSOSettings, doc *discoveryDocument, idToken string) ([]byte, *model.AppError) {
+	jws, err := jose.ParseSigned(idToken)
+	if err != nil {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, err.Error())
+	}
+
+	if len(jws.Signatures) != 1 {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "the token must have exactly one signature")
+	}
+
+	header := jws.Signatures[0].Header
+	if !allowedAlgorithms[header.Algorithm] {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "alg="+header.Algorithm)
+	}
+
+	keys, appErr := p.getKeys(doc.JwksUri, header.KeyID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	var payload []byte
+	for _, key := range keys {
+		if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+			continue
+		}
+
+		if payload, err = jws.Verify(key.Key); err == nil {
+			break
+		}
+	}
+
+	if payload == nil {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_signature.app_error", nil, "kid="+header.KeyID)
+	}
+
+	claims := openIdClaimsFromJson(strings.NewReader(string(payload)))
+	if claims == nil {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "unable to parse the claims")
+	}
+
+	now := model.GetMillis() / 1000
+
+	if claims.Issuer != doc.Issuer {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "iss="+claims.Issuer)
+	} else if !claims.Audience.contains(settings.Id) {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "aud="+strings.Join(claims.Audience, ","))
+	} else if len(claims.Audience) > 1 && claims.AuthorizedParty != settings.Id {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "azp="+claims.AuthorizedParty)
+	} else if claims.ExpiresAt+CLOCK_SKEW < now {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "the token has expired")
+	} else if claims.IssuedAt-CLOCK_SKEW > now {
+		return nil, model.NewLocAppError("OpenIdProvider.verifyIdToken", "ent.openid.invalid_id_token.app_error", nil, "the token was issued in the future")
+	}
+
+	return payload, nil
+}
+
+func (p *OpenIdProvider) getDiscoveryDocument(issuer string) (*discoveryDocument, *model.AppError) {
+	p.mutex.Lock()
+	doc, ok := p.documents[issuer]
+	p.mutex.Unlock()
+
+	if ok && model.GetMillis()-doc.fetchedAt < DISCOVERY_CACHE_TIME {
+		return doc, nil
+	}
+
+	doc = &discoveryDocument{}
+	if err := getJson(strings.TrimSuffix(issuer, "/")+DISCOVERY_PATH, doc); err != nil {
+		return nil, model.NewLocAppError("OpenIdProvider.getDiscoveryDocument", "ent.openid.discovery.app_error", nil, "issuer="+issuer+", "+err.Error())
+	}
+
+	if doc.Issuer != issuer {
+		return nil, model.NewLocAppError("OpenIdProvider.getDiscoveryDocument", "ent.openid.discovery.app_error", nil, "issuer="+issuer+", discovered_issuer="+doc.Issuer)
+	} else if len(doc.AuthorizationEndpoint) == 0 || len(doc.TokenEndpoint) == 0 || len(doc.JwksUri) == 0 {
+		return nil, model.NewLocAppError("OpenIdProvider.getDiscoveryDocument", "ent.openid.discovery.app_error", nil, "issuer="+issuer+", missing endpoints")
+	}
+
+	doc.fetchedAt = model.GetMillis()
+
+	p.mutex.Lock()
+	p.documents[issuer] = doc
+	p.mutex.Unlock()
+
+	return doc, nil
+}
+
+// getKeys returns the provider's signing keys with the given key id, or all of them if the id is blank. The keys are
+// requested again if none of them match since the provider may have started signing with a new key.
+func (p *OpenIdProvider) getKeys(jwksUri string, keyId string) ([]jose.JsonWebKey, *model.AppError) {
+	p.mutex.Lock()
+	keys, ok := p.keySets[jwksUri]
+	p.mutex.Unlock()
+
+	if ok && model.GetMillis()-keys.fetchedAt < DISCOVERY_CACHE_TIME {
+		if matching := keys.match(keyId); len(matching) > 0 {
+			return matching, nil
+		}
+	}
+
+	keys = &keySet{}
+	if err := getJson(jwksUri, &keys.JsonWebKeySet); err != nil {
+		return nil, model.NewLocAppError("OpenIdProvider.getKeys", "ent.openid.keys.app_error", nil, "jwks_uri="+jwksUri+", "+err.Error())
+	}
+
+	keys.fetchedAt = model.GetMillis()
+
+	p.mutex.Lock()
+	p.keySets[jwksUri] = keys
+	p.mutex.Unlock()
+
+	return keys.match(keyId), nil
+}
+
+func (keys *keySet) match(keyId string) []jose.JsonWebKey {
+	if keyId == "" {
+		return keys.Keys
+	}
+
+	return keys.Key(keyId)
+}
+
+func httpClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: *utils.Cfg.ServiceSettings.EnableInsecureOutgoingConnections},
+		},
+		Timeout: REQUEST_TIMEOUT,
+	}
+}
+
+func getJson(location string, v interface{}) error {
+	req, _ := http.NewRequest("GET", location, nil)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %v", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
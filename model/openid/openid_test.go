@@ -0,0 +1,346 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package oauthopenid
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+	"gopkg.in/square/go-jose.v1"
+)
+
+// fakeIdentityProvider is an OpenID Connect provider that issues an ID token with the given claims for the code "code"
+// and for any refresh token that it issued and hasn't been used yet.
+type fakeIdentityProvider struct {
+	server *httptest.Server
+
+	clientId     string
+	clientSecret string
+
+	key   *rsa.PrivateKey
+	keyId string
+
+	// The key that ID tokens are signed with, which is the published key unless a test changes it
+	signingKey   *rsa.PrivateKey
+	signingKeyId string
+
+	claims        map[string]interface{}
+	refreshTokens map[string]bool
+	mutex         sync.Mutex
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	idp := &fakeIdentityProvider{
+		clientId:      model.NewId(),
+		clientSecret:  model.NewId(),
+		refreshTokens: make(map[string]bool),
+	}
+
+	idp.rotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(DISCOVERY_PATH, idp.handleDiscovery)
+	mux.HandleFunc("/keys", idp.handleKeys)
+	mux.HandleFunc("/token", idp.handleToken)
+	idp.server = httptest.NewServer(mux)
+
+	idp.claims = map[string]interface{}{
+		"iss":                idp.server.URL,
+		"sub":                "subject",
+		"aud":                idp.clientId,
+		"exp":                model.GetMillis()/1000 + 60,
+		"iat":                model.GetMillis() / 1000,
+		"email":              "jdoe@example.com",
+		"email_verified":     true,
+		"preferred_username": "JDoe",
+		"given_name":         "Jane",
+		"family_name":        "Doe",
+	}
+
+	return idp
+}
+
+func (idp *fakeIdentityProvider) rotateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	idp.key = key
+	idp.keyId = model.NewId()
+	idp.signingKey = key
+	idp.signingKeyId = idp.keyId
+}
+
+func (idp *fakeIdentityProvider) settings() *model.SSOSettings {
+	return &model.SSOSettings{
+		Enable: true,
+		Id:     idp.clientId,
+		Secret: idp.clientSecret,
+		Scope:  model.OPENID_SETTINGS_DEFAULT_SCOPE,
+		Issuer: idp.server.URL,
+	}
+}
+
+func (idp *fakeIdentityProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(model.StringInterfaceToJson(map[string]interface{}{
+		"issuer":                 idp.server.URL,
+		"authorization_endpoint": idp.server.URL + "/authorize",
+		"token_endpoint":         idp.server.URL + "/token",
+		"jwks_uri":               idp.server.URL + "/keys",
+	})))
+}
+
+func (idp *fakeIdentityProvider) handleKeys(w http.ResponseWriter, r *http.Request) {
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	keys := jose.JsonWebKeySet{
+		Keys: []jose.JsonWebKey{{Key: &idp.key.PublicKey, KeyID: idp.keyId, Algorithm: "RS256", Use: "sig"}},
+	}
+
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (idp *fakeIdentityProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	if clientId, clientSecret, ok := r.BasicAuth(); !ok || clientId != idp.clientId || clientSecret != idp.clientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid_client"}`))
+		return
+	}
+
+	switch r.FormValue("grant_type") {
+	case "authorization_code":
+		if r.FormValue("code") != "code" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+	case "refresh_token":
+		if !idp.refreshTokens[r.FormValue("refresh_token")] {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_grant"}`))
+			return
+		}
+
+		delete(idp.refreshTokens, r.FormValue("refresh_token"))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "unsupported_grant_type"}`))
+		return
+	}
+
+	signer, err := jose.NewSigner(jose.RS256, &jose.JsonWebKey{Key: idp.signingKey, KeyID: idp.signingKeyId})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	jws, err := signer.Sign([]byte(model.StringInterfaceToJson(idp.claims)))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	idToken, _ := jws.CompactSerialize()
+
+	refreshToken := model.NewId()
+	idp.refreshTokens[refreshToken] = true
+
+	w.Write([]byte(model.StringInterfaceToJson(map[string]interface{}{
+		"access_token":  model.NewId(),
+		"token_type":    "Bearer",
+		"refresh_token": refreshToken,
+		"id_token":      idToken,
+	})))
+}
+
+func (idp *fakeIdentityProvider) setClaim(name string, value interface{}) {
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+
+	idp.claims[name] = value
+}
+
+func setupOpenIdTest(t *testing.T) *fakeIdentityProvider {
+	utils.TranslationsPreInit()
+	utils.LoadTestConfig()
+
+	return newFakeIdentityProvider(t)
+}
+
+func TestGetAuthEndpoint(t *testing.T) {
+	idp := setupOpenIdTest(t)
+	defer idp.server.Close()
+
+	provider := NewOpenIdProvider()
+
+	if endpoint, err := provider.GetAuthEndpoint(idp.settings()); err != nil {
+		t.Fatal(err)
+	} else if endpoint != idp.server.URL+"/authorize" {
+		t.Fatal("returned the wrong endpoint")
+	}
+
+	settings := idp.settings()
+	settings.Issuer = idp.server.URL + "/"
+	if _, err := provider.GetAuthEndpoint(settings); err == nil {
+		t.Fatal("should've failed when the discovered issuer doesn't match")
+	}
+}
+
+func TestRedeemCode(t *testing.T) {
+	idp := setupOpenIdTest(t)
+	defer idp.server.Close()
+
+	provider := NewOpenIdProvider()
+
+	tokens, err := provider.RedeemCode(idp.settings(), "code", "http://localhost/signup/openid/complete")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens.AccessToken) == 0 || len(tokens.RefreshToken) == 0 {
+		t.Fatal("should've returned the tokens")
+	}
+
+	if authData := provider.GetAuthDataFromJson(strings.NewReader(tokens.Claims)); authData != "subject" {
+		t.Fatal("should've used the subject as the auth data")
+	}
+
+	user := provider.GetUserFromJson(strings.NewReader(tokens.Claims))
+	if user.Username != "jdoe" || user.Email != "jdoe@example.com" || user.FirstName != "Jane" || user.LastName != "Doe" {
+		t.Fatal("should've mapped the claims to the user")
+	} else if user.AuthService != model.USER_AUTH_SERVICE_OPENID || *user.AuthData != "subject" {
+		t.Fatal("should've set the user's auth service")
+	}
+
+	if _, err := provider.RedeemCode(idp.settings(), "wrong", "http://localhost/signup/openid/complete"); err == nil {
+		t.Fatal("should've failed with a code that the provider didn't issue")
+	}
+
+	settings := idp.settings()
+	settings.Secret = "wrong"
+	if _, err := provider.RedeemCode(settings, "code", "http://localhost/signup/openid/complete"); err == nil {
+		t.Fatal("should've failed with the wrong client secret")
+	}
+}
+
+func TestRedeemCodeInvalidIdToken(t *testing.T) {
+	for name, claim := range map[string]struct {
+		name  string
+		value interface{}
+	}{
+		"wrong issuer":      {"iss", "https://example.com"},
+		"wrong audience":    {"aud", "someone-else"},
+		"multiple audience": {"aud", []string{"someone-else", "another"}},
+		"expired":           {"exp", model.GetMillis()/1000 - 60*60},
+		"issued later":      {"iat", model.GetMillis()/1000 + 60*60},
+	} {
+		idp := setupOpenIdTest(t)
+		idp.setClaim(claim.name, claim.value)
+
+		if _, err := NewOpenIdProvider().RedeemCode(idp.settings(), "code", ""); err == nil {
+			t.Fatalf("%v: should've rejected the ID token", name)
+		}
+
+		idp.server.Close()
+	}
+}
+
+func TestRedeemCodeWithUnknownKey(t *testing.T) {
+	idp := setupOpenIdTest(t)
+	defer idp.server.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp.mutex.Lock()
+	idp.signingKey = key
+	idp.mutex.Unlock()
+
+	if _, err := NewOpenIdProvider().RedeemCode(idp.settings(), "code", ""); err == nil {
+		t.Fatal("should've rejected an ID token that wasn't signed with the published key")
+	}
+}
+
+func TestRedeemCodeAfterKeyRotation(t *testing.T) {
+	idp := setupOpenIdTest(t)
+	defer idp.server.Close()
+
+	provider := NewOpenIdProvider()
+
+	if _, err := provider.RedeemCode(idp.settings(), "code", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	idp.rotateKey(t)
+
+	if _, err := provider.RedeemCode(idp.settings(), "code", ""); err != nil {
+		t.Fatal("should've requested the new signing key", err)
+	}
+}
+
+func TestRefreshTokens(t *testing.T) {
+	idp := setupOpenIdTest(t)
+	defer idp.server.Close()
+
+	provider := NewOpenIdProvider()
+
+	tokens, err := provider.RedeemCode(idp.settings(), "code", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := provider.RefreshTokens(idp.settings(), tokens.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if refreshed.RefreshToken == tokens.RefreshToken {
+		t.Fatal("should've returned the new refresh token")
+	} else if provider.GetAuthDataFromJson(strings.NewReader(refreshed.Claims)) != "subject" {
+		t.Fatal("should've returned the claims of the new ID token")
+	}
+
+	if _, err := provider.RefreshTokens(idp.settings(), tokens.RefreshToken); err == nil {
+		t.Fatal("should've failed to reuse a refresh token")
+	} else if err.StatusCode != http.StatusUnauthorized {
+		t.Fatal("should've reported that the refresh token was rejected")
+	}
+}
+
+func TestGetUserFromJson(t *testing.T) {
+	provider := NewOpenIdProvider()
+
+	user := provider.GetUserFromJson(strings.NewReader(`{"sub": "subject", "email": "jane.doe@example.com", "name": "Jane van Doe"}`))
+	if user.Username != "jane.doe" {
+		t.Fatal("should've used the email address for the username")
+	} else if user.FirstName != "Jane" || user.LastName != "van Doe" {
+		t.Fatal("should've split the name")
+	}
+
+	if user := provider.GetUserFromJson(strings.NewReader(`{"sub": "subject", "email": "jdoe@example.com", "email_verified": false}`)); user.Email != "" {
+		t.Fatal("shouldn't accept an email address that hasn't been verified")
+	}
+
+	if authData := provider.GetAuthDataFromJson(strings.NewReader(`{"email": "jdoe@example.com"}`)); authData != "" {
+		t.Fatal("shouldn't accept claims without a subject")
+	}
+}
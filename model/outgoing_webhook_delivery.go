@@ -0,0 +1,173 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+const (
+	OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING = "pending"
+	OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS = "success"
+	OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED  = "failed"
+
+	OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_SIZE = 65535
+	OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH = 1024
+
+	HEADER_WEBHOOK_SIGNATURE = "X-Mattermost-Signature"
+	HEADER_WEBHOOK_DELIVERY  = "X-Mattermost-Delivery"
+)
+
+// OutgoingWebhookDelivery is a request that's sent to one of the callback URLs of an outgoing webhook. Deliveries are
+// saved before they're sent so that they can be retried if the callback URL can't be reached, and they're kept
+// afterwards as a log of the requests that were sent.
+//
+// Events sent to event subscriptions are delivered the same way. Those deliveries have the name of the event in Event
+// and the id of the subscription in HookId, and they don't belong to a post.
+type OutgoingWebhookDelivery struct {
+	Id            string `json:"id"`
+	CreateAt      int64  `json:"create_at"`
+	HookId        string `json:"hook_id"`
+	Event         string `json:"event,omitempty"`
+	PostId        string `json:"post_id"`
+	ChannelId     string `json:"channel_id"`
+	URL           string `json:"url"`
+	ContentType   string `json:"content_type"`
+	Payload       string `json:"payload"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt int64  `json:"next_attempt_at"`
+	LastAttemptAt int64  `json:"last_attempt_at"`
+	StatusCode    int    `json:"status_code"`
+	Latency       int64  `json:"latency"`
+	ErrorMessage  string `json:"error_message"`
+}
+
+func (o *OutgoingWebhookDelivery) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OutgoingWebhookDeliveryFromJson(data io.Reader) *OutgoingWebhookDelivery {
+	decoder := json.NewDecoder(data)
+	var o OutgoingWebhookDelivery
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func OutgoingWebhookDeliveriesToJson(deliveries []*OutgoingWebhookDelivery) string {
+	b, err := json.Marshal(deliveries)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func OutgoingWebhookDeliveriesFromJson(data io.Reader) []*OutgoingWebhookDelivery {
+	decoder := json.NewDecoder(data)
+	var o []*OutgoingWebhookDelivery
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *OutgoingWebhookDelivery) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.CreateAt = GetMillis()
+
+	if o.Status == "" {
+		o.Status = OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING
+		o.NextAttemptAt = o.CreateAt
+	}
+}
+
+func (o *OutgoingWebhookDelivery) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.id.app_error", nil, "")
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.create_at.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.HookId) != 26 {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.hook_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Event) > 64 {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.event.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Event) == 0 && len(o.PostId) != 26 {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.post_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Event) == 0 && len(o.ChannelId) != 26 {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.channel_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.URL) == 0 || len(o.URL) > 1024 || !IsValidHttpUrl(o.URL) {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.url.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Payload) > OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_SIZE {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.payload.app_error", nil, "id="+o.Id)
+	}
+
+	if o.Status != OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING && o.Status != OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS && o.Status != OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED {
+		return NewLocAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_webhook_delivery.is_valid.status.app_error", nil, "id="+o.Id)
+	}
+
+	return nil
+}
+
+// SetError records why the latest attempt failed, truncating the message to fit in the database.
+func (o *OutgoingWebhookDelivery) SetError(message string) {
+	if len(message) > OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH {
+		message = message[:OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH]
+	}
+
+	o.ErrorMessage = message
+}
+
+// Redelivery returns a new pending delivery with the same request as this one.
+func (o *OutgoingWebhookDelivery) Redelivery() *OutgoingWebhookDelivery {
+	return &OutgoingWebhookDelivery{
+		HookId:      o.HookId,
+		Event:       o.Event,
+		PostId:      o.PostId,
+		ChannelId:   o.ChannelId,
+		URL:         o.URL,
+		ContentType: o.ContentType,
+		Payload:     o.Payload,
+	}
+}
+
+// SignOutgoingWebhookPayload returns the value of the signature header that's sent with an outgoing webhook request.
+// Receivers can compute the HMAC-SHA256 of the request body using the hook's token as the key to check that the
+// request came from this server.
+func SignOutgoingWebhookPayload(token string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutgoingWebhookDeliveryJson(t *testing.T) {
+	o := OutgoingWebhookDelivery{Id: NewId(), HookId: NewId(), URL: "http://example.com", Payload: "text=hello"}
+	json := o.ToJson()
+
+	ro := OutgoingWebhookDeliveryFromJson(strings.NewReader(json))
+	if ro.Id != o.Id || ro.HookId != o.HookId || ro.Payload != o.Payload {
+		t.Fatal("ids do not match")
+	}
+
+	deliveries := OutgoingWebhookDeliveriesFromJson(strings.NewReader(OutgoingWebhookDeliveriesToJson([]*OutgoingWebhookDelivery{&o})))
+	if len(deliveries) != 1 || deliveries[0].Id != o.Id {
+		t.Fatal("ids do not match")
+	}
+}
+
+func TestOutgoingWebhookDeliveryIsValid(t *testing.T) {
+	o := OutgoingWebhookDelivery{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.PreSave()
+	if o.Status != OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING || o.NextAttemptAt != o.CreateAt {
+		t.Fatal("should be pending")
+	}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.HookId = NewId()
+	o.PostId = NewId()
+	o.ChannelId = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.URL = "nowhere"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.URL = "http://example.com"
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Payload = strings.Repeat("0", OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_SIZE+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Payload = ""
+	o.Status = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	// event subscription deliveries don't belong to a post
+	o.Status = OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING
+	o.PostId = ""
+	o.ChannelId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Event = WEBSOCKET_EVENT_CHANNEL_CREATED
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Event = strings.Repeat("0", 65)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestOutgoingWebhookDeliverySetError(t *testing.T) {
+	o := OutgoingWebhookDelivery{}
+
+	o.SetError(strings.Repeat("0", OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH+1))
+	if len(o.ErrorMessage) != OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH {
+		t.Fatal("should've truncated the error")
+	}
+}
+
+func TestOutgoingWebhookDeliveryRedelivery(t *testing.T) {
+	o := OutgoingWebhookDelivery{HookId: NewId(), Event: WEBSOCKET_EVENT_POST_EDITED, PostId: NewId(), ChannelId: NewId(), URL: "http://example.com", Payload: "text=hello"}
+	o.PreSave()
+	o.Attempts = 5
+	o.Status = OUTGOING_WEBHOOK_DELIVERY_STATUS_FAILED
+
+	r := o.Redelivery()
+	r.PreSave()
+
+	if r.Id == o.Id || r.Payload != o.Payload || r.URL != o.URL || r.Event != o.Event || r.Attempts != 0 || r.Status != OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING {
+		t.Fatal("should've created a new pending delivery")
+	}
+}
+
+func TestSignOutgoingWebhookPayload(t *testing.T) {
+	// Computed with: echo -n "text=hello" | openssl dgst -sha256 -hmac "token"
+	if SignOutgoingWebhookPayload("token", []byte("text=hello")) != "sha256=d0c41de50068d5bb5881cb33a3593e8feb66b2921bb75bc6e3464a96d3bbeb10" {
+		t.Fatal("should've signed the payload")
+	}
+
+	if SignOutgoingWebhookPayload("token", []byte("text=hello")) == SignOutgoingWebhookPayload("other", []byte("text=hello")) {
+		t.Fatal("signatures should depend on the token")
+	}
+}
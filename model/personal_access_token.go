@@ -0,0 +1,116 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	PERSONAL_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES = 255
+)
+
+// PersonalAccessToken lets scripts and integrations use the API as the user who owns it. Unlike a session, it never
+// expires and stays valid until it's revoked. Only a hash of the token is stored, so the token itself is only known
+// when it's created.
+type PersonalAccessToken struct {
+	Id          string `json:"id"`
+	Token       string `json:"token,omitempty" db:"-"`
+	TokenHash   string `json:"-"`
+	CreateAt    int64  `json:"create_at"`
+	UserId      string `json:"user_id"`
+	Description string `json:"description"`
+}
+
+func (o *PersonalAccessToken) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PersonalAccessTokenFromJson(data io.Reader) *PersonalAccessToken {
+	decoder := json.NewDecoder(data)
+	var o PersonalAccessToken
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func PersonalAccessTokensToJson(tokens []*PersonalAccessToken) string {
+	b, err := json.Marshal(tokens)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PersonalAccessTokensFromJson(data io.Reader) []*PersonalAccessToken {
+	decoder := json.NewDecoder(data)
+	var o []*PersonalAccessToken
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+// HashPersonalAccessToken returns the hash that's stored for a token. Tokens are random enough that they don't need a
+// salted password hash, and an unsalted hash lets a token be looked up by its hash.
+func HashPersonalAccessToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// PreSave generates a new token, which is left in Token so that it can be given to the user.
+func (o *PersonalAccessToken) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	o.Token = NewId()
+	o.TokenHash = HashPersonalAccessToken(o.Token)
+
+	o.CreateAt = GetMillis()
+}
+
+func (o *PersonalAccessToken) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewLocAppError("PersonalAccessToken.IsValid", "model.personal_access_token.is_valid.id.app_error", nil, "")
+	}
+
+	if len(o.TokenHash) != sha256.Size*2 {
+		return NewLocAppError("PersonalAccessToken.IsValid", "model.personal_access_token.is_valid.token_hash.app_error", nil, "id="+o.Id)
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("PersonalAccessToken.IsValid", "model.personal_access_token.is_valid.create_at.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewLocAppError("PersonalAccessToken.IsValid", "model.personal_access_token.is_valid.user_id.app_error", nil, "id="+o.Id)
+	}
+
+	if utf8.RuneCountInString(o.Description) > PERSONAL_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES {
+		return NewLocAppError("PersonalAccessToken.IsValid", "model.personal_access_token.is_valid.description.app_error", nil, "id="+o.Id)
+	}
+
+	return nil
+}
+
+// Sanitize removes the token so that it's only returned when the token is created.
+func (o *PersonalAccessToken) Sanitize() {
+	o.Token = ""
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPersonalAccessTokenJson(t *testing.T) {
+	o := PersonalAccessToken{Id: NewId(), Token: NewId(), TokenHash: "hash", UserId: NewId(), Description: "script"}
+	json := o.ToJson()
+
+	if strings.Contains(json, "hash") {
+		t.Fatal("shouldn't have included the hash")
+	}
+
+	ro := PersonalAccessTokenFromJson(strings.NewReader(json))
+	if ro.Id != o.Id || ro.Token != o.Token || ro.Description != o.Description {
+		t.Fatal("ids do not match")
+	}
+
+	o.Sanitize()
+	if strings.Contains(o.ToJson(), `"token"`) {
+		t.Fatal("should've removed the token")
+	}
+}
+
+func TestPersonalAccessTokenPreSave(t *testing.T) {
+	o := PersonalAccessToken{UserId: NewId()}
+	o.PreSave()
+
+	if len(o.Id) != 26 || len(o.Token) != 26 || o.CreateAt == 0 {
+		t.Fatal("should've generated the token")
+	}
+
+	if o.TokenHash != HashPersonalAccessToken(o.Token) || o.TokenHash == o.Token {
+		t.Fatal("should've hashed the token")
+	}
+
+	token := o.Token
+	o.PreSave()
+	if o.Token == token {
+		t.Fatal("should've generated a new token")
+	}
+}
+
+func TestPersonalAccessTokenIsValid(t *testing.T) {
+	o := PersonalAccessToken{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Id = NewId()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.TokenHash = HashPersonalAccessToken(NewId())
+	o.CreateAt = GetMillis()
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Description = strings.Repeat("0123456789", 26)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestSessionIsPersonalAccessToken(t *testing.T) {
+	session := Session{}
+	session.PreSave()
+
+	if session.IsPersonalAccessToken() {
+		t.Fatal("a normal session isn't for a personal access token")
+	}
+
+	session.Props[SESSION_PROP_TYPE] = SESSION_TYPE_PERSONAL_ACCESS_TOKEN
+	if !session.IsPersonalAccessToken() {
+		t.Fatal("should've been for a personal access token")
+	}
+}
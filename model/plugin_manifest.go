@@ -0,0 +1,123 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	PLUGIN_MANIFEST_FILENAME = "plugin.json"
+
+	PLUGIN_ID_MIN_LENGTH = 3
+	PLUGIN_ID_MAX_LENGTH = 190
+)
+
+var validPluginId = regexp.MustCompile(`^[a-zA-Z0-9-_\.]+$`)
+
+// Manifest describes a plugin. It's read from the plugin.json file at the root of the plugin's bundle.
+type Manifest struct {
+	Id          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Version     string           `json:"version"`
+	Backend     *ManifestBackend `json:"backend,omitempty"`
+}
+
+// ManifestBackend is the server side part of a plugin. Executable is the path of the program to run, relative to the
+// plugin's directory.
+type ManifestBackend struct {
+	Executable string `json:"executable"`
+}
+
+// PluginInfo is an installed plugin, whether it's enabled in the config and whether it's running.
+type PluginInfo struct {
+	Manifest
+	Enabled bool `json:"enabled"`
+	Active  bool `json:"active"`
+}
+
+func (o *Manifest) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ManifestFromJson(data io.Reader) *Manifest {
+	decoder := json.NewDecoder(data)
+	var o Manifest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func PluginInfoListToJson(l []*PluginInfo) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PluginInfoListFromJson(data io.Reader) []*PluginInfo {
+	decoder := json.NewDecoder(data)
+	var o []*PluginInfo
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func IsValidPluginId(id string) bool {
+	if len(id) < PLUGIN_ID_MIN_LENGTH || len(id) > PLUGIN_ID_MAX_LENGTH {
+		return false
+	}
+
+	// The id is used as the name of the plugin's directory, so it can't be allowed to point anywhere else
+	if id == "." || id == ".." || strings.HasPrefix(id, ".") {
+		return false
+	}
+
+	return validPluginId.MatchString(id)
+}
+
+func (o *Manifest) IsValid() *AppError {
+	if !IsValidPluginId(o.Id) {
+		return NewLocAppError("Manifest.IsValid", "model.manifest.is_valid.id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Name) == 0 || len(o.Name) > 64 {
+		return NewLocAppError("Manifest.IsValid", "model.manifest.is_valid.name.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Description) > 1024 {
+		return NewLocAppError("Manifest.IsValid", "model.manifest.is_valid.description.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Version) > 64 {
+		return NewLocAppError("Manifest.IsValid", "model.manifest.is_valid.version.app_error", nil, "id="+o.Id)
+	}
+
+	if o.Backend != nil {
+		executable := filepath.Clean(filepath.FromSlash(o.Backend.Executable))
+		if len(o.Backend.Executable) == 0 || filepath.IsAbs(executable) || executable == ".." || strings.HasPrefix(executable, ".."+string(filepath.Separator)) {
+			return NewLocAppError("Manifest.IsValid", "model.manifest.is_valid.executable.app_error", nil, "id="+o.Id)
+		}
+	}
+
+	return nil
+}
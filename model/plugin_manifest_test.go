@@ -0,0 +1,56 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestManifestJson(t *testing.T) {
+	o := Manifest{Id: "com.example.plugin", Name: "Plugin", Backend: &ManifestBackend{Executable: "plugin"}}
+	ro := ManifestFromJson(strings.NewReader(o.ToJson()))
+
+	if o.Id != ro.Id || ro.Backend == nil || ro.Backend.Executable != o.Backend.Executable {
+		t.Fatal("manifests don't match")
+	}
+
+	l := PluginInfoListFromJson(strings.NewReader(PluginInfoListToJson([]*PluginInfo{{Manifest: o, Enabled: true}})))
+	if len(l) != 1 || l[0].Id != o.Id || !l[0].Enabled || l[0].Active {
+		t.Fatal("plugin lists don't match")
+	}
+}
+
+func TestManifestIsValid(t *testing.T) {
+	o := Manifest{Id: "com.example.plugin", Name: "Plugin", Version: "0.1.0"}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"", "ab", "..", ".hidden", "has/slash", "has space", strings.Repeat("a", PLUGIN_ID_MAX_LENGTH+1)} {
+		o.Id = id
+		if err := o.IsValid(); err == nil {
+			t.Fatal("should be invalid", id)
+		}
+	}
+	o.Id = "com.example.plugin"
+
+	o.Name = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+	o.Name = "Plugin"
+
+	o.Backend = &ManifestBackend{Executable: "server/plugin"}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, executable := range []string{"", "/usr/bin/plugin", "../plugin", "server/../../plugin"} {
+		o.Backend.Executable = executable
+		if err := o.IsValid(); err == nil {
+			t.Fatal("should be invalid", executable)
+		}
+	}
+}
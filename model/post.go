@@ -20,11 +20,18 @@ const (
 	POST_DISPLAYNAME_CHANGE    = "system_displayname_change"
 	POST_CHANNEL_DELETED       = "system_channel_deleted"
 	POST_EPHEMERAL             = "system_ephemeral"
+	POST_PINNED                = "system_post_pinned"
 	POST_FILEIDS_MAX_RUNES     = 150
 	POST_FILENAMES_MAX_RUNES   = 4000
 	POST_HASHTAGS_MAX_RUNES    = 1000
 	POST_MESSAGE_MAX_RUNES     = 4000
 	POST_PROPS_MAX_RUNES       = 8000
+
+	// Join/leave and add/remove messages record which user's membership changed so that the change can be exported
+	POST_PROPS_MEMBER_EVENT   = "member_event"
+	POST_PROPS_MEMBER_USER_ID = "member_user_id"
+	POST_MEMBER_EVENT_JOIN    = "join"
+	POST_MEMBER_EVENT_LEAVE   = "leave"
 )
 
 type Post struct {
@@ -46,6 +53,7 @@ type Post struct {
 	FileIds       StringArray     `json:"file_ids,omitempty"`
 	PendingPostId string          `json:"pending_post_id" db:"-"`
 	HasReactions  bool            `json:"has_reactions,omitempty"`
+	IsPinned      bool            `json:"is_pinned"`
 }
 
 func (o *Post) ToJson() string {
@@ -121,7 +129,7 @@ func (o *Post) IsValid() *AppError {
 	// should be removed once more message types are supported
 	if !(o.Type == POST_DEFAULT || o.Type == POST_JOIN_LEAVE || o.Type == POST_ADD_REMOVE ||
 		o.Type == POST_SLACK_ATTACHMENT || o.Type == POST_HEADER_CHANGE ||
-		o.Type == POST_DISPLAYNAME_CHANGE || o.Type == POST_CHANNEL_DELETED) {
+		o.Type == POST_DISPLAYNAME_CHANGE || o.Type == POST_CHANNEL_DELETED || o.Type == POST_PINNED) {
 		return NewLocAppError("Post.IsValid", "model.post.is_valid.type.app_error", nil, "id="+o.Type)
 	}
 
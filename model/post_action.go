@@ -0,0 +1,185 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+const (
+	POST_ACTION_TYPE_BUTTON = "button"
+	POST_ACTION_TYPE_SELECT = "select"
+
+	POST_ACTION_CONTEXT_SELECTED_OPTION = "selected_option"
+)
+
+// PostAction is a button or menu that's shown on a message attachment. Using it sends a request to the integration
+// that created the message.
+type PostAction struct {
+	Id          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Type        string                 `json:"type"`
+	Options     []*PostActionOptions   `json:"options,omitempty"`
+	Integration *PostActionIntegration `json:"integration,omitempty"`
+}
+
+type PostActionOptions struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+type PostActionIntegration struct {
+	URL     string          `json:"url"`
+	Context StringInterface `json:"context,omitempty"`
+}
+
+// PostActionIntegrationRequest is sent to an integration when a user uses one of its post actions.
+type PostActionIntegrationRequest struct {
+	UserId    string          `json:"user_id"`
+	TeamId    string          `json:"team_id"`
+	ChannelId string          `json:"channel_id"`
+	PostId    string          `json:"post_id"`
+	Type      string          `json:"type"`
+	Context   StringInterface `json:"context,omitempty"`
+}
+
+// PostActionIntegrationResponse is what an integration can reply with to change the post that the action was on or
+// to send a message that's only shown to the user who used the action.
+type PostActionIntegrationResponse struct {
+	Update        *Post  `json:"update"`
+	EphemeralText string `json:"ephemeral_text"`
+}
+
+func (o *PostActionIntegrationRequest) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostActionIntegrationRequestFromJson(data io.Reader) *PostActionIntegrationRequest {
+	decoder := json.NewDecoder(data)
+	var o PostActionIntegrationRequest
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *PostActionIntegrationResponse) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func PostActionIntegrationResponseFromJson(data io.Reader) *PostActionIntegrationResponse {
+	decoder := json.NewDecoder(data)
+	var o PostActionIntegrationResponse
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+// SignPostActionRequest returns the value of the signature header that's sent with a post action request. Integrations
+// can compute the HMAC-SHA256 of the request body using the server's post action signing secret as the key to check
+// that the request came from this server.
+func SignPostActionRequest(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateActionIds gives an id to each action in the post's attachments that doesn't have one so that it can be
+// referred to when it's used.
+func (o *Post) GenerateActionIds() {
+	list, ok := o.Props["attachments"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for _, aInt := range list {
+		attachment, ok := aInt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		actions, ok := attachment["actions"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, actionInt := range actions {
+			if action, ok := actionInt.(map[string]interface{}); ok {
+				if id, _ := action["id"].(string); len(id) == 0 {
+					action["id"] = NewId()
+				}
+			}
+		}
+	}
+}
+
+// GetAction returns the action with the given id from the post's attachments or nil if there isn't one.
+func (o *Post) GetAction(id string) *PostAction {
+	list, ok := o.Props["attachments"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, aInt := range list {
+		attachment, ok := aInt.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		actions, ok := attachment["actions"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, actionInt := range actions {
+			if action, ok := actionInt.(map[string]interface{}); ok && action["id"] == id {
+				// Round trip through JSON since attachments are stored as generic maps
+				var postAction *PostAction
+				if b, err := json.Marshal(action); err != nil {
+					return nil
+				} else if err := json.Unmarshal(b, &postAction); err != nil {
+					return nil
+				}
+
+				return postAction
+			}
+		}
+	}
+
+	return nil
+}
+
+// StripActions removes the actions from the post's attachments. Actions make the server send requests when they're
+// used, so only posts made by integrations are allowed to have them.
+func (o *Post) StripActions() {
+	list, ok := o.Props["attachments"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for _, aInt := range list {
+		if attachment, ok := aInt.(map[string]interface{}); ok {
+			delete(attachment, "actions")
+		}
+	}
+}
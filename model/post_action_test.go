@@ -0,0 +1,128 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPostActionIntegrationRequestJson(t *testing.T) {
+	o := PostActionIntegrationRequest{UserId: NewId(), PostId: NewId(), Context: StringInterface{"a": "b"}}
+	ro := PostActionIntegrationRequestFromJson(strings.NewReader(o.ToJson()))
+
+	if ro.UserId != o.UserId || ro.PostId != o.PostId || ro.Context["a"] != "b" {
+		t.Fatal("requests don't match")
+	}
+}
+
+func TestPostActionIntegrationResponseJson(t *testing.T) {
+	o := PostActionIntegrationResponse{Update: &Post{Message: "updated"}, EphemeralText: "hello"}
+	ro := PostActionIntegrationResponseFromJson(strings.NewReader(o.ToJson()))
+
+	if ro.Update == nil || ro.Update.Message != "updated" || ro.EphemeralText != "hello" {
+		t.Fatal("responses don't match")
+	}
+
+	if ro := PostActionIntegrationResponseFromJson(strings.NewReader("")); ro != nil {
+		t.Fatal("should've failed to parse an empty response")
+	}
+}
+
+func TestPostGenerateActionIds(t *testing.T) {
+	post := PostFromJson(strings.NewReader(`{
+		"props": {
+			"attachments": [
+				{"text": "no actions"},
+				{
+					"actions": [
+						{"id": "existing", "name": "Existing", "integration": {"url": "http://localhost/action"}},
+						{"name": "New", "type": "select", "options": [{"text": "One", "value": "1"}], "integration": {"url": "http://localhost/action", "context": {"a": "b"}}}
+					]
+				}
+			]
+		}
+	}`))
+
+	post.GenerateActionIds()
+
+	actions := post.Props["attachments"].([]interface{})[1].(map[string]interface{})["actions"].([]interface{})
+	if id := actions[0].(map[string]interface{})["id"]; id != "existing" {
+		t.Fatal("shouldn't have changed an existing id")
+	}
+
+	id, _ := actions[1].(map[string]interface{})["id"].(string)
+	if len(id) != 26 {
+		t.Fatal("should've generated an id")
+	}
+
+	if action := post.GetAction("existing"); action == nil || action.Name != "Existing" || action.Integration.URL != "http://localhost/action" {
+		t.Fatal("should've found the existing action")
+	}
+
+	if action := post.GetAction(id); action == nil {
+		t.Fatal("should've found the new action")
+	} else if action.Type != POST_ACTION_TYPE_SELECT || len(action.Options) != 1 || action.Options[0].Value != "1" {
+		t.Fatal("should've read the action's options")
+	} else if action.Integration.Context["a"] != "b" {
+		t.Fatal("should've read the action's context")
+	}
+
+	if action := post.GetAction("junk"); action != nil {
+		t.Fatal("shouldn't have found a missing action")
+	}
+
+	// posts without attachments are left alone
+	post = &Post{Message: "test"}
+	post.GenerateActionIds()
+	if post.GetAction("existing") != nil {
+		t.Fatal("shouldn't have found an action")
+	}
+}
+
+func TestSignPostActionRequest(t *testing.T) {
+	if SignPostActionRequest("token", []byte("text=hello")) != SignOutgoingWebhookPayload("token", []byte("text=hello")) {
+		t.Fatal("signatures should use the same scheme as outgoing webhooks")
+	}
+
+	if SignPostActionRequest("secret", []byte("body")) == SignPostActionRequest("other", []byte("body")) {
+		t.Fatal("signatures should depend on the secret")
+	}
+}
+
+func TestPostStripActions(t *testing.T) {
+	post := &Post{
+		Props: StringInterface{
+			"attachments": []interface{}{
+				map[string]interface{}{
+					"text": "attachment",
+					"actions": []interface{}{
+						map[string]interface{}{
+							"id":   "existing",
+							"name": "Existing",
+							"integration": map[string]interface{}{
+								"url": "http://localhost/action",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	post.StripActions()
+
+	if post.GetAction("existing") != nil {
+		t.Fatal("should've removed the action")
+	}
+
+	attachment := post.Props["attachments"].([]interface{})[0].(map[string]interface{})
+	if attachment["text"] != "attachment" {
+		t.Fatal("should've kept the rest of the attachment")
+	}
+
+	// posts without attachments are left alone
+	post = &Post{Message: "test"}
+	post.StripActions()
+}
@@ -11,6 +11,9 @@ import (
 type PostList struct {
 	Order []string         `json:"order"`
 	Posts map[string]*Post `json:"posts"`
+
+	// Snippets contains highlighted excerpts of posts keyed by post id. It is only set for search results.
+	Snippets map[string]string `json:"snippets,omitempty"`
 }
 
 func (o *PostList) ToJson() string {
@@ -54,11 +57,24 @@ func (o *PostList) AddPost(post *Post) {
 	o.Posts[post.Id] = post
 }
 
+func (o *PostList) AddSnippet(postId string, snippet string) {
+
+	if o.Snippets == nil {
+		o.Snippets = make(map[string]string)
+	}
+
+	o.Snippets[postId] = snippet
+}
+
 func (o *PostList) Extend(other *PostList) {
 	for _, postId := range other.Order {
 		if _, ok := o.Posts[postId]; !ok {
 			o.AddPost(other.Posts[postId])
 			o.AddOrder(postId)
+
+			if snippet, ok := other.Snippets[postId]; ok {
+				o.AddSnippet(postId, snippet)
+			}
 		}
 	}
 }
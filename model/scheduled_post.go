@@ -0,0 +1,178 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"unicode/utf8"
+)
+
+const (
+	SCHEDULED_POST_ERROR_MESSAGE_MAX_LENGTH = 1024
+)
+
+// ScheduledPost is a post that's saved by a user to be published automatically at a later time.
+type ScheduledPost struct {
+	Id           string          `json:"id"`
+	CreateAt     int64           `json:"create_at"`
+	UpdateAt     int64           `json:"update_at"`
+	UserId       string          `json:"user_id"`
+	TeamId       string          `json:"team_id"`
+	ChannelId    string          `json:"channel_id"`
+	RootId       string          `json:"root_id"`
+	Message      string          `json:"message"`
+	Props        StringInterface `json:"props"`
+	FileIds      StringArray     `json:"file_ids,omitempty"`
+	ScheduledAt  int64           `json:"scheduled_at"`
+	ProcessedAt  int64           `json:"processed_at"`
+	ErrorMessage string          `json:"error_message"`
+}
+
+func (o *ScheduledPost) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ScheduledPostFromJson(data io.Reader) *ScheduledPost {
+	decoder := json.NewDecoder(data)
+	var o ScheduledPost
+	err := decoder.Decode(&o)
+	if err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func ScheduledPostsToJson(posts []*ScheduledPost) string {
+	b, err := json.Marshal(posts)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ScheduledPostsFromJson(data io.Reader) []*ScheduledPost {
+	decoder := json.NewDecoder(data)
+	var o []*ScheduledPost
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
+func (o *ScheduledPost) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.Props == nil {
+		o.Props = make(map[string]interface{})
+	}
+
+	if o.FileIds == nil {
+		o.FileIds = []string{}
+	}
+
+	o.CreateAt = GetMillis()
+	o.UpdateAt = o.CreateAt
+	o.ProcessedAt = 0
+	o.ErrorMessage = ""
+}
+
+func (o *ScheduledPost) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+func (o *ScheduledPost) IsValid() *AppError {
+	if len(o.Id) != 26 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.id.app_error", nil, "")
+	}
+
+	if o.CreateAt == 0 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.create_at.app_error", nil, "id="+o.Id)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.update_at.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.UserId) != 26 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.user_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.TeamId) != 26 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.team_id.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.channel_id.app_error", nil, "id="+o.Id)
+	}
+
+	if !(len(o.RootId) == 26 || len(o.RootId) == 0) {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.root_id.app_error", nil, "id="+o.Id)
+	}
+
+	if utf8.RuneCountInString(o.Message) > POST_MESSAGE_MAX_RUNES {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.msg.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.Message) == 0 && len(o.FileIds) == 0 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.empty.app_error", nil, "id="+o.Id)
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.FileIds)) > POST_FILEIDS_MAX_RUNES {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.file_ids.app_error", nil, "id="+o.Id)
+	}
+
+	if utf8.RuneCountInString(StringInterfaceToJson(o.Props)) > POST_PROPS_MAX_RUNES {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.props.app_error", nil, "id="+o.Id)
+	}
+
+	if o.ScheduledAt == 0 {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.scheduled_at.app_error", nil, "id="+o.Id)
+	}
+
+	if len(o.ErrorMessage) > SCHEDULED_POST_ERROR_MESSAGE_MAX_LENGTH {
+		return NewLocAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.error_message.app_error", nil, "id="+o.Id)
+	}
+
+	return nil
+}
+
+// IsPending returns true if the post is still waiting to be published.
+func (o *ScheduledPost) IsPending() bool {
+	return o.ProcessedAt == 0
+}
+
+// HasFailed returns true if the post was due to be published but couldn't be. A failed post can be rescheduled.
+func (o *ScheduledPost) HasFailed() bool {
+	return o.ProcessedAt != 0 && o.ErrorMessage != ""
+}
+
+// ToPost creates the post that will be published for this scheduled post.
+func (o *ScheduledPost) ToPost() *Post {
+	post := &Post{
+		UserId:    o.UserId,
+		ChannelId: o.ChannelId,
+		RootId:    o.RootId,
+		Message:   o.Message,
+		FileIds:   o.FileIds,
+	}
+
+	post.Props = make(StringInterface)
+	for key, value := range o.Props {
+		post.Props[key] = value
+	}
+
+	return post
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduledPostJson(t *testing.T) {
+	o := ScheduledPost{Id: NewId(), Message: NewId(), ScheduledAt: GetMillis()}
+	json := o.ToJson()
+	ro := ScheduledPostFromJson(strings.NewReader(json))
+
+	if o.Id != ro.Id || o.Message != ro.Message || o.ScheduledAt != ro.ScheduledAt {
+		t.Fatal("Ids do not match")
+	}
+
+	posts := ScheduledPostsFromJson(strings.NewReader(ScheduledPostsToJson([]*ScheduledPost{&o})))
+	if len(posts) != 1 || posts[0].Id != o.Id {
+		t.Fatal("Ids do not match")
+	}
+}
+
+func TestScheduledPostIsValid(t *testing.T) {
+	o := ScheduledPost{}
+
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.UserId = NewId()
+	o.TeamId = NewId()
+	o.ChannelId = NewId()
+	o.Message = "message"
+	o.ScheduledAt = GetMillis() + 1000
+	o.PreSave()
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.RootId = "junk"
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.RootId = ""
+	o.Message = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should need a message or files")
+	}
+
+	o.FileIds = StringArray{NewId()}
+	if err := o.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	o.Message = strings.Repeat("0", POST_MESSAGE_MAX_RUNES+1)
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.Message = "message"
+	o.ScheduledAt = 0
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	o.ScheduledAt = GetMillis()
+	o.TeamId = ""
+	if err := o.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+}
+
+func TestScheduledPostState(t *testing.T) {
+	o := ScheduledPost{}
+	if !o.IsPending() || o.HasFailed() {
+		t.Fatal("should be pending")
+	}
+
+	o.ProcessedAt = GetMillis()
+	if o.IsPending() || o.HasFailed() {
+		t.Fatal("should be being published")
+	}
+
+	o.ErrorMessage = "error"
+	if o.IsPending() || !o.HasFailed() {
+		t.Fatal("should have failed")
+	}
+}
+
+func TestScheduledPostToPost(t *testing.T) {
+	o := ScheduledPost{
+		UserId:    NewId(),
+		ChannelId: NewId(),
+		RootId:    NewId(),
+		Message:   "message",
+		Props:     StringInterface{"from_webhook": "true"},
+		FileIds:   StringArray{NewId()},
+	}
+
+	post := o.ToPost()
+	if post.UserId != o.UserId || post.ChannelId != o.ChannelId || post.RootId != o.RootId || post.Message != o.Message {
+		t.Fatal("post should match the scheduled post")
+	} else if post.Props["from_webhook"] != "true" || len(post.FileIds) != 1 {
+		t.Fatal("post should have the scheduled post's props and files")
+	}
+
+	post.Props["other"] = "value"
+	if _, ok := o.Props["other"]; ok {
+		t.Fatal("shouldn't share props with the scheduled post")
+	}
+}
@@ -4,22 +4,167 @@
 package model
 
 import (
+	"html"
 	"regexp"
 	"strings"
+	"time"
+)
+
+const (
+	SEARCH_DATE_FORMAT = "2006-01-02"
+
+	SEARCH_HIGHLIGHT_START = "<mark>"
+	SEARCH_HIGHLIGHT_END   = "</mark>"
+	SEARCH_SNIPPET_WORDS   = 30
+	SEARCH_SNIPPET_CONTEXT = 10
 )
 
 var searchTermPuncStart = regexp.MustCompile(`^[^\pL\d\s#"]+`)
 var searchTermPuncEnd = regexp.MustCompile(`[^\pL\d\s*"]+$`)
 
+var searchSnippetWord = regexp.MustCompile(`#?[\pL\d_]+(?:['.\-][\pL\d_]+)*`)
+
 type SearchParams struct {
 	Terms      string
 	IsHashtag  bool
 	InChannels []string
 	FromUsers  []string
+	AfterDate  string
+	BeforeDate string
+	OnDate     string
+	Extensions []string
 	OrTerms    bool
 }
 
-var searchFlags = [...]string{"from", "channel", "in"}
+var searchFlags = [...]string{"from", "channel", "in", "before", "after", "on", "ext"}
+
+// SplitTerms returns the individual words and quoted phrases that make up the search terms. Phrases keep their
+// surrounding quotes so that they can be distinguished from single words.
+func (p *SearchParams) SplitTerms() []string {
+	return splitWords(p.Terms)
+}
+
+// HasDateFilter returns true if the search is limited to posts made before, after, or on a given date.
+func (p *SearchParams) HasDateFilter() bool {
+	return p.AfterDate != "" || p.BeforeDate != "" || p.OnDate != ""
+}
+
+// GetAfterDateMillis returns the start of the day following AfterDate in UTC, or 0 if AfterDate isn't a valid date.
+func (p *SearchParams) GetAfterDateMillis() int64 {
+	if date, err := time.Parse(SEARCH_DATE_FORMAT, p.AfterDate); err != nil {
+		return 0
+	} else {
+		return GetMillisForTime(date.AddDate(0, 0, 1))
+	}
+}
+
+// GetBeforeDateMillis returns the start of the day of BeforeDate in UTC, or 0 if BeforeDate isn't a valid date.
+func (p *SearchParams) GetBeforeDateMillis() int64 {
+	if date, err := time.Parse(SEARCH_DATE_FORMAT, p.BeforeDate); err != nil {
+		return 0
+	} else {
+		return GetMillisForTime(date)
+	}
+}
+
+// GetOnDateMillis returns the start and end of the day of OnDate in UTC, or 0 for both if OnDate isn't a valid date.
+func (p *SearchParams) GetOnDateMillis() (int64, int64) {
+	if date, err := time.Parse(SEARCH_DATE_FORMAT, p.OnDate); err != nil {
+		return 0, 0
+	} else {
+		return GetMillisForTime(date), GetMillisForTime(date.AddDate(0, 0, 1)) - 1
+	}
+}
+
+// searchTermMatchers returns the lower case words that should be highlighted in a search result along with whether
+// or not each one should also match longer words that start with it.
+func (p *SearchParams) searchTermMatchers() map[string]bool {
+	matchers := map[string]bool{}
+
+	for _, term := range p.SplitTerms() {
+		for _, word := range searchSnippetWord.FindAllString(strings.ToLower(term), -1) {
+			if !p.IsHashtag {
+				word = strings.TrimPrefix(word, "#")
+			}
+
+			// prefix matching also catches simple plurals and other suffixes that the database's stemming ignores
+			matchers[word] = !p.IsHashtag
+		}
+	}
+
+	return matchers
+}
+
+// Snippet returns an HTML-escaped excerpt of the given message around the first word matching the search terms with
+// every matching word wrapped in SEARCH_HIGHLIGHT_START and SEARCH_HIGHLIGHT_END.
+func (p *SearchParams) Snippet(message string) string {
+	matchers := p.searchTermMatchers()
+	locations := searchSnippetWord.FindAllStringIndex(message, -1)
+
+	isMatch := func(word string) bool {
+		word = strings.ToLower(word)
+		if !p.IsHashtag {
+			word = strings.TrimPrefix(word, "#")
+		}
+
+		for term, prefix := range matchers {
+			if word == term || (prefix && strings.HasPrefix(word, term)) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	first := -1
+	for i, location := range locations {
+		if isMatch(message[location[0]:location[1]]) {
+			first = i
+			break
+		}
+	}
+
+	if first == -1 {
+		return ""
+	}
+
+	start := first - SEARCH_SNIPPET_CONTEXT
+	if start < 0 {
+		start = 0
+	}
+
+	end := start + SEARCH_SNIPPET_WORDS
+	if end > len(locations) {
+		end = len(locations)
+	}
+
+	snippet := ""
+	if start > 0 {
+		snippet += "..."
+	}
+
+	position := locations[start][0]
+	for _, location := range locations[start:end] {
+		snippet += html.EscapeString(message[position:location[0]])
+
+		word := message[location[0]:location[1]]
+		if isMatch(word) {
+			snippet += SEARCH_HIGHLIGHT_START + html.EscapeString(word) + SEARCH_HIGHLIGHT_END
+		} else {
+			snippet += html.EscapeString(word)
+		}
+
+		position = location[1]
+	}
+
+	if end < len(locations) {
+		snippet += "..."
+	} else {
+		snippet += html.EscapeString(message[position:])
+	}
+
+	return snippet
+}
 
 func splitWordsNoQuotes(text string) []string {
 	words := []string{}
@@ -129,6 +274,10 @@ func ParseSearchParams(text string) []*SearchParams {
 
 	inChannels := []string{}
 	fromUsers := []string{}
+	afterDate := ""
+	beforeDate := ""
+	onDate := ""
+	extensions := []string{}
 
 	for _, flagPair := range flags {
 		flag := flagPair[0]
@@ -138,6 +287,14 @@ func ParseSearchParams(text string) []*SearchParams {
 			inChannels = append(inChannels, value)
 		} else if flag == "from" {
 			fromUsers = append(fromUsers, value)
+		} else if flag == "after" {
+			afterDate = value
+		} else if flag == "before" {
+			beforeDate = value
+		} else if flag == "on" {
+			onDate = value
+		} else if flag == "ext" {
+			extensions = append(extensions, strings.ToLower(strings.TrimPrefix(value, ".")))
 		}
 	}
 
@@ -149,6 +306,10 @@ func ParseSearchParams(text string) []*SearchParams {
 			IsHashtag:  false,
 			InChannels: inChannels,
 			FromUsers:  fromUsers,
+			AfterDate:  afterDate,
+			BeforeDate: beforeDate,
+			OnDate:     onDate,
+			Extensions: extensions,
 		})
 	}
 
@@ -158,16 +319,24 @@ func ParseSearchParams(text string) []*SearchParams {
 			IsHashtag:  true,
 			InChannels: inChannels,
 			FromUsers:  fromUsers,
+			AfterDate:  afterDate,
+			BeforeDate: beforeDate,
+			OnDate:     onDate,
+			Extensions: extensions,
 		})
 	}
 
 	// special case for when no terms are specified but we still have a filter
-	if len(plainTerms) == 0 && len(hashtagTerms) == 0 && (len(inChannels) != 0 || len(fromUsers) != 0) {
+	if len(plainTerms) == 0 && len(hashtagTerms) == 0 && (len(inChannels) != 0 || len(fromUsers) != 0 || afterDate != "" || beforeDate != "" || onDate != "" || len(extensions) != 0) {
 		paramsList = append(paramsList, &SearchParams{
 			Terms:      "",
 			IsHashtag:  true,
 			InChannels: inChannels,
 			FromUsers:  fromUsers,
+			AfterDate:  afterDate,
+			BeforeDate: beforeDate,
+			OnDate:     onDate,
+			Extensions: extensions,
 		})
 	}
 
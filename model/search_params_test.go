@@ -4,7 +4,9 @@
 package model
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSplitWords(t *testing.T) {
@@ -221,3 +223,67 @@ func TestParseSearchParams(t *testing.T) {
 		t.Fatalf("Incorrect output from parse search params: %v", sp[0])
 	}
 }
+
+func TestParseSearchParamsDates(t *testing.T) {
+	if sp := ParseSearchParams("testing after:2017-01-02 before:2017-02-03"); len(sp) != 1 || sp[0].Terms != "testing" || sp[0].AfterDate != "2017-01-02" || sp[0].BeforeDate != "2017-02-03" || sp[0].OnDate != "" {
+		t.Fatal("didn't parse date flags correctly")
+	}
+
+	if sp := ParseSearchParams("on:2017-01-02"); len(sp) != 1 || sp[0].Terms != "" || sp[0].OnDate != "2017-01-02" || !sp[0].HasDateFilter() {
+		t.Fatal("should search for posts on a date without any terms")
+	}
+
+	sp := &SearchParams{AfterDate: "2017-01-02", BeforeDate: "2017-01-02", OnDate: "2017-01-02"}
+	day := time.Date(2017, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	if sp.GetBeforeDateMillis() != GetMillisForTime(day) {
+		t.Fatal("before should be the start of the day")
+	}
+
+	if sp.GetAfterDateMillis() != GetMillisForTime(day.AddDate(0, 0, 1)) {
+		t.Fatal("after should be the start of the next day")
+	}
+
+	if start, end := sp.GetOnDateMillis(); start != GetMillisForTime(day) || end != GetMillisForTime(day.AddDate(0, 0, 1))-1 {
+		t.Fatal("on should cover the whole day")
+	}
+
+	invalid := &SearchParams{AfterDate: "yesterday", BeforeDate: "2017-13-45"}
+	if invalid.GetAfterDateMillis() != 0 || invalid.GetBeforeDateMillis() != 0 {
+		t.Fatal("invalid dates should be ignored")
+	}
+}
+
+func TestParseSearchParamsExtensions(t *testing.T) {
+	if sp := ParseSearchParams("report ext:pdf ext:.DOCX"); len(sp) != 1 || sp[0].Terms != "report" || len(sp[0].Extensions) != 2 || sp[0].Extensions[0] != "pdf" || sp[0].Extensions[1] != "docx" {
+		t.Fatal("didn't parse extension flags correctly")
+	}
+
+	if sp := ParseSearchParams("ext:txt"); len(sp) != 1 || sp[0].Terms != "" || len(sp[0].Extensions) != 1 {
+		t.Fatal("should search for files with an extension without any terms")
+	}
+}
+
+func TestSearchParamsSnippet(t *testing.T) {
+	sp := &SearchParams{Terms: "\"new york\" corey"}
+
+	if snippet := sp.Snippet("Corey moved to New York's <b>best</b> neighbourhood"); snippet != "<mark>Corey</mark> moved to <mark>New</mark> <mark>York&#39;s</mark> &lt;b&gt;best&lt;/b&gt; neighbourhood" {
+		t.Fatal("incorrect snippet " + snippet)
+	}
+
+	if snippet := sp.Snippet("nothing to see here"); snippet != "" {
+		t.Fatal("shouldn't return a snippet without any matches")
+	}
+
+	sp = &SearchParams{Terms: "match*"}
+	message := strings.Repeat("word ", 20) + "matches " + strings.Repeat("word ", 30)
+	expected := "..." + strings.Repeat("word ", 10) + "<mark>matches</mark>" + strings.Repeat(" word", 19) + "..."
+	if snippet := sp.Snippet(message); snippet != expected {
+		t.Fatal("incorrect snippet " + snippet)
+	}
+
+	sp = &SearchParams{Terms: "#tag", IsHashtag: true}
+	if snippet := sp.Snippet("tag #tag #tagged"); snippet != "tag <mark>#tag</mark> #tagged" {
+		t.Fatal("incorrect snippet " + snippet)
+	}
+}
@@ -6,6 +6,7 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"strconv"
 	"strings"
 )
 
@@ -15,6 +16,15 @@ const (
 	SESSION_PROP_PLATFORM = "platform"
 	SESSION_PROP_OS       = "os"
 	SESSION_PROP_BROWSER  = "browser"
+
+	SESSION_PROP_OPENID_REFRESH_TOKEN = "openid_refresh_token"
+	SESSION_PROP_OPENID_REFRESHED_AT  = "openid_refreshed_at"
+
+	SESSION_OPENID_REFRESH_INTERVAL = 60 * 60 * 1000 // 1 hour
+
+	SESSION_PROP_TYPE                     = "type"
+	SESSION_PROP_PERSONAL_ACCESS_TOKEN_ID = "personal_access_token_id"
+	SESSION_TYPE_PERSONAL_ACCESS_TOKEN    = "PersonalAccessToken"
 )
 
 type Session struct {
@@ -68,6 +78,7 @@ func (me *Session) PreSave() {
 
 func (me *Session) Sanitize() {
 	me.Token = ""
+	delete(me.Props, SESSION_PROP_OPENID_REFRESH_TOKEN)
 }
 
 func (me *Session) IsExpired() bool {
@@ -100,6 +111,24 @@ func (me *Session) AddProp(key string, value string) {
 	me.Props[key] = value
 }
 
+// IsOpenIdRefreshDue returns true if the session was created by signing in with an OpenID Connect provider and the
+// provider hasn't confirmed that the user can still sign in for a while.
+func (me *Session) IsOpenIdRefreshDue() bool {
+	if len(me.Props[SESSION_PROP_OPENID_REFRESH_TOKEN]) == 0 {
+		return false
+	}
+
+	refreshedAt, _ := strconv.ParseInt(me.Props[SESSION_PROP_OPENID_REFRESHED_AT], 10, 64)
+
+	return GetMillis()-refreshedAt > SESSION_OPENID_REFRESH_INTERVAL
+}
+
+// IsPersonalAccessToken returns true if the session was created to authenticate requests made with a personal access
+// token rather than by the user signing in.
+func (me *Session) IsPersonalAccessToken() bool {
+	return me.Props[SESSION_PROP_TYPE] == SESSION_TYPE_PERSONAL_ACCESS_TOKEN
+}
+
 func (me *Session) GetTeamByTeamId(teamId string) *TeamMember {
 	for _, team := range me.TeamMembers {
 		if team.TeamId == teamId {
@@ -4,6 +4,7 @@
 package model
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -33,3 +34,39 @@ func TestSessionJson(t *testing.T) {
 
 	session.SetExpireInDays(10)
 }
+
+func TestSessionSanitizeOpenIdRefreshToken(t *testing.T) {
+	session := Session{}
+	session.PreSave()
+	session.AddProp(SESSION_PROP_OPENID_REFRESH_TOKEN, "token")
+
+	session.Sanitize()
+
+	if _, ok := session.Props[SESSION_PROP_OPENID_REFRESH_TOKEN]; ok {
+		t.Fatal("should've removed the refresh token")
+	}
+}
+
+func TestSessionIsOpenIdRefreshDue(t *testing.T) {
+	session := Session{}
+	session.PreSave()
+
+	if session.IsOpenIdRefreshDue() {
+		t.Fatal("shouldn't refresh a session without a refresh token")
+	}
+
+	session.AddProp(SESSION_PROP_OPENID_REFRESH_TOKEN, "token")
+	if !session.IsOpenIdRefreshDue() {
+		t.Fatal("should refresh a session that has never been refreshed")
+	}
+
+	session.AddProp(SESSION_PROP_OPENID_REFRESHED_AT, strconv.FormatInt(GetMillis(), 10))
+	if session.IsOpenIdRefreshDue() {
+		t.Fatal("shouldn't refresh a session that was just refreshed")
+	}
+
+	session.AddProp(SESSION_PROP_OPENID_REFRESHED_AT, strconv.FormatInt(GetMillis()-SESSION_OPENID_REFRESH_INTERVAL-1, 10))
+	if !session.IsOpenIdRefreshDue() {
+		t.Fatal("should refresh a session once the interval has passed")
+	}
+}
@@ -10,9 +10,30 @@ import (
 
 type SuggestCommand struct {
 	Suggestion  string `json:"suggestion"`
+	Hint        string `json:"hint"`
 	Description string `json:"description"`
 }
 
+func SuggestCommandListToJson(l []*SuggestCommand) string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func SuggestCommandListFromJson(data io.Reader) []*SuggestCommand {
+	decoder := json.NewDecoder(data)
+	var o []*SuggestCommand
+	err := decoder.Decode(&o)
+	if err == nil {
+		return o
+	} else {
+		return nil
+	}
+}
+
 func (o *SuggestCommand) ToJson() string {
 	b, err := json.Marshal(o)
 	if err != nil {
@@ -9,11 +9,13 @@ import (
 )
 
 const (
-	SYSTEM_DIAGNOSTIC_ID        = "DiagnosticId"
-	SYSTEM_RAN_UNIT_TESTS       = "RanUnitTests"
-	SYSTEM_LAST_SECURITY_TIME   = "LastSecurityTime"
-	SYSTEM_ACTIVE_LICENSE_ID    = "ActiveLicenseId"
-	SYSTEM_LAST_COMPLIANCE_TIME = "LastComplianceTime"
+	SYSTEM_DIAGNOSTIC_ID            = "DiagnosticId"
+	SYSTEM_RAN_UNIT_TESTS           = "RanUnitTests"
+	SYSTEM_LAST_SECURITY_TIME       = "LastSecurityTime"
+	SYSTEM_ACTIVE_LICENSE_ID        = "ActiveLicenseId"
+	SYSTEM_LAST_COMPLIANCE_TIME     = "LastComplianceTime"
+	SYSTEM_LAST_DATA_RETENTION_TIME = "LastDataRetentionTime"
+	SYSTEM_CLUSTER_SECRET           = "ClusterSecret"
 )
 
 type System struct {
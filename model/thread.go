@@ -0,0 +1,139 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	THREAD_PARTICIPANTS_MAX = 50
+)
+
+// Thread tracks the replies made to a root post so that conversations can be shown without loading the whole channel.
+type Thread struct {
+	PostId       string      `json:"id"`
+	ChannelId    string      `json:"channel_id"`
+	ReplyCount   int64       `json:"reply_count"`
+	LastReplyAt  int64       `json:"last_reply_at"`
+	Participants StringArray `json:"participants"`
+}
+
+// ThreadMembership tracks whether a user is following a thread and how much of it they've read.
+type ThreadMembership struct {
+	PostId      string `json:"post_id"`
+	UserId      string `json:"user_id"`
+	Following   bool   `json:"following"`
+	LastViewed  int64  `json:"last_viewed"`
+	LastUpdated int64  `json:"last_updated"`
+}
+
+// ThreadResponse is a thread along with the root post and the read state of the user that requested it.
+type ThreadResponse struct {
+	PostId        string      `json:"id"`
+	ChannelId     string      `json:"channel_id"`
+	ReplyCount    int64       `json:"reply_count"`
+	LastReplyAt   int64       `json:"last_reply_at"`
+	Participants  StringArray `json:"participants"`
+	Following     bool        `json:"following"`
+	LastViewedAt  int64       `json:"last_viewed_at"`
+	UnreadReplies int64       `json:"unread_replies"`
+	Post          *Post       `json:"post,omitempty" db:"-"`
+}
+
+type ThreadList struct {
+	Total              int64             `json:"total"`
+	TotalUnreadThreads int64             `json:"total_unread_threads"`
+	Threads            []*ThreadResponse `json:"threads"`
+}
+
+func (o *Thread) IsValid() *AppError {
+	if len(o.PostId) != 26 {
+		return NewLocAppError("Thread.IsValid", "model.thread.is_valid.post_id.app_error", nil, "")
+	}
+
+	if len(o.ChannelId) != 26 {
+		return NewLocAppError("Thread.IsValid", "model.thread.is_valid.channel_id.app_error", nil, "post_id="+o.PostId)
+	}
+
+	if o.ReplyCount < 0 {
+		return NewLocAppError("Thread.IsValid", "model.thread.is_valid.reply_count.app_error", nil, "post_id="+o.PostId)
+	}
+
+	return nil
+}
+
+func (o *ThreadMembership) PreSave() {
+	o.LastUpdated = GetMillis()
+}
+
+func (o *ThreadMembership) IsValid() *AppError {
+	if len(o.PostId) != 26 {
+		return NewLocAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.post_id.app_error", nil, "")
+	}
+
+	if len(o.UserId) != 26 {
+		return NewLocAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.user_id.app_error", nil, "post_id="+o.PostId)
+	}
+
+	return nil
+}
+
+func (o *ThreadMembership) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ThreadMembershipFromJson(data io.Reader) *ThreadMembership {
+	decoder := json.NewDecoder(data)
+	var o ThreadMembership
+	if err := decoder.Decode(&o); err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *ThreadResponse) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ThreadResponseFromJson(data io.Reader) *ThreadResponse {
+	decoder := json.NewDecoder(data)
+	var o ThreadResponse
+	if err := decoder.Decode(&o); err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
+
+func (o *ThreadList) ToJson() string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func ThreadListFromJson(data io.Reader) *ThreadList {
+	decoder := json.NewDecoder(data)
+	var o ThreadList
+	if err := decoder.Decode(&o); err == nil {
+		return &o
+	} else {
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThreadIsValid(t *testing.T) {
+	thread := Thread{
+		PostId:    "1234garbage",
+		ChannelId: NewId(),
+	}
+
+	if err := thread.IsValid(); err == nil {
+		t.Fatal()
+	}
+
+	thread.PostId = NewId()
+	if err := thread.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	thread.ChannelId = ""
+	if err := thread.IsValid(); err == nil {
+		t.Fatal()
+	}
+
+	thread.ChannelId = NewId()
+	thread.ReplyCount = -1
+	if err := thread.IsValid(); err == nil {
+		t.Fatal()
+	}
+}
+
+func TestThreadMembershipIsValid(t *testing.T) {
+	membership := ThreadMembership{
+		PostId: NewId(),
+		UserId: "1234garbage",
+	}
+
+	if err := membership.IsValid(); err == nil {
+		t.Fatal()
+	}
+
+	membership.UserId = NewId()
+	if err := membership.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	membership.PostId = ""
+	if err := membership.IsValid(); err == nil {
+		t.Fatal()
+	}
+}
+
+func TestThreadListJson(t *testing.T) {
+	list := &ThreadList{
+		Total: 1,
+		Threads: []*ThreadResponse{
+			{
+				PostId:        NewId(),
+				ChannelId:     NewId(),
+				ReplyCount:    3,
+				Participants:  StringArray{NewId()},
+				UnreadReplies: 2,
+				Post:          &Post{Id: NewId()},
+			},
+		},
+	}
+
+	result := ThreadListFromJson(strings.NewReader(list.ToJson()))
+	if result.Total != 1 || len(result.Threads) != 1 {
+		t.Fatal("list should have been the same")
+	} else if thread := result.Threads[0]; thread.PostId != list.Threads[0].PostId || thread.UnreadReplies != 2 || thread.Post.Id != list.Threads[0].Post.Id {
+		t.Fatal("thread should have been the same")
+	}
+}
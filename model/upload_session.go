@@ -0,0 +1,138 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+const (
+	UPLOAD_SESSION_EXPIRY_MILLIS = 24 * 60 * 60 * 1000 // 24 hours
+)
+
+// UploadSession tracks a file that's being uploaded in chunks so that the client can resume the upload if it's
+// interrupted instead of having to start over.
+type UploadSession struct {
+	Id         string `json:"id"`
+	CreateAt   int64  `json:"create_at"`
+	UpdateAt   int64  `json:"update_at"`
+	UserId     string `json:"user_id"`
+	TeamId     string `json:"team_id"`
+	ChannelId  string `json:"channel_id"`
+	Filename   string `json:"filename"`
+	Path       string `json:"-"` // not sent back to the client
+	FileSize   int64  `json:"file_size"`
+	FileOffset int64  `json:"file_offset"`
+	ClaimedAt  int64  `json:"-"` // set while a request is putting the chunks back together
+}
+
+func (us *UploadSession) ToJson() string {
+	b, err := json.Marshal(us)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UploadSessionFromJson(data io.Reader) *UploadSession {
+	decoder := json.NewDecoder(data)
+	var us UploadSession
+	err := decoder.Decode(&us)
+	if err == nil {
+		return &us
+	} else {
+		return nil
+	}
+}
+
+func UploadSessionsToJson(sessions []*UploadSession) string {
+	b, err := json.Marshal(sessions)
+	if err != nil {
+		return ""
+	} else {
+		return string(b)
+	}
+}
+
+func UploadSessionsFromJson(data io.Reader) []*UploadSession {
+	decoder := json.NewDecoder(data)
+	var sessions []*UploadSession
+	err := decoder.Decode(&sessions)
+	if err == nil {
+		return sessions
+	} else {
+		return nil
+	}
+}
+
+func (us *UploadSession) PreSave() {
+	if us.Id == "" {
+		us.Id = NewId()
+	}
+
+	us.Filename = filepath.Base(us.Filename)
+
+	if us.Path == "" {
+		us.Path = "uploads/" + us.Id
+	}
+
+	us.CreateAt = GetMillis()
+	us.UpdateAt = us.CreateAt
+}
+
+func (us *UploadSession) PreUpdate() {
+	us.UpdateAt = GetMillis()
+}
+
+func (us *UploadSession) IsValid() *AppError {
+	if len(us.Id) != 26 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.id.app_error", nil, "")
+	}
+
+	if len(us.UserId) != 26 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.user_id.app_error", nil, "id="+us.Id)
+	}
+
+	if len(us.TeamId) != 26 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.team_id.app_error", nil, "id="+us.Id)
+	}
+
+	if len(us.ChannelId) != 26 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.channel_id.app_error", nil, "id="+us.Id)
+	}
+
+	if us.CreateAt == 0 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.create_at.app_error", nil, "id="+us.Id)
+	}
+
+	if us.UpdateAt == 0 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.update_at.app_error", nil, "id="+us.Id)
+	}
+
+	if len(us.Filename) == 0 || len(us.Filename) > 256 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.filename.app_error", nil, "id="+us.Id)
+	}
+
+	if len(us.Path) == 0 || len(us.Path) > 512 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.path.app_error", nil, "id="+us.Id)
+	}
+
+	if us.FileSize <= 0 {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.file_size.app_error", nil, "id="+us.Id)
+	}
+
+	if us.FileOffset < 0 || us.FileOffset > us.FileSize {
+		return NewLocAppError("UploadSession.IsValid", "model.upload_session.is_valid.file_offset.app_error", nil, "id="+us.Id)
+	}
+
+	return nil
+}
+
+// IsComplete returns true once every byte of the file has been received.
+func (us *UploadSession) IsComplete() bool {
+	return us.FileOffset == us.FileSize
+}
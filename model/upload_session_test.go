@@ -0,0 +1,89 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUploadSessionJson(t *testing.T) {
+	session := UploadSession{
+		Id:        NewId(),
+		UserId:    NewId(),
+		TeamId:    NewId(),
+		ChannelId: NewId(),
+		Filename:  "test.png",
+		Path:      "uploads/test",
+		FileSize:  1000,
+	}
+	json := session.ToJson()
+	rsession := UploadSessionFromJson(strings.NewReader(json))
+
+	if session.Id != rsession.Id {
+		t.Fatal("Ids do not match")
+	}
+
+	if rsession.Path != "" {
+		t.Fatal("path shouldn't be sent to the client")
+	}
+}
+
+func TestUploadSessionPreSave(t *testing.T) {
+	session := UploadSession{
+		Filename: "../../some/path/test.png",
+	}
+	session.PreSave()
+
+	if len(session.Id) != 26 {
+		t.Fatal("should've set an id")
+	} else if session.Filename != "test.png" {
+		t.Fatal("should've removed the path from the filename")
+	} else if session.Path != "uploads/"+session.Id {
+		t.Fatal("should've set the path")
+	} else if session.CreateAt == 0 || session.UpdateAt == 0 {
+		t.Fatal("should've set the timestamps")
+	}
+}
+
+func TestUploadSessionIsValid(t *testing.T) {
+	session := UploadSession{}
+
+	if err := session.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	session.Id = NewId()
+	if err := session.IsValid(); err == nil {
+		t.Fatal("should be invalid")
+	}
+
+	session.UserId = NewId()
+	session.TeamId = NewId()
+	session.ChannelId = NewId()
+	session.CreateAt = GetMillis()
+	session.UpdateAt = GetMillis()
+	session.Filename = "test.png"
+	session.Path = "uploads/" + session.Id
+	if err := session.IsValid(); err == nil {
+		t.Fatal("should be invalid without a file size")
+	}
+
+	session.FileSize = 1000
+	if err := session.IsValid(); err != nil {
+		t.Fatal(err)
+	}
+
+	session.FileOffset = 1001
+	if err := session.IsValid(); err == nil {
+		t.Fatal("should be invalid with an offset past the end of the file")
+	}
+
+	session.FileOffset = 1000
+	if err := session.IsValid(); err != nil {
+		t.Fatal(err)
+	} else if !session.IsComplete() {
+		t.Fatal("should be complete")
+	}
+}
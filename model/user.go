@@ -48,6 +48,8 @@ type User struct {
 	Locale             string    `json:"locale"`
 	MfaActive          bool      `json:"mfa_active,omitempty"`
 	MfaSecret          string    `json:"mfa_secret,omitempty"`
+	MfaLastTimeStep    int64     `json:"-"` // the time step of the last MFA token used so that tokens can't be reused
+	IsBot              bool      `json:"is_bot,omitempty"`
 	LastActivityAt     int64     `db:"-" json:"last_activity_at,omitempty"`
 }
 
@@ -377,7 +379,7 @@ func IsInRole(userRoles string, inRole string) bool {
 }
 
 func (u *User) IsOAuthUser() bool {
-	if u.AuthService == USER_AUTH_SERVICE_GITLAB {
+	if u.AuthService == USER_AUTH_SERVICE_GITLAB || u.AuthService == USER_AUTH_SERVICE_OPENID {
 		return true
 	}
 	return false
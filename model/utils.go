@@ -135,6 +135,11 @@ func GetMillis() int64 {
 	return time.Now().UnixNano() / int64(time.Millisecond)
 }
 
+// GetMillisForTime is a convience method to get milliseconds since epoch for a given time.
+func GetMillisForTime(thisTime time.Time) int64 {
+	return thisTime.UnixNano() / int64(time.Millisecond)
+}
+
 // MapToJson converts a map to a json string
 func MapToJson(objmap map[string]string) string {
 	if b, err := json.Marshal(objmap); err != nil {
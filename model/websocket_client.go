@@ -5,6 +5,8 @@ package model
 
 import (
 	"encoding/json"
+	"strconv"
+
 	"github.com/gorilla/websocket"
 )
 
@@ -18,6 +20,8 @@ type WebSocketClient struct {
 	Conn            *websocket.Conn // The WebSocket connection
 	AuthToken       string          // The token used to open the WebSocket
 	Sequence        int64           // The ever-incrementing sequence attached to each WebSocket action
+	ConnectionId    string          // The id given to the connection by the server, used to resume it after reconnecting
+	EventSequence   int64           // The sequence number of the last event received from the server
 	EventChannel    chan *WebSocketEvent
 	ResponseChannel chan *WebSocketResponse
 	ListenError     *AppError
@@ -37,6 +41,8 @@ func NewWebSocketClient(url, authToken string) (*WebSocketClient, *AppError) {
 		conn,
 		authToken,
 		1,
+		"",
+		-1,
 		make(chan *WebSocketEvent, 100),
 		make(chan *WebSocketResponse, 100),
 		nil,
@@ -47,9 +53,16 @@ func NewWebSocketClient(url, authToken string) (*WebSocketClient, *AppError) {
 	return client, nil
 }
 
+// Connect opens a new connection to the server. If the client was connected before, the server is asked to resume the
+// previous connection by sending any events that were missed in between.
 func (wsc *WebSocketClient) Connect() *AppError {
+	url := wsc.ApiUrl + "/users/websocket"
+	if wsc.ConnectionId != "" {
+		url += "?connection_id=" + wsc.ConnectionId + "&sequence_number=" + strconv.FormatInt(wsc.EventSequence, 10)
+	}
+
 	var err error
-	wsc.Conn, _, err = websocket.DefaultDialer.Dial(wsc.ApiUrl+"/users/websocket", nil)
+	wsc.Conn, _, err = websocket.DefaultDialer.Dial(url, nil)
 	if err != nil {
 		return NewLocAppError("NewWebSocketClient", "model.websocket_client.connect_fail.app_error", nil, err.Error())
 	}
@@ -87,6 +100,13 @@ func (wsc *WebSocketClient) Listen() {
 
 			var event WebSocketEvent
 			if err := json.Unmarshal(rawMsg, &event); err == nil && event.IsValid() {
+				wsc.EventSequence = event.Sequence
+				if event.Event == WEBSOCKET_EVENT_HELLO {
+					if connectionId, ok := event.Data["connection_id"].(string); ok {
+						wsc.ConnectionId = connectionId
+					}
+				}
+
 				wsc.EventChannel <- &event
 				continue
 			}
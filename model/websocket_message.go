@@ -6,30 +6,38 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"strconv"
 )
 
 const (
-	WEBSOCKET_EVENT_TYPING             = "typing"
-	WEBSOCKET_EVENT_POSTED             = "posted"
-	WEBSOCKET_EVENT_POST_EDITED        = "post_edited"
-	WEBSOCKET_EVENT_POST_DELETED       = "post_deleted"
-	WEBSOCKET_EVENT_CHANNEL_DELETED    = "channel_deleted"
-	WEBSOCKET_EVENT_CHANNEL_VIEWED     = "channel_viewed"
-	WEBSOCKET_EVENT_DIRECT_ADDED       = "direct_added"
-	WEBSOCKET_EVENT_NEW_USER           = "new_user"
-	WEBSOCKET_EVENT_LEAVE_TEAM         = "leave_team"
-	WEBSOCKET_EVENT_UPDATE_TEAM        = "update_team"
-	WEBSOCKET_EVENT_USER_ADDED         = "user_added"
-	WEBSOCKET_EVENT_USER_UPDATED       = "user_updated"
-	WEBSOCKET_EVENT_USER_REMOVED       = "user_removed"
-	WEBSOCKET_EVENT_PREFERENCE_CHANGED = "preference_changed"
-	WEBSOCKET_EVENT_EPHEMERAL_MESSAGE  = "ephemeral_message"
-	WEBSOCKET_EVENT_STATUS_CHANGE      = "status_change"
-	WEBSOCKET_EVENT_HELLO              = "hello"
-	WEBSOCKET_EVENT_WEBRTC             = "webrtc"
-	WEBSOCKET_AUTHENTICATION_CHALLENGE = "authentication_challenge"
-	WEBSOCKET_EVENT_REACTION_ADDED     = "reaction_added"
-	WEBSOCKET_EVENT_REACTION_REMOVED   = "reaction_removed"
+	WEBSOCKET_EVENT_TYPING                = "typing"
+	WEBSOCKET_EVENT_POSTED                = "posted"
+	WEBSOCKET_EVENT_POST_EDITED           = "post_edited"
+	WEBSOCKET_EVENT_POST_DELETED          = "post_deleted"
+	WEBSOCKET_EVENT_CHANNEL_CREATED       = "channel_created"
+	WEBSOCKET_EVENT_CHANNEL_DELETED       = "channel_deleted"
+	WEBSOCKET_EVENT_CHANNEL_VIEWED        = "channel_viewed"
+	WEBSOCKET_EVENT_DIRECT_ADDED          = "direct_added"
+	WEBSOCKET_EVENT_NEW_USER              = "new_user"
+	WEBSOCKET_EVENT_ADDED_TO_TEAM         = "added_to_team"
+	WEBSOCKET_EVENT_LEAVE_TEAM            = "leave_team"
+	WEBSOCKET_EVENT_UPDATE_TEAM           = "update_team"
+	WEBSOCKET_EVENT_USER_ADDED            = "user_added"
+	WEBSOCKET_EVENT_USER_UPDATED          = "user_updated"
+	WEBSOCKET_EVENT_USER_REMOVED          = "user_removed"
+	WEBSOCKET_EVENT_PREFERENCE_CHANGED    = "preference_changed"
+	WEBSOCKET_EVENT_EPHEMERAL_MESSAGE     = "ephemeral_message"
+	WEBSOCKET_EVENT_STATUS_CHANGE         = "status_change"
+	WEBSOCKET_EVENT_HELLO                 = "hello"
+	WEBSOCKET_EVENT_WEBRTC                = "webrtc"
+	WEBSOCKET_AUTHENTICATION_CHALLENGE    = "authentication_challenge"
+	WEBSOCKET_EVENT_REACTION_ADDED        = "reaction_added"
+	WEBSOCKET_EVENT_REACTION_REMOVED      = "reaction_removed"
+	WEBSOCKET_EVENT_THREAD_UPDATED        = "thread_updated"
+	WEBSOCKET_EVENT_SCHEDULED_POST_FAILED = "scheduled_post_failed"
+	WEBSOCKET_EVENT_DRAFT_UPDATED         = "draft_updated"
+	WEBSOCKET_EVENT_DRAFT_DELETED         = "draft_deleted"
+	WEBSOCKET_EVENT_RESYNC_REQUIRED       = "resync_required"
 )
 
 type WebSocketMessage interface {
@@ -51,7 +59,11 @@ type WebSocketEvent struct {
 	Event          string                 `json:"event"`
 	Data           map[string]interface{} `json:"data"`
 	Broadcast      *WebsocketBroadcast    `json:"broadcast"`
+	Sequence       int64                  `json:"seq"` // set separately for each connection that the event is sent to
 	PreComputeJson []byte                 `json:"-"`
+
+	// the JSON for everything but the sequence number and closing brace, shared by each copy of the event
+	unsequencedJson []byte
 }
 
 func (m *WebSocketEvent) Add(key string, value interface{}) {
@@ -63,6 +75,47 @@ func NewWebSocketEvent(event, teamId, channelId, userId string, omitUsers map[st
 		Broadcast: &WebsocketBroadcast{TeamId: teamId, ChannelId: channelId, UserId: userId, OmitUsers: omitUsers}}
 }
 
+// CopyWithSequence returns a copy of the event with the given sequence number that shares the event's data. The
+// copy's JSON is built from the event's precomputed JSON so that the data only needs to be encoded once.
+func (o *WebSocketEvent) CopyWithSequence(sequence int64) *WebSocketEvent {
+	unsequenced := o.unsequencedJson
+	if unsequenced == nil {
+		unsequenced = o.marshalWithoutSequence()
+	}
+
+	event := *o
+	event.Sequence = sequence
+	event.unsequencedJson = unsequenced
+	event.PreComputeJson = appendSequenceJson(unsequenced, sequence)
+	return &event
+}
+
+func (o *WebSocketEvent) marshalWithoutSequence() []byte {
+	b, err := json.Marshal(&struct {
+		Event     string                 `json:"event"`
+		Data      map[string]interface{} `json:"data"`
+		Broadcast *WebsocketBroadcast    `json:"broadcast"`
+	}{o.Event, o.Data, o.Broadcast})
+	if err != nil {
+		return []byte("")
+	}
+
+	// Leave the object open so that the sequence number can be added to the end
+	return b[:len(b)-1]
+}
+
+func appendSequenceJson(unsequenced []byte, sequence int64) []byte {
+	if len(unsequenced) == 0 {
+		return []byte("")
+	}
+
+	b := make([]byte, 0, len(unsequenced)+32)
+	b = append(b, unsequenced...)
+	b = append(b, `,"seq":`...)
+	b = strconv.AppendInt(b, sequence, 10)
+	return append(b, '}')
+}
+
 func (o *WebSocketEvent) IsValid() bool {
 	return o.Event != ""
 }
@@ -72,12 +125,8 @@ func (o *WebSocketEvent) EventType() string {
 }
 
 func (o *WebSocketEvent) DoPreComputeJson() {
-	b, err := json.Marshal(o)
-	if err != nil {
-		o.PreComputeJson = []byte("")
-	} else {
-		o.PreComputeJson = b
-	}
+	o.unsequencedJson = o.marshalWithoutSequence()
+	o.PreComputeJson = appendSequenceJson(o.unsequencedJson, o.Sequence)
 }
 
 func (o *WebSocketEvent) GetPreComputeJson() []byte {
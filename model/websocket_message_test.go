@@ -32,6 +32,36 @@ func TestWebSocketEvent(t *testing.T) {
 	}
 }
 
+func TestWebSocketEventCopyWithSequence(t *testing.T) {
+	m := NewWebSocketEvent("some_event", NewId(), NewId(), NewId(), nil)
+	m.DoPreComputeJson()
+
+	sequenced := m.CopyWithSequence(5)
+	if m.Sequence != 0 || sequenced.Sequence != 5 {
+		t.Fatal("should've only set the sequence on the copy")
+	}
+
+	if result := WebSocketEventFromJson(strings.NewReader(string(sequenced.GetPreComputeJson()))); result.Sequence != 5 {
+		t.Fatal("should've included the sequence in the precomputed json")
+	}
+
+	m.Add("key", "value")
+	m.DoPreComputeJson()
+
+	sequenced = m.CopyWithSequence(6)
+	if string(sequenced.GetPreComputeJson()) != sequenced.ToJson() {
+		t.Fatal("precomputed json should've matched the copy", string(sequenced.GetPreComputeJson()))
+	}
+
+	if string(m.CopyWithSequence(7).GetPreComputeJson()) == string(sequenced.GetPreComputeJson()) {
+		t.Fatal("copies shouldn't share precomputed json")
+	}
+
+	if result := WebSocketEventFromJson(strings.NewReader(string(NewWebSocketEvent("some_event", "", "", "", nil).CopyWithSequence(8).GetPreComputeJson()))); result == nil || result.Sequence != 8 {
+		t.Fatal("should've built the json for an event that wasn't precomputed")
+	}
+}
+
 func TestWebSocketResponse(t *testing.T) {
 	m := NewWebSocketResponse("OK", 1, map[string]interface{}{})
 	e := NewWebSocketError(1, &AppError{})
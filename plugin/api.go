@@ -0,0 +1,26 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+// API is the set of functions that a plugin can use to call back into the server. It's passed to the plugin's
+// OnActivate hook.
+type API interface {
+	// LoadPluginConfiguration unmarshals the plugin's settings from the server's config into dest.
+	LoadPluginConfiguration(dest interface{}) error
+
+	GetUser(userId string) (*model.User, *model.AppError)
+	GetUserByUsername(username string) (*model.User, *model.AppError)
+	GetTeam(teamId string) (*model.Team, *model.AppError)
+	GetTeamByName(name string) (*model.Team, *model.AppError)
+	GetChannel(channelId string) (*model.Channel, *model.AppError)
+	GetChannelByName(name, teamId string) (*model.Channel, *model.AppError)
+
+	// CreatePost creates a post in the same way as a post made by a user. The post goes through the
+	// MessageWillBePosted hooks of every plugin, including the one that made it.
+	CreatePost(post *model.Post) (*model.Post, *model.AppError)
+}
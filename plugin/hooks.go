@@ -0,0 +1,34 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+// Hooks are the methods that the server calls on a plugin. A plugin only has to implement the ones that it needs, and
+// the server skips the others.
+type Hooks interface {
+	// OnActivate is called when the plugin is started. Returning an error stops the plugin.
+	OnActivate(api API) error
+
+	// OnDeactivate is called before the plugin is stopped.
+	OnDeactivate() error
+
+	// OnConfigurationChange is called when the server's config is changed so that the plugin can reload its settings.
+	OnConfigurationChange() error
+
+	// ServeHTTP handles requests to /plugins/{plugin_id}. The path of the request is relative to that prefix and the
+	// Mattermost-User-Id header is set if the request was made by a signed in user.
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+
+	// MessageWillBePosted is called before a post is saved. It returns the post to save, which can be changed, or nil
+	// and a reason to reject the post.
+	MessageWillBePosted(post *model.Post) (*model.Post, string)
+
+	// MessageHasBeenPosted is called after a post has been saved.
+	MessageHasBeenPosted(post *model.Post)
+}
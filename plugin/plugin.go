@@ -0,0 +1,12 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+// Package plugin defines the hooks that the server calls on plugins and the API that plugins use to call back into
+// the server. Plugins run as separate programs that talk to the server over RPC, which is implemented by the rpcplugin
+// package.
+package plugin
+
+const (
+	// HEADER_USER_ID is set on requests to a plugin's ServeHTTP hook to the id of the user who made the request.
+	HEADER_USER_ID = "Mattermost-User-Id"
+)
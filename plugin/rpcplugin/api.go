@@ -0,0 +1,183 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"encoding/json"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/plugin"
+)
+
+type APIGetChannelByNameArgs struct {
+	Name   string
+	TeamId string
+}
+
+type APIUserReply struct {
+	User  *model.User
+	Error *model.AppError
+}
+
+type APITeamReply struct {
+	Team  *model.Team
+	Error *model.AppError
+}
+
+type APIChannelReply struct {
+	Channel *model.Channel
+	Error   *model.AppError
+}
+
+type APIPostReply struct {
+	Post  *model.Post
+	Error *model.AppError
+}
+
+// APIRPCServer runs in the server and answers a plugin's calls to the API.
+type APIRPCServer struct {
+	api plugin.API
+}
+
+func (s *APIRPCServer) LoadPluginConfiguration(args struct{}, reply *json.RawMessage) error {
+	var config json.RawMessage
+	if err := s.api.LoadPluginConfiguration(&config); err != nil {
+		return err
+	}
+	*reply = config
+	return nil
+}
+
+func (s *APIRPCServer) GetUser(args string, reply *APIUserReply) error {
+	reply.User, reply.Error = s.api.GetUser(args)
+	return nil
+}
+
+func (s *APIRPCServer) GetUserByUsername(args string, reply *APIUserReply) error {
+	reply.User, reply.Error = s.api.GetUserByUsername(args)
+	return nil
+}
+
+func (s *APIRPCServer) GetTeam(args string, reply *APITeamReply) error {
+	reply.Team, reply.Error = s.api.GetTeam(args)
+	return nil
+}
+
+func (s *APIRPCServer) GetTeamByName(args string, reply *APITeamReply) error {
+	reply.Team, reply.Error = s.api.GetTeamByName(args)
+	return nil
+}
+
+func (s *APIRPCServer) GetChannel(args string, reply *APIChannelReply) error {
+	reply.Channel, reply.Error = s.api.GetChannel(args)
+	return nil
+}
+
+func (s *APIRPCServer) GetChannelByName(args *APIGetChannelByNameArgs, reply *APIChannelReply) error {
+	reply.Channel, reply.Error = s.api.GetChannelByName(args.Name, args.TeamId)
+	return nil
+}
+
+func (s *APIRPCServer) CreatePost(args *model.Post, reply *APIPostReply) error {
+	reply.Post, reply.Error = s.api.CreatePost(args)
+	return nil
+}
+
+// ServeAPI answers a plugin's calls to the API until the connection to the plugin is closed.
+func ServeAPI(conn io.ReadWriteCloser, api plugin.API) {
+	server := rpc.NewServer()
+	server.RegisterName("API", &APIRPCServer{api: api})
+	server.ServeCodec(jsonrpc.NewServerCodec(conn))
+}
+
+// APIRPCClient runs in the plugin and implements the API by calling the server.
+type APIRPCClient struct {
+	client *rpc.Client
+}
+
+var _ plugin.API = (*APIRPCClient)(nil)
+
+func NewAPIRPCClient(conn io.ReadWriteCloser) *APIRPCClient {
+	return &APIRPCClient{client: jsonrpc.NewClient(conn)}
+}
+
+func (c *APIRPCClient) LoadPluginConfiguration(dest interface{}) error {
+	var config json.RawMessage
+	if err := c.client.Call("API.LoadPluginConfiguration", struct{}{}, &config); err != nil {
+		return err
+	}
+
+	if len(config) == 0 {
+		return nil
+	}
+	return json.Unmarshal(config, dest)
+}
+
+func (c *APIRPCClient) GetUser(userId string) (*model.User, *model.AppError) {
+	var reply APIUserReply
+	if err := c.client.Call("API.GetUser", userId, &reply); err != nil {
+		return nil, newAPIRPCError("GetUser", err)
+	}
+	return reply.User, reply.Error
+}
+
+func (c *APIRPCClient) GetUserByUsername(username string) (*model.User, *model.AppError) {
+	var reply APIUserReply
+	if err := c.client.Call("API.GetUserByUsername", username, &reply); err != nil {
+		return nil, newAPIRPCError("GetUserByUsername", err)
+	}
+	return reply.User, reply.Error
+}
+
+func (c *APIRPCClient) GetTeam(teamId string) (*model.Team, *model.AppError) {
+	var reply APITeamReply
+	if err := c.client.Call("API.GetTeam", teamId, &reply); err != nil {
+		return nil, newAPIRPCError("GetTeam", err)
+	}
+	return reply.Team, reply.Error
+}
+
+func (c *APIRPCClient) GetTeamByName(name string) (*model.Team, *model.AppError) {
+	var reply APITeamReply
+	if err := c.client.Call("API.GetTeamByName", name, &reply); err != nil {
+		return nil, newAPIRPCError("GetTeamByName", err)
+	}
+	return reply.Team, reply.Error
+}
+
+func (c *APIRPCClient) GetChannel(channelId string) (*model.Channel, *model.AppError) {
+	var reply APIChannelReply
+	if err := c.client.Call("API.GetChannel", channelId, &reply); err != nil {
+		return nil, newAPIRPCError("GetChannel", err)
+	}
+	return reply.Channel, reply.Error
+}
+
+func (c *APIRPCClient) GetChannelByName(name, teamId string) (*model.Channel, *model.AppError) {
+	var reply APIChannelReply
+	if err := c.client.Call("API.GetChannelByName", &APIGetChannelByNameArgs{Name: name, TeamId: teamId}, &reply); err != nil {
+		return nil, newAPIRPCError("GetChannelByName", err)
+	}
+	return reply.Channel, reply.Error
+}
+
+func (c *APIRPCClient) CreatePost(post *model.Post) (*model.Post, *model.AppError) {
+	var reply APIPostReply
+	if err := c.client.Call("API.CreatePost", post, &reply); err != nil {
+		return nil, newAPIRPCError("CreatePost", err)
+	}
+	return reply.Post, reply.Error
+}
+
+func (c *APIRPCClient) Close() error {
+	return c.client.Close()
+}
+
+// newAPIRPCError is returned when the server couldn't be reached at all, as opposed to an error from the API itself.
+func newAPIRPCError(where string, err error) *model.AppError {
+	return model.NewLocAppError("APIRPCClient."+where, "plugin.rpcplugin.api.app_error", nil, err.Error())
+}
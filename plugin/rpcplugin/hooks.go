@@ -0,0 +1,330 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/plugin"
+)
+
+const (
+	SERVE_HTTP_MAX_BODY_SIZE = 10 * 1024 * 1024
+
+	// Hooks that are run while a post is being created have this long to reply before the plugin is skipped
+	POST_HOOK_TIMEOUT = 5 * time.Second
+)
+
+var ErrHookTimeout = errors.New("plugin didn't reply to the hook in time")
+
+// Each hook is optional, so the server asks the plugin which of these it implements when it starts
+type onActivateHook interface {
+	OnActivate(plugin.API) error
+}
+
+type onDeactivateHook interface {
+	OnDeactivate() error
+}
+
+type onConfigurationChangeHook interface {
+	OnConfigurationChange() error
+}
+
+type serveHTTPHook interface {
+	ServeHTTP(http.ResponseWriter, *http.Request)
+}
+
+type messageWillBePostedHook interface {
+	MessageWillBePosted(*model.Post) (*model.Post, string)
+}
+
+type messageHasBeenPostedHook interface {
+	MessageHasBeenPosted(*model.Post)
+}
+
+type ServeHTTPArgs struct {
+	Method     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+	RemoteAddr string
+}
+
+type ServeHTTPReply struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type MessageWillBePostedReply struct {
+	Post            *model.Post
+	RejectionReason string
+}
+
+// HooksRPCServer runs in the plugin and calls its hooks when the server asks it to.
+type HooksRPCServer struct {
+	hooks interface{}
+	api   plugin.API
+}
+
+func (s *HooksRPCServer) Implemented(args struct{}, reply *[]string) error {
+	implemented := []string{}
+
+	if _, ok := s.hooks.(onActivateHook); ok {
+		implemented = append(implemented, "OnActivate")
+	}
+	if _, ok := s.hooks.(onDeactivateHook); ok {
+		implemented = append(implemented, "OnDeactivate")
+	}
+	if _, ok := s.hooks.(onConfigurationChangeHook); ok {
+		implemented = append(implemented, "OnConfigurationChange")
+	}
+	if _, ok := s.hooks.(serveHTTPHook); ok {
+		implemented = append(implemented, "ServeHTTP")
+	}
+	if _, ok := s.hooks.(messageWillBePostedHook); ok {
+		implemented = append(implemented, "MessageWillBePosted")
+	}
+	if _, ok := s.hooks.(messageHasBeenPostedHook); ok {
+		implemented = append(implemented, "MessageHasBeenPosted")
+	}
+
+	*reply = implemented
+	return nil
+}
+
+func (s *HooksRPCServer) OnActivate(args struct{}, reply *struct{}) error {
+	if hook, ok := s.hooks.(onActivateHook); ok {
+		return hook.OnActivate(s.api)
+	}
+	return nil
+}
+
+func (s *HooksRPCServer) OnDeactivate(args struct{}, reply *struct{}) error {
+	if hook, ok := s.hooks.(onDeactivateHook); ok {
+		return hook.OnDeactivate()
+	}
+	return nil
+}
+
+func (s *HooksRPCServer) OnConfigurationChange(args struct{}, reply *struct{}) error {
+	if hook, ok := s.hooks.(onConfigurationChangeHook); ok {
+		return hook.OnConfigurationChange()
+	}
+	return nil
+}
+
+func (s *HooksRPCServer) ServeHTTP(args *ServeHTTPArgs, reply *ServeHTTPReply) error {
+	hook, ok := s.hooks.(serveHTTPHook)
+	if !ok {
+		reply.StatusCode = http.StatusNotFound
+		return nil
+	}
+
+	r, err := http.NewRequest(args.Method, args.URL, bytes.NewReader(args.Body))
+	if err != nil {
+		return err
+	}
+	r.Header = args.Header
+	r.RemoteAddr = args.RemoteAddr
+
+	w := &responseWriter{header: http.Header{}}
+	hook.ServeHTTP(w, r)
+
+	reply.StatusCode = w.statusCode
+	if reply.StatusCode == 0 {
+		reply.StatusCode = http.StatusOK
+	}
+	reply.Header = w.header
+	reply.Body = w.body.Bytes()
+	return nil
+}
+
+func (s *HooksRPCServer) MessageWillBePosted(args *model.Post, reply *MessageWillBePostedReply) error {
+	if hook, ok := s.hooks.(messageWillBePostedHook); ok {
+		reply.Post, reply.RejectionReason = hook.MessageWillBePosted(args)
+	} else {
+		reply.Post = args
+	}
+	return nil
+}
+
+func (s *HooksRPCServer) MessageHasBeenPosted(args *model.Post, reply *struct{}) error {
+	if hook, ok := s.hooks.(messageHasBeenPostedHook); ok {
+		hook.MessageHasBeenPosted(args)
+	}
+	return nil
+}
+
+// ServeHooks answers calls to a plugin's hooks until the connection to the server is closed.
+func ServeHooks(conn io.ReadWriteCloser, hooks interface{}, api plugin.API) {
+	server := rpc.NewServer()
+	server.RegisterName("Hooks", &HooksRPCServer{hooks: hooks, api: api})
+	server.ServeCodec(jsonrpc.NewServerCodec(conn))
+}
+
+// responseWriter collects what a plugin's ServeHTTP hook writes so that it can be sent back to the server.
+type responseWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+// HooksRPCClient runs in the server and calls the hooks of a plugin. Calls to hooks that the plugin doesn't implement
+// return straight away.
+type HooksRPCClient struct {
+	client      *rpc.Client
+	implemented map[string]bool
+	postTimeout time.Duration
+}
+
+func NewHooksRPCClient(conn io.ReadWriteCloser) (*HooksRPCClient, error) {
+	client := jsonrpc.NewClient(conn)
+
+	var implemented []string
+	if err := client.Call("Hooks.Implemented", struct{}{}, &implemented); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	h := &HooksRPCClient{
+		client:      client,
+		implemented: map[string]bool{},
+		postTimeout: POST_HOOK_TIMEOUT,
+	}
+	for _, hook := range implemented {
+		h.implemented[hook] = true
+	}
+
+	return h, nil
+}
+
+// Implements returns true if the plugin implements the hook with the given name.
+func (h *HooksRPCClient) Implements(hook string) bool {
+	return h.implemented[hook]
+}
+
+func (h *HooksRPCClient) OnActivate() error {
+	if !h.implemented["OnActivate"] {
+		return nil
+	}
+	return h.client.Call("Hooks.OnActivate", struct{}{}, nil)
+}
+
+func (h *HooksRPCClient) OnDeactivate() error {
+	if !h.implemented["OnDeactivate"] {
+		return nil
+	}
+	return h.client.Call("Hooks.OnDeactivate", struct{}{}, nil)
+}
+
+func (h *HooksRPCClient) OnConfigurationChange() error {
+	if !h.implemented["OnConfigurationChange"] {
+		return nil
+	}
+	return h.client.Call("Hooks.OnConfigurationChange", struct{}{}, nil)
+}
+
+// ServeHTTP sends a request to the plugin and writes its response. The whole request and response are sent at once,
+// so their bodies are limited in size.
+func (h *HooksRPCClient) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.implemented["ServeHTTP"] {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, SERVE_HTTP_MAX_BODY_SIZE+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if len(body) > SERVE_HTTP_MAX_BODY_SIZE {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	args := &ServeHTTPArgs{
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		Header:     r.Header,
+		Body:       body,
+		RemoteAddr: r.RemoteAddr,
+	}
+
+	var reply ServeHTTPReply
+	if err := h.client.Call("Hooks.ServeHTTP", args, &reply); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for key, values := range reply.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(reply.StatusCode)
+	w.Write(reply.Body)
+}
+
+// MessageWillBePosted returns the post as changed by the plugin, or nil and the reason that the plugin rejected it.
+func (h *HooksRPCClient) MessageWillBePosted(post *model.Post) (*model.Post, string, error) {
+	if !h.implemented["MessageWillBePosted"] {
+		return post, "", nil
+	}
+
+	var reply MessageWillBePostedReply
+	if err := h.callWithTimeout("Hooks.MessageWillBePosted", post, &reply, h.postTimeout); err != nil {
+		return nil, "", err
+	}
+
+	return reply.Post, reply.RejectionReason, nil
+}
+
+func (h *HooksRPCClient) MessageHasBeenPosted(post *model.Post) error {
+	if !h.implemented["MessageHasBeenPosted"] {
+		return nil
+	}
+	return h.callWithTimeout("Hooks.MessageHasBeenPosted", post, nil, h.postTimeout)
+}
+
+// callWithTimeout calls a hook but stops waiting for the plugin to reply once the timeout has passed so that a stuck
+// plugin can't hold up the server. The reply shouldn't be used after a timeout since the plugin may still fill it in.
+func (h *HooksRPCClient) callWithTimeout(method string, args interface{}, reply interface{}, timeout time.Duration) error {
+	call := h.client.Go(method, args, reply, make(chan *rpc.Call, 1))
+
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-time.After(timeout):
+		return ErrHookTimeout
+	}
+}
+
+func (h *HooksRPCClient) Close() error {
+	return h.client.Close()
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/plugin"
+)
+
+type testHooks struct {
+	api plugin.API
+}
+
+func (h *testHooks) OnActivate(api plugin.API) error {
+	h.api = api
+
+	var config struct{ Greeting string }
+	if err := api.LoadPluginConfiguration(&config); err != nil {
+		return err
+	} else if config.Greeting != "hello" {
+		return errors.New("wrong configuration")
+	}
+
+	return nil
+}
+
+func (h *testHooks) OnConfigurationChange() error {
+	return errors.New("bad configuration")
+}
+
+func (h *testHooks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	w.Header().Set("X-Test", "test")
+	w.WriteHeader(http.StatusTeapot)
+	fmt.Fprintf(w, "%v %v %v", r.URL.Path, r.Header.Get(plugin.HEADER_USER_ID), string(body))
+}
+
+func (h *testHooks) MessageWillBePosted(post *model.Post) (*model.Post, string) {
+	if strings.Contains(post.Message, "reject") {
+		return nil, "rejected"
+	}
+
+	user, err := h.api.GetUser(post.UserId)
+	if err != nil {
+		return nil, err.Id
+	}
+
+	post.Message += " from " + user.Username
+	return post, ""
+}
+
+// testAPI knows about a single user
+type testAPI struct{}
+
+func (api *testAPI) LoadPluginConfiguration(dest interface{}) error {
+	*dest.(*json.RawMessage) = json.RawMessage(`{"Greeting": "hello"}`)
+	return nil
+}
+
+func (api *testAPI) GetUser(userId string) (*model.User, *model.AppError) {
+	if userId != "userid" {
+		return nil, model.NewLocAppError("GetUser", "test.not_found", nil, "")
+	}
+	return &model.User{Id: userId, Username: "username"}, nil
+}
+
+func (api *testAPI) GetUserByUsername(username string) (*model.User, *model.AppError) {
+	return nil, nil
+}
+
+func (api *testAPI) GetTeam(teamId string) (*model.Team, *model.AppError) {
+	return nil, nil
+}
+
+func (api *testAPI) GetTeamByName(name string) (*model.Team, *model.AppError) {
+	return nil, nil
+}
+
+func (api *testAPI) GetChannel(channelId string) (*model.Channel, *model.AppError) {
+	return nil, nil
+}
+
+func (api *testAPI) GetChannelByName(name, teamId string) (*model.Channel, *model.AppError) {
+	return &model.Channel{Name: name, TeamId: teamId}, nil
+}
+
+func (api *testAPI) CreatePost(post *model.Post) (*model.Post, *model.AppError) {
+	return post, nil
+}
+
+// connectTestPlugin connects to hooks that are running in the same process
+func connectTestPlugin(t *testing.T, hooks interface{}) (*HooksRPCClient, *Muxer) {
+	serverConn, pluginConn := net.Pipe()
+	serverMuxer := NewMuxer(serverConn)
+	pluginMuxer := NewMuxer(pluginConn)
+
+	go ServeAPI(serverMuxer.Stream(API_STREAM), &testAPI{})
+	go ServeHooks(pluginMuxer.Stream(HOOKS_STREAM), hooks, NewAPIRPCClient(pluginMuxer.Stream(API_STREAM)))
+
+	client, err := NewHooksRPCClient(serverMuxer.Stream(HOOKS_STREAM))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client, serverMuxer
+}
+
+func testHooksRPCClient(t *testing.T, hooks *HooksRPCClient) {
+	if !hooks.Implements("OnActivate") || !hooks.Implements("MessageWillBePosted") || hooks.Implements("MessageHasBeenPosted") {
+		t.Fatal("wrong hooks implemented")
+	}
+
+	if err := hooks.OnActivate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hooks.OnConfigurationChange(); err == nil || err.Error() != "bad configuration" {
+		t.Fatal("should've returned the plugin's error", err)
+	}
+
+	// Hooks that aren't implemented do nothing
+	if err := hooks.OnDeactivate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := hooks.MessageHasBeenPosted(&model.Post{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if post, reason, err := hooks.MessageWillBePosted(&model.Post{UserId: "userid", Message: "message"}); err != nil {
+		t.Fatal(err)
+	} else if post == nil || post.Message != "message from username" || reason != "" {
+		t.Fatal("should've changed the post", post, reason)
+	}
+
+	if post, reason, err := hooks.MessageWillBePosted(&model.Post{UserId: "userid", Message: "reject"}); err != nil {
+		t.Fatal(err)
+	} else if post != nil || reason != "rejected" {
+		t.Fatal("should've rejected the post")
+	}
+
+	if post, reason, err := hooks.MessageWillBePosted(&model.Post{UserId: "other", Message: "message"}); err != nil {
+		t.Fatal(err)
+	} else if post != nil || reason != "test.not_found" {
+		t.Fatal("should've been given the api's error", reason)
+	}
+
+	r := httptest.NewRequest("POST", "/path?query=1", strings.NewReader("body"))
+	r.Header.Set(plugin.HEADER_USER_ID, "userid")
+	w := httptest.NewRecorder()
+	hooks.ServeHTTP(w, r)
+
+	if w.Code != http.StatusTeapot || w.Header().Get("X-Test") != "test" || w.Body.String() != "/path userid body" {
+		t.Fatal("wrong response", w.Code, w.Header(), w.Body.String())
+	}
+}
+
+func TestHooksRPC(t *testing.T) {
+	hooks, muxer := connectTestPlugin(t, &testHooks{})
+	defer muxer.Close()
+
+	testHooksRPCClient(t, hooks)
+}
+
+func TestHooksRPCNoHooks(t *testing.T) {
+	hooks, muxer := connectTestPlugin(t, struct{}{})
+	defer muxer.Close()
+
+	if post, _, err := hooks.MessageWillBePosted(&model.Post{Message: "message"}); err != nil {
+		t.Fatal(err)
+	} else if post.Message != "message" {
+		t.Fatal("shouldn't have changed the post")
+	}
+
+	w := httptest.NewRecorder()
+	hooks.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatal("should've been not found", w.Code)
+	}
+}
+
+// stuckHooks don't reply to MessageWillBePosted until they're released
+type stuckHooks struct {
+	release chan bool
+}
+
+func (h *stuckHooks) MessageWillBePosted(post *model.Post) (*model.Post, string) {
+	<-h.release
+	return post, ""
+}
+
+func TestHooksRPCTimeout(t *testing.T) {
+	stuck := &stuckHooks{release: make(chan bool)}
+	hooks, muxer := connectTestPlugin(t, stuck)
+	defer muxer.Close()
+	defer close(stuck.release)
+
+	hooks.postTimeout = 10 * time.Millisecond
+
+	if post, _, err := hooks.MessageWillBePosted(&model.Post{Message: "message"}); err != ErrHookTimeout {
+		t.Fatal("should've timed out", err)
+	} else if post != nil {
+		t.Fatal("shouldn't have returned a post")
+	}
+}
+
+func TestAPIRPC(t *testing.T) {
+	serverConn, pluginConn := net.Pipe()
+	serverMuxer := NewMuxer(serverConn)
+	pluginMuxer := NewMuxer(pluginConn)
+	defer serverMuxer.Close()
+
+	go ServeAPI(serverMuxer.Stream(API_STREAM), &testAPI{})
+	api := NewAPIRPCClient(pluginMuxer.Stream(API_STREAM))
+
+	if user, err := api.GetUser("userid"); err != nil {
+		t.Fatal(err)
+	} else if user.Username != "username" {
+		t.Fatal("wrong user")
+	}
+
+	if _, err := api.GetUser("other"); err == nil || err.Id != "test.not_found" {
+		t.Fatal("should've returned the api's error", err)
+	}
+
+	if channel, err := api.GetChannelByName("name", "teamid"); err != nil {
+		t.Fatal(err)
+	} else if channel.Name != "name" || channel.TeamId != "teamid" {
+		t.Fatal("wrong channel")
+	}
+
+	post := &model.Post{Message: "message", Props: model.StringInterface{"attachments": []interface{}{map[string]interface{}{"text": "text"}}}}
+	if rpost, err := api.CreatePost(post); err != nil {
+		t.Fatal(err)
+	} else if attachments, ok := rpost.Props["attachments"].([]interface{}); rpost.Message != post.Message || !ok || len(attachments) != 1 {
+		t.Fatal("post should've survived the round trip")
+	}
+
+	serverMuxer.Close()
+	if _, err := api.GetUser("userid"); err == nil || err.Id != "plugin.rpcplugin.api.app_error" {
+		t.Fatal("should've failed once the server was gone", err)
+	}
+}
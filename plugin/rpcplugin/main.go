@@ -0,0 +1,21 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"os"
+)
+
+// Main is called from the main function of a plugin with the value that implements its hooks. It serves the hooks
+// until the server stops the plugin. The server talks to the plugin over stdin and stdout, so plugins must only log
+// to stderr.
+func Main(hooks interface{}) {
+	muxer := NewMuxer(NewReadWriteCloser(os.Stdin, os.Stdout))
+	defer muxer.Close()
+
+	api := NewAPIRPCClient(muxer.Stream(API_STREAM))
+	defer api.Close()
+
+	ServeHooks(muxer.Stream(HOOKS_STREAM), hooks, api)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	// HOOKS_STREAM carries calls from the server to the plugin's hooks.
+	HOOKS_STREAM byte = 0
+
+	// API_STREAM carries calls from the plugin to the server's API.
+	API_STREAM byte = 1
+
+	muxerStreamCount = 2
+	muxerHeaderSize  = 5
+	muxerMaxFrame    = 64 * 1024 * 1024
+)
+
+// Muxer splits a single connection, such as a plugin's stdin and stdout, into a fixed number of streams. Each write to
+// a stream is sent as a frame made of the stream's id, the length of the data and the data itself. Received data is
+// buffered for each stream so that a stream that isn't being read from doesn't hold up the others.
+type Muxer struct {
+	conn      io.ReadWriteCloser
+	writeLock sync.Mutex
+	streams   [muxerStreamCount]*muxerStream
+}
+
+type muxerStream struct {
+	id    byte
+	muxer *Muxer
+
+	lock   sync.Mutex
+	cond   *sync.Cond
+	buffer bytes.Buffer
+	err    error
+	closed bool
+}
+
+func NewMuxer(conn io.ReadWriteCloser) *Muxer {
+	m := &Muxer{conn: conn}
+	for i := range m.streams {
+		stream := &muxerStream{id: byte(i), muxer: m}
+		stream.cond = sync.NewCond(&stream.lock)
+		m.streams[i] = stream
+	}
+
+	go m.run()
+
+	return m
+}
+
+// Stream returns one of the streams of the connection. Closing a stream only stops it from being read from, so close
+// the muxer to end the connection.
+func (m *Muxer) Stream(id byte) io.ReadWriteCloser {
+	return m.streams[id]
+}
+
+func (m *Muxer) Close() error {
+	return m.conn.Close()
+}
+
+func (m *Muxer) run() {
+	header := make([]byte, muxerHeaderSize)
+
+	var err error
+	for {
+		if _, err = io.ReadFull(m.conn, header); err != nil {
+			break
+		}
+
+		id := header[0]
+		length := binary.BigEndian.Uint32(header[1:])
+		if int(id) >= len(m.streams) || length > muxerMaxFrame {
+			err = errors.New("rpcplugin: invalid frame")
+			break
+		}
+
+		data := make([]byte, length)
+		if _, err = io.ReadFull(m.conn, data); err != nil {
+			break
+		}
+
+		m.streams[id].push(data)
+	}
+
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	for _, stream := range m.streams {
+		stream.end(err)
+	}
+}
+
+// push adds received data to the stream. Data for a stream that's been closed is dropped.
+func (s *muxerStream) push(data []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.closed {
+		s.buffer.Write(data)
+		s.cond.Broadcast()
+	}
+}
+
+// end stops the stream from receiving any more data. Reads return err once the buffered data has been read.
+func (s *muxerStream) end(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.err = err
+	s.cond.Broadcast()
+}
+
+func (s *muxerStream) Read(p []byte) (int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for s.buffer.Len() == 0 && s.err == nil && !s.closed {
+		s.cond.Wait()
+	}
+
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	} else if s.buffer.Len() > 0 {
+		return s.buffer.Read(p)
+	}
+
+	return 0, s.err
+}
+
+func (s *muxerStream) Write(p []byte) (int, error) {
+	if len(p) > muxerMaxFrame {
+		return 0, errors.New("rpcplugin: write too large")
+	}
+
+	header := make([]byte, muxerHeaderSize)
+	header[0] = s.id
+	binary.BigEndian.PutUint32(header[1:], uint32(len(p)))
+
+	s.muxer.writeLock.Lock()
+	defer s.muxer.writeLock.Unlock()
+
+	if _, err := s.muxer.conn.Write(header); err != nil {
+		return 0, err
+	}
+
+	return s.muxer.conn.Write(p)
+}
+
+func (s *muxerStream) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.closed = true
+	s.buffer.Reset()
+	s.cond.Broadcast()
+
+	return nil
+}
+
+type readWriteCloser struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+// NewReadWriteCloser joins the two ends of a connection, such as a process's stdout and stdin. Closing it closes both.
+func NewReadWriteCloser(r io.ReadCloser, w io.WriteCloser) io.ReadWriteCloser {
+	return &readWriteCloser{r, w}
+}
+
+func (rwc *readWriteCloser) Close() error {
+	werr := rwc.WriteCloser.Close()
+	if rerr := rwc.ReadCloser.Close(); rerr != nil {
+		return rerr
+	}
+	return werr
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+)
+
+func TestMuxer(t *testing.T) {
+	left, right := net.Pipe()
+	leftMuxer := NewMuxer(left)
+	rightMuxer := NewMuxer(right)
+
+	go func() {
+		leftMuxer.Stream(API_STREAM).Write([]byte("api"))
+		leftMuxer.Stream(HOOKS_STREAM).Write([]byte("hooks"))
+	}()
+
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(rightMuxer.Stream(HOOKS_STREAM), buf); err != nil {
+		t.Fatal(err)
+	} else if string(buf) != "hooks" {
+		t.Fatal("wrong data on hooks stream", string(buf))
+	}
+
+	buf = make([]byte, 3)
+	if _, err := io.ReadFull(rightMuxer.Stream(API_STREAM), buf); err != nil {
+		t.Fatal(err)
+	} else if string(buf) != "api" {
+		t.Fatal("wrong data on api stream", string(buf))
+	}
+
+	// Data for a closed stream is dropped without blocking the others
+	rightMuxer.Stream(API_STREAM).Close()
+	go func() {
+		leftMuxer.Stream(API_STREAM).Write([]byte("dropped"))
+		leftMuxer.Stream(HOOKS_STREAM).Write([]byte("after"))
+	}()
+
+	if _, err := io.ReadFull(rightMuxer.Stream(HOOKS_STREAM), buf); err != nil {
+		t.Fatal(err)
+	} else if string(buf) != "aft" {
+		t.Fatal("wrong data on hooks stream", string(buf))
+	}
+
+	// Closing one end ends every stream on the other
+	leftMuxer.Close()
+	if b, err := ioutil.ReadAll(rightMuxer.Stream(HOOKS_STREAM)); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "er" {
+		t.Fatal("should've read the rest of the data", string(b))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/mattermost/platform/plugin"
+)
+
+const (
+	SUPERVISOR_START_TIMEOUT = 10 * time.Second
+	SUPERVISOR_STOP_TIMEOUT  = 5 * time.Second
+)
+
+// Supervisor runs a plugin's executable and talks to it over its stdin and stdout. Anything that the plugin writes to
+// stderr is copied to the given writer.
+type Supervisor struct {
+	cmd    *exec.Cmd
+	muxer  *Muxer
+	hooks  *HooksRPCClient
+	exited chan struct{}
+}
+
+func StartSupervisor(executable string, dir string, api plugin.API, stderr io.Writer) (*Supervisor, error) {
+	// The pipes are made here rather than with cmd.StdinPipe and cmd.StdoutPipe so that they aren't closed under the
+	// muxer when the process exits
+	stdinReader, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		stdinReader.Close()
+		stdinWriter.Close()
+		return nil, err
+	}
+
+	cmd := exec.Command(executable)
+	cmd.Dir = dir
+	cmd.Stdin = stdinReader
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderr
+
+	err = cmd.Start()
+	stdinReader.Close()
+	stdoutWriter.Close()
+	if err != nil {
+		stdinWriter.Close()
+		stdoutReader.Close()
+		return nil, err
+	}
+
+	s := &Supervisor{
+		cmd:    cmd,
+		muxer:  NewMuxer(NewReadWriteCloser(stdoutReader, stdinWriter)),
+		exited: make(chan struct{}),
+	}
+
+	go func() {
+		cmd.Wait()
+		close(s.exited)
+	}()
+
+	go ServeAPI(s.muxer.Stream(API_STREAM), api)
+
+	type result struct {
+		hooks *HooksRPCClient
+		err   error
+	}
+	started := make(chan result, 1)
+	go func() {
+		hooks, err := NewHooksRPCClient(s.muxer.Stream(HOOKS_STREAM))
+		started <- result{hooks, err}
+	}()
+
+	select {
+	case r := <-started:
+		if r.err != nil {
+			s.Stop()
+			return nil, r.err
+		}
+		s.hooks = r.hooks
+	case <-time.After(SUPERVISOR_START_TIMEOUT):
+		s.Stop()
+		return nil, errors.New("rpcplugin: timed out waiting for plugin to start")
+	}
+
+	return s, nil
+}
+
+func (s *Supervisor) Hooks() *HooksRPCClient {
+	return s.hooks
+}
+
+// Exited is closed when the plugin's process exits.
+func (s *Supervisor) Exited() <-chan struct{} {
+	return s.exited
+}
+
+// Stop closes the connection to the plugin, which tells it to exit, and kills it if it doesn't exit in time.
+func (s *Supervisor) Stop() {
+	s.muxer.Close()
+
+	select {
+	case <-s.exited:
+	case <-time.After(SUPERVISOR_STOP_TIMEOUT):
+		s.cmd.Process.Kill()
+		<-s.exited
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package rpcplugin
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+const testPluginEnv = "MM_RPCPLUGIN_TEST_PLUGIN"
+
+// The test binary runs itself as the plugin for the supervisor tests
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) == "1" {
+		Main(&testHooks{})
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestSupervisor(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(testPluginEnv, "1")
+	defer os.Unsetenv(testPluginEnv)
+
+	supervisor, err := StartSupervisor(executable, "", &testAPI{}, os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testHooksRPCClient(t, supervisor.Hooks())
+
+	supervisor.Stop()
+
+	select {
+	case <-supervisor.Exited():
+	case <-time.After(time.Second):
+		t.Fatal("plugin should've exited")
+	}
+
+	if _, _, err := supervisor.Hooks().MessageWillBePosted(nil); err == nil {
+		t.Fatal("shouldn't be able to call hooks once the plugin has exited")
+	}
+}
+
+func TestSupervisorNotAPlugin(t *testing.T) {
+	if _, err := StartSupervisor("/nonexistent/plugin", "", &testAPI{}, os.Stderr); err == nil {
+		t.Fatal("shouldn't have started")
+	}
+
+	// This exits straight away without serving any hooks
+	if _, err := StartSupervisor("/bin/true", "", &testAPI{}, os.Stderr); err == nil {
+		t.Fatal("shouldn't have started")
+	}
+}
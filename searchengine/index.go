@@ -0,0 +1,215 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package searchengine
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzers/custom_analyzer"
+	"github.com/blevesearch/bleve/analysis/analyzers/keyword_analyzer"
+	"github.com/blevesearch/bleve/analysis/token_filters/lower_case_filter"
+	"github.com/blevesearch/bleve/analysis/tokenizers/regexp_tokenizer"
+)
+
+const (
+	// the analyzer that splits text into the lower case words that are stored in and looked up from an index
+	WORDS_ANALYZER  = "words"
+	WORDS_TOKENIZER = "words"
+	WORD_PATTERN    = `[\pL\d_]+`
+)
+
+var indexWord = regexp.MustCompile(WORD_PATTERN)
+
+// tokenize splits text into words in the same way as the words analyzer so that searches can tell which parts of a
+// search term can be matched.
+func tokenize(text string) []string {
+	return indexWord.FindAllString(strings.ToLower(text), -1)
+}
+
+// postDocument is the part of a post that's stored in the posts index.
+type postDocument struct {
+	Message   string   `json:"message"`
+	Hashtags  []string `json:"hashtags"`
+	ChannelId string   `json:"channel_id"`
+	UserId    string   `json:"user_id"`
+	CreateAt  int64    `json:"create_at"`
+}
+
+// channelDocument is the part of a channel that's stored in the channels index. Words contains both the name and the
+// display name of the channel.
+type channelDocument struct {
+	TeamId   string `json:"team_id"`
+	Words    string `json:"words"`
+	CreateAt int64  `json:"create_at"`
+}
+
+// userDocument is the part of a user that's stored in the users index. Words contains every name and the email
+// address of the user.
+type userDocument struct {
+	TeamIds  []string `json:"team_ids"`
+	Words    string   `json:"words"`
+	CreateAt int64    `json:"create_at"`
+}
+
+func newPostsMapping() (*bleve.IndexMapping, error) {
+	document := bleve.NewDocumentStaticMapping()
+	document.AddFieldMappingsAt("message", wordsFieldMapping(true))
+	document.AddFieldMappingsAt("hashtags", keywordFieldMapping())
+	document.AddFieldMappingsAt("channel_id", keywordFieldMapping())
+	document.AddFieldMappingsAt("user_id", keywordFieldMapping())
+	document.AddFieldMappingsAt("create_at", numericFieldMapping())
+
+	return newIndexMapping(document)
+}
+
+func newChannelsMapping() (*bleve.IndexMapping, error) {
+	document := bleve.NewDocumentStaticMapping()
+	document.AddFieldMappingsAt("team_id", keywordFieldMapping())
+	document.AddFieldMappingsAt("words", wordsFieldMapping(false))
+	document.AddFieldMappingsAt("create_at", numericFieldMapping())
+
+	return newIndexMapping(document)
+}
+
+func newUsersMapping() (*bleve.IndexMapping, error) {
+	document := bleve.NewDocumentStaticMapping()
+	document.AddFieldMappingsAt("team_ids", keywordFieldMapping())
+	document.AddFieldMappingsAt("words", wordsFieldMapping(false))
+	document.AddFieldMappingsAt("create_at", numericFieldMapping())
+
+	return newIndexMapping(document)
+}
+
+func newIndexMapping(document *bleve.DocumentMapping) (*bleve.IndexMapping, error) {
+	mapping := bleve.NewIndexMapping()
+
+	if err := mapping.AddCustomTokenizer(WORDS_TOKENIZER, map[string]interface{}{
+		"type":   regexp_tokenizer.Name,
+		"regexp": WORD_PATTERN,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := mapping.AddCustomAnalyzer(WORDS_ANALYZER, map[string]interface{}{
+		"type":          custom_analyzer.Name,
+		"tokenizer":     WORDS_TOKENIZER,
+		"token_filters": []string{lower_case_filter.Name},
+	}); err != nil {
+		return nil, err
+	}
+
+	mapping.DefaultAnalyzer = WORDS_ANALYZER
+	mapping.DefaultMapping = document
+
+	return mapping, nil
+}
+
+// wordsFieldMapping is used for text that's searched for by word. Term vectors are only needed for phrase searches.
+func wordsFieldMapping(termVectors bool) *bleve.FieldMapping {
+	field := bleve.NewTextFieldMapping()
+	field.Analyzer = WORDS_ANALYZER
+	field.Store = false
+	field.IncludeInAll = false
+	field.IncludeTermVectors = termVectors
+
+	return field
+}
+
+// keywordFieldMapping is used for ids and other values that are only matched in full.
+func keywordFieldMapping() *bleve.FieldMapping {
+	field := bleve.NewTextFieldMapping()
+	field.Analyzer = keyword_analyzer.Name
+	field.Store = false
+	field.IncludeInAll = false
+	field.IncludeTermVectors = false
+
+	return field
+}
+
+func numericFieldMapping() *bleve.FieldMapping {
+	field := bleve.NewNumericFieldMapping()
+	field.Store = false
+	field.IncludeInAll = false
+
+	return field
+}
+
+// openIndex opens the index stored at path, creating it with the given mapping if it doesn't exist yet.
+func openIndex(path string, newMapping func() (*bleve.IndexMapping, error)) (bleve.Index, error) {
+	if _, err := os.Stat(path); err == nil {
+		return bleve.Open(path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	mapping, err := newMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	return bleve.New(path, mapping)
+}
+
+// filterQuery matches documents where the given field has any of the given values. It doesn't affect how the matching
+// documents are scored.
+func filterQuery(field string, values []string) bleve.Query {
+	queries := make([]bleve.Query, len(values))
+	for i, value := range values {
+		queries[i] = bleve.NewTermQuery(value).SetField(field).SetBoost(0)
+	}
+
+	return bleve.NewDisjunctionQuery(queries)
+}
+
+// createAtQuery matches documents created in the given range. Either end can be left open by passing zero.
+func createAtQuery(start int64, end int64, endInclusive bool) bleve.Query {
+	var min, max *float64
+	if start != 0 {
+		value := float64(start)
+		min = &value
+	}
+	if end != 0 {
+		value := float64(end)
+		max = &value
+	}
+
+	minInclusive := true
+	return bleve.NewNumericRangeInclusiveQuery(min, max, &minInclusive, &endInclusive).SetField("create_at").SetBoost(0)
+}
+
+// wordsQuery matches documents containing every one of the given words in field. If lastIsPrefix is set, the last word
+// matches any word that starts with it.
+func wordsQuery(field string, words []string, lastIsPrefix bool) bleve.Query {
+	queries := make([]bleve.Query, len(words))
+	for i, word := range words {
+		if lastIsPrefix && i == len(words)-1 {
+			queries[i] = bleve.NewPrefixQuery(word).SetField(field)
+		} else {
+			queries[i] = bleve.NewTermQuery(word).SetField(field)
+		}
+	}
+
+	return bleve.NewConjunctionQuery(queries)
+}
+
+// searchIds returns the ids of up to limit documents matching the query in the given order.
+func searchIds(idx bleve.Index, query bleve.Query, order []string, limit int) ([]string, error) {
+	request := bleve.NewSearchRequestOptions(query, limit, 0, false)
+	request.SortBy(order)
+
+	result, err := idx.Search(request)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = hit.ID
+	}
+
+	return ids, nil
+}
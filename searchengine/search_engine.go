@@ -0,0 +1,397 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package searchengine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	l4g "github.com/alecthomas/log4go"
+	"github.com/blevesearch/bleve"
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+const (
+	POSTS_INDEX_DIR    = "posts.bleve"
+	CHANNELS_INDEX_DIR = "channels.bleve"
+	USERS_INDEX_DIR    = "users.bleve"
+
+	POST_SEARCH_LIMIT    = 100
+	CHANNEL_SEARCH_LIMIT = 100
+	USER_SEARCH_LIMIT    = 100
+)
+
+// EmbeddedSearchEngine keeps Bleve indexes of posts, channels and users on disk in the directory given by
+// SearchEngineSettings.IndexDir. Only the terms needed to find a document are indexed, so search results are looked up
+// from the database by id.
+//
+// Each server in a cluster keeps its own index, so changes made on one server are sent to the others by the app layer.
+type EmbeddedSearchEngine struct {
+	mutex    sync.RWMutex // held while the indexes are opened or closed
+	posts    bleve.Index
+	channels bleve.Index
+	users    bleve.Index
+	indexDir string
+}
+
+// storedIndex describes where an index is stored and how it's created.
+type storedIndex struct {
+	name       string
+	newMapping func() (*bleve.IndexMapping, error)
+}
+
+var storedIndexes = []storedIndex{
+	{name: POSTS_INDEX_DIR, newMapping: newPostsMapping},
+	{name: CHANNELS_INDEX_DIR, newMapping: newChannelsMapping},
+	{name: USERS_INDEX_DIR, newMapping: newUsersMapping},
+}
+
+func init() {
+	einterfaces.RegisterSearchEngineInterface(NewEmbeddedSearchEngine())
+}
+
+func NewEmbeddedSearchEngine() *EmbeddedSearchEngine {
+	return &EmbeddedSearchEngine{}
+}
+
+func (se *EmbeddedSearchEngine) IsIndexingEnabled() bool {
+	return *utils.Cfg.SearchEngineSettings.EnableIndexing
+}
+
+func (se *EmbeddedSearchEngine) IsSearchEnabled() bool {
+	return *utils.Cfg.SearchEngineSettings.EnableIndexing && *utils.Cfg.SearchEngineSettings.EnableSearching
+}
+
+func (se *EmbeddedSearchEngine) Start() *model.AppError {
+	if !se.IsIndexingEnabled() {
+		return nil
+	}
+
+	se.mutex.Lock()
+	defer se.mutex.Unlock()
+
+	if se.indexDir != "" {
+		return nil
+	}
+
+	dir := *utils.Cfg.SearchEngineSettings.IndexDir
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return model.NewLocAppError("EmbeddedSearchEngine.Start", "searchengine.start.create_dir.app_error", nil, "dir="+dir+", err="+err.Error())
+	}
+
+	if err := se.open(dir); err != nil {
+		return err
+	}
+
+	l4g.Info(utils.T("searchengine.start.info"), dir)
+
+	return nil
+}
+
+// open opens every index stored in dir. It must be called with the mutex locked.
+func (se *EmbeddedSearchEngine) open(dir string) *model.AppError {
+	indexes := make([]bleve.Index, len(storedIndexes))
+
+	for i, d := range storedIndexes {
+		idx, err := openIndex(filepath.Join(dir, d.name), d.newMapping)
+		if err != nil {
+			for _, opened := range indexes[:i] {
+				opened.Close()
+			}
+
+			return model.NewLocAppError("EmbeddedSearchEngine.Start", "searchengine.start.load.app_error", nil, "dir="+d.name+", err="+err.Error())
+		}
+
+		indexes[i] = idx
+	}
+
+	se.posts = indexes[0]
+	se.channels = indexes[1]
+	se.users = indexes[2]
+	se.indexDir = dir
+
+	return nil
+}
+
+// close closes every open index. It must be called with the mutex locked.
+func (se *EmbeddedSearchEngine) close() *model.AppError {
+	var closeErr error
+	for _, idx := range []bleve.Index{se.posts, se.channels, se.users} {
+		if err := idx.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	se.posts = nil
+	se.channels = nil
+	se.users = nil
+	se.indexDir = ""
+
+	if closeErr != nil {
+		return model.NewLocAppError("EmbeddedSearchEngine.Stop", "searchengine.stop.app_error", nil, closeErr.Error())
+	}
+
+	return nil
+}
+
+func (se *EmbeddedSearchEngine) Stop() *model.AppError {
+	se.mutex.Lock()
+	defer se.mutex.Unlock()
+
+	if se.indexDir == "" {
+		return nil
+	}
+
+	return se.close()
+}
+
+// getIndex returns one of the indexes once the engine has been started. Bleve indexes can be used concurrently, so
+// the index is used without the mutex being held.
+func (se *EmbeddedSearchEngine) getIndex(where string, field *bleve.Index) (bleve.Index, *model.AppError) {
+	se.mutex.RLock()
+	defer se.mutex.RUnlock()
+
+	if idx := *field; idx != nil {
+		return idx, nil
+	}
+
+	return nil, model.NewLocAppError(where, "searchengine.not_started.app_error", nil, "")
+}
+
+func (se *EmbeddedSearchEngine) index(where string, field *bleve.Index, id string, doc interface{}) *model.AppError {
+	idx, appErr := se.getIndex(where, field)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := idx.Index(id, doc); err != nil {
+		return model.NewLocAppError(where, "searchengine.index.app_error", nil, "id="+id+", err="+err.Error())
+	}
+
+	return nil
+}
+
+func (se *EmbeddedSearchEngine) delete(where string, field *bleve.Index, id string) *model.AppError {
+	idx, appErr := se.getIndex(where, field)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := idx.Delete(id); err != nil {
+		return model.NewLocAppError(where, "searchengine.delete.app_error", nil, "id="+id+", err="+err.Error())
+	}
+
+	return nil
+}
+
+func (se *EmbeddedSearchEngine) search(where string, field *bleve.Index, query bleve.Query, order []string, limit int) ([]string, *model.AppError) {
+	idx, appErr := se.getIndex(where, field)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	ids, err := searchIds(idx, query, order, limit)
+	if err != nil {
+		return nil, model.NewLocAppError(where, "searchengine.search.app_error", nil, err.Error())
+	}
+
+	return ids, nil
+}
+
+func (se *EmbeddedSearchEngine) IndexPost(post *model.Post) *model.AppError {
+	hashtags := []string{}
+	for _, hashtag := range strings.Fields(post.Hashtags) {
+		hashtags = append(hashtags, strings.ToLower(hashtag))
+	}
+
+	doc := &postDocument{
+		Message:   post.Message,
+		Hashtags:  hashtags,
+		ChannelId: post.ChannelId,
+		UserId:    post.UserId,
+		CreateAt:  post.CreateAt,
+	}
+
+	return se.index("EmbeddedSearchEngine.IndexPost", &se.posts, post.Id, doc)
+}
+
+// SearchPosts returns the ids of up to POST_SEARCH_LIMIT posts in the given channels that match the search
+// parameters ordered by relevance. If fromUserIds isn't empty, only posts made by those users are returned.
+func (se *EmbeddedSearchEngine) SearchPosts(channelIds []string, fromUserIds []string, params *model.SearchParams) ([]string, *model.AppError) {
+	if !se.IsSearchEnabled() {
+		return nil, model.NewLocAppError("EmbeddedSearchEngine.SearchPosts", "searchengine.search.disabled.app_error", nil, "")
+	}
+
+	if len(channelIds) == 0 {
+		return []string{}, nil
+	}
+
+	termQueries := []bleve.Query{}
+
+	for _, term := range params.SplitTerms() {
+		if params.IsHashtag {
+			termQueries = append(termQueries, bleve.NewTermQuery(strings.ToLower(term)).SetField("hashtags"))
+		} else if len(term) > 1 && strings.HasPrefix(term, "\"") && strings.HasSuffix(term, "\"") {
+			if len(tokenize(term)) == 0 {
+				continue
+			}
+
+			termQueries = append(termQueries, bleve.NewMatchPhraseQuery(term[1:len(term)-1]).SetField("message"))
+		} else {
+			words := tokenize(term)
+			if len(words) == 0 {
+				continue
+			}
+
+			termQueries = append(termQueries, wordsQuery("message", words, strings.HasSuffix(term, "*")))
+		}
+	}
+
+	if len(termQueries) == 0 && params.Terms != "" {
+		// the search terms only contained characters that can't be searched for
+		return []string{}, nil
+	}
+
+	queries := []bleve.Query{filterQuery("channel_id", channelIds)}
+
+	if len(fromUserIds) > 0 {
+		queries = append(queries, filterQuery("user_id", fromUserIds))
+	}
+
+	if afterTime := params.GetAfterDateMillis(); afterTime != 0 {
+		queries = append(queries, createAtQuery(afterTime, 0, false))
+	}
+
+	if beforeTime := params.GetBeforeDateMillis(); beforeTime != 0 {
+		queries = append(queries, createAtQuery(0, beforeTime, false))
+	}
+
+	if onStart, onEnd := params.GetOnDateMillis(); onStart != 0 {
+		queries = append(queries, createAtQuery(onStart, onEnd, true))
+	}
+
+	order := []string{"-create_at"}
+
+	if len(termQueries) > 0 {
+		if params.OrTerms {
+			queries = append(queries, bleve.NewDisjunctionQuery(termQueries))
+		} else {
+			queries = append(queries, termQueries...)
+		}
+
+		order = []string{"-_score", "-create_at"}
+	}
+
+	return se.search("EmbeddedSearchEngine.SearchPosts", &se.posts, bleve.NewConjunctionQuery(queries), order, POST_SEARCH_LIMIT)
+}
+
+func (se *EmbeddedSearchEngine) DeletePost(postId string) *model.AppError {
+	return se.delete("EmbeddedSearchEngine.DeletePost", &se.posts, postId)
+}
+
+// IndexChannel adds an open channel to the index so that it can be found by users that aren't a member of it. Other
+// types of channels are removed from the index.
+func (se *EmbeddedSearchEngine) IndexChannel(channel *model.Channel) *model.AppError {
+	if channel.Type != model.CHANNEL_OPEN || channel.DeleteAt != 0 {
+		return se.DeleteChannel(channel.Id)
+	}
+
+	doc := &channelDocument{
+		TeamId:   channel.TeamId,
+		Words:    channel.Name + " " + channel.DisplayName,
+		CreateAt: channel.CreateAt,
+	}
+
+	return se.index("EmbeddedSearchEngine.IndexChannel", &se.channels, channel.Id, doc)
+}
+
+// SearchChannels returns the ids of open channels on the given team with a name or display name containing words that
+// start with every word in the search term.
+func (se *EmbeddedSearchEngine) SearchChannels(teamId string, term string) ([]string, *model.AppError) {
+	if !se.IsSearchEnabled() {
+		return nil, model.NewLocAppError("EmbeddedSearchEngine.SearchChannels", "searchengine.search.disabled.app_error", nil, "")
+	}
+
+	words := tokenize(term)
+	if len(words) == 0 {
+		return []string{}, nil
+	}
+
+	return se.search("EmbeddedSearchEngine.SearchChannels", &se.channels, prefixSearchQuery("team_id", teamId, words), []string{"-create_at"}, CHANNEL_SEARCH_LIMIT)
+}
+
+func (se *EmbeddedSearchEngine) DeleteChannel(channelId string) *model.AppError {
+	return se.delete("EmbeddedSearchEngine.DeleteChannel", &se.channels, channelId)
+}
+
+func (se *EmbeddedSearchEngine) IndexUser(user *model.User, teamIds []string) *model.AppError {
+	doc := &userDocument{
+		TeamIds:  teamIds,
+		Words:    strings.Join([]string{user.Username, user.Nickname, user.FirstName, user.LastName, user.Email}, " "),
+		CreateAt: user.CreateAt,
+	}
+
+	return se.index("EmbeddedSearchEngine.IndexUser", &se.users, user.Id, doc)
+}
+
+// SearchUsersInTeam returns the ids of users on the given team, or on any team if teamId is empty, with a username,
+// nickname, full name or email containing words that start with every word in the search term.
+func (se *EmbeddedSearchEngine) SearchUsersInTeam(teamId string, term string) ([]string, *model.AppError) {
+	if !se.IsSearchEnabled() {
+		return nil, model.NewLocAppError("EmbeddedSearchEngine.SearchUsersInTeam", "searchengine.search.disabled.app_error", nil, "")
+	}
+
+	words := tokenize(term)
+	if len(words) == 0 {
+		return []string{}, nil
+	}
+
+	return se.search("EmbeddedSearchEngine.SearchUsersInTeam", &se.users, prefixSearchQuery("team_ids", teamId, words), []string{"-create_at"}, USER_SEARCH_LIMIT)
+}
+
+func (se *EmbeddedSearchEngine) DeleteUser(userId string) *model.AppError {
+	return se.delete("EmbeddedSearchEngine.DeleteUser", &se.users, userId)
+}
+
+// prefixSearchQuery matches documents on the given team, or on any team if teamId is empty, containing words that start
+// with every one of the given words.
+func prefixSearchQuery(teamField string, teamId string, words []string) bleve.Query {
+	queries := []bleve.Query{}
+	for _, word := range words {
+		queries = append(queries, bleve.NewPrefixQuery(word).SetField("words"))
+	}
+
+	if teamId != "" {
+		queries = append(queries, filterQuery(teamField, []string{teamId}))
+	}
+
+	return bleve.NewConjunctionQuery(queries)
+}
+
+// PurgeIndexes removes every document from the indexes by deleting them and creating them again.
+func (se *EmbeddedSearchEngine) PurgeIndexes() *model.AppError {
+	se.mutex.Lock()
+	defer se.mutex.Unlock()
+
+	dir := se.indexDir
+	if dir == "" {
+		return nil
+	}
+
+	if err := se.close(); err != nil {
+		return err
+	}
+
+	for _, d := range storedIndexes {
+		if err := os.RemoveAll(filepath.Join(dir, d.name)); err != nil {
+			return model.NewLocAppError("EmbeddedSearchEngine.PurgeIndexes", "searchengine.purge.app_error", nil, "dir="+d.name+", err="+err.Error())
+		}
+	}
+
+	return se.open(dir)
+}
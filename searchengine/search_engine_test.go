@@ -0,0 +1,260 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package searchengine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+func setupSearchEngine(t *testing.T) (*EmbeddedSearchEngine, func()) {
+	utils.TranslationsPreInit()
+	utils.LoadTestConfig()
+	utils.InitTranslations(utils.Cfg.LocalizationSettings)
+
+	dir, err := ioutil.TempDir("", "searchindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enableIndexing := *utils.Cfg.SearchEngineSettings.EnableIndexing
+	enableSearching := *utils.Cfg.SearchEngineSettings.EnableSearching
+	indexDir := *utils.Cfg.SearchEngineSettings.IndexDir
+
+	*utils.Cfg.SearchEngineSettings.EnableIndexing = true
+	*utils.Cfg.SearchEngineSettings.EnableSearching = true
+	*utils.Cfg.SearchEngineSettings.IndexDir = dir
+
+	se := NewEmbeddedSearchEngine()
+	if err := se.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	return se, func() {
+		se.Stop()
+
+		*utils.Cfg.SearchEngineSettings.EnableIndexing = enableIndexing
+		*utils.Cfg.SearchEngineSettings.EnableSearching = enableSearching
+		*utils.Cfg.SearchEngineSettings.IndexDir = indexDir
+
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSearchPosts(t *testing.T) {
+	se, teardown := setupSearchEngine(t)
+	defer teardown()
+
+	channelId := model.NewId()
+	otherChannelId := model.NewId()
+	userId := model.NewId()
+	day := time.Date(2017, time.March, 4, 12, 0, 0, 0, time.UTC)
+
+	p1 := &model.Post{Id: model.NewId(), ChannelId: channelId, UserId: userId, Message: "corey mattermost new york", CreateAt: model.GetMillisForTime(day)}
+	p2 := &model.Post{Id: model.NewId(), ChannelId: channelId, UserId: model.NewId(), Message: "New Jersey is where John is from", CreateAt: model.GetMillisForTime(day.AddDate(0, 0, 1))}
+	p3 := &model.Post{Id: model.NewId(), ChannelId: otherChannelId, UserId: userId, Message: "new york", CreateAt: model.GetMillisForTime(day)}
+	p4 := &model.Post{Id: model.NewId(), ChannelId: channelId, UserId: userId, Message: "the york stadium is new new new", Hashtags: "#stadium", CreateAt: model.GetMillisForTime(day.AddDate(0, 0, 2))}
+
+	for _, post := range []*model.Post{p1, p2, p3, p4} {
+		if err := se.IndexPost(post); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	channelIds := []string{channelId}
+
+	search := func(params *model.SearchParams, fromUserIds ...string) []string {
+		ids, err := se.SearchPosts(channelIds, fromUserIds, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ids
+	}
+
+	if ids := search(&model.SearchParams{Terms: "york"}); len(ids) != 2 {
+		t.Fatal("should've only found posts in the given channels", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "new"}); len(ids) != 3 || ids[0] != p4.Id {
+		t.Fatal("should've ranked the post mentioning the term the most first", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "\"new york\""}); len(ids) != 1 || ids[0] != p1.Id {
+		t.Fatal("should've only found the exact phrase", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "jer* john"}); len(ids) != 1 || ids[0] != p2.Id {
+		t.Fatal("should've matched the prefix", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "corey jersey"}); len(ids) != 0 {
+		t.Fatal("should've required every term", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "corey jersey", OrTerms: true}); len(ids) != 2 {
+		t.Fatal("should've matched either term", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "#stadium", IsHashtag: true}); len(ids) != 1 || ids[0] != p4.Id {
+		t.Fatal("should've found the hashtag", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "new"}, userId); len(ids) != 2 {
+		t.Fatal("should've only found posts from the given user", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "new", OnDate: "2017-03-05"}); len(ids) != 1 || ids[0] != p2.Id {
+		t.Fatal("should've only found posts on the given date", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "new", AfterDate: "2017-03-04", BeforeDate: "2017-03-06"}); len(ids) != 1 || ids[0] != p2.Id {
+		t.Fatal("should've only found posts between the given dates", ids)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "", IsHashtag: true, BeforeDate: "2017-03-05"}); len(ids) != 1 || ids[0] != p1.Id {
+		t.Fatal("should've found posts by date without any terms", ids)
+	}
+
+	p1.Message = "edited"
+	if err := se.IndexPost(p1); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "corey"}); len(ids) != 0 {
+		t.Fatal("should've removed old terms when reindexing a post", ids)
+	}
+
+	if err := se.DeletePost(p2.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids := search(&model.SearchParams{Terms: "john"}); len(ids) != 0 {
+		t.Fatal("should've removed the deleted post", ids)
+	}
+}
+
+func TestSearchChannelsAndUsers(t *testing.T) {
+	se, teardown := setupSearchEngine(t)
+	defer teardown()
+
+	teamId := model.NewId()
+
+	c1 := &model.Channel{Id: model.NewId(), TeamId: teamId, Name: "off-topic", DisplayName: "Off-Topic", Type: model.CHANNEL_OPEN}
+	c2 := &model.Channel{Id: model.NewId(), TeamId: teamId, Name: "off-the-record", DisplayName: "Off the Record", Type: model.CHANNEL_PRIVATE}
+	c3 := &model.Channel{Id: model.NewId(), TeamId: model.NewId(), Name: "off-topic", DisplayName: "Off-Topic", Type: model.CHANNEL_OPEN}
+
+	for _, channel := range []*model.Channel{c1, c2, c3} {
+		if err := se.IndexChannel(channel); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ids, err := se.SearchChannels(teamId, "off"); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 1 || ids[0] != c1.Id {
+		t.Fatal("should've only found open channels on the team", ids)
+	}
+
+	if ids, err := se.SearchChannels(teamId, "off top"); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 1 || ids[0] != c1.Id {
+		t.Fatal("should've matched the start of every word", ids)
+	}
+
+	u1 := &model.User{Id: model.NewId(), Username: "john.doe", FirstName: "John", LastName: "Doe", Email: "jdoe@example.com"}
+	u2 := &model.User{Id: model.NewId(), Username: "jane", Nickname: "jdoe"}
+
+	if err := se.IndexUser(u1, []string{teamId}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := se.IndexUser(u2, []string{model.NewId()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, err := se.SearchUsersInTeam(teamId, "jdo"); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 1 || ids[0] != u1.Id {
+		t.Fatal("should've only found users on the team", ids)
+	}
+
+	if ids, err := se.SearchUsersInTeam("", "jdo"); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 2 {
+		t.Fatal("should've found users on any team", ids)
+	}
+
+	if err := se.DeleteUser(u1.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, err := se.SearchUsersInTeam(teamId, "john"); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 0 {
+		t.Fatal("should've removed the deleted user", ids)
+	}
+}
+
+func TestSearchEngineSavesIndex(t *testing.T) {
+	se, teardown := setupSearchEngine(t)
+	defer teardown()
+
+	post := &model.Post{Id: model.NewId(), ChannelId: model.NewId(), Message: "persistent"}
+	if err := se.IndexPost(post); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := se.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewEmbeddedSearchEngine()
+	if err := restarted.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Stop()
+
+	if ids, err := restarted.SearchPosts([]string{post.ChannelId}, nil, &model.SearchParams{Terms: "persistent"}); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 1 || ids[0] != post.Id {
+		t.Fatal("should've loaded the saved index")
+	}
+
+	if err := restarted.PurgeIndexes(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, err := restarted.SearchPosts([]string{post.ChannelId}, nil, &model.SearchParams{Terms: "persistent"}); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 0 {
+		t.Fatal("should've purged the index")
+	}
+}
+
+func TestSearchEngineStoresIndexOnDisk(t *testing.T) {
+	se, teardown := setupSearchEngine(t)
+	defer teardown()
+
+	for _, d := range storedIndexes {
+		if info, err := os.Stat(filepath.Join(se.indexDir, d.name)); err != nil {
+			t.Fatal(err)
+		} else if !info.IsDir() {
+			t.Fatal("should've stored the index in a directory", d.name)
+		}
+	}
+
+	if err := se.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := se.IndexPost(&model.Post{Id: model.NewId(), ChannelId: model.NewId(), Message: "stopped"}); err == nil {
+		t.Fatal("shouldn't be able to index a post once stopped")
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+// layer_generators writes the store layers that wrap every method of the Store interface. Run it with
+// "go generate ./store" after changing store.go.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+var (
+	inputFile  = flag.String("in", "store.go", "the file that defines the Store interface")
+	outputFile = flag.String("out", "timer_layer_generated.go", "the file to write the timer layer to")
+)
+
+// reservedNames are used by the generated code, so parameters with these names are renamed.
+var reservedNames = map[string]bool{
+	"s":    true,
+	"time": true,
+}
+
+type param struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+type method struct {
+	Name   string
+	Params []param
+}
+
+type subStore struct {
+	Accessor string
+	Name     string
+	Methods  []method
+}
+
+func main() {
+	flag.Parse()
+
+	subStores, err := parseStores(*inputFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code, err := generateTimerLayer(subStores)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*outputFile, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func typeString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+// parseStores returns each store returned by an accessor on the Store interface along with its methods that return a
+// StoreChannel. The other methods are passed through to the wrapped store without being timed.
+func parseStores(fileName string) ([]*subStore, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := map[string]*ast.InterfaceType{}
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+				interfaces[typeSpec.Name.Name] = iface
+			}
+		}
+	}
+
+	root, ok := interfaces["Store"]
+	if !ok {
+		return nil, fmt.Errorf("unable to find the Store interface in %v", fileName)
+	}
+
+	subStores := []*subStore{}
+	for _, field := range root.Methods.List {
+		funcType, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) != 1 || funcType.Results == nil || len(funcType.Results.List) != 1 {
+			continue
+		}
+
+		storeName := typeString(fset, funcType.Results.List[0].Type)
+		iface, ok := interfaces[storeName]
+		if !ok {
+			continue
+		}
+
+		subStore := &subStore{Accessor: field.Names[0].Name, Name: storeName}
+
+		for _, methodField := range iface.Methods.List {
+			methodType := methodField.Type.(*ast.FuncType)
+			if methodType.Results == nil || len(methodType.Results.List) != 1 || typeString(fset, methodType.Results.List[0].Type) != "StoreChannel" {
+				continue
+			}
+
+			m := method{Name: methodField.Names[0].Name}
+			for _, paramField := range methodType.Params.List {
+				paramType := paramField.Type
+				variadic := false
+				if ellipsis, ok := paramType.(*ast.Ellipsis); ok {
+					paramType = ellipsis.Elt
+					variadic = true
+				}
+
+				names := paramField.Names
+				if len(names) == 0 {
+					names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("param%v", len(m.Params)))}
+				}
+
+				for _, name := range names {
+					paramName := name.Name
+					if reservedNames[paramName] {
+						paramName += "Param"
+					}
+
+					m.Params = append(m.Params, param{Name: paramName, Type: typeString(fset, paramType), Variadic: variadic})
+				}
+			}
+
+			subStore.Methods = append(subStore.Methods, m)
+		}
+
+		sort.Slice(subStore.Methods, func(i, j int) bool { return subStore.Methods[i].Name < subStore.Methods[j].Name })
+		subStores = append(subStores, subStore)
+	}
+
+	return subStores, nil
+}
+
+var timerLayerTemplate = template.Must(template.New("timer_layer").Funcs(template.FuncMap{
+	"joinParams": func(params []param) string {
+		parts := []string{}
+		for _, p := range params {
+			if p.Variadic {
+				parts = append(parts, p.Name+" ..."+p.Type)
+			} else {
+				parts = append(parts, p.Name+" "+p.Type)
+			}
+		}
+		return strings.Join(parts, ", ")
+	},
+	"joinArgs": func(params []param) string {
+		parts := []string{}
+		for _, p := range params {
+			if p.Variadic {
+				parts = append(parts, p.Name+"...")
+			} else {
+				parts = append(parts, p.Name)
+			}
+		}
+		return strings.Join(parts, ", ")
+	},
+}).Parse(`// Code generated by layer_generators; DO NOT EDIT.
+
+package store
+
+import (
+	"time"
+{{if .UsesModel}}
+	"github.com/mattermost/platform/model"
+{{- end}}
+)
+
+// TimerLayer wraps a Store to report how long each call to it takes.
+type TimerLayer struct {
+	Store
+{{- range .SubStores}}
+	{{.Name}} TimerLayer{{.Name}}
+{{- end}}
+}
+
+{{range .SubStores}}
+func (s *TimerLayer) {{.Accessor}}() {{.Name}} {
+	return &s.{{.Name}}
+}
+{{end}}
+
+{{range $store := .SubStores}}
+type TimerLayer{{$store.Name}} struct {
+	{{$store.Name}}
+	Root *TimerLayer
+}
+{{range $store.Methods}}
+func (s *TimerLayer{{$store.Name}}) {{.Name}}({{joinParams .Params}}) StoreChannel {
+	return s.Root.time("{{$store.Name}}.{{.Name}}", time.Now(), s.{{$store.Name}}.{{.Name}}({{joinArgs .Params}}))
+}
+{{end}}
+{{end}}
+
+func NewTimerLayer(childStore Store) *TimerLayer {
+	newStore := &TimerLayer{
+		Store: childStore,
+	}
+{{range .SubStores}}
+	newStore.{{.Name}} = TimerLayer{{.Name}}{ {{- .Name}}: childStore.{{.Accessor}}(), Root: newStore}
+{{- end}}
+
+	return newStore
+}
+`))
+
+func generateTimerLayer(subStores []*subStore) ([]byte, error) {
+	var buf bytes.Buffer
+
+	usesModel := false
+	for _, subStore := range subStores {
+		for _, m := range subStore.Methods {
+			for _, p := range m.Params {
+				usesModel = usesModel || strings.Contains(p.Type, "model.")
+			}
+		}
+	}
+
+	if err := timerLayerTemplate.Execute(&buf, map[string]interface{}{
+		"UsesModel": usesModel,
+		"SubStores": subStores,
+	}); err != nil {
+		return nil, err
+	}
+
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path.Base(*outputFile), err)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlBotStore struct {
+	*SqlStore
+}
+
+func NewSqlBotStore(sqlStore *SqlStore) BotStore {
+	s := &SqlBotStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Bot{}, "Bots").SetKeys(false, "UserId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Description").SetMaxSize(model.BOT_DESCRIPTION_MAX_RUNES)
+		table.ColMap("OwnerId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlBotStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_bots_owner_id", "Bots", "OwnerId")
+}
+
+func (s SqlBotStore) Save(bot *model.Bot) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		bot.PreSave()
+		if result.Err = bot.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(bot); err != nil {
+			result.Err = model.NewLocAppError("SqlBotStore.Save", "store.sql_bot.save.app_error", nil, "user_id="+bot.UserId+", "+err.Error())
+		} else {
+			result.Data = bot
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) Update(bot *model.Bot) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		bot.PreUpdate()
+		if result.Err = bot.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(bot); err != nil {
+			result.Err = model.NewLocAppError("SqlBotStore.Update", "store.sql_bot.update.app_error", nil, "user_id="+bot.UserId+", "+err.Error())
+		} else if count != 1 {
+			result.Err = model.NewLocAppError("SqlBotStore.Update", "store.sql_bot.update.app_error", nil, "user_id="+bot.UserId)
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = bot
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) Get(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var bot *model.Bot
+
+		if err := s.GetReplica().SelectOne(&bot, "SELECT * FROM Bots WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlBotStore.Get", "store.sql_bot.get.app_error", nil, "user_id="+userId+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = bot
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) GetByOwner(ownerId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var bots []*model.Bot
+
+		if _, err := s.GetReplica().Select(&bots, "SELECT * FROM Bots WHERE OwnerId = :OwnerId ORDER BY CreateAt, UserId", map[string]interface{}{"OwnerId": ownerId}); err != nil {
+			result.Err = model.NewLocAppError("SqlBotStore.GetByOwner", "store.sql_bot.get_by_owner.app_error", nil, "owner_id="+ownerId+", "+err.Error())
+		} else {
+			result.Data = bots
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) GetAll() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var bots []*model.Bot
+
+		if _, err := s.GetReplica().Select(&bots, "SELECT * FROM Bots ORDER BY CreateAt, UserId"); err != nil {
+			result.Err = model.NewLocAppError("SqlBotStore.GetAll", "store.sql_bot.get_all.app_error", nil, err.Error())
+		} else {
+			result.Data = bots
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlBotStore) PermanentDelete(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM Bots WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlBotStore.PermanentDelete", "store.sql_bot.permanent_delete.app_error", nil, "user_id="+userId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestBotStore(t *testing.T) {
+	Setup()
+
+	ownerId := model.NewId()
+
+	bot1 := Must(store.Bot().Save(&model.Bot{UserId: model.NewId(), OwnerId: ownerId, Description: "first"})).(*model.Bot)
+	bot2 := Must(store.Bot().Save(&model.Bot{UserId: model.NewId(), OwnerId: ownerId})).(*model.Bot)
+	bot3 := Must(store.Bot().Save(&model.Bot{UserId: model.NewId()})).(*model.Bot)
+	defer store.Bot().PermanentDelete(bot2.UserId)
+	defer store.Bot().PermanentDelete(bot3.UserId)
+
+	if result := <-store.Bot().Save(&model.Bot{UserId: bot1.UserId}); result.Err == nil {
+		t.Fatal("shouldn't have saved the same bot twice")
+	}
+
+	if bot := Must(store.Bot().Get(bot1.UserId)).(*model.Bot); bot.OwnerId != ownerId || bot.Description != "first" {
+		t.Fatal("should've gotten the bot")
+	}
+
+	if bots := Must(store.Bot().GetByOwner(ownerId)).([]*model.Bot); len(bots) != 2 || bots[0].UserId != bot1.UserId || bots[1].UserId != bot2.UserId {
+		t.Fatal("should've gotten the owner's bots")
+	}
+
+	if bots := Must(store.Bot().GetAll()).([]*model.Bot); len(bots) < 3 {
+		t.Fatal("should've gotten all of the bots")
+	}
+
+	bot1.Description = "updated"
+	Must(store.Bot().Update(bot1))
+
+	if bot := Must(store.Bot().Get(bot1.UserId)).(*model.Bot); bot.Description != "updated" {
+		t.Fatal("should've updated the bot")
+	}
+
+	if result := <-store.Bot().Update(&model.Bot{UserId: model.NewId(), CreateAt: model.GetMillis()}); result.Err == nil {
+		t.Fatal("shouldn't have updated a bot that doesn't exist")
+	}
+
+	Must(store.Bot().PermanentDelete(bot1.UserId))
+
+	if result := <-store.Bot().Get(bot1.UserId); result.Err == nil {
+		t.Fatal("should've deleted the bot")
+	}
+}
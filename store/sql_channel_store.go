@@ -1370,3 +1370,37 @@ func (s SqlChannelStore) GetMembersByIds(channelId string, userIds []string) Sto
 
 	return storeChannel
 }
+
+func (s SqlChannelStore) GetChannelsByIds(channelIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, channelId := range channelIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["channelId"+strconv.Itoa(index)] = channelId
+			idQuery += ":channelId" + strconv.Itoa(index)
+		}
+
+		var channels []*model.Channel
+		if len(channelIds) == 0 {
+			result.Data = channels
+		} else if _, err := s.GetReplica().Select(&channels, "SELECT * FROM Channels WHERE Id IN ("+idQuery+") AND DeleteAt = 0 ORDER BY DisplayName", props); err != nil {
+			result.Err = model.NewLocAppError("SqlChannelStore.GetChannelsByIds", "store.sql_channel.get_channels_by_ids.app_error", nil, err.Error())
+		} else {
+			result.Data = channels
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
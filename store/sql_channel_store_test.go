@@ -1341,3 +1341,24 @@ func TestChannelStoreGetMembersByIds(t *testing.T) {
 		t.Fatal("empty user ids - should have failed")
 	}
 }
+
+func TestChannelStoreGetChannelsByIds(t *testing.T) {
+	Setup()
+
+	o1 := Must(store.Channel().Save(&model.Channel{TeamId: model.NewId(), DisplayName: "ChannelB", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	o2 := Must(store.Channel().Save(&model.Channel{TeamId: model.NewId(), DisplayName: "ChannelA", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	o3 := Must(store.Channel().Save(&model.Channel{TeamId: model.NewId(), DisplayName: "ChannelC", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	Must(store.Channel().Delete(o3.Id, model.GetMillis()))
+
+	if r := <-store.Channel().GetChannelsByIds([]string{o1.Id, o2.Id, o3.Id}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if channels := r.Data.([]*model.Channel); len(channels) != 2 || channels[0].Id != o2.Id || channels[1].Id != o1.Id {
+		t.Fatal("should've returned the channels that aren't deleted sorted by display name")
+	}
+
+	if r := <-store.Channel().GetChannelsByIds([]string{}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]*model.Channel)) != 0 {
+		t.Fatal("empty channel ids - should have returned nothing")
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"github.com/mattermost/platform/model"
+)
+
+type SqlClusterDiscoveryStore struct {
+	*SqlStore
+}
+
+func NewSqlClusterDiscoveryStore(sqlStore *SqlStore) ClusterDiscoveryStore {
+	s := &SqlClusterDiscoveryStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ClusterDiscovery{}, "ClusterDiscovery").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Hostname").SetMaxSize(512)
+		table.ColMap("InterNodeUrl").SetMaxSize(512)
+		table.ColMap("Version").SetMaxSize(64)
+		table.ColMap("ConfigHash").SetMaxSize(64)
+	}
+
+	return s
+}
+
+func (s SqlClusterDiscoveryStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_clusterdiscovery_last_ping_at", "ClusterDiscovery", "LastPingAt")
+}
+
+// SaveOrUpdate records that a server is still running by updating its LastPingAt, adding the server if it hasn't been
+// seen before.
+func (s SqlClusterDiscoveryStore) SaveOrUpdate(node *model.ClusterDiscovery) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		node.PreSave()
+		if result.Err = node.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(node); err != nil {
+			result.Err = model.NewLocAppError("SqlClusterDiscoveryStore.SaveOrUpdate", "store.sql_cluster_discovery.save.app_error", nil, "id="+node.Id+", "+err.Error())
+		} else if count == 0 {
+			if err := s.GetMaster().Insert(node); err != nil {
+				result.Err = model.NewLocAppError("SqlClusterDiscoveryStore.SaveOrUpdate", "store.sql_cluster_discovery.save.app_error", nil, "id="+node.Id+", "+err.Error())
+			}
+		}
+
+		if result.Err == nil {
+			result.Data = node
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetAll returns every server that has updated its LastPingAt since the given time.
+func (s SqlClusterDiscoveryStore) GetAll(aliveSince int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var nodes []*model.ClusterDiscovery
+
+		// Read from the master since servers ping more often than a replica may be updated
+		if _, err := s.GetMaster().Select(&nodes, "SELECT * FROM ClusterDiscovery WHERE LastPingAt >= :AliveSince ORDER BY CreateAt, Id", map[string]interface{}{"AliveSince": aliveSince}); err != nil {
+			result.Err = model.NewLocAppError("SqlClusterDiscoveryStore.GetAll", "store.sql_cluster_discovery.get_all.app_error", nil, err.Error())
+		} else {
+			result.Data = nodes
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlClusterDiscoveryStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ClusterDiscovery WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlClusterDiscoveryStore.Delete", "store.sql_cluster_discovery.delete.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Cleanup removes the servers that stopped without removing themselves and that haven't pinged since the given time.
+func (s SqlClusterDiscoveryStore) Cleanup(before int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM ClusterDiscovery WHERE LastPingAt < :Before", map[string]interface{}{"Before": before}); err != nil {
+			result.Err = model.NewLocAppError("SqlClusterDiscoveryStore.Cleanup", "store.sql_cluster_discovery.cleanup.app_error", nil, err.Error())
+		} else if count, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewLocAppError("SqlClusterDiscoveryStore.Cleanup", "store.sql_cluster_discovery.cleanup.app_error", nil, err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestClusterDiscoveryStore(t *testing.T) {
+	Setup()
+
+	node := &model.ClusterDiscovery{Id: model.NewId(), Hostname: "host", InterNodeUrl: "http://localhost:8075"}
+	Must(store.ClusterDiscovery().SaveOrUpdate(node))
+	createAt := node.CreateAt
+	lastPingAt := node.LastPingAt
+
+	time.Sleep(10 * time.Millisecond)
+
+	Must(store.ClusterDiscovery().SaveOrUpdate(node))
+	if node.CreateAt != createAt || node.LastPingAt <= lastPingAt {
+		t.Fatal("should've updated the last ping time")
+	}
+
+	found := false
+	for _, rnode := range Must(store.ClusterDiscovery().GetAll(lastPingAt)).([]*model.ClusterDiscovery) {
+		if rnode.Id == node.Id {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("should've returned the node")
+	}
+
+	for _, rnode := range Must(store.ClusterDiscovery().GetAll(node.LastPingAt + 1)).([]*model.ClusterDiscovery) {
+		if rnode.Id == node.Id {
+			t.Fatal("shouldn't have returned a node that hasn't pinged recently")
+		}
+	}
+
+	Must(store.ClusterDiscovery().Cleanup(node.LastPingAt + 1))
+	for _, rnode := range Must(store.ClusterDiscovery().GetAll(0)).([]*model.ClusterDiscovery) {
+		if rnode.Id == node.Id {
+			t.Fatal("should've cleaned up the node")
+		}
+	}
+
+	Must(store.ClusterDiscovery().SaveOrUpdate(node))
+	Must(store.ClusterDiscovery().Delete(node.Id))
+	for _, rnode := range Must(store.ClusterDiscovery().GetAll(0)).([]*model.ClusterDiscovery) {
+		if rnode.Id == node.Id {
+			t.Fatal("should've deleted the node")
+		}
+	}
+}
@@ -29,6 +29,8 @@ func NewSqlCommandStore(sqlStore *SqlStore) CommandStore {
 		tableo.ColMap("AutoCompleteHint").SetMaxSize(1024)
 		tableo.ColMap("DisplayName").SetMaxSize(64)
 		tableo.ColMap("Description").SetMaxSize(128)
+		tableo.ColMap("AutocompleteData").SetMaxSize(model.AUTOCOMPLETE_DATA_MAX_SIZE)
+		tableo.ColMap("AutoCompleteURL").SetMaxSize(1024)
 	}
 
 	return s
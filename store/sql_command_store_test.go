@@ -52,6 +52,55 @@ func TestCommandStoreGet(t *testing.T) {
 	}
 }
 
+func TestCommandStoreAutocompleteData(t *testing.T) {
+	Setup()
+
+	o1 := &model.Command{}
+	o1.CreatorId = model.NewId()
+	o1.Method = model.COMMAND_METHOD_POST
+	o1.TeamId = model.NewId()
+	o1.URL = "http://nowhere.com/"
+	o1.Trigger = "trigger"
+	o1.AutoCompleteURL = "http://nowhere.com/autocomplete"
+	o1.AutocompleteData = &model.AutocompleteData{
+		Subcommands: []*model.AutocompleteData{
+			{
+				Trigger: "create",
+				Arguments: []*model.AutocompleteArgument{
+					{Name: "project", Type: model.AUTOCOMPLETE_ARG_TYPE_DYNAMIC},
+				},
+			},
+		},
+	}
+
+	o1 = (<-store.Command().Save(o1)).Data.(*model.Command)
+
+	o2 := &model.Command{}
+	o2.CreatorId = model.NewId()
+	o2.Method = model.COMMAND_METHOD_POST
+	o2.TeamId = o1.TeamId
+	o2.URL = "http://nowhere.com/"
+	o2.Trigger = "trigger2"
+
+	o2 = (<-store.Command().Save(o2)).Data.(*model.Command)
+
+	if r1 := <-store.Command().Get(o1.Id); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if cmd := r1.Data.(*model.Command); cmd.AutoCompleteURL != o1.AutoCompleteURL {
+		t.Fatal("should've saved the autocomplete url")
+	} else if cmd.AutocompleteData == nil || cmd.AutocompleteData.GetSubcommand("create") == nil {
+		t.Fatal("should've saved the autocomplete data")
+	} else if cmd.AutocompleteData.GetSubcommand("create").Arguments[0].Type != model.AUTOCOMPLETE_ARG_TYPE_DYNAMIC {
+		t.Fatal("should've saved the arguments")
+	}
+
+	if r2 := <-store.Command().Get(o2.Id); r2.Err != nil {
+		t.Fatal(r2.Err)
+	} else if r2.Data.(*model.Command).AutocompleteData != nil {
+		t.Fatal("shouldn't have autocomplete data")
+	}
+}
+
 func TestCommandStoreGetByTeam(t *testing.T) {
 	Setup()
 
@@ -141,43 +141,7 @@ func (s SqlComplianceStore) ComplianceExport(job *model.Compliance) StoreChannel
 
 		props := map[string]interface{}{"StartTime": job.StartAt, "EndTime": job.EndAt}
 
-		keywordQuery := ""
-		keywords := strings.Fields(strings.TrimSpace(strings.ToLower(strings.Replace(job.Keywords, ",", " ", -1))))
-		if len(keywords) > 0 {
-
-			keywordQuery = "AND ("
-
-			for index, keyword := range keywords {
-				if index >= 1 {
-					keywordQuery += " OR LOWER(Posts.Message) LIKE :Keyword" + strconv.Itoa(index)
-				} else {
-					keywordQuery += "LOWER(Posts.Message) LIKE :Keyword" + strconv.Itoa(index)
-				}
-
-				props["Keyword"+strconv.Itoa(index)] = "%" + keyword + "%"
-			}
-
-			keywordQuery += ")"
-		}
-
-		emailQuery := ""
-		emails := strings.Fields(strings.TrimSpace(strings.ToLower(strings.Replace(job.Emails, ",", " ", -1))))
-		if len(emails) > 0 {
-
-			emailQuery = "AND ("
-
-			for index, email := range emails {
-				if index >= 1 {
-					emailQuery += " OR Users.Email = :Email" + strconv.Itoa(index)
-				} else {
-					emailQuery += "Users.Email = :Email" + strconv.Itoa(index)
-				}
-
-				props["Email"+strconv.Itoa(index)] = email
-			}
-
-			emailQuery += ")"
-		}
+		filterQuery := buildComplianceFilterQuery(job, props)
 
 		query :=
 			`SELECT
@@ -211,8 +175,7 @@ func (s SqlComplianceStore) ComplianceExport(job *model.Compliance) StoreChannel
 			        AND Posts.UserId = Users.Id
 			        AND Posts.CreateAt > :StartTime
 			        AND Posts.CreateAt <= :EndTime
-			        ` + emailQuery + `
-			        ` + keywordQuery + `
+			        ` + filterQuery + `
 			ORDER BY Posts.CreateAt
 			LIMIT 30000`
 
@@ -230,3 +193,154 @@ func (s SqlComplianceStore) ComplianceExport(job *model.Compliance) StoreChannel
 
 	return storeChannel
 }
+
+// GetChannelIdsForExport returns the ids of the channels that have posts matching a compliance job, including direct
+// and group message channels.
+func (s SqlComplianceStore) GetChannelIdsForExport(job *model.Compliance) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"StartTime": job.StartAt, "EndTime": job.EndAt}
+		filterQuery := buildComplianceFilterQuery(job, props)
+
+		var channelIds []string
+		if _, err := s.GetReplica().Select(&channelIds,
+			`SELECT DISTINCT
+			    Posts.ChannelId
+			FROM
+			    Posts
+			        INNER JOIN Users ON Posts.UserId = Users.Id
+			WHERE
+			    Posts.CreateAt > :StartTime
+			        AND Posts.CreateAt <= :EndTime
+			        `+filterQuery+`
+			ORDER BY Posts.ChannelId`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlComplianceStore.GetChannelIdsForExport", "store.sql_compliance.get_channel_ids_for_export.app_error", nil, err.Error())
+		} else {
+			result.Data = channelIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ComplianceExportBatch returns up to limit posts from a channel that match a compliance job, starting after the post
+// with the given creation time and id, so that the whole channel can be exported by repeatedly calling it with the
+// last result.
+func (s SqlComplianceStore) ComplianceExportBatch(job *model.Compliance, channelId string, afterTime int64, afterPostId string, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{
+			"StartTime":   job.StartAt,
+			"EndTime":     job.EndAt,
+			"ChannelId":   channelId,
+			"AfterTime":   afterTime,
+			"AfterPostId": afterPostId,
+			"Limit":       limit,
+		}
+		filterQuery := buildComplianceFilterQuery(job, props)
+
+		query :=
+			`SELECT
+			    COALESCE(Teams.Name, '') AS TeamName,
+			    COALESCE(Teams.DisplayName, '') AS TeamDisplayName,
+			    Channels.Id AS ChannelId,
+			    Channels.Name AS ChannelName,
+			    Channels.DisplayName AS ChannelDisplayName,
+			    Channels.Type AS ChannelType,
+			    Users.Id AS UserId,
+			    Users.Username AS UserUsername,
+			    Users.Email AS UserEmail,
+			    Users.Nickname AS UserNickname,
+			    Posts.Id AS PostId,
+			    Posts.CreateAt AS PostCreateAt,
+			    Posts.UpdateAt AS PostUpdateAt,
+			    Posts.DeleteAt AS PostDeleteAt,
+			    Posts.RootId AS PostRootId,
+			    Posts.ParentId AS PostParentId,
+			    Posts.OriginalId AS PostOriginalId,
+			    Posts.Message AS PostMessage,
+			    Posts.Type AS PostType,
+			    Posts.Props AS PostProps,
+			    Posts.Hashtags AS PostHashtags,
+			    Posts.FileIds AS PostFileIds
+			FROM
+			    Posts
+			        INNER JOIN Channels ON Posts.ChannelId = Channels.Id
+			        INNER JOIN Users ON Posts.UserId = Users.Id
+			        LEFT JOIN Teams ON Channels.TeamId = Teams.Id
+			WHERE
+			    Posts.ChannelId = :ChannelId
+			        AND Posts.CreateAt > :StartTime
+			        AND Posts.CreateAt <= :EndTime
+			        AND (Posts.CreateAt > :AfterTime OR (Posts.CreateAt = :AfterTime AND Posts.Id > :AfterPostId))
+			        ` + filterQuery + `
+			ORDER BY Posts.CreateAt, Posts.Id
+			LIMIT :Limit`
+
+		var cposts []*model.CompliancePost
+
+		if _, err := s.GetReplica().Select(&cposts, query, props); err != nil {
+			result.Err = model.NewLocAppError("SqlComplianceStore.ComplianceExportBatch", "store.sql_compliance.compliance_export_batch.app_error", nil, "channel_id="+channelId+", "+err.Error())
+		} else {
+			result.Data = cposts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// buildComplianceFilterQuery returns the conditions that limit an export to posts from the job's emails that contain
+// one of its keywords.
+func buildComplianceFilterQuery(job *model.Compliance, props map[string]interface{}) string {
+	keywordQuery := ""
+	keywords := strings.Fields(strings.TrimSpace(strings.ToLower(strings.Replace(job.Keywords, ",", " ", -1))))
+	if len(keywords) > 0 {
+
+		keywordQuery = "AND ("
+
+		for index, keyword := range keywords {
+			if index >= 1 {
+				keywordQuery += " OR LOWER(Posts.Message) LIKE :Keyword" + strconv.Itoa(index)
+			} else {
+				keywordQuery += "LOWER(Posts.Message) LIKE :Keyword" + strconv.Itoa(index)
+			}
+
+			props["Keyword"+strconv.Itoa(index)] = "%" + keyword + "%"
+		}
+
+		keywordQuery += ")"
+	}
+
+	emailQuery := ""
+	emails := strings.Fields(strings.TrimSpace(strings.ToLower(strings.Replace(job.Emails, ",", " ", -1))))
+	if len(emails) > 0 {
+
+		emailQuery = "AND ("
+
+		for index, email := range emails {
+			if index >= 1 {
+				emailQuery += " OR Users.Email = :Email" + strconv.Itoa(index)
+			} else {
+				emailQuery += "Users.Email = :Email" + strconv.Itoa(index)
+			}
+
+			props["Email"+strconv.Itoa(index)] = email
+		}
+
+		emailQuery += ")"
+	}
+
+	return emailQuery + " " + keywordQuery
+}
@@ -208,3 +208,53 @@ func TestComplianceExport(t *testing.T) {
 		}
 	}
 }
+
+func TestComplianceExportBatch(t *testing.T) {
+	Setup()
+
+	u1 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: model.NewId()})).(*model.User)
+	u2 := Must(store.User().Save(&model.User{Email: model.NewId(), Username: model.NewId()})).(*model.User)
+
+	c1 := Must(store.Channel().SaveDirectChannel(
+		&model.Channel{Name: model.GetDMNameFromIds(u1.Id, u2.Id), DisplayName: "DisplayName", Type: model.CHANNEL_DIRECT},
+		&model.ChannelMember{UserId: u1.Id, NotifyProps: model.GetDefaultChannelNotifyProps()},
+		&model.ChannelMember{UserId: u2.Id, NotifyProps: model.GetDefaultChannelNotifyProps()},
+	)).(*model.Channel)
+
+	createAt := model.GetMillis()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: u1.Id, CreateAt: createAt, Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: u2.Id, CreateAt: createAt + 10, Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o3 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: u1.Id, CreateAt: createAt + 20, Message: "a" + model.NewId() + "b"})).(*model.Post)
+
+	job := &model.Compliance{Desc: "test" + model.NewId(), StartAt: createAt - 1, EndAt: createAt + 30}
+
+	channelIds := Must(store.Compliance().GetChannelIdsForExport(job)).([]string)
+	found := false
+	for _, channelId := range channelIds {
+		if channelId == c1.Id {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("should've returned the direct channel")
+	}
+
+	cposts := Must(store.Compliance().ComplianceExportBatch(job, c1.Id, job.StartAt, "", 2)).([]*model.CompliancePost)
+	if len(cposts) != 2 || cposts[0].PostId != o1.Id || cposts[1].PostId != o2.Id {
+		t.Fatal("returned the wrong posts")
+	} else if cposts[0].TeamName != "" || cposts[0].ChannelType != model.CHANNEL_DIRECT || cposts[0].UserId != u1.Id {
+		t.Fatal("returned the wrong post details")
+	}
+
+	cposts = Must(store.Compliance().ComplianceExportBatch(job, c1.Id, cposts[1].PostCreateAt, cposts[1].PostId, 2)).([]*model.CompliancePost)
+	if len(cposts) != 1 || cposts[0].PostId != o3.Id {
+		t.Fatal("should've returned the posts after the last batch")
+	}
+
+	job.Emails = u2.Email
+	cposts = Must(store.Compliance().ComplianceExportBatch(job, c1.Id, job.StartAt, "", 10)).([]*model.CompliancePost)
+	if len(cposts) != 1 || cposts[0].PostId != o2.Id {
+		t.Fatal("should've only returned the posts by the user")
+	}
+}
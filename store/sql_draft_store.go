@@ -0,0 +1,238 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+type SqlDraftStore struct {
+	*SqlStore
+}
+
+func NewSqlDraftStore(sqlStore *SqlStore) DraftStore {
+	s := &SqlDraftStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Draft{}, "Drafts").SetKeys(false, "UserId", "ChannelId", "RootId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(4000)
+		table.ColMap("Props").SetMaxSize(8000)
+		table.ColMap("FileIds").SetMaxSize(150)
+	}
+
+	return s
+}
+
+func (s SqlDraftStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_drafts_update_at", "Drafts", "UpdateAt")
+}
+
+// Save creates a draft or replaces the existing draft for the same user, channel and thread.
+func (s SqlDraftStore) Save(draft *model.Draft) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		draft.PreSave()
+		if result.Err = draft.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.upsert(draft); err != nil {
+			result.Err = model.NewLocAppError("SqlDraftStore.Save", "store.sql_draft.save.app_error", nil, "user_id="+draft.UserId+", channel_id="+draft.ChannelId+", "+err.Error())
+		} else {
+			result.Data = draft
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDraftStore) upsert(draft *model.Draft) error {
+	params := map[string]interface{}{
+		"CreateAt":  draft.CreateAt,
+		"UpdateAt":  draft.UpdateAt,
+		"UserId":    draft.UserId,
+		"ChannelId": draft.ChannelId,
+		"RootId":    draft.RootId,
+		"Message":   draft.Message,
+		"Props":     model.StringInterfaceToJson(draft.Props),
+		"FileIds":   model.ArrayToJson(draft.FileIds),
+	}
+
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
+		_, err := s.GetMaster().Exec(
+			`INSERT INTO
+				Drafts
+				(CreateAt, UpdateAt, UserId, ChannelId, RootId, Message, Props, FileIds)
+			VALUES
+				(:CreateAt, :UpdateAt, :UserId, :ChannelId, :RootId, :Message, :Props, :FileIds)
+			ON DUPLICATE KEY UPDATE
+				UpdateAt = :UpdateAt,
+				Message = :Message,
+				Props = :Props,
+				FileIds = :FileIds`, params)
+		return err
+	}
+
+	// postgres has no way to upsert values until version 9.5, so the existing draft is updated and a new one is only
+	// inserted if there wasn't one. If another request inserts the same draft first, the insert fails and the draft
+	// that it inserted is updated instead.
+	if updated, err := s.update(params); err != nil || updated {
+		return err
+	}
+
+	if err := s.GetMaster().Insert(draft); err == nil {
+		return nil
+	} else if !IsUniqueConstraintError(err.Error(), []string{"drafts_pkey"}) {
+		return err
+	}
+
+	_, err := s.update(params)
+	return err
+}
+
+// update changes an existing draft, leaving its creation time unchanged. It returns false if there's no draft to update.
+func (s SqlDraftStore) update(params map[string]interface{}) (bool, error) {
+	sqlResult, err := s.GetMaster().Exec(
+		`UPDATE
+			Drafts
+		SET
+			UpdateAt = :UpdateAt,
+			Message = :Message,
+			Props = :Props,
+			FileIds = :FileIds
+		WHERE
+			UserId = :UserId
+			AND ChannelId = :ChannelId
+			AND RootId = :RootId`, params)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := sqlResult.RowsAffected()
+	return rows > 0, err
+}
+
+func (s SqlDraftStore) Get(userId string, channelId string, rootId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var draft *model.Draft
+		if err := s.GetReplica().SelectOne(&draft,
+			`SELECT
+				*
+			FROM
+				Drafts
+			WHERE
+				UserId = :UserId
+				AND ChannelId = :ChannelId
+				AND RootId = :RootId`, map[string]interface{}{"UserId": userId, "ChannelId": channelId, "RootId": rootId}); err != nil {
+			result.Err = model.NewLocAppError("SqlDraftStore.Get", "store.sql_draft.get.app_error", nil, "user_id="+userId+", channel_id="+channelId+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = draft
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForUser returns a user's drafts in the channels that they belong to on a team, including direct and group
+// channels, with the most recently changed first.
+func (s SqlDraftStore) GetForUser(teamId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var drafts []*model.Draft
+		if _, err := s.GetReplica().Select(&drafts,
+			`SELECT
+				Drafts.*
+			FROM
+				Drafts,
+				Channels,
+				ChannelMembers
+			WHERE
+				Drafts.UserId = :UserId
+				AND Drafts.ChannelId = Channels.Id
+				AND (Channels.TeamId = :TeamId OR Channels.TeamId = '')
+				AND Channels.DeleteAt = 0
+				AND ChannelMembers.ChannelId = Drafts.ChannelId
+				AND ChannelMembers.UserId = Drafts.UserId
+			ORDER BY
+				Drafts.UpdateAt DESC`, map[string]interface{}{"TeamId": teamId, "UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlDraftStore.GetForUser", "store.sql_draft.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = drafts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Delete removes a draft. The result's data is true if there was a draft to delete.
+func (s SqlDraftStore) Delete(userId string, channelId string, rootId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`DELETE FROM
+				Drafts
+			WHERE
+				UserId = :UserId
+				AND ChannelId = :ChannelId
+				AND RootId = :RootId`, map[string]interface{}{"UserId": userId, "ChannelId": channelId, "RootId": rootId}); err != nil {
+			result.Err = model.NewLocAppError("SqlDraftStore.Delete", "store.sql_draft.delete.app_error", nil, "user_id="+userId+", channel_id="+channelId+", "+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows > 0
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlDraftStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM Drafts WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlDraftStore.PermanentDeleteByUser", "store.sql_draft.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
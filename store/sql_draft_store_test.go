@@ -0,0 +1,107 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestDraftStoreSaveGetDelete(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	channelId := model.NewId()
+	rootId := model.NewId()
+
+	d1 := Must(store.Draft().Save(&model.Draft{UserId: userId, ChannelId: channelId, Message: "channel"})).(*model.Draft)
+	Must(store.Draft().Save(&model.Draft{UserId: userId, ChannelId: channelId, RootId: rootId, Message: "thread"}))
+
+	if draft := Must(store.Draft().Get(userId, channelId, "")).(*model.Draft); draft.Message != "channel" {
+		t.Fatal("returned the wrong draft")
+	}
+
+	if draft := Must(store.Draft().Get(userId, channelId, rootId)).(*model.Draft); draft.Message != "thread" {
+		t.Fatal("returned the wrong draft")
+	}
+
+	Must(store.Draft().Save(&model.Draft{UserId: userId, ChannelId: channelId, Message: "updated"}))
+
+	if draft := Must(store.Draft().Get(userId, channelId, "")).(*model.Draft); draft.Message != "updated" {
+		t.Fatal("should've replaced the existing draft")
+	} else if draft.CreateAt != d1.CreateAt {
+		t.Fatal("shouldn't have changed the creation time")
+	}
+
+	if deleted := Must(store.Draft().Delete(userId, channelId, "")).(bool); !deleted {
+		t.Fatal("should've deleted the draft")
+	}
+
+	if deleted := Must(store.Draft().Delete(userId, channelId, "")).(bool); deleted {
+		t.Fatal("there should be no draft left to delete")
+	}
+
+	if result := <-store.Draft().Get(userId, channelId, ""); result.Err == nil {
+		t.Fatal("should've deleted the draft")
+	}
+
+	if result := <-store.Draft().Get(userId, channelId, rootId); result.Err != nil {
+		t.Fatal("shouldn't have deleted the draft for the thread")
+	}
+
+	Must(store.Draft().PermanentDeleteByUser(userId))
+
+	if result := <-store.Draft().Get(userId, channelId, rootId); result.Err == nil {
+		t.Fatal("should've deleted all of the user's drafts")
+	}
+}
+
+func TestDraftStoreSaveConcurrently(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	channelId := model.NewId()
+	defer store.Draft().PermanentDeleteByUser(userId)
+
+	// every save should succeed even when the draft doesn't exist yet when they start
+	results := make(chan StoreResult, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			results <- <-store.Draft().Save(&model.Draft{UserId: userId, ChannelId: channelId, Message: "draft"})
+		}()
+	}
+
+	for i := 0; i < 5; i++ {
+		if result := <-results; result.Err != nil {
+			t.Fatal(result.Err)
+		}
+	}
+
+	if draft := Must(store.Draft().Get(userId, channelId, "")).(*model.Draft); draft.Message != "draft" {
+		t.Fatal("should've saved the draft")
+	}
+}
+
+func TestDraftStoreGetForUser(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	c1 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Name", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	c2 := Must(store.Channel().Save(&model.Channel{TeamId: model.NewId(), DisplayName: "Name", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	c3 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Name", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: c1.Id, UserId: userId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: c2.Id, UserId: userId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+
+	Must(store.Draft().Save(&model.Draft{UserId: userId, ChannelId: c1.Id, Message: "team"}))
+	Must(store.Draft().Save(&model.Draft{UserId: userId, ChannelId: c2.Id, Message: "other team"}))
+	Must(store.Draft().Save(&model.Draft{UserId: userId, ChannelId: c3.Id, Message: "not a member"}))
+
+	if drafts := Must(store.Draft().GetForUser(teamId, userId)).([]*model.Draft); len(drafts) != 1 || drafts[0].ChannelId != c1.Id {
+		t.Fatal("should've only returned drafts in the team's channels that the user belongs to")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlEventSubscriptionStore struct {
+	*SqlStore
+}
+
+func NewSqlEventSubscriptionStore(sqlStore *SqlStore) EventSubscriptionStore {
+	s := &SqlEventSubscriptionStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.EventSubscription{}, "EventSubscriptions").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("Secret").SetMaxSize(model.EVENT_SUBSCRIPTION_SECRET_LENGTH)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("URL").SetMaxSize(1024)
+		table.ColMap("Events").SetMaxSize(1024)
+		table.ColMap("DisplayName").SetMaxSize(64)
+		table.ColMap("Description").SetMaxSize(128)
+	}
+
+	return s
+}
+
+func (s SqlEventSubscriptionStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_eventsubscriptions_team_id", "EventSubscriptions", "TeamId")
+	s.CreateIndexIfNotExists("idx_eventsubscriptions_creator_id", "EventSubscriptions", "CreatorId")
+	s.CreateIndexIfNotExists("idx_eventsubscriptions_delete_at", "EventSubscriptions", "DeleteAt")
+}
+
+func (s SqlEventSubscriptionStore) Save(subscription *model.EventSubscription) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(subscription.Id) > 0 {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.Save", "store.sql_event_subscription.save.existing.app_error", nil, "id="+subscription.Id)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		subscription.PreSave()
+		if result.Err = subscription.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(subscription); err != nil {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.Save", "store.sql_event_subscription.save.app_error", nil, "id="+subscription.Id+", "+err.Error())
+		} else {
+			result.Data = subscription
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlEventSubscriptionStore) Update(subscription *model.EventSubscription) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		subscription.PreUpdate()
+		if result.Err = subscription.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(subscription); err != nil {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.Update", "store.sql_event_subscription.update.app_error", nil, "id="+subscription.Id+", "+err.Error())
+		} else if count != 1 {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.Update", "store.sql_event_subscription.update.app_error", nil, "id="+subscription.Id)
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = subscription
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlEventSubscriptionStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var subscription *model.EventSubscription
+
+		if err := s.GetReplica().SelectOne(&subscription, "SELECT * FROM EventSubscriptions WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.Get", "store.sql_event_subscription.get.app_error", nil, "id="+id+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = subscription
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlEventSubscriptionStore) GetByTeam(teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var subscriptions []*model.EventSubscription
+
+		if _, err := s.GetReplica().Select(&subscriptions, "SELECT * FROM EventSubscriptions WHERE TeamId = :TeamId AND DeleteAt = 0 ORDER BY CreateAt, Id", map[string]interface{}{"TeamId": teamId}); err != nil {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.GetByTeam", "store.sql_event_subscription.get_by_team.app_error", nil, "team_id="+teamId+", "+err.Error())
+		} else {
+			result.Data = subscriptions
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlEventSubscriptionStore) Delete(id string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE EventSubscriptions SET DeleteAt = :DeleteAt, UpdateAt = :UpdateAt WHERE Id = :Id", map[string]interface{}{"DeleteAt": time, "UpdateAt": time, "Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.Delete", "store.sql_event_subscription.delete.app_error", nil, "id="+id+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlEventSubscriptionStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM EventSubscriptions WHERE CreatorId = :CreatorId", map[string]interface{}{"CreatorId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlEventSubscriptionStore.PermanentDeleteByUser", "store.sql_event_subscription.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
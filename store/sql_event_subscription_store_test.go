@@ -0,0 +1,66 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestEventSubscriptionStore(t *testing.T) {
+	Setup()
+
+	creatorId := model.NewId()
+	teamId := model.NewId()
+
+	s1 := Must(store.EventSubscription().Save(&model.EventSubscription{
+		CreatorId: creatorId,
+		TeamId:    teamId,
+		URL:       "http://nowhere.com/events",
+		Events:    model.StringArray{model.WEBSOCKET_EVENT_CHANNEL_CREATED},
+	})).(*model.EventSubscription)
+	s2 := Must(store.EventSubscription().Save(&model.EventSubscription{
+		CreatorId: model.NewId(),
+		TeamId:    teamId,
+		URL:       "http://nowhere.com/events",
+		Events:    model.StringArray{model.WEBSOCKET_EVENT_POST_EDITED, model.WEBSOCKET_EVENT_POST_DELETED},
+	})).(*model.EventSubscription)
+	defer store.EventSubscription().PermanentDeleteByUser(s2.CreatorId)
+
+	if result := <-store.EventSubscription().Save(s1); result.Err == nil {
+		t.Fatal("shouldn't have saved an existing subscription")
+	}
+
+	if subscription := Must(store.EventSubscription().Get(s1.Id)).(*model.EventSubscription); subscription.Secret != s1.Secret || !subscription.HasEvent(model.WEBSOCKET_EVENT_CHANNEL_CREATED) {
+		t.Fatal("should've gotten the subscription")
+	}
+
+	if subscriptions := Must(store.EventSubscription().GetByTeam(teamId)).([]*model.EventSubscription); len(subscriptions) != 2 || subscriptions[0].Id != s1.Id || subscriptions[1].Id != s2.Id {
+		t.Fatal("should've gotten the team's subscriptions")
+	}
+
+	s2.Events = model.StringArray{model.WEBSOCKET_EVENT_REACTION_ADDED}
+	Must(store.EventSubscription().Update(s2))
+
+	if subscription := Must(store.EventSubscription().Get(s2.Id)).(*model.EventSubscription); len(subscription.Events) != 1 || !subscription.HasEvent(model.WEBSOCKET_EVENT_REACTION_ADDED) {
+		t.Fatal("should've updated the subscription")
+	}
+
+	Must(store.EventSubscription().Delete(s2.Id, model.GetMillis()))
+
+	if result := <-store.EventSubscription().Get(s2.Id); result.Err == nil {
+		t.Fatal("shouldn't get a deleted subscription")
+	}
+
+	if subscriptions := Must(store.EventSubscription().GetByTeam(teamId)).([]*model.EventSubscription); len(subscriptions) != 1 {
+		t.Fatal("shouldn't get deleted subscriptions for the team")
+	}
+
+	Must(store.EventSubscription().PermanentDeleteByUser(creatorId))
+
+	if result := <-store.EventSubscription().Get(s1.Id); result.Err == nil {
+		t.Fatal("should've deleted the user's subscriptions")
+	}
+}
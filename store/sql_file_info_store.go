@@ -3,7 +3,12 @@
 package store
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
 )
 
 type SqlFileInfoStore struct {
@@ -24,6 +29,8 @@ func NewSqlFileInfoStore(sqlStore *SqlStore) FileInfoStore {
 		table.ColMap("Name").SetMaxSize(256)
 		table.ColMap("Extension").SetMaxSize(64)
 		table.ColMap("MimeType").SetMaxSize(256)
+		table.ColMap("Hash").SetMaxSize(64)
+		table.ColMap("Content").SetMaxSize(model.FILE_INFO_CONTENT_MAX_RUNES)
 	}
 
 	return s
@@ -33,6 +40,9 @@ func (fs SqlFileInfoStore) CreateIndexesIfNotExists() {
 	fs.CreateIndexIfNotExists("idx_fileinfo_update_at", "FileInfo", "UpdateAt")
 	fs.CreateIndexIfNotExists("idx_fileinfo_create_at", "FileInfo", "CreateAt")
 	fs.CreateIndexIfNotExists("idx_fileinfo_delete_at", "FileInfo", "DeleteAt")
+	fs.CreateIndexIfNotExists("idx_fileinfo_post_id", "FileInfo", "PostId")
+	fs.CreateIndexIfNotExists("idx_fileinfo_extension", "FileInfo", "Extension")
+	fs.CreateFullTextIndexIfNotExists("idx_fileinfo_content_txt", "FileInfo", "Content")
 }
 
 func (fs SqlFileInfoStore) Save(info *model.FileInfo) StoreChannel {
@@ -199,3 +209,368 @@ func (fs SqlFileInfoStore) DeleteForPost(postId string) StoreChannel {
 
 	return storeChannel
 }
+
+type searchFileInfoResult struct {
+	model.FileInfo
+	Score float64
+}
+
+// buildFileNameSearchTerms converts search terms into patterns that can be used to find them anywhere in a file's name.
+func buildFileNameSearchTerms(terms []string) []string {
+	patterns := []string{}
+
+	for _, term := range terms {
+		term = strings.ToLower(strings.Trim(term, "\"#*"))
+		term = strings.Replace(term, "\\", "\\\\", -1)
+		term = strings.Replace(term, "%", "\\%", -1)
+		term = strings.Replace(term, "_", "\\_", -1)
+
+		if term != "" {
+			patterns = append(patterns, "%"+term+"%")
+		}
+	}
+
+	return patterns
+}
+
+// Search returns the files attached to posts in channels on the given team that the user belongs to which match the
+// search params. The terms are matched against both the names of the files and the text extracted from them.
+func (fs SqlFileInfoStore) Search(teamId string, userId string, params *model.SearchParams) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		queryParams := map[string]interface{}{
+			"TeamId": teamId,
+			"UserId": userId,
+		}
+
+		if params.Terms == "" && len(params.InChannels) == 0 && len(params.FromUsers) == 0 && len(params.Extensions) == 0 && !params.HasDateFilter() {
+			result.Data = []*model.FileInfo{}
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		buildInClause := func(prefix string, values []string) string {
+			clause := ""
+			for i, value := range values {
+				if i > 0 {
+					clause += ", "
+				}
+
+				paramName := prefix + strconv.Itoa(i)
+				clause += ":" + paramName
+				queryParams[paramName] = value
+			}
+
+			return clause
+		}
+
+		searchQuery := `
+			SELECT
+				FileInfo.*SCORE_CLAUSE
+			FROM
+				FileInfo
+				INNER JOIN Posts ON Posts.Id = FileInfo.PostId
+			WHERE
+				FileInfo.DeleteAt = 0
+				AND Posts.DeleteAt = 0
+				FILE_FILTER
+				DATE_FILTER
+				AND Posts.ChannelId IN (
+					SELECT
+						Id
+					FROM
+						Channels,
+						ChannelMembers
+					WHERE
+						Id = ChannelId
+							AND (TeamId = :TeamId OR TeamId = '')
+							AND UserId = :UserId
+							AND DeleteAt = 0
+							CHANNEL_FILTER)
+				SEARCH_CLAUSE
+				ORDER BY ORDER_CLAUSE FileInfo.CreateAt DESC
+			LIMIT 100`
+
+		if len(params.InChannels) > 0 {
+			searchQuery = strings.Replace(searchQuery, "CHANNEL_FILTER", "AND Name IN ("+buildInClause("InChannel", params.InChannels)+")", 1)
+		} else {
+			searchQuery = strings.Replace(searchQuery, "CHANNEL_FILTER", "", 1)
+		}
+
+		fileFilter := ""
+		if len(params.FromUsers) > 0 {
+			fileFilter += `
+				AND FileInfo.CreatorId IN (
+					SELECT
+						Id
+					FROM
+						Users,
+						TeamMembers
+					WHERE
+						TeamMembers.TeamId = :TeamId
+						AND Users.Id = TeamMembers.UserId
+						AND Username IN (` + buildInClause("FromUser", params.FromUsers) + `))`
+		}
+		if len(params.Extensions) > 0 {
+			fileFilter += " AND FileInfo.Extension IN (" + buildInClause("Extension", params.Extensions) + ")"
+		}
+		searchQuery = strings.Replace(searchQuery, "FILE_FILTER", fileFilter, 1)
+
+		dateFilter := ""
+		if afterTime := params.GetAfterDateMillis(); afterTime != 0 {
+			dateFilter += " AND FileInfo.CreateAt >= :AfterTime"
+			queryParams["AfterTime"] = afterTime
+		}
+		if beforeTime := params.GetBeforeDateMillis(); beforeTime != 0 {
+			dateFilter += " AND FileInfo.CreateAt < :BeforeTime"
+			queryParams["BeforeTime"] = beforeTime
+		}
+		if onStart, onEnd := params.GetOnDateMillis(); onStart != 0 {
+			dateFilter += " AND FileInfo.CreateAt BETWEEN :OnDateStart AND :OnDateEnd"
+			queryParams["OnDateStart"] = onStart
+			queryParams["OnDateEnd"] = onEnd
+		}
+		searchQuery = strings.Replace(searchQuery, "DATE_FILTER", dateFilter, 1)
+
+		if params.Terms == "" {
+			// we've already confirmed that we have a channel, user, extension, or date to search for
+			searchQuery = strings.Replace(searchQuery, "SCORE_CLAUSE", ", 0 AS Score", 1)
+			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", "", 1)
+			searchQuery = strings.Replace(searchQuery, "ORDER_CLAUSE", "", 1)
+		} else {
+			terms := params.SplitTerms()
+			for i, term := range terms {
+				terms[i] = strings.Replace(term, "#", "", -1)
+			}
+
+			contentTerms := ""
+			contentMatch := ""
+			contentScore := ""
+			if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+				contentTerms = buildPostgresSearchTerms(terms, params.OrTerms, fs.SupportsPhraseSearch())
+
+				// these must match the expression used by the full text index so that the index is used by the query
+				tsVector := "to_tsvector('english', FileInfo.Content)"
+				tsQuery := "to_tsquery('english', :Terms)"
+
+				contentMatch = tsVector + " @@ " + tsQuery
+				if !fs.SupportsPhraseSearch() && !params.OrTerms {
+					// older versions of Postgres can only check that the words of a phrase are in the file, not their order
+					for i, pattern := range buildPhraseSearchPatterns(terms) {
+						paramName := "Phrase" + strconv.Itoa(i)
+						contentMatch += " AND FileInfo.Content ILIKE :" + paramName
+						queryParams[paramName] = pattern
+					}
+					contentMatch = "(" + contentMatch + ")"
+				}
+				contentScore = fmt.Sprintf("ts_rank(%s, %s)", tsVector, tsQuery)
+			} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
+				contentTerms = buildMysqlSearchTerms(terms, params.OrTerms)
+
+				contentMatch = "MATCH (FileInfo.Content) AGAINST (:Terms IN BOOLEAN MODE)"
+				contentScore = contentMatch
+			}
+
+			nameMatches := []string{}
+			for i, pattern := range buildFileNameSearchTerms(terms) {
+				paramName := "NameTerm" + strconv.Itoa(i)
+				nameMatches = append(nameMatches, "LOWER(FileInfo.Name) LIKE :"+paramName)
+				queryParams[paramName] = pattern
+			}
+
+			if contentTerms == "" && len(nameMatches) == 0 {
+				// the search terms only contained characters that can't be searched for
+				result.Data = []*model.FileInfo{}
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+
+			matches := []string{}
+			if contentTerms != "" {
+				matches = append(matches, contentMatch)
+				queryParams["Terms"] = contentTerms
+				searchQuery = strings.Replace(searchQuery, "SCORE_CLAUSE", ", "+contentScore+" AS Score", 1)
+			} else {
+				// only the file names can be searched, so there's nothing to rank the results by
+				searchQuery = strings.Replace(searchQuery, "SCORE_CLAUSE", ", 0 AS Score", 1)
+			}
+
+			if len(nameMatches) > 0 {
+				if params.OrTerms {
+					matches = append(matches, strings.Join(nameMatches, " OR "))
+				} else {
+					matches = append(matches, "("+strings.Join(nameMatches, " AND ")+")")
+				}
+			}
+
+			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", fmt.Sprintf("AND (%s)", strings.Join(matches, " OR ")), 1)
+			searchQuery = strings.Replace(searchQuery, "ORDER_CLAUSE", "Score DESC,", 1)
+		}
+
+		var results []*searchFileInfoResult
+		if _, err := fs.GetReplica().Select(&results, searchQuery, queryParams); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.Search", "store.sql_file_info.search.app_error", nil, "teamId="+teamId+", err="+err.Error())
+		} else {
+			infos := make([]*model.FileInfo, len(results))
+			for i, searchResult := range results {
+				infos[i] = &searchResult.FileInfo
+			}
+
+			result.Data = infos
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForRetention returns up to limit files uploaded before endTime that are covered by a data retention policy,
+// skipping any preserved by the given legal holds. Files are matched to a team through the post they're attached to, so files that were never attached to a post or whose
+// post has already been deleted are only covered by the global policy.
+func (fs SqlFileInfoStore) GetForRetention(endTime int64, teamId string, excludeTeamIds []string, legalHolds []*model.LegalHold, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime, "Limit": limit}
+
+		postQuery := ""
+		if len(teamId) > 0 {
+			props["TeamId"] = teamId
+			postQuery = "AND PostId IN (SELECT Posts.Id FROM Posts, Channels WHERE Posts.ChannelId = Channels.Id AND Channels.TeamId = :TeamId)"
+		} else if len(excludeTeamIds) > 0 {
+			teamQuery := buildIdListQuery("ExcludeTeamId", excludeTeamIds, props)
+			postQuery = "AND PostId NOT IN (SELECT Posts.Id FROM Posts, Channels WHERE Posts.ChannelId = Channels.Id AND Channels.TeamId IN (" + teamQuery + "))"
+		}
+
+		legalHoldQuery := buildLegalHoldQuery(legalHolds, "CreatorId IN (%s)", "PostId IN (SELECT Id FROM Posts WHERE ChannelId IN (%s))", "CreateAt", props)
+
+		var infos []*model.FileInfo
+		if _, err := fs.GetMaster().Select(&infos,
+			`SELECT
+				*
+			FROM
+				FileInfo
+			WHERE
+				CreateAt < :EndTime
+				`+postQuery+`
+				`+legalHoldQuery+`
+			LIMIT :Limit`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.GetForRetention", "store.sql_file_info.get_for_retention.app_error", nil, "team_id="+teamId+", err="+err.Error())
+		} else {
+			result.Data = infos
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// CountHeldForRetention returns how many of the files created before endTime that are covered by a data retention
+// policy are being preserved by the given legal hold.
+func (fs SqlFileInfoStore) CountHeldForRetention(endTime int64, teamId string, excludeTeamIds []string, hold *model.LegalHold) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime}
+
+		postQuery := ""
+		if len(teamId) > 0 {
+			props["TeamId"] = teamId
+			postQuery = "AND PostId IN (SELECT Posts.Id FROM Posts, Channels WHERE Posts.ChannelId = Channels.Id AND Channels.TeamId = :TeamId)"
+		} else if len(excludeTeamIds) > 0 {
+			teamQuery := buildIdListQuery("ExcludeTeamId", excludeTeamIds, props)
+			postQuery = "AND PostId NOT IN (SELECT Posts.Id FROM Posts, Channels WHERE Posts.ChannelId = Channels.Id AND Channels.TeamId IN (" + teamQuery + "))"
+		}
+
+		holdQuery := buildLegalHoldQuery([]*model.LegalHold{hold}, "CreatorId IN (%s)", "PostId IN (SELECT Id FROM Posts WHERE ChannelId IN (%s))", "CreateAt", props)
+		if holdQuery == "" {
+			result.Data = int64(0)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := fs.GetMaster().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				FileInfo
+			WHERE
+				CreateAt < :EndTime
+				`+postQuery+`
+				AND NOT (1 = 1 `+holdQuery+`)`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlFileInfoStore.CountHeldForRetention", "store.sql_file_info.count_held_for_retention.app_error", nil, "legal_hold_id="+hold.Id+", team_id="+teamId+", err="+err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (fs SqlFileInfoStore) PermanentDeleteByIds(fileIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(fileIds) > 0 {
+			props := make(map[string]interface{})
+			idQuery := buildIdListQuery("FileId", fileIds, props)
+
+			if _, err := fs.GetMaster().Exec("DELETE FROM FileInfo WHERE Id IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlFileInfoStore.PermanentDeleteByIds", "store.sql_file_info.permanent_delete_by_ids.app_error", nil, err.Error())
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForPostIds returns the files that were attached to any of the given posts, including ones that have been deleted.
+func (fs SqlFileInfoStore) GetForPostIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var infos []*model.FileInfo
+
+		if len(postIds) == 0 {
+			result.Data = infos
+		} else {
+			props := make(map[string]interface{})
+			idQuery := buildIdListQuery("PostId", postIds, props)
+
+			if _, err := fs.GetReplica().Select(&infos, "SELECT * FROM FileInfo WHERE PostId IN ("+idQuery+") ORDER BY CreateAt", props); err != nil {
+				result.Err = model.NewLocAppError("SqlFileInfoStore.GetForPostIds", "store.sql_file_info.get_for_post_ids.app_error", nil, err.Error())
+			} else {
+				result.Data = infos
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
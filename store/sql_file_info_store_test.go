@@ -206,3 +206,121 @@ func TestFileInfoDeleteForPost(t *testing.T) {
 		t.Fatal("shouldn't have returned any file infos")
 	}
 }
+
+func TestFileInfoSearch(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	c1 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Channel1", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: c1.Id, UserId: userId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+
+	c2 := Must(store.Channel().Save(&model.Channel{TeamId: teamId, DisplayName: "Channel2", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	p1 := Must(store.Post().Save(&model.Post{ChannelId: c1.Id, UserId: userId, Message: "files"})).(*model.Post)
+	p2 := Must(store.Post().Save(&model.Post{ChannelId: c2.Id, UserId: userId, Message: "files"})).(*model.Post)
+
+	i1 := Must(store.FileInfo().Save(&model.FileInfo{CreatorId: userId, PostId: p1.Id, Path: "report.pdf", Name: "Quarterly_Report.pdf", Extension: "pdf", Content: "revenue grew in new york"})).(*model.FileInfo)
+	i2 := Must(store.FileInfo().Save(&model.FileInfo{CreatorId: userId, PostId: p1.Id, Path: "notes.txt", Name: "notes.txt", Extension: "txt", Content: "meeting notes about the quarterly revenue"})).(*model.FileInfo)
+	Must(store.FileInfo().Save(&model.FileInfo{CreatorId: userId, PostId: p2.Id, Path: "other.txt", Name: "other.txt", Extension: "txt", Content: "revenue in another channel"}))
+	Must(store.FileInfo().Save(&model.FileInfo{CreatorId: userId, Path: "unattached.txt", Name: "unattached.txt", Extension: "txt", Content: "revenue that was never posted"}))
+
+	search := func(params *model.SearchParams) []*model.FileInfo {
+		if result := <-store.FileInfo().Search(teamId, userId, params); result.Err != nil {
+			t.Fatal(result.Err)
+			return nil
+		} else {
+			return result.Data.([]*model.FileInfo)
+		}
+	}
+
+	if infos := search(&model.SearchParams{Terms: "revenue"}); len(infos) != 2 {
+		t.Fatal("should've only found attached files in channels that the user belongs to", infos)
+	}
+
+	if infos := search(&model.SearchParams{Terms: "\"new york\""}); len(infos) != 1 || infos[0].Id != i1.Id {
+		t.Fatal("should've found the phrase in the file's content", infos)
+	}
+
+	if infos := search(&model.SearchParams{Terms: "quarterly_rep"}); len(infos) != 1 || infos[0].Id != i1.Id {
+		t.Fatal("should've found the file by its name", infos)
+	}
+
+	if infos := search(&model.SearchParams{Terms: "quarterly"}); len(infos) != 2 {
+		t.Fatal("should've found files by name and content", infos)
+	}
+
+	if infos := search(&model.SearchParams{Terms: "revenue", Extensions: []string{"txt"}}); len(infos) != 1 || infos[0].Id != i2.Id {
+		t.Fatal("should've only found files with the given extension", infos)
+	}
+
+	if infos := search(&model.SearchParams{Terms: "", Extensions: []string{"pdf"}}); len(infos) != 1 || infos[0].Id != i1.Id {
+		t.Fatal("should've searched by extension without any terms", infos)
+	}
+
+	if infos := search(&model.SearchParams{Terms: "revenue", InChannels: []string{c2.Name}}); len(infos) != 0 {
+		t.Fatal("shouldn't have found files in channels that the user doesn't belong to", infos)
+	}
+
+	if infos := search(&model.SearchParams{Terms: "revenue", BeforeDate: "2000-01-01"}); len(infos) != 0 {
+		t.Fatal("should've filtered files by date", infos)
+	}
+
+	Must(store.FileInfo().DeleteForPost(p1.Id))
+
+	if infos := search(&model.SearchParams{Terms: "revenue"}); len(infos) != 0 {
+		t.Fatal("shouldn't have found deleted files", infos)
+	}
+}
+
+func TestFileInfoRetention(t *testing.T) {
+	Setup()
+
+	team := Must(store.Team().Save(&model.Team{DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Email: model.NewId() + "@nowhere.com", Type: model.TEAM_OPEN})).(*model.Team)
+	channel := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	post := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+
+	userId := model.NewId()
+
+	info1 := Must(store.FileInfo().Save(&model.FileInfo{PostId: post.Id, CreatorId: userId, Path: "file.txt", CreateAt: 1000})).(*model.FileInfo)
+	info2 := Must(store.FileInfo().Save(&model.FileInfo{PostId: post.Id, CreatorId: userId, Path: "file.txt", CreateAt: 3000})).(*model.FileInfo)
+	info3 := Must(store.FileInfo().Save(&model.FileInfo{CreatorId: userId, Path: "file.txt", CreateAt: 1000})).(*model.FileInfo)
+
+	if infos := Must(store.FileInfo().GetForRetention(2000, team.Id, nil, nil, 100)).([]*model.FileInfo); len(infos) != 1 || infos[0].Id != info1.Id {
+		t.Fatal("should've only returned the old file from the team")
+	}
+
+	holds := []*model.LegalHold{{UserIds: []string{userId}, StartAt: 500}}
+	if infos := Must(store.FileInfo().GetForRetention(2000, team.Id, nil, holds, 100)).([]*model.FileInfo); len(infos) != 0 {
+		t.Fatal("shouldn't have returned a file preserved by a legal hold")
+	}
+
+	holds = []*model.LegalHold{{ChannelIds: []string{channel.Id}, StartAt: 500}}
+	if infos := Must(store.FileInfo().GetForRetention(2000, team.Id, nil, holds, 100)).([]*model.FileInfo); len(infos) != 0 {
+		t.Fatal("shouldn't have returned a file posted in a held channel")
+	}
+
+	infos := Must(store.FileInfo().GetForRetention(2000, "", []string{team.Id}, nil, 10000)).([]*model.FileInfo)
+	found := false
+	for _, info := range infos {
+		if info.Id == info1.Id || info.Id == info2.Id {
+			t.Fatal("shouldn't have returned a file from an excluded team")
+		} else if info.Id == info3.Id {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("should've returned the old file that isn't attached to a post")
+	}
+
+	Must(store.FileInfo().PermanentDeleteByIds([]string{info1.Id, info3.Id}))
+
+	if result := <-store.FileInfo().Get(info1.Id); result.Err == nil {
+		t.Fatal("should've deleted the file info")
+	}
+
+	if result := <-store.FileInfo().Get(info2.Id); result.Err != nil {
+		t.Fatal("shouldn't have deleted the newer file info")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlLegalHoldStore struct {
+	*SqlStore
+}
+
+func NewSqlLegalHoldStore(sqlStore *SqlStore) LegalHoldStore {
+	s := &SqlLegalHoldStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.LegalHold{}, "LegalHolds").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("CreatorId").SetMaxSize(26)
+		table.ColMap("Name").SetMaxSize(64)
+		table.ColMap("Description").SetMaxSize(1024)
+		table.ColMap("UserIds").SetMaxSize(model.LEGAL_HOLD_IDS_MAX_RUNES)
+		table.ColMap("ChannelIds").SetMaxSize(model.LEGAL_HOLD_IDS_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlLegalHoldStore) CreateIndexesIfNotExists() {
+}
+
+func (s SqlLegalHoldStore) Save(hold *model.LegalHold) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		hold.PreSave()
+		if result.Err = hold.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(hold); err != nil {
+			result.Err = model.NewLocAppError("SqlLegalHoldStore.Save", "store.sql_legal_hold.save.app_error", nil, "id="+hold.Id+", "+err.Error())
+		} else {
+			result.Data = hold
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLegalHoldStore) Update(hold *model.LegalHold) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		hold.PreUpdate()
+		if result.Err = hold.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(hold); err != nil {
+			result.Err = model.NewLocAppError("SqlLegalHoldStore.Update", "store.sql_legal_hold.update.app_error", nil, "id="+hold.Id+", "+err.Error())
+		} else if count != 1 {
+			result.Err = model.NewLocAppError("SqlLegalHoldStore.Update", "store.sql_legal_hold.update.app_error", nil, "id="+hold.Id)
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = hold
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLegalHoldStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var hold *model.LegalHold
+
+		if err := s.GetReplica().SelectOne(&hold, "SELECT * FROM LegalHolds WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlLegalHoldStore.Get", "store.sql_legal_hold.get.app_error", nil, "id="+id+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = hold
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLegalHoldStore) GetAll() StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var holds []*model.LegalHold
+
+		// Read from the master since holds need to be enforced as soon as they're created
+		if _, err := s.GetMaster().Select(&holds, "SELECT * FROM LegalHolds ORDER BY CreateAt, Id"); err != nil {
+			result.Err = model.NewLocAppError("SqlLegalHoldStore.GetAll", "store.sql_legal_hold.get_all.app_error", nil, err.Error())
+		} else {
+			result.Data = holds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlLegalHoldStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM LegalHolds WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlLegalHoldStore.Delete", "store.sql_legal_hold.delete.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// buildLegalHoldQuery returns conditions that exclude anything preserved by the given legal holds. The user and
+// channel conditions are format strings that are given a list of ids to match against, and the time column is when
+// each row was created.
+func buildLegalHoldQuery(holds []*model.LegalHold, userCondition string, channelCondition string, timeColumn string, props map[string]interface{}) string {
+	query := ""
+
+	for i, hold := range holds {
+		prefix := "LegalHold" + strconv.Itoa(i)
+
+		conditions := []string{}
+		if len(hold.UserIds) > 0 {
+			conditions = append(conditions, fmt.Sprintf(userCondition, buildIdListQuery(prefix+"UserId", hold.UserIds, props)))
+		}
+		if len(hold.ChannelIds) > 0 {
+			conditions = append(conditions, fmt.Sprintf(channelCondition, buildIdListQuery(prefix+"ChannelId", hold.ChannelIds, props)))
+		}
+
+		if len(conditions) == 0 {
+			continue
+		}
+
+		props[prefix+"StartAt"] = hold.StartAt
+		timeQuery := timeColumn + " >= :" + prefix + "StartAt"
+		if hold.EndAt != 0 {
+			props[prefix+"EndAt"] = hold.EndAt
+			timeQuery += " AND " + timeColumn + " <= :" + prefix + "EndAt"
+		}
+
+		memberQuery := conditions[0]
+		if len(conditions) > 1 {
+			memberQuery += " OR " + conditions[1]
+		}
+
+		query += " AND NOT ((" + memberQuery + ") AND " + timeQuery + ")"
+	}
+
+	return query
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestLegalHoldStoreSaveGetUpdateDelete(t *testing.T) {
+	Setup()
+
+	hold := Must(store.LegalHold().Save(&model.LegalHold{
+		CreatorId: model.NewId(),
+		Name:      "hold",
+		UserIds:   []string{model.NewId()},
+		StartAt:   1000,
+	})).(*model.LegalHold)
+
+	if rhold := Must(store.LegalHold().Get(hold.Id)).(*model.LegalHold); rhold.Name != hold.Name || len(rhold.UserIds) != 1 || rhold.UserIds[0] != hold.UserIds[0] {
+		t.Fatal("returned the wrong legal hold")
+	}
+
+	found := false
+	for _, rhold := range Must(store.LegalHold().GetAll()).([]*model.LegalHold) {
+		if rhold.Id == hold.Id {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("should've returned the legal hold")
+	}
+
+	hold.ChannelIds = []string{model.NewId()}
+	hold.EndAt = 2000
+	Must(store.LegalHold().Update(hold))
+
+	if rhold := Must(store.LegalHold().Get(hold.Id)).(*model.LegalHold); len(rhold.ChannelIds) != 1 || rhold.EndAt != 2000 {
+		t.Fatal("should've updated the legal hold")
+	}
+
+	if result := <-store.LegalHold().Save(&model.LegalHold{Name: "empty"}); result.Err == nil {
+		t.Fatal("shouldn't have saved a legal hold without any users or channels")
+	}
+
+	Must(store.LegalHold().Delete(hold.Id))
+
+	if result := <-store.LegalHold().Get(hold.Id); result.Err == nil {
+		t.Fatal("should've deleted the legal hold")
+	}
+}
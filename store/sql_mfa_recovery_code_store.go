@@ -0,0 +1,138 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"github.com/go-gorp/gorp"
+	"github.com/mattermost/platform/model"
+)
+
+type SqlMfaRecoveryCodeStore struct {
+	*SqlStore
+}
+
+func NewSqlMfaRecoveryCodeStore(sqlStore *SqlStore) MfaRecoveryCodeStore {
+	s := &SqlMfaRecoveryCodeStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.MfaRecoveryCode{}, "MfaRecoveryCodes").SetKeys(false, "UserId", "Hash")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Hash").SetMaxSize(64)
+	}
+
+	return s
+}
+
+func (s SqlMfaRecoveryCodeStore) CreateIndexesIfNotExists() {
+}
+
+// Save replaces all of a user's recovery codes with the codes with the given hashes.
+func (s SqlMfaRecoveryCodeStore) Save(userId string, hashes []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		codes := make([]*model.MfaRecoveryCode, len(hashes))
+		for i, hash := range hashes {
+			codes[i] = &model.MfaRecoveryCode{UserId: userId, Hash: hash}
+
+			codes[i].PreSave()
+			if result.Err = codes[i].IsValid(); result.Err != nil {
+				storeChannel <- result
+				close(storeChannel)
+				return
+			}
+		}
+
+		if transaction, err := s.GetMaster().Begin(); err != nil {
+			result.Err = model.NewLocAppError("SqlMfaRecoveryCodeStore.Save", "store.sql_mfa_recovery_code.save.app_error", nil, err.Error())
+		} else if err := s.save(transaction, userId, codes); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewLocAppError("SqlMfaRecoveryCodeStore.Save", "store.sql_mfa_recovery_code.save.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = model.NewLocAppError("SqlMfaRecoveryCodeStore.Save", "store.sql_mfa_recovery_code.save.app_error", nil, err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMfaRecoveryCodeStore) save(transaction *gorp.Transaction, userId string, codes []*model.MfaRecoveryCode) error {
+	if _, err := transaction.Exec("DELETE FROM MfaRecoveryCodes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		if err := transaction.Insert(code); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Use deletes the user's recovery code with the given hash so that it can't be used again. The result's data is true
+// if the user had that code.
+func (s SqlMfaRecoveryCodeStore) Use(userId string, hash string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		// Deleting the code checks it and uses it up in one statement, so the same code can't be used by two requests
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM MfaRecoveryCodes WHERE UserId = :UserId AND Hash = :Hash", map[string]interface{}{"UserId": userId, "Hash": hash}); err != nil {
+			result.Err = model.NewLocAppError("SqlMfaRecoveryCodeStore.Use", "store.sql_mfa_recovery_code.use.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else if count, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewLocAppError("SqlMfaRecoveryCodeStore.Use", "store.sql_mfa_recovery_code.use.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = count == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Count returns the number of recovery codes that the user hasn't used yet.
+func (s SqlMfaRecoveryCodeStore) Count(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetMaster().SelectInt("SELECT COUNT(*) FROM MfaRecoveryCodes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlMfaRecoveryCodeStore.Count", "store.sql_mfa_recovery_code.count.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlMfaRecoveryCodeStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM MfaRecoveryCodes WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlMfaRecoveryCodeStore.PermanentDeleteByUser", "store.sql_mfa_recovery_code.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestMfaRecoveryCodeStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	hashes := []string{
+		model.HashMfaRecoveryCode(model.NewMfaRecoveryCode()),
+		model.HashMfaRecoveryCode(model.NewMfaRecoveryCode()),
+	}
+
+	Must(store.MfaRecoveryCode().Save(userId, hashes))
+
+	if count := Must(store.MfaRecoveryCode().Count(userId)).(int64); count != 2 {
+		t.Fatal("should've saved the codes")
+	}
+
+	if used := Must(store.MfaRecoveryCode().Use(model.NewId(), hashes[0])).(bool); used {
+		t.Fatal("shouldn't have used another user's code")
+	}
+
+	if used := Must(store.MfaRecoveryCode().Use(userId, hashes[0])).(bool); !used {
+		t.Fatal("should've used the code")
+	}
+
+	if used := Must(store.MfaRecoveryCode().Use(userId, hashes[0])).(bool); used {
+		t.Fatal("shouldn't have used the same code twice")
+	}
+
+	replacement := model.HashMfaRecoveryCode(model.NewMfaRecoveryCode())
+	Must(store.MfaRecoveryCode().Save(userId, []string{replacement}))
+
+	if used := Must(store.MfaRecoveryCode().Use(userId, hashes[1])).(bool); used {
+		t.Fatal("should've replaced the old codes")
+	}
+
+	if result := <-store.MfaRecoveryCode().Save(userId, []string{"not a hash"}); result.Err == nil {
+		t.Fatal("shouldn't have saved an invalid hash")
+	}
+
+	Must(store.MfaRecoveryCode().PermanentDeleteByUser(userId))
+
+	if count := Must(store.MfaRecoveryCode().Count(userId)).(int64); count != 0 {
+		t.Fatal("should've deleted the codes")
+	}
+}
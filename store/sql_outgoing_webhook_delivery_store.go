@@ -0,0 +1,268 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlOutgoingWebhookDeliveryStore struct {
+	*SqlStore
+}
+
+func NewSqlOutgoingWebhookDeliveryStore(sqlStore *SqlStore) OutgoingWebhookDeliveryStore {
+	s := &SqlOutgoingWebhookDeliveryStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.OutgoingWebhookDelivery{}, "OutgoingWebhookDeliveries").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("HookId").SetMaxSize(26)
+		table.ColMap("Event").SetMaxSize(64)
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("URL").SetMaxSize(1024)
+		table.ColMap("ContentType").SetMaxSize(128)
+		table.ColMap("Payload").SetMaxSize(model.OUTGOING_WEBHOOK_DELIVERY_PAYLOAD_MAX_SIZE)
+		table.ColMap("Status").SetMaxSize(16)
+		table.ColMap("ErrorMessage").SetMaxSize(model.OUTGOING_WEBHOOK_DELIVERY_ERROR_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlOutgoingWebhookDeliveryStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_outgoingwebhookdeliveries_hook_id", "OutgoingWebhookDeliveries", "HookId")
+	s.CreateIndexIfNotExists("idx_outgoingwebhookdeliveries_next_attempt_at", "OutgoingWebhookDeliveries", "NextAttemptAt")
+	s.CreateIndexIfNotExists("idx_outgoingwebhookdeliveries_create_at", "OutgoingWebhookDeliveries", "CreateAt")
+}
+
+func (s SqlOutgoingWebhookDeliveryStore) Save(delivery *model.OutgoingWebhookDelivery) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		delivery.PreSave()
+		if result.Err = delivery.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(delivery); err != nil {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.Save", "store.sql_outgoing_webhook_delivery.save.app_error", nil, "id="+delivery.Id+", "+err.Error())
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlOutgoingWebhookDeliveryStore) Update(delivery *model.OutgoingWebhookDelivery) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if result.Err = delivery.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().Update(delivery); err != nil {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.Update", "store.sql_outgoing_webhook_delivery.update.app_error", nil, "id="+delivery.Id+", "+err.Error())
+		} else if count != 1 {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.Update", "store.sql_outgoing_webhook_delivery.update.app_error", nil, "id="+delivery.Id)
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlOutgoingWebhookDeliveryStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var delivery *model.OutgoingWebhookDelivery
+
+		// Read from the master since deliveries are claimed by whichever server sends them
+		if err := s.GetMaster().SelectOne(&delivery, "SELECT * FROM OutgoingWebhookDeliveries WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.Get", "store.sql_outgoing_webhook_delivery.get.app_error", nil, "id="+id+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = delivery
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetByHook returns a page of the deliveries for an outgoing webhook with the newest ones first.
+func (s SqlOutgoingWebhookDeliveryStore) GetByHook(hookId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if limit > 1000 {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.GetByHook", "store.sql_outgoing_webhook_delivery.get_by_hook.limit.app_error", nil, "hook_id="+hookId)
+			result.Err.StatusCode = http.StatusBadRequest
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		var deliveries []*model.OutgoingWebhookDelivery
+
+		if _, err := s.GetReplica().Select(&deliveries,
+			`SELECT
+				*
+			FROM
+				OutgoingWebhookDeliveries
+			WHERE
+				HookId = :HookId
+			ORDER BY
+				CreateAt DESC, Id
+			LIMIT :Limit
+			OFFSET :Offset`, map[string]interface{}{"HookId": hookId, "Offset": offset, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.GetByHook", "store.sql_outgoing_webhook_delivery.get_by_hook.app_error", nil, "hook_id="+hookId+", "+err.Error())
+		} else {
+			result.Data = deliveries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDue returns the pending deliveries that should be attempted at or before the given time.
+func (s SqlOutgoingWebhookDeliveryStore) GetDue(time int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var deliveries []*model.OutgoingWebhookDelivery
+
+		if _, err := s.GetMaster().Select(&deliveries,
+			`SELECT
+				*
+			FROM
+				OutgoingWebhookDeliveries
+			WHERE
+				NextAttemptAt <= :Time
+				AND Status = :Status
+			ORDER BY
+				NextAttemptAt, Id
+			LIMIT :Limit`, map[string]interface{}{"Time": time, "Status": model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.GetDue", "store.sql_outgoing_webhook_delivery.get_due.app_error", nil, err.Error())
+		} else {
+			result.Data = deliveries
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Claim moves the next attempt of a pending delivery from nextAttemptAt to leaseUntil so that only one server sends
+// it. If that server goes away before recording the result, the delivery will be attempted again once the lease runs
+// out. The result's data is true if this call claimed the delivery.
+func (s SqlOutgoingWebhookDeliveryStore) Claim(id string, nextAttemptAt int64, leaseUntil int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`UPDATE
+				OutgoingWebhookDeliveries
+			SET
+				NextAttemptAt = :LeaseUntil
+			WHERE
+				Id = :Id
+				AND NextAttemptAt = :NextAttemptAt
+				AND Status = :Status`, map[string]interface{}{"Id": id, "NextAttemptAt": nextAttemptAt, "LeaseUntil": leaseUntil, "Status": model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING}); err != nil {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.Claim", "store.sql_outgoing_webhook_delivery.claim.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// PermanentDeleteBefore removes the deliveries that were created before the given time and are no longer pending.
+func (s SqlOutgoingWebhookDeliveryStore) PermanentDeleteBefore(time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`DELETE FROM
+				OutgoingWebhookDeliveries
+			WHERE
+				CreateAt < :Time
+				AND Status != :Status`, map[string]interface{}{"Time": time, "Status": model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING}); err != nil {
+			result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.PermanentDeleteBefore", "store.sql_outgoing_webhook_delivery.permanent_delete_before.app_error", nil, err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlOutgoingWebhookDeliveryStore) PermanentDeleteByPostIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) > 0 {
+			props := make(map[string]interface{})
+			idQuery := buildIdListQuery("PostId", postIds, props)
+
+			if _, err := s.GetMaster().Exec("DELETE FROM OutgoingWebhookDeliveries WHERE PostId IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlOutgoingWebhookDeliveryStore.PermanentDeleteByPostIds", "store.sql_outgoing_webhook_delivery.permanent_delete_by_post_ids.app_error", nil, err.Error())
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestOutgoingWebhookDeliveryStore(t *testing.T) {
+	Setup()
+
+	hookId := model.NewId()
+	postId := model.NewId()
+
+	newDelivery := func() *model.OutgoingWebhookDelivery {
+		return &model.OutgoingWebhookDelivery{
+			HookId:      hookId,
+			PostId:      postId,
+			ChannelId:   model.NewId(),
+			URL:         "http://example.com",
+			ContentType: "application/json",
+			Payload:     "{}",
+		}
+	}
+
+	d1 := Must(store.OutgoingWebhookDelivery().Save(newDelivery())).(*model.OutgoingWebhookDelivery)
+	d2 := Must(store.OutgoingWebhookDelivery().Save(newDelivery())).(*model.OutgoingWebhookDelivery)
+
+	if d := Must(store.OutgoingWebhookDelivery().Get(d1.Id)).(*model.OutgoingWebhookDelivery); d.HookId != hookId || d.Status != model.OUTGOING_WEBHOOK_DELIVERY_STATUS_PENDING {
+		t.Fatal("should've gotten the delivery")
+	}
+
+	if deliveries := Must(store.OutgoingWebhookDelivery().GetByHook(hookId, 0, 10)).([]*model.OutgoingWebhookDelivery); len(deliveries) != 2 {
+		t.Fatal("should've gotten the hook's deliveries")
+	}
+
+	if deliveries := Must(store.OutgoingWebhookDelivery().GetByHook(hookId, 1, 10)).([]*model.OutgoingWebhookDelivery); len(deliveries) != 1 {
+		t.Fatal("should've gotten the second page of deliveries")
+	}
+
+	if result := <-store.OutgoingWebhookDelivery().GetByHook(hookId, 0, 1001); result.Err == nil {
+		t.Fatal("shouldn't have allowed getting too many deliveries at once")
+	}
+
+	now := model.GetMillis()
+
+	if claimed := Must(store.OutgoingWebhookDelivery().Claim(d1.Id, d1.NextAttemptAt, now+60000)).(bool); !claimed {
+		t.Fatal("should've claimed the delivery")
+	}
+
+	if claimed := Must(store.OutgoingWebhookDelivery().Claim(d1.Id, d1.NextAttemptAt, now+60000)).(bool); claimed {
+		t.Fatal("shouldn't have claimed the delivery twice")
+	}
+
+	found := false
+	for _, d := range Must(store.OutgoingWebhookDelivery().GetDue(now, 1000)).([]*model.OutgoingWebhookDelivery) {
+		if d.Id == d1.Id {
+			t.Fatal("shouldn't have returned a claimed delivery")
+		} else if d.Id == d2.Id {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should've returned the due delivery")
+	}
+
+	d1.Status = model.OUTGOING_WEBHOOK_DELIVERY_STATUS_SUCCESS
+	d1.StatusCode = 200
+	d1.NextAttemptAt = 0
+	Must(store.OutgoingWebhookDelivery().Update(d1))
+
+	if d := Must(store.OutgoingWebhookDelivery().Get(d1.Id)).(*model.OutgoingWebhookDelivery); d.StatusCode != 200 {
+		t.Fatal("should've updated the delivery")
+	}
+
+	Must(store.OutgoingWebhookDelivery().PermanentDeleteBefore(model.GetMillis() + 1))
+
+	if result := <-store.OutgoingWebhookDelivery().Get(d1.Id); result.Err == nil {
+		t.Fatal("should've deleted the finished delivery")
+	}
+
+	if result := <-store.OutgoingWebhookDelivery().Get(d2.Id); result.Err != nil {
+		t.Fatal("shouldn't have deleted the pending delivery")
+	}
+
+	Must(store.OutgoingWebhookDelivery().PermanentDeleteByPostIds([]string{postId}))
+
+	if result := <-store.OutgoingWebhookDelivery().Get(d2.Id); result.Err == nil {
+		t.Fatal("should've deleted the post's deliveries")
+	}
+}
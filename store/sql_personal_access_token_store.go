@@ -0,0 +1,160 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlPersonalAccessTokenStore struct {
+	*SqlStore
+}
+
+func NewSqlPersonalAccessTokenStore(sqlStore *SqlStore) PersonalAccessTokenStore {
+	s := &SqlPersonalAccessTokenStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PersonalAccessToken{}, "PersonalAccessTokens").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("TokenHash").SetMaxSize(64).SetUnique(true)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("Description").SetMaxSize(model.PERSONAL_ACCESS_TOKEN_DESCRIPTION_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlPersonalAccessTokenStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_personalaccesstokens_user_id", "PersonalAccessTokens", "UserId")
+}
+
+func (s SqlPersonalAccessTokenStore) Save(token *model.PersonalAccessToken) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		token.PreSave()
+		if result.Err = token.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(token); err != nil {
+			result.Err = model.NewLocAppError("SqlPersonalAccessTokenStore.Save", "store.sql_personal_access_token.save.app_error", nil, "id="+token.Id+", "+err.Error())
+		} else {
+			result.Data = token
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPersonalAccessTokenStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var token *model.PersonalAccessToken
+
+		if err := s.GetReplica().SelectOne(&token, "SELECT * FROM PersonalAccessTokens WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlPersonalAccessTokenStore.Get", "store.sql_personal_access_token.get.app_error", nil, "id="+id+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = token
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPersonalAccessTokenStore) GetByTokenHash(hash string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var token *model.PersonalAccessToken
+
+		// Read from the master so that a token can be used as soon as it's created
+		if err := s.GetMaster().SelectOne(&token, "SELECT * FROM PersonalAccessTokens WHERE TokenHash = :TokenHash", map[string]interface{}{"TokenHash": hash}); err != nil {
+			result.Err = model.NewLocAppError("SqlPersonalAccessTokenStore.GetByTokenHash", "store.sql_personal_access_token.get_by_token_hash.app_error", nil, err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = token
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPersonalAccessTokenStore) GetByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var tokens []*model.PersonalAccessToken
+
+		if _, err := s.GetReplica().Select(&tokens, "SELECT * FROM PersonalAccessTokens WHERE UserId = :UserId ORDER BY CreateAt, Id", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPersonalAccessTokenStore.GetByUser", "store.sql_personal_access_token.get_by_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = tokens
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPersonalAccessTokenStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PersonalAccessTokens WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlPersonalAccessTokenStore.Delete", "store.sql_personal_access_token.delete.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPersonalAccessTokenStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM PersonalAccessTokens WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlPersonalAccessTokenStore.PermanentDeleteByUser", "store.sql_personal_access_token.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
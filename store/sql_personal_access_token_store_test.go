@@ -0,0 +1,52 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestPersonalAccessTokenStore(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+
+	token1 := Must(store.PersonalAccessToken().Save(&model.PersonalAccessToken{UserId: userId, Description: "first"})).(*model.PersonalAccessToken)
+	token2 := Must(store.PersonalAccessToken().Save(&model.PersonalAccessToken{UserId: userId, Description: "second"})).(*model.PersonalAccessToken)
+	Must(store.PersonalAccessToken().Save(&model.PersonalAccessToken{UserId: model.NewId()}))
+
+	if result := <-store.PersonalAccessToken().Save(&model.PersonalAccessToken{}); result.Err == nil {
+		t.Fatal("shouldn't have saved a token without a user")
+	}
+
+	if token := Must(store.PersonalAccessToken().Get(token1.Id)).(*model.PersonalAccessToken); token.TokenHash != token1.TokenHash || token.Token != "" {
+		t.Fatal("should've only saved the hash of the token")
+	}
+
+	if token := Must(store.PersonalAccessToken().GetByTokenHash(model.HashPersonalAccessToken(token2.Token))).(*model.PersonalAccessToken); token.Id != token2.Id {
+		t.Fatal("should've found the token by its hash")
+	}
+
+	if result := <-store.PersonalAccessToken().GetByTokenHash(model.HashPersonalAccessToken(model.NewId())); result.Err == nil {
+		t.Fatal("shouldn't have found a token that doesn't exist")
+	}
+
+	if tokens := Must(store.PersonalAccessToken().GetByUser(userId)).([]*model.PersonalAccessToken); len(tokens) != 2 {
+		t.Fatal("should've gotten the user's tokens")
+	}
+
+	Must(store.PersonalAccessToken().Delete(token1.Id))
+
+	if result := <-store.PersonalAccessToken().Get(token1.Id); result.Err == nil {
+		t.Fatal("should've deleted the token")
+	}
+
+	Must(store.PersonalAccessToken().PermanentDeleteByUser(userId))
+
+	if tokens := Must(store.PersonalAccessToken().GetByUser(userId)).([]*model.PersonalAccessToken); len(tokens) != 0 {
+		t.Fatal("should've deleted the user's tokens")
+	}
+}
@@ -5,7 +5,6 @@ package store
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
@@ -181,6 +180,68 @@ func (s SqlPostStore) GetFlaggedPosts(userId string, offset int, limit int) Stor
 	return storeChannel
 }
 
+func (s SqlPostStore) GetPinnedPosts(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+	go func() {
+		result := StoreResult{}
+		pl := &model.PostList{}
+
+		var posts []*model.Post
+		if _, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE ChannelId = :ChannelId AND IsPinned = :IsPinned AND DeleteAt = 0 ORDER BY CreateAt DESC", map[string]interface{}{"ChannelId": channelId, "IsPinned": true}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPinnedPosts", "store.sql_post.get_pinned_posts.app_error", nil, "channel_id="+channelId+", "+err.Error())
+		} else {
+			for _, post := range posts {
+				pl.AddPost(post)
+				pl.AddOrder(post.Id)
+			}
+		}
+
+		result.Data = pl
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostStore) GetPinnedPostCount(channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+	go func() {
+		result := StoreResult{}
+
+		if count, err := s.GetReplica().SelectInt("SELECT COUNT(0) FROM Posts WHERE ChannelId = :ChannelId AND IsPinned = :IsPinned AND DeleteAt = 0", map[string]interface{}{"ChannelId": channelId, "IsPinned": true}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPinnedPostCount", "store.sql_post.get_pinned_post_count.app_error", nil, "channel_id="+channelId+", "+err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// SetPinned pins or unpins a post without marking it as edited.
+func (s SqlPostStore) SetPinned(postId string, isPinned bool) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("UPDATE Posts SET IsPinned = :IsPinned, UpdateAt = :UpdateAt WHERE Id = :Id AND DeleteAt = 0", map[string]interface{}{"Id": postId, "IsPinned": isPinned, "UpdateAt": model.GetMillis()}); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.SetPinned", "store.sql_post.set_pinned.app_error", nil, "id="+postId+", "+err.Error())
+		} else {
+			result.Data = postId
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPostStore) Get(id string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -725,6 +786,133 @@ var specialSearchChar = []string{
 	":",
 }
 
+// these characters have a special meaning in a Postgres tsquery in addition to the ones above
+var specialPostgresSearchChar = []string{
+	"&",
+	"|",
+	"!",
+	"'",
+	"\\",
+}
+
+type searchPostResult struct {
+	model.Post
+	Score float64
+}
+
+func removeSpecialSearchChars(term string, specialChars []string) string {
+	for _, c := range specialChars {
+		term = strings.Replace(term, c, " ", -1)
+	}
+
+	return term
+}
+
+// buildPostgresSearchTerms converts search terms into a tsquery where words ending in an asterisk are treated as
+// prefixes and quoted phrases must appear in order. If phraseSearch is false, the words of a phrase only need to appear
+// somewhere in the text, so the results should be filtered using buildPhraseSearchPatterns.
+func buildPostgresSearchTerms(terms []string, orTerms bool, phraseSearch bool) string {
+	parts := []string{}
+
+	for _, term := range terms {
+		isPhrase := len(term) > 1 && strings.HasPrefix(term, "\"") && strings.HasSuffix(term, "\"")
+
+		term = strings.Replace(term, "\"", " ", -1)
+		term = removeSpecialSearchChars(term, specialSearchChar)
+		term = removeSpecialSearchChars(term, specialPostgresSearchChar)
+
+		words := []string{}
+		for _, word := range strings.Fields(term) {
+			prefix := strings.HasSuffix(word, "*")
+
+			word = strings.Trim(word, "*")
+			if word == "" {
+				continue
+			}
+
+			if prefix && !isPhrase {
+				word += ":*"
+			}
+
+			words = append(words, word)
+		}
+
+		if len(words) == 0 {
+			continue
+		} else if isPhrase && len(words) > 1 && phraseSearch {
+			parts = append(parts, "("+strings.Join(words, " <-> ")+")")
+		} else if isPhrase && len(words) > 1 {
+			parts = append(parts, "("+strings.Join(words, " & ")+")")
+		} else {
+			parts = append(parts, strings.Join(words, " & "))
+		}
+	}
+
+	if orTerms {
+		return strings.Join(parts, " | ")
+	} else {
+		return strings.Join(parts, " & ")
+	}
+}
+
+// buildPhraseSearchPatterns returns a LIKE pattern for each quoted phrase in the search terms for databases that can't
+// match phrases as part of their full text search.
+func buildPhraseSearchPatterns(terms []string) []string {
+	patterns := []string{}
+
+	for _, term := range terms {
+		if len(term) < 2 || !strings.HasPrefix(term, "\"") || !strings.HasSuffix(term, "\"") {
+			continue
+		}
+
+		words := strings.Fields(strings.Trim(term, "\""))
+		if len(words) < 2 {
+			continue
+		}
+
+		phrase := strings.Join(words, " ")
+		phrase = strings.Replace(phrase, "\\", "\\\\", -1)
+		phrase = strings.Replace(phrase, "%", "\\%", -1)
+		phrase = strings.Replace(phrase, "_", "\\_", -1)
+
+		patterns = append(patterns, "%"+phrase+"%")
+	}
+
+	return patterns
+}
+
+// buildMysqlSearchTerms converts search terms into a boolean mode full text search where every word or quoted phrase
+// is required unless orTerms is set.
+func buildMysqlSearchTerms(terms []string, orTerms bool) string {
+	parts := []string{}
+
+	for _, term := range terms {
+		isPhrase := len(term) > 1 && strings.HasPrefix(term, "\"") && strings.HasSuffix(term, "\"")
+
+		term = strings.Replace(term, "\"", " ", -1)
+		term = removeSpecialSearchChars(term, specialSearchChar)
+
+		words := strings.Fields(term)
+		if len(words) == 0 {
+			continue
+		}
+
+		if isPhrase {
+			words = []string{"\"" + strings.Replace(strings.Join(words, " "), "*", "", -1) + "\""}
+		}
+
+		for _, word := range words {
+			if !orTerms {
+				word = "+" + word
+			}
+
+			parts = append(parts, word)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
 func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchParams) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -739,9 +927,10 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 		termMap := map[string]bool{}
 		terms := params.Terms
 
-		if terms == "" && len(params.InChannels) == 0 && len(params.FromUsers) == 0 {
-			result.Data = []*model.Post{}
+		if terms == "" && len(params.InChannels) == 0 && len(params.FromUsers) == 0 && !params.HasDateFilter() {
+			result.Data = &model.PostList{Order: []string{}, Posts: map[string]*model.Post{}}
 			storeChannel <- result
+			close(storeChannel)
 			return
 		}
 
@@ -753,22 +942,18 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 			}
 		}
 
-		// these chars have special meaning and can be treated as spaces
-		for _, c := range specialSearchChar {
-			terms = strings.Replace(terms, c, " ", -1)
-		}
-
-		var posts []*model.Post
+		var posts []*searchPostResult
 
 		searchQuery := `
 			SELECT
-				*
+				*SCORE_CLAUSE
 			FROM
 				Posts
 			WHERE
 				DeleteAt = 0
 				AND Type NOT LIKE '` + model.POST_SYSTEM_MESSAGE_PREFIX + `%'
 				POST_FILTER
+				DATE_FILTER
 				AND ChannelId IN (
 					SELECT
 						Id
@@ -782,7 +967,7 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 							AND DeleteAt = 0
 							CHANNEL_FILTER)
 				SEARCH_CLAUSE
-				ORDER BY CreateAt DESC
+				ORDER BY ORDER_CLAUSE CreateAt DESC
 			LIMIT 100`
 
 		if len(params.InChannels) > 1 {
@@ -841,35 +1026,63 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 			searchQuery = strings.Replace(searchQuery, "POST_FILTER", "", 1)
 		}
 
+		dateFilter := ""
+		if afterTime := params.GetAfterDateMillis(); afterTime != 0 {
+			dateFilter += " AND CreateAt >= :AfterTime"
+			queryParams["AfterTime"] = afterTime
+		}
+		if beforeTime := params.GetBeforeDateMillis(); beforeTime != 0 {
+			dateFilter += " AND CreateAt < :BeforeTime"
+			queryParams["BeforeTime"] = beforeTime
+		}
+		if onStart, onEnd := params.GetOnDateMillis(); onStart != 0 {
+			dateFilter += " AND CreateAt BETWEEN :OnDateStart AND :OnDateEnd"
+			queryParams["OnDateStart"] = onStart
+			queryParams["OnDateEnd"] = onEnd
+		}
+		searchQuery = strings.Replace(searchQuery, "DATE_FILTER", dateFilter, 1)
+
 		if terms == "" {
-			// we've already confirmed that we have a channel or user to search for
+			// we've already confirmed that we have a channel, user, or date to search for
+			searchQuery = strings.Replace(searchQuery, "SCORE_CLAUSE", ", 0 AS Score", 1)
 			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", "", 1)
+			searchQuery = strings.Replace(searchQuery, "ORDER_CLAUSE", "", 1)
 		} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
-			// Parse text for wildcards
-			if wildcard, err := regexp.Compile("\\*($| )"); err == nil {
-				terms = wildcard.ReplaceAllLiteralString(terms, ":* ")
-			}
-
-			if params.OrTerms {
-				terms = strings.Join(strings.Fields(terms), " | ")
-			} else {
-				terms = strings.Join(strings.Fields(terms), " & ")
+			terms = buildPostgresSearchTerms(params.SplitTerms(), params.OrTerms, s.SupportsPhraseSearch())
+
+			// these must match the expression used by the full text index so that the index is used by the query
+			tsVector := fmt.Sprintf("to_tsvector('english', %s)", searchType)
+			tsQuery := "to_tsquery('english', :Terms)"
+
+			searchClause := fmt.Sprintf("AND %s @@ %s", tsVector, tsQuery)
+			if !s.SupportsPhraseSearch() && !params.OrTerms && !params.IsHashtag {
+				// older versions of Postgres can only check that the words of a phrase are in the post, not their order
+				for i, pattern := range buildPhraseSearchPatterns(params.SplitTerms()) {
+					paramName := "Phrase" + strconv.Itoa(i)
+					searchClause += fmt.Sprintf(" AND %s ILIKE :%s", searchType, paramName)
+					queryParams[paramName] = pattern
+				}
 			}
 
-			searchClause := fmt.Sprintf("AND %s @@  to_tsquery(:Terms)", searchType)
+			searchQuery = strings.Replace(searchQuery, "SCORE_CLAUSE", fmt.Sprintf(", ts_rank(%s, %s) AS Score", tsVector, tsQuery), 1)
 			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", searchClause, 1)
+			searchQuery = strings.Replace(searchQuery, "ORDER_CLAUSE", "Score DESC,", 1)
 		} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
-			searchClause := fmt.Sprintf("AND MATCH (%s) AGAINST (:Terms IN BOOLEAN MODE)", searchType)
-			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", searchClause, 1)
+			terms = buildMysqlSearchTerms(params.SplitTerms(), params.OrTerms)
 
-			if !params.OrTerms {
-				splitTerms := strings.Fields(terms)
-				for i, t := range strings.Fields(terms) {
-					splitTerms[i] = "+" + t
-				}
+			match := fmt.Sprintf("MATCH (%s) AGAINST (:Terms IN BOOLEAN MODE)", searchType)
 
-				terms = strings.Join(splitTerms, " ")
-			}
+			searchQuery = strings.Replace(searchQuery, "SCORE_CLAUSE", ", "+match+" AS Score", 1)
+			searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", "AND "+match, 1)
+			searchQuery = strings.Replace(searchQuery, "ORDER_CLAUSE", "Score DESC,", 1)
+		}
+
+		if params.Terms != "" && terms == "" {
+			// the search terms only contained characters that can't be searched for
+			result.Data = &model.PostList{Order: []string{}, Posts: map[string]*model.Post{}}
+			storeChannel <- result
+			close(storeChannel)
+			return
 		}
 
 		queryParams["Terms"] = terms
@@ -881,7 +1094,9 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 
 		list := &model.PostList{Order: make([]string, 0, len(posts))}
 
-		for _, p := range posts {
+		for _, searchResult := range posts {
+			p := &searchResult.Post
+
 			if searchType == "Hashtags" {
 				exactMatch := false
 				for _, tag := range strings.Split(p.Hashtags, " ") {
@@ -895,6 +1110,12 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 			}
 			list.AddPost(p)
 			list.AddOrder(p.Id)
+
+			if params.Terms != "" {
+				if snippet := params.Snippet(p.Message); snippet != "" {
+					list.AddSnippet(p.Id, snippet)
+				}
+			}
 		}
 
 		list.MakeNonNil()
@@ -908,6 +1129,122 @@ func (s SqlPostStore) Search(teamId string, userId string, params *model.SearchP
 	return storeChannel
 }
 
+func (s SqlPostStore) GetPostsByIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, postId := range postIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["postId"+strconv.Itoa(index)] = postId
+			idQuery += ":postId" + strconv.Itoa(index)
+		}
+
+		var posts []*model.Post
+		if len(postIds) == 0 {
+			result.Data = posts
+		} else if _, err := s.GetReplica().Select(&posts, "SELECT * FROM Posts WHERE Id IN ("+idQuery+") AND DeleteAt = 0 ORDER BY CreateAt DESC", props); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPostsByIds", "store.sql_post.get_posts_by_ids.app_error", nil, err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDeletedPostIds returns the ids from the given list that don't belong to any existing post. This reads from the
+// master so that posts which haven't reached a read replica yet aren't included.
+func (s SqlPostStore) GetDeletedPostIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := make(map[string]interface{})
+		idQuery := ""
+
+		for index, postId := range postIds {
+			if len(idQuery) > 0 {
+				idQuery += ", "
+			}
+
+			props["postId"+strconv.Itoa(index)] = postId
+			idQuery += ":postId" + strconv.Itoa(index)
+		}
+
+		var existingIds []string
+		if len(postIds) == 0 {
+			result.Data = []string{}
+		} else if _, err := s.GetMaster().Select(&existingIds, "SELECT Id FROM Posts WHERE Id IN ("+idQuery+") AND DeleteAt = 0", props); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetDeletedPostIds", "store.sql_post.get_deleted_post_ids.app_error", nil, err.Error())
+		} else {
+			existing := make(map[string]bool, len(existingIds))
+			for _, id := range existingIds {
+				existing[id] = true
+			}
+
+			deletedIds := []string{}
+			for _, postId := range postIds {
+				if !existing[postId] {
+					deletedIds = append(deletedIds, postId)
+				}
+			}
+
+			result.Data = deletedIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetPostsBatchForIndexing returns up to limit posts created after the post with the given creation time and id in
+// the order that they were created so that every post can be visited by repeatedly calling it with the last result.
+func (s SqlPostStore) GetPostsBatchForIndexing(startTime int64, startPostId string, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.Post
+		_, err := s.GetReplica().Select(&posts,
+			`SELECT
+				*
+			FROM
+				Posts
+			WHERE
+				DeleteAt = 0
+				AND (CreateAt > :StartTime OR (CreateAt = :StartTime AND Id > :StartPostId))
+			ORDER BY CreateAt ASC, Id ASC
+			LIMIT :Limit`,
+			map[string]interface{}{"StartTime": startTime, "StartPostId": startPostId, "Limit": limit})
+
+		if err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPostsBatchForIndexing", "store.sql_post.get_posts_batch_for_indexing.app_error", nil, err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (s SqlPostStore) AnalyticsUserCountsWithPostsByDay(teamId string) StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
@@ -1073,3 +1410,178 @@ func (s SqlPostStore) AnalyticsPostCount(teamId string, mustHaveFile bool, mustH
 
 	return storeChannel
 }
+
+// buildIdListQuery adds each of the given ids to props and returns a comma-separated list of their placeholders for
+// use in an IN clause.
+func buildIdListQuery(name string, ids []string, props map[string]interface{}) string {
+	idQuery := ""
+
+	for index, id := range ids {
+		if len(idQuery) > 0 {
+			idQuery += ", "
+		}
+
+		props[name+strconv.Itoa(index)] = id
+		idQuery += ":" + name + strconv.Itoa(index)
+	}
+
+	return idQuery
+}
+
+// buildRetentionChannelQuery returns a condition matching the channels covered by a data retention policy. A policy
+// for a single team covers that team's channels, while the global policy covers every other channel including direct
+// and group messages.
+func buildRetentionChannelQuery(column string, teamId string, excludeTeamIds []string, props map[string]interface{}) string {
+	if len(teamId) > 0 {
+		props["TeamId"] = teamId
+		return column + " IN (SELECT Id FROM Channels WHERE TeamId = :TeamId)"
+	}
+
+	if len(excludeTeamIds) == 0 {
+		return "1 = 1"
+	}
+
+	return column + " NOT IN (SELECT Id FROM Channels WHERE TeamId IN (" + buildIdListQuery("ExcludeTeamId", excludeTeamIds, props) + "))"
+}
+
+// GetPostIdsForRetention returns the ids of up to limit posts created before endTime that are covered by a data
+// retention policy, skipping any preserved by the given legal holds. Deleted posts and the old versions of edited
+// posts are included since they're still stored.
+func (s SqlPostStore) GetPostIdsForRetention(endTime int64, teamId string, excludeTeamIds []string, legalHolds []*model.LegalHold, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime, "Limit": limit}
+		channelQuery := buildRetentionChannelQuery("ChannelId", teamId, excludeTeamIds, props)
+		legalHoldQuery := buildLegalHoldQuery(legalHolds, "UserId IN (%s)", "ChannelId IN (%s)", "CreateAt", props)
+
+		var ids []string
+		if _, err := s.GetMaster().Select(&ids,
+			`SELECT
+				Id
+			FROM
+				Posts
+			WHERE
+				CreateAt < :EndTime
+				AND `+channelQuery+`
+				`+legalHoldQuery+`
+			LIMIT :Limit`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.GetPostIdsForRetention", "store.sql_post.get_post_ids_for_retention.app_error", nil, "team_id="+teamId+", err="+err.Error())
+		} else {
+			result.Data = ids
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlPostStore) PermanentDeleteByIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) > 0 {
+			props := make(map[string]interface{})
+			idQuery := buildIdListQuery("PostId", postIds, props)
+
+			if _, err := s.GetMaster().Exec("DELETE FROM Posts WHERE Id IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlPostStore.PermanentDeleteByIds", "store.sql_post.permanent_delete_by_ids.app_error", nil, err.Error())
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// CountPostsHeldForRetention returns how many of the posts created before endTime that are covered by a data retention
+// policy are being preserved by the given legal hold.
+func (s SqlPostStore) CountPostsHeldForRetention(endTime int64, teamId string, excludeTeamIds []string, hold *model.LegalHold) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"EndTime": endTime}
+		channelQuery := buildRetentionChannelQuery("ChannelId", teamId, excludeTeamIds, props)
+
+		holdQuery := buildLegalHoldQuery([]*model.LegalHold{hold}, "UserId IN (%s)", "ChannelId IN (%s)", "CreateAt", props)
+		if holdQuery == "" {
+			result.Data = int64(0)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if count, err := s.GetMaster().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				Posts
+			WHERE
+				CreateAt < :EndTime
+				AND `+channelQuery+`
+				AND NOT (1 = 1 `+holdQuery+`)`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.CountPostsHeldForRetention", "store.sql_post.count_posts_held_for_retention.app_error", nil, "legal_hold_id="+hold.Id+", team_id="+teamId+", err="+err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// CountPostsForLegalHold returns how many of the posts preserved by a legal hold were made by the given user or in the
+// given team's channels.
+func (s SqlPostStore) CountPostsForLegalHold(hold *model.LegalHold, userId string, teamId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		props := map[string]interface{}{"UserId": userId, "TeamId": teamId}
+
+		// Reuse the exclusion query by negating it so that only posts covered by the hold are counted
+		holdQuery := buildLegalHoldQuery([]*model.LegalHold{hold}, "UserId IN (%s)", "ChannelId IN (%s)", "CreateAt", props)
+		if holdQuery == "" {
+			result.Data = int64(0)
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		scopeQuery := "UserId = :UserId"
+		if len(teamId) > 0 {
+			scopeQuery = "ChannelId IN (SELECT Id FROM Channels WHERE TeamId = :TeamId)"
+		}
+
+		if count, err := s.GetReplica().SelectInt(
+			`SELECT
+				COUNT(*)
+			FROM
+				Posts
+			WHERE
+				`+scopeQuery+`
+				AND NOT (1 = 1 `+holdQuery+`)`, props); err != nil {
+			result.Err = model.NewLocAppError("SqlPostStore.CountPostsForLegalHold", "store.sql_post.count_posts_for_legal_hold.app_error", nil, "legal_hold_id="+hold.Id+", err="+err.Error())
+		} else {
+			result.Data = count
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -868,6 +868,106 @@ func TestPostStoreSearch(t *testing.T) {
 	if len(r13.Order) != 2 {
 		t.Fatal("returned wrong search result")
 	}
+
+	o6 := &model.Post{}
+	o6.ChannelId = c1.Id
+	o6.UserId = model.NewId()
+	o6.Message = "the york stadium is new"
+	o6.CreateAt = time.Date(2017, time.January, 2, 12, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	o6 = (<-store.Post().Save(o6)).Data.(*model.Post)
+
+	r14 := (<-store.Post().Search(teamId, userId, &model.SearchParams{Terms: "\"new york\"", IsHashtag: false})).Data.(*model.PostList)
+	if len(r14.Order) != 1 || r14.Order[0] != o1.Id {
+		t.Fatal("phrase search should only match words in order")
+	}
+
+	r15 := (<-store.Post().Search(teamId, userId, &model.SearchParams{Terms: "new york", IsHashtag: false})).Data.(*model.PostList)
+	if len(r15.Order) != 2 {
+		t.Fatal("returned wrong search result")
+	}
+
+	r16 := (<-store.Post().Search(teamId, userId, &model.SearchParams{Terms: "york", IsHashtag: false, OnDate: "2017-01-02"})).Data.(*model.PostList)
+	if len(r16.Order) != 1 || r16.Order[0] != o6.Id {
+		t.Fatal("returned wrong search result for on date")
+	}
+
+	r17 := (<-store.Post().Search(teamId, userId, &model.SearchParams{Terms: "york", IsHashtag: false, BeforeDate: "2017-01-02"})).Data.(*model.PostList)
+	if len(r17.Order) != 0 {
+		t.Fatal("returned wrong search result for before date")
+	}
+
+	r18 := (<-store.Post().Search(teamId, userId, &model.SearchParams{Terms: "york", IsHashtag: false, AfterDate: "2017-01-02"})).Data.(*model.PostList)
+	if len(r18.Order) != 1 || r18.Order[0] != o1.Id {
+		t.Fatal("returned wrong search result for after date")
+	}
+
+	r19 := (<-store.Post().Search(teamId, userId, &model.SearchParams{Terms: "", IsHashtag: true, BeforeDate: "2017-01-03"})).Data.(*model.PostList)
+	if len(r19.Order) != 1 || r19.Order[0] != o6.Id {
+		t.Fatal("returned wrong search result for date without terms")
+	}
+
+	if snippet := r14.Snippets[o1.Id]; snippet != "corey mattermost <mark>new</mark> <mark>york</mark>" {
+		t.Fatal("returned wrong snippet " + snippet)
+	}
+
+	r20 := (<-store.Post().Search(teamId, userId, &model.SearchParams{Terms: "new york corey", IsHashtag: false, OrTerms: true})).Data.(*model.PostList)
+	if len(r20.Order) != 2 || r20.Order[0] != o1.Id || r20.Order[1] != o6.Id {
+		t.Fatal("results should be ordered by relevance")
+	}
+}
+
+func TestBuildPostgresSearchTerms(t *testing.T) {
+	if terms := buildPostgresSearchTerms([]string{"apple", "banana"}, false, true); terms != "apple & banana" {
+		t.Fatal("incorrect terms " + terms)
+	}
+
+	if terms := buildPostgresSearchTerms([]string{"apple", "banana"}, true, true); terms != "apple | banana" {
+		t.Fatal("incorrect terms " + terms)
+	}
+
+	if terms := buildPostgresSearchTerms([]string{"app*", "\"banana split\""}, false, true); terms != "app:* & (banana <-> split)" {
+		t.Fatal("incorrect terms " + terms)
+	}
+
+	if terms := buildPostgresSearchTerms([]string{"app*", "\"banana split\""}, false, false); terms != "app:* & (banana & split)" {
+		t.Fatal("incorrect terms " + terms)
+	}
+
+	if terms := buildPostgresSearchTerms([]string{"apple's", "(banana)", "&|!"}, false, true); terms != "apple & s & banana" {
+		t.Fatal("incorrect terms " + terms)
+	}
+}
+
+func TestBuildPhraseSearchPatterns(t *testing.T) {
+	if patterns := buildPhraseSearchPatterns([]string{"apple", "\"banana\""}); len(patterns) != 0 {
+		t.Fatal("shouldn't have returned patterns for single words")
+	}
+
+	if patterns := buildPhraseSearchPatterns([]string{"apple", "\"banana  split\""}); len(patterns) != 1 || patterns[0] != "%banana split%" {
+		t.Fatal("incorrect patterns", patterns)
+	}
+
+	if patterns := buildPhraseSearchPatterns([]string{"\"100% real_fruit\""}); len(patterns) != 1 || patterns[0] != "%100\\% real\\_fruit%" {
+		t.Fatal("incorrect patterns", patterns)
+	}
+}
+
+func TestBuildMysqlSearchTerms(t *testing.T) {
+	if terms := buildMysqlSearchTerms([]string{"apple", "banana"}, false); terms != "+apple +banana" {
+		t.Fatal("incorrect terms " + terms)
+	}
+
+	if terms := buildMysqlSearchTerms([]string{"apple", "banana"}, true); terms != "apple banana" {
+		t.Fatal("incorrect terms " + terms)
+	}
+
+	if terms := buildMysqlSearchTerms([]string{"app*", "\"banana split\""}, false); terms != "+app* +\"banana split\"" {
+		t.Fatal("incorrect terms " + terms)
+	}
+
+	if terms := buildMysqlSearchTerms([]string{"(apple)", "<>"}, false); terms != "+apple" {
+		t.Fatal("incorrect terms " + terms)
+	}
 }
 
 func TestUserCountsWithPostsByDay(t *testing.T) {
@@ -1000,6 +1100,40 @@ func TestPostCountsByDay(t *testing.T) {
 	}
 }
 
+func TestPostStorePinnedPosts(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: channelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: channelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o3 := Must(store.Post().Save(&model.Post{ChannelId: channelId, UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+
+	Must(store.Post().SetPinned(o1.Id, true))
+	Must(store.Post().SetPinned(o3.Id, true))
+	Must(store.Post().Delete(o3.Id, model.GetMillis()))
+
+	if list := Must(store.Post().GetPinnedPosts(channelId)).(*model.PostList); len(list.Order) != 1 || list.Order[0] != o1.Id {
+		t.Fatal("should've only returned the pinned post that isn't deleted")
+	} else if !list.Posts[o1.Id].IsPinned {
+		t.Fatal("post should be pinned")
+	}
+
+	if count := Must(store.Post().GetPinnedPostCount(channelId)).(int64); count != 1 {
+		t.Fatal("got the wrong pinned post count")
+	}
+
+	if post := Must(store.Post().Get(o2.Id)).(*model.PostList).Posts[o2.Id]; post.IsPinned {
+		t.Fatal("post shouldn't be pinned")
+	}
+
+	Must(store.Post().SetPinned(o1.Id, false))
+
+	if count := Must(store.Post().GetPinnedPostCount(channelId)).(int64); count != 0 {
+		t.Fatal("should've unpinned the post")
+	}
+}
+
 func TestPostStoreGetFlaggedPosts(t *testing.T) {
 	Setup()
 
@@ -1086,3 +1220,186 @@ func TestPostStoreGetFlaggedPosts(t *testing.T) {
 		t.Fatal("should have 2 posts")
 	}
 }
+
+func TestPostStoreGetPostsByIds(t *testing.T) {
+	Setup()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o3 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	Must(store.Post().Delete(o3.Id, model.GetMillis()))
+
+	if r := <-store.Post().GetPostsByIds([]string{o1.Id, o2.Id, o3.Id, model.NewId()}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if posts := r.Data.([]*model.Post); len(posts) != 2 {
+		t.Fatal("should've only returned the posts that exist and aren't deleted")
+	}
+
+	if r := <-store.Post().GetPostsByIds([]string{}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]*model.Post)) != 0 {
+		t.Fatal("empty post ids - should have returned nothing")
+	}
+}
+
+func TestPostStoreGetDeletedPostIds(t *testing.T) {
+	Setup()
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b"})).(*model.Post)
+	Must(store.Post().Delete(o2.Id, model.GetMillis()))
+	missingId := model.NewId()
+
+	if r := <-store.Post().GetDeletedPostIds([]string{o1.Id, o2.Id, missingId}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if ids := r.Data.([]string); len(ids) != 2 || ids[0] != o2.Id || ids[1] != missingId {
+		t.Fatal("should've only returned the ids of the deleted and missing posts", ids)
+	}
+
+	if r := <-store.Post().GetDeletedPostIds([]string{}); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if len(r.Data.([]string)) != 0 {
+		t.Fatal("empty post ids - should have returned nothing")
+	}
+}
+
+func TestPostStoreGetPostsBatchForIndexing(t *testing.T) {
+	Setup()
+
+	startTime := model.GetMillis() + 100000000
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: startTime + 1})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: startTime + 2})).(*model.Post)
+	o3 := Must(store.Post().Save(&model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: startTime + 2})).(*model.Post)
+
+	if o3.Id < o2.Id {
+		o2, o3 = o3, o2
+	}
+
+	if r := <-store.Post().GetPostsBatchForIndexing(startTime, "", 2); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if posts := r.Data.([]*model.Post); len(posts) != 2 || posts[0].Id != o1.Id || posts[1].Id != o2.Id {
+		t.Fatal("returned the wrong posts")
+	}
+
+	if r := <-store.Post().GetPostsBatchForIndexing(o2.CreateAt, o2.Id, 2); r.Err != nil {
+		t.Fatal(r.Err)
+	} else if posts := r.Data.([]*model.Post); len(posts) != 1 || posts[0].Id != o3.Id {
+		t.Fatal("should've continued from the last post in the previous batch")
+	}
+}
+
+func TestPostStoreRetention(t *testing.T) {
+	Setup()
+
+	team1 := Must(store.Team().Save(&model.Team{DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Email: model.NewId() + "@nowhere.com", Type: model.TEAM_OPEN})).(*model.Team)
+	team2 := Must(store.Team().Save(&model.Team{DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Email: model.NewId() + "@nowhere.com", Type: model.TEAM_OPEN})).(*model.Team)
+	channel1 := Must(store.Channel().Save(&model.Channel{TeamId: team1.Id, DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	channel2 := Must(store.Channel().Save(&model.Channel{TeamId: team2.Id, DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	o1 := Must(store.Post().Save(&model.Post{ChannelId: channel1.Id, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: 1000})).(*model.Post)
+	o2 := Must(store.Post().Save(&model.Post{ChannelId: channel1.Id, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: 3000})).(*model.Post)
+	o3 := Must(store.Post().Save(&model.Post{ChannelId: channel2.Id, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: 1000})).(*model.Post)
+
+	if ids := Must(store.Post().GetPostIdsForRetention(2000, team1.Id, nil, nil, 100)).([]string); len(ids) != 1 || ids[0] != o1.Id {
+		t.Fatal("should've only returned the old post from the team")
+	}
+
+	holds := []*model.LegalHold{{ChannelIds: []string{channel1.Id}, StartAt: 500, EndAt: 1500}}
+	if ids := Must(store.Post().GetPostIdsForRetention(2000, team1.Id, nil, holds, 100)).([]string); len(ids) != 0 {
+		t.Fatal("shouldn't have returned a post preserved by a legal hold")
+	}
+
+	holds = []*model.LegalHold{{UserIds: []string{o1.UserId}, StartAt: 1500}}
+	if ids := Must(store.Post().GetPostIdsForRetention(2000, team1.Id, nil, holds, 100)).([]string); len(ids) != 1 || ids[0] != o1.Id {
+		t.Fatal("should've returned a post from before the legal hold started")
+	}
+
+	ids := Must(store.Post().GetPostIdsForRetention(2000, "", []string{team1.Id}, nil, 10000)).([]string)
+	found := false
+	for _, id := range ids {
+		if id == o1.Id || id == o2.Id {
+			t.Fatal("shouldn't have returned a post from an excluded team")
+		} else if id == o3.Id {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("should've returned the old post from the other team")
+	}
+
+	Must(store.Reaction().Save(&model.Reaction{UserId: model.NewId(), PostId: o1.Id, EmojiName: "smile"}))
+
+	Must(store.Reaction().PermanentDeleteByPostIds([]string{o1.Id}))
+	Must(store.Post().PermanentDeleteByIds([]string{o1.Id}))
+
+	if reactions := Must(store.Reaction().GetForPost(o1.Id)).([]*model.Reaction); len(reactions) != 0 {
+		t.Fatal("should've deleted the reaction")
+	}
+
+	if result := <-store.Post().Get(o1.Id); result.Err == nil {
+		t.Fatal("should've deleted the post")
+	}
+
+	if result := <-store.Post().Get(o2.Id); result.Err != nil {
+		t.Fatal("shouldn't have deleted the newer post")
+	}
+}
+
+func TestPostStoreCountPostsForLegalHold(t *testing.T) {
+	Setup()
+
+	team := Must(store.Team().Save(&model.Team{DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Email: model.NewId() + "@nowhere.com", Type: model.TEAM_OPEN})).(*model.Team)
+	channel1 := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	channel2 := Must(store.Channel().Save(&model.Channel{TeamId: model.NewId(), DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	userId := model.NewId()
+
+	Must(store.Post().Save(&model.Post{ChannelId: channel1.Id, UserId: userId, Message: "a" + model.NewId() + "b", CreateAt: 1000}))
+	Must(store.Post().Save(&model.Post{ChannelId: channel2.Id, UserId: userId, Message: "a" + model.NewId() + "b", CreateAt: 1000}))
+	Must(store.Post().Save(&model.Post{ChannelId: channel2.Id, UserId: userId, Message: "a" + model.NewId() + "b", CreateAt: 3000}))
+
+	hold := &model.LegalHold{ChannelIds: []string{channel2.Id}, StartAt: 500, EndAt: 2000}
+
+	if count := Must(store.Post().CountPostsForLegalHold(hold, userId, "")).(int64); count != 1 {
+		t.Fatal("should've counted the user's post in the held channel during the hold", count)
+	}
+
+	if count := Must(store.Post().CountPostsForLegalHold(hold, "", team.Id)).(int64); count != 0 {
+		t.Fatal("shouldn't have counted posts outside of the held channel", count)
+	}
+
+	hold = &model.LegalHold{UserIds: []string{userId}, StartAt: 500}
+
+	if count := Must(store.Post().CountPostsForLegalHold(hold, "", team.Id)).(int64); count != 1 {
+		t.Fatal("should've counted the held user's post in the team", count)
+	}
+}
+
+func TestPostStoreCountPostsHeldForRetention(t *testing.T) {
+	Setup()
+
+	team := Must(store.Team().Save(&model.Team{DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Email: model.NewId() + "@nowhere.com", Type: model.TEAM_OPEN})).(*model.Team)
+	channel1 := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+	channel2 := Must(store.Channel().Save(&model.Channel{TeamId: team.Id, DisplayName: "DisplayName", Name: "a" + model.NewId() + "b", Type: model.CHANNEL_OPEN})).(*model.Channel)
+
+	Must(store.Post().Save(&model.Post{ChannelId: channel1.Id, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: 1000}))
+	Must(store.Post().Save(&model.Post{ChannelId: channel2.Id, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: 1000}))
+	Must(store.Post().Save(&model.Post{ChannelId: channel2.Id, UserId: model.NewId(), Message: "a" + model.NewId() + "b", CreateAt: 3000}))
+
+	hold := &model.LegalHold{ChannelIds: []string{channel2.Id}, StartAt: 500}
+
+	if count := Must(store.Post().CountPostsHeldForRetention(2000, team.Id, nil, hold)).(int64); count != 1 {
+		t.Fatal("should've only counted the held post older than the end time", count)
+	}
+
+	if count := Must(store.Post().CountPostsHeldForRetention(2000, "", []string{team.Id}, hold)).(int64); count != 0 {
+		t.Fatal("shouldn't have counted posts in excluded teams", count)
+	}
+
+	hold = &model.LegalHold{ChannelIds: []string{channel2.Id}, StartAt: 1500}
+
+	if count := Must(store.Post().CountPostsHeldForRetention(2000, team.Id, nil, hold)).(int64); count != 0 {
+		t.Fatal("shouldn't have counted posts from before the hold", count)
+	}
+}
@@ -228,3 +228,25 @@ func (s SqlReactionStore) DeleteAllWithEmojiName(emojiName string) StoreChannel
 
 	return storeChannel
 }
+
+func (s SqlReactionStore) PermanentDeleteByPostIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) > 0 {
+			props := make(map[string]interface{})
+			idQuery := buildIdListQuery("PostId", postIds, props)
+
+			if _, err := s.GetMaster().Exec("DELETE FROM Reactions WHERE PostId IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlReactionStore.PermanentDeleteByPostIds", "store.sql_reaction.permanent_delete_by_post_ids.app_error", nil, err.Error())
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlScheduledPostStore struct {
+	*SqlStore
+}
+
+func NewSqlScheduledPostStore(sqlStore *SqlStore) ScheduledPostStore {
+	s := &SqlScheduledPostStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ScheduledPost{}, "ScheduledPosts").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(4000)
+		table.ColMap("Props").SetMaxSize(8000)
+		table.ColMap("FileIds").SetMaxSize(150)
+		table.ColMap("ErrorMessage").SetMaxSize(1024)
+	}
+
+	return s
+}
+
+func (s SqlScheduledPostStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_scheduledposts_user_id", "ScheduledPosts", "UserId")
+	s.CreateIndexIfNotExists("idx_scheduledposts_scheduled_at", "ScheduledPosts", "ScheduledAt")
+}
+
+func (s SqlScheduledPostStore) Save(post *model.ScheduledPost) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		post.PreSave()
+		if result.Err = post.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(post); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.app_error", nil, "id="+post.Id+", "+err.Error())
+		} else {
+			result.Data = post
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Update saves the changes to a scheduled post as long as its ProcessedAt hasn't changed from oldProcessedAt so that a
+// post can't be changed while a server is publishing it.
+func (s SqlScheduledPostStore) Update(post *model.ScheduledPost, oldProcessedAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		post.PreUpdate()
+		if result.Err = post.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`UPDATE
+				ScheduledPosts
+			SET
+				UpdateAt = :UpdateAt,
+				Message = :Message,
+				Props = :Props,
+				FileIds = :FileIds,
+				ScheduledAt = :ScheduledAt,
+				ProcessedAt = :ProcessedAt,
+				ErrorMessage = :ErrorMessage
+			WHERE
+				Id = :Id
+				AND ProcessedAt = :OldProcessedAt`, map[string]interface{}{
+				"Id":             post.Id,
+				"UpdateAt":       post.UpdateAt,
+				"Message":        post.Message,
+				"Props":          model.StringInterfaceToJson(post.Props),
+				"FileIds":        model.ArrayToJson(post.FileIds),
+				"ScheduledAt":    post.ScheduledAt,
+				"ProcessedAt":    post.ProcessedAt,
+				"ErrorMessage":   post.ErrorMessage,
+				"OldProcessedAt": oldProcessedAt,
+			}); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.update.app_error", nil, "id="+post.Id+", "+err.Error())
+		} else if rows, _ := sqlResult.RowsAffected(); rows != 1 {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.update.conflict.app_error", nil, "id="+post.Id)
+			result.Err.StatusCode = http.StatusConflict
+		} else {
+			result.Data = post
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var post *model.ScheduledPost
+
+		// Read from the master since posts are claimed by whichever server publishes them
+		if err := s.GetMaster().SelectOne(&post, "SELECT * FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = post
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetForUser returns the posts that a user has scheduled from a team ordered by when they'll be published.
+func (s SqlScheduledPostStore) GetForUser(teamId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.ScheduledPost
+
+		if _, err := s.GetReplica().Select(&posts,
+			`SELECT
+				*
+			FROM
+				ScheduledPosts
+			WHERE
+				UserId = :UserId
+				AND TeamId = :TeamId
+			ORDER BY
+				ScheduledAt, Id`, map[string]interface{}{"TeamId": teamId, "UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.GetForUser", "store.sql_scheduled_post.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetDue returns the posts that are waiting to be published at or before the given time along with any posts that
+// were claimed before expiredBefore without being published or failing, such as if the server publishing them stopped.
+func (s SqlScheduledPostStore) GetDue(time int64, expiredBefore int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var posts []*model.ScheduledPost
+
+		if _, err := s.GetMaster().Select(&posts,
+			`SELECT
+				*
+			FROM
+				ScheduledPosts
+			WHERE
+				ScheduledAt <= :Time
+				AND (ProcessedAt = 0 OR (ProcessedAt < :ExpiredBefore AND ErrorMessage = ''))
+			ORDER BY
+				ScheduledAt, Id
+			LIMIT :Limit`, map[string]interface{}{"Time": time, "ExpiredBefore": expiredBefore, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.GetDue", "store.sql_scheduled_post.get_due.app_error", nil, err.Error())
+		} else {
+			result.Data = posts
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Claim marks a scheduled post as being processed at the given time so that it's only published once even if multiple
+// servers try to publish it at the same time. A claim made before expiredBefore that didn't lead to the post being
+// published or failing has expired and can be taken over. The result's data is true if this call claimed the post.
+func (s SqlScheduledPostStore) Claim(id string, time int64, expiredBefore int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`UPDATE
+				ScheduledPosts
+			SET
+				ProcessedAt = :ProcessedAt
+			WHERE
+				Id = :Id
+				AND (ProcessedAt = 0 OR (ProcessedAt < :ExpiredBefore AND ErrorMessage = ''))`, map[string]interface{}{"Id": id, "ProcessedAt": time, "ExpiredBefore": expiredBefore}); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.Claim", "store.sql_scheduled_post.claim.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.Delete", "store.sql_scheduled_post.delete.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlScheduledPostStore) PermanentDeleteByUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE UserId = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlScheduledPostStore.PermanentDeleteByUser", "store.sql_scheduled_post.permanent_delete_by_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestScheduledPostStoreSaveGetUpdate(t *testing.T) {
+	Setup()
+
+	o1 := &model.ScheduledPost{
+		UserId:      model.NewId(),
+		TeamId:      model.NewId(),
+		ChannelId:   model.NewId(),
+		Message:     "message",
+		ScheduledAt: model.GetMillis() + 100000,
+	}
+
+	if result := <-store.ScheduledPost().Save(o1); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.ScheduledPost().Get(o1.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if saved := result.Data.(*model.ScheduledPost); saved.Message != o1.Message || saved.ScheduledAt != o1.ScheduledAt {
+		t.Fatal("returned the wrong scheduled post")
+	}
+
+	o1.Message = "updated"
+	if result := <-store.ScheduledPost().Update(o1, 0); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if saved := Must(store.ScheduledPost().Get(o1.Id)).(*model.ScheduledPost); saved.Message != "updated" {
+		t.Fatal("should've updated the scheduled post")
+	}
+
+	o1.Message = "conflict"
+	if result := <-store.ScheduledPost().Update(o1, 1000); result.Err == nil {
+		t.Fatal("shouldn't update a scheduled post that's been claimed since it was read")
+	}
+
+	if saved := Must(store.ScheduledPost().Get(o1.Id)).(*model.ScheduledPost); saved.Message != "updated" {
+		t.Fatal("shouldn't have changed the scheduled post")
+	}
+
+	if result := <-store.ScheduledPost().Get(model.NewId()); result.Err == nil {
+		t.Fatal("shouldn't have found a scheduled post")
+	}
+
+	if result := <-store.ScheduledPost().Save(&model.ScheduledPost{UserId: model.NewId()}); result.Err == nil {
+		t.Fatal("shouldn't save an invalid scheduled post")
+	}
+}
+
+func TestScheduledPostStoreGetForUser(t *testing.T) {
+	Setup()
+
+	userId := model.NewId()
+	teamId := model.NewId()
+
+	o1 := Must(store.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, TeamId: teamId, ChannelId: model.NewId(), Message: "a", ScheduledAt: 2000})).(*model.ScheduledPost)
+	o2 := Must(store.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, TeamId: teamId, ChannelId: model.NewId(), Message: "b", ScheduledAt: 1000})).(*model.ScheduledPost)
+	Must(store.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, TeamId: model.NewId(), ChannelId: model.NewId(), Message: "c", ScheduledAt: 1000}))
+	Must(store.ScheduledPost().Save(&model.ScheduledPost{UserId: model.NewId(), TeamId: teamId, ChannelId: model.NewId(), Message: "d", ScheduledAt: 1000}))
+
+	if posts := Must(store.ScheduledPost().GetForUser(teamId, userId)).([]*model.ScheduledPost); len(posts) != 2 {
+		t.Fatal("should've only returned the user's posts on the team")
+	} else if posts[0].Id != o2.Id || posts[1].Id != o1.Id {
+		t.Fatal("should've ordered the posts by when they're scheduled")
+	}
+
+	Must(store.ScheduledPost().PermanentDeleteByUser(userId))
+
+	if posts := Must(store.ScheduledPost().GetForUser(teamId, userId)).([]*model.ScheduledPost); len(posts) != 0 {
+		t.Fatal("should've deleted the user's posts")
+	}
+}
+
+func TestScheduledPostStoreGetDueAndClaim(t *testing.T) {
+	Setup()
+
+	now := model.GetMillis()
+
+	o1 := Must(store.ScheduledPost().Save(&model.ScheduledPost{UserId: model.NewId(), TeamId: model.NewId(), ChannelId: model.NewId(), Message: "a", ScheduledAt: now - 1000})).(*model.ScheduledPost)
+	o2 := Must(store.ScheduledPost().Save(&model.ScheduledPost{UserId: model.NewId(), TeamId: model.NewId(), ChannelId: model.NewId(), Message: "b", ScheduledAt: now + 100000})).(*model.ScheduledPost)
+
+	found := false
+	for _, post := range Must(store.ScheduledPost().GetDue(now, now-60000, 1000)).([]*model.ScheduledPost) {
+		if post.Id == o2.Id {
+			t.Fatal("shouldn't return posts that aren't due")
+		} else if post.Id == o1.Id {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should've returned the post that's due")
+	}
+
+	if claimed := Must(store.ScheduledPost().Claim(o1.Id, now, now-60000)).(bool); !claimed {
+		t.Fatal("should've claimed the post")
+	}
+
+	if claimed := Must(store.ScheduledPost().Claim(o1.Id, now, now-60000)).(bool); claimed {
+		t.Fatal("shouldn't claim a post twice")
+	}
+
+	for _, post := range Must(store.ScheduledPost().GetDue(now, now-60000, 1000)).([]*model.ScheduledPost) {
+		if post.Id == o1.Id {
+			t.Fatal("shouldn't return posts that have been claimed")
+		}
+	}
+
+	// a claim that's expired can be taken over by another server
+	later := now + 120000
+	found = false
+	for _, post := range Must(store.ScheduledPost().GetDue(later, later-60000, 1000)).([]*model.ScheduledPost) {
+		if post.Id == o1.Id {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("should've returned the post with an expired claim")
+	}
+
+	if claimed := Must(store.ScheduledPost().Claim(o1.Id, later, later-60000)).(bool); !claimed {
+		t.Fatal("should've claimed the post once the first claim expired")
+	}
+
+	Must(store.ScheduledPost().Delete(o1.Id))
+
+	if result := <-store.ScheduledPost().Get(o1.Id); result.Err == nil {
+		t.Fatal("should've deleted the post")
+	}
+}
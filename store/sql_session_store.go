@@ -23,7 +23,7 @@ func NewSqlSessionStore(sqlStore *SqlStore) SessionStore {
 		table.ColMap("UserId").SetMaxSize(26)
 		table.ColMap("DeviceId").SetMaxSize(512)
 		table.ColMap("Roles").SetMaxSize(64)
-		table.ColMap("Props").SetMaxSize(1000)
+		table.ColMap("Props").SetMaxSize(4000)
 	}
 
 	return us
@@ -318,6 +318,24 @@ func (me SqlSessionStore) UpdateDeviceId(id string, deviceId string, expiresAt i
 	return storeChannel
 }
 
+func (me SqlSessionStore) UpdateProps(session *model.Session) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+		if _, err := me.GetMaster().Exec("UPDATE Sessions SET Props = :Props WHERE Id = :Id", map[string]interface{}{"Props": model.MapToJson(session.Props), "Id": session.Id}); err != nil {
+			result.Err = model.NewLocAppError("SqlSessionStore.UpdateProps", "store.sql_session.update_props.app_error", nil, err.Error())
+		} else {
+			result.Data = session
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (me SqlSessionStore) AnalyticsSessionCount() StoreChannel {
 	storeChannel := make(StoreChannel, 1)
 
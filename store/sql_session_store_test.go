@@ -197,6 +197,25 @@ func TestSessionUpdateDeviceId(t *testing.T) {
 	}
 }
 
+func TestSessionUpdateProps(t *testing.T) {
+	Setup()
+
+	s1 := model.Session{}
+	s1.UserId = model.NewId()
+	Must(store.Session().Save(&s1))
+
+	s1.AddProp(model.SESSION_PROP_OPENID_REFRESH_TOKEN, "token")
+	if err := (<-store.Session().UpdateProps(&s1)).Err; err != nil {
+		t.Fatal(err)
+	}
+
+	if r1 := <-store.Session().Get(s1.Id); r1.Err != nil {
+		t.Fatal(r1.Err)
+	} else if r1.Data.(*model.Session).Props[model.SESSION_PROP_OPENID_REFRESH_TOKEN] != "token" {
+		t.Fatal("Props not updated correctly")
+	}
+}
+
 func TestSessionStoreUpdateLastActivityAt(t *testing.T) {
 	Setup()
 
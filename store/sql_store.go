@@ -35,6 +35,9 @@ const (
 	INDEX_TYPE_FULL_TEXT = "full_text"
 	INDEX_TYPE_DEFAULT   = "default"
 	MAX_DB_CONN_LIFETIME = 15
+
+	// the first version of Postgres with the <-> operator for matching phrases
+	POSTGRES_PHRASE_SEARCH_VERSION = 90600
 )
 
 const (
@@ -65,28 +68,40 @@ const (
 )
 
 type SqlStore struct {
-	master        *gorp.DbMap
-	replicas      []*gorp.DbMap
-	team          TeamStore
-	channel       ChannelStore
-	post          PostStore
-	user          UserStore
-	audit         AuditStore
-	compliance    ComplianceStore
-	session       SessionStore
-	oauth         OAuthStore
-	system        SystemStore
-	webhook       WebhookStore
-	command       CommandStore
-	preference    PreferenceStore
-	license       LicenseStore
-	recovery      PasswordRecoveryStore
-	emoji         EmojiStore
-	status        StatusStore
-	fileInfo      FileInfoStore
-	reaction      ReactionStore
-	SchemaVersion string
-	rrCounter     int64
+	master                  *gorp.DbMap
+	replicas                []*gorp.DbMap
+	team                    TeamStore
+	channel                 ChannelStore
+	post                    PostStore
+	user                    UserStore
+	audit                   AuditStore
+	compliance              ComplianceStore
+	session                 SessionStore
+	oauth                   OAuthStore
+	system                  SystemStore
+	webhook                 WebhookStore
+	command                 CommandStore
+	preference              PreferenceStore
+	license                 LicenseStore
+	recovery                PasswordRecoveryStore
+	emoji                   EmojiStore
+	status                  StatusStore
+	fileInfo                FileInfoStore
+	reaction                ReactionStore
+	uploadSession           UploadSessionStore
+	thread                  ThreadStore
+	scheduledPost           ScheduledPostStore
+	outgoingWebhookDelivery OutgoingWebhookDeliveryStore
+	draft                   DraftStore
+	legalHold               LegalHoldStore
+	cluster                 ClusterDiscoveryStore
+	mfaRecovery             MfaRecoveryCodeStore
+	accessToken             PersonalAccessTokenStore
+	bot                     BotStore
+	eventSubscription       EventSubscriptionStore
+	SchemaVersion           string
+	postgresVersion         int64
+	rrCounter               int64
 }
 
 func initConnection() *SqlStore {
@@ -111,6 +126,15 @@ func initConnection() *SqlStore {
 	}
 
 	sqlStore.SchemaVersion = sqlStore.GetCurrentSchemaVersion()
+
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+		if version, err := sqlStore.GetMaster().SelectInt("SHOW server_version_num"); err != nil {
+			l4g.Warn(utils.T("store.sql.postgres_version.warn"), err.Error())
+		} else {
+			sqlStore.postgresVersion = version
+		}
+	}
+
 	return sqlStore
 }
 
@@ -136,6 +160,17 @@ func NewSqlStore() Store {
 	sqlStore.status = NewSqlStatusStore(sqlStore)
 	sqlStore.fileInfo = NewSqlFileInfoStore(sqlStore)
 	sqlStore.reaction = NewSqlReactionStore(sqlStore)
+	sqlStore.uploadSession = NewSqlUploadSessionStore(sqlStore)
+	sqlStore.thread = NewSqlThreadStore(sqlStore)
+	sqlStore.scheduledPost = NewSqlScheduledPostStore(sqlStore)
+	sqlStore.outgoingWebhookDelivery = NewSqlOutgoingWebhookDeliveryStore(sqlStore)
+	sqlStore.draft = NewSqlDraftStore(sqlStore)
+	sqlStore.legalHold = NewSqlLegalHoldStore(sqlStore)
+	sqlStore.cluster = NewSqlClusterDiscoveryStore(sqlStore)
+	sqlStore.mfaRecovery = NewSqlMfaRecoveryCodeStore(sqlStore)
+	sqlStore.accessToken = NewSqlPersonalAccessTokenStore(sqlStore)
+	sqlStore.bot = NewSqlBotStore(sqlStore)
+	sqlStore.eventSubscription = NewSqlEventSubscriptionStore(sqlStore)
 
 	err := sqlStore.master.CreateTablesIfNotExists()
 	if err != nil {
@@ -164,6 +199,17 @@ func NewSqlStore() Store {
 	sqlStore.status.(*SqlStatusStore).CreateIndexesIfNotExists()
 	sqlStore.fileInfo.(*SqlFileInfoStore).CreateIndexesIfNotExists()
 	sqlStore.reaction.(*SqlReactionStore).CreateIndexesIfNotExists()
+	sqlStore.uploadSession.(*SqlUploadSessionStore).CreateIndexesIfNotExists()
+	sqlStore.thread.(*SqlThreadStore).CreateIndexesIfNotExists()
+	sqlStore.scheduledPost.(*SqlScheduledPostStore).CreateIndexesIfNotExists()
+	sqlStore.outgoingWebhookDelivery.(*SqlOutgoingWebhookDeliveryStore).CreateIndexesIfNotExists()
+	sqlStore.draft.(*SqlDraftStore).CreateIndexesIfNotExists()
+	sqlStore.legalHold.(*SqlLegalHoldStore).CreateIndexesIfNotExists()
+	sqlStore.cluster.(*SqlClusterDiscoveryStore).CreateIndexesIfNotExists()
+	sqlStore.mfaRecovery.(*SqlMfaRecoveryCodeStore).CreateIndexesIfNotExists()
+	sqlStore.accessToken.(*SqlPersonalAccessTokenStore).CreateIndexesIfNotExists()
+	sqlStore.bot.(*SqlBotStore).CreateIndexesIfNotExists()
+	sqlStore.eventSubscription.(*SqlEventSubscriptionStore).CreateIndexesIfNotExists()
 
 	sqlStore.preference.(*SqlPreferenceStore).DeleteUnusedFeatures()
 
@@ -237,6 +283,16 @@ func (ss *SqlStore) GetCurrentSchemaVersion() string {
 	return version
 }
 
+// SupportsPhraseSearch returns true if the database can match the words of a quoted phrase in order as part of a full
+// text search. Postgres only added the operator for this in 9.6, so older versions need to filter the results instead.
+func (ss *SqlStore) SupportsPhraseSearch() bool {
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+		return ss.postgresVersion >= POSTGRES_PHRASE_SEARCH_VERSION
+	}
+
+	return true
+}
+
 func (ss *SqlStore) MarkSystemRanUnitTests() {
 	if result := <-ss.System().Get(); result.Err == nil {
 		props := result.Data.(model.StringMap)
@@ -382,6 +438,42 @@ func (ss *SqlStore) CreateColumnIfNotExists(tableName string, columnName string,
 	}
 }
 
+// CreateColumnIfNotExistsNoDefault adds a column without a default value for column types such as MySQL's text that
+// aren't allowed to have one.
+func (ss *SqlStore) CreateColumnIfNotExistsNoDefault(tableName string, columnName string, mySqlColType string, postgresColType string) bool {
+
+	if ss.DoesColumnExist(tableName, columnName) {
+		return false
+	}
+
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
+		_, err := ss.GetMaster().Exec("ALTER TABLE " + tableName + " ADD " + columnName + " " + postgresColType)
+		if err != nil {
+			l4g.Critical(utils.T("store.sql.create_column.critical"), err)
+			time.Sleep(time.Second)
+			os.Exit(EXIT_CREATE_COLUMN_POSTGRES)
+		}
+
+		return true
+
+	} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
+		_, err := ss.GetMaster().Exec("ALTER TABLE " + tableName + " ADD " + columnName + " " + mySqlColType)
+		if err != nil {
+			l4g.Critical(utils.T("store.sql.create_column.critical"), err)
+			time.Sleep(time.Second)
+			os.Exit(EXIT_CREATE_COLUMN_MYSQL)
+		}
+
+		return true
+
+	} else {
+		l4g.Critical(utils.T("store.sql.create_column_missing_driver.critical"))
+		time.Sleep(time.Second)
+		os.Exit(EXIT_CREATE_COLUMN_MISSING)
+		return false
+	}
+}
+
 func (ss *SqlStore) RemoveColumnIfExists(tableName string, columnName string) bool {
 
 	if !ss.DoesColumnExist(tableName, columnName) {
@@ -683,6 +775,50 @@ func (ss *SqlStore) Reaction() ReactionStore {
 	return ss.reaction
 }
 
+func (ss *SqlStore) UploadSession() UploadSessionStore {
+	return ss.uploadSession
+}
+
+func (ss *SqlStore) Thread() ThreadStore {
+	return ss.thread
+}
+
+func (ss *SqlStore) ScheduledPost() ScheduledPostStore {
+	return ss.scheduledPost
+}
+
+func (ss *SqlStore) OutgoingWebhookDelivery() OutgoingWebhookDeliveryStore {
+	return ss.outgoingWebhookDelivery
+}
+
+func (ss *SqlStore) Draft() DraftStore {
+	return ss.draft
+}
+
+func (ss *SqlStore) LegalHold() LegalHoldStore {
+	return ss.legalHold
+}
+
+func (ss *SqlStore) ClusterDiscovery() ClusterDiscoveryStore {
+	return ss.cluster
+}
+
+func (ss *SqlStore) MfaRecoveryCode() MfaRecoveryCodeStore {
+	return ss.mfaRecovery
+}
+
+func (ss *SqlStore) PersonalAccessToken() PersonalAccessTokenStore {
+	return ss.accessToken
+}
+
+func (ss *SqlStore) Bot() BotStore {
+	return ss.bot
+}
+
+func (ss *SqlStore) EventSubscription() EventSubscriptionStore {
+	return ss.eventSubscription
+}
+
 func (ss *SqlStore) DropAllTables() {
 	ss.master.TruncateTables()
 }
@@ -700,6 +836,11 @@ func (me mattermConverter) ToDb(val interface{}) (interface{}, error) {
 		return encrypt([]byte(utils.Cfg.SqlSettings.AtRestEncryptKey), model.MapToJson(t))
 	case model.StringInterface:
 		return model.StringInterfaceToJson(t), nil
+	case *model.AutocompleteData:
+		if t == nil {
+			return "", nil
+		}
+		return t.ToJson(), nil
 	}
 
 	return val, nil
@@ -753,6 +894,22 @@ func (me mattermConverter) FromDb(target interface{}) (gorp.CustomScanner, bool)
 			return json.Unmarshal(b, target)
 		}
 		return gorp.CustomScanner{new(string), target, binder}, true
+	case **model.AutocompleteData:
+		binder := func(holder, target interface{}) error {
+			s, ok := holder.(*string)
+			if !ok {
+				return errors.New(utils.T("store.sql.convert_autocomplete_data"))
+			}
+
+			// Commands without autocomplete data are stored as an empty string
+			if len(*s) == 0 {
+				return nil
+			}
+
+			b := []byte(*s)
+			return json.Unmarshal(b, target)
+		}
+		return gorp.CustomScanner{Holder: new(string), Target: target, Binder: binder}, true
 	}
 
 	return gorp.CustomScanner{}, false
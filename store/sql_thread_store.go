@@ -0,0 +1,487 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+	"github.com/mattermost/platform/model"
+	"github.com/mattermost/platform/utils"
+)
+
+type SqlThreadStore struct {
+	*SqlStore
+}
+
+func NewSqlThreadStore(sqlStore *SqlStore) ThreadStore {
+	s := &SqlThreadStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Thread{}, "Threads").SetKeys(false, "PostId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("Participants").SetMaxSize(2000)
+
+		tableMembers := db.AddTableWithName(model.ThreadMembership{}, "ThreadMemberships").SetKeys(false, "PostId", "UserId")
+		tableMembers.ColMap("PostId").SetMaxSize(26)
+		tableMembers.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlThreadStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_threads_channel_id", "Threads", "ChannelId")
+	s.CreateIndexIfNotExists("idx_threads_last_reply_at", "Threads", "LastReplyAt")
+	s.CreateIndexIfNotExists("idx_thread_memberships_user_id", "ThreadMemberships", "UserId")
+}
+
+// threadsForUserQuery selects the threads in the channels on a team that a user belongs to along with their read state
+// for each one. FILTER is replaced with any extra conditions.
+const threadsForUserQuery = `
+	SELECT
+		Threads.*,
+		COALESCE(ThreadMemberships.Following, FALSE) AS Following,
+		COALESCE(ThreadMemberships.LastViewed, 0) AS LastViewedAt,
+		(SELECT
+			COUNT(0)
+		FROM
+			Posts
+		WHERE
+			Posts.RootId = Threads.PostId
+			AND Posts.CreateAt > COALESCE(ThreadMemberships.LastViewed, 0)
+			AND Posts.UserId != :UserId
+			AND Posts.DeleteAt = 0) AS UnreadReplies
+	FROM
+		Threads
+		LEFT JOIN ThreadMemberships ON ThreadMemberships.PostId = Threads.PostId AND ThreadMemberships.UserId = :UserId
+	WHERE
+		Threads.ChannelId IN (
+			SELECT
+				Id
+			FROM
+				Channels,
+				ChannelMembers
+			WHERE
+				Id = ChannelId
+				AND (TeamId = :TeamId OR TeamId = '')
+				AND ChannelMembers.UserId = :UserId
+				AND DeleteAt = 0)
+		FILTER`
+
+// UpdateFromReplies counts the replies made to a root post and saves the result as the post's thread, creating the
+// thread if this is the first reply.
+func (s SqlThreadStore) UpdateFromReplies(postId string, channelId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		params := map[string]interface{}{"PostId": postId}
+
+		thread := &model.Thread{
+			PostId:       postId,
+			ChannelId:    channelId,
+			Participants: model.StringArray{},
+		}
+
+		if err := s.GetMaster().SelectOne(thread,
+			`SELECT
+				COUNT(0) AS ReplyCount,
+				COALESCE(MAX(CreateAt), 0) AS LastReplyAt
+			FROM
+				Posts
+			WHERE
+				RootId = :PostId
+				AND DeleteAt = 0`, params); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.UpdateFromReplies", "store.sql_thread.update_from_replies.app_error", nil, "post_id="+postId+", "+err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		var participants []string
+		if _, err := s.GetMaster().Select(&participants,
+			`SELECT
+				UserId
+			FROM
+				Posts
+			WHERE
+				RootId = :PostId
+				AND DeleteAt = 0
+			GROUP BY
+				UserId
+			ORDER BY
+				MIN(CreateAt)
+			LIMIT :Limit`, map[string]interface{}{"PostId": postId, "Limit": model.THREAD_PARTICIPANTS_MAX}); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.UpdateFromReplies", "store.sql_thread.update_from_replies.app_error", nil, "post_id="+postId+", "+err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+		thread.Participants = participants
+
+		if result.Err = thread.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if transaction, err := s.GetMaster().Begin(); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.UpdateFromReplies", "store.sql_thread.update_from_replies.app_error", nil, err.Error())
+		} else if err := s.saveThread(transaction, thread); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewLocAppError("SqlThreadStore.UpdateFromReplies", "store.sql_thread.update_from_replies.app_error", nil, "post_id="+postId+", "+err.Error())
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.UpdateFromReplies", "store.sql_thread.update_from_replies.app_error", nil, err.Error())
+		} else {
+			result.Data = thread
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlThreadStore) saveThread(transaction *gorp.Transaction, thread *model.Thread) error {
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
+		_, err := transaction.Exec(
+			`INSERT INTO
+				Threads
+				(PostId, ChannelId, ReplyCount, LastReplyAt, Participants)
+			VALUES
+				(:PostId, :ChannelId, :ReplyCount, :LastReplyAt, :Participants)
+			ON DUPLICATE KEY UPDATE
+				ReplyCount = :ReplyCount,
+				LastReplyAt = :LastReplyAt,
+				Participants = :Participants`,
+			map[string]interface{}{
+				"PostId":       thread.PostId,
+				"ChannelId":    thread.ChannelId,
+				"ReplyCount":   thread.ReplyCount,
+				"LastReplyAt":  thread.LastReplyAt,
+				"Participants": model.ArrayToJson(thread.Participants),
+			})
+		return err
+	}
+
+	// postgres has no way to upsert values until version 9.5 and trying inserting and then updating causes transactions to abort
+	count, err := transaction.SelectInt("SELECT COUNT(0) FROM Threads WHERE PostId = :PostId", map[string]interface{}{"PostId": thread.PostId})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return transaction.Insert(thread)
+	}
+
+	_, err = transaction.Update(thread)
+	return err
+}
+
+func (s SqlThreadStore) Get(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var thread model.Thread
+		if err := s.GetReplica().SelectOne(&thread, "SELECT * FROM Threads WHERE PostId = :PostId", map[string]interface{}{"PostId": postId}); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.Get", "store.sql_thread.get.app_error", nil, "post_id="+postId+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = &thread
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Delete removes a thread along with every user's membership of it.
+func (s SqlThreadStore) Delete(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		params := map[string]interface{}{"PostId": postId}
+
+		if _, err := s.GetMaster().Exec("DELETE FROM ThreadMemberships WHERE PostId = :PostId", params); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.Delete", "store.sql_thread.delete.app_error", nil, "post_id="+postId+", "+err.Error())
+		} else if _, err := s.GetMaster().Exec("DELETE FROM Threads WHERE PostId = :PostId", params); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.Delete", "store.sql_thread.delete.app_error", nil, "post_id="+postId+", "+err.Error())
+		} else {
+			result.Data = postId
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlThreadStore) SaveMembership(membership *model.ThreadMembership) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		membership.PreSave()
+		if result.Err = membership.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if transaction, err := s.GetMaster().Begin(); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.SaveMembership", "store.sql_thread.save_membership.app_error", nil, err.Error())
+		} else if err := s.saveMembership(transaction, membership); err != nil {
+			transaction.Rollback()
+			result.Err = model.NewLocAppError("SqlThreadStore.SaveMembership", "store.sql_thread.save_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error())
+		} else if err := transaction.Commit(); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.SaveMembership", "store.sql_thread.save_membership.app_error", nil, err.Error())
+		} else {
+			result.Data = membership
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlThreadStore) saveMembership(transaction *gorp.Transaction, membership *model.ThreadMembership) error {
+	if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_MYSQL {
+		_, err := transaction.Exec(
+			`INSERT INTO
+				ThreadMemberships
+				(PostId, UserId, Following, LastViewed, LastUpdated)
+			VALUES
+				(:PostId, :UserId, :Following, :LastViewed, :LastUpdated)
+			ON DUPLICATE KEY UPDATE
+				Following = :Following,
+				LastViewed = :LastViewed,
+				LastUpdated = :LastUpdated`,
+			map[string]interface{}{
+				"PostId":      membership.PostId,
+				"UserId":      membership.UserId,
+				"Following":   membership.Following,
+				"LastViewed":  membership.LastViewed,
+				"LastUpdated": membership.LastUpdated,
+			})
+		return err
+	}
+
+	// postgres has no way to upsert values until version 9.5 and trying inserting and then updating causes transactions to abort
+	count, err := transaction.SelectInt("SELECT COUNT(0) FROM ThreadMemberships WHERE PostId = :PostId AND UserId = :UserId",
+		map[string]interface{}{"PostId": membership.PostId, "UserId": membership.UserId})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return transaction.Insert(membership)
+	}
+
+	_, err = transaction.Update(membership)
+	return err
+}
+
+func (s SqlThreadStore) GetMembership(postId string, userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var membership model.ThreadMembership
+		if err := s.GetMaster().SelectOne(&membership, "SELECT * FROM ThreadMemberships WHERE PostId = :PostId AND UserId = :UserId",
+			map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.GetMembership", "store.sql_thread.get_membership.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = &membership
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetFollowers returns the ids of the users following a thread.
+func (s SqlThreadStore) GetFollowers(postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var userIds []string
+		if _, err := s.GetReplica().Select(&userIds, "SELECT UserId FROM ThreadMemberships WHERE PostId = :PostId AND Following = :Following",
+			map[string]interface{}{"PostId": postId, "Following": true}); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.GetFollowers", "store.sql_thread.get_followers.app_error", nil, "post_id="+postId+", "+err.Error())
+		} else {
+			result.Data = userIds
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetThreadForUser returns a thread along with the given user's read state for it. The user must belong to the
+// thread's channel.
+func (s SqlThreadStore) GetThreadForUser(teamId string, userId string, postId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var thread model.ThreadResponse
+		if err := s.GetReplica().SelectOne(&thread, threadsForUserQueryWithFilter("AND Threads.PostId = :PostId"),
+			map[string]interface{}{"TeamId": teamId, "UserId": userId, "PostId": postId}); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.GetThreadForUser", "store.sql_thread.get_thread_for_user.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = &thread
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// GetThreadsForUser returns a page of the threads on a team that the user is following ordered by the time of their
+// last reply along with the total number of followed threads and how many of them have unread replies.
+func (s SqlThreadStore) GetThreadsForUser(teamId string, userId string, offset int, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		params := map[string]interface{}{
+			"TeamId":    teamId,
+			"UserId":    userId,
+			"Following": true,
+			"Offset":    offset,
+			"Limit":     limit,
+		}
+
+		list := &model.ThreadList{Threads: []*model.ThreadResponse{}}
+
+		var threads []*model.ThreadResponse
+		if _, err := s.GetReplica().Select(&threads,
+			threadsForUserQueryWithFilter("AND ThreadMemberships.Following = :Following")+`
+			ORDER BY
+				Threads.LastReplyAt DESC
+			LIMIT :Limit
+			OFFSET :Offset`, params); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.GetThreadsForUser", "store.sql_thread.get_threads_for_user.app_error", nil, "user_id="+userId+", "+err.Error())
+			storeChannel <- result
+			close(storeChannel)
+			return
+		} else if threads != nil {
+			list.Threads = threads
+		}
+
+		var counts struct {
+			Total              int64
+			TotalUnreadThreads int64
+		}
+		if err := s.GetReplica().SelectOne(&counts,
+			`SELECT
+				COUNT(0) AS Total,
+				COALESCE(SUM(CASE WHEN UnreadReplies > 0 THEN 1 ELSE 0 END), 0) AS TotalUnreadThreads
+			FROM
+				(`+threadsForUserQueryWithFilter("AND ThreadMemberships.Following = :Following")+`) AS FollowedThreads`, params); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.GetThreadsForUser", "store.sql_thread.get_threads_for_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			list.Total = counts.Total
+			list.TotalUnreadThreads = counts.TotalUnreadThreads
+			result.Data = list
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// MarkAllAsRead marks every thread on a team that the user has a membership for as read up to the given time.
+func (s SqlThreadStore) MarkAllAsRead(teamId string, userId string, timestamp int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec(
+			`UPDATE
+				ThreadMemberships
+			SET
+				LastViewed = :LastViewed,
+				LastUpdated = :LastUpdated
+			WHERE
+				UserId = :UserId
+				AND PostId IN (
+					SELECT
+						PostId
+					FROM
+						Threads,
+						Channels
+					WHERE
+						Threads.ChannelId = Channels.Id
+						AND (Channels.TeamId = :TeamId OR Channels.TeamId = ''))`,
+			map[string]interface{}{"TeamId": teamId, "UserId": userId, "LastViewed": timestamp, "LastUpdated": model.GetMillis()}); err != nil {
+			result.Err = model.NewLocAppError("SqlThreadStore.MarkAllAsRead", "store.sql_thread.mark_all_as_read.app_error", nil, "user_id="+userId+", "+err.Error())
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func threadsForUserQueryWithFilter(filter string) string {
+	return strings.Replace(threadsForUserQuery, "FILTER", filter, 1)
+}
+
+// PermanentDeleteByPostIds removes the threads started by any of the given posts along with their memberships.
+func (s SqlThreadStore) PermanentDeleteByPostIds(postIds []string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if len(postIds) > 0 {
+			props := make(map[string]interface{})
+			idQuery := buildIdListQuery("PostId", postIds, props)
+
+			if _, err := s.GetMaster().Exec("DELETE FROM ThreadMemberships WHERE PostId IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlThreadStore.PermanentDeleteByPostIds", "store.sql_thread.permanent_delete_by_post_ids.app_error", nil, err.Error())
+			} else if _, err := s.GetMaster().Exec("DELETE FROM Threads WHERE PostId IN ("+idQuery+")", props); err != nil {
+				result.Err = model.NewLocAppError("SqlThreadStore.PermanentDeleteByPostIds", "store.sql_thread.permanent_delete_by_post_ids.app_error", nil, err.Error())
+			}
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
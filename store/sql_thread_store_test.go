@@ -0,0 +1,147 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestThreadStoreUpdateFromReplies(t *testing.T) {
+	Setup()
+
+	channelId := model.NewId()
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	root := Must(store.Post().Save(&model.Post{ChannelId: channelId, UserId: userId1, Message: "root"})).(*model.Post)
+	reply1 := Must(store.Post().Save(&model.Post{ChannelId: channelId, UserId: userId2, RootId: root.Id, ParentId: root.Id, Message: "reply1"})).(*model.Post)
+	reply2 := Must(store.Post().Save(&model.Post{ChannelId: channelId, UserId: userId1, RootId: root.Id, ParentId: root.Id, Message: "reply2", CreateAt: reply1.CreateAt + 1})).(*model.Post)
+
+	thread := Must(store.Thread().UpdateFromReplies(root.Id, channelId)).(*model.Thread)
+	if thread.ReplyCount != 2 {
+		t.Fatal("should have counted both replies")
+	} else if thread.LastReplyAt != reply2.CreateAt {
+		t.Fatal("should have used the time of the latest reply")
+	} else if len(thread.Participants) != 2 || thread.Participants[0] != userId2 || thread.Participants[1] != userId1 {
+		t.Fatal("should have listed the participants in the order that they replied")
+	}
+
+	if saved := Must(store.Thread().Get(root.Id)).(*model.Thread); saved.ReplyCount != 2 {
+		t.Fatal("should have saved the thread")
+	}
+
+	Must(store.Post().Delete(reply2.Id, model.GetMillis()))
+
+	Must(store.Thread().UpdateFromReplies(root.Id, channelId))
+	if saved := Must(store.Thread().Get(root.Id)).(*model.Thread); saved.ReplyCount != 1 || saved.LastReplyAt != reply1.CreateAt {
+		t.Fatal("should have updated the existing thread")
+	} else if len(saved.Participants) != 1 {
+		t.Fatal("should no longer include the deleted reply's author")
+	}
+
+	Must(store.Thread().Delete(root.Id))
+	if result := <-store.Thread().Get(root.Id); result.Err == nil {
+		t.Fatal("should have deleted the thread")
+	}
+}
+
+func TestThreadStoreMembership(t *testing.T) {
+	Setup()
+
+	postId := model.NewId()
+	userId := model.NewId()
+
+	if result := <-store.Thread().GetMembership(postId, userId); result.Err == nil {
+		t.Fatal("shouldn't have a membership yet")
+	}
+
+	Must(store.Thread().SaveMembership(&model.ThreadMembership{PostId: postId, UserId: userId, Following: true, LastViewed: 1000}))
+
+	if membership := Must(store.Thread().GetMembership(postId, userId)).(*model.ThreadMembership); !membership.Following || membership.LastViewed != 1000 {
+		t.Fatal("saved the wrong membership")
+	}
+
+	if followers := Must(store.Thread().GetFollowers(postId)).([]string); len(followers) != 1 || followers[0] != userId {
+		t.Fatal("should've returned the follower")
+	}
+
+	Must(store.Thread().SaveMembership(&model.ThreadMembership{PostId: postId, UserId: userId, Following: false, LastViewed: 2000}))
+
+	if membership := Must(store.Thread().GetMembership(postId, userId)).(*model.ThreadMembership); membership.Following || membership.LastViewed != 2000 {
+		t.Fatal("should've updated the existing membership")
+	}
+
+	if followers := Must(store.Thread().GetFollowers(postId)).([]string); len(followers) != 0 {
+		t.Fatal("should no longer be following the thread")
+	}
+
+	if result := <-store.Thread().SaveMembership(&model.ThreadMembership{PostId: postId, UserId: "junk"}); result.Err == nil {
+		t.Fatal("shouldn't save an invalid membership")
+	}
+}
+
+func TestThreadStoreGetThreadsForUser(t *testing.T) {
+	Setup()
+
+	teamId := model.NewId()
+	userId1 := model.NewId()
+	userId2 := model.NewId()
+
+	channel := Must(store.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Name",
+		Name:        "a" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	})).(*model.Channel)
+
+	Must(store.Channel().SaveMember(&model.ChannelMember{ChannelId: channel.Id, UserId: userId1, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+
+	root1 := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId1, Message: "root1"})).(*model.Post)
+	root2 := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId1, Message: "root2"})).(*model.Post)
+
+	reply1 := Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId2, RootId: root1.Id, ParentId: root1.Id, Message: "reply1"})).(*model.Post)
+	Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId2, RootId: root2.Id, ParentId: root2.Id, Message: "reply2", CreateAt: reply1.CreateAt + 1}))
+	Must(store.Post().Save(&model.Post{ChannelId: channel.Id, UserId: userId1, RootId: root2.Id, ParentId: root2.Id, Message: "reply3", CreateAt: reply1.CreateAt + 2}))
+
+	Must(store.Thread().UpdateFromReplies(root1.Id, channel.Id))
+	Must(store.Thread().UpdateFromReplies(root2.Id, channel.Id))
+
+	Must(store.Thread().SaveMembership(&model.ThreadMembership{PostId: root1.Id, UserId: userId1, Following: true, LastViewed: root1.CreateAt}))
+	Must(store.Thread().SaveMembership(&model.ThreadMembership{PostId: root2.Id, UserId: userId1, Following: true, LastViewed: reply1.CreateAt + 2}))
+
+	list := Must(store.Thread().GetThreadsForUser(teamId, userId1, 0, 10)).(*model.ThreadList)
+	if list.Total != 2 || len(list.Threads) != 2 {
+		t.Fatal("should've returned both followed threads")
+	} else if list.TotalUnreadThreads != 1 {
+		t.Fatal("only the first thread has unread replies")
+	} else if list.Threads[0].PostId != root2.Id || list.Threads[1].PostId != root1.Id {
+		t.Fatal("should've ordered the threads by their most recent reply")
+	} else if list.Threads[0].UnreadReplies != 0 || list.Threads[1].UnreadReplies != 1 {
+		t.Fatal("returned the wrong unread counts")
+	}
+
+	if list := Must(store.Thread().GetThreadsForUser(teamId, userId1, 1, 10)).(*model.ThreadList); len(list.Threads) != 1 || list.Total != 2 {
+		t.Fatal("should've returned the second page")
+	}
+
+	if list := Must(store.Thread().GetThreadsForUser(teamId, userId2, 0, 10)).(*model.ThreadList); list.Total != 0 || len(list.Threads) != 0 {
+		t.Fatal("shouldn't return threads from channels that the user doesn't belong to")
+	}
+
+	if thread := Must(store.Thread().GetThreadForUser(teamId, userId1, root1.Id)).(*model.ThreadResponse); !thread.Following || thread.UnreadReplies != 1 {
+		t.Fatal("returned the wrong thread")
+	}
+
+	if result := <-store.Thread().GetThreadForUser(teamId, userId2, root1.Id); result.Err == nil {
+		t.Fatal("shouldn't return a thread from a channel that the user doesn't belong to")
+	}
+
+	Must(store.Thread().MarkAllAsRead(teamId, userId1, model.GetMillis()+1000))
+
+	if list := Must(store.Thread().GetThreadsForUser(teamId, userId1, 0, 10)).(*model.ThreadList); list.TotalUnreadThreads != 0 {
+		t.Fatal("should've marked every thread as read")
+	}
+}
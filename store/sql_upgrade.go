@@ -237,5 +237,42 @@ func UpgradeDatabaseToVersion37(sqlStore *SqlStore) {
 	// if shouldPerformUpgrade(sqlStore, VERSION_3_6_0, VERSION_3_7_0) {
 	// Add EditAt column to Posts
 	sqlStore.CreateColumnIfNotExists("Posts", "EditAt", " bigint", " bigint", "0")
+
+	// Add Hash column to FileInfo so that streamed uploads can be verified
+	sqlStore.CreateColumnIfNotExists("FileInfo", "Hash", "varchar(64)", "varchar(64)", "")
+
+	// Add Content column to FileInfo so that the text of documents can be searched. MySQL doesn't allow a default value
+	// for text columns, but existing rows are still given an empty string since the column can't be null.
+	sqlStore.CreateColumnIfNotExistsNoDefault("FileInfo", "Content", "text NOT NULL", "varchar(16000) NOT NULL DEFAULT ''")
+
+	// Add IsPinned column to Posts
+	sqlStore.CreateColumnIfNotExists("Posts", "IsPinned", "tinyint", "boolean", "0")
+
+	// Add IsBot column to Users so that bot accounts can be told apart from people
+	sqlStore.CreateColumnIfNotExists("Users", "IsBot", "tinyint(1)", "boolean", "0")
+
+	// Add MfaLastTimeStep column to Users so that MFA tokens can't be used more than once
+	sqlStore.CreateColumnIfNotExists("Users", "MfaLastTimeStep", "bigint", "bigint", "0")
+
+	// Add autocomplete columns to Commands so that they can suggest their subcommands and arguments
+	sqlStore.CreateColumnIfNotExistsNoDefault("Commands", "AutocompleteData", "text NOT NULL", "varchar(16000) NOT NULL DEFAULT ''")
+	sqlStore.CreateColumnIfNotExists("Commands", "AutoCompleteURL", "varchar(1024)", "varchar(1024)", "")
+
+	// Add Event column to OutgoingWebhookDeliveries so that events for event subscriptions can be delivered with retries
+	sqlStore.CreateColumnIfNotExists("OutgoingWebhookDeliveries", "Event", "varchar(64)", "varchar(64)", "")
+
+	// Add ClaimedAt column to UploadSessions so that only one request can finish an upload
+	sqlStore.CreateColumnIfNotExists("UploadSessions", "ClaimedAt", "bigint", "bigint", "0")
+
+	// Make room in session props for the refresh tokens issued by OpenID Connect providers
+	if sqlStore.GetMaxLengthOfColumnIfExists("Sessions", "Props") == "1000" {
+		sqlStore.AlterColumnTypeIfExists("Sessions", "Props", "text", "varchar(4000)")
+	}
 	// }
+
+	// Development builds briefly marked the database as being on 3.7.0 before it was released, so put those databases
+	// back on the current version to let them start and be upgraded again when 3.7.0 is released
+	if sqlStore.SchemaVersion == VERIONS_3_7_0 && model.CurrentVersion == VERSION_3_6_0 {
+		saveSchemaVersion(sqlStore, VERSION_3_6_0)
+	}
 }
@@ -0,0 +1,263 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/platform/model"
+)
+
+type SqlUploadSessionStore struct {
+	*SqlStore
+}
+
+func NewSqlUploadSessionStore(sqlStore *SqlStore) UploadSessionStore {
+	s := &SqlUploadSessionStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.UploadSession{}, "UploadSessions").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("TeamId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("Filename").SetMaxSize(256)
+		table.ColMap("Path").SetMaxSize(512)
+	}
+
+	return s
+}
+
+func (s SqlUploadSessionStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_uploadsessions_user_id", "UploadSessions", "UserId")
+	s.CreateIndexIfNotExists("idx_uploadsessions_update_at", "UploadSessions", "UpdateAt")
+}
+
+func (s SqlUploadSessionStore) Save(session *model.UploadSession) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		session.PreSave()
+		if result.Err = session.IsValid(); result.Err != nil {
+			storeChannel <- result
+			close(storeChannel)
+			return
+		}
+
+		if err := s.GetMaster().Insert(session); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.Save", "store.sql_upload_session.save.app_error", nil, "id="+session.Id+", "+err.Error())
+		} else {
+			result.Data = session
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlUploadSessionStore) Get(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var session *model.UploadSession
+
+		// Read from the master since the offset is updated by every chunk that's uploaded
+		if err := s.GetMaster().SelectOne(&session,
+			`SELECT
+				*
+			FROM
+				UploadSessions
+			WHERE
+				Id = :Id`, map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.Get", "store.sql_upload_session.get.app_error", nil, "id="+id+", "+err.Error())
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = session
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlUploadSessionStore) GetForUser(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var sessions []*model.UploadSession
+
+		if _, err := s.GetReplica().Select(&sessions,
+			`SELECT
+				*
+			FROM
+				UploadSessions
+			WHERE
+				UserId = :UserId
+			ORDER BY
+				CreateAt`, map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.GetForUser", "store.sql_upload_session.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = sessions
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// UpdateFileOffset moves the offset of an upload forward after a chunk has been stored. It fails if the offset has
+// changed since the chunk was started so that two requests can't both append to the same upload.
+func (s SqlUploadSessionStore) UpdateFileOffset(id string, oldOffset int64, newOffset int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`UPDATE
+				UploadSessions
+			SET
+				FileOffset = :NewOffset,
+				UpdateAt = :UpdateAt
+			WHERE
+				Id = :Id
+				AND FileOffset = :OldOffset`, map[string]interface{}{"Id": id, "OldOffset": oldOffset, "NewOffset": newOffset, "UpdateAt": model.GetMillis()}); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.UpdateFileOffset", "store.sql_upload_session.update_file_offset.app_error", nil, "id="+id+", "+err.Error())
+		} else if rows, _ := sqlResult.RowsAffected(); rows != 1 {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.UpdateFileOffset", "store.sql_upload_session.update_file_offset.conflict.app_error", nil, "id="+id+", offset="+strconv.FormatInt(oldOffset, 10))
+			result.Err.StatusCode = http.StatusConflict
+		} else {
+			result.Data = newOffset
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Claim marks an upload session as being finished so that only one request puts its chunks back together. The result's
+// data is true if this call claimed the session.
+func (s SqlUploadSessionStore) Claim(id string, time int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec(
+			`UPDATE
+				UploadSessions
+			SET
+				ClaimedAt = :ClaimedAt,
+				UpdateAt = :ClaimedAt
+			WHERE
+				Id = :Id
+				AND ClaimedAt = 0`, map[string]interface{}{"Id": id, "ClaimedAt": time}); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.Claim", "store.sql_upload_session.claim.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			rows, _ := sqlResult.RowsAffected()
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// ReleaseClaim lets an upload session be finished again after the request that claimed it at claimedAt failed.
+func (s SqlUploadSessionStore) ReleaseClaim(id string, claimedAt int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := s.GetMaster().Exec(
+			`UPDATE
+				UploadSessions
+			SET
+				ClaimedAt = 0
+			WHERE
+				Id = :Id
+				AND ClaimedAt = :ClaimedAt`, map[string]interface{}{"Id": id, "ClaimedAt": claimedAt}); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.ReleaseClaim", "store.sql_upload_session.release_claim.app_error", nil, "id="+id+", "+err.Error())
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+// Delete removes an upload session. It fails if the session has already been removed so that only one request can finish
+// or cancel an upload.
+func (s SqlUploadSessionStore) Delete(id string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := s.GetMaster().Exec("DELETE FROM UploadSessions WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.Delete", "store.sql_upload_session.delete.app_error", nil, "id="+id+", "+err.Error())
+		} else if rows, _ := sqlResult.RowsAffected(); rows != 1 {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.Delete", "store.sql_upload_session.delete.missing.app_error", nil, "id="+id)
+			result.Err.StatusCode = http.StatusNotFound
+		} else {
+			result.Data = id
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
+func (s SqlUploadSessionStore) GetStale(updatedBefore int64, limit int) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		var sessions []*model.UploadSession
+
+		if _, err := s.GetReplica().Select(&sessions,
+			`SELECT
+				*
+			FROM
+				UploadSessions
+			WHERE
+				UpdateAt < :UpdatedBefore
+			ORDER BY
+				UpdateAt
+			LIMIT :Limit`, map[string]interface{}{"UpdatedBefore": updatedBefore, "Limit": limit}); err != nil {
+			result.Err = model.NewLocAppError("SqlUploadSessionStore.GetStale", "store.sql_upload_session.get_stale.app_error", nil, err.Error())
+		} else {
+			result.Data = sessions
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestUploadSessionSaveGet(t *testing.T) {
+	Setup()
+
+	session := &model.UploadSession{
+		UserId:    model.NewId(),
+		TeamId:    model.NewId(),
+		ChannelId: model.NewId(),
+		Filename:  "file.txt",
+		FileSize:  1000,
+	}
+
+	if result := <-store.UploadSession().Save(session); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if returned := result.Data.(*model.UploadSession); len(returned.Id) != 26 {
+		t.Fatal("should've assigned an id to the upload session")
+	}
+
+	if result := <-store.UploadSession().Get(session.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if returned := result.Data.(*model.UploadSession); returned.Id != session.Id {
+		t.Fatal("should've returned correct upload session")
+	} else if returned.Path != session.Path {
+		t.Fatal("should've stored the path")
+	}
+
+	if result := <-store.UploadSession().Get(model.NewId()); result.Err == nil {
+		t.Fatal("shouldn't have returned a missing upload session")
+	}
+
+	if result := <-store.UploadSession().GetForUser(session.UserId); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if sessions := result.Data.([]*model.UploadSession); len(sessions) != 1 || sessions[0].Id != session.Id {
+		t.Fatal("should've returned the user's upload session")
+	}
+
+	if result := <-store.UploadSession().Delete(session.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.UploadSession().Get(session.Id); result.Err == nil {
+		t.Fatal("should've deleted the upload session")
+	}
+
+	if result := <-store.UploadSession().Delete(session.Id); result.Err == nil {
+		t.Fatal("shouldn't be able to delete the upload session twice")
+	}
+}
+
+func TestUploadSessionUpdateFileOffset(t *testing.T) {
+	Setup()
+
+	session := &model.UploadSession{
+		UserId:    model.NewId(),
+		TeamId:    model.NewId(),
+		ChannelId: model.NewId(),
+		Filename:  "file.txt",
+		FileSize:  1000,
+	}
+	Must(store.UploadSession().Save(session))
+	defer store.UploadSession().Delete(session.Id)
+
+	if result := <-store.UploadSession().UpdateFileOffset(session.Id, 0, 500); result.Err != nil {
+		t.Fatal(result.Err)
+	}
+
+	if result := <-store.UploadSession().UpdateFileOffset(session.Id, 0, 600); result.Err == nil {
+		t.Fatal("shouldn't be able to update from a stale offset")
+	}
+
+	if result := <-store.UploadSession().Get(session.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if returned := result.Data.(*model.UploadSession); returned.FileOffset != 500 {
+		t.Fatal("should've updated the offset")
+	}
+}
+
+func TestUploadSessionClaim(t *testing.T) {
+	Setup()
+
+	session := &model.UploadSession{
+		UserId:    model.NewId(),
+		TeamId:    model.NewId(),
+		ChannelId: model.NewId(),
+		Filename:  "file.txt",
+		FileSize:  1000,
+	}
+	Must(store.UploadSession().Save(session))
+	defer store.UploadSession().Delete(session.Id)
+
+	claimedAt := model.GetMillis()
+
+	if result := <-store.UploadSession().Claim(session.Id, claimedAt); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if !result.Data.(bool) {
+		t.Fatal("should've claimed the upload session")
+	}
+
+	if result := <-store.UploadSession().Claim(session.Id, claimedAt+1); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(bool) {
+		t.Fatal("shouldn't be able to claim an upload session twice")
+	}
+
+	if result := <-store.UploadSession().Get(session.Id); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if returned := result.Data.(*model.UploadSession); returned.ClaimedAt != claimedAt {
+		t.Fatal("should've stored the claim time")
+	}
+
+	Must(store.UploadSession().ReleaseClaim(session.Id, claimedAt+1))
+
+	if result := <-store.UploadSession().Claim(session.Id, claimedAt+2); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if result.Data.(bool) {
+		t.Fatal("shouldn't have released a claim made at a different time")
+	}
+
+	Must(store.UploadSession().ReleaseClaim(session.Id, claimedAt))
+
+	if result := <-store.UploadSession().Claim(session.Id, claimedAt+2); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if !result.Data.(bool) {
+		t.Fatal("should be able to claim the upload session again once released")
+	}
+}
+
+func TestUploadSessionGetStale(t *testing.T) {
+	Setup()
+
+	session := &model.UploadSession{
+		UserId:    model.NewId(),
+		TeamId:    model.NewId(),
+		ChannelId: model.NewId(),
+		Filename:  "file.txt",
+		FileSize:  1000,
+	}
+	Must(store.UploadSession().Save(session))
+	defer store.UploadSession().Delete(session.Id)
+
+	if result := <-store.UploadSession().GetStale(session.UpdateAt+1, 1000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if !containsUploadSession(result.Data.([]*model.UploadSession), session.Id) {
+		t.Fatal("should've returned the stale session")
+	}
+
+	if result := <-store.UploadSession().GetStale(session.UpdateAt, 1000); result.Err != nil {
+		t.Fatal(result.Err)
+	} else if containsUploadSession(result.Data.([]*model.UploadSession), session.Id) {
+		t.Fatal("shouldn't have returned a session that was updated after the cutoff")
+	}
+}
+
+func containsUploadSession(sessions []*model.UploadSession, id string) bool {
+	for _, session := range sessions {
+		if session.Id == id {
+			return true
+		}
+	}
+
+	return false
+}
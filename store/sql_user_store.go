@@ -26,6 +26,7 @@ const (
 	USER_SEARCH_OPTION_NAMES_ONLY_NO_FULL_NAME = "names_only_no_full_name"
 	USER_SEARCH_OPTION_ALL_NO_FULL_NAME        = "all_no_full_name"
 	USER_SEARCH_OPTION_ALLOW_INACTIVE          = "allow_inactive"
+	USER_SEARCH_OPTION_EXCLUDE_BOTS            = "exclude_bots"
 	USER_SEARCH_TYPE_NAMES_NO_FULL_NAME        = "Username, Nickname"
 	USER_SEARCH_TYPE_NAMES                     = "Username, FirstName, LastName, Nickname"
 	USER_SEARCH_TYPE_ALL_NO_FULL_NAME          = "Username, Nickname, Email"
@@ -156,6 +157,8 @@ func (us SqlUserStore) Update(user *model.User, trustedUpdateData bool) StoreCha
 			user.FailedAttempts = oldUser.FailedAttempts
 			user.MfaSecret = oldUser.MfaSecret
 			user.MfaActive = oldUser.MfaActive
+			user.MfaLastTimeStep = oldUser.MfaLastTimeStep
+			user.IsBot = oldUser.IsBot
 
 			if !trustedUpdateData {
 				user.Roles = oldUser.Roles
@@ -285,6 +288,27 @@ func (us SqlUserStore) UpdateFailedPasswordAttempts(userId string, attempts int)
 	return storeChannel
 }
 
+// IncrementFailedPasswordAttempts adds one to the number of times in a row that someone has failed to sign in as the
+// user. The count is incremented by the database so that attempts made at the same time are all counted.
+func (us SqlUserStore) IncrementFailedPasswordAttempts(userId string) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if _, err := us.GetMaster().Exec("UPDATE Users SET FailedAttempts = FailedAttempts + 1 WHERE Id = :UserId", map[string]interface{}{"UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.IncrementFailedPasswordAttempts", "store.sql_user.update_failed_pwd_attempts.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = userId
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (us SqlUserStore) UpdateAuthData(userId string, service string, authData *string, email string, resetMfa bool) StoreChannel {
 
 	storeChannel := make(StoreChannel, 1)
@@ -378,6 +402,29 @@ func (us SqlUserStore) UpdateMfaActive(userId string, active bool) StoreChannel
 	return storeChannel
 }
 
+// UseMfaTimeStep records that the user has signed in with an MFA token from the given time step. The result's data is
+// false if a token from that time step or a later one has already been used, in which case the token must be rejected.
+func (us SqlUserStore) UseMfaTimeStep(userId string, timeStep int64) StoreChannel {
+	storeChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := StoreResult{}
+
+		if sqlResult, err := us.GetMaster().Exec("UPDATE Users SET MfaLastTimeStep = :TimeStep WHERE Id = :UserId AND MfaLastTimeStep < :TimeStep", map[string]interface{}{"TimeStep": timeStep, "UserId": userId}); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.UseMfaTimeStep", "store.sql_user.use_mfa_time_step.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else if rows, err := sqlResult.RowsAffected(); err != nil {
+			result.Err = model.NewLocAppError("SqlUserStore.UseMfaTimeStep", "store.sql_user.use_mfa_time_step.app_error", nil, "user_id="+userId+", "+err.Error())
+		} else {
+			result.Data = rows == 1
+		}
+
+		storeChannel <- result
+		close(storeChannel)
+	}()
+
+	return storeChannel
+}
+
 func (us SqlUserStore) Get(id string) StoreChannel {
 
 	storeChannel := make(StoreChannel, 1)
@@ -1110,9 +1157,10 @@ func (us SqlUserStore) AnalyticsUniqueUserCount(teamId string) StoreChannel {
 
 		query := ""
 		if len(teamId) > 0 {
-			query = "SELECT COUNT(DISTINCT Users.Email) From Users, TeamMembers WHERE TeamMembers.TeamId = :TeamId AND Users.Id = TeamMembers.UserId AND TeamMembers.DeleteAt = 0 AND Users.DeleteAt = 0"
+			query = "SELECT COUNT(DISTINCT Users.Email) From Users, TeamMembers WHERE TeamMembers.TeamId = :TeamId AND Users.Id = TeamMembers.UserId AND TeamMembers.DeleteAt = 0 AND Users.DeleteAt = 0 AND Users.IsBot = false"
 		} else {
-			query = "SELECT COUNT(DISTINCT Email) FROM Users WHERE DeleteAt = 0"
+			// Bots aren't counted since they don't take up a seat
+			query = "SELECT COUNT(DISTINCT Email) FROM Users WHERE DeleteAt = 0 AND IsBot = false"
 		}
 
 		v, err := us.GetReplica().SelectInt(query, map[string]interface{}{"TeamId": teamId})
@@ -1175,7 +1223,7 @@ func (us SqlUserStore) Search(teamId string, term string, options map[string]boo
 
 		if teamId == "" {
 
-			// Id != '' is added because both SEARCH_CLAUSE and INACTIVE_CLAUSE start with an AND
+			// Id != '' is added because SEARCH_CLAUSE, INACTIVE_CLAUSE and BOT_CLAUSE all start with an AND
 			searchQuery = `
 			SELECT
 				*
@@ -1185,6 +1233,7 @@ func (us SqlUserStore) Search(teamId string, term string, options map[string]boo
 				Id != ''
 				SEARCH_CLAUSE
 				INACTIVE_CLAUSE
+				BOT_CLAUSE
 				ORDER BY Username ASC
 			LIMIT 100`
 		} else {
@@ -1199,6 +1248,7 @@ func (us SqlUserStore) Search(teamId string, term string, options map[string]boo
 				AND TeamMembers.DeleteAt = 0
 				SEARCH_CLAUSE
 				INACTIVE_CLAUSE
+				BOT_CLAUSE
 				ORDER BY Users.Username ASC
 			LIMIT 100`
 		}
@@ -1228,6 +1278,7 @@ func (us SqlUserStore) SearchNotInChannel(teamId string, channelId string, term
 				cm.UserId IS NULL
 				SEARCH_CLAUSE
 				INACTIVE_CLAUSE
+				BOT_CLAUSE
 			ORDER BY Users.Username ASC
 			LIMIT 100`
 		} else {
@@ -1246,6 +1297,7 @@ func (us SqlUserStore) SearchNotInChannel(teamId string, channelId string, term
 				cm.UserId IS NULL
 				SEARCH_CLAUSE
 				INACTIVE_CLAUSE
+				BOT_CLAUSE
 			ORDER BY Users.Username ASC
 			LIMIT 100`
 		}
@@ -1272,6 +1324,7 @@ func (us SqlUserStore) SearchInChannel(channelId string, term string, options ma
             AND ChannelMembers.UserId = Users.Id
             SEARCH_CLAUSE
             INACTIVE_CLAUSE
+            BOT_CLAUSE
             ORDER BY Users.Username ASC
         LIMIT 100`
 
@@ -1340,6 +1393,12 @@ func (us SqlUserStore) performSearch(searchQuery string, term string, options ma
 		searchQuery = strings.Replace(searchQuery, "INACTIVE_CLAUSE", "AND Users.DeleteAt = 0", 1)
 	}
 
+	if ok := options[USER_SEARCH_OPTION_EXCLUDE_BOTS]; ok {
+		searchQuery = strings.Replace(searchQuery, "BOT_CLAUSE", "AND Users.IsBot = false", 1)
+	} else {
+		searchQuery = strings.Replace(searchQuery, "BOT_CLAUSE", "", 1)
+	}
+
 	if term == "" {
 		searchQuery = strings.Replace(searchQuery, "SEARCH_CLAUSE", "", 1)
 	} else if utils.Cfg.SqlSettings.DriverName == model.DATABASE_DRIVER_POSTGRES {
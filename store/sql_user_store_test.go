@@ -6,6 +6,7 @@ package store
 import (
 	"github.com/mattermost/platform/model"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -151,6 +152,62 @@ func TestUserStoreUpdateFailedPasswordAttempts(t *testing.T) {
 
 }
 
+func TestUserStoreIncrementFailedPasswordAttempts(t *testing.T) {
+	Setup()
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	Must(store.User().Save(u1))
+
+	Must(store.User().UpdateFailedPasswordAttempts(u1.Id, 2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Must(store.User().IncrementFailedPasswordAttempts(u1.Id))
+		}()
+	}
+	wg.Wait()
+
+	if user := Must(store.User().Get(u1.Id)).(*model.User); user.FailedAttempts != 5 {
+		t.Fatal("should've counted every failed attempt", user.FailedAttempts)
+	}
+}
+
+func TestUserStoreUseMfaTimeStep(t *testing.T) {
+	Setup()
+
+	u1 := &model.User{}
+	u1.Email = model.NewId()
+	Must(store.User().Save(u1))
+
+	if ok := Must(store.User().UseMfaTimeStep(u1.Id, 100)).(bool); !ok {
+		t.Fatal("should've accepted the first time step")
+	}
+
+	if ok := Must(store.User().UseMfaTimeStep(u1.Id, 100)).(bool); ok {
+		t.Fatal("shouldn't have accepted the same time step twice")
+	}
+
+	if ok := Must(store.User().UseMfaTimeStep(u1.Id, 99)).(bool); ok {
+		t.Fatal("shouldn't have accepted an earlier time step")
+	}
+
+	if ok := Must(store.User().UseMfaTimeStep(u1.Id, 101)).(bool); !ok {
+		t.Fatal("should've accepted a later time step")
+	}
+
+	user := Must(store.User().Get(u1.Id)).(*model.User)
+	user.MfaLastTimeStep = 0
+	Must(store.User().Update(user, true))
+
+	if ok := Must(store.User().UseMfaTimeStep(u1.Id, 101)).(bool); ok {
+		t.Fatal("updating the user shouldn't have reset the last time step")
+	}
+}
+
 func TestUserStoreGet(t *testing.T) {
 	Setup()
 
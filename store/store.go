@@ -47,11 +47,23 @@ type Store interface {
 	Status() StatusStore
 	FileInfo() FileInfoStore
 	Reaction() ReactionStore
+	UploadSession() UploadSessionStore
+	Thread() ThreadStore
+	ScheduledPost() ScheduledPostStore
+	OutgoingWebhookDelivery() OutgoingWebhookDeliveryStore
+	Draft() DraftStore
+	LegalHold() LegalHoldStore
+	ClusterDiscovery() ClusterDiscoveryStore
+	MfaRecoveryCode() MfaRecoveryCodeStore
+	PersonalAccessToken() PersonalAccessTokenStore
+	Bot() BotStore
+	EventSubscription() EventSubscriptionStore
 	MarkSystemRanUnitTests()
 	Close()
 	DropAllTables()
 	TotalMasterDbConnections() int
 	TotalReadDbConnections() int
+	GetCurrentSchemaVersion() string
 }
 
 type TeamStore interface {
@@ -121,6 +133,7 @@ type ChannelStore interface {
 	SearchInTeam(teamId string, term string) StoreChannel
 	SearchMore(userId string, teamId string, term string) StoreChannel
 	GetMembersByIds(channelId string, userIds []string) StoreChannel
+	GetChannelsByIds(channelIds []string) StoreChannel
 }
 
 type PostStore interface {
@@ -131,11 +144,21 @@ type PostStore interface {
 	PermanentDeleteByUser(userId string) StoreChannel
 	GetPosts(channelId string, offset int, limit int, allowFromCache bool) StoreChannel
 	GetFlaggedPosts(userId string, offset int, limit int) StoreChannel
+	GetPinnedPosts(channelId string) StoreChannel
+	GetPinnedPostCount(channelId string) StoreChannel
+	SetPinned(postId string, isPinned bool) StoreChannel
 	GetPostsBefore(channelId string, postId string, numPosts int, offset int) StoreChannel
 	GetPostsAfter(channelId string, postId string, numPosts int, offset int) StoreChannel
 	GetPostsSince(channelId string, time int64, allowFromCache bool) StoreChannel
 	GetEtag(channelId string, allowFromCache bool) StoreChannel
 	Search(teamId string, userId string, params *model.SearchParams) StoreChannel
+	GetPostsByIds(postIds []string) StoreChannel
+	GetDeletedPostIds(postIds []string) StoreChannel
+	GetPostsBatchForIndexing(startTime int64, startPostId string, limit int) StoreChannel
+	GetPostIdsForRetention(endTime int64, teamId string, excludeTeamIds []string, legalHolds []*model.LegalHold, limit int) StoreChannel
+	CountPostsHeldForRetention(endTime int64, teamId string, excludeTeamIds []string, hold *model.LegalHold) StoreChannel
+	PermanentDeleteByIds(postIds []string) StoreChannel
+	CountPostsForLegalHold(hold *model.LegalHold, userId string, teamId string) StoreChannel
 	AnalyticsUserCountsWithPostsByDay(teamId string) StoreChannel
 	AnalyticsPostCountsByDay(teamId string) StoreChannel
 	AnalyticsPostCount(teamId string, mustHaveFile bool, mustHaveHashtag bool) StoreChannel
@@ -151,6 +174,7 @@ type UserStore interface {
 	UpdateAuthData(userId string, service string, authData *string, email string, resetMfa bool) StoreChannel
 	UpdateMfaSecret(userId, secret string) StoreChannel
 	UpdateMfaActive(userId string, active bool) StoreChannel
+	UseMfaTimeStep(userId string, timeStep int64) StoreChannel
 	Get(id string) StoreChannel
 	GetAll() StoreChannel
 	InvalidateProfilesInChannelCacheByUser(userId string)
@@ -171,6 +195,7 @@ type UserStore interface {
 	GetEtagForAllProfiles() StoreChannel
 	GetEtagForProfiles(teamId string) StoreChannel
 	UpdateFailedPasswordAttempts(userId string, attempts int) StoreChannel
+	IncrementFailedPasswordAttempts(userId string) StoreChannel
 	GetTotalUsersCount() StoreChannel
 	GetSystemAdminProfiles() StoreChannel
 	PermanentDelete(userId string) StoreChannel
@@ -194,6 +219,7 @@ type SessionStore interface {
 	UpdateLastActivityAt(sessionId string, time int64) StoreChannel
 	UpdateRoles(userId string, roles string) StoreChannel
 	UpdateDeviceId(id string, deviceId string, expiresAt int64) StoreChannel
+	UpdateProps(session *model.Session) StoreChannel
 	AnalyticsSessionCount() StoreChannel
 }
 
@@ -209,6 +235,8 @@ type ComplianceStore interface {
 	Get(id string) StoreChannel
 	GetAll() StoreChannel
 	ComplianceExport(compliance *model.Compliance) StoreChannel
+	GetChannelIdsForExport(compliance *model.Compliance) StoreChannel
+	ComplianceExportBatch(compliance *model.Compliance, channelId string, afterTime int64, afterPostId string, limit int) StoreChannel
 }
 
 type OAuthStore interface {
@@ -318,6 +346,11 @@ type FileInfoStore interface {
 	GetForPost(postId string) StoreChannel
 	AttachToPost(fileId string, postId string) StoreChannel
 	DeleteForPost(postId string) StoreChannel
+	Search(teamId string, userId string, params *model.SearchParams) StoreChannel
+	GetForRetention(endTime int64, teamId string, excludeTeamIds []string, legalHolds []*model.LegalHold, limit int) StoreChannel
+	CountHeldForRetention(endTime int64, teamId string, excludeTeamIds []string, hold *model.LegalHold) StoreChannel
+	PermanentDeleteByIds(fileIds []string) StoreChannel
+	GetForPostIds(postIds []string) StoreChannel
 }
 
 type ReactionStore interface {
@@ -325,4 +358,108 @@ type ReactionStore interface {
 	Delete(reaction *model.Reaction) StoreChannel
 	GetForPost(postId string) StoreChannel
 	DeleteAllWithEmojiName(emojiName string) StoreChannel
+	PermanentDeleteByPostIds(postIds []string) StoreChannel
+}
+
+type UploadSessionStore interface {
+	Save(session *model.UploadSession) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(userId string) StoreChannel
+	UpdateFileOffset(id string, oldOffset int64, newOffset int64) StoreChannel
+	Claim(id string, time int64) StoreChannel
+	ReleaseClaim(id string, claimedAt int64) StoreChannel
+	Delete(id string) StoreChannel
+	GetStale(updatedBefore int64, limit int) StoreChannel
+}
+
+type ThreadStore interface {
+	UpdateFromReplies(postId string, channelId string) StoreChannel
+	Get(postId string) StoreChannel
+	Delete(postId string) StoreChannel
+	SaveMembership(membership *model.ThreadMembership) StoreChannel
+	GetMembership(postId string, userId string) StoreChannel
+	GetFollowers(postId string) StoreChannel
+	GetThreadForUser(teamId string, userId string, postId string) StoreChannel
+	GetThreadsForUser(teamId string, userId string, offset int, limit int) StoreChannel
+	MarkAllAsRead(teamId string, userId string, timestamp int64) StoreChannel
+	PermanentDeleteByPostIds(postIds []string) StoreChannel
+}
+
+type ScheduledPostStore interface {
+	Save(post *model.ScheduledPost) StoreChannel
+	Update(post *model.ScheduledPost, oldProcessedAt int64) StoreChannel
+	Get(id string) StoreChannel
+	GetForUser(teamId string, userId string) StoreChannel
+	GetDue(time int64, expiredBefore int64, limit int) StoreChannel
+	Claim(id string, time int64, expiredBefore int64) StoreChannel
+	Delete(id string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type OutgoingWebhookDeliveryStore interface {
+	Save(delivery *model.OutgoingWebhookDelivery) StoreChannel
+	Update(delivery *model.OutgoingWebhookDelivery) StoreChannel
+	Get(id string) StoreChannel
+	GetByHook(hookId string, offset int, limit int) StoreChannel
+	GetDue(time int64, limit int) StoreChannel
+	Claim(id string, nextAttemptAt int64, leaseUntil int64) StoreChannel
+	PermanentDeleteBefore(time int64) StoreChannel
+	PermanentDeleteByPostIds(postIds []string) StoreChannel
+}
+
+type LegalHoldStore interface {
+	Save(hold *model.LegalHold) StoreChannel
+	Update(hold *model.LegalHold) StoreChannel
+	Get(id string) StoreChannel
+	GetAll() StoreChannel
+	Delete(id string) StoreChannel
+}
+
+type ClusterDiscoveryStore interface {
+	SaveOrUpdate(node *model.ClusterDiscovery) StoreChannel
+	GetAll(aliveSince int64) StoreChannel
+	Delete(id string) StoreChannel
+	Cleanup(before int64) StoreChannel
+}
+
+type MfaRecoveryCodeStore interface {
+	Save(userId string, hashes []string) StoreChannel
+	Use(userId string, hash string) StoreChannel
+	Count(userId string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type PersonalAccessTokenStore interface {
+	Save(token *model.PersonalAccessToken) StoreChannel
+	Get(id string) StoreChannel
+	GetByTokenHash(hash string) StoreChannel
+	GetByUser(userId string) StoreChannel
+	Delete(id string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type BotStore interface {
+	Save(bot *model.Bot) StoreChannel
+	Update(bot *model.Bot) StoreChannel
+	Get(userId string) StoreChannel
+	GetByOwner(ownerId string) StoreChannel
+	GetAll() StoreChannel
+	PermanentDelete(userId string) StoreChannel
+}
+
+type EventSubscriptionStore interface {
+	Save(subscription *model.EventSubscription) StoreChannel
+	Update(subscription *model.EventSubscription) StoreChannel
+	Get(id string) StoreChannel
+	GetByTeam(teamId string) StoreChannel
+	Delete(id string, time int64) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
+}
+
+type DraftStore interface {
+	Save(draft *model.Draft) StoreChannel
+	Get(userId string, channelId string, rootId string) StoreChannel
+	GetForUser(teamId string, userId string) StoreChannel
+	Delete(userId string, channelId string, rootId string) StoreChannel
+	PermanentDeleteByUser(userId string) StoreChannel
 }
@@ -0,0 +1,35 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package store
+
+//go:generate go run layer_generators/main.go
+
+import (
+	"time"
+
+	"github.com/mattermost/platform/einterfaces"
+	"github.com/mattermost/platform/utils"
+)
+
+// time reports how long a call to the store took once its result is ready. The result is passed through unchanged.
+func (s *TimerLayer) time(method string, start time.Time, storeChannel StoreChannel) StoreChannel {
+	metrics := einterfaces.GetMetricsInterface()
+	if metrics == nil || !*utils.Cfg.MetricsSettings.Enable {
+		return storeChannel
+	}
+
+	timedChannel := make(StoreChannel, 1)
+
+	go func() {
+		result := <-storeChannel
+
+		elapsed := float64(time.Since(start)) / float64(time.Second)
+		metrics.ObserveStoreMethodDuration(method, result.Err == nil, elapsed)
+
+		timedChannel <- result
+		close(timedChannel)
+	}()
+
+	return timedChannel
+}
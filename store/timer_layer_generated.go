@@ -0,0 +1,1550 @@
+// Code generated by layer_generators; DO NOT EDIT.
+
+package store
+
+import (
+	"time"
+
+	"github.com/mattermost/platform/model"
+)
+
+// TimerLayer wraps a Store to report how long each call to it takes.
+type TimerLayer struct {
+	Store
+	TeamStore                    TimerLayerTeamStore
+	ChannelStore                 TimerLayerChannelStore
+	PostStore                    TimerLayerPostStore
+	UserStore                    TimerLayerUserStore
+	AuditStore                   TimerLayerAuditStore
+	ComplianceStore              TimerLayerComplianceStore
+	SessionStore                 TimerLayerSessionStore
+	OAuthStore                   TimerLayerOAuthStore
+	SystemStore                  TimerLayerSystemStore
+	WebhookStore                 TimerLayerWebhookStore
+	CommandStore                 TimerLayerCommandStore
+	PreferenceStore              TimerLayerPreferenceStore
+	LicenseStore                 TimerLayerLicenseStore
+	PasswordRecoveryStore        TimerLayerPasswordRecoveryStore
+	EmojiStore                   TimerLayerEmojiStore
+	StatusStore                  TimerLayerStatusStore
+	FileInfoStore                TimerLayerFileInfoStore
+	ReactionStore                TimerLayerReactionStore
+	UploadSessionStore           TimerLayerUploadSessionStore
+	ThreadStore                  TimerLayerThreadStore
+	ScheduledPostStore           TimerLayerScheduledPostStore
+	OutgoingWebhookDeliveryStore TimerLayerOutgoingWebhookDeliveryStore
+	DraftStore                   TimerLayerDraftStore
+	LegalHoldStore               TimerLayerLegalHoldStore
+	ClusterDiscoveryStore        TimerLayerClusterDiscoveryStore
+	MfaRecoveryCodeStore         TimerLayerMfaRecoveryCodeStore
+	PersonalAccessTokenStore     TimerLayerPersonalAccessTokenStore
+	BotStore                     TimerLayerBotStore
+	EventSubscriptionStore       TimerLayerEventSubscriptionStore
+}
+
+func (s *TimerLayer) Team() TeamStore {
+	return &s.TeamStore
+}
+
+func (s *TimerLayer) Channel() ChannelStore {
+	return &s.ChannelStore
+}
+
+func (s *TimerLayer) Post() PostStore {
+	return &s.PostStore
+}
+
+func (s *TimerLayer) User() UserStore {
+	return &s.UserStore
+}
+
+func (s *TimerLayer) Audit() AuditStore {
+	return &s.AuditStore
+}
+
+func (s *TimerLayer) Compliance() ComplianceStore {
+	return &s.ComplianceStore
+}
+
+func (s *TimerLayer) Session() SessionStore {
+	return &s.SessionStore
+}
+
+func (s *TimerLayer) OAuth() OAuthStore {
+	return &s.OAuthStore
+}
+
+func (s *TimerLayer) System() SystemStore {
+	return &s.SystemStore
+}
+
+func (s *TimerLayer) Webhook() WebhookStore {
+	return &s.WebhookStore
+}
+
+func (s *TimerLayer) Command() CommandStore {
+	return &s.CommandStore
+}
+
+func (s *TimerLayer) Preference() PreferenceStore {
+	return &s.PreferenceStore
+}
+
+func (s *TimerLayer) License() LicenseStore {
+	return &s.LicenseStore
+}
+
+func (s *TimerLayer) PasswordRecovery() PasswordRecoveryStore {
+	return &s.PasswordRecoveryStore
+}
+
+func (s *TimerLayer) Emoji() EmojiStore {
+	return &s.EmojiStore
+}
+
+func (s *TimerLayer) Status() StatusStore {
+	return &s.StatusStore
+}
+
+func (s *TimerLayer) FileInfo() FileInfoStore {
+	return &s.FileInfoStore
+}
+
+func (s *TimerLayer) Reaction() ReactionStore {
+	return &s.ReactionStore
+}
+
+func (s *TimerLayer) UploadSession() UploadSessionStore {
+	return &s.UploadSessionStore
+}
+
+func (s *TimerLayer) Thread() ThreadStore {
+	return &s.ThreadStore
+}
+
+func (s *TimerLayer) ScheduledPost() ScheduledPostStore {
+	return &s.ScheduledPostStore
+}
+
+func (s *TimerLayer) OutgoingWebhookDelivery() OutgoingWebhookDeliveryStore {
+	return &s.OutgoingWebhookDeliveryStore
+}
+
+func (s *TimerLayer) Draft() DraftStore {
+	return &s.DraftStore
+}
+
+func (s *TimerLayer) LegalHold() LegalHoldStore {
+	return &s.LegalHoldStore
+}
+
+func (s *TimerLayer) ClusterDiscovery() ClusterDiscoveryStore {
+	return &s.ClusterDiscoveryStore
+}
+
+func (s *TimerLayer) MfaRecoveryCode() MfaRecoveryCodeStore {
+	return &s.MfaRecoveryCodeStore
+}
+
+func (s *TimerLayer) PersonalAccessToken() PersonalAccessTokenStore {
+	return &s.PersonalAccessTokenStore
+}
+
+func (s *TimerLayer) Bot() BotStore {
+	return &s.BotStore
+}
+
+func (s *TimerLayer) EventSubscription() EventSubscriptionStore {
+	return &s.EventSubscriptionStore
+}
+
+type TimerLayerTeamStore struct {
+	TeamStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerTeamStore) AnalyticsTeamCount() StoreChannel {
+	return s.Root.time("TeamStore.AnalyticsTeamCount", time.Now(), s.TeamStore.AnalyticsTeamCount())
+}
+
+func (s *TimerLayerTeamStore) Get(id string) StoreChannel {
+	return s.Root.time("TeamStore.Get", time.Now(), s.TeamStore.Get(id))
+}
+
+func (s *TimerLayerTeamStore) GetActiveMemberCount(teamId string) StoreChannel {
+	return s.Root.time("TeamStore.GetActiveMemberCount", time.Now(), s.TeamStore.GetActiveMemberCount(teamId))
+}
+
+func (s *TimerLayerTeamStore) GetAll() StoreChannel {
+	return s.Root.time("TeamStore.GetAll", time.Now(), s.TeamStore.GetAll())
+}
+
+func (s *TimerLayerTeamStore) GetAllTeamListing() StoreChannel {
+	return s.Root.time("TeamStore.GetAllTeamListing", time.Now(), s.TeamStore.GetAllTeamListing())
+}
+
+func (s *TimerLayerTeamStore) GetByInviteId(inviteId string) StoreChannel {
+	return s.Root.time("TeamStore.GetByInviteId", time.Now(), s.TeamStore.GetByInviteId(inviteId))
+}
+
+func (s *TimerLayerTeamStore) GetByName(name string) StoreChannel {
+	return s.Root.time("TeamStore.GetByName", time.Now(), s.TeamStore.GetByName(name))
+}
+
+func (s *TimerLayerTeamStore) GetMember(teamId string, userId string) StoreChannel {
+	return s.Root.time("TeamStore.GetMember", time.Now(), s.TeamStore.GetMember(teamId, userId))
+}
+
+func (s *TimerLayerTeamStore) GetMembers(teamId string, offset int, limit int) StoreChannel {
+	return s.Root.time("TeamStore.GetMembers", time.Now(), s.TeamStore.GetMembers(teamId, offset, limit))
+}
+
+func (s *TimerLayerTeamStore) GetMembersByIds(teamId string, userIds []string) StoreChannel {
+	return s.Root.time("TeamStore.GetMembersByIds", time.Now(), s.TeamStore.GetMembersByIds(teamId, userIds))
+}
+
+func (s *TimerLayerTeamStore) GetTeamsByUserId(userId string) StoreChannel {
+	return s.Root.time("TeamStore.GetTeamsByUserId", time.Now(), s.TeamStore.GetTeamsByUserId(userId))
+}
+
+func (s *TimerLayerTeamStore) GetTeamsForUser(userId string) StoreChannel {
+	return s.Root.time("TeamStore.GetTeamsForUser", time.Now(), s.TeamStore.GetTeamsForUser(userId))
+}
+
+func (s *TimerLayerTeamStore) GetTeamsUnreadForUser(teamId string, userId string) StoreChannel {
+	return s.Root.time("TeamStore.GetTeamsUnreadForUser", time.Now(), s.TeamStore.GetTeamsUnreadForUser(teamId, userId))
+}
+
+func (s *TimerLayerTeamStore) GetTotalMemberCount(teamId string) StoreChannel {
+	return s.Root.time("TeamStore.GetTotalMemberCount", time.Now(), s.TeamStore.GetTotalMemberCount(teamId))
+}
+
+func (s *TimerLayerTeamStore) PermanentDelete(teamId string) StoreChannel {
+	return s.Root.time("TeamStore.PermanentDelete", time.Now(), s.TeamStore.PermanentDelete(teamId))
+}
+
+func (s *TimerLayerTeamStore) RemoveAllMembersByTeam(teamId string) StoreChannel {
+	return s.Root.time("TeamStore.RemoveAllMembersByTeam", time.Now(), s.TeamStore.RemoveAllMembersByTeam(teamId))
+}
+
+func (s *TimerLayerTeamStore) RemoveAllMembersByUser(userId string) StoreChannel {
+	return s.Root.time("TeamStore.RemoveAllMembersByUser", time.Now(), s.TeamStore.RemoveAllMembersByUser(userId))
+}
+
+func (s *TimerLayerTeamStore) RemoveMember(teamId string, userId string) StoreChannel {
+	return s.Root.time("TeamStore.RemoveMember", time.Now(), s.TeamStore.RemoveMember(teamId, userId))
+}
+
+func (s *TimerLayerTeamStore) Save(team *model.Team) StoreChannel {
+	return s.Root.time("TeamStore.Save", time.Now(), s.TeamStore.Save(team))
+}
+
+func (s *TimerLayerTeamStore) SaveMember(member *model.TeamMember) StoreChannel {
+	return s.Root.time("TeamStore.SaveMember", time.Now(), s.TeamStore.SaveMember(member))
+}
+
+func (s *TimerLayerTeamStore) Update(team *model.Team) StoreChannel {
+	return s.Root.time("TeamStore.Update", time.Now(), s.TeamStore.Update(team))
+}
+
+func (s *TimerLayerTeamStore) UpdateDisplayName(name string, teamId string) StoreChannel {
+	return s.Root.time("TeamStore.UpdateDisplayName", time.Now(), s.TeamStore.UpdateDisplayName(name, teamId))
+}
+
+func (s *TimerLayerTeamStore) UpdateMember(member *model.TeamMember) StoreChannel {
+	return s.Root.time("TeamStore.UpdateMember", time.Now(), s.TeamStore.UpdateMember(member))
+}
+
+type TimerLayerChannelStore struct {
+	ChannelStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerChannelStore) AnalyticsTypeCount(teamId string, channelType string) StoreChannel {
+	return s.Root.time("ChannelStore.AnalyticsTypeCount", time.Now(), s.ChannelStore.AnalyticsTypeCount(teamId, channelType))
+}
+
+func (s *TimerLayerChannelStore) CreateDirectChannel(userId string, otherUserId string) StoreChannel {
+	return s.Root.time("ChannelStore.CreateDirectChannel", time.Now(), s.ChannelStore.CreateDirectChannel(userId, otherUserId))
+}
+
+func (s *TimerLayerChannelStore) Delete(channelId string, timeParam int64) StoreChannel {
+	return s.Root.time("ChannelStore.Delete", time.Now(), s.ChannelStore.Delete(channelId, timeParam))
+}
+
+func (s *TimerLayerChannelStore) ExtraUpdateByUser(userId string, timeParam int64) StoreChannel {
+	return s.Root.time("ChannelStore.ExtraUpdateByUser", time.Now(), s.ChannelStore.ExtraUpdateByUser(userId, timeParam))
+}
+
+func (s *TimerLayerChannelStore) Get(id string, allowFromCache bool) StoreChannel {
+	return s.Root.time("ChannelStore.Get", time.Now(), s.ChannelStore.Get(id, allowFromCache))
+}
+
+func (s *TimerLayerChannelStore) GetAll(teamId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetAll", time.Now(), s.ChannelStore.GetAll(teamId))
+}
+
+func (s *TimerLayerChannelStore) GetAllChannelMembersForUser(userId string, allowFromCache bool) StoreChannel {
+	return s.Root.time("ChannelStore.GetAllChannelMembersForUser", time.Now(), s.ChannelStore.GetAllChannelMembersForUser(userId, allowFromCache))
+}
+
+func (s *TimerLayerChannelStore) GetByName(team_id string, name string) StoreChannel {
+	return s.Root.time("ChannelStore.GetByName", time.Now(), s.ChannelStore.GetByName(team_id, name))
+}
+
+func (s *TimerLayerChannelStore) GetByNameIncludeDeleted(team_id string, name string) StoreChannel {
+	return s.Root.time("ChannelStore.GetByNameIncludeDeleted", time.Now(), s.ChannelStore.GetByNameIncludeDeleted(team_id, name))
+}
+
+func (s *TimerLayerChannelStore) GetChannelCounts(teamId string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetChannelCounts", time.Now(), s.ChannelStore.GetChannelCounts(teamId, userId))
+}
+
+func (s *TimerLayerChannelStore) GetChannels(teamId string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetChannels", time.Now(), s.ChannelStore.GetChannels(teamId, userId))
+}
+
+func (s *TimerLayerChannelStore) GetChannelsByIds(channelIds []string) StoreChannel {
+	return s.Root.time("ChannelStore.GetChannelsByIds", time.Now(), s.ChannelStore.GetChannelsByIds(channelIds))
+}
+
+func (s *TimerLayerChannelStore) GetForPost(postId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetForPost", time.Now(), s.ChannelStore.GetForPost(postId))
+}
+
+func (s *TimerLayerChannelStore) GetFromMaster(id string) StoreChannel {
+	return s.Root.time("ChannelStore.GetFromMaster", time.Now(), s.ChannelStore.GetFromMaster(id))
+}
+
+func (s *TimerLayerChannelStore) GetMember(channelId string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetMember", time.Now(), s.ChannelStore.GetMember(channelId, userId))
+}
+
+func (s *TimerLayerChannelStore) GetMemberCount(channelId string, allowFromCache bool) StoreChannel {
+	return s.Root.time("ChannelStore.GetMemberCount", time.Now(), s.ChannelStore.GetMemberCount(channelId, allowFromCache))
+}
+
+func (s *TimerLayerChannelStore) GetMemberForPost(postId string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetMemberForPost", time.Now(), s.ChannelStore.GetMemberForPost(postId, userId))
+}
+
+func (s *TimerLayerChannelStore) GetMembers(channelId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetMembers", time.Now(), s.ChannelStore.GetMembers(channelId))
+}
+
+func (s *TimerLayerChannelStore) GetMembersByIds(channelId string, userIds []string) StoreChannel {
+	return s.Root.time("ChannelStore.GetMembersByIds", time.Now(), s.ChannelStore.GetMembersByIds(channelId, userIds))
+}
+
+func (s *TimerLayerChannelStore) GetMembersForUser(teamId string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetMembersForUser", time.Now(), s.ChannelStore.GetMembersForUser(teamId, userId))
+}
+
+func (s *TimerLayerChannelStore) GetMoreChannels(teamId string, userId string, offset int, limit int) StoreChannel {
+	return s.Root.time("ChannelStore.GetMoreChannels", time.Now(), s.ChannelStore.GetMoreChannels(teamId, userId, offset, limit))
+}
+
+func (s *TimerLayerChannelStore) GetTeamChannels(teamId string) StoreChannel {
+	return s.Root.time("ChannelStore.GetTeamChannels", time.Now(), s.ChannelStore.GetTeamChannels(teamId))
+}
+
+func (s *TimerLayerChannelStore) IncrementMentionCount(channelId string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.IncrementMentionCount", time.Now(), s.ChannelStore.IncrementMentionCount(channelId, userId))
+}
+
+func (s *TimerLayerChannelStore) PermanentDeleteByTeam(teamId string) StoreChannel {
+	return s.Root.time("ChannelStore.PermanentDeleteByTeam", time.Now(), s.ChannelStore.PermanentDeleteByTeam(teamId))
+}
+
+func (s *TimerLayerChannelStore) PermanentDeleteMembersByUser(userId string) StoreChannel {
+	return s.Root.time("ChannelStore.PermanentDeleteMembersByUser", time.Now(), s.ChannelStore.PermanentDeleteMembersByUser(userId))
+}
+
+func (s *TimerLayerChannelStore) RemoveMember(channelId string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.RemoveMember", time.Now(), s.ChannelStore.RemoveMember(channelId, userId))
+}
+
+func (s *TimerLayerChannelStore) Save(channel *model.Channel) StoreChannel {
+	return s.Root.time("ChannelStore.Save", time.Now(), s.ChannelStore.Save(channel))
+}
+
+func (s *TimerLayerChannelStore) SaveDirectChannel(channel *model.Channel, member1 *model.ChannelMember, member2 *model.ChannelMember) StoreChannel {
+	return s.Root.time("ChannelStore.SaveDirectChannel", time.Now(), s.ChannelStore.SaveDirectChannel(channel, member1, member2))
+}
+
+func (s *TimerLayerChannelStore) SaveMember(member *model.ChannelMember) StoreChannel {
+	return s.Root.time("ChannelStore.SaveMember", time.Now(), s.ChannelStore.SaveMember(member))
+}
+
+func (s *TimerLayerChannelStore) SearchInTeam(teamId string, term string) StoreChannel {
+	return s.Root.time("ChannelStore.SearchInTeam", time.Now(), s.ChannelStore.SearchInTeam(teamId, term))
+}
+
+func (s *TimerLayerChannelStore) SearchMore(userId string, teamId string, term string) StoreChannel {
+	return s.Root.time("ChannelStore.SearchMore", time.Now(), s.ChannelStore.SearchMore(userId, teamId, term))
+}
+
+func (s *TimerLayerChannelStore) SetDeleteAt(channelId string, deleteAt int64, updateAt int64) StoreChannel {
+	return s.Root.time("ChannelStore.SetDeleteAt", time.Now(), s.ChannelStore.SetDeleteAt(channelId, deleteAt, updateAt))
+}
+
+func (s *TimerLayerChannelStore) SetLastViewedAt(channelId string, userId string, newLastViewedAt int64) StoreChannel {
+	return s.Root.time("ChannelStore.SetLastViewedAt", time.Now(), s.ChannelStore.SetLastViewedAt(channelId, userId, newLastViewedAt))
+}
+
+func (s *TimerLayerChannelStore) Update(channel *model.Channel) StoreChannel {
+	return s.Root.time("ChannelStore.Update", time.Now(), s.ChannelStore.Update(channel))
+}
+
+func (s *TimerLayerChannelStore) UpdateLastViewedAt(channelIds []string, userId string) StoreChannel {
+	return s.Root.time("ChannelStore.UpdateLastViewedAt", time.Now(), s.ChannelStore.UpdateLastViewedAt(channelIds, userId))
+}
+
+func (s *TimerLayerChannelStore) UpdateMember(member *model.ChannelMember) StoreChannel {
+	return s.Root.time("ChannelStore.UpdateMember", time.Now(), s.ChannelStore.UpdateMember(member))
+}
+
+type TimerLayerPostStore struct {
+	PostStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerPostStore) AnalyticsPostCount(teamId string, mustHaveFile bool, mustHaveHashtag bool) StoreChannel {
+	return s.Root.time("PostStore.AnalyticsPostCount", time.Now(), s.PostStore.AnalyticsPostCount(teamId, mustHaveFile, mustHaveHashtag))
+}
+
+func (s *TimerLayerPostStore) AnalyticsPostCountsByDay(teamId string) StoreChannel {
+	return s.Root.time("PostStore.AnalyticsPostCountsByDay", time.Now(), s.PostStore.AnalyticsPostCountsByDay(teamId))
+}
+
+func (s *TimerLayerPostStore) AnalyticsUserCountsWithPostsByDay(teamId string) StoreChannel {
+	return s.Root.time("PostStore.AnalyticsUserCountsWithPostsByDay", time.Now(), s.PostStore.AnalyticsUserCountsWithPostsByDay(teamId))
+}
+
+func (s *TimerLayerPostStore) CountPostsForLegalHold(hold *model.LegalHold, userId string, teamId string) StoreChannel {
+	return s.Root.time("PostStore.CountPostsForLegalHold", time.Now(), s.PostStore.CountPostsForLegalHold(hold, userId, teamId))
+}
+
+func (s *TimerLayerPostStore) CountPostsHeldForRetention(endTime int64, teamId string, excludeTeamIds []string, hold *model.LegalHold) StoreChannel {
+	return s.Root.time("PostStore.CountPostsHeldForRetention", time.Now(), s.PostStore.CountPostsHeldForRetention(endTime, teamId, excludeTeamIds, hold))
+}
+
+func (s *TimerLayerPostStore) Delete(postId string, timeParam int64) StoreChannel {
+	return s.Root.time("PostStore.Delete", time.Now(), s.PostStore.Delete(postId, timeParam))
+}
+
+func (s *TimerLayerPostStore) Get(id string) StoreChannel {
+	return s.Root.time("PostStore.Get", time.Now(), s.PostStore.Get(id))
+}
+
+func (s *TimerLayerPostStore) GetDeletedPostIds(postIds []string) StoreChannel {
+	return s.Root.time("PostStore.GetDeletedPostIds", time.Now(), s.PostStore.GetDeletedPostIds(postIds))
+}
+
+func (s *TimerLayerPostStore) GetEtag(channelId string, allowFromCache bool) StoreChannel {
+	return s.Root.time("PostStore.GetEtag", time.Now(), s.PostStore.GetEtag(channelId, allowFromCache))
+}
+
+func (s *TimerLayerPostStore) GetFlaggedPosts(userId string, offset int, limit int) StoreChannel {
+	return s.Root.time("PostStore.GetFlaggedPosts", time.Now(), s.PostStore.GetFlaggedPosts(userId, offset, limit))
+}
+
+func (s *TimerLayerPostStore) GetPinnedPostCount(channelId string) StoreChannel {
+	return s.Root.time("PostStore.GetPinnedPostCount", time.Now(), s.PostStore.GetPinnedPostCount(channelId))
+}
+
+func (s *TimerLayerPostStore) GetPinnedPosts(channelId string) StoreChannel {
+	return s.Root.time("PostStore.GetPinnedPosts", time.Now(), s.PostStore.GetPinnedPosts(channelId))
+}
+
+func (s *TimerLayerPostStore) GetPostIdsForRetention(endTime int64, teamId string, excludeTeamIds []string, legalHolds []*model.LegalHold, limit int) StoreChannel {
+	return s.Root.time("PostStore.GetPostIdsForRetention", time.Now(), s.PostStore.GetPostIdsForRetention(endTime, teamId, excludeTeamIds, legalHolds, limit))
+}
+
+func (s *TimerLayerPostStore) GetPosts(channelId string, offset int, limit int, allowFromCache bool) StoreChannel {
+	return s.Root.time("PostStore.GetPosts", time.Now(), s.PostStore.GetPosts(channelId, offset, limit, allowFromCache))
+}
+
+func (s *TimerLayerPostStore) GetPostsAfter(channelId string, postId string, numPosts int, offset int) StoreChannel {
+	return s.Root.time("PostStore.GetPostsAfter", time.Now(), s.PostStore.GetPostsAfter(channelId, postId, numPosts, offset))
+}
+
+func (s *TimerLayerPostStore) GetPostsBatchForIndexing(startTime int64, startPostId string, limit int) StoreChannel {
+	return s.Root.time("PostStore.GetPostsBatchForIndexing", time.Now(), s.PostStore.GetPostsBatchForIndexing(startTime, startPostId, limit))
+}
+
+func (s *TimerLayerPostStore) GetPostsBefore(channelId string, postId string, numPosts int, offset int) StoreChannel {
+	return s.Root.time("PostStore.GetPostsBefore", time.Now(), s.PostStore.GetPostsBefore(channelId, postId, numPosts, offset))
+}
+
+func (s *TimerLayerPostStore) GetPostsByIds(postIds []string) StoreChannel {
+	return s.Root.time("PostStore.GetPostsByIds", time.Now(), s.PostStore.GetPostsByIds(postIds))
+}
+
+func (s *TimerLayerPostStore) GetPostsSince(channelId string, timeParam int64, allowFromCache bool) StoreChannel {
+	return s.Root.time("PostStore.GetPostsSince", time.Now(), s.PostStore.GetPostsSince(channelId, timeParam, allowFromCache))
+}
+
+func (s *TimerLayerPostStore) PermanentDeleteByIds(postIds []string) StoreChannel {
+	return s.Root.time("PostStore.PermanentDeleteByIds", time.Now(), s.PostStore.PermanentDeleteByIds(postIds))
+}
+
+func (s *TimerLayerPostStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("PostStore.PermanentDeleteByUser", time.Now(), s.PostStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerPostStore) Save(post *model.Post) StoreChannel {
+	return s.Root.time("PostStore.Save", time.Now(), s.PostStore.Save(post))
+}
+
+func (s *TimerLayerPostStore) Search(teamId string, userId string, params *model.SearchParams) StoreChannel {
+	return s.Root.time("PostStore.Search", time.Now(), s.PostStore.Search(teamId, userId, params))
+}
+
+func (s *TimerLayerPostStore) SetPinned(postId string, isPinned bool) StoreChannel {
+	return s.Root.time("PostStore.SetPinned", time.Now(), s.PostStore.SetPinned(postId, isPinned))
+}
+
+func (s *TimerLayerPostStore) Update(newPost *model.Post, oldPost *model.Post) StoreChannel {
+	return s.Root.time("PostStore.Update", time.Now(), s.PostStore.Update(newPost, oldPost))
+}
+
+type TimerLayerUserStore struct {
+	UserStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerUserStore) AnalyticsUniqueUserCount(teamId string) StoreChannel {
+	return s.Root.time("UserStore.AnalyticsUniqueUserCount", time.Now(), s.UserStore.AnalyticsUniqueUserCount(teamId))
+}
+
+func (s *TimerLayerUserStore) Get(id string) StoreChannel {
+	return s.Root.time("UserStore.Get", time.Now(), s.UserStore.Get(id))
+}
+
+func (s *TimerLayerUserStore) GetAll() StoreChannel {
+	return s.Root.time("UserStore.GetAll", time.Now(), s.UserStore.GetAll())
+}
+
+func (s *TimerLayerUserStore) GetAllProfiles(offset int, limit int) StoreChannel {
+	return s.Root.time("UserStore.GetAllProfiles", time.Now(), s.UserStore.GetAllProfiles(offset, limit))
+}
+
+func (s *TimerLayerUserStore) GetAllUsingAuthService(authService string) StoreChannel {
+	return s.Root.time("UserStore.GetAllUsingAuthService", time.Now(), s.UserStore.GetAllUsingAuthService(authService))
+}
+
+func (s *TimerLayerUserStore) GetByAuth(authData *string, authService string) StoreChannel {
+	return s.Root.time("UserStore.GetByAuth", time.Now(), s.UserStore.GetByAuth(authData, authService))
+}
+
+func (s *TimerLayerUserStore) GetByEmail(email string) StoreChannel {
+	return s.Root.time("UserStore.GetByEmail", time.Now(), s.UserStore.GetByEmail(email))
+}
+
+func (s *TimerLayerUserStore) GetByUsername(username string) StoreChannel {
+	return s.Root.time("UserStore.GetByUsername", time.Now(), s.UserStore.GetByUsername(username))
+}
+
+func (s *TimerLayerUserStore) GetEtagForAllProfiles() StoreChannel {
+	return s.Root.time("UserStore.GetEtagForAllProfiles", time.Now(), s.UserStore.GetEtagForAllProfiles())
+}
+
+func (s *TimerLayerUserStore) GetEtagForProfiles(teamId string) StoreChannel {
+	return s.Root.time("UserStore.GetEtagForProfiles", time.Now(), s.UserStore.GetEtagForProfiles(teamId))
+}
+
+func (s *TimerLayerUserStore) GetForLogin(loginId string, allowSignInWithUsername bool, allowSignInWithEmail bool, ldapEnabled bool) StoreChannel {
+	return s.Root.time("UserStore.GetForLogin", time.Now(), s.UserStore.GetForLogin(loginId, allowSignInWithUsername, allowSignInWithEmail, ldapEnabled))
+}
+
+func (s *TimerLayerUserStore) GetProfileByIds(userId []string, allowFromCache bool) StoreChannel {
+	return s.Root.time("UserStore.GetProfileByIds", time.Now(), s.UserStore.GetProfileByIds(userId, allowFromCache))
+}
+
+func (s *TimerLayerUserStore) GetProfiles(teamId string, offset int, limit int) StoreChannel {
+	return s.Root.time("UserStore.GetProfiles", time.Now(), s.UserStore.GetProfiles(teamId, offset, limit))
+}
+
+func (s *TimerLayerUserStore) GetProfilesByUsernames(usernames []string, teamId string) StoreChannel {
+	return s.Root.time("UserStore.GetProfilesByUsernames", time.Now(), s.UserStore.GetProfilesByUsernames(usernames, teamId))
+}
+
+func (s *TimerLayerUserStore) GetProfilesInChannel(channelId string, offset int, limit int, allowFromCache bool) StoreChannel {
+	return s.Root.time("UserStore.GetProfilesInChannel", time.Now(), s.UserStore.GetProfilesInChannel(channelId, offset, limit, allowFromCache))
+}
+
+func (s *TimerLayerUserStore) GetProfilesNotInChannel(teamId string, channelId string, offset int, limit int) StoreChannel {
+	return s.Root.time("UserStore.GetProfilesNotInChannel", time.Now(), s.UserStore.GetProfilesNotInChannel(teamId, channelId, offset, limit))
+}
+
+func (s *TimerLayerUserStore) GetRecentlyActiveUsersForTeam(teamId string) StoreChannel {
+	return s.Root.time("UserStore.GetRecentlyActiveUsersForTeam", time.Now(), s.UserStore.GetRecentlyActiveUsersForTeam(teamId))
+}
+
+func (s *TimerLayerUserStore) GetSystemAdminProfiles() StoreChannel {
+	return s.Root.time("UserStore.GetSystemAdminProfiles", time.Now(), s.UserStore.GetSystemAdminProfiles())
+}
+
+func (s *TimerLayerUserStore) GetTotalUsersCount() StoreChannel {
+	return s.Root.time("UserStore.GetTotalUsersCount", time.Now(), s.UserStore.GetTotalUsersCount())
+}
+
+func (s *TimerLayerUserStore) GetUnreadCount(userId string) StoreChannel {
+	return s.Root.time("UserStore.GetUnreadCount", time.Now(), s.UserStore.GetUnreadCount(userId))
+}
+
+func (s *TimerLayerUserStore) GetUnreadCountForChannel(userId string, channelId string) StoreChannel {
+	return s.Root.time("UserStore.GetUnreadCountForChannel", time.Now(), s.UserStore.GetUnreadCountForChannel(userId, channelId))
+}
+
+func (s *TimerLayerUserStore) IncrementFailedPasswordAttempts(userId string) StoreChannel {
+	return s.Root.time("UserStore.IncrementFailedPasswordAttempts", time.Now(), s.UserStore.IncrementFailedPasswordAttempts(userId))
+}
+
+func (s *TimerLayerUserStore) PermanentDelete(userId string) StoreChannel {
+	return s.Root.time("UserStore.PermanentDelete", time.Now(), s.UserStore.PermanentDelete(userId))
+}
+
+func (s *TimerLayerUserStore) Save(user *model.User) StoreChannel {
+	return s.Root.time("UserStore.Save", time.Now(), s.UserStore.Save(user))
+}
+
+func (s *TimerLayerUserStore) Search(teamId string, term string, options map[string]bool) StoreChannel {
+	return s.Root.time("UserStore.Search", time.Now(), s.UserStore.Search(teamId, term, options))
+}
+
+func (s *TimerLayerUserStore) SearchInChannel(channelId string, term string, options map[string]bool) StoreChannel {
+	return s.Root.time("UserStore.SearchInChannel", time.Now(), s.UserStore.SearchInChannel(channelId, term, options))
+}
+
+func (s *TimerLayerUserStore) SearchNotInChannel(teamId string, channelId string, term string, options map[string]bool) StoreChannel {
+	return s.Root.time("UserStore.SearchNotInChannel", time.Now(), s.UserStore.SearchNotInChannel(teamId, channelId, term, options))
+}
+
+func (s *TimerLayerUserStore) Update(user *model.User, allowRoleUpdate bool) StoreChannel {
+	return s.Root.time("UserStore.Update", time.Now(), s.UserStore.Update(user, allowRoleUpdate))
+}
+
+func (s *TimerLayerUserStore) UpdateAuthData(userId string, service string, authData *string, email string, resetMfa bool) StoreChannel {
+	return s.Root.time("UserStore.UpdateAuthData", time.Now(), s.UserStore.UpdateAuthData(userId, service, authData, email, resetMfa))
+}
+
+func (s *TimerLayerUserStore) UpdateFailedPasswordAttempts(userId string, attempts int) StoreChannel {
+	return s.Root.time("UserStore.UpdateFailedPasswordAttempts", time.Now(), s.UserStore.UpdateFailedPasswordAttempts(userId, attempts))
+}
+
+func (s *TimerLayerUserStore) UpdateLastPictureUpdate(userId string) StoreChannel {
+	return s.Root.time("UserStore.UpdateLastPictureUpdate", time.Now(), s.UserStore.UpdateLastPictureUpdate(userId))
+}
+
+func (s *TimerLayerUserStore) UpdateMfaActive(userId string, active bool) StoreChannel {
+	return s.Root.time("UserStore.UpdateMfaActive", time.Now(), s.UserStore.UpdateMfaActive(userId, active))
+}
+
+func (s *TimerLayerUserStore) UpdateMfaSecret(userId string, secret string) StoreChannel {
+	return s.Root.time("UserStore.UpdateMfaSecret", time.Now(), s.UserStore.UpdateMfaSecret(userId, secret))
+}
+
+func (s *TimerLayerUserStore) UpdatePassword(userId string, newPassword string) StoreChannel {
+	return s.Root.time("UserStore.UpdatePassword", time.Now(), s.UserStore.UpdatePassword(userId, newPassword))
+}
+
+func (s *TimerLayerUserStore) UpdateUpdateAt(userId string) StoreChannel {
+	return s.Root.time("UserStore.UpdateUpdateAt", time.Now(), s.UserStore.UpdateUpdateAt(userId))
+}
+
+func (s *TimerLayerUserStore) UseMfaTimeStep(userId string, timeStep int64) StoreChannel {
+	return s.Root.time("UserStore.UseMfaTimeStep", time.Now(), s.UserStore.UseMfaTimeStep(userId, timeStep))
+}
+
+func (s *TimerLayerUserStore) VerifyEmail(userId string) StoreChannel {
+	return s.Root.time("UserStore.VerifyEmail", time.Now(), s.UserStore.VerifyEmail(userId))
+}
+
+type TimerLayerAuditStore struct {
+	AuditStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerAuditStore) Get(user_id string, limit int) StoreChannel {
+	return s.Root.time("AuditStore.Get", time.Now(), s.AuditStore.Get(user_id, limit))
+}
+
+func (s *TimerLayerAuditStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("AuditStore.PermanentDeleteByUser", time.Now(), s.AuditStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerAuditStore) Save(audit *model.Audit) StoreChannel {
+	return s.Root.time("AuditStore.Save", time.Now(), s.AuditStore.Save(audit))
+}
+
+type TimerLayerComplianceStore struct {
+	ComplianceStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerComplianceStore) ComplianceExport(compliance *model.Compliance) StoreChannel {
+	return s.Root.time("ComplianceStore.ComplianceExport", time.Now(), s.ComplianceStore.ComplianceExport(compliance))
+}
+
+func (s *TimerLayerComplianceStore) ComplianceExportBatch(compliance *model.Compliance, channelId string, afterTime int64, afterPostId string, limit int) StoreChannel {
+	return s.Root.time("ComplianceStore.ComplianceExportBatch", time.Now(), s.ComplianceStore.ComplianceExportBatch(compliance, channelId, afterTime, afterPostId, limit))
+}
+
+func (s *TimerLayerComplianceStore) Get(id string) StoreChannel {
+	return s.Root.time("ComplianceStore.Get", time.Now(), s.ComplianceStore.Get(id))
+}
+
+func (s *TimerLayerComplianceStore) GetAll() StoreChannel {
+	return s.Root.time("ComplianceStore.GetAll", time.Now(), s.ComplianceStore.GetAll())
+}
+
+func (s *TimerLayerComplianceStore) GetChannelIdsForExport(compliance *model.Compliance) StoreChannel {
+	return s.Root.time("ComplianceStore.GetChannelIdsForExport", time.Now(), s.ComplianceStore.GetChannelIdsForExport(compliance))
+}
+
+func (s *TimerLayerComplianceStore) Save(compliance *model.Compliance) StoreChannel {
+	return s.Root.time("ComplianceStore.Save", time.Now(), s.ComplianceStore.Save(compliance))
+}
+
+func (s *TimerLayerComplianceStore) Update(compliance *model.Compliance) StoreChannel {
+	return s.Root.time("ComplianceStore.Update", time.Now(), s.ComplianceStore.Update(compliance))
+}
+
+type TimerLayerSessionStore struct {
+	SessionStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerSessionStore) AnalyticsSessionCount() StoreChannel {
+	return s.Root.time("SessionStore.AnalyticsSessionCount", time.Now(), s.SessionStore.AnalyticsSessionCount())
+}
+
+func (s *TimerLayerSessionStore) Get(sessionIdOrToken string) StoreChannel {
+	return s.Root.time("SessionStore.Get", time.Now(), s.SessionStore.Get(sessionIdOrToken))
+}
+
+func (s *TimerLayerSessionStore) GetSessions(userId string) StoreChannel {
+	return s.Root.time("SessionStore.GetSessions", time.Now(), s.SessionStore.GetSessions(userId))
+}
+
+func (s *TimerLayerSessionStore) GetSessionsWithActiveDeviceIds(userId string) StoreChannel {
+	return s.Root.time("SessionStore.GetSessionsWithActiveDeviceIds", time.Now(), s.SessionStore.GetSessionsWithActiveDeviceIds(userId))
+}
+
+func (s *TimerLayerSessionStore) PermanentDeleteSessionsByUser(teamId string) StoreChannel {
+	return s.Root.time("SessionStore.PermanentDeleteSessionsByUser", time.Now(), s.SessionStore.PermanentDeleteSessionsByUser(teamId))
+}
+
+func (s *TimerLayerSessionStore) Remove(sessionIdOrToken string) StoreChannel {
+	return s.Root.time("SessionStore.Remove", time.Now(), s.SessionStore.Remove(sessionIdOrToken))
+}
+
+func (s *TimerLayerSessionStore) RemoveAllSessions() StoreChannel {
+	return s.Root.time("SessionStore.RemoveAllSessions", time.Now(), s.SessionStore.RemoveAllSessions())
+}
+
+func (s *TimerLayerSessionStore) Save(session *model.Session) StoreChannel {
+	return s.Root.time("SessionStore.Save", time.Now(), s.SessionStore.Save(session))
+}
+
+func (s *TimerLayerSessionStore) UpdateDeviceId(id string, deviceId string, expiresAt int64) StoreChannel {
+	return s.Root.time("SessionStore.UpdateDeviceId", time.Now(), s.SessionStore.UpdateDeviceId(id, deviceId, expiresAt))
+}
+
+func (s *TimerLayerSessionStore) UpdateLastActivityAt(sessionId string, timeParam int64) StoreChannel {
+	return s.Root.time("SessionStore.UpdateLastActivityAt", time.Now(), s.SessionStore.UpdateLastActivityAt(sessionId, timeParam))
+}
+
+func (s *TimerLayerSessionStore) UpdateProps(session *model.Session) StoreChannel {
+	return s.Root.time("SessionStore.UpdateProps", time.Now(), s.SessionStore.UpdateProps(session))
+}
+
+func (s *TimerLayerSessionStore) UpdateRoles(userId string, roles string) StoreChannel {
+	return s.Root.time("SessionStore.UpdateRoles", time.Now(), s.SessionStore.UpdateRoles(userId, roles))
+}
+
+type TimerLayerOAuthStore struct {
+	OAuthStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerOAuthStore) DeleteApp(id string) StoreChannel {
+	return s.Root.time("OAuthStore.DeleteApp", time.Now(), s.OAuthStore.DeleteApp(id))
+}
+
+func (s *TimerLayerOAuthStore) GetAccessData(token string) StoreChannel {
+	return s.Root.time("OAuthStore.GetAccessData", time.Now(), s.OAuthStore.GetAccessData(token))
+}
+
+func (s *TimerLayerOAuthStore) GetAccessDataByRefreshToken(token string) StoreChannel {
+	return s.Root.time("OAuthStore.GetAccessDataByRefreshToken", time.Now(), s.OAuthStore.GetAccessDataByRefreshToken(token))
+}
+
+func (s *TimerLayerOAuthStore) GetAccessDataByUserForApp(userId string, clientId string) StoreChannel {
+	return s.Root.time("OAuthStore.GetAccessDataByUserForApp", time.Now(), s.OAuthStore.GetAccessDataByUserForApp(userId, clientId))
+}
+
+func (s *TimerLayerOAuthStore) GetApp(id string) StoreChannel {
+	return s.Root.time("OAuthStore.GetApp", time.Now(), s.OAuthStore.GetApp(id))
+}
+
+func (s *TimerLayerOAuthStore) GetAppByUser(userId string) StoreChannel {
+	return s.Root.time("OAuthStore.GetAppByUser", time.Now(), s.OAuthStore.GetAppByUser(userId))
+}
+
+func (s *TimerLayerOAuthStore) GetApps() StoreChannel {
+	return s.Root.time("OAuthStore.GetApps", time.Now(), s.OAuthStore.GetApps())
+}
+
+func (s *TimerLayerOAuthStore) GetAuthData(code string) StoreChannel {
+	return s.Root.time("OAuthStore.GetAuthData", time.Now(), s.OAuthStore.GetAuthData(code))
+}
+
+func (s *TimerLayerOAuthStore) GetAuthorizedApps(userId string) StoreChannel {
+	return s.Root.time("OAuthStore.GetAuthorizedApps", time.Now(), s.OAuthStore.GetAuthorizedApps(userId))
+}
+
+func (s *TimerLayerOAuthStore) GetPreviousAccessData(userId string, clientId string) StoreChannel {
+	return s.Root.time("OAuthStore.GetPreviousAccessData", time.Now(), s.OAuthStore.GetPreviousAccessData(userId, clientId))
+}
+
+func (s *TimerLayerOAuthStore) PermanentDeleteAuthDataByUser(userId string) StoreChannel {
+	return s.Root.time("OAuthStore.PermanentDeleteAuthDataByUser", time.Now(), s.OAuthStore.PermanentDeleteAuthDataByUser(userId))
+}
+
+func (s *TimerLayerOAuthStore) RemoveAccessData(token string) StoreChannel {
+	return s.Root.time("OAuthStore.RemoveAccessData", time.Now(), s.OAuthStore.RemoveAccessData(token))
+}
+
+func (s *TimerLayerOAuthStore) RemoveAuthData(code string) StoreChannel {
+	return s.Root.time("OAuthStore.RemoveAuthData", time.Now(), s.OAuthStore.RemoveAuthData(code))
+}
+
+func (s *TimerLayerOAuthStore) SaveAccessData(accessData *model.AccessData) StoreChannel {
+	return s.Root.time("OAuthStore.SaveAccessData", time.Now(), s.OAuthStore.SaveAccessData(accessData))
+}
+
+func (s *TimerLayerOAuthStore) SaveApp(app *model.OAuthApp) StoreChannel {
+	return s.Root.time("OAuthStore.SaveApp", time.Now(), s.OAuthStore.SaveApp(app))
+}
+
+func (s *TimerLayerOAuthStore) SaveAuthData(authData *model.AuthData) StoreChannel {
+	return s.Root.time("OAuthStore.SaveAuthData", time.Now(), s.OAuthStore.SaveAuthData(authData))
+}
+
+func (s *TimerLayerOAuthStore) UpdateAccessData(accessData *model.AccessData) StoreChannel {
+	return s.Root.time("OAuthStore.UpdateAccessData", time.Now(), s.OAuthStore.UpdateAccessData(accessData))
+}
+
+func (s *TimerLayerOAuthStore) UpdateApp(app *model.OAuthApp) StoreChannel {
+	return s.Root.time("OAuthStore.UpdateApp", time.Now(), s.OAuthStore.UpdateApp(app))
+}
+
+type TimerLayerSystemStore struct {
+	SystemStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerSystemStore) Get() StoreChannel {
+	return s.Root.time("SystemStore.Get", time.Now(), s.SystemStore.Get())
+}
+
+func (s *TimerLayerSystemStore) GetByName(name string) StoreChannel {
+	return s.Root.time("SystemStore.GetByName", time.Now(), s.SystemStore.GetByName(name))
+}
+
+func (s *TimerLayerSystemStore) Save(system *model.System) StoreChannel {
+	return s.Root.time("SystemStore.Save", time.Now(), s.SystemStore.Save(system))
+}
+
+func (s *TimerLayerSystemStore) SaveOrUpdate(system *model.System) StoreChannel {
+	return s.Root.time("SystemStore.SaveOrUpdate", time.Now(), s.SystemStore.SaveOrUpdate(system))
+}
+
+func (s *TimerLayerSystemStore) Update(system *model.System) StoreChannel {
+	return s.Root.time("SystemStore.Update", time.Now(), s.SystemStore.Update(system))
+}
+
+type TimerLayerWebhookStore struct {
+	WebhookStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerWebhookStore) AnalyticsIncomingCount(teamId string) StoreChannel {
+	return s.Root.time("WebhookStore.AnalyticsIncomingCount", time.Now(), s.WebhookStore.AnalyticsIncomingCount(teamId))
+}
+
+func (s *TimerLayerWebhookStore) AnalyticsOutgoingCount(teamId string) StoreChannel {
+	return s.Root.time("WebhookStore.AnalyticsOutgoingCount", time.Now(), s.WebhookStore.AnalyticsOutgoingCount(teamId))
+}
+
+func (s *TimerLayerWebhookStore) DeleteIncoming(webhookId string, timeParam int64) StoreChannel {
+	return s.Root.time("WebhookStore.DeleteIncoming", time.Now(), s.WebhookStore.DeleteIncoming(webhookId, timeParam))
+}
+
+func (s *TimerLayerWebhookStore) DeleteOutgoing(webhookId string, timeParam int64) StoreChannel {
+	return s.Root.time("WebhookStore.DeleteOutgoing", time.Now(), s.WebhookStore.DeleteOutgoing(webhookId, timeParam))
+}
+
+func (s *TimerLayerWebhookStore) GetIncoming(id string) StoreChannel {
+	return s.Root.time("WebhookStore.GetIncoming", time.Now(), s.WebhookStore.GetIncoming(id))
+}
+
+func (s *TimerLayerWebhookStore) GetIncomingByChannel(channelId string) StoreChannel {
+	return s.Root.time("WebhookStore.GetIncomingByChannel", time.Now(), s.WebhookStore.GetIncomingByChannel(channelId))
+}
+
+func (s *TimerLayerWebhookStore) GetIncomingByTeam(teamId string) StoreChannel {
+	return s.Root.time("WebhookStore.GetIncomingByTeam", time.Now(), s.WebhookStore.GetIncomingByTeam(teamId))
+}
+
+func (s *TimerLayerWebhookStore) GetOutgoing(id string) StoreChannel {
+	return s.Root.time("WebhookStore.GetOutgoing", time.Now(), s.WebhookStore.GetOutgoing(id))
+}
+
+func (s *TimerLayerWebhookStore) GetOutgoingByChannel(channelId string) StoreChannel {
+	return s.Root.time("WebhookStore.GetOutgoingByChannel", time.Now(), s.WebhookStore.GetOutgoingByChannel(channelId))
+}
+
+func (s *TimerLayerWebhookStore) GetOutgoingByTeam(teamId string) StoreChannel {
+	return s.Root.time("WebhookStore.GetOutgoingByTeam", time.Now(), s.WebhookStore.GetOutgoingByTeam(teamId))
+}
+
+func (s *TimerLayerWebhookStore) PermanentDeleteIncomingByUser(userId string) StoreChannel {
+	return s.Root.time("WebhookStore.PermanentDeleteIncomingByUser", time.Now(), s.WebhookStore.PermanentDeleteIncomingByUser(userId))
+}
+
+func (s *TimerLayerWebhookStore) PermanentDeleteOutgoingByUser(userId string) StoreChannel {
+	return s.Root.time("WebhookStore.PermanentDeleteOutgoingByUser", time.Now(), s.WebhookStore.PermanentDeleteOutgoingByUser(userId))
+}
+
+func (s *TimerLayerWebhookStore) SaveIncoming(webhook *model.IncomingWebhook) StoreChannel {
+	return s.Root.time("WebhookStore.SaveIncoming", time.Now(), s.WebhookStore.SaveIncoming(webhook))
+}
+
+func (s *TimerLayerWebhookStore) SaveOutgoing(webhook *model.OutgoingWebhook) StoreChannel {
+	return s.Root.time("WebhookStore.SaveOutgoing", time.Now(), s.WebhookStore.SaveOutgoing(webhook))
+}
+
+func (s *TimerLayerWebhookStore) UpdateOutgoing(hook *model.OutgoingWebhook) StoreChannel {
+	return s.Root.time("WebhookStore.UpdateOutgoing", time.Now(), s.WebhookStore.UpdateOutgoing(hook))
+}
+
+type TimerLayerCommandStore struct {
+	CommandStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerCommandStore) AnalyticsCommandCount(teamId string) StoreChannel {
+	return s.Root.time("CommandStore.AnalyticsCommandCount", time.Now(), s.CommandStore.AnalyticsCommandCount(teamId))
+}
+
+func (s *TimerLayerCommandStore) Delete(commandId string, timeParam int64) StoreChannel {
+	return s.Root.time("CommandStore.Delete", time.Now(), s.CommandStore.Delete(commandId, timeParam))
+}
+
+func (s *TimerLayerCommandStore) Get(id string) StoreChannel {
+	return s.Root.time("CommandStore.Get", time.Now(), s.CommandStore.Get(id))
+}
+
+func (s *TimerLayerCommandStore) GetByTeam(teamId string) StoreChannel {
+	return s.Root.time("CommandStore.GetByTeam", time.Now(), s.CommandStore.GetByTeam(teamId))
+}
+
+func (s *TimerLayerCommandStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("CommandStore.PermanentDeleteByUser", time.Now(), s.CommandStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerCommandStore) Save(webhook *model.Command) StoreChannel {
+	return s.Root.time("CommandStore.Save", time.Now(), s.CommandStore.Save(webhook))
+}
+
+func (s *TimerLayerCommandStore) Update(hook *model.Command) StoreChannel {
+	return s.Root.time("CommandStore.Update", time.Now(), s.CommandStore.Update(hook))
+}
+
+type TimerLayerPreferenceStore struct {
+	PreferenceStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerPreferenceStore) Delete(userId string, category string, name string) StoreChannel {
+	return s.Root.time("PreferenceStore.Delete", time.Now(), s.PreferenceStore.Delete(userId, category, name))
+}
+
+func (s *TimerLayerPreferenceStore) DeleteCategory(userId string, category string) StoreChannel {
+	return s.Root.time("PreferenceStore.DeleteCategory", time.Now(), s.PreferenceStore.DeleteCategory(userId, category))
+}
+
+func (s *TimerLayerPreferenceStore) Get(userId string, category string, name string) StoreChannel {
+	return s.Root.time("PreferenceStore.Get", time.Now(), s.PreferenceStore.Get(userId, category, name))
+}
+
+func (s *TimerLayerPreferenceStore) GetAll(userId string) StoreChannel {
+	return s.Root.time("PreferenceStore.GetAll", time.Now(), s.PreferenceStore.GetAll(userId))
+}
+
+func (s *TimerLayerPreferenceStore) GetCategory(userId string, category string) StoreChannel {
+	return s.Root.time("PreferenceStore.GetCategory", time.Now(), s.PreferenceStore.GetCategory(userId, category))
+}
+
+func (s *TimerLayerPreferenceStore) IsFeatureEnabled(feature string, userId string) StoreChannel {
+	return s.Root.time("PreferenceStore.IsFeatureEnabled", time.Now(), s.PreferenceStore.IsFeatureEnabled(feature, userId))
+}
+
+func (s *TimerLayerPreferenceStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("PreferenceStore.PermanentDeleteByUser", time.Now(), s.PreferenceStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerPreferenceStore) Save(preferences *model.Preferences) StoreChannel {
+	return s.Root.time("PreferenceStore.Save", time.Now(), s.PreferenceStore.Save(preferences))
+}
+
+type TimerLayerLicenseStore struct {
+	LicenseStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerLicenseStore) Get(id string) StoreChannel {
+	return s.Root.time("LicenseStore.Get", time.Now(), s.LicenseStore.Get(id))
+}
+
+func (s *TimerLayerLicenseStore) Save(license *model.LicenseRecord) StoreChannel {
+	return s.Root.time("LicenseStore.Save", time.Now(), s.LicenseStore.Save(license))
+}
+
+type TimerLayerPasswordRecoveryStore struct {
+	PasswordRecoveryStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerPasswordRecoveryStore) Delete(userId string) StoreChannel {
+	return s.Root.time("PasswordRecoveryStore.Delete", time.Now(), s.PasswordRecoveryStore.Delete(userId))
+}
+
+func (s *TimerLayerPasswordRecoveryStore) Get(userId string) StoreChannel {
+	return s.Root.time("PasswordRecoveryStore.Get", time.Now(), s.PasswordRecoveryStore.Get(userId))
+}
+
+func (s *TimerLayerPasswordRecoveryStore) GetByCode(code string) StoreChannel {
+	return s.Root.time("PasswordRecoveryStore.GetByCode", time.Now(), s.PasswordRecoveryStore.GetByCode(code))
+}
+
+func (s *TimerLayerPasswordRecoveryStore) SaveOrUpdate(recovery *model.PasswordRecovery) StoreChannel {
+	return s.Root.time("PasswordRecoveryStore.SaveOrUpdate", time.Now(), s.PasswordRecoveryStore.SaveOrUpdate(recovery))
+}
+
+type TimerLayerEmojiStore struct {
+	EmojiStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerEmojiStore) Delete(id string, timeParam int64) StoreChannel {
+	return s.Root.time("EmojiStore.Delete", time.Now(), s.EmojiStore.Delete(id, timeParam))
+}
+
+func (s *TimerLayerEmojiStore) Get(id string) StoreChannel {
+	return s.Root.time("EmojiStore.Get", time.Now(), s.EmojiStore.Get(id))
+}
+
+func (s *TimerLayerEmojiStore) GetAll() StoreChannel {
+	return s.Root.time("EmojiStore.GetAll", time.Now(), s.EmojiStore.GetAll())
+}
+
+func (s *TimerLayerEmojiStore) GetByName(name string) StoreChannel {
+	return s.Root.time("EmojiStore.GetByName", time.Now(), s.EmojiStore.GetByName(name))
+}
+
+func (s *TimerLayerEmojiStore) Save(emoji *model.Emoji) StoreChannel {
+	return s.Root.time("EmojiStore.Save", time.Now(), s.EmojiStore.Save(emoji))
+}
+
+type TimerLayerStatusStore struct {
+	StatusStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerStatusStore) Get(userId string) StoreChannel {
+	return s.Root.time("StatusStore.Get", time.Now(), s.StatusStore.Get(userId))
+}
+
+func (s *TimerLayerStatusStore) GetAllFromTeam(teamId string) StoreChannel {
+	return s.Root.time("StatusStore.GetAllFromTeam", time.Now(), s.StatusStore.GetAllFromTeam(teamId))
+}
+
+func (s *TimerLayerStatusStore) GetByIds(userIds []string) StoreChannel {
+	return s.Root.time("StatusStore.GetByIds", time.Now(), s.StatusStore.GetByIds(userIds))
+}
+
+func (s *TimerLayerStatusStore) GetOnline() StoreChannel {
+	return s.Root.time("StatusStore.GetOnline", time.Now(), s.StatusStore.GetOnline())
+}
+
+func (s *TimerLayerStatusStore) GetOnlineAway() StoreChannel {
+	return s.Root.time("StatusStore.GetOnlineAway", time.Now(), s.StatusStore.GetOnlineAway())
+}
+
+func (s *TimerLayerStatusStore) GetTotalActiveUsersCount() StoreChannel {
+	return s.Root.time("StatusStore.GetTotalActiveUsersCount", time.Now(), s.StatusStore.GetTotalActiveUsersCount())
+}
+
+func (s *TimerLayerStatusStore) ResetAll() StoreChannel {
+	return s.Root.time("StatusStore.ResetAll", time.Now(), s.StatusStore.ResetAll())
+}
+
+func (s *TimerLayerStatusStore) SaveOrUpdate(status *model.Status) StoreChannel {
+	return s.Root.time("StatusStore.SaveOrUpdate", time.Now(), s.StatusStore.SaveOrUpdate(status))
+}
+
+func (s *TimerLayerStatusStore) UpdateLastActivityAt(userId string, lastActivityAt int64) StoreChannel {
+	return s.Root.time("StatusStore.UpdateLastActivityAt", time.Now(), s.StatusStore.UpdateLastActivityAt(userId, lastActivityAt))
+}
+
+type TimerLayerFileInfoStore struct {
+	FileInfoStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerFileInfoStore) AttachToPost(fileId string, postId string) StoreChannel {
+	return s.Root.time("FileInfoStore.AttachToPost", time.Now(), s.FileInfoStore.AttachToPost(fileId, postId))
+}
+
+func (s *TimerLayerFileInfoStore) CountHeldForRetention(endTime int64, teamId string, excludeTeamIds []string, hold *model.LegalHold) StoreChannel {
+	return s.Root.time("FileInfoStore.CountHeldForRetention", time.Now(), s.FileInfoStore.CountHeldForRetention(endTime, teamId, excludeTeamIds, hold))
+}
+
+func (s *TimerLayerFileInfoStore) DeleteForPost(postId string) StoreChannel {
+	return s.Root.time("FileInfoStore.DeleteForPost", time.Now(), s.FileInfoStore.DeleteForPost(postId))
+}
+
+func (s *TimerLayerFileInfoStore) Get(id string) StoreChannel {
+	return s.Root.time("FileInfoStore.Get", time.Now(), s.FileInfoStore.Get(id))
+}
+
+func (s *TimerLayerFileInfoStore) GetByPath(path string) StoreChannel {
+	return s.Root.time("FileInfoStore.GetByPath", time.Now(), s.FileInfoStore.GetByPath(path))
+}
+
+func (s *TimerLayerFileInfoStore) GetForPost(postId string) StoreChannel {
+	return s.Root.time("FileInfoStore.GetForPost", time.Now(), s.FileInfoStore.GetForPost(postId))
+}
+
+func (s *TimerLayerFileInfoStore) GetForPostIds(postIds []string) StoreChannel {
+	return s.Root.time("FileInfoStore.GetForPostIds", time.Now(), s.FileInfoStore.GetForPostIds(postIds))
+}
+
+func (s *TimerLayerFileInfoStore) GetForRetention(endTime int64, teamId string, excludeTeamIds []string, legalHolds []*model.LegalHold, limit int) StoreChannel {
+	return s.Root.time("FileInfoStore.GetForRetention", time.Now(), s.FileInfoStore.GetForRetention(endTime, teamId, excludeTeamIds, legalHolds, limit))
+}
+
+func (s *TimerLayerFileInfoStore) PermanentDeleteByIds(fileIds []string) StoreChannel {
+	return s.Root.time("FileInfoStore.PermanentDeleteByIds", time.Now(), s.FileInfoStore.PermanentDeleteByIds(fileIds))
+}
+
+func (s *TimerLayerFileInfoStore) Save(info *model.FileInfo) StoreChannel {
+	return s.Root.time("FileInfoStore.Save", time.Now(), s.FileInfoStore.Save(info))
+}
+
+func (s *TimerLayerFileInfoStore) Search(teamId string, userId string, params *model.SearchParams) StoreChannel {
+	return s.Root.time("FileInfoStore.Search", time.Now(), s.FileInfoStore.Search(teamId, userId, params))
+}
+
+type TimerLayerReactionStore struct {
+	ReactionStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerReactionStore) Delete(reaction *model.Reaction) StoreChannel {
+	return s.Root.time("ReactionStore.Delete", time.Now(), s.ReactionStore.Delete(reaction))
+}
+
+func (s *TimerLayerReactionStore) DeleteAllWithEmojiName(emojiName string) StoreChannel {
+	return s.Root.time("ReactionStore.DeleteAllWithEmojiName", time.Now(), s.ReactionStore.DeleteAllWithEmojiName(emojiName))
+}
+
+func (s *TimerLayerReactionStore) GetForPost(postId string) StoreChannel {
+	return s.Root.time("ReactionStore.GetForPost", time.Now(), s.ReactionStore.GetForPost(postId))
+}
+
+func (s *TimerLayerReactionStore) PermanentDeleteByPostIds(postIds []string) StoreChannel {
+	return s.Root.time("ReactionStore.PermanentDeleteByPostIds", time.Now(), s.ReactionStore.PermanentDeleteByPostIds(postIds))
+}
+
+func (s *TimerLayerReactionStore) Save(reaction *model.Reaction) StoreChannel {
+	return s.Root.time("ReactionStore.Save", time.Now(), s.ReactionStore.Save(reaction))
+}
+
+type TimerLayerUploadSessionStore struct {
+	UploadSessionStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerUploadSessionStore) Claim(id string, timeParam int64) StoreChannel {
+	return s.Root.time("UploadSessionStore.Claim", time.Now(), s.UploadSessionStore.Claim(id, timeParam))
+}
+
+func (s *TimerLayerUploadSessionStore) Delete(id string) StoreChannel {
+	return s.Root.time("UploadSessionStore.Delete", time.Now(), s.UploadSessionStore.Delete(id))
+}
+
+func (s *TimerLayerUploadSessionStore) Get(id string) StoreChannel {
+	return s.Root.time("UploadSessionStore.Get", time.Now(), s.UploadSessionStore.Get(id))
+}
+
+func (s *TimerLayerUploadSessionStore) GetForUser(userId string) StoreChannel {
+	return s.Root.time("UploadSessionStore.GetForUser", time.Now(), s.UploadSessionStore.GetForUser(userId))
+}
+
+func (s *TimerLayerUploadSessionStore) GetStale(updatedBefore int64, limit int) StoreChannel {
+	return s.Root.time("UploadSessionStore.GetStale", time.Now(), s.UploadSessionStore.GetStale(updatedBefore, limit))
+}
+
+func (s *TimerLayerUploadSessionStore) ReleaseClaim(id string, claimedAt int64) StoreChannel {
+	return s.Root.time("UploadSessionStore.ReleaseClaim", time.Now(), s.UploadSessionStore.ReleaseClaim(id, claimedAt))
+}
+
+func (s *TimerLayerUploadSessionStore) Save(session *model.UploadSession) StoreChannel {
+	return s.Root.time("UploadSessionStore.Save", time.Now(), s.UploadSessionStore.Save(session))
+}
+
+func (s *TimerLayerUploadSessionStore) UpdateFileOffset(id string, oldOffset int64, newOffset int64) StoreChannel {
+	return s.Root.time("UploadSessionStore.UpdateFileOffset", time.Now(), s.UploadSessionStore.UpdateFileOffset(id, oldOffset, newOffset))
+}
+
+type TimerLayerThreadStore struct {
+	ThreadStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerThreadStore) Delete(postId string) StoreChannel {
+	return s.Root.time("ThreadStore.Delete", time.Now(), s.ThreadStore.Delete(postId))
+}
+
+func (s *TimerLayerThreadStore) Get(postId string) StoreChannel {
+	return s.Root.time("ThreadStore.Get", time.Now(), s.ThreadStore.Get(postId))
+}
+
+func (s *TimerLayerThreadStore) GetFollowers(postId string) StoreChannel {
+	return s.Root.time("ThreadStore.GetFollowers", time.Now(), s.ThreadStore.GetFollowers(postId))
+}
+
+func (s *TimerLayerThreadStore) GetMembership(postId string, userId string) StoreChannel {
+	return s.Root.time("ThreadStore.GetMembership", time.Now(), s.ThreadStore.GetMembership(postId, userId))
+}
+
+func (s *TimerLayerThreadStore) GetThreadForUser(teamId string, userId string, postId string) StoreChannel {
+	return s.Root.time("ThreadStore.GetThreadForUser", time.Now(), s.ThreadStore.GetThreadForUser(teamId, userId, postId))
+}
+
+func (s *TimerLayerThreadStore) GetThreadsForUser(teamId string, userId string, offset int, limit int) StoreChannel {
+	return s.Root.time("ThreadStore.GetThreadsForUser", time.Now(), s.ThreadStore.GetThreadsForUser(teamId, userId, offset, limit))
+}
+
+func (s *TimerLayerThreadStore) MarkAllAsRead(teamId string, userId string, timestamp int64) StoreChannel {
+	return s.Root.time("ThreadStore.MarkAllAsRead", time.Now(), s.ThreadStore.MarkAllAsRead(teamId, userId, timestamp))
+}
+
+func (s *TimerLayerThreadStore) PermanentDeleteByPostIds(postIds []string) StoreChannel {
+	return s.Root.time("ThreadStore.PermanentDeleteByPostIds", time.Now(), s.ThreadStore.PermanentDeleteByPostIds(postIds))
+}
+
+func (s *TimerLayerThreadStore) SaveMembership(membership *model.ThreadMembership) StoreChannel {
+	return s.Root.time("ThreadStore.SaveMembership", time.Now(), s.ThreadStore.SaveMembership(membership))
+}
+
+func (s *TimerLayerThreadStore) UpdateFromReplies(postId string, channelId string) StoreChannel {
+	return s.Root.time("ThreadStore.UpdateFromReplies", time.Now(), s.ThreadStore.UpdateFromReplies(postId, channelId))
+}
+
+type TimerLayerScheduledPostStore struct {
+	ScheduledPostStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerScheduledPostStore) Claim(id string, timeParam int64, expiredBefore int64) StoreChannel {
+	return s.Root.time("ScheduledPostStore.Claim", time.Now(), s.ScheduledPostStore.Claim(id, timeParam, expiredBefore))
+}
+
+func (s *TimerLayerScheduledPostStore) Delete(id string) StoreChannel {
+	return s.Root.time("ScheduledPostStore.Delete", time.Now(), s.ScheduledPostStore.Delete(id))
+}
+
+func (s *TimerLayerScheduledPostStore) Get(id string) StoreChannel {
+	return s.Root.time("ScheduledPostStore.Get", time.Now(), s.ScheduledPostStore.Get(id))
+}
+
+func (s *TimerLayerScheduledPostStore) GetDue(timeParam int64, expiredBefore int64, limit int) StoreChannel {
+	return s.Root.time("ScheduledPostStore.GetDue", time.Now(), s.ScheduledPostStore.GetDue(timeParam, expiredBefore, limit))
+}
+
+func (s *TimerLayerScheduledPostStore) GetForUser(teamId string, userId string) StoreChannel {
+	return s.Root.time("ScheduledPostStore.GetForUser", time.Now(), s.ScheduledPostStore.GetForUser(teamId, userId))
+}
+
+func (s *TimerLayerScheduledPostStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("ScheduledPostStore.PermanentDeleteByUser", time.Now(), s.ScheduledPostStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerScheduledPostStore) Save(post *model.ScheduledPost) StoreChannel {
+	return s.Root.time("ScheduledPostStore.Save", time.Now(), s.ScheduledPostStore.Save(post))
+}
+
+func (s *TimerLayerScheduledPostStore) Update(post *model.ScheduledPost, oldProcessedAt int64) StoreChannel {
+	return s.Root.time("ScheduledPostStore.Update", time.Now(), s.ScheduledPostStore.Update(post, oldProcessedAt))
+}
+
+type TimerLayerOutgoingWebhookDeliveryStore struct {
+	OutgoingWebhookDeliveryStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) Claim(id string, nextAttemptAt int64, leaseUntil int64) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.Claim", time.Now(), s.OutgoingWebhookDeliveryStore.Claim(id, nextAttemptAt, leaseUntil))
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) Get(id string) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.Get", time.Now(), s.OutgoingWebhookDeliveryStore.Get(id))
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) GetByHook(hookId string, offset int, limit int) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.GetByHook", time.Now(), s.OutgoingWebhookDeliveryStore.GetByHook(hookId, offset, limit))
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) GetDue(timeParam int64, limit int) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.GetDue", time.Now(), s.OutgoingWebhookDeliveryStore.GetDue(timeParam, limit))
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) PermanentDeleteBefore(timeParam int64) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.PermanentDeleteBefore", time.Now(), s.OutgoingWebhookDeliveryStore.PermanentDeleteBefore(timeParam))
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) PermanentDeleteByPostIds(postIds []string) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.PermanentDeleteByPostIds", time.Now(), s.OutgoingWebhookDeliveryStore.PermanentDeleteByPostIds(postIds))
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) Save(delivery *model.OutgoingWebhookDelivery) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.Save", time.Now(), s.OutgoingWebhookDeliveryStore.Save(delivery))
+}
+
+func (s *TimerLayerOutgoingWebhookDeliveryStore) Update(delivery *model.OutgoingWebhookDelivery) StoreChannel {
+	return s.Root.time("OutgoingWebhookDeliveryStore.Update", time.Now(), s.OutgoingWebhookDeliveryStore.Update(delivery))
+}
+
+type TimerLayerDraftStore struct {
+	DraftStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerDraftStore) Delete(userId string, channelId string, rootId string) StoreChannel {
+	return s.Root.time("DraftStore.Delete", time.Now(), s.DraftStore.Delete(userId, channelId, rootId))
+}
+
+func (s *TimerLayerDraftStore) Get(userId string, channelId string, rootId string) StoreChannel {
+	return s.Root.time("DraftStore.Get", time.Now(), s.DraftStore.Get(userId, channelId, rootId))
+}
+
+func (s *TimerLayerDraftStore) GetForUser(teamId string, userId string) StoreChannel {
+	return s.Root.time("DraftStore.GetForUser", time.Now(), s.DraftStore.GetForUser(teamId, userId))
+}
+
+func (s *TimerLayerDraftStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("DraftStore.PermanentDeleteByUser", time.Now(), s.DraftStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerDraftStore) Save(draft *model.Draft) StoreChannel {
+	return s.Root.time("DraftStore.Save", time.Now(), s.DraftStore.Save(draft))
+}
+
+type TimerLayerLegalHoldStore struct {
+	LegalHoldStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerLegalHoldStore) Delete(id string) StoreChannel {
+	return s.Root.time("LegalHoldStore.Delete", time.Now(), s.LegalHoldStore.Delete(id))
+}
+
+func (s *TimerLayerLegalHoldStore) Get(id string) StoreChannel {
+	return s.Root.time("LegalHoldStore.Get", time.Now(), s.LegalHoldStore.Get(id))
+}
+
+func (s *TimerLayerLegalHoldStore) GetAll() StoreChannel {
+	return s.Root.time("LegalHoldStore.GetAll", time.Now(), s.LegalHoldStore.GetAll())
+}
+
+func (s *TimerLayerLegalHoldStore) Save(hold *model.LegalHold) StoreChannel {
+	return s.Root.time("LegalHoldStore.Save", time.Now(), s.LegalHoldStore.Save(hold))
+}
+
+func (s *TimerLayerLegalHoldStore) Update(hold *model.LegalHold) StoreChannel {
+	return s.Root.time("LegalHoldStore.Update", time.Now(), s.LegalHoldStore.Update(hold))
+}
+
+type TimerLayerClusterDiscoveryStore struct {
+	ClusterDiscoveryStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerClusterDiscoveryStore) Cleanup(before int64) StoreChannel {
+	return s.Root.time("ClusterDiscoveryStore.Cleanup", time.Now(), s.ClusterDiscoveryStore.Cleanup(before))
+}
+
+func (s *TimerLayerClusterDiscoveryStore) Delete(id string) StoreChannel {
+	return s.Root.time("ClusterDiscoveryStore.Delete", time.Now(), s.ClusterDiscoveryStore.Delete(id))
+}
+
+func (s *TimerLayerClusterDiscoveryStore) GetAll(aliveSince int64) StoreChannel {
+	return s.Root.time("ClusterDiscoveryStore.GetAll", time.Now(), s.ClusterDiscoveryStore.GetAll(aliveSince))
+}
+
+func (s *TimerLayerClusterDiscoveryStore) SaveOrUpdate(node *model.ClusterDiscovery) StoreChannel {
+	return s.Root.time("ClusterDiscoveryStore.SaveOrUpdate", time.Now(), s.ClusterDiscoveryStore.SaveOrUpdate(node))
+}
+
+type TimerLayerMfaRecoveryCodeStore struct {
+	MfaRecoveryCodeStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerMfaRecoveryCodeStore) Count(userId string) StoreChannel {
+	return s.Root.time("MfaRecoveryCodeStore.Count", time.Now(), s.MfaRecoveryCodeStore.Count(userId))
+}
+
+func (s *TimerLayerMfaRecoveryCodeStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("MfaRecoveryCodeStore.PermanentDeleteByUser", time.Now(), s.MfaRecoveryCodeStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerMfaRecoveryCodeStore) Save(userId string, hashes []string) StoreChannel {
+	return s.Root.time("MfaRecoveryCodeStore.Save", time.Now(), s.MfaRecoveryCodeStore.Save(userId, hashes))
+}
+
+func (s *TimerLayerMfaRecoveryCodeStore) Use(userId string, hash string) StoreChannel {
+	return s.Root.time("MfaRecoveryCodeStore.Use", time.Now(), s.MfaRecoveryCodeStore.Use(userId, hash))
+}
+
+type TimerLayerPersonalAccessTokenStore struct {
+	PersonalAccessTokenStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerPersonalAccessTokenStore) Delete(id string) StoreChannel {
+	return s.Root.time("PersonalAccessTokenStore.Delete", time.Now(), s.PersonalAccessTokenStore.Delete(id))
+}
+
+func (s *TimerLayerPersonalAccessTokenStore) Get(id string) StoreChannel {
+	return s.Root.time("PersonalAccessTokenStore.Get", time.Now(), s.PersonalAccessTokenStore.Get(id))
+}
+
+func (s *TimerLayerPersonalAccessTokenStore) GetByTokenHash(hash string) StoreChannel {
+	return s.Root.time("PersonalAccessTokenStore.GetByTokenHash", time.Now(), s.PersonalAccessTokenStore.GetByTokenHash(hash))
+}
+
+func (s *TimerLayerPersonalAccessTokenStore) GetByUser(userId string) StoreChannel {
+	return s.Root.time("PersonalAccessTokenStore.GetByUser", time.Now(), s.PersonalAccessTokenStore.GetByUser(userId))
+}
+
+func (s *TimerLayerPersonalAccessTokenStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("PersonalAccessTokenStore.PermanentDeleteByUser", time.Now(), s.PersonalAccessTokenStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerPersonalAccessTokenStore) Save(token *model.PersonalAccessToken) StoreChannel {
+	return s.Root.time("PersonalAccessTokenStore.Save", time.Now(), s.PersonalAccessTokenStore.Save(token))
+}
+
+type TimerLayerBotStore struct {
+	BotStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerBotStore) Get(userId string) StoreChannel {
+	return s.Root.time("BotStore.Get", time.Now(), s.BotStore.Get(userId))
+}
+
+func (s *TimerLayerBotStore) GetAll() StoreChannel {
+	return s.Root.time("BotStore.GetAll", time.Now(), s.BotStore.GetAll())
+}
+
+func (s *TimerLayerBotStore) GetByOwner(ownerId string) StoreChannel {
+	return s.Root.time("BotStore.GetByOwner", time.Now(), s.BotStore.GetByOwner(ownerId))
+}
+
+func (s *TimerLayerBotStore) PermanentDelete(userId string) StoreChannel {
+	return s.Root.time("BotStore.PermanentDelete", time.Now(), s.BotStore.PermanentDelete(userId))
+}
+
+func (s *TimerLayerBotStore) Save(bot *model.Bot) StoreChannel {
+	return s.Root.time("BotStore.Save", time.Now(), s.BotStore.Save(bot))
+}
+
+func (s *TimerLayerBotStore) Update(bot *model.Bot) StoreChannel {
+	return s.Root.time("BotStore.Update", time.Now(), s.BotStore.Update(bot))
+}
+
+type TimerLayerEventSubscriptionStore struct {
+	EventSubscriptionStore
+	Root *TimerLayer
+}
+
+func (s *TimerLayerEventSubscriptionStore) Delete(id string, timeParam int64) StoreChannel {
+	return s.Root.time("EventSubscriptionStore.Delete", time.Now(), s.EventSubscriptionStore.Delete(id, timeParam))
+}
+
+func (s *TimerLayerEventSubscriptionStore) Get(id string) StoreChannel {
+	return s.Root.time("EventSubscriptionStore.Get", time.Now(), s.EventSubscriptionStore.Get(id))
+}
+
+func (s *TimerLayerEventSubscriptionStore) GetByTeam(teamId string) StoreChannel {
+	return s.Root.time("EventSubscriptionStore.GetByTeam", time.Now(), s.EventSubscriptionStore.GetByTeam(teamId))
+}
+
+func (s *TimerLayerEventSubscriptionStore) PermanentDeleteByUser(userId string) StoreChannel {
+	return s.Root.time("EventSubscriptionStore.PermanentDeleteByUser", time.Now(), s.EventSubscriptionStore.PermanentDeleteByUser(userId))
+}
+
+func (s *TimerLayerEventSubscriptionStore) Save(subscription *model.EventSubscription) StoreChannel {
+	return s.Root.time("EventSubscriptionStore.Save", time.Now(), s.EventSubscriptionStore.Save(subscription))
+}
+
+func (s *TimerLayerEventSubscriptionStore) Update(subscription *model.EventSubscription) StoreChannel {
+	return s.Root.time("EventSubscriptionStore.Update", time.Now(), s.EventSubscriptionStore.Update(subscription))
+}
+
+func NewTimerLayer(childStore Store) *TimerLayer {
+	newStore := &TimerLayer{
+		Store: childStore,
+	}
+
+	newStore.TeamStore = TimerLayerTeamStore{TeamStore: childStore.Team(), Root: newStore}
+	newStore.ChannelStore = TimerLayerChannelStore{ChannelStore: childStore.Channel(), Root: newStore}
+	newStore.PostStore = TimerLayerPostStore{PostStore: childStore.Post(), Root: newStore}
+	newStore.UserStore = TimerLayerUserStore{UserStore: childStore.User(), Root: newStore}
+	newStore.AuditStore = TimerLayerAuditStore{AuditStore: childStore.Audit(), Root: newStore}
+	newStore.ComplianceStore = TimerLayerComplianceStore{ComplianceStore: childStore.Compliance(), Root: newStore}
+	newStore.SessionStore = TimerLayerSessionStore{SessionStore: childStore.Session(), Root: newStore}
+	newStore.OAuthStore = TimerLayerOAuthStore{OAuthStore: childStore.OAuth(), Root: newStore}
+	newStore.SystemStore = TimerLayerSystemStore{SystemStore: childStore.System(), Root: newStore}
+	newStore.WebhookStore = TimerLayerWebhookStore{WebhookStore: childStore.Webhook(), Root: newStore}
+	newStore.CommandStore = TimerLayerCommandStore{CommandStore: childStore.Command(), Root: newStore}
+	newStore.PreferenceStore = TimerLayerPreferenceStore{PreferenceStore: childStore.Preference(), Root: newStore}
+	newStore.LicenseStore = TimerLayerLicenseStore{LicenseStore: childStore.License(), Root: newStore}
+	newStore.PasswordRecoveryStore = TimerLayerPasswordRecoveryStore{PasswordRecoveryStore: childStore.PasswordRecovery(), Root: newStore}
+	newStore.EmojiStore = TimerLayerEmojiStore{EmojiStore: childStore.Emoji(), Root: newStore}
+	newStore.StatusStore = TimerLayerStatusStore{StatusStore: childStore.Status(), Root: newStore}
+	newStore.FileInfoStore = TimerLayerFileInfoStore{FileInfoStore: childStore.FileInfo(), Root: newStore}
+	newStore.ReactionStore = TimerLayerReactionStore{ReactionStore: childStore.Reaction(), Root: newStore}
+	newStore.UploadSessionStore = TimerLayerUploadSessionStore{UploadSessionStore: childStore.UploadSession(), Root: newStore}
+	newStore.ThreadStore = TimerLayerThreadStore{ThreadStore: childStore.Thread(), Root: newStore}
+	newStore.ScheduledPostStore = TimerLayerScheduledPostStore{ScheduledPostStore: childStore.ScheduledPost(), Root: newStore}
+	newStore.OutgoingWebhookDeliveryStore = TimerLayerOutgoingWebhookDeliveryStore{OutgoingWebhookDeliveryStore: childStore.OutgoingWebhookDelivery(), Root: newStore}
+	newStore.DraftStore = TimerLayerDraftStore{DraftStore: childStore.Draft(), Root: newStore}
+	newStore.LegalHoldStore = TimerLayerLegalHoldStore{LegalHoldStore: childStore.LegalHold(), Root: newStore}
+	newStore.ClusterDiscoveryStore = TimerLayerClusterDiscoveryStore{ClusterDiscoveryStore: childStore.ClusterDiscovery(), Root: newStore}
+	newStore.MfaRecoveryCodeStore = TimerLayerMfaRecoveryCodeStore{MfaRecoveryCodeStore: childStore.MfaRecoveryCode(), Root: newStore}
+	newStore.PersonalAccessTokenStore = TimerLayerPersonalAccessTokenStore{PersonalAccessTokenStore: childStore.PersonalAccessToken(), Root: newStore}
+	newStore.BotStore = TimerLayerBotStore{BotStore: childStore.Bot(), Root: newStore}
+	newStore.EventSubscriptionStore = TimerLayerEventSubscriptionStore{EventSubscriptionStore: childStore.EventSubscription(), Root: newStore}
+
+	return newStore
+}
@@ -132,6 +132,7 @@ func SetDefaultRolesBasedOnConfig() {
 		model.ROLE_SYSTEM_USER.Permissions = append(
 			model.ROLE_SYSTEM_USER.Permissions,
 			model.PERMISSION_MANAGE_OAUTH.Id,
+			model.PERMISSION_MANAGE_BOTS.Id,
 		)
 	}
 
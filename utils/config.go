@@ -4,6 +4,7 @@
 package utils
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
@@ -32,6 +33,7 @@ var CfgHash = ""
 var CfgFileName string = ""
 var ClientCfg map[string]string = map[string]string{}
 var originalDisableDebugLvl l4g.Level = l4g.DEBUG
+var testConfigFileName = ""
 var siteURL = ""
 
 func GetSiteURL() string {
@@ -78,6 +80,48 @@ func EnableDebugLogForTest() {
 	}
 }
 
+// LoadTestConfig loads a copy of the config file that's kept in a temporary directory along with the log file. Tests
+// use it so that they don't fill in the generated settings of the config file in the source tree or leave logs there.
+func LoadTestConfig() {
+	if len(testConfigFileName) == 0 {
+		testConfigFileName = createTestConfig()
+	}
+
+	LoadConfig(testConfigFileName)
+}
+
+func createTestConfig() string {
+	var data []byte
+	var err error
+	for _, dir := range []string{"config", "../config", "../../config"} {
+		if data, err = ioutil.ReadFile(filepath.Join(dir, "config.json")); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		panic("unable to find the config file: " + err.Error())
+	}
+
+	config := model.ConfigFromJson(bytes.NewReader(data))
+	if config == nil {
+		panic("unable to decode the config file")
+	}
+
+	dir, err := ioutil.TempDir("", "mattermost-test")
+	if err != nil {
+		panic("unable to create a directory for the test config: " + err.Error())
+	}
+
+	config.LogSettings.FileLocation = filepath.Join(dir, "mattermost.log")
+
+	fileName := filepath.Join(dir, "config.json")
+	if err := SaveConfig(fileName, config); err != nil {
+		panic(err.Error())
+	}
+
+	return fileName
+}
+
 func ConfigureCmdLineLog() {
 	ls := model.LogSettings{}
 	ls.EnableConsole = true
@@ -182,7 +226,8 @@ func LoadConfig(fileName string) {
 	}
 
 	needSave := len(config.SqlSettings.AtRestEncryptKey) == 0 || len(*config.FileSettings.PublicLinkSalt) == 0 ||
-		len(config.EmailSettings.InviteSalt) == 0 || len(config.EmailSettings.PasswordResetSalt) == 0
+		len(config.EmailSettings.InviteSalt) == 0 || len(config.EmailSettings.PasswordResetSalt) == 0 ||
+		config.ServiceSettings.PostActionSigningSecret == nil || len(*config.ServiceSettings.PostActionSigningSecret) == 0
 
 	config.SetDefaults()
 
@@ -266,6 +311,12 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["EnablePostIconOverride"] = strconv.FormatBool(c.ServiceSettings.EnablePostIconOverride)
 	props["EnableTesting"] = strconv.FormatBool(c.ServiceSettings.EnableTesting)
 	props["EnableDeveloper"] = strconv.FormatBool(*c.ServiceSettings.EnableDeveloper)
+	props["EnableMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnableMultifactorAuthentication)
+	props["EnforceMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnforceMultifactorAuthentication)
+	props["EnforceMultifactorAuthenticationForAdmins"] = strconv.FormatBool(*c.ServiceSettings.EnforceMultifactorAuthenticationForAdmins)
+	props["EnablePersonalAccessTokens"] = strconv.FormatBool(*c.ServiceSettings.EnablePersonalAccessTokens)
+	props["EnableBotAccounts"] = strconv.FormatBool(*c.ServiceSettings.EnableBotAccounts)
+	props["EnableEventSubscriptions"] = strconv.FormatBool(*c.ServiceSettings.EnableEventSubscriptions)
 	props["EnableDiagnostics"] = strconv.FormatBool(*c.LogSettings.EnableDiagnostics)
 
 	props["SendEmailNotifications"] = strconv.FormatBool(c.EmailSettings.SendEmailNotifications)
@@ -277,6 +328,7 @@ func getClientConfig(c *model.Config) map[string]string {
 	props["EnableEmailBatching"] = strconv.FormatBool(*c.EmailSettings.EnableEmailBatching)
 
 	props["EnableSignUpWithGitLab"] = strconv.FormatBool(c.GitLabSettings.Enable)
+	props["EnableSignUpWithOpenId"] = strconv.FormatBool(c.OpenIdSettings.Enable)
 
 	props["ShowEmailAddress"] = strconv.FormatBool(c.PrivacySettings.ShowEmailAddress)
 
@@ -308,6 +360,8 @@ func getClientConfig(c *model.Config) map[string]string {
 
 	props["EnableWebrtc"] = strconv.FormatBool(*c.WebrtcSettings.Enable)
 
+	props["EnableCompliance"] = strconv.FormatBool(*c.ComplianceSettings.Enable)
+
 	if IsLicensed {
 		if *License.Features.CustomBrand {
 			props["EnableCustomBrand"] = strconv.FormatBool(*c.TeamSettings.EnableCustomBrand)
@@ -323,15 +377,6 @@ func getClientConfig(c *model.Config) map[string]string {
 			props["LastNameAttributeSet"] = strconv.FormatBool(*c.LdapSettings.LastNameAttribute != "")
 		}
 
-		if *License.Features.MFA {
-			props["EnableMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnableMultifactorAuthentication)
-			props["EnforceMultifactorAuthentication"] = strconv.FormatBool(*c.ServiceSettings.EnforceMultifactorAuthentication)
-		}
-
-		if *License.Features.Compliance {
-			props["EnableCompliance"] = strconv.FormatBool(*c.ComplianceSettings.Enable)
-		}
-
 		if *License.Features.SAML {
 			props["EnableSaml"] = strconv.FormatBool(*c.SamlSettings.Enable)
 			props["SamlLoginButtonText"] = *c.SamlSettings.LoginButtonText
@@ -396,6 +441,9 @@ func Desanitize(cfg *model.Config) {
 	if cfg.EmailSettings.PasswordResetSalt == model.FAKE_SETTING {
 		cfg.EmailSettings.PasswordResetSalt = Cfg.EmailSettings.PasswordResetSalt
 	}
+	if cfg.ServiceSettings.PostActionSigningSecret != nil && *cfg.ServiceSettings.PostActionSigningSecret == model.FAKE_SETTING {
+		*cfg.ServiceSettings.PostActionSigningSecret = *Cfg.ServiceSettings.PostActionSigningSecret
+	}
 	if cfg.EmailSettings.SMTPPassword == model.FAKE_SETTING {
 		cfg.EmailSettings.SMTPPassword = Cfg.EmailSettings.SMTPPassword
 	}
@@ -404,6 +452,10 @@ func Desanitize(cfg *model.Config) {
 		cfg.GitLabSettings.Secret = Cfg.GitLabSettings.Secret
 	}
 
+	if cfg.OpenIdSettings.Secret == model.FAKE_SETTING {
+		cfg.OpenIdSettings.Secret = Cfg.OpenIdSettings.Secret
+	}
+
 	if cfg.SqlSettings.DataSource == model.FAKE_SETTING {
 		cfg.SqlSettings.DataSource = Cfg.SqlSettings.DataSource
 	}
@@ -412,6 +464,8 @@ func Desanitize(cfg *model.Config) {
 	}
 
 	for i := range cfg.SqlSettings.DataSourceReplicas {
-		cfg.SqlSettings.DataSourceReplicas[i] = Cfg.SqlSettings.DataSourceReplicas[i]
+		if cfg.SqlSettings.DataSourceReplicas[i] == model.FAKE_SETTING && i < len(Cfg.SqlSettings.DataSourceReplicas) {
+			cfg.SqlSettings.DataSourceReplicas[i] = Cfg.SqlSettings.DataSourceReplicas[i]
+		}
 	}
 }
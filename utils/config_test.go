@@ -9,6 +9,6 @@ import (
 
 func TestConfig(t *testing.T) {
 	TranslationsPreInit()
-	LoadConfig("config.json")
+	LoadTestConfig()
 	InitTranslations(Cfg.LocalizationSettings)
 }
@@ -0,0 +1,53 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/mattermost/platform/model"
+)
+
+// FileBackend is implemented by each of the supported file storage drivers. All paths are relative to the root of the
+// configured storage (the local directory or the S3 bucket).
+type FileBackend interface {
+	TestConnection() *model.AppError
+
+	// Reader opens a stream to read the file at the given path. The caller is responsible for closing it.
+	Reader(path string) (io.ReadCloser, *model.AppError)
+	ReadFile(path string) ([]byte, *model.AppError)
+	FileExists(path string) (bool, *model.AppError)
+
+	// Writer opens a stream to write a new file at the given path. The file isn't guaranteed to be stored until the
+	// stream has been closed without an error.
+	Writer(path string) (io.WriteCloser, *model.AppError)
+	WriteFile(fr io.Reader, path string) (int64, *model.AppError)
+	MoveFile(oldPath, newPath string) *model.AppError
+	RemoveFile(path string) *model.AppError
+
+	ListDirectory(path string) ([]string, *model.AppError)
+	RemoveDirectory(path string) *model.AppError
+}
+
+func NewFileBackend(settings *model.FileSettings) (FileBackend, *model.AppError) {
+	switch settings.DriverName {
+	case model.IMAGE_DRIVER_S3:
+		return &S3FileBackend{
+			endpoint:  settings.AmazonS3Endpoint,
+			accessKey: settings.AmazonS3AccessKeyId,
+			secretKey: settings.AmazonS3SecretAccessKey,
+			secure:    settings.AmazonS3SSL == nil || *settings.AmazonS3SSL,
+			bucket:    settings.AmazonS3Bucket,
+		}, nil
+	case model.IMAGE_DRIVER_LOCAL:
+		return &LocalFileBackend{
+			directory: settings.Directory,
+		}, nil
+	}
+
+	err := model.NewLocAppError("NewFileBackend", "utils.file.new_file_backend.configured.app_error", nil, "driver="+settings.DriverName)
+	err.StatusCode = http.StatusNotImplemented
+	return nil, err
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	TEST_FILE_PATH = "/testfile"
+)
+
+type LocalFileBackend struct {
+	directory string
+}
+
+func (b *LocalFileBackend) TestConnection() *model.AppError {
+	f := []byte("testingwrite")
+	if err := writeFileLocally(f, filepath.Join(b.directory, TEST_FILE_PATH)); err != nil {
+		return model.NewLocAppError("TestFileConnection", "utils.file.test_connection.local.app_error", nil, err.Error())
+	}
+	os.Remove(filepath.Join(b.directory, TEST_FILE_PATH))
+	return nil
+}
+
+func (b *LocalFileBackend) Reader(path string) (io.ReadCloser, *model.AppError) {
+	if f, err := os.Open(filepath.Join(b.directory, path)); err != nil {
+		return nil, model.NewLocAppError("Reader", "api.file.read_file.reading_local.app_error", nil, err.Error())
+	} else {
+		return f, nil
+	}
+}
+
+func (b *LocalFileBackend) ReadFile(path string) ([]byte, *model.AppError) {
+	if f, err := ioutil.ReadFile(filepath.Join(b.directory, path)); err != nil {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.reading_local.app_error", nil, err.Error())
+	} else {
+		return f, nil
+	}
+}
+
+func (b *LocalFileBackend) FileExists(path string) (bool, *model.AppError) {
+	if _, err := os.Stat(filepath.Join(b.directory, path)); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, model.NewLocAppError("FileExists", "utils.file.file_exists.local.app_error", nil, err.Error())
+	}
+
+	return true, nil
+}
+
+func (b *LocalFileBackend) Writer(path string) (io.WriteCloser, *model.AppError) {
+	fullPath := filepath.Join(b.directory, path)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0774); err != nil {
+		return nil, model.NewLocAppError("Writer", "api.file.open_file_write_stream.creating_dir.app_error", nil, err.Error())
+	}
+
+	if fileHandle, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644); err != nil {
+		return nil, model.NewLocAppError("Writer", "api.file.open_file_write_stream.local_server.app_error", nil, err.Error())
+	} else {
+		return fileHandle, nil
+	}
+}
+
+func (b *LocalFileBackend) WriteFile(fr io.Reader, path string) (int64, *model.AppError) {
+	w, err := b.Writer(path)
+	if err != nil {
+		return 0, err
+	}
+
+	written, copyErr := io.Copy(w, fr)
+	if closeErr := w.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+
+	if copyErr != nil {
+		return written, model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, copyErr.Error())
+	}
+
+	return written, nil
+}
+
+func (b *LocalFileBackend) MoveFile(oldPath, newPath string) *model.AppError {
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(b.directory, newPath)), 0774); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
+	}
+
+	if err := os.Rename(filepath.Join(b.directory, oldPath), filepath.Join(b.directory, newPath)); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.rename.app_error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func (b *LocalFileBackend) RemoveFile(path string) *model.AppError {
+	if err := os.Remove(filepath.Join(b.directory, path)); err != nil && !os.IsNotExist(err) {
+		return model.NewLocAppError("RemoveFile", "utils.file.remove_file.local.app_error", nil, err.Error())
+	}
+	return nil
+}
+
+func (b *LocalFileBackend) ListDirectory(path string) ([]string, *model.AppError) {
+	var paths []string
+	if fileInfos, err := ioutil.ReadDir(filepath.Join(b.directory, path)); err != nil {
+		return nil, model.NewLocAppError("ListDirectory", "utils.file.list_directory.local.app_error", nil, err.Error())
+	} else {
+		for _, fileInfo := range fileInfos {
+			paths = append(paths, filepath.Join(path, fileInfo.Name()))
+		}
+	}
+	return paths, nil
+}
+
+func (b *LocalFileBackend) RemoveDirectory(path string) *model.AppError {
+	if err := os.RemoveAll(filepath.Join(b.directory, path)); err != nil {
+		return model.NewLocAppError("RemoveDirectory", "utils.file.remove_directory.local.app_error", nil, err.Error())
+	}
+	return nil
+}
+
+func writeFileLocally(f []byte, path string) *model.AppError {
+	if err := os.MkdirAll(filepath.Dir(path), 0774); err != nil {
+		directory, _ := filepath.Abs(filepath.Dir(path))
+		return model.NewLocAppError("WriteFile", "api.file.write_file_locally.create_dir.app_error", nil, "directory="+directory+", err="+err.Error())
+	}
+
+	if err := ioutil.WriteFile(path, f, 0644); err != nil {
+		return model.NewLocAppError("WriteFile", "api.file.write_file_locally.writing.app_error", nil, err.Error())
+	}
+
+	return nil
+}
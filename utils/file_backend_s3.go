@@ -0,0 +1,261 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"io"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	s3 "github.com/minio/minio-go"
+
+	"github.com/mattermost/platform/model"
+)
+
+// S3FileBackend stores files in an S3 bucket. The client looks up the bucket's region itself, so the region in the
+// file settings isn't needed to reach it.
+type S3FileBackend struct {
+	endpoint  string
+	accessKey string
+	secretKey string
+	secure    bool
+	bucket    string
+
+	clientMutex sync.Mutex
+	client      *s3.Client
+}
+
+// s3Client lazily creates the minio client so that it is only built once and then reused for every request.
+func (b *S3FileBackend) s3Client() (*s3.Client, error) {
+	b.clientMutex.Lock()
+	defer b.clientMutex.Unlock()
+
+	if b.client == nil {
+		client, err := s3.New(b.endpoint, b.accessKey, b.secretKey, b.secure)
+		if err != nil {
+			return nil, err
+		}
+
+		b.client = client
+	}
+
+	return b.client, nil
+}
+
+func (b *S3FileBackend) TestConnection() *model.AppError {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return model.NewLocAppError("TestFileConnection", "utils.file.test_connection.s3.connection.app_error", nil, err.Error())
+	}
+
+	if exists, err := s3Clnt.BucketExists(b.bucket); err != nil {
+		return model.NewLocAppError("TestFileConnection", "utils.file.test_connection.s3.connection.app_error", nil, err.Error())
+	} else if !exists {
+		return model.NewLocAppError("TestFileConnection", "utils.file.test_connection.s3.bucket_exists.app_error", nil, "bucket="+b.bucket)
+	}
+
+	return nil
+}
+
+func (b *S3FileBackend) Reader(path string) (io.ReadCloser, *model.AppError) {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return nil, model.NewLocAppError("Reader", "api.file.read_file.s3.app_error", nil, err.Error())
+	}
+
+	minioObject, err := s3Clnt.GetObject(b.bucket, path)
+	if err != nil {
+		return nil, model.NewLocAppError("Reader", "api.file.read_file.s3.app_error", nil, err.Error())
+	}
+
+	// GetObject doesn't make a request until the object is first read, so check that it exists before returning it
+	if _, err := minioObject.Stat(); err != nil {
+		minioObject.Close()
+		return nil, model.NewLocAppError("Reader", "api.file.read_file.s3.app_error", nil, err.Error())
+	}
+
+	return minioObject, nil
+}
+
+func (b *S3FileBackend) ReadFile(path string) ([]byte, *model.AppError) {
+	r, appErr := b.Reader(path)
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer r.Close()
+
+	if f, err := ioutil.ReadAll(r); err != nil {
+		return nil, model.NewLocAppError("ReadFile", "api.file.read_file.s3.app_error", nil, err.Error())
+	} else {
+		return f, nil
+	}
+}
+
+func (b *S3FileBackend) FileExists(path string) (bool, *model.AppError) {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return false, model.NewLocAppError("FileExists", "utils.file.file_exists.s3.app_error", nil, err.Error())
+	}
+
+	if _, err := s3Clnt.StatObject(b.bucket, path); err != nil {
+		if s3.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+
+		return false, model.NewLocAppError("FileExists", "utils.file.file_exists.s3.app_error", nil, err.Error())
+	}
+
+	return true, nil
+}
+
+// s3Writer pipes everything written to it into a PutObject request that runs in the background so that the file
+// never needs to be held in memory in full.
+type s3Writer struct {
+	pipe *io.PipeWriter
+	done chan error
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.pipe.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	w.pipe.Close()
+	return <-w.done
+}
+
+func (b *S3FileBackend) Writer(path string) (io.WriteCloser, *model.AppError) {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return nil, model.NewLocAppError("Writer", "api.file.write_file.s3.app_error", nil, err.Error())
+	}
+
+	pr, pw := io.Pipe()
+	w := &s3Writer{
+		pipe: pw,
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		_, err := s3Clnt.PutObject(b.bucket, path, pr, getContentType(path))
+
+		// Unblock any pending writes if the upload failed part way through
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+
+	return w, nil
+}
+
+func (b *S3FileBackend) WriteFile(fr io.Reader, path string) (int64, *model.AppError) {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return 0, model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
+	}
+
+	if written, err := s3Clnt.PutObject(b.bucket, path, fr, getContentType(path)); err != nil {
+		return written, model.NewLocAppError("WriteFile", "api.file.write_file.s3.app_error", nil, err.Error())
+	} else {
+		return written, nil
+	}
+}
+
+func (b *S3FileBackend) MoveFile(oldPath, newPath string) *model.AppError {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return model.NewLocAppError("moveFile", "api.file.write_file.s3.app_error", nil, err.Error())
+	}
+
+	var copyConds = s3.NewCopyConditions()
+	if err := s3Clnt.CopyObject(b.bucket, newPath, "/"+path.Join(b.bucket, oldPath), copyConds); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
+	}
+	if err := s3Clnt.RemoveObject(b.bucket, oldPath); err != nil {
+		return model.NewLocAppError("moveFile", "api.file.move_file.delete_from_s3.app_error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func (b *S3FileBackend) RemoveFile(path string) *model.AppError {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return model.NewLocAppError("RemoveFile", "utils.file.remove_file.s3.app_error", nil, err.Error())
+	}
+
+	if err := s3Clnt.RemoveObject(b.bucket, path); err != nil {
+		return model.NewLocAppError("RemoveFile", "utils.file.remove_file.s3.app_error", nil, err.Error())
+	}
+
+	return nil
+}
+
+func (b *S3FileBackend) listObjects(path string, recursive bool) ([]string, *model.AppError) {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return nil, model.NewLocAppError("ListDirectory", "utils.file.list_directory.s3.app_error", nil, err.Error())
+	}
+
+	prefix := path
+	if len(prefix) > 0 && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var paths []string
+	for object := range s3Clnt.ListObjects(b.bucket, prefix, recursive, doneCh) {
+		if object.Err != nil {
+			return nil, model.NewLocAppError("ListDirectory", "utils.file.list_directory.s3.app_error", nil, object.Err.Error())
+		}
+
+		paths = append(paths, strings.TrimSuffix(object.Key, "/"))
+	}
+
+	return paths, nil
+}
+
+func (b *S3FileBackend) ListDirectory(path string) ([]string, *model.AppError) {
+	return b.listObjects(path, false)
+}
+
+func (b *S3FileBackend) RemoveDirectory(path string) *model.AppError {
+	s3Clnt, err := b.s3Client()
+	if err != nil {
+		return model.NewLocAppError("RemoveDirectory", "utils.file.remove_directory.s3.app_error", nil, err.Error())
+	}
+
+	objects, appErr := b.listObjects(path, true)
+	if appErr != nil {
+		return appErr
+	}
+
+	objectsCh := make(chan string, len(objects))
+	for _, object := range objects {
+		objectsCh <- object
+	}
+	close(objectsCh)
+
+	for removeErr := range s3Clnt.RemoveObjects(b.bucket, objectsCh) {
+		if removeErr.Err != nil {
+			return model.NewLocAppError("RemoveDirectory", "utils.file.remove_directory.s3.app_error", nil, removeErr.Err.Error())
+		}
+	}
+
+	return nil
+}
+
+func getContentType(path string) string {
+	ext := filepath.Ext(path)
+
+	if model.IsFileExtImage(ext) {
+		return model.GetImageMimeType(ext)
+	} else {
+		return "binary/octet-stream"
+	}
+}
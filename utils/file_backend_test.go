@@ -0,0 +1,132 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestNewFileBackend(t *testing.T) {
+	if _, err := NewFileBackend(&model.FileSettings{DriverName: ""}); err == nil {
+		t.Fatal("should've failed with no driver")
+	}
+
+	if backend, err := NewFileBackend(&model.FileSettings{DriverName: model.IMAGE_DRIVER_LOCAL}); err != nil {
+		t.Fatal(err)
+	} else if _, ok := backend.(*LocalFileBackend); !ok {
+		t.Fatal("should've created a local file backend")
+	}
+
+	if backend, err := NewFileBackend(&model.FileSettings{DriverName: model.IMAGE_DRIVER_S3}); err != nil {
+		t.Fatal(err)
+	} else if _, ok := backend.(*S3FileBackend); !ok {
+		t.Fatal("should've created an S3 file backend")
+	}
+}
+
+func TestLocalFileBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filebackend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	backend, appErr := NewFileBackend(&model.FileSettings{DriverName: model.IMAGE_DRIVER_LOCAL, Directory: dir})
+	if appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	if err := backend.TestConnection(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("some test data")
+	path := "tests/" + model.NewId() + "/file.txt"
+
+	if exists, err := backend.FileExists(path); err != nil {
+		t.Fatal(err)
+	} else if exists {
+		t.Fatal("file shouldn't exist yet")
+	}
+
+	if written, err := backend.WriteFile(bytes.NewReader(data), path); err != nil {
+		t.Fatal(err)
+	} else if written != int64(len(data)) {
+		t.Fatal("wrote the wrong number of bytes")
+	}
+
+	if exists, err := backend.FileExists(path); err != nil {
+		t.Fatal(err)
+	} else if !exists {
+		t.Fatal("file should exist")
+	}
+
+	if read, err := backend.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(read, data) {
+		t.Fatal("read the wrong data")
+	}
+
+	if reader, err := backend.Reader(path); err != nil {
+		t.Fatal(err)
+	} else {
+		read, _ := ioutil.ReadAll(reader)
+		reader.Close()
+
+		if !bytes.Equal(read, data) {
+			t.Fatal("streamed the wrong data")
+		}
+	}
+
+	streamedPath := "tests/streamed/file.txt"
+	if writer, err := backend.Writer(streamedPath); err != nil {
+		t.Fatal(err)
+	} else {
+		writer.Write(data[:4])
+		writer.Write(data[4:])
+		if err := writer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if read, err := backend.ReadFile(streamedPath); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(read, data) {
+		t.Fatal("stream wrote the wrong data")
+	}
+
+	movedPath := "tests/moved/file.txt"
+	if err := backend.MoveFile(path, movedPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, _ := backend.FileExists(path); exists {
+		t.Fatal("file should've been moved")
+	} else if exists, _ := backend.FileExists(movedPath); !exists {
+		t.Fatal("file should've been moved")
+	}
+
+	if paths, err := backend.ListDirectory("tests"); err != nil {
+		t.Fatal(err)
+	} else if len(paths) != 3 {
+		t.Fatal("should've listed 3 directories", paths)
+	}
+
+	if err := backend.RemoveFile(movedPath); err != nil {
+		t.Fatal(err)
+	} else if exists, _ := backend.FileExists(movedPath); exists {
+		t.Fatal("file should've been removed")
+	}
+
+	if err := backend.RemoveDirectory("tests"); err != nil {
+		t.Fatal(err)
+	} else if exists, _ := backend.FileExists(streamedPath); exists {
+		t.Fatal("directory should've been removed")
+	}
+}
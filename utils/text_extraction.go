@@ -0,0 +1,426 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattermost/platform/model"
+)
+
+const (
+	TEXT_EXTRACTION_MAX_FILE_SIZE = 20 * 1024 * 1024 // 20MB
+
+	// documents are compressed, so allow them to expand to several times the size of the largest file we'll read
+	TEXT_EXTRACTION_MAX_DECOMPRESSED_SIZE = 5 * TEXT_EXTRACTION_MAX_FILE_SIZE
+)
+
+var plainTextExtensions = map[string]bool{
+	"txt": true, "text": true, "md": true, "markdown": true, "csv": true, "tsv": true, "log": true, "json": true,
+	"xml": true, "yaml": true, "yml": true, "html": true, "htm": true, "css": true, "scss": true, "less": true,
+	"go": true, "js": true, "jsx": true, "ts": true, "tsx": true, "py": true, "rb": true, "java": true, "kt": true,
+	"scala": true, "c": true, "h": true, "cc": true, "cpp": true, "hpp": true, "cs": true, "m": true, "swift": true,
+	"rs": true, "php": true, "pl": true, "lua": true, "r": true, "sh": true, "bash": true, "ps1": true, "sql": true,
+}
+
+// CanExtractText returns true if ExtractText is able to read the text from files with the given extension.
+func CanExtractText(extension string) bool {
+	extension = strings.ToLower(extension)
+
+	return plainTextExtensions[extension] || extension == "pdf" || extension == "docx"
+}
+
+// ExtractText returns the text contained in a file with the given extension so that it can be searched. Whitespace is
+// collapsed and the result is cut off at model.FILE_INFO_CONTENT_MAX_RUNES characters.
+func ExtractText(extension string, data []byte) (string, error) {
+	var text string
+	var err error
+
+	extension = strings.ToLower(extension)
+
+	if extension == "pdf" {
+		text, err = extractPdfText(data)
+	} else if extension == "docx" {
+		text, err = extractDocxText(data)
+	} else if plainTextExtensions[extension] {
+		text, err = extractPlainText(data)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return cleanExtractedText(text), nil
+}
+
+func cleanExtractedText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || (!unicode.IsPrint(r) && !unicode.IsSpace(r)) {
+			return -1
+		}
+
+		return r
+	}, text)
+
+	text = strings.Join(strings.Fields(text), " ")
+
+	if utf8.RuneCountInString(text) > model.FILE_INFO_CONTENT_MAX_RUNES {
+		text = string([]rune(text)[:model.FILE_INFO_CONTENT_MAX_RUNES])
+	}
+
+	return text
+}
+
+// latin1ToString is used for text that isn't valid UTF-8 since every byte maps directly to a code point.
+func latin1ToString(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+
+	return string(runes)
+}
+
+func extractPlainText(data []byte) (string, error) {
+	if bytes.IndexByte(data, 0) != -1 {
+		return "", errors.New("file contains binary data")
+	}
+
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	return latin1ToString(data), nil
+}
+
+func extractDocxText(data []byte) (string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range reader.File {
+		if file.Name != "word/document.xml" {
+			continue
+		}
+
+		document, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		defer document.Close()
+
+		return extractDocxDocumentText(io.LimitReader(document, TEXT_EXTRACTION_MAX_DECOMPRESSED_SIZE))
+	}
+
+	return "", errors.New("docx file is missing its document")
+}
+
+// extractDocxDocumentText reads the text runs out of the main part of a Word document, separating paragraphs, tabs and
+// line breaks with whitespace.
+func extractDocxDocumentText(r io.Reader) (string, error) {
+	decoder := xml.NewDecoder(r)
+
+	var text bytes.Buffer
+	inText := false
+
+	// stop once we've read more than could possibly be kept
+	for text.Len() < model.FILE_INFO_CONTENT_MAX_RUNES*utf8.UTFMax {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "t" {
+				inText = true
+			} else if t.Name.Local == "tab" || t.Name.Local == "br" {
+				text.WriteByte(' ')
+			}
+		case xml.EndElement:
+			if t.Name.Local == "t" {
+				inText = false
+			} else if t.Name.Local == "p" {
+				text.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+
+	return text.String(), nil
+}
+
+var pdfUnsupportedFilters = []string{"/DCTDecode", "/JPXDecode", "/CCITTFaxDecode", "/JBIG2Decode", "/LZWDecode", "/ASCII85Decode", "/ASCIIHexDecode", "/RunLengthDecode"}
+
+// extractPdfText makes a best effort to read the text out of a PDF by decoding each of its content streams and
+// collecting the strings shown by text operators. Text written using fonts with custom encodings can't be read this
+// way, but that's good enough for the common case of documents exported from word processors.
+func extractPdfText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("file isn't a pdf")
+	}
+
+	var text bytes.Buffer
+
+	for offset := 0; offset < len(data) && text.Len() < model.FILE_INFO_CONTENT_MAX_RUNES*utf8.UTFMax; {
+		streamStart := bytes.Index(data[offset:], []byte("stream"))
+		if streamStart == -1 {
+			break
+		}
+		streamStart += offset
+
+		// the stream's dictionary comes between the start of the object and the stream itself
+		dictionaryStart := bytes.LastIndex(data[offset:streamStart], []byte("obj"))
+		if dictionaryStart == -1 {
+			dictionaryStart = 0
+		}
+		dictionary := data[offset+dictionaryStart : streamStart]
+
+		contentStart := streamStart + len("stream")
+		if bytes.HasPrefix(data[contentStart:], []byte("\r\n")) {
+			contentStart += 2
+		} else if bytes.HasPrefix(data[contentStart:], []byte("\n")) {
+			contentStart += 1
+		} else {
+			// this was part of another keyword such as endstream
+			offset = contentStart
+			continue
+		}
+
+		contentEnd := bytes.Index(data[contentStart:], []byte("endstream"))
+		if contentEnd == -1 {
+			break
+		}
+		contentEnd += contentStart
+
+		offset = contentEnd + len("endstream")
+
+		if content := decodePdfStream(dictionary, data[contentStart:contentEnd]); content != nil {
+			extractPdfContentText(content, &text)
+		}
+	}
+
+	return text.String(), nil
+}
+
+// decodePdfStream returns the decoded contents of a stream or nil if the stream can't contain text.
+func decodePdfStream(dictionary []byte, stream []byte) []byte {
+	if bytes.Contains(dictionary, []byte("/Image")) || bytes.Contains(dictionary, []byte("/XRef")) {
+		return nil
+	}
+
+	for _, filter := range pdfUnsupportedFilters {
+		if bytes.Contains(dictionary, []byte(filter)) {
+			return nil
+		}
+	}
+
+	if !bytes.Contains(dictionary, []byte("/FlateDecode")) {
+		return stream
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(stream))
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+
+	// streams are often padded with extra bytes after the compressed data, so keep whatever was decoded before an error
+	content, _ := ioutil.ReadAll(io.LimitReader(reader, TEXT_EXTRACTION_MAX_DECOMPRESSED_SIZE))
+
+	return content
+}
+
+func isPdfDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) != -1
+}
+
+func isPdfWhitespace(c byte) bool {
+	return strings.IndexByte("\x00\t\n\f\r ", c) != -1
+}
+
+// extractPdfContentText writes the strings shown between each BT and ET operator in a content stream to text.
+func extractPdfContentText(content []byte, text *bytes.Buffer) {
+	inText := false
+	inArray := false
+
+	for i := 0; i < len(content); {
+		c := content[i]
+
+		switch {
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			str, next := readPdfLiteralString(content, i)
+			if inText {
+				text.WriteString(latin1ToString(str))
+			}
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '<':
+			str, next := readPdfHexString(content, i)
+			if inText {
+				text.WriteString(latin1ToString(str))
+			}
+			i = next
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case isPdfWhitespace(c) || isPdfDelimiter(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPdfWhitespace(content[i]) && !isPdfDelimiter(content[i]) {
+				i++
+			}
+
+			token := string(content[start:i])
+
+			switch token {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				text.WriteByte('\n')
+			case "Td", "TD", "Tm", "T*", "'", "\"":
+				if inText {
+					text.WriteByte(' ')
+				}
+			case "ID":
+				// skip over the binary data of inline images
+				if end := bytes.Index(content[i:], []byte("EI")); end != -1 {
+					i += end + len("EI")
+				} else {
+					i = len(content)
+				}
+			default:
+				// large negative adjustments between the strings of a TJ array are used instead of spaces
+				if inText && inArray {
+					if adjustment, err := strconv.ParseFloat(token, 64); err == nil && adjustment < -200 {
+						text.WriteByte(' ')
+					}
+				}
+			}
+		}
+	}
+}
+
+// readPdfLiteralString reads the string in parentheses starting at the given index and returns it along with the
+// index of the character following it.
+func readPdfLiteralString(content []byte, start int) ([]byte, int) {
+	var str []byte
+	depth := 0
+
+	i := start
+	for i < len(content) {
+		c := content[i]
+		i++
+
+		switch c {
+		case '(':
+			if depth > 0 {
+				str = append(str, c)
+			}
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return str, i
+			}
+			str = append(str, c)
+		case '\\':
+			if i >= len(content) {
+				return str, i
+			}
+
+			escaped := content[i]
+			i++
+
+			switch escaped {
+			case 'n':
+				str = append(str, '\n')
+			case 'r':
+				str = append(str, '\r')
+			case 't':
+				str = append(str, '\t')
+			case 'b':
+				str = append(str, '\b')
+			case 'f':
+				str = append(str, '\f')
+			case '\r':
+				// a backslash at the end of a line continues the string on the next one
+				if i < len(content) && content[i] == '\n' {
+					i++
+				}
+			case '\n':
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					value := int(escaped - '0')
+					for j := 0; j < 2 && i < len(content) && content[i] >= '0' && content[i] <= '7'; j++ {
+						value = value*8 + int(content[i]-'0')
+						i++
+					}
+					str = append(str, byte(value))
+				} else {
+					str = append(str, escaped)
+				}
+			}
+		default:
+			str = append(str, c)
+		}
+	}
+
+	return str, i
+}
+
+// readPdfHexString reads the string in angle brackets starting at the given index and returns it along with the index
+// of the character following it.
+func readPdfHexString(content []byte, start int) ([]byte, int) {
+	end := bytes.IndexByte(content[start:], '>')
+	if end == -1 {
+		return nil, len(content)
+	}
+	end += start
+
+	digits := []byte{}
+	for _, c := range content[start+1 : end] {
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+	}
+
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	str := make([]byte, len(digits)/2)
+	for i := range str {
+		value, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		str[i] = byte(value)
+	}
+
+	return str, end + 1
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2017 Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/platform/model"
+)
+
+func TestCanExtractText(t *testing.T) {
+	for _, extension := range []string{"txt", "MD", "csv", "go", "pdf", "docx"} {
+		if !CanExtractText(extension) {
+			t.Fatal("should be able to extract text from " + extension)
+		}
+	}
+
+	for _, extension := range []string{"", "png", "zip", "doc", "exe"} {
+		if CanExtractText(extension) {
+			t.Fatal("shouldn't be able to extract text from " + extension)
+		}
+	}
+}
+
+func TestExtractPlainText(t *testing.T) {
+	if text, err := ExtractText("md", []byte("# Heading\n\n* some   list\titems\n")); err != nil {
+		t.Fatal(err)
+	} else if text != "# Heading * some list items" {
+		t.Fatal("should've collapsed whitespace, got " + text)
+	}
+
+	if text, err := ExtractText("txt", []byte("caf\xe9")); err != nil {
+		t.Fatal(err)
+	} else if text != "café" {
+		t.Fatal("should've treated invalid utf-8 as latin-1, got " + text)
+	}
+
+	if _, err := ExtractText("txt", []byte("binary\x00data")); err == nil {
+		t.Fatal("shouldn't extract text from binary data")
+	}
+
+	if text, err := ExtractText("csv", []byte(strings.Repeat("a,", model.FILE_INFO_CONTENT_MAX_RUNES))); err != nil {
+		t.Fatal(err)
+	} else if len(text) != model.FILE_INFO_CONTENT_MAX_RUNES {
+		t.Fatal("should've limited the length of the text")
+	}
+
+	if text, err := ExtractText("png", []byte("not really an image")); err != nil || text != "" {
+		t.Fatal("shouldn't extract text from unsupported files")
+	}
+}
+
+func TestExtractDocxText(t *testing.T) {
+	var buf bytes.Buffer
+
+	archive := zip.NewWriter(&buf)
+	if file, err := archive.Create("word/document.xml"); err != nil {
+		t.Fatal(err)
+	} else {
+		file.Write([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+	<w:body>
+		<w:p><w:r><w:t>Quarterly</w:t></w:r><w:r><w:t xml:space="preserve"> report</w:t></w:r></w:p>
+		<w:p><w:r><w:t>Revenue</w:t><w:tab/><w:t>grew &amp; grew</w:t></w:r></w:p>
+	</w:body>
+</w:document>`))
+	}
+	archive.Close()
+
+	if text, err := ExtractText("docx", buf.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if text != "Quarterly report Revenue grew & grew" {
+		t.Fatal("incorrect text " + text)
+	}
+
+	if _, err := ExtractText("docx", []byte("not a zip")); err == nil {
+		t.Fatal("should've failed to read an invalid docx")
+	}
+}
+
+func TestExtractPdfText(t *testing.T) {
+	var compressed bytes.Buffer
+
+	writer := zlib.NewWriter(&compressed)
+	writer.Write([]byte("BT /F1 12 Tf 72 712 Td (Quarterly \\(Q1\\) report) Tj T* [(Reve) -30 (nue) -300 (grew)] TJ ET\n" +
+		"BT 72 600 Td <436f6e666964656e7469616c> Tj ET"))
+	writer.Close()
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.4\n")
+	pdf.WriteString("1 0 obj\n<< /Type /Catalog /Pages 2 0 R /Title (Not part of the content) >>\nendobj\n")
+	pdf.WriteString(fmt.Sprintf("4 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", compressed.Len()))
+	pdf.Write(compressed.Bytes())
+	pdf.WriteString("\nendstream\nendobj\n")
+	pdf.WriteString("5 0 obj\n<< /Length 24 >>\nstream\nBT (Uncompressed) Tj ET\n\nendstream\nendobj\n")
+	pdf.WriteString("6 0 obj\n<< /Type /XObject /Subtype /Image /Length 9 >>\nstream\n(ignored)\nendstream\nendobj\n")
+	pdf.WriteString("%%EOF\n")
+
+	if text, err := ExtractText("pdf", pdf.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if text != "Quarterly (Q1) report Revenue grew Confidential Uncompressed" {
+		t.Fatal("incorrect text " + text)
+	}
+
+	if _, err := ExtractText("pdf", []byte("not a pdf")); err == nil {
+		t.Fatal("should've failed to read an invalid pdf")
+	}
+}
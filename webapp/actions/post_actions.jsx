@@ -115,6 +115,17 @@ export function getFlaggedPosts() {
     );
 }
 
+export function doPostAction(channelId, postId, actionId, selectedOption) {
+    Client.doPostAction(channelId, postId, actionId, selectedOption,
+        () => {
+            // Any changes to the post will be received over the websocket
+        },
+        (err) => {
+            AsyncClient.dispatchError(err, 'doPostAction');
+        }
+    );
+}
+
 export function loadPosts(channelId = ChannelStore.getCurrentId()) {
     const postList = PostStore.getAllPosts(channelId);
     const latestPostTime = PostStore.getLatestPostFromPageTime(channelId);
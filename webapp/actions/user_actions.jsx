@@ -374,6 +374,21 @@ export function generateMfaSecret(success, error) {
     );
 }
 
+export function generateMfaRecoveryCodes(success, error) {
+    Client.generateMfaRecoveryCodes(
+        (data) => {
+            if (success) {
+                success(data);
+            }
+        },
+        (err) => {
+            if (error) {
+                error(err);
+            }
+        }
+    );
+}
+
 export function updateUserRoles(userId, newRoles, success, error) {
     Client.updateUserRoles(
       userId,
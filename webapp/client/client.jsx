@@ -1042,6 +1042,15 @@ export default class Client {
             end(this.handleResponse.bind(this, 'generateMfaSecret', success, error));
     }
 
+    generateMfaRecoveryCodes(success, error) {
+        request.
+            post(`${this.getUsersRoute()}/generate_mfa_recovery_codes`).
+            set(this.defaultHeaders).
+            type('application/json').
+            accept('application/json').
+            end(this.handleResponse.bind(this, 'generateMfaRecoveryCodes', success, error));
+    }
+
     revokeSession(altId, success, error) {
         request.
             post(`${this.getUsersRoute()}/revoke_session`).
@@ -1531,6 +1540,16 @@ export default class Client {
             end(this.handleResponse.bind(this, 'listCommands', success, error));
     }
 
+    getCommandSuggestions(channelId, userInput, success, error) {
+        request.
+            get(`${this.getCommandsRoute()}/suggestions`).
+            set(this.defaultHeaders).
+            type('application/json').
+            accept('application/json').
+            query({channel_id: channelId, user_input: userInput}).
+            end(this.handleResponse.bind(this, 'getCommandSuggestions', success, error));
+    }
+
     executeCommand(command, commandArgs, success, error) {
         request.
             post(`${this.getCommandsRoute()}/execute`).
@@ -1672,6 +1691,16 @@ export default class Client {
         this.track('api', 'api_posts_delete');
     }
 
+    doPostAction(channelId, postId, actionId, selectedOption, success, error) {
+        request.
+            post(`${this.getChannelNeededRoute(channelId)}/posts/${postId}/actions/${actionId}`).
+            set(this.defaultHeaders).
+            type('application/json').
+            accept('application/json').
+            send({selected_option: selectedOption || ''}).
+            end(this.handleResponse.bind(this, 'doPostAction', success, error));
+    }
+
     search(terms, isOrSearch, success, error) {
         const data = {};
         data.terms = terms;
@@ -1748,9 +1777,9 @@ export default class Client {
         return request.
             post(`${this.getTeamFilesRoute()}/upload`).
             set(this.defaultHeaders).
-            attach('files', file, filename).
             field('channel_id', channelId).
             field('client_ids', clientId).
+            attach('files', file, filename).
             accept('application/json').
             end(this.handleResponse.bind(this, 'uploadFile', success, error));
     }
@@ -10,6 +10,8 @@ export default class WebSocketClient {
         this.conn = null;
         this.connectionUrl = null;
         this.sequence = 1;
+        this.connectionId = null;
+        this.eventSequence = -1;
         this.connectFailCount = 0;
         this.eventCallback = null;
         this.responseCallbacks = {};
@@ -33,7 +35,13 @@ export default class WebSocketClient {
             console.log('websocket connecting to ' + connectionUrl); //eslint-disable-line no-console
         }
 
-        this.conn = new WebSocket(connectionUrl);
+        // Ask the server to resume the previous connection so that we're sent any events we missed
+        let url = connectionUrl;
+        if (this.connectionId) {
+            url += '?connection_id=' + encodeURIComponent(this.connectionId) + '&sequence_number=' + this.eventSequence;
+        }
+
+        this.conn = new WebSocket(url);
         this.connectionUrl = connectionUrl;
 
         this.conn.onopen = () => {
@@ -43,7 +51,9 @@ export default class WebSocketClient {
 
             if (this.connectFailCount > 0) {
                 console.log('websocket re-established connection'); //eslint-disable-line no-console
-                if (this.reconnectCallback) {
+
+                // If the connection is being resumed, the server will tell us if we need to reload anything
+                if (!this.connectionId && this.reconnectCallback) {
                     this.reconnectCallback();
                 }
             } else if (this.firstConnectCallback) {
@@ -107,8 +117,22 @@ export default class WebSocketClient {
                     this.responseCallbacks[msg.seq_reply](msg);
                     Reflect.deleteProperty(this.responseCallbacks, msg.seq_reply);
                 }
-            } else if (this.eventCallback) {
-                this.eventCallback(msg);
+            } else {
+                this.eventSequence = msg.seq;
+
+                if (msg.event === 'hello') {
+                    this.connectionId = msg.data.connection_id;
+                } else if (msg.event === 'resync_required') {
+                    console.log('websocket missed events while disconnected'); //eslint-disable-line no-console
+                    if (this.reconnectCallback) {
+                        this.reconnectCallback();
+                    }
+                    return;
+                }
+
+                if (this.eventCallback) {
+                    this.eventCallback(msg);
+                }
             }
         };
     }
@@ -136,6 +160,8 @@ export default class WebSocketClient {
     close() {
         this.connectFailCount = 0;
         this.sequence = 1;
+        this.connectionId = null;
+        this.eventSequence = -1;
         if (this.conn && this.conn.readyState === WebSocket.OPEN) {
             this.conn.onclose = () => {}; //eslint-disable-line no-empty-function
             this.conn.close();
@@ -207,7 +207,6 @@ export default class AdminSidebar extends React.Component {
         let clusterSettings = null;
         let metricsSettings = null;
         let complianceSettings = null;
-        let mfaSettings = null;
 
         let license = null;
         let audits = null;
@@ -298,20 +297,6 @@ export default class AdminSidebar extends React.Component {
                 );
             }
 
-            if (global.window.mm_license.MFA === 'true') {
-                mfaSettings = (
-                    <AdminSidebarSection
-                        name='mfa'
-                        title={
-                            <FormattedMessage
-                                id='admin.sidebar.mfa'
-                                defaultMessage='MFA'
-                            />
-                        }
-                    />
-                );
-            }
-
             oauthSettings = (
                 <AdminSidebarSection
                     name='oauth'
@@ -398,6 +383,18 @@ export default class AdminSidebar extends React.Component {
             );
         }
 
+        const mfaSettings = (
+            <AdminSidebarSection
+                name='mfa'
+                title={
+                    <FormattedMessage
+                        id='admin.sidebar.mfa'
+                        defaultMessage='MFA'
+                    />
+                }
+            />
+        );
+
         const webrtcSettings = (
             <AdminSidebarSection
                 name='webrtc'
@@ -25,6 +25,11 @@ export default class ClusterSettings extends AdminSettings {
     getConfigFromState(config) {
         config.ClusterSettings.Enable = this.state.enable;
         config.ClusterSettings.InterNodeListenAddress = this.state.interNodeListenAddress;
+        config.ClusterSettings.AdvertiseAddress = this.state.advertiseAddress;
+        config.ClusterSettings.UseTLS = this.state.useTLS;
+        config.ClusterSettings.TLSCertFile = this.state.tlsCertFile;
+        config.ClusterSettings.TLSKeyFile = this.state.tlsKeyFile;
+        config.ClusterSettings.TLSCAFile = this.state.tlsCAFile;
 
         config.ClusterSettings.InterNodeUrls = this.state.interNodeUrls.split(',');
         config.ClusterSettings.InterNodeUrls = config.ClusterSettings.InterNodeUrls.map((url) => {
@@ -45,6 +50,11 @@ export default class ClusterSettings extends AdminSettings {
             enable: settings.Enable,
             interNodeUrls: settings.InterNodeUrls.join(', '),
             interNodeListenAddress: settings.InterNodeListenAddress,
+            advertiseAddress: settings.AdvertiseAddress,
+            useTLS: settings.UseTLS,
+            tlsCertFile: settings.TLSCertFile,
+            tlsKeyFile: settings.TLSKeyFile,
+            tlsCAFile: settings.TLSCAFile,
             showWarning: false
         };
     }
@@ -163,6 +173,97 @@ export default class ClusterSettings extends AdminSettings {
                     onChange={this.overrideHandleChange}
                     disabled={true}
                 />
+                <TextSetting
+                    id='advertiseAddress'
+                    label={
+                        <FormattedMessage
+                            id='admin.cluster.advertiseAddressTitle'
+                            defaultMessage='Advertise Address:'
+                        />
+                    }
+                    placeholder={Utils.localizeMessage('admin.cluster.advertiseAddressEx', 'Ex "http://10.10.10.30:8075"')}
+                    helpText={
+                        <FormattedMessage
+                            id='admin.cluster.advertiseAddressDesc'
+                            defaultMessage='The internal/private URL that the other servers use to reach this server. If blank, the server uses its hostname and the port from the Inter-Node Listen Address.'
+                        />
+                    }
+                    value={this.state.advertiseAddress}
+                    onChange={this.overrideHandleChange}
+                    disabled={true}
+                />
+                <BooleanSetting
+                    id='useTLS'
+                    label={
+                        <FormattedMessage
+                            id='admin.cluster.useTLSTitle'
+                            defaultMessage='Use TLS Between Servers:'
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.cluster.useTLSDesc'
+                            defaultMessage='When true, the servers encrypt their communication with each other, and configuration changes are synced along with their passwords and secrets. Every server in the cluster must use TLS and advertise an https address. When false, passwords and secrets are not synced and must be set on each server.'
+                        />
+                    }
+                    value={this.state.useTLS}
+                    onChange={this.overrideHandleChange}
+                    disabled={true}
+                />
+                <TextSetting
+                    id='tlsCertFile'
+                    label={
+                        <FormattedMessage
+                            id='admin.cluster.tlsCertFileTitle'
+                            defaultMessage='TLS Certificate File:'
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.cluster.tlsCertFileDesc'
+                            defaultMessage='The certificate file this server uses for communicating with other servers.'
+                        />
+                    }
+                    value={this.state.tlsCertFile}
+                    onChange={this.overrideHandleChange}
+                    disabled={true}
+                />
+                <TextSetting
+                    id='tlsKeyFile'
+                    label={
+                        <FormattedMessage
+                            id='admin.cluster.tlsKeyFileTitle'
+                            defaultMessage='TLS Key File:'
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.cluster.tlsKeyFileDesc'
+                            defaultMessage='The private key file for the TLS certificate.'
+                        />
+                    }
+                    value={this.state.tlsKeyFile}
+                    onChange={this.overrideHandleChange}
+                    disabled={true}
+                />
+                <TextSetting
+                    id='tlsCAFile'
+                    label={
+                        <FormattedMessage
+                            id='admin.cluster.tlsCAFileTitle'
+                            defaultMessage='TLS Certificate Authority File:'
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.cluster.tlsCAFileDesc'
+                            defaultMessage='The certificate authorities used to verify the certificates of the other servers. If blank, the system certificate authorities are used.'
+                        />
+                    }
+                    value={this.state.tlsCAFile}
+                    onChange={this.overrideHandleChange}
+                    disabled={true}
+                />
                 <TextSetting
                     id='interNodeUrls'
                     label={
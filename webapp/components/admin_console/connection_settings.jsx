@@ -23,6 +23,7 @@ export default class ConnectionSettings extends AdminSettings {
     getConfigFromState(config) {
         config.ServiceSettings.AllowCorsFrom = this.state.allowCorsFrom;
         config.ServiceSettings.EnableInsecureOutgoingConnections = this.state.enableInsecureOutgoingConnections;
+        config.ServiceSettings.AllowedUntrustedInternalConnections = this.state.allowedUntrustedInternalConnections;
 
         return config;
     }
@@ -30,7 +31,8 @@ export default class ConnectionSettings extends AdminSettings {
     getStateFromConfig(config) {
         return {
             allowCorsFrom: config.ServiceSettings.AllowCorsFrom,
-            enableInsecureOutgoingConnections: config.ServiceSettings.EnableInsecureOutgoingConnections
+            enableInsecureOutgoingConnections: config.ServiceSettings.EnableInsecureOutgoingConnections,
+            allowedUntrustedInternalConnections: config.ServiceSettings.AllowedUntrustedInternalConnections
         };
     }
 
@@ -83,6 +85,24 @@ export default class ConnectionSettings extends AdminSettings {
                     value={this.state.enableInsecureOutgoingConnections}
                     onChange={this.handleChange}
                 />
+                <TextSetting
+                    id='allowedUntrustedInternalConnections'
+                    label={
+                        <FormattedMessage
+                            id='admin.service.internalConnectionsTitle'
+                            defaultMessage='Allow untrusted internal connections to: '
+                        />
+                    }
+                    placeholder={Utils.localizeMessage('admin.service.internalConnectionsEx', 'webhooks.internal.example.com 127.0.0.1 10.0.16.0/28')}
+                    helpText={
+                        <FormattedMessage
+                            id='admin.service.internalConnectionsDesc'
+                            defaultMessage='A whitespace-separated list of hostnames, IP addresses and CIDR ranges that the server is allowed to connect to on behalf of users, such as when a message action is used, even though they are on the local network or this machine. Use this with care since it lets users reach those addresses through the server.'
+                        />
+                    }
+                    value={this.state.allowedUntrustedInternalConnections}
+                    onChange={this.handleChange}
+                />
             </SettingsGroup>
         );
     }
@@ -25,6 +25,9 @@ export default class WebhookSettings extends AdminSettings {
         config.ServiceSettings.EnablePostUsernameOverride = this.state.enablePostUsernameOverride;
         config.ServiceSettings.EnablePostIconOverride = this.state.enablePostIconOverride;
         config.ServiceSettings.EnableOAuthServiceProvider = this.state.enableOAuthServiceProvider;
+        config.ServiceSettings.EnablePersonalAccessTokens = this.state.enablePersonalAccessTokens;
+        config.ServiceSettings.EnableBotAccounts = this.state.enableBotAccounts;
+        config.ServiceSettings.EnableEventSubscriptions = this.state.enableEventSubscriptions;
 
         return config;
     }
@@ -37,7 +40,10 @@ export default class WebhookSettings extends AdminSettings {
             enableOnlyAdminIntegrations: config.ServiceSettings.EnableOnlyAdminIntegrations,
             enablePostUsernameOverride: config.ServiceSettings.EnablePostUsernameOverride,
             enablePostIconOverride: config.ServiceSettings.EnablePostIconOverride,
-            enableOAuthServiceProvider: config.ServiceSettings.EnableOAuthServiceProvider
+            enableOAuthServiceProvider: config.ServiceSettings.EnableOAuthServiceProvider,
+            enablePersonalAccessTokens: config.ServiceSettings.EnablePersonalAccessTokens,
+            enableBotAccounts: config.ServiceSettings.EnableBotAccounts,
+            enableEventSubscriptions: config.ServiceSettings.EnableEventSubscriptions
         };
     }
 
@@ -123,6 +129,57 @@ export default class WebhookSettings extends AdminSettings {
                     value={this.state.enableOAuthServiceProvider}
                     onChange={this.handleChange}
                 />
+                <BooleanSetting
+                    id='enablePersonalAccessTokens'
+                    label={
+                        <FormattedMessage
+                            id='admin.service.personalAccessTokensTitle'
+                            defaultMessage='Enable Personal Access Tokens: '
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.service.personalAccessTokensDescription'
+                            defaultMessage='When true, users can create personal access tokens that let scripts and integrations use the API as them. Tokens never expire and stay valid until they are revoked.'
+                        />
+                    }
+                    value={this.state.enablePersonalAccessTokens}
+                    onChange={this.handleChange}
+                />
+                <BooleanSetting
+                    id='enableBotAccounts'
+                    label={
+                        <FormattedMessage
+                            id='admin.service.botAccountsTitle'
+                            defaultMessage='Enable Bot Accounts: '
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.service.botAccountsDescription'
+                            defaultMessage='When true, users can create bot accounts for integrations to post as. Bots can only use the API with personal access tokens.'
+                        />
+                    }
+                    value={this.state.enableBotAccounts}
+                    onChange={this.handleChange}
+                />
+                <BooleanSetting
+                    id='enableEventSubscriptions'
+                    label={
+                        <FormattedMessage
+                            id='admin.service.eventSubscriptionsTitle'
+                            defaultMessage='Enable Event Subscriptions: '
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.service.eventSubscriptionsDescription'
+                            defaultMessage='When true, integrations can subscribe to events such as channels being created or posts being edited in public channels. Events are sent to the subscription URL with the same payload as WebSocket events.'
+                        />
+                    }
+                    value={this.state.enableEventSubscriptions}
+                    onChange={this.handleChange}
+                />
                 <BooleanSetting
                     id='enableOnlyAdminIntegrations'
                     label={
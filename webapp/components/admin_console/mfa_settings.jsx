@@ -18,13 +18,15 @@ export default class MfaSettings extends AdminSettings {
 
         this.state = Object.assign(this.state, {
             enableMultifactorAuthentication: props.config.ServiceSettings.EnableMultifactorAuthentication,
-            enforceMultifactorAuthentication: props.config.ServiceSettings.EnforceMultifactorAuthentication
+            enforceMultifactorAuthentication: props.config.ServiceSettings.EnforceMultifactorAuthentication,
+            enforceMultifactorAuthenticationForAdmins: props.config.ServiceSettings.EnforceMultifactorAuthenticationForAdmins
         });
     }
 
     getConfigFromState(config) {
         config.ServiceSettings.EnableMultifactorAuthentication = this.state.enableMultifactorAuthentication;
         config.ServiceSettings.EnforceMultifactorAuthentication = this.state.enableMultifactorAuthentication && this.state.enforceMultifactorAuthentication;
+        config.ServiceSettings.EnforceMultifactorAuthenticationForAdmins = this.state.enableMultifactorAuthentication && this.state.enforceMultifactorAuthenticationForAdmins;
 
         return config;
     }
@@ -32,7 +34,8 @@ export default class MfaSettings extends AdminSettings {
     getStateFromConfig(config) {
         return {
             enableMultifactorAuthentication: config.ServiceSettings.EnableMultifactorAuthentication,
-            enforceMultifactorAuthentication: config.ServiceSettings.EnableMultifactorAuthentication && config.ServiceSettings.EnforceMultifactorAuthentication
+            enforceMultifactorAuthentication: config.ServiceSettings.EnableMultifactorAuthentication && config.ServiceSettings.EnforceMultifactorAuthentication,
+            enforceMultifactorAuthenticationForAdmins: config.ServiceSettings.EnableMultifactorAuthentication && config.ServiceSettings.EnforceMultifactorAuthenticationForAdmins
         };
     }
 
@@ -93,6 +96,24 @@ export default class MfaSettings extends AdminSettings {
                     value={this.state.enforceMultifactorAuthentication}
                     onChange={this.handleChange}
                 />
+                <BooleanSetting
+                    id='enforceMultifactorAuthenticationForAdmins'
+                    label={
+                        <FormattedMessage
+                            id='admin.service.enforceMfaForAdminsTitle'
+                            defaultMessage='Enforce Multi-factor Authentication for System Admins:'
+                        />
+                    }
+                    helpText={
+                        <FormattedMessage
+                            id='admin.service.enforceMfaForAdminsDesc'
+                            defaultMessage='When true, System Admins will be required to set up multi-factor authentication even if it is not enforced for other users. Any logged in System Admins will be redirected to the multi-factor authentication setup page until they successfully add MFA to their account.'
+                        />
+                    }
+                    disabled={!this.state.enableMultifactorAuthentication || this.state.enforceMultifactorAuthentication}
+                    value={this.state.enforceMultifactorAuthenticationForAdmins}
+                    onChange={this.handleChange}
+                />
             </SettingsGroup>
         );
     }
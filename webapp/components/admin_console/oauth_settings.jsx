@@ -22,6 +22,7 @@ export default class OAuthSettings extends AdminSettings {
         this.renderOffice365 = this.renderOffice365.bind(this);
         this.renderGoogle = this.renderGoogle.bind(this);
         this.renderGitLab = this.renderGitLab.bind(this);
+        this.renderOpenId = this.renderOpenId.bind(this);
         this.changeType = this.changeType.bind(this);
     }
 
@@ -29,6 +30,7 @@ export default class OAuthSettings extends AdminSettings {
         config.GitLabSettings.Enable = false;
         config.GoogleSettings.Enable = false;
         config.Office365Settings.Enable = false;
+        config.OpenIdSettings.Enable = false;
 
         if (this.state.oauthType === Constants.GITLAB_SERVICE) {
             config.GitLabSettings.Enable = true;
@@ -59,6 +61,13 @@ export default class OAuthSettings extends AdminSettings {
             config.Office365Settings.Scope = 'User.Read';
         }
 
+        if (this.state.oauthType === Constants.OPENID_SERVICE) {
+            config.OpenIdSettings.Enable = true;
+            config.OpenIdSettings.Id = this.state.id;
+            config.OpenIdSettings.Secret = this.state.secret;
+            config.OpenIdSettings.Issuer = this.state.issuer;
+        }
+
         return config;
     }
 
@@ -76,6 +85,9 @@ export default class OAuthSettings extends AdminSettings {
         } else if (config.Office365Settings.Enable) {
             oauthType = Constants.OFFICE365_SERVICE;
             settings = config.Office365Settings;
+        } else if (config.OpenIdSettings.Enable) {
+            oauthType = Constants.OPENID_SERVICE;
+            settings = config.OpenIdSettings;
         }
 
         return {
@@ -84,7 +96,8 @@ export default class OAuthSettings extends AdminSettings {
             secret: settings.Secret,
             userApiEndpoint: settings.UserApiEndpoint,
             authEndpoint: settings.AuthEndpoint,
-            tokenEndpoint: settings.TokenEndpoint
+            tokenEndpoint: settings.TokenEndpoint,
+            issuer: settings.Issuer
         };
     }
 
@@ -96,6 +109,8 @@ export default class OAuthSettings extends AdminSettings {
             settings = this.config.GoogleSettings;
         } else if (value === Constants.OFFICE365_SERVICE) {
             settings = this.config.Office365Settings;
+        } else if (value === Constants.OPENID_SERVICE) {
+            settings = this.config.OpenIdSettings;
         }
 
         this.setState({
@@ -103,7 +118,8 @@ export default class OAuthSettings extends AdminSettings {
             secret: settings.Secret,
             userApiEndpoint: settings.UserApiEndpoint,
             authEndpoint: settings.AuthEndpoint,
-            tokenEndpoint: settings.TokenEndpoint
+            tokenEndpoint: settings.TokenEndpoint,
+            issuer: settings.Issuer
         });
 
         this.handleChange(id, value);
@@ -369,6 +385,67 @@ export default class OAuthSettings extends AdminSettings {
         );
     }
 
+    renderOpenId() {
+        return (
+            <div>
+                <TextSetting
+                    id='issuer'
+                    label={
+                        <FormattedMessage
+                            id='admin.openid.issuerTitle'
+                            defaultMessage='Issuer:'
+                        />
+                    }
+                    placeholder={Utils.localizeMessage('admin.openid.issuerExample', 'Ex "https://accounts.example.com"')}
+                    helpText={
+                        <FormattedMessage
+                            id='admin.openid.issuerDescription'
+                            defaultMessage='The URL that identifies your OpenID Connect provider. Its endpoints and signing keys are found using the discovery document at <issuer>/.well-known/openid-configuration.'
+                        />
+                    }
+                    value={this.state.issuer}
+                    onChange={this.handleChange}
+                />
+                <TextSetting
+                    id='id'
+                    label={
+                        <FormattedMessage
+                            id='admin.openid.clientIdTitle'
+                            defaultMessage='Client ID:'
+                        />
+                    }
+                    placeholder={Utils.localizeMessage('admin.openid.clientIdExample', 'Ex "mattermost"')}
+                    helpText={
+                        <FormattedMessage
+                            id='admin.openid.clientIdDescription'
+                            defaultMessage='The Client ID you received when registering your application with your OpenID Connect provider.'
+                        />
+                    }
+                    value={this.state.id}
+                    onChange={this.handleChange}
+                />
+                <TextSetting
+                    id='secret'
+                    label={
+                        <FormattedMessage
+                            id='admin.openid.clientSecretTitle'
+                            defaultMessage='Client Secret:'
+                        />
+                    }
+                    placeholder={Utils.localizeMessage('admin.openid.clientSecretExample', 'Ex "H8sz0Az-dDs2p15-7QzD231"')}
+                    helpText={
+                        <FormattedMessage
+                            id='admin.openid.clientSecretDescription'
+                            defaultMessage='The Client Secret you received when registering your application with your OpenID Connect provider.'
+                        />
+                    }
+                    value={this.state.secret}
+                    onChange={this.handleChange}
+                />
+            </div>
+        );
+    }
+
     renderSettings() {
         let contents;
         let helpText;
@@ -396,11 +473,20 @@ export default class OAuthSettings extends AdminSettings {
                     defaultMessage='<ol><li><a target="_blank" href="https://login.microsoftonline.com/">Log in</a> to your Microsoft or Office 365 account. Make sure it`s the account on the same <a target="_blank" href="https://msdn.microsoft.com/en-us/library/azure/jj573650.aspx#Anchor_0">tenant</a> that you would like users to log in with.</li><li>Go to <a target="_blank" href="https://apps.dev.microsoft.com">https://apps.dev.microsoft.com</a>, click <strong>Go to app list</strong> > <strong>Add an app</strong> and use "Mattermost - your-company-name" as the <strong>Application Name</strong>.</li><li>Under <strong>Application Secrets</strong>, click <strong>Generate New Password</strong> and paste it to the <strong>Application Secret Password</strong> field below.</li><li>Under <strong>Platforms</strong>, click <strong>Add Platform</strong>, choose <strong>Web</strong> and enter <strong>your-mattermost-url/signup/office365/complete</strong> (example: http://localhost:8065/signup/office365/complete) under <strong>Redirect URIs</strong>. Also uncheck <strong>Allow Implicit Flow</strong>.</li><li>Finally, click <strong>Save</strong> and then paste the <strong>Application ID</strong> below.</li></ol>'
                 />
             );
+        } else if (this.state.oauthType === Constants.OPENID_SERVICE) {
+            contents = this.renderOpenId();
+            helpText = (
+                <FormattedHTMLMessage
+                    id='admin.openid.EnableHtmlDesc'
+                    defaultMessage='<ol><li>Register Mattermost as a web application with your OpenID Connect provider.</li><li>Enter "<your-mattermost-url>/signup/openid/complete" (example: http://localhost:8065/signup/openid/complete) as the redirect URI.</li><li>Allow the application to use the "openid", "profile" and "email" scopes, and to use refresh tokens so that users who are deactivated by the provider are signed out of Mattermost.</li><li>Complete the fields below with the issuer URL of your provider and the Client ID and Client Secret of the application.</li></ol>'
+                />
+            );
         }
 
         const oauthTypes = [];
         oauthTypes.push({value: 'off', text: Utils.localizeMessage('admin.oauth.off', 'Do not allow sign-in via an OAuth 2.0 provider.')});
         oauthTypes.push({value: Constants.GITLAB_SERVICE, text: Utils.localizeMessage('admin.oauth.gitlab', 'GitLab')});
+        oauthTypes.push({value: Constants.OPENID_SERVICE, text: Utils.localizeMessage('admin.oauth.openid', 'OpenID Connect')});
         if (global.window.mm_license.IsLicensed === 'true') {
             if (global.window.mm_license.GoogleOAuth === 'true') {
                 oauthTypes.push({value: Constants.GOOGLE_SERVICE, text: Utils.localizeMessage('admin.oauth.google', 'Google Apps')});
@@ -183,7 +183,7 @@ export default class UserList extends React.Component {
         const users = this.state.users;
         const actionUserProps = {};
         const extraInfo = {};
-        const mfaEnabled = global.window.mm_license.IsLicensed === 'true' && global.window.mm_license.MFA === 'true' && global.window.mm_config.EnableMultifactorAuthentication === 'true';
+        const mfaEnabled = global.window.mm_config.EnableMultifactorAuthentication === 'true';
 
         let usersToDisplay;
         if (this.state.loading) {
@@ -52,6 +52,12 @@ export default class LoginMfa extends React.Component {
                             defaultMessage="To complete the sign in process, please enter a token from your smartphone's authenticator"
                         />
                     </p>
+                    <p>
+                        <FormattedMessage
+                            id='login_mfa.recoveryCode'
+                            defaultMessage="If you don't have access to your authenticator, you can enter one of your recovery codes instead."
+                        />
+                    </p>
                     <div className={'form-group' + errorClass}>
                         {serverError}
                     </div>
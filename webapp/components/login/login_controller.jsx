@@ -281,6 +281,7 @@ export default class LoginController extends React.Component {
             global.window.mm_config.EnableSignUpWithGitLab === 'true' ||
             global.window.mm_config.EnableSignUpWithOffice365 === 'true' ||
             global.window.mm_config.EnableSignUpWithGoogle === 'true' ||
+            global.window.mm_config.EnableSignUpWithOpenId === 'true' ||
             global.window.mm_config.EnableLdap === 'true' ||
             global.window.mm_config.EnableSaml === 'true';
     }
@@ -338,6 +339,7 @@ export default class LoginController extends React.Component {
         const gitlabSigninEnabled = global.window.mm_config.EnableSignUpWithGitLab === 'true';
         const googleSigninEnabled = global.window.mm_config.EnableSignUpWithGoogle === 'true';
         const office365SigninEnabled = global.window.mm_config.EnableSignUpWithOffice365 === 'true';
+        const openIdSigninEnabled = global.window.mm_config.EnableSignUpWithOpenId === 'true';
         const samlSigninEnabled = this.state.samlEnabled;
         const usernameSigninEnabled = this.state.usernameSigninEnabled;
         const emailSigninEnabled = this.state.emailSigninEnabled;
@@ -439,7 +441,7 @@ export default class LoginController extends React.Component {
             );
         }
 
-        if ((emailSigninEnabled || usernameSigninEnabled || ldapEnabled) && (gitlabSigninEnabled || googleSigninEnabled || samlSigninEnabled || office365SigninEnabled)) {
+        if ((emailSigninEnabled || usernameSigninEnabled || ldapEnabled) && (gitlabSigninEnabled || googleSigninEnabled || samlSigninEnabled || office365SigninEnabled || openIdSigninEnabled)) {
             loginControls.push(
                 <div
                     key='divider'
@@ -516,6 +518,24 @@ export default class LoginController extends React.Component {
             );
         }
 
+        if (openIdSigninEnabled) {
+            loginControls.push(
+                <a
+                    className='btn btn-custom-login openid'
+                    key='openid'
+                    href={Client.getOAuthRoute() + '/openid/login' + this.props.location.search}
+                >
+                    <span className='icon fa fa-openid fa--margin-top'/>
+                    <span>
+                        <FormattedMessage
+                            id='login.openid'
+                            defaultMessage='OpenID Connect'
+                        />
+                    </span>
+                </a>
+            );
+        }
+
         if (samlSigninEnabled) {
             loginControls.push(
                 <a
@@ -49,7 +49,7 @@ export default class Setup extends React.Component {
                 browserHistory.push('/mfa/confirm');
             },
             (err) => {
-                if (err.id === 'ent.mfa.activate.authenticate.app_error') {
+                if (err.id === 'ent.mfa.activate.authenticate.app_error' || err.id === 'ent.mfa.activate.bad_token.app_error') {
                     this.setState({error: Utils.localizeMessage('mfa.setup.badCode', 'Invalid code. If this issue persists, contact your System Administrator.')});
                     return;
                 }
@@ -67,7 +67,7 @@ export default class Setup extends React.Component {
         }
 
         let mfaRequired;
-        if (global.window.mm_config.EnforceMultifactorAuthentication === 'true') {
+        if (Utils.isMfaEnforced(UserStore.getCurrentUser())) {
             mfaRequired = (
                 <p>
                     <FormattedHTMLMessage
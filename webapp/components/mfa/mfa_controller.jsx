@@ -5,18 +5,22 @@ import React from 'react';
 import {FormattedMessage} from 'react-intl';
 import {browserHistory, Link} from 'react-router/es6';
 
+import UserStore from 'stores/user_store.jsx';
+
+import * as Utils from 'utils/utils.jsx';
+
 import logoImage from 'images/logo.png';
 
 export default class MFAController extends React.Component {
     componentDidMount() {
-        if (window.mm_license.MFA !== 'true' || window.mm_config.EnableMultifactorAuthentication !== 'true') {
+        if (window.mm_config.EnableMultifactorAuthentication !== 'true') {
             browserHistory.push('/');
         }
     }
 
     render() {
         let backButton;
-        if (window.mm_config.EnforceMultifactorAuthentication !== 'true') {
+        if (!Utils.isMfaEnforced(UserStore.getCurrentUser())) {
             backButton = (
                 <div className='signup-header'>
                     <Link to='/'>
@@ -3,6 +3,7 @@
 
 import $ from 'jquery';
 import * as TextFormatting from 'utils/text_formatting.jsx';
+import {doPostAction} from 'actions/post_actions.jsx';
 
 import {intlShape, injectIntl, defineMessages} from 'react-intl';
 
@@ -23,6 +24,9 @@ class PostAttachment extends React.Component {
     constructor(props) {
         super(props);
 
+        this.getActionView = this.getActionView.bind(this);
+        this.handleAction = this.handleAction.bind(this);
+        this.handleSelect = this.handleSelect.bind(this);
         this.getFieldsTable = this.getFieldsTable.bind(this);
         this.getInitState = this.getInitState.bind(this);
         this.shouldCollapse = this.shouldCollapse.bind(this);
@@ -81,6 +85,85 @@ class PostAttachment extends React.Component {
         return TextFormatting.formatText(text) + `<div><a class="attachment-link-more" href="#">${this.props.intl.formatMessage(holders.more)}</a></div>`;
     }
 
+    handleAction(e) {
+        e.preventDefault();
+
+        const actionId = e.currentTarget.getAttribute('data-action-id');
+        doPostAction(this.props.post.channel_id, this.props.post.id, actionId);
+    }
+
+    handleSelect(e) {
+        const actionId = e.target.getAttribute('data-action-id');
+        const selectedOption = e.target.value;
+        if (!selectedOption) {
+            return;
+        }
+
+        doPostAction(this.props.post.channel_id, this.props.post.id, actionId, selectedOption);
+    }
+
+    getActionView() {
+        const actions = this.props.attachment.actions;
+        if (!actions || !actions.length) {
+            return '';
+        }
+
+        const content = [];
+        actions.forEach((action) => {
+            if (!action.id || !action.name) {
+                return;
+            }
+
+            if (action.type === 'select') {
+                const options = (action.options || []).map((option) => {
+                    return (
+                        <option
+                            key={option.value}
+                            value={option.value}
+                        >
+                            {option.text}
+                        </option>
+                    );
+                });
+
+                content.push(
+                    <select
+                        key={action.id}
+                        className='form-control attachment__select'
+                        data-action-id={action.id}
+                        defaultValue=''
+                        onChange={this.handleSelect}
+                    >
+                        <option
+                            value=''
+                            disabled={true}
+                        >
+                            {action.name}
+                        </option>
+                        {options}
+                    </select>
+                );
+            } else {
+                content.push(
+                    <button
+                        key={action.id}
+                        className='btn btn-sm btn-default attachment__button'
+                        data-action-id={action.id}
+                        onClick={this.handleAction}
+                    >
+                        {action.name}
+                    </button>
+                );
+            }
+        });
+
+        return (
+            <div className='attachment-actions'>
+                {content}
+            </div>
+        );
+    }
+
     getFieldsTable() {
         const fields = this.props.attachment.fields;
         if (!fields || !fields.length) {
@@ -276,6 +359,7 @@ class PostAttachment extends React.Component {
         }
 
         const fields = this.getFieldsTable();
+        const actions = this.getActionView();
 
         let useBorderStyle;
         if (data.color && data.color[0] === '#') {
@@ -302,6 +386,7 @@ class PostAttachment extends React.Component {
                                 {text}
                                 {image}
                                 {fields}
+                                {actions}
                             </div>
                             {thumb}
                             <div style={{clear: 'both'}}/>
@@ -315,6 +400,7 @@ class PostAttachment extends React.Component {
 
 PostAttachment.propTypes = {
     intl: intlShape.isRequired,
+    post: React.PropTypes.object.isRequired,
     attachment: React.PropTypes.object.isRequired
 };
 
@@ -11,6 +11,7 @@ export default class PostAttachmentList extends React.Component {
         this.props.attachments.forEach((attachment, i) => {
             content.push(
                 <PostAttachment
+                    post={this.props.post}
                     attachment={attachment}
                     key={'att_' + i}
                 />
@@ -26,5 +27,6 @@ export default class PostAttachmentList extends React.Component {
 }
 
 PostAttachmentList.propTypes = {
+    post: React.PropTypes.object.isRequired,
     attachments: React.PropTypes.array.isRequired
 };
@@ -61,6 +61,7 @@ export default class PostBodyAdditionalContent extends React.Component {
 
         return (
             <PostAttachmentList
+                post={this.props.post}
                 attachments={attachments}
             />
         );
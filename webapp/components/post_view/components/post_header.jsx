@@ -50,6 +50,8 @@ export default class PostHeader extends React.Component {
                 );
             }
 
+            botIndicator = <li className='bot-indicator'>{Constants.BOT_NAME}</li>;
+        } else if (post.props && post.props.from_bot) {
             botIndicator = <li className='bot-indicator'>{Constants.BOT_NAME}</li>;
         } else if (isSystemMessage) {
             userProfile = (
@@ -203,6 +203,24 @@ export default class SignupController extends React.Component {
            );
         }
 
+        if (global.window.mm_config.EnableSignUpWithOpenId === 'true') {
+            signupControls.push(
+                <a
+                    className='btn btn-custom-login btn--full openid'
+                    key='openid'
+                    href={Client.getOAuthRoute() + '/openid/signup' + window.location.search}
+                >
+                    <span className='icon fa fa-openid fa--margin-top'/>
+                    <span>
+                        <FormattedMessage
+                            id='signup.openid'
+                            defaultMessage='OpenID Connect'
+                        />
+                    </span>
+                </a>
+            );
+        }
+
         if (global.window.mm_license.IsLicensed === 'true' && global.window.mm_config.EnableLdap === 'true') {
             signupControls.push(
                 <Link
@@ -14,7 +14,7 @@ import * as AsyncClient from 'utils/async_client.jsx';
 import * as Utils from 'utils/utils.jsx';
 import Constants from 'utils/constants.jsx';
 
-import {updatePassword} from 'actions/user_actions.jsx';
+import {updatePassword, generateMfaRecoveryCodes} from 'actions/user_actions.jsx';
 
 import $ from 'jquery';
 import React from 'react';
@@ -30,6 +30,7 @@ export default class SecurityTab extends React.Component {
         this.submitPassword = this.submitPassword.bind(this);
         this.setupMfa = this.setupMfa.bind(this);
         this.deactivateMfa = this.deactivateMfa.bind(this);
+        this.generateRecoveryCodes = this.generateRecoveryCodes.bind(this);
         this.updateCurrentPassword = this.updateCurrentPassword.bind(this);
         this.updateNewPassword = this.updateNewPassword.bind(this);
         this.updateConfirmPassword = this.updateConfirmPassword.bind(this);
@@ -49,6 +50,7 @@ export default class SecurityTab extends React.Component {
             confirmPassword: '',
             passwordError: '',
             serverError: '',
+            mfaRecoveryCodes: null,
             authService: this.props.user.auth_service
         };
     }
@@ -125,9 +127,7 @@ export default class SecurityTab extends React.Component {
             '',
             false,
             () => {
-                if (global.window.mm_license.MFA === 'true' &&
-                        global.window.mm_config.EnableMultifactorAuthentication === 'true' &&
-                        global.window.mm_config.EnforceMultifactorAuthentication === 'true') {
+                if (Utils.isMfaEnforced(this.props.user)) {
                     window.location.href = '/mfa/setup';
                     return;
                 }
@@ -148,6 +148,19 @@ export default class SecurityTab extends React.Component {
         );
     }
 
+    generateRecoveryCodes(e) {
+        e.preventDefault();
+
+        generateMfaRecoveryCodes(
+            (codes) => {
+                this.setState({mfaRecoveryCodes: codes, serverError: null});
+            },
+            (err) => {
+                this.setState({serverError: err.message});
+            }
+        );
+    }
+
     updateCurrentPassword(e) {
         this.setState({currentPassword: e.target.value});
     }
@@ -188,7 +201,7 @@ export default class SecurityTab extends React.Component {
                 let mfaRemoveHelp;
                 let mfaButtonText;
 
-                if (global.window.mm_config.EnforceMultifactorAuthentication === 'true') {
+                if (Utils.isMfaEnforced(this.props.user)) {
                     mfaRemoveHelp = (
                         <FormattedMessage
                             id='user.settings.mfa.requiredHelp'
@@ -218,6 +231,19 @@ export default class SecurityTab extends React.Component {
                     );
                 }
 
+                let recoveryCodes;
+                if (this.state.mfaRecoveryCodes) {
+                    recoveryCodes = (
+                        <div className='padding-top'>
+                            <FormattedMessage
+                                id='user.settings.mfa.recoveryCodesHelp'
+                                defaultMessage='Store these recovery codes somewhere safe. Each code can be used once to sign in if you lose access to your authenticator, and they will not be shown again. Any recovery codes that you generated before no longer work.'
+                            />
+                            <pre>{this.state.mfaRecoveryCodes.join('\n')}</pre>
+                        </div>
+                    );
+                }
+
                 content = (
                     <div key='mfaQrCode'>
                         <a
@@ -227,6 +253,17 @@ export default class SecurityTab extends React.Component {
                         >
                             {mfaButtonText}
                         </a>
+                        <a
+                            className='btn btn-default'
+                            href='#'
+                            onClick={this.generateRecoveryCodes}
+                        >
+                            <FormattedMessage
+                                id='user.settings.mfa.generateRecoveryCodes'
+                                defaultMessage='Generate new recovery codes'
+                            />
+                        </a>
+                        {recoveryCodes}
                         <br/>
                     </div>
                 );
@@ -939,7 +976,6 @@ export default class SecurityTab extends React.Component {
 
         let mfaSection;
         if (config.EnableMultifactorAuthentication === 'true' &&
-                global.window.mm_license.IsLicensed === 'true' &&
                 (user.auth_service === '' || user.auth_service === Constants.LDAP_SERVICE)) {
             mfaSection = this.createMfaSection();
         }
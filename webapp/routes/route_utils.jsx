@@ -31,12 +31,9 @@ const mfaAuthServices = [
 ];
 
 export function checkIfMFARequired(state) {
-    if (window.mm_license.MFA === 'true' &&
-            window.mm_config.EnableMultifactorAuthentication === 'true' &&
-            window.mm_config.EnforceMultifactorAuthentication === 'true' &&
-            mfaPaths.indexOf(state.location.pathname) === -1) {
+    if (mfaPaths.indexOf(state.location.pathname) === -1) {
         const user = UserStore.getCurrentUser();
-        if (user && !user.mfa_active &&
+        if (user && !user.mfa_active && Utils.isMfaEnforced(user) &&
                 mfaAuthServices.indexOf(user.auth_service) !== -1) {
             return true;
         }
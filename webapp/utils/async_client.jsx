@@ -948,27 +948,12 @@ export function deletePreferences(preferences, success, error) {
 }
 
 export function getSuggestedCommands(command, suggestionId, component) {
-    Client.listCommands(
+    Client.getCommandSuggestions(
+        ChannelStore.getCurrentId(),
+        command,
         (data) => {
-            var matches = [];
-            data.forEach((cmd) => {
-                if (cmd.trigger !== 'shortcuts' || !UserAgent.isMobile()) {
-                    if (('/' + cmd.trigger).indexOf(command) === 0) {
-                        const s = '/' + cmd.trigger;
-                        let hint = '';
-                        if (cmd.auto_complete_hint && cmd.auto_complete_hint.length !== 0) {
-                            hint = cmd.auto_complete_hint;
-                        }
-                        matches.push({
-                            suggestion: s,
-                            hint,
-                            description: cmd.auto_complete_desc
-                        });
-                    }
-                }
-            });
-
-            matches = matches.sort((a, b) => a.suggestion.localeCompare(b.suggestion));
+            // the server suggests the trigger, subcommand or argument at the end of the command
+            const matches = data.filter((suggestion) => suggestion.suggestion !== '/shortcuts' || !UserAgent.isMobile());
 
             // pull out the suggested commands from the returned data
             const terms = matches.map((suggestion) => suggestion.suggestion);
@@ -74,6 +74,20 @@ export function isSystemAdmin(roles) {
     return false;
 }
 
+// isMfaEnforced returns whether the user has to set up multi-factor authentication, either because it's enforced for
+// everyone or because the user is a System Admin and it's enforced for them.
+export function isMfaEnforced(user) {
+    if (global.window.mm_config.EnableMultifactorAuthentication !== 'true') {
+        return false;
+    }
+
+    if (global.window.mm_config.EnforceMultifactorAuthentication === 'true') {
+        return true;
+    }
+
+    return global.window.mm_config.EnforceMultifactorAuthenticationForAdmins === 'true' && Boolean(user) && isSystemAdmin(user.roles);
+}
+
 export function getCookie(name) {
     var value = '; ' + document.cookie;
     var parts = value.split('; ' + name + '=');